@@ -1,112 +1,293 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
+	// Base de fusos horários embutida, usada pelas listas de tarefas do dia
+	_ "time/tzdata"
 
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
-	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
+	"github.com/whatsapp/backend/config"
 	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/automation"
+	"github.com/whatsapp/backend/internal/campaigns"
+	"github.com/whatsapp/backend/internal/chatbot"
+	"github.com/whatsapp/backend/internal/duplicates"
 	"github.com/whatsapp/backend/internal/handlers"
+	"github.com/whatsapp/backend/internal/health"
+	"github.com/whatsapp/backend/internal/inbox"
+	"github.com/whatsapp/backend/internal/leadexport"
+	"github.com/whatsapp/backend/internal/leadimport"
+	"github.com/whatsapp/backend/internal/leadmerge"
 	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/media"
+	"github.com/whatsapp/backend/internal/messaging"
 	authMiddleware "github.com/whatsapp/backend/internal/middleware"
+	"github.com/whatsapp/backend/internal/quickreplies"
+	"github.com/whatsapp/backend/internal/ratelimit"
 	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/storage"
+	"github.com/whatsapp/backend/internal/tasks"
+	"github.com/whatsapp/backend/internal/templates"
+	"github.com/whatsapp/backend/internal/whatsapp"
+	"github.com/whatsapp/backend/internal/worker"
 	"github.com/whatsapp/backend/pkg/database"
 )
 
 func main() {
-	// Carregar variáveis de ambiente
-	err := godotenv.Load()
+	// Carregar configuração
+	cfg, err := config.Load()
 	if err != nil {
-		logger.Warning("Arquivo .env não encontrado, usando variáveis de ambiente do sistema")
+		logger.Error("Configuração inválida", err.Error())
+		os.Exit(1)
 	}
 
 	// Conectar ao banco de dados
-	db, err := database.NewPostgresConnection()
+	db, err := database.NewPostgresConnection(cfg.Database)
 	if err != nil {
 		logger.Error("Erro ao conectar ao banco de dados", err)
 		os.Exit(1)
 	}
-	defer db.Close()
+
+	// Conectar ao Redis
+	redisClient, err := database.NewRedisConnection(cfg.Redis)
+	if err != nil {
+		logger.Error("Erro ao conectar ao Redis", err)
+		db.Close()
+		os.Exit(1)
+	}
 
 	// Executar migrações
 	err = database.MigrateTables(db)
 	if err != nil {
 		logger.Error("Erro ao executar migrações", err)
+		closeConnections(db, redisClient)
 		os.Exit(1)
 	}
 
+	// Workers em segundo plano
+	workers := worker.NewGroup()
+
 	// Inicializar repositórios
 	userRepo := repository.NewUserRepository(db)
+	inviteRepo := repository.NewOrganizationInviteRepository(db)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	leadRepo := repository.NewLeadRepository(db)
+	leadImportRepo := repository.NewLeadImportRepository(db)
+	leadExportRepo := repository.NewLeadExportRepository(db)
+	customFieldRepo := repository.NewCustomFieldRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	segmentRepo := repository.NewSegmentRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	noteRepo := repository.NewNoteRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	activityRepo := repository.NewActivityRepository(db)
+	organizationRepo := repository.NewOrganizationRepository(db)
+	conversationRepo := repository.NewConversationRepository(db)
+	assignmentRepo := repository.NewAssignmentRepository(db)
+	templateRepo := repository.NewTemplateRepository(db)
+	campaignRepo := repository.NewCampaignRepository(db)
+	consentRepo := repository.NewConsentRepository(db)
+	mediaRepo := repository.NewMediaRepository(db)
+	automationRepo := repository.NewAutomationRepository(db)
+	chatbotRepo := repository.NewChatbotRepository(db)
+	calendarRepo := repository.NewBusinessCalendarRepository(db)
+	quickReplyRepo := repository.NewQuickReplyRepository(db)
+
+	// Armazenamento dos arquivos das mídias
+	blobStore, err := storage.New(cfg.Media.Storage)
+	if err != nil {
+		logger.Error("Erro ao inicializar armazenamento de mídias", err)
+		closeConnections(db, redisClient)
+		os.Exit(1)
+	}
+
+	// Cliente da WhatsApp Cloud API
+	whatsAppClient := whatsapp.NewClient(cfg.WhatsApp)
 
 	// Inicializar serviços
-	authService := auth.NewAuthService(refreshTokenRepo)
+	authService := auth.NewAuthService(cfg.JWT, refreshTokenRepo)
+	importService := leadimport.NewService(leadImportRepo, customFieldRepo)
+	exportService := leadexport.NewService(leadExportRepo, leadRepo, blobStore, cfg.Export)
+	duplicatesService := duplicates.NewService(leadRepo)
+	mergeService := leadmerge.NewService(leadRepo)
+	reminderScheduler := tasks.NewScheduler(taskRepo)
+	templateService := templates.NewService(templateRepo, organizationRepo, whatsAppClient)
+	mediaService := media.NewService(mediaRepo, blobStore, whatsAppClient, cfg.Media)
+	messagingService := messaging.NewService(conversationRepo, leadRepo, templateRepo, organizationRepo, consentRepo, mediaService, whatsAppClient)
+	chatbotService := chatbot.NewService(chatbotRepo, messagingService, leadRepo, customFieldRepo, assignmentRepo)
+	automationService := automation.NewService(automationRepo, messagingService, leadRepo, tagRepo, assignmentRepo, chatbotService, calendarRepo)
+	inboxService := inbox.NewService(organizationRepo, conversationRepo, assignmentRepo, automationService, chatbotService)
+	quickReplyService := quickreplies.NewService(quickReplyRepo, conversationRepo, leadRepo, userRepo, messagingService, blobStore)
+	campaignService := campaigns.NewService(campaignRepo, templateRepo, organizationRepo, messagingService, ratelimit.NewLimiter(redisClient), calendarRepo)
+
+	// Processamento das importações e exportações de leads
+	workers.Go("lead-import", importService.Run)
+	workers.Go("lead-export", exportService.Run)
+
+	// Lembretes de tarefas vencidas
+	workers.Go("task-reminders", reminderScheduler.Run)
+
+	// Status de aprovação dos modelos de mensagem, envio das campanhas,
+	// download das mídias recebidas e prazos dos fluxos do chatbot, apenas
+	// com o provedor configurado
+	if cfg.WhatsApp.Validate() == nil {
+		workers.Go("template-sync", templateService.Run)
+		workers.Go("campaigns", campaignService.Run)
+		workers.Go("media", mediaService.Run)
+		workers.Go("chatbot-timeouts", chatbotService.Run)
+	}
+
+	// Configurar verificações de saúde
+	healthChecker := health.NewChecker(5 * time.Second)
+	healthChecker.Register("postgres", health.PostgresCheck(db))
+	healthChecker.Register("redis", health.RedisCheck(redisClient))
+	healthChecker.Register("migrations", health.MigrationsCheck(db))
+	// Sem o WhatsApp a API continua atendendo leads, tarefas e demais rotas
+	healthChecker.RegisterOptional("whatsapp", health.WhatsAppConfigCheck(cfg.WhatsApp))
 
 	// Inicializar handlers
-	authHandler := handlers.NewAuthHandler(userRepo, authService)
+	authHandler := handlers.NewAuthHandler(userRepo, inviteRepo, authService)
+	organizationHandler := handlers.NewOrganizationHandler(userRepo, inviteRepo)
+	healthHandler := handlers.NewHealthHandler(healthChecker)
+	leadHandler := handlers.NewLeadHandler(leadRepo, customFieldRepo, userRepo, duplicatesService, mergeService)
+	leadImportHandler := handlers.NewLeadImportHandler(leadImportRepo, importService)
+	leadExportHandler := handlers.NewLeadExportHandler(leadExportRepo, customFieldRepo, segmentRepo, exportService)
+	customFieldHandler := handlers.NewCustomFieldHandler(customFieldRepo)
+	tagHandler := handlers.NewTagHandler(tagRepo, leadRepo, segmentRepo, customFieldRepo)
+	segmentHandler := handlers.NewSegmentHandler(segmentRepo, leadRepo, customFieldRepo)
+	searchHandler := handlers.NewSearchHandler(searchRepo, leadRepo)
+	timelineHandler := handlers.NewTimelineHandler(activityRepo, leadRepo)
+	noteHandler := handlers.NewNoteHandler(noteRepo, leadRepo, userRepo)
+	taskHandler := handlers.NewTaskHandler(taskRepo, leadRepo, userRepo)
+	notificationHandler := handlers.NewNotificationHandler(notificationRepo)
+	assignmentHandler := handlers.NewAssignmentHandler(assignmentRepo)
+	whatsAppHandler := handlers.NewWhatsAppHandler(cfg.WhatsApp, organizationRepo, userRepo, whatsAppClient, inboxService)
+	templateHandler := handlers.NewTemplateHandler(templateRepo, customFieldRepo, leadRepo, templateService)
+	conversationHandler := handlers.NewConversationHandler(conversationRepo, messagingService, mediaService)
+	mediaHandler := handlers.NewMediaHandler(mediaService)
+	automationHandler := handlers.NewAutomationHandler(automationRepo, templateRepo, userRepo, chatbotRepo, calendarRepo)
+	chatbotHandler := handlers.NewChatbotHandler(chatbotRepo, customFieldRepo, userRepo, conversationRepo, chatbotService)
+	campaignHandler := handlers.NewCampaignHandler(campaignRepo, templateRepo, segmentRepo, leadRepo, calendarRepo)
+	calendarHandler := handlers.NewBusinessCalendarHandler(calendarRepo)
+	quickReplyHandler := handlers.NewQuickReplyHandler(quickReplyRepo, customFieldRepo, quickReplyService, mediaService)
+	consentHandler := handlers.NewConsentHandler(consentRepo, leadRepo)
+
+	docsHandler, err := handlers.NewDocsHandler(apiSpec())
+	if err != nil {
+		logger.Error("Erro ao gerar especificação OpenAPI", err)
+		closeConnections(db, redisClient)
+		os.Exit(1)
+	}
 
 	// Inicializar middlewares
 	authMiddlewareInstance := authMiddleware.NewAuthMiddleware(authService)
 
 	// Configurar router
-	r := chi.NewRouter()
-
-	// Middlewares globais
-	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(30 * time.Second))
-
-	// Configurar CORS
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:5173"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
-
-	// Rotas públicas
-	r.Group(func(r chi.Router) {
-		r.Post("/api/auth/register", authHandler.Register)
-		r.Post("/api/auth/login", authHandler.Login)
-		r.Post("/api/auth/refresh", authHandler.RefreshToken)
+	r := newRouter(cfg, routeHandlers{
+		auth:           authHandler,
+		health:         healthHandler,
+		organization:   organizationHandler,
+		docs:           docsHandler,
+		lead:           leadHandler,
+		leadImport:     leadImportHandler,
+		leadExport:     leadExportHandler,
+		customField:    customFieldHandler,
+		tag:            tagHandler,
+		segment:        segmentHandler,
+		search:         searchHandler,
+		timeline:       timelineHandler,
+		note:           noteHandler,
+		task:           taskHandler,
+		notification:   notificationHandler,
+		assignment:     assignmentHandler,
+		whatsApp:       whatsAppHandler,
+		template:       templateHandler,
+		conversation:   conversationHandler,
+		campaign:       campaignHandler,
+		consent:        consentHandler,
+		media:          mediaHandler,
+		automation:     automationHandler,
+		chatbot:        chatbotHandler,
+		calendar:       calendarHandler,
+		quickReply:     quickReplyHandler,
+		authMiddleware: authMiddlewareInstance,
 	})
 
-	// Rotas protegidas
-	r.Group(func(r chi.Router) {
-		r.Use(authMiddlewareInstance.RequireAuth)
+	// Iniciar servidor
+	srv := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
 
-		r.Post("/api/auth/logout", authHandler.Logout)
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info(fmt.Sprintf("Servidor iniciado na porta %s", cfg.Server.Port))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
 
-		// Exemplo de rota protegida
-		r.Get("/api/me", func(w http.ResponseWriter, r *http.Request) {
-			userID, _ := auth.GetUserID(r.Context())
-			email, _ := auth.GetEmail(r.Context())
+	// Aguardar sinal de encerramento ou falha do servidor
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 
-			w.Header().Set("Content-Type", "application/json")
-			w.Write([]byte(fmt.Sprintf(`{"user_id": %d, "email": "%s"}`, userID, email)))
-		})
-	})
+	exitCode := 0
+	select {
+	case sig := <-stop:
+		logger.Info(fmt.Sprintf("Sinal %s recebido, iniciando desligamento", sig))
+	case err := <-serverErr:
+		logger.Error("Erro ao iniciar servidor", err)
+		exitCode = 1
+	}
+	signal.Stop(stop)
 
-	// Iniciar servidor
-	port := os.Getenv("SERVER_PORT")
-	if port == "" {
-		port = "8080"
+	// Desligamento gracioso: retirar a instância do balanceamento, parar de
+	// aceitar conexões, drenar requisições e workers e fechar as conexões
+	healthChecker.MarkShuttingDown()
+	if cfg.Server.DrainDelay > 0 {
+		logger.Info(fmt.Sprintf("Aguardando %s para o balanceador retirar a instância", cfg.Server.DrainDelay))
+		time.Sleep(cfg.Server.DrainDelay)
 	}
 
-	logger.Info(fmt.Sprintf("Servidor iniciado na porta %s", port))
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
 
-	err = http.ListenAndServe(":"+port, r)
-	if err != nil {
-		logger.Error("Erro ao iniciar servidor", err)
-		os.Exit(1)
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Error("Erro ao drenar requisições HTTP", err)
+		exitCode = 1
+	}
+
+	if err := workers.Shutdown(ctx); err != nil {
+		logger.Error("Workers não finalizaram dentro do prazo de desligamento", err)
+		exitCode = 1
+	}
+
+	closeConnections(db, redisClient)
+
+	logger.Info("Servidor encerrado")
+	os.Exit(exitCode)
+}
+
+// closeConnections fecha as conexões com o banco de dados e o Redis
+func closeConnections(db *sql.DB, redisClient *redis.Client) {
+	if err := redisClient.Close(); err != nil {
+		logger.Error("Erro ao fechar conexão com o Redis", err)
+	}
+	if err := db.Close(); err != nil {
+		logger.Error("Erro ao fechar conexão com o banco de dados", err)
 	}
 }
@@ -0,0 +1,2172 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/businesshours"
+	"github.com/whatsapp/backend/internal/handlers"
+	"github.com/whatsapp/backend/internal/health"
+	"github.com/whatsapp/backend/internal/leadexport"
+	"github.com/whatsapp/backend/internal/leadimport"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/openapi"
+	"github.com/whatsapp/backend/internal/quickreplies"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+	"github.com/whatsapp/backend/internal/templates"
+)
+
+// undocumentedRoutes são servidas pela API mas não fazem parte do contrato
+var undocumentedRoutes = map[string]bool{
+	"GET /api/docs":   true,
+	"GET /api/docs/*": true,
+}
+
+// apiSpec descreve todas as rotas registradas em newRouter
+func apiSpec() *openapi.Document {
+	doc := openapi.NewDocument(openapi.Info{
+		Title:       "WhatsApp Lead API",
+		Description: "API de gestão de leads e atendimento via WhatsApp",
+		Version:     "1.0.0",
+	})
+	doc.Servers = []openapi.Server{{URL: "/", Description: "Servidor atual"}}
+	doc.Tags = []openapi.Tag{
+		{Name: "auth", Description: "Autenticação e tokens"},
+		{Name: "users", Description: "Usuário autenticado"},
+		{Name: "organization", Description: "Membros, papéis e convites da organização"},
+		{Name: "leads", Description: "Leads e importações"},
+		{Name: "search", Description: "Busca textual"},
+		{Name: "notes", Description: "Notas internas dos leads"},
+		{Name: "tasks", Description: "Tarefas e listas de trabalho"},
+		{Name: "notifications", Description: "Notificações do usuário"},
+		{Name: "whatsapp", Description: "Canal e webhook do WhatsApp"},
+		{Name: "conversations", Description: "Conversas e envio de mensagens pelo WhatsApp"},
+		{Name: "templates", Description: "Modelos de mensagem do WhatsApp"},
+		{Name: "quick-replies", Description: "Respostas rápidas dos atendentes"},
+		{Name: "campaigns", Description: "Campanhas de disparo de modelos"},
+		{Name: "automation", Description: "Regras de automação das mensagens recebidas"},
+		{Name: "chatbot", Description: "Fluxos do chatbot para a qualificação de leads"},
+		{Name: "business-hours", Description: "Calendários de atendimento, feriados e relógios de SLA"},
+		{Name: "assignment", Description: "Distribuição automática de leads"},
+		{Name: "health", Description: "Verificações de saúde"},
+		{Name: "docs", Description: "Documentação da API"},
+	}
+
+	problem := func(description string) openapi.Response {
+		return doc.ProblemResponse(description, response.Problem{})
+	}
+	authResponses := func(success openapi.Response, successStatus int) map[string]openapi.Response {
+		return map[string]openapi.Response{
+			openapi.Status(successStatus):                    success,
+			openapi.Status(http.StatusBadRequest):            problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnprocessableEntity):   problem("Campos inválidos"),
+			openapi.Status(http.StatusInternalServerError):   problem("Erro interno"),
+			openapi.Status(http.StatusRequestEntityTooLarge): problem("Corpo da requisição muito grande"),
+		}
+	}
+
+	// Health checks
+	doc.Add(http.MethodGet, "/healthz", &openapi.Operation{
+		Tags:        []string{"health"},
+		Summary:     "Liveness",
+		OperationID: "liveness",
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK): doc.JSONResponse("Processo respondendo", handlers.LivenessResponse{}),
+		},
+	})
+	doc.Add(http.MethodGet, "/readyz", &openapi.Operation{
+		Tags:        []string{"health"},
+		Summary:     "Readiness com verificação das dependências",
+		OperationID: "readiness",
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                 doc.JSONResponse("Pronto para receber tráfego; degraded se um componente opcional falhou", health.Report{}),
+			openapi.Status(http.StatusServiceUnavailable): doc.JSONResponse("Alguma dependência obrigatória falhou", health.Report{}),
+		},
+	})
+
+	// Documentação
+	doc.Add(http.MethodGet, "/api/openapi.json", &openapi.Operation{
+		Tags:        []string{"docs"},
+		Summary:     "Especificação OpenAPI desta API",
+		OperationID: "getOpenAPISpec",
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "Documento OpenAPI 3",
+				Content:     map[string]openapi.MediaType{"application/json": {Schema: &openapi.Schema{Type: "object"}}},
+			},
+		},
+	})
+
+	// Autenticação
+	register := authResponses(doc.JSONResponse("Usuário criado", handlers.AuthResponse{}), http.StatusCreated)
+	register[openapi.Status(http.StatusConflict)] = problem("Não foi possível concluir o registro")
+	doc.Add(http.MethodPost, "/api/auth/register", &openapi.Operation{
+		Tags:        []string{"auth"},
+		Summary:     "Registrar usuário",
+		OperationID: "register",
+		RequestBody: doc.JSONBody(handlers.RegisterRequest{}),
+		Responses:   register,
+	})
+
+	login := authResponses(doc.JSONResponse("Autenticado", handlers.AuthResponse{}), http.StatusOK)
+	login[openapi.Status(http.StatusUnauthorized)] = problem("Credenciais inválidas")
+	doc.Add(http.MethodPost, "/api/auth/login", &openapi.Operation{
+		Tags:        []string{"auth"},
+		Summary:     "Autenticar usuário",
+		OperationID: "login",
+		RequestBody: doc.JSONBody(handlers.LoginRequest{}),
+		Responses:   login,
+	})
+
+	refresh := authResponses(doc.JSONResponse("Tokens renovados", handlers.AuthResponse{}), http.StatusOK)
+	refresh[openapi.Status(http.StatusUnauthorized)] = problem("Refresh token inválido ou expirado")
+	doc.Add(http.MethodPost, "/api/auth/refresh", &openapi.Operation{
+		Tags:        []string{"auth"},
+		Summary:     "Renovar token de acesso",
+		OperationID: "refreshToken",
+		RequestBody: doc.JSONBody(handlers.RefreshTokenRequest{}),
+		Responses:   refresh,
+	})
+
+	acceptInvite := authResponses(doc.JSONResponse("Usuário criado na organização do convite", handlers.AuthResponse{}), http.StatusCreated)
+	acceptInvite[openapi.Status(http.StatusBadRequest)] = problem("Corpo inválido ou convite inválido, expirado ou já aceito")
+	acceptInvite[openapi.Status(http.StatusConflict)] = problem("Email já cadastrado")
+	doc.Add(http.MethodPost, "/api/auth/invites/accept", &openapi.Operation{
+		Tags:        []string{"auth"},
+		Summary:     "Aceitar convite para uma organização",
+		Description: "Cria o usuário com o email e o papel do convite e retorna os tokens, como no registro. O convite vale por 7 dias e uma única vez.",
+		OperationID: "acceptInvite",
+		RequestBody: doc.JSONBody(handlers.AcceptInviteRequest{}),
+		Responses:   acceptInvite,
+	})
+
+	doc.Add(http.MethodPost, "/api/auth/logout", &openapi.Operation{
+		Tags:        []string{"auth"},
+		Summary:     "Encerrar sessão invalidando os refresh tokens",
+		OperationID: "logout",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Sessão encerrada"),
+			openapi.Status(http.StatusBadRequest):   problem("Token ausente"),
+			openapi.Status(http.StatusUnauthorized): problem("Token inválido"),
+		},
+	})
+
+	// Usuário autenticado
+	doc.Add(http.MethodGet, "/api/me", &openapi.Operation{
+		Tags:        []string{"users"},
+		Summary:     "Dados do usuário autenticado",
+		OperationID: "getMe",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Usuário autenticado", handlers.MeResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+		},
+	})
+
+	// Membros e convites da organização
+	memberIDParam := openapi.PathParam("id", "ID do usuário", openapi.Integer())
+	inviteIDParam := openapi.PathParam("id", "ID do convite", openapi.Integer())
+	doc.Add(http.MethodGet, "/api/organization/members", &openapi.Operation{
+		Tags:        []string{"organization"},
+		Summary:     "Listar membros da organização",
+		OperationID: "listMembers",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Membros", handlers.MemberListResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/organization/members/{id}", &openapi.Operation{
+		Tags:        []string{"organization"},
+		Summary:     "Alterar papel de um membro",
+		Description: "Restrito aos administradores. A organização mantém ao menos um administrador.",
+		OperationID: "updateMemberRole",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{memberIDParam},
+		RequestBody: doc.JSONBody(handlers.MemberRoleRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Membro alterado", entity.User{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização ou não administrador"),
+			openapi.Status(http.StatusNotFound):            problem("Membro não encontrado"),
+			openapi.Status(http.StatusConflict):            problem("Único administrador da organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Papel inválido"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/organization/invites", &openapi.Operation{
+		Tags:        []string{"organization"},
+		Summary:     "Listar convites pendentes",
+		Description: "Restrito aos administradores. Inclui os convites expirados ainda não aceitos.",
+		OperationID: "listInvites",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Convites", handlers.InviteListResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização ou não administrador"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/organization/invites", &openapi.Operation{
+		Tags:        []string{"organization"},
+		Summary:     "Convidar para a organização",
+		Description: "Restrito aos administradores. O token retornado deve ser entregue ao convidado, que o usa em POST /api/auth/invites/accept; ele não é exibido novamente.",
+		OperationID: "createInvite",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.InviteRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Convite criado", handlers.InviteResponse{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização ou não administrador"),
+			openapi.Status(http.StatusConflict):            problem("Email já cadastrado ou com convite pendente"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/organization/invites/{id}", &openapi.Operation{
+		Tags:        []string{"organization"},
+		Summary:     "Revogar convite pendente",
+		Description: "Restrito aos administradores.",
+		OperationID: "deleteInvite",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{inviteIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Convite revogado"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização ou não administrador"),
+			openapi.Status(http.StatusNotFound):     problem("Convite não encontrado"),
+		},
+	})
+
+	// Leads
+	leadFilterParams := []openapi.Parameter{
+		openapi.QueryParam("status", "Status separados por vírgula", openapi.String()),
+		openapi.QueryParam("stage", "Etapa do funil", openapi.String()),
+		openapi.QueryParam("source", "Origem", openapi.String()),
+		openapi.QueryParam("owner_id", "ID do responsável ou me", openapi.String()),
+		openapi.QueryParam("tag", "Etiquetas separadas por vírgula", openapi.String()),
+		openapi.QueryParam("tag_mode", "any (qualquer uma das etiquetas, padrão) ou all (todas)", openapi.String()),
+		openapi.QueryParam("exclude_tag", "Exclui leads com qualquer uma destas etiquetas", openapi.String()),
+		openapi.QueryParam("q", "Busca por nome, email ou telefone", openapi.String()),
+		openapi.QueryParam("created_from", "Criados a partir de (AAAA-MM-DD ou RFC 3339)", openapi.String()),
+		openapi.QueryParam("created_to", "Criados até (AAAA-MM-DD inclui o dia inteiro)", openapi.String()),
+		openapi.QueryParam("last_message_from", "Última mensagem a partir de (AAAA-MM-DD ou RFC 3339)", openapi.String()),
+		openapi.QueryParam("last_message_to", "Última mensagem até (AAAA-MM-DD inclui o dia inteiro)", openapi.String()),
+		openapi.QueryParam("active_within_days", "Com mensagem nos últimos N dias", openapi.Integer()),
+		openapi.QueryParam("inactive_for_days", "Sem mensagem há N dias ou nunca contatados", openapi.Integer()),
+		openapi.QueryParam("sort", "created_at, updated_at, name, last_message_at ou custom.<chave>; prefixo - para decrescente", openapi.String()),
+	}
+	doc.Add(http.MethodGet, "/api/leads", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Listar leads com filtros",
+		Description: "Campos personalizados definidos são filtrados por custom.<chave>=valor (múltipla escolha: contém as opções) e, para números e datas, por custom.<chave>.min e custom.<chave>.max.",
+		OperationID: "listLeads",
+		Security:    openapi.Secured(),
+		Parameters: append([]openapi.Parameter{
+			openapi.QueryParam("limit", "Tamanho da página (1 a 200, padrão 50)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+		}, leadFilterParams...),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Página de leads", handlers.LeadListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Filtros inválidos"),
+		},
+	})
+
+	doc.Add(http.MethodPost, "/api/leads", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Cadastrar lead",
+		Description: "O telefone é normalizado para E.164 e deve ser único na organização.",
+		OperationID: "createLead",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.CreateLeadRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):               doc.JSONResponse("Lead criado", entity.Lead{}),
+			openapi.Status(http.StatusBadRequest):            problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):          problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):             problem("Usuário sem organização"),
+			openapi.Status(http.StatusConflict):              problem("Telefone já cadastrado na organização"),
+			openapi.Status(http.StatusRequestEntityTooLarge): problem("Corpo da requisição muito grande"),
+			openapi.Status(http.StatusUnprocessableEntity):   problem("Campos inválidos"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/leads/duplicates", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Procurar leads parecidos antes de um cadastro",
+		Description: "Compara telefone (normalizado), email e semelhança de nome.",
+		OperationID: "checkLeadDuplicates",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("name", "Nome", openapi.String()),
+			openapi.QueryParam("phone", "Telefone em qualquer formatação", openapi.String()),
+			openapi.QueryParam("email", "Email", openapi.String()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Possíveis duplicados", handlers.DuplicatesResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Nenhum critério informado"),
+		},
+	})
+	leadIDParam := openapi.PathParam("id", "ID do lead", openapi.Integer())
+	doc.Add(http.MethodGet, "/api/leads/{id}", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Consultar lead",
+		Description: "IDs de leads mesclados retornam o lead sobrevivente, com o endereço canônico em Content-Location.",
+		OperationID: "getLead",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{leadIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Lead", entity.Lead{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Lead não encontrado"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/leads/{id}", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Alterar lead",
+		Description: "Substitui os dados do lead. Cada campo alterado é registrado no histórico com os valores anterior e novo.",
+		OperationID: "updateLead",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{leadIDParam},
+		RequestBody: doc.JSONBody(handlers.UpdateLeadRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                    doc.JSONResponse("Lead alterado", entity.Lead{}),
+			openapi.Status(http.StatusBadRequest):            problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):          problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):             problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):              problem("Lead não encontrado"),
+			openapi.Status(http.StatusConflict):              problem("Telefone já cadastrado em outro lead"),
+			openapi.Status(http.StatusRequestEntityTooLarge): problem("Corpo da requisição muito grande"),
+			openapi.Status(http.StatusUnprocessableEntity):   problem("Campos inválidos ou responsável fora da organização"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/leads/{id}/timeline", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Histórico do lead",
+		Description: "Atividades do lead em ordem cronológica: criação, alterações de campos (com valores anterior e novo), mudanças de status e etapa, atribuições, mensagens, notas, tarefas, envios de campanhas, consentimento e mesclagens. O histórico é somente de inclusão.",
+		OperationID: "getLeadTimeline",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			leadIDParam,
+			openapi.QueryParam("type", "Tipos separados por vírgula: "+strings.Join(entity.ActivityTypes, ", "), openapi.String()),
+			openapi.QueryParam("order", "desc (padrão, mais recentes primeiro) ou asc", openapi.String()),
+			openapi.QueryParam("limit", "Tamanho da página (1 a 200, padrão 50)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Página do histórico", handlers.TimelineResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Lead não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/leads/{id}/consents", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Consentimento do lead",
+		Description: "Situação atual em cada canal (a alteração mais recente) e o histórico de concessões e retiradas, com origem e evidência. Leads sem registro em um canal não estão descadastrados.",
+		OperationID: "listLeadConsents",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			leadIDParam,
+			openapi.QueryParam("limit", "Tamanho da página do histórico (1 a 200, padrão 50)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Consentimento do lead", handlers.ConsentListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Lead não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Paginação inválida"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/leads/{id}/consents", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Registrar consentimento do lead",
+		Description: "Registra a concessão (opted_in) ou a retirada (opted_out) do consentimento em um canal. Leads descadastrados do WhatsApp não recebem modelos nem campanhas.",
+		OperationID: "recordLeadConsent",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{leadIDParam},
+		RequestBody: doc.JSONBody(handlers.ConsentRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Consentimento registrado", entity.Consent{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Lead não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/leads/{id}/duplicates", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Possíveis duplicados de um lead",
+		OperationID: "getLeadDuplicates",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{leadIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Possíveis duplicados", handlers.DuplicatesResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Lead não encontrado"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/leads/{id}/merge", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Mesclar leads no lead da rota",
+		Description: "Em uma única transação, grava os valores escolhidos no sobrevivente, transfere etiquetas e demais registros dos leads mesclados, remove-os mantendo seus IDs como redirecionamentos e registra a operação na auditoria.",
+		OperationID: "mergeLeads",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{leadIDParam},
+		RequestBody: doc.JSONBody(handlers.MergeLeadsRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Lead resultante", entity.Lead{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Lead sobrevivente não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Dados inválidos ou leads mesclados inexistentes"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/leads/{id}/tags", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Definir etiquetas do lead",
+		Description: "Substitui as etiquetas do lead. Nomes são comparados sem distinção de maiúsculas; os inexistentes criam novas etiquetas.",
+		OperationID: "setLeadTags",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{leadIDParam},
+		RequestBody: doc.JSONBody(handlers.LeadTagsRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Lead atualizado", entity.Lead{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Lead não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Nomes inválidos"),
+		},
+	})
+
+	// Importação de leads
+	doc.Add(http.MethodPost, "/api/leads/imports", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Importar leads de uma planilha CSV ou XLSX",
+		Description: "Com dry_run=true valida todas as linhas e devolve a pré-visualização sem gravar. Caso contrário cria um job processado em segundo plano.",
+		OperationID: "importLeads",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("dry_run", "Apenas valida e pré-visualiza", openapi.Boolean()),
+		},
+		RequestBody: doc.MultipartBody(handlers.LeadImportUploadForm{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                    doc.JSONResponse("Pré-visualização da importação", leadimport.Preview{}),
+			openapi.Status(http.StatusAccepted):              doc.JSONResponse("Importação enfileirada", handlers.LeadImportResponse{}),
+			openapi.Status(http.StatusBadRequest):            problem("Formulário inválido"),
+			openapi.Status(http.StatusUnauthorized):          problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):             problem("Usuário sem organização"),
+			openapi.Status(http.StatusRequestEntityTooLarge): problem("Arquivo muito grande"),
+			openapi.Status(http.StatusUnprocessableEntity):   problem("Arquivo ou mapeamento inválido"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/leads/imports", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Listar importações recentes",
+		OperationID: "listLeadImports",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Importações", []handlers.LeadImportResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/leads/imports/{id}", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Consultar progresso de uma importação",
+		OperationID: "getLeadImport",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{openapi.PathParam("id", "ID da importação", openapi.Integer())},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Importação", handlers.LeadImportResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Importação não encontrada"),
+		},
+	})
+
+	// Exportação de leads
+	exportFile := func(description string) openapi.Response {
+		binary := &openapi.Schema{Type: "string", Format: "binary"}
+		return openapi.Response{
+			Description: description,
+			Content: map[string]openapi.MediaType{
+				leadexport.ContentType(leadexport.FormatCSV):  {Schema: binary},
+				leadexport.ContentType(leadexport.FormatXLSX): {Schema: binary},
+				leadexport.ContentType(leadexport.FormatJSON): {Schema: openapi.ArrayOf(&openapi.Schema{Type: "object"})},
+			},
+		}
+	}
+	doc.Add(http.MethodGet, "/api/leads/export", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Exportar leads em fluxo na própria resposta",
+		Description: "Aceita os mesmos filtros da listagem. Limitada a EXPORT_MAX_SYNC_ROWS leads; acima disso use POST /api/leads/exports.",
+		OperationID: "exportLeads",
+		Security:    openapi.Secured(),
+		Parameters: append([]openapi.Parameter{
+			openapi.QueryParam("format", "Formato do arquivo (padrão csv)", openapi.Enum(leadexport.Formats...)),
+			openapi.QueryParam("columns", "Colunas separadas por vírgula, incluindo custom.<chave>", openapi.String()),
+		}, leadFilterParams...),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  exportFile("Arquivo exportado"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos ou exportação acima do limite"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/leads/exports", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Criar exportação em segundo plano",
+		OperationID: "createLeadExport",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.LeadExportRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusAccepted):              doc.JSONResponse("Exportação enfileirada", handlers.LeadExportResponse{}),
+			openapi.Status(http.StatusBadRequest):            problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):          problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):             problem("Usuário sem organização"),
+			openapi.Status(http.StatusRequestEntityTooLarge): problem("Corpo da requisição muito grande"),
+			openapi.Status(http.StatusUnprocessableEntity):   problem("Parâmetros inválidos ou exportação acima do limite"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/leads/exports", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Histórico de exportações da organização",
+		OperationID: "listLeadExports",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Exportações", []handlers.LeadExportResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/leads/exports/{id}", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Consultar exportação e obter o link de download",
+		OperationID: "getLeadExport",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{openapi.PathParam("id", "ID da exportação", openapi.Integer())},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Exportação", handlers.LeadExportResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Exportação não encontrada"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/leads/exports/{id}/download", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Baixar arquivo de uma exportação pelo link assinado",
+		OperationID: "downloadLeadExport",
+		Parameters: []openapi.Parameter{
+			openapi.PathParam("id", "ID da exportação", openapi.Integer()),
+			{Name: "expires", In: "query", Required: true, Description: "Expiração do link (Unix)", Schema: openapi.Integer()},
+			{Name: "signature", In: "query", Required: true, Description: "Assinatura do link", Schema: openapi.String()},
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):        exportFile("Arquivo exportado"),
+			openapi.Status(http.StatusForbidden): problem("Link inválido ou expirado"),
+			openapi.Status(http.StatusNotFound):  problem("Arquivo não está mais disponível"),
+		},
+	})
+
+	// Campos personalizados
+	customFieldIDParam := openapi.PathParam("id", "ID do campo personalizado", openapi.Integer())
+	doc.Add(http.MethodGet, "/api/leads/custom-fields", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Listar campos personalizados da organização",
+		OperationID: "listCustomFields",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Campos personalizados", handlers.CustomFieldListResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/leads/custom-fields", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Definir campo personalizado",
+		Description: "Tipos: text, number, date, select, multi_select e boolean. Os valores dos leads são validados e convertidos conforme o tipo no cadastro e na importação.",
+		OperationID: "createCustomField",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.CreateCustomFieldRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Campo criado", entity.CustomFieldDefinition{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusConflict):            problem("Chave já utilizada"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Dados inválidos ou limite de campos atingido"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/leads/custom-fields/{id}", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Alterar campo personalizado",
+		Description: "A chave e o tipo não podem ser alterados.",
+		OperationID: "updateCustomField",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{customFieldIDParam},
+		RequestBody: doc.JSONBody(handlers.UpdateCustomFieldRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Campo alterado", entity.CustomFieldDefinition{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Campo não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Dados inválidos"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/leads/custom-fields/{id}", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Remover campo personalizado",
+		Description: "Os valores já gravados nos leads são mantidos, sem validação de tipo.",
+		OperationID: "deleteCustomField",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{customFieldIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Campo removido"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Campo não encontrado"),
+		},
+	})
+
+	// Etiquetas
+	tagIDParam := openapi.PathParam("id", "ID da etiqueta", openapi.Integer())
+	doc.Add(http.MethodGet, "/api/leads/tags", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Listar etiquetas da organização",
+		Description: "Inclui o número de leads com cada etiqueta.",
+		OperationID: "listTags",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Etiquetas", handlers.TagListResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/leads/tags", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Criar etiqueta",
+		OperationID: "createTag",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.TagRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Etiqueta criada", entity.Tag{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusConflict):            problem("Nome já utilizado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Nome inválido ou limite de etiquetas atingido"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/leads/tags/bulk", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Adicionar e remover etiquetas em massa",
+		Description: "Os leads são escolhidos por exatamente um entre lead_ids, segment_id e filters, avaliados uma única vez antes das alterações. IDs de outras organizações são ignorados.",
+		OperationID: "bulkTagLeads",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.BulkTagRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Leads selecionados e associações criadas e removidas", repository.TagChanges{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Dados inválidos ou segmento inexistente"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/leads/tags/{id}", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Renomear etiqueta",
+		Description: "Os segmentos que filtram pelo nome antigo passam a usar o novo.",
+		OperationID: "updateTag",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{tagIDParam},
+		RequestBody: doc.JSONBody(handlers.TagRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Etiqueta renomeada", entity.Tag{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Etiqueta não encontrada"),
+			openapi.Status(http.StatusConflict):            problem("Nome já utilizado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Nome inválido"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/leads/tags/{id}", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Remover etiqueta",
+		Description: "Remove a etiqueta de todos os leads. Segmentos que a utilizam são mantidos.",
+		OperationID: "deleteTag",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{tagIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Etiqueta removida"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Etiqueta não encontrada"),
+		},
+	})
+
+	// Segmentos
+	segmentIDParam := openapi.PathParam("id", "ID do segmento", openapi.Integer())
+	doc.Add(http.MethodGet, "/api/leads/segments", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Listar segmentos salvos",
+		OperationID: "listSegments",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Segmentos", handlers.SegmentListResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/leads/segments", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Salvar segmento",
+		Description: "Os filtros são os da listagem de leads e são avaliados a cada uso; prefira active_within_days e inactive_for_days a datas fixas para segmentos recorrentes.",
+		OperationID: "createSegment",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.SegmentRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Segmento criado", entity.Segment{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusConflict):            problem("Nome já utilizado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Filtros inválidos ou limite de segmentos atingido"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/leads/segments/preview", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Pré-visualizar filtros de segmento",
+		Description: "Retorna o total de leads e os primeiros 10, sem salvar o segmento.",
+		OperationID: "previewSegment",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.SegmentPreviewRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Total e amostra", handlers.SegmentPreviewResponse{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Filtros inválidos"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/leads/segments/{id}", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Consultar segmento",
+		Description: "Inclui o número de leads que atendem ao segmento no momento da consulta.",
+		OperationID: "getSegment",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{segmentIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Segmento", handlers.SegmentResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Segmento não encontrado"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/leads/segments/{id}", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Alterar segmento",
+		OperationID: "updateSegment",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{segmentIDParam},
+		RequestBody: doc.JSONBody(handlers.SegmentRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Segmento alterado", entity.Segment{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Segmento não encontrado"),
+			openapi.Status(http.StatusConflict):            problem("Nome já utilizado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Filtros inválidos"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/leads/segments/{id}", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Remover segmento",
+		OperationID: "deleteSegment",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{segmentIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Segmento removido"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Segmento não encontrado"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/leads/segments/{id}/leads", &openapi.Operation{
+		Tags:        []string{"leads"},
+		Summary:     "Listar leads do segmento",
+		Description: "Avalia os filtros salvos no momento da consulta.",
+		OperationID: "listSegmentLeads",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			segmentIDParam,
+			openapi.QueryParam("limit", "Tamanho da página (1 a 200, padrão 50)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+			openapi.QueryParam("sort", "Substitui a ordenação salva no segmento", openapi.String()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Página de leads", handlers.LeadListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Segmento não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Paginação ou ordenação inválida"),
+		},
+	})
+
+	// Busca textual
+	doc.Add(http.MethodGet, "/api/search", &openapi.Operation{
+		Tags:        []string{"search"},
+		Summary:     "Buscar em leads, notas e mensagens",
+		Description: "Busca textual em português, ignorando acentos e flexões (apartamento encontra apartamentos). Aceita aspas para frases, or e - para excluir termos. Os resultados são ordenados por relevância, com o trecho encontrado destacado por <mark> e o restante escapado para HTML.",
+		OperationID: "search",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("q", "Texto buscado (2 a 200 caracteres)", openapi.String()),
+			openapi.QueryParam("type", "Tipos separados por vírgula: lead, note, message (padrão todos)", openapi.String()),
+			openapi.QueryParam("lead_id", "Restringe a um lead", openapi.Integer()),
+			openapi.QueryParam("from", "Criados a partir de (AAAA-MM-DD ou RFC 3339)", openapi.String()),
+			openapi.QueryParam("to", "Criados até (AAAA-MM-DD inclui o dia inteiro)", openapi.String()),
+			openapi.QueryParam("limit", "Tamanho da página (1 a 50, padrão 20)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Resultados", handlers.SearchResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos"),
+		},
+	})
+
+	// Notas
+	doc.Add(http.MethodGet, "/api/leads/{id}/notes", &openapi.Operation{
+		Tags:        []string{"notes"},
+		Summary:     "Listar notas do lead",
+		Description: "Das mais recentes para as mais antigas.",
+		OperationID: "listLeadNotes",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			leadIDParam,
+			openapi.QueryParam("limit", "Tamanho da página (1 a 200, padrão 50)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Página de notas", handlers.NoteListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Lead não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Paginação inválida"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/leads/{id}/notes", &openapi.Operation{
+		Tags:        []string{"notes"},
+		Summary:     "Criar nota no lead",
+		Description: "Colegas mencionados com @ recebem uma notificação.",
+		OperationID: "createLeadNote",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{leadIDParam},
+		RequestBody: doc.JSONBody(handlers.NoteRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Nota criada", entity.Note{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Lead não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos"),
+		},
+	})
+	noteIDParam := openapi.PathParam("id", "ID da nota", openapi.Integer())
+	doc.Add(http.MethodPut, "/api/notes/{id}", &openapi.Operation{
+		Tags:        []string{"notes"},
+		Summary:     "Alterar nota",
+		Description: "Apenas o autor pode alterar a nota. Somente os colegas mencionados pela primeira vez são notificados.",
+		OperationID: "updateNote",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{noteIDParam},
+		RequestBody: doc.JSONBody(handlers.NoteRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Nota alterada", entity.Note{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização ou não é o autor"),
+			openapi.Status(http.StatusNotFound):            problem("Nota não encontrada"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/notes/{id}", &openapi.Operation{
+		Tags:        []string{"notes"},
+		Summary:     "Remover nota",
+		Description: "Apenas o autor pode remover a nota.",
+		OperationID: "deleteNote",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{noteIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Nota removida"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização ou não é o autor"),
+			openapi.Status(http.StatusNotFound):     problem("Nota não encontrada"),
+		},
+	})
+
+	// Tarefas
+	doc.Add(http.MethodGet, "/api/leads/{id}/tasks", &openapi.Operation{
+		Tags:        []string{"tasks"},
+		Summary:     "Listar tarefas do lead",
+		Description: "As abertas primeiro, pelo prazo, seguidas das concluídas.",
+		OperationID: "listLeadTasks",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{leadIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Tarefas", handlers.TaskListResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Lead não encontrado"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/leads/{id}/tasks", &openapi.Operation{
+		Tags:        []string{"tasks"},
+		Summary:     "Criar tarefa no lead",
+		Description: "O responsável é notificado quando a tarefa é atribuída por outro usuário e recebe um lembrete em remind_at.",
+		OperationID: "createLeadTask",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{leadIDParam},
+		RequestBody: doc.JSONBody(handlers.TaskRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Tarefa criada", entity.Task{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Lead não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos ou responsável fora da organização"),
+		},
+	})
+	tzParam := openapi.QueryParam("tz", "Fuso horário IANA que define o dia (padrão America/Sao_Paulo)", openapi.String())
+	doc.Add(http.MethodGet, "/api/tasks/today", &openapi.Operation{
+		Tags:        []string{"tasks"},
+		Summary:     "Minhas tarefas de hoje",
+		Description: "Tarefas abertas do usuário autenticado com prazo no dia atual.",
+		OperationID: "listTodayTasks",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{tzParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Tarefas", handlers.TaskListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Fuso horário inválido"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/tasks/overdue", &openapi.Operation{
+		Tags:        []string{"tasks"},
+		Summary:     "Minhas tarefas atrasadas",
+		Description: "Tarefas abertas do usuário autenticado com prazo anterior ao dia atual.",
+		OperationID: "listOverdueTasks",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{tzParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Tarefas", handlers.TaskListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Fuso horário inválido"),
+		},
+	})
+	taskIDParam := openapi.PathParam("id", "ID da tarefa", openapi.Integer())
+	doc.Add(http.MethodGet, "/api/tasks/{id}", &openapi.Operation{
+		Tags:        []string{"tasks"},
+		Summary:     "Consultar tarefa",
+		OperationID: "getTask",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{taskIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Tarefa", entity.Task{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Tarefa não encontrada"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/tasks/{id}", &openapi.Operation{
+		Tags:        []string{"tasks"},
+		Summary:     "Alterar tarefa",
+		Description: "Um novo responsável é notificado. Alterar remind_at agenda novamente o lembrete.",
+		OperationID: "updateTask",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{taskIDParam},
+		RequestBody: doc.JSONBody(handlers.TaskRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Tarefa alterada", entity.Task{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Tarefa não encontrada"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos ou responsável fora da organização"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/tasks/{id}", &openapi.Operation{
+		Tags:        []string{"tasks"},
+		Summary:     "Remover tarefa",
+		OperationID: "deleteTask",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{taskIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Tarefa removida"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Tarefa não encontrada"),
+		},
+	})
+
+	// Notificações
+	doc.Add(http.MethodGet, "/api/notifications", &openapi.Operation{
+		Tags:        []string{"notifications"},
+		Summary:     "Listar minhas notificações",
+		Description: "Das mais recentes para as mais antigas, com o total de não lidas. Tipos: task.due, task.assigned, note.mention e lead.assigned.",
+		OperationID: "listNotifications",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("unread", "Apenas as não lidas", openapi.Boolean()),
+			openapi.QueryParam("limit", "Tamanho da página (1 a 200, padrão 50)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Página de notificações", handlers.NotificationListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/notifications/read-all", &openapi.Operation{
+		Tags:        []string{"notifications"},
+		Summary:     "Marcar todas como lidas",
+		OperationID: "markAllNotificationsRead",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Notificações marcadas", handlers.MarkAllReadResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/notifications/{id}/read", &openapi.Operation{
+		Tags:        []string{"notifications"},
+		Summary:     "Marcar notificação como lida",
+		OperationID: "markNotificationRead",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{openapi.PathParam("id", "ID da notificação", openapi.Integer())},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Notificação marcada"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Notificação não encontrada"),
+		},
+	})
+
+	// WhatsApp
+	doc.Add(http.MethodGet, "/api/webhooks/whatsapp", &openapi.Operation{
+		Tags:        []string{"whatsapp"},
+		Summary:     "Verificação do webhook pela Meta",
+		Description: "Devolve hub.challenge quando hub.verify_token confere com WHATSAPP_VERIFY_TOKEN.",
+		OperationID: "verifyWhatsAppWebhook",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("hub.mode", "subscribe", openapi.String()),
+			openapi.QueryParam("hub.verify_token", "Token de verificação configurado", openapi.String()),
+			openapi.QueryParam("hub.challenge", "Desafio a devolver", openapi.String()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "Desafio devolvido",
+				Content:     map[string]openapi.MediaType{"text/plain": {Schema: openapi.String()}},
+			},
+			openapi.Status(http.StatusForbidden): problem("Token de verificação inválido"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/webhooks/whatsapp", &openapi.Operation{
+		Tags:        []string{"whatsapp"},
+		Summary:     "Receber mensagens e status da WhatsApp Cloud API",
+		Description: "O corpo deve estar assinado com WHATSAPP_APP_SECRET no cabeçalho X-Hub-Signature-256. Mensagens de remetentes desconhecidos criam leads (origem whatsapp) e os leads sem responsável são distribuídos conforme /api/assignment/settings.",
+		OperationID: "receiveWhatsAppWebhook",
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]openapi.MediaType{"application/json": {Schema: &openapi.Schema{Type: "object"}}},
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                    openapi.EmptyResponse("Notificação processada"),
+			openapi.Status(http.StatusBadRequest):            problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):          problem("Assinatura inválida"),
+			openapi.Status(http.StatusRequestEntityTooLarge): problem("Corpo da requisição muito grande"),
+			openapi.Status(http.StatusInternalServerError):   problem("Erro ao gravar; a Meta reenviará a notificação"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/whatsapp/channel", &openapi.Operation{
+		Tags:        []string{"whatsapp"},
+		Summary:     "Número e conta comercial do WhatsApp vinculados à organização",
+		OperationID: "getWhatsAppChannel",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Número vinculado", handlers.WhatsAppChannelResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/whatsapp/channel", &openapi.Operation{
+		Tags:        []string{"whatsapp"},
+		Summary:     "Vincular número e conta comercial do WhatsApp à organização",
+		Description: "Restrito aos administradores. As mensagens recebidas pelo número passam a criar leads e conversas na organização. A conta comercial (WABA) recebe os modelos de mensagem. O número precisa pertencer à conta comercial, consultada no provedor, e o nível de envio é o informado pelo provedor; repetir a operação atualiza o nível.",
+		OperationID: "updateWhatsAppChannel",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.WhatsAppChannelRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Número vinculado", handlers.WhatsAppChannelResponse{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização ou não administrador"),
+			openapi.Status(http.StatusConflict):            problem("Número ou conta comercial vinculados a outra organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos, conta comercial inacessível ou número fora da conta"),
+			openapi.Status(http.StatusBadGateway):          problem("Erro do provedor do WhatsApp"),
+			openapi.Status(http.StatusServiceUnavailable):  problem("Provedor do WhatsApp não configurado"),
+		},
+	})
+
+	// Conversas e envio de mensagens
+	conversationIDParam := openapi.PathParam("id", "ID da conversa", openapi.Integer())
+	doc.Add(http.MethodGet, "/api/conversations", &openapi.Operation{
+		Tags:        []string{"conversations"},
+		Summary:     "Listar conversas",
+		Description: "Ordenadas pela última mensagem. window_expires_at indica o fim da janela de atendimento de 24 horas, para a contagem regressiva na caixa de entrada.",
+		OperationID: "listConversations",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("status", "open ou closed", openapi.String()),
+			openapi.QueryParam("assigned_user_id", "ID do atendente ou me", openapi.String()),
+			openapi.QueryParam("limit", "Tamanho da página (1 a 200, padrão 50)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Página de conversas", handlers.ConversationListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/conversations/{id}", &openapi.Operation{
+		Tags:        []string{"conversations"},
+		Summary:     "Consultar conversa",
+		Description: "Inclui window_open e window_expires_at, calculados a partir da última mensagem recebida do lead.",
+		OperationID: "getConversation",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{conversationIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Conversa", entity.Conversation{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Conversa não encontrada"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/conversations/{id}/messages", &openapi.Operation{
+		Tags:        []string{"conversations"},
+		Summary:     "Listar mensagens da conversa",
+		Description: "Das mais recentes para as mais antigas. Mensagens com mídia trazem o arquivo em media; media.url é um link assinado e temporário (MEDIA_LINK_EXPIRY), presente quando o arquivo já foi armazenado.",
+		OperationID: "listConversationMessages",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			conversationIDParam,
+			openapi.QueryParam("limit", "Tamanho da página (1 a 200, padrão 50)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Página de mensagens", handlers.MessageListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Conversa não encontrada"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/conversations/{id}/messages", &openapi.Operation{
+		Tags:        []string{"conversations"},
+		Summary:     "Enviar mensagem ao lead",
+		Description: "Texto livre só é aceito até 24 horas depois da última mensagem do lead; fora da janela a API responde 409 window_closed e apenas modelos aprovados podem ser enviados. As variáveis do modelo são preenchidas com os dados do lead.",
+		OperationID: "sendConversationMessage",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{conversationIDParam},
+		RequestBody: doc.JSONBody(handlers.SendMessageRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Mensagem enviada", entity.Message{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Conversa ou modelo não encontrado"),
+			openapi.Status(http.StatusConflict):            problem("Janela de atendimento fechada (window_closed), conversa encerrada ou telefone inválido"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos, modelo não aprovado ou lead sem os campos do modelo"),
+			openapi.Status(http.StatusBadGateway):          problem("Erro retornado pelo WhatsApp"),
+			openapi.Status(http.StatusServiceUnavailable):  problem("Provedor do WhatsApp não configurado"),
+		},
+	})
+
+	doc.Add(http.MethodPost, "/api/conversations/{id}/media", &openapi.Operation{
+		Tags:        []string{"conversations"},
+		Summary:     "Enviar mídia ao lead",
+		Description: "Envia imagem, áudio, vídeo, documento ou figurinha, conforme o tipo MIME do arquivo. Os tipos e tamanhos seguem os limites do WhatsApp: imagens JPEG/PNG até 5 MB, figurinhas WebP até 500 KB, áudios e vídeos até 16 MB e documentos até 100 MB. Como o texto livre, só é aceita com a janela de atendimento aberta.",
+		OperationID: "sendConversationMedia",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{conversationIDParam},
+		RequestBody: doc.MultipartBody(handlers.SendMediaForm{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):               doc.JSONResponse("Mensagem enviada", entity.Message{}),
+			openapi.Status(http.StatusBadRequest):            problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):          problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):             problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):              problem("Conversa não encontrada"),
+			openapi.Status(http.StatusConflict):              problem("Janela de atendimento fechada (window_closed), conversa encerrada ou telefone inválido"),
+			openapi.Status(http.StatusRequestEntityTooLarge): problem("Arquivo acima de 100 MB"),
+			openapi.Status(http.StatusUnprocessableEntity):   problem("Tipo de arquivo não aceito ou acima do limite do seu tipo"),
+			openapi.Status(http.StatusBadGateway):            problem("Erro retornado pelo WhatsApp"),
+			openapi.Status(http.StatusServiceUnavailable):    problem("Provedor do WhatsApp não configurado"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/conversations/{id}/quick-replies", &openapi.Operation{
+		Tags:        []string{"quick-replies"},
+		Summary:     "Enviar resposta rápida ao lead",
+		Description: "Envia o texto com as variáveis preenchidas pelos campos do lead da conversa e do atendente e, em seguida, cada anexo, somando um uso à resposta. Segue as regras do texto livre: só é aceita com a janela de atendimento aberta. O envio para no primeiro erro.",
+		OperationID: "sendQuickReply",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{conversationIDParam},
+		RequestBody: doc.JSONBody(handlers.QuickReplySendRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Mensagens enviadas, na ordem do envio", []entity.Message{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Conversa não encontrada"),
+			openapi.Status(http.StatusConflict):            problem("Janela de atendimento fechada (window_closed), conversa encerrada ou telefone inválido"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Resposta não encontrada, variáveis sem valor no lead (missing_fields) ou texto acima do limite"),
+			openapi.Status(http.StatusBadGateway):          problem("Erro retornado pelo WhatsApp"),
+			openapi.Status(http.StatusServiceUnavailable):  problem("Provedor do WhatsApp não configurado"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/conversations/{id}/flow", &openapi.Operation{
+		Tags:        []string{"chatbot"},
+		Summary:     "Consultar o fluxo em andamento na conversa",
+		Description: "Retorna a sessão ativa, com o nó em que aguarda a resposta, as respostas dadas e o prazo.",
+		OperationID: "getConversationFlow",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{conversationIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Sessão ativa", entity.FlowSession{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Conversa não encontrada ou sem fluxo em andamento"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/conversations/{id}/flow", &openapi.Operation{
+		Tags:        []string{"chatbot"},
+		Summary:     "Iniciar um fluxo na conversa",
+		Description: "Executa o fluxo até a primeira pergunta. Falhas no envio encerram a sessão com status failed e o motivo em error.",
+		OperationID: "startConversationFlow",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{conversationIDParam},
+		RequestBody: doc.JSONBody(handlers.FlowStartRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Sessão iniciada", entity.FlowSession{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Conversa não encontrada"),
+			openapi.Status(http.StatusConflict):            problem("Conversa encerrada ou já em um fluxo"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Fluxo inexistente ou desativado"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/conversations/{id}/flow", &openapi.Operation{
+		Tags:        []string{"chatbot"},
+		Summary:     "Encerrar o fluxo da conversa",
+		Description: "Cancela a sessão ativa para que um atendente assuma a conversa.",
+		OperationID: "cancelConversationFlow",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{conversationIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Sessão cancelada"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Conversa não encontrada ou sem fluxo em andamento"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/media/{id}", &openapi.Operation{
+		Tags:        []string{"conversations"},
+		Summary:     "Baixar arquivo de uma mídia pelo link assinado",
+		Description: "Link obtido em media.url das mensagens, válido por MEDIA_LINK_EXPIRY.",
+		OperationID: "downloadMedia",
+		Parameters: []openapi.Parameter{
+			openapi.PathParam("id", "ID da mídia", openapi.Integer()),
+			{Name: "expires", In: "query", Required: true, Description: "Expiração do link (Unix)", Schema: openapi.Integer()},
+			{Name: "signature", In: "query", Required: true, Description: "Assinatura do link", Schema: openapi.String()},
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "Arquivo da mídia, com o tipo MIME original",
+				Content: map[string]openapi.MediaType{
+					"application/octet-stream": {Schema: &openapi.Schema{Type: "string", Format: "binary"}},
+				},
+			},
+			openapi.Status(http.StatusForbidden): problem("Link inválido ou expirado"),
+			openapi.Status(http.StatusNotFound):  problem("Arquivo não está disponível"),
+		},
+	})
+
+	// Modelos de mensagem
+	templateIDParam := openapi.PathParam("id", "ID do modelo", openapi.Integer())
+	doc.Add(http.MethodGet, "/api/templates", &openapi.Operation{
+		Tags:        []string{"templates"},
+		Summary:     "Listar modelos de mensagem",
+		OperationID: "listTemplates",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("status", "draft, pending, approved, rejected, paused ou disabled", openapi.String()),
+			openapi.QueryParam("limit", "Tamanho da página (1 a 200, padrão 50)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Página de modelos", handlers.TemplateListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/templates", &openapi.Operation{
+		Tags:        []string{"templates"},
+		Summary:     "Criar modelo em rascunho",
+		Description: "Valida localmente as regras do WhatsApp: nome, idioma, tamanhos, marcadores sequenciais com uma variável (campo do lead e exemplo) para cada um e botões.",
+		OperationID: "createTemplate",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.TemplateRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Modelo criado", entity.MessageTemplate{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusConflict):            problem("Nome e idioma já usados"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/templates/sync", &openapi.Operation{
+		Tags:        []string{"templates"},
+		Summary:     "Sincronizar status de aprovação com o WhatsApp",
+		Description: "Também executada a cada 5 minutos para organizações com modelos pendentes.",
+		OperationID: "syncTemplates",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                 doc.JSONResponse("Resultado da sincronização", templates.SyncResult{}),
+			openapi.Status(http.StatusUnauthorized):       problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):          problem("Usuário sem organização"),
+			openapi.Status(http.StatusConflict):           problem("Organização sem conta comercial vinculada"),
+			openapi.Status(http.StatusBadGateway):         problem("Erro retornado pelo WhatsApp"),
+			openapi.Status(http.StatusServiceUnavailable): problem("Provedor do WhatsApp não configurado"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/templates/{id}", &openapi.Operation{
+		Tags:        []string{"templates"},
+		Summary:     "Consultar modelo",
+		OperationID: "getTemplate",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{templateIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Modelo", entity.MessageTemplate{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Modelo não encontrado"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/templates/{id}", &openapi.Operation{
+		Tags:        []string{"templates"},
+		Summary:     "Alterar modelo",
+		Description: "Apenas rascunhos e modelos rejeitados, que voltam a rascunho. Modelos já enviados mantêm nome e idioma.",
+		OperationID: "updateTemplate",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{templateIDParam},
+		RequestBody: doc.JSONBody(handlers.TemplateRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Modelo alterado", entity.MessageTemplate{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Modelo não encontrado"),
+			openapi.Status(http.StatusConflict):            problem("Modelo em análise ou aprovado, ou nome já usado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/templates/{id}", &openapi.Operation{
+		Tags:        []string{"templates"},
+		Summary:     "Remover modelo não enviado",
+		OperationID: "deleteTemplate",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{templateIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Modelo removido"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Modelo não encontrado"),
+			openapi.Status(http.StatusConflict):     problem("Modelo já enviado ao WhatsApp"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/templates/{id}/submit", &openapi.Operation{
+		Tags:        []string{"templates"},
+		Summary:     "Enviar modelo para aprovação",
+		Description: "Cria o modelo na conta comercial vinculada ou, se rejeitado, envia a edição para nova análise.",
+		OperationID: "submitTemplate",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{templateIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                 doc.JSONResponse("Modelo enviado", entity.MessageTemplate{}),
+			openapi.Status(http.StatusUnauthorized):       problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):          problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):           problem("Modelo não encontrado"),
+			openapi.Status(http.StatusConflict):           problem("Modelo já enviado ou organização sem conta comercial"),
+			openapi.Status(http.StatusBadGateway):         problem("Modelo recusado pelo WhatsApp"),
+			openapi.Status(http.StatusServiceUnavailable): problem("Provedor do WhatsApp não configurado"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/templates/{id}/preview", &openapi.Operation{
+		Tags:        []string{"templates"},
+		Summary:     "Prévia do modelo com os dados de um lead",
+		Description: "Campos vazios no lead são preenchidos pelo exemplo da variável e listados em missing.",
+		OperationID: "previewTemplate",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			templateIDParam,
+			openapi.QueryParam("lead_id", "ID do lead", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Modelo preenchido", templates.Rendered{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Modelo não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Lead não informado ou inexistente"),
+		},
+	})
+
+	// Respostas rápidas
+	quickReplyIDParam := openapi.PathParam("id", "ID da resposta rápida", openapi.Integer())
+	attachmentIDParam := openapi.PathParam("attachmentId", "ID do anexo", openapi.Integer())
+	doc.Add(http.MethodGet, "/api/quick-replies", &openapi.Operation{
+		Tags:        []string{"quick-replies"},
+		Summary:     "Listar respostas rápidas",
+		Description: "Retorna as respostas da organização e as pessoais do atendente, as mais usadas primeiro.",
+		OperationID: "listQuickReplies",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("q", "Busca no atalho e no título, como /pre", openapi.String()),
+			openapi.QueryParam("scope", "organization ou personal", openapi.String()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Respostas rápidas", []entity.QuickReply{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Escopo inválido"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/quick-replies", &openapi.Operation{
+		Tags:        []string{"quick-replies"},
+		Summary:     "Criar resposta rápida",
+		Description: "O atalho é único entre as respostas da organização e entre as pessoais de cada atendente; uma resposta pessoal pode repetir o atalho de uma da organização.",
+		OperationID: "createQuickReply",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.QuickReplyRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Resposta rápida criada", entity.QuickReply{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusConflict):            problem("Atalho já utilizado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Atalho, título ou variáveis inválidos"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/quick-replies/{id}", &openapi.Operation{
+		Tags:        []string{"quick-replies"},
+		Summary:     "Consultar resposta rápida",
+		OperationID: "getQuickReply",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{quickReplyIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Resposta rápida", entity.QuickReply{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Resposta rápida não encontrada"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/quick-replies/{id}", &openapi.Operation{
+		Tags:        []string{"quick-replies"},
+		Summary:     "Alterar resposta rápida",
+		Description: "Substitui o atalho, o título e o texto; o escopo não muda.",
+		OperationID: "updateQuickReply",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{quickReplyIDParam},
+		RequestBody: doc.JSONBody(handlers.QuickReplyRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Resposta rápida alterada", entity.QuickReply{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Resposta rápida não encontrada"),
+			openapi.Status(http.StatusConflict):            problem("Atalho já utilizado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Atalho, título ou variáveis inválidos"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/quick-replies/{id}", &openapi.Operation{
+		Tags:        []string{"quick-replies"},
+		Summary:     "Remover resposta rápida",
+		Description: "Remove também os arquivos dos anexos.",
+		OperationID: "deleteQuickReply",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{quickReplyIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Resposta rápida removida"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Resposta rápida não encontrada"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/quick-replies/{id}/preview", &openapi.Operation{
+		Tags:        []string{"quick-replies"},
+		Summary:     "Prévia da resposta com os dados de um lead",
+		Description: "Preenche as variáveis com os campos do lead e do atendente autenticado. Variáveis sem valor ficam vazias e são listadas em missing; com elas a resposta não é enviada.",
+		OperationID: "previewQuickReply",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			quickReplyIDParam,
+			openapi.QueryParam("lead_id", "ID do lead", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Resposta preenchida", quickreplies.Rendered{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Resposta rápida não encontrada"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Lead não informado ou inexistente"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/quick-replies/{id}/attachments", &openapi.Operation{
+		Tags:        []string{"quick-replies"},
+		Summary:     "Anexar arquivo à resposta rápida",
+		Description: "Até 5 anexos por resposta, enviados depois do texto na ordem em que foram anexados.",
+		OperationID: "addQuickReplyAttachment",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{quickReplyIDParam},
+		RequestBody: doc.MultipartBody(handlers.QuickReplyAttachmentForm{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):               doc.JSONResponse("Anexo gravado", entity.QuickReplyAttachment{}),
+			openapi.Status(http.StatusBadRequest):            problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):          problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):             problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):              problem("Resposta rápida não encontrada"),
+			openapi.Status(http.StatusConflict):              problem("Limite de anexos atingido"),
+			openapi.Status(http.StatusRequestEntityTooLarge): problem("Arquivo acima de 16 MB"),
+			openapi.Status(http.StatusUnprocessableEntity):   problem("Tipo de arquivo não aceito ou acima do limite do seu tipo"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/quick-replies/{id}/attachments/{attachmentId}", &openapi.Operation{
+		Tags:        []string{"quick-replies"},
+		Summary:     "Baixar anexo da resposta rápida",
+		OperationID: "downloadQuickReplyAttachment",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{quickReplyIDParam, attachmentIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "Arquivo do anexo, com o tipo MIME original",
+				Content: map[string]openapi.MediaType{
+					"application/octet-stream": {Schema: &openapi.Schema{Type: "string", Format: "binary"}},
+				},
+			},
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Resposta rápida ou anexo não encontrado"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/quick-replies/{id}/attachments/{attachmentId}", &openapi.Operation{
+		Tags:        []string{"quick-replies"},
+		Summary:     "Remover anexo da resposta rápida",
+		OperationID: "removeQuickReplyAttachment",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{quickReplyIDParam, attachmentIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Anexo removido"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Resposta rápida ou anexo não encontrado"),
+		},
+	})
+
+	// Regras de automação
+	ruleIDParam := openapi.PathParam("id", "ID da regra", openapi.Integer())
+	doc.Add(http.MethodGet, "/api/automation/rules", &openapi.Operation{
+		Tags:        []string{"automation"},
+		Summary:     "Listar regras de automação",
+		Description: "Na ordem de avaliação.",
+		OperationID: "listAutomationRules",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Regras", []entity.AutomationRule{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/automation/rules", &openapi.Operation{
+		Tags:        []string{"automation"},
+		Summary:     "Criar regra de automação",
+		Description: "A regra entra ao final da ordem de avaliação. É executada nas mensagens recebidas que atendem a todas as condições.",
+		OperationID: "createAutomationRule",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.AutomationRuleRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Regra criada", entity.AutomationRule{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Condições ou ações inválidas, modelo ou atendente inexistente"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/automation/rules/order", &openapi.Operation{
+		Tags:        []string{"automation"},
+		Summary:     "Ordenar regras de automação",
+		OperationID: "reorderAutomationRules",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.AutomationOrderRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Regras na nova ordem", []entity.AutomationRule{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("A lista não contém exatamente as regras da organização"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/automation/rules/test", &openapi.Operation{
+		Tags:        []string{"automation"},
+		Summary:     "Testar regras com uma mensagem simulada",
+		Description: "Avalia a mensagem contra as regras ativas, ou contra a regra informada em rule, e informa as condições atendidas e as ações que seriam executadas. Nenhuma ação é executada; o intervalo das regras e a proteção contra laços não são considerados.",
+		OperationID: "testAutomationRules",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.AutomationTestRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Avaliação das regras", handlers.AutomationTestResponse{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Regra informada inválida"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/automation/rules/{id}", &openapi.Operation{
+		Tags:        []string{"automation"},
+		Summary:     "Consultar regra de automação",
+		OperationID: "getAutomationRule",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{ruleIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Regra", entity.AutomationRule{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Regra não encontrada"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/automation/rules/{id}", &openapi.Operation{
+		Tags:        []string{"automation"},
+		Summary:     "Alterar regra de automação",
+		Description: "Substitui o conteúdo da regra, mantendo a posição.",
+		OperationID: "updateAutomationRule",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{ruleIDParam},
+		RequestBody: doc.JSONBody(handlers.AutomationRuleRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Regra alterada", entity.AutomationRule{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Regra não encontrada"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Condições ou ações inválidas, modelo ou atendente inexistente"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/automation/rules/{id}", &openapi.Operation{
+		Tags:        []string{"automation"},
+		Summary:     "Remover regra de automação",
+		OperationID: "deleteAutomationRule",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{ruleIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Regra removida"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Regra não encontrada"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/automation/rules/{id}/runs", &openapi.Operation{
+		Tags:        []string{"automation"},
+		Summary:     "Listar execuções da regra",
+		Description: "Das mais recentes para as mais antigas, com o resultado de cada ação.",
+		OperationID: "listAutomationRuns",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			ruleIDParam,
+			openapi.QueryParam("limit", "Tamanho da página (1 a 200, padrão 50)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Página de execuções", handlers.AutomationRunListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Regra não encontrada"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos"),
+		},
+	})
+
+	// Fluxos do chatbot
+	flowIDParam := openapi.PathParam("id", "ID do fluxo", openapi.Integer())
+	doc.Add(http.MethodGet, "/api/chatbot/flows", &openapi.Operation{
+		Tags:        []string{"chatbot"},
+		Summary:     "Listar fluxos do chatbot",
+		OperationID: "listChatbotFlows",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Fluxos", []entity.ChatbotFlow{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/chatbot/flows", &openapi.Operation{
+		Tags:        []string{"chatbot"},
+		Summary:     "Criar fluxo do chatbot",
+		Description: "Os nós são validados com as regras do WhatsApp para botões (até 3, títulos de 20 caracteres) e listas (até 10 itens, títulos de 24 caracteres), e todas as referências entre nós precisam existir.",
+		OperationID: "createChatbotFlow",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.ChatbotFlowRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Fluxo criado", entity.ChatbotFlow{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Nós inválidos, referências inexistentes ou atendente fora da organização"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/chatbot/flows/{id}", &openapi.Operation{
+		Tags:        []string{"chatbot"},
+		Summary:     "Consultar fluxo do chatbot",
+		OperationID: "getChatbotFlow",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{flowIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Fluxo", entity.ChatbotFlow{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Fluxo não encontrado"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/chatbot/flows/{id}", &openapi.Operation{
+		Tags:        []string{"chatbot"},
+		Summary:     "Alterar fluxo do chatbot",
+		Description: "Sessões em andamento continuam no nó em que estão; se ele for removido, a sessão é encerrada com falha na próxima resposta.",
+		OperationID: "updateChatbotFlow",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{flowIDParam},
+		RequestBody: doc.JSONBody(handlers.ChatbotFlowRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Fluxo alterado", entity.ChatbotFlow{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Fluxo não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Nós inválidos, referências inexistentes ou atendente fora da organização"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/chatbot/flows/{id}", &openapi.Operation{
+		Tags:        []string{"chatbot"},
+		Summary:     "Remover fluxo do chatbot",
+		Description: "Remove também as sessões; conversas em andamento voltam a ser distribuídas na próxima mensagem.",
+		OperationID: "deleteChatbotFlow",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{flowIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Fluxo removido"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Fluxo não encontrado"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/chatbot/flows/{id}/sessions", &openapi.Operation{
+		Tags:        []string{"chatbot"},
+		Summary:     "Listar sessões do fluxo",
+		Description: "Das mais recentes para as mais antigas, com as respostas de cada lead.",
+		OperationID: "listChatbotSessions",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			flowIDParam,
+			openapi.QueryParam("status", "Filtra pelo status: active, completed, handed_off, timed_out, cancelled ou failed", openapi.String()),
+			openapi.QueryParam("limit", "Tamanho da página (1 a 200, padrão 50)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Página de sessões", handlers.FlowSessionListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Fluxo não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos"),
+		},
+	})
+
+	// Calendários de atendimento
+	calendarIDParam := openapi.PathParam("id", "ID do calendário", openapi.Integer())
+	atParam := openapi.QueryParam("at", "Instante consultado em RFC 3339; padrão agora", openapi.String())
+	doc.Add(http.MethodGet, "/api/business-hours", &openapi.Operation{
+		Tags:        []string{"business-hours"},
+		Summary:     "Listar calendários de atendimento",
+		Description: "O calendário padrão primeiro e os demais em ordem alfabética.",
+		OperationID: "listBusinessCalendars",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Calendários", []entity.BusinessCalendar{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/business-hours", &openapi.Operation{
+		Tags:        []string{"business-hours"},
+		Summary:     "Criar calendário de atendimento",
+		Description: "Um calendário por organização ou equipe, com intervalos semanais no fuso informado. Nos feriados o atendimento fica fechado o dia inteiro.",
+		OperationID: "createBusinessCalendar",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.BusinessCalendarRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Calendário criado", entity.BusinessCalendar{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusConflict):            problem("Nome já utilizado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Fuso, intervalos ou feriados inválidos"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/business-hours/status", &openapi.Operation{
+		Tags:        []string{"business-hours"},
+		Summary:     "Consultar situação do calendário padrão",
+		Description: "Informa se a organização está atendendo e quando abre ou fecha.",
+		OperationID: "getDefaultBusinessStatus",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{atParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Situação do atendimento", businesshours.Status{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Organização sem calendário padrão"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/business-hours/holidays", &openapi.Operation{
+		Tags:        []string{"business-hours"},
+		Summary:     "Listar feriados nacionais",
+		Description: "Feriados nacionais do Brasil no ano, incluindo Sexta-feira Santa; Carnaval e Corpus Christi vêm marcados como pontos facultativos (optional).",
+		OperationID: "listNationalHolidays",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("year", "Ano; padrão o ano atual", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Feriados", []entity.Holiday{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Ano inválido"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/business-hours/{id}", &openapi.Operation{
+		Tags:        []string{"business-hours"},
+		Summary:     "Consultar calendário de atendimento",
+		OperationID: "getBusinessCalendar",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{calendarIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Calendário", entity.BusinessCalendar{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Calendário não encontrado"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/business-hours/{id}", &openapi.Operation{
+		Tags:        []string{"business-hours"},
+		Summary:     "Alterar calendário de atendimento",
+		OperationID: "updateBusinessCalendar",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{calendarIDParam},
+		RequestBody: doc.JSONBody(handlers.BusinessCalendarRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Calendário alterado", entity.BusinessCalendar{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Calendário não encontrado"),
+			openapi.Status(http.StatusConflict):            problem("Nome já utilizado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Fuso, intervalos ou feriados inválidos"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/business-hours/{id}", &openapi.Operation{
+		Tags:        []string{"business-hours"},
+		Summary:     "Remover calendário de atendimento",
+		Description: "Não é possível remover um calendário usado por regras de automação ou campanhas não encerradas.",
+		OperationID: "deleteBusinessCalendar",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{calendarIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Calendário removido"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Calendário não encontrado"),
+			openapi.Status(http.StatusConflict):     problem("Calendário em uso"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/business-hours/{id}/status", &openapi.Operation{
+		Tags:        []string{"business-hours"},
+		Summary:     "Consultar situação do calendário",
+		Description: "Informa se o atendimento está aberto e, com os horários no fuso do calendário, a próxima abertura ou o próximo fechamento.",
+		OperationID: "getBusinessStatus",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{calendarIDParam, atParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Situação do atendimento", businesshours.Status{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Calendário não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/business-hours/{id}/sla", &openapi.Operation{
+		Tags:        []string{"business-hours"},
+		Summary:     "Calcular relógio de SLA",
+		Description: "O relógio corre apenas no horário de atendimento e para nos feriados: retorna o prazo da meta, o tempo útil decorrido até at e se a meta foi ultrapassada.",
+		OperationID: "getBusinessSLA",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			calendarIDParam,
+			openapi.QueryParam("started_at", "Início do relógio em RFC 3339", openapi.String()),
+			openapi.QueryParam("target_minutes", "Meta em minutos de atendimento (1 a 43200)", openapi.Integer()),
+			atParam,
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Relógio de SLA", businesshours.Clock{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Calendário não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos"),
+		},
+	})
+
+	// Campanhas
+	campaignIDParam := openapi.PathParam("id", "ID da campanha", openapi.Integer())
+	campaignTransition := func(summary, description, operationID string) *openapi.Operation {
+		return &openapi.Operation{
+			Tags:        []string{"campaigns"},
+			Summary:     summary,
+			Description: description,
+			OperationID: operationID,
+			Security:    openapi.Secured(),
+			Parameters:  []openapi.Parameter{campaignIDParam},
+			Responses: map[string]openapi.Response{
+				openapi.Status(http.StatusOK):           doc.JSONResponse("Campanha alterada", entity.Campaign{}),
+				openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+				openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+				openapi.Status(http.StatusNotFound):     problem("Campanha não encontrada"),
+				openapi.Status(http.StatusConflict):     problem("Operação não permitida no status atual"),
+			},
+		}
+	}
+	doc.Add(http.MethodGet, "/api/campaigns", &openapi.Operation{
+		Tags:        []string{"campaigns"},
+		Summary:     "Listar campanhas",
+		OperationID: "listCampaigns",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("status", "draft, scheduled, running, paused, completed ou cancelled", openapi.String()),
+			openapi.QueryParam("limit", "Tamanho da página (1 a 200, padrão 50)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Página de campanhas", handlers.CampaignListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/campaigns", &openapi.Operation{
+		Tags:        []string{"campaigns"},
+		Summary:     "Criar campanha em rascunho",
+		Description: "O filtro do segmento é copiado para a campanha; os destinatários são os leads que o atendem quando o envio começa.",
+		OperationID: "createCampaign",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.CampaignRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusCreated):             doc.JSONResponse("Campanha criada", entity.Campaign{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos, modelo não aprovado ou segmento inexistente"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/campaigns/{id}", &openapi.Operation{
+		Tags:        []string{"campaigns"},
+		Summary:     "Consultar campanha",
+		OperationID: "getCampaign",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{campaignIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Campanha", entity.Campaign{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Campanha não encontrada"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/campaigns/{id}", &openapi.Operation{
+		Tags:        []string{"campaigns"},
+		Summary:     "Alterar campanha em rascunho",
+		OperationID: "updateCampaign",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{campaignIDParam},
+		RequestBody: doc.JSONBody(handlers.CampaignRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Campanha alterada", entity.Campaign{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Campanha não encontrada"),
+			openapi.Status(http.StatusConflict):            problem("Campanha já agendada"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos, modelo não aprovado ou segmento inexistente"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/api/campaigns/{id}", &openapi.Operation{
+		Tags:        []string{"campaigns"},
+		Summary:     "Remover campanha em rascunho",
+		OperationID: "deleteCampaign",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{campaignIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusNoContent):    openapi.EmptyResponse("Campanha removida"),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Campanha não encontrada"),
+			openapi.Status(http.StatusConflict):     problem("Campanha já agendada; use o cancelamento"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/campaigns/{id}/schedule", &openapi.Operation{
+		Tags:        []string{"campaigns"},
+		Summary:     "Agendar campanha",
+		Description: "Sem scheduled_at, usa o horário do rascunho ou inicia no próximo ciclo do envio. O modelo precisa estar aprovado e o segmento precisa ter leads.",
+		OperationID: "scheduleCampaign",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{campaignIDParam},
+		RequestBody: doc.JSONBody(handlers.CampaignScheduleRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Campanha agendada", entity.Campaign{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Campanha não encontrada"),
+			openapi.Status(http.StatusConflict):            problem("Campanha já agendada"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Modelo não aprovado ou segmento sem leads"),
+		},
+	})
+	doc.Add(http.MethodPost, "/api/campaigns/{id}/pause", campaignTransition("Pausar campanha",
+		"Campanhas agendadas ou em andamento; os envios já reservados terminam.", "pauseCampaign"))
+	doc.Add(http.MethodPost, "/api/campaigns/{id}/resume", campaignTransition("Retomar campanha",
+		"Volta a agendada se o envio ainda não tinha começado.", "resumeCampaign"))
+	doc.Add(http.MethodPost, "/api/campaigns/{id}/cancel", campaignTransition("Cancelar campanha",
+		"Os destinatários pendentes são marcados como cancelled.", "cancelCampaign"))
+	doc.Add(http.MethodGet, "/api/campaigns/{id}/recipients", &openapi.Operation{
+		Tags:        []string{"campaigns"},
+		Summary:     "Listar destinatários da campanha",
+		OperationID: "listCampaignRecipients",
+		Security:    openapi.Secured(),
+		Parameters: []openapi.Parameter{
+			campaignIDParam,
+			openapi.QueryParam("status", "pending, sending, sent, delivered, read, failed ou cancelled", openapi.String()),
+			openapi.QueryParam("limit", "Tamanho da página (1 a 200, padrão 50)", openapi.Integer()),
+			openapi.QueryParam("offset", "Deslocamento", openapi.Integer()),
+		},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Página de destinatários", handlers.CampaignRecipientListResponse{}),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Campanha não encontrada"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Parâmetros inválidos"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/campaigns/{id}/report", &openapi.Operation{
+		Tags:        []string{"campaigns"},
+		Summary:     "Relatório da campanha",
+		Description: "Contagens cumulativas: uma mensagem lida também conta como enviada e entregue. Respostas são mensagens do lead até 72 horas depois do envio.",
+		OperationID: "getCampaignReport",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{campaignIDParam},
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Relatório", entity.CampaignReport{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):     problem("Campanha não encontrada"),
+		},
+	})
+
+	// Distribuição automática de leads
+	doc.Add(http.MethodGet, "/api/assignment/settings", &openapi.Operation{
+		Tags:        []string{"assignment"},
+		Summary:     "Configuração da distribuição automática",
+		Description: "Organizações que ainda não configuraram a distribuição a recebem desativada, com rodízio.",
+		OperationID: "getAssignmentSettings",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Configuração", entity.AssignmentSettings{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/assignment/settings", &openapi.Operation{
+		Tags:        []string{"assignment"},
+		Summary:     "Alterar a distribuição automática",
+		Description: "Os candidatos são os atendentes disponíveis abaixo do limite de conversas abertas; se uma regra exigir uma habilidade e ninguém disponível a tiver, qualquer atendente disponível é escolhido.",
+		OperationID: "updateAssignmentSettings",
+		Security:    openapi.Secured(),
+		RequestBody: doc.JSONBody(handlers.AssignmentSettingsRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Configuração alterada", entity.AssignmentSettings{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos"),
+		},
+	})
+	doc.Add(http.MethodGet, "/api/assignment/agents", &openapi.Operation{
+		Tags:        []string{"assignment"},
+		Summary:     "Listar atendentes",
+		Description: "Todos os usuários da organização, com disponibilidade, habilidades e conversas abertas. Usuários nunca configurados estão disponíveis.",
+		OperationID: "listAgents",
+		Security:    openapi.Secured(),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):           doc.JSONResponse("Atendentes", handlers.AgentListResponse{}),
+			openapi.Status(http.StatusUnauthorized): problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):    problem("Usuário sem organização"),
+		},
+	})
+	doc.Add(http.MethodPut, "/api/assignment/agents/{id}", &openapi.Operation{
+		Tags:        []string{"assignment"},
+		Summary:     "Alterar disponibilidade e habilidades de um atendente",
+		OperationID: "updateAgent",
+		Security:    openapi.Secured(),
+		Parameters:  []openapi.Parameter{openapi.PathParam("id", "ID do usuário", openapi.Integer())},
+		RequestBody: doc.JSONBody(handlers.AgentSettingsRequest{}),
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                  doc.JSONResponse("Atendente alterado", entity.AgentSettings{}),
+			openapi.Status(http.StatusBadRequest):          problem("Corpo da requisição inválido"),
+			openapi.Status(http.StatusUnauthorized):        problem("Autenticação necessária"),
+			openapi.Status(http.StatusForbidden):           problem("Usuário sem organização"),
+			openapi.Status(http.StatusNotFound):            problem("Usuário não encontrado"),
+			openapi.Status(http.StatusUnprocessableEntity): problem("Campos inválidos"),
+		},
+	})
+
+	return doc
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/cors"
+	"github.com/whatsapp/backend/config"
+	"github.com/whatsapp/backend/internal/handlers"
+	authMiddleware "github.com/whatsapp/backend/internal/middleware"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// routeHandlers reúne os handlers e middlewares usados na montagem das rotas
+type routeHandlers struct {
+	auth           *handlers.AuthHandler
+	health         *handlers.HealthHandler
+	organization   *handlers.OrganizationHandler
+	docs           *handlers.DocsHandler
+	lead           *handlers.LeadHandler
+	leadImport     *handlers.LeadImportHandler
+	leadExport     *handlers.LeadExportHandler
+	customField    *handlers.CustomFieldHandler
+	tag            *handlers.TagHandler
+	segment        *handlers.SegmentHandler
+	search         *handlers.SearchHandler
+	timeline       *handlers.TimelineHandler
+	note           *handlers.NoteHandler
+	task           *handlers.TaskHandler
+	notification   *handlers.NotificationHandler
+	assignment     *handlers.AssignmentHandler
+	whatsApp       *handlers.WhatsAppHandler
+	template       *handlers.TemplateHandler
+	conversation   *handlers.ConversationHandler
+	campaign       *handlers.CampaignHandler
+	consent        *handlers.ConsentHandler
+	media          *handlers.MediaHandler
+	automation     *handlers.AutomationHandler
+	chatbot        *handlers.ChatbotHandler
+	calendar       *handlers.BusinessCalendarHandler
+	quickReply     *handlers.QuickReplyHandler
+	authMiddleware *authMiddleware.AuthMiddleware
+}
+
+// newRouter monta o router da API. Toda rota registrada aqui precisa de uma
+// operação correspondente em apiSpec (verificado em routes_test.go).
+func newRouter(cfg *config.Config, h routeHandlers) *chi.Mux {
+	r := chi.NewRouter()
+
+	// Middlewares globais
+	r.Use(middleware.RequestID)
+	r.Use(middleware.RealIP)
+	r.Use(middleware.Logger)
+	r.Use(middleware.Recoverer)
+	r.Use(middleware.Timeout(cfg.Server.RequestTimeout))
+
+	// Configurar CORS
+	r.Use(cors.Handler(cors.Options{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		ExposedHeaders:   []string{"Link", "Location", "Content-Location", "Content-Disposition", "X-Export-ID"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	}))
+
+	// Respostas de erro padronizadas para rotas e métodos inexistentes
+	r.NotFound(response.NotFound)
+	r.MethodNotAllowed(response.MethodNotAllowed)
+
+	// Health checks
+	r.Get("/healthz", h.health.Liveness)
+	r.Get("/readyz", h.health.Readiness)
+
+	// Documentação
+	r.Get("/api/openapi.json", h.docs.Spec)
+	r.Get("/api/docs", h.docs.RedirectUI)
+	r.Get("/api/docs/*", h.docs.UI)
+
+	// Rotas públicas
+	r.Group(func(r chi.Router) {
+		r.Post("/api/auth/register", h.auth.Register)
+		r.Post("/api/auth/login", h.auth.Login)
+		r.Post("/api/auth/refresh", h.auth.RefreshToken)
+		r.Post("/api/auth/invites/accept", h.auth.AcceptInvite)
+
+		// Download de exportações, autorizado pela assinatura do link
+		r.Get("/api/leads/exports/{id}/download", h.leadExport.Download)
+
+		// Arquivos das mensagens com mídia, também autorizados pela assinatura do link
+		r.Get("/api/media/{id}", h.media.Download)
+
+		// Webhook do WhatsApp, autorizado pelo token de verificação e pela assinatura
+		r.Get("/api/webhooks/whatsapp", h.whatsApp.VerifyWebhook)
+		r.Post("/api/webhooks/whatsapp", h.whatsApp.ReceiveWebhook)
+	})
+
+	// Rotas protegidas
+	r.Group(func(r chi.Router) {
+		r.Use(h.authMiddleware.RequireAuth)
+
+		r.Post("/api/auth/logout", h.auth.Logout)
+
+		r.Get("/api/me", handlers.Me)
+
+		// Membros e convites da organização
+		r.Get("/api/organization/members", h.organization.ListMembers)
+		r.Put("/api/organization/members/{id}", h.organization.UpdateMemberRole)
+		r.Get("/api/organization/invites", h.organization.ListInvites)
+		r.Post("/api/organization/invites", h.organization.CreateInvite)
+		r.Delete("/api/organization/invites/{id}", h.organization.DeleteInvite)
+
+		// Leads
+		r.Get("/api/leads", h.lead.List)
+		r.Post("/api/leads", h.lead.Create)
+		r.Get("/api/leads/duplicates", h.lead.CheckDuplicates)
+		r.Get("/api/leads/{id}", h.lead.Get)
+		r.Put("/api/leads/{id}", h.lead.Update)
+		r.Get("/api/leads/{id}/timeline", h.timeline.Timeline)
+		r.Get("/api/leads/{id}/consents", h.consent.List)
+		r.Post("/api/leads/{id}/consents", h.consent.Record)
+		r.Get("/api/leads/{id}/duplicates", h.lead.Duplicates)
+		r.Post("/api/leads/{id}/merge", h.lead.Merge)
+		r.Put("/api/leads/{id}/tags", h.tag.SetLeadTags)
+
+		// Importação de leads
+		r.Post("/api/leads/imports", h.leadImport.Upload)
+		r.Get("/api/leads/imports", h.leadImport.List)
+		r.Get("/api/leads/imports/{id}", h.leadImport.Get)
+
+		// Exportação de leads
+		r.Get("/api/leads/export", h.leadExport.Export)
+		r.Post("/api/leads/exports", h.leadExport.Create)
+		r.Get("/api/leads/exports", h.leadExport.List)
+		r.Get("/api/leads/exports/{id}", h.leadExport.Get)
+
+		// Campos personalizados
+		r.Get("/api/leads/custom-fields", h.customField.List)
+		r.Post("/api/leads/custom-fields", h.customField.Create)
+		r.Put("/api/leads/custom-fields/{id}", h.customField.Update)
+		r.Delete("/api/leads/custom-fields/{id}", h.customField.Delete)
+
+		// Etiquetas
+		r.Get("/api/leads/tags", h.tag.List)
+		r.Post("/api/leads/tags", h.tag.Create)
+		r.Post("/api/leads/tags/bulk", h.tag.Bulk)
+		r.Put("/api/leads/tags/{id}", h.tag.Update)
+		r.Delete("/api/leads/tags/{id}", h.tag.Delete)
+
+		// Segmentos
+		r.Get("/api/leads/segments", h.segment.List)
+		r.Post("/api/leads/segments", h.segment.Create)
+		r.Post("/api/leads/segments/preview", h.segment.Preview)
+		r.Get("/api/leads/segments/{id}", h.segment.Get)
+		r.Put("/api/leads/segments/{id}", h.segment.Update)
+		r.Delete("/api/leads/segments/{id}", h.segment.Delete)
+		r.Get("/api/leads/segments/{id}/leads", h.segment.Leads)
+
+		// Busca textual
+		r.Get("/api/search", h.search.Search)
+
+		// Notas
+		r.Get("/api/leads/{id}/notes", h.note.List)
+		r.Post("/api/leads/{id}/notes", h.note.Create)
+		r.Put("/api/notes/{id}", h.note.Update)
+		r.Delete("/api/notes/{id}", h.note.Delete)
+
+		// Tarefas
+		r.Get("/api/leads/{id}/tasks", h.task.ListByLead)
+		r.Post("/api/leads/{id}/tasks", h.task.Create)
+		r.Get("/api/tasks/today", h.task.Today)
+		r.Get("/api/tasks/overdue", h.task.Overdue)
+		r.Get("/api/tasks/{id}", h.task.Get)
+		r.Put("/api/tasks/{id}", h.task.Update)
+		r.Delete("/api/tasks/{id}", h.task.Delete)
+
+		// Notificações
+		r.Get("/api/notifications", h.notification.List)
+		r.Post("/api/notifications/read-all", h.notification.MarkAllRead)
+		r.Post("/api/notifications/{id}/read", h.notification.MarkRead)
+
+		// Canal do WhatsApp
+		r.Get("/api/whatsapp/channel", h.whatsApp.GetChannel)
+		r.Put("/api/whatsapp/channel", h.whatsApp.UpdateChannel)
+
+		// Conversas e envio de mensagens
+		r.Get("/api/conversations", h.conversation.List)
+		r.Get("/api/conversations/{id}", h.conversation.Get)
+		r.Get("/api/conversations/{id}/messages", h.conversation.ListMessages)
+		r.Post("/api/conversations/{id}/messages", h.conversation.SendMessage)
+		r.Post("/api/conversations/{id}/media", h.conversation.SendMedia)
+		r.Get("/api/conversations/{id}/flow", h.chatbot.ConversationSession)
+		r.Post("/api/conversations/{id}/flow", h.chatbot.StartSession)
+		r.Delete("/api/conversations/{id}/flow", h.chatbot.CancelSession)
+		r.Post("/api/conversations/{id}/quick-replies", h.quickReply.Send)
+
+		// Modelos de mensagem
+		r.Get("/api/templates", h.template.List)
+		r.Post("/api/templates", h.template.Create)
+		r.Post("/api/templates/sync", h.template.Sync)
+		r.Get("/api/templates/{id}", h.template.Get)
+		r.Put("/api/templates/{id}", h.template.Update)
+		r.Delete("/api/templates/{id}", h.template.Delete)
+		r.Post("/api/templates/{id}/submit", h.template.Submit)
+		r.Get("/api/templates/{id}/preview", h.template.Preview)
+
+		// Respostas rápidas
+		r.Get("/api/quick-replies", h.quickReply.List)
+		r.Post("/api/quick-replies", h.quickReply.Create)
+		r.Get("/api/quick-replies/{id}", h.quickReply.Get)
+		r.Put("/api/quick-replies/{id}", h.quickReply.Update)
+		r.Delete("/api/quick-replies/{id}", h.quickReply.Delete)
+		r.Get("/api/quick-replies/{id}/preview", h.quickReply.Preview)
+		r.Post("/api/quick-replies/{id}/attachments", h.quickReply.AddAttachment)
+		r.Get("/api/quick-replies/{id}/attachments/{attachmentId}", h.quickReply.DownloadAttachment)
+		r.Delete("/api/quick-replies/{id}/attachments/{attachmentId}", h.quickReply.RemoveAttachment)
+
+		// Regras de automação
+		r.Get("/api/automation/rules", h.automation.List)
+		r.Post("/api/automation/rules", h.automation.Create)
+		r.Put("/api/automation/rules/order", h.automation.Reorder)
+		r.Post("/api/automation/rules/test", h.automation.Test)
+		r.Get("/api/automation/rules/{id}", h.automation.Get)
+		r.Put("/api/automation/rules/{id}", h.automation.Update)
+		r.Delete("/api/automation/rules/{id}", h.automation.Delete)
+		r.Get("/api/automation/rules/{id}/runs", h.automation.Runs)
+
+		// Fluxos do chatbot
+		r.Get("/api/chatbot/flows", h.chatbot.List)
+		r.Post("/api/chatbot/flows", h.chatbot.Create)
+		r.Get("/api/chatbot/flows/{id}", h.chatbot.Get)
+		r.Put("/api/chatbot/flows/{id}", h.chatbot.Update)
+		r.Delete("/api/chatbot/flows/{id}", h.chatbot.Delete)
+		r.Get("/api/chatbot/flows/{id}/sessions", h.chatbot.Sessions)
+
+		// Calendários de atendimento
+		r.Get("/api/business-hours", h.calendar.List)
+		r.Post("/api/business-hours", h.calendar.Create)
+		r.Get("/api/business-hours/status", h.calendar.DefaultStatus)
+		r.Get("/api/business-hours/holidays", h.calendar.NationalHolidays)
+		r.Get("/api/business-hours/{id}", h.calendar.Get)
+		r.Put("/api/business-hours/{id}", h.calendar.Update)
+		r.Delete("/api/business-hours/{id}", h.calendar.Delete)
+		r.Get("/api/business-hours/{id}/status", h.calendar.Status)
+		r.Get("/api/business-hours/{id}/sla", h.calendar.SLA)
+
+		// Campanhas
+		r.Get("/api/campaigns", h.campaign.List)
+		r.Post("/api/campaigns", h.campaign.Create)
+		r.Get("/api/campaigns/{id}", h.campaign.Get)
+		r.Put("/api/campaigns/{id}", h.campaign.Update)
+		r.Delete("/api/campaigns/{id}", h.campaign.Delete)
+		r.Post("/api/campaigns/{id}/schedule", h.campaign.Schedule)
+		r.Post("/api/campaigns/{id}/pause", h.campaign.Pause)
+		r.Post("/api/campaigns/{id}/resume", h.campaign.Resume)
+		r.Post("/api/campaigns/{id}/cancel", h.campaign.Cancel)
+		r.Get("/api/campaigns/{id}/recipients", h.campaign.Recipients)
+		r.Get("/api/campaigns/{id}/report", h.campaign.Report)
+
+		// Distribuição automática de leads
+		r.Get("/api/assignment/settings", h.assignment.GetSettings)
+		r.Put("/api/assignment/settings", h.assignment.UpdateSettings)
+		r.Get("/api/assignment/agents", h.assignment.ListAgents)
+		r.Put("/api/assignment/agents/{id}", h.assignment.UpdateAgent)
+	})
+
+	return r
+}
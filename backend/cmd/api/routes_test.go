@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/whatsapp/backend/config"
+)
+
+// chiParam remove expressões regulares de parâmetros, como {id:[0-9]+}
+var chiParam = regexp.MustCompile(`\{([^}:]+):[^}]+\}`)
+
+// registeredRoutes percorre o router e retorna as rotas no formato "MÉTODO caminho"
+func registeredRoutes(t *testing.T) map[string]bool {
+	t.Helper()
+
+	r := newRouter(&config.Config{}, routeHandlers{})
+	routes := make(map[string]bool)
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		route = chiParam.ReplaceAllString(route, "{$1}")
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		routes[method+" "+route] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("erro ao percorrer rotas: %v", err)
+	}
+	return routes
+}
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	doc := apiSpec()
+
+	for route := range registeredRoutes(t) {
+		if undocumentedRoutes[route] {
+			continue
+		}
+		method, path, _ := strings.Cut(route, " ")
+		if !doc.Has(method, path) {
+			t.Errorf("rota %s registrada sem entrada na especificação OpenAPI (cmd/api/openapi.go)", route)
+		}
+	}
+}
+
+func TestEverySpecEntryHasRoute(t *testing.T) {
+	routes := registeredRoutes(t)
+
+	for _, route := range apiSpec().Routes() {
+		if !routes[route] {
+			t.Errorf("operação %s documentada mas não registrada no router", route)
+		}
+	}
+}
+
+func TestSpecIsValidJSON(t *testing.T) {
+	doc := apiSpec()
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("erro ao serializar especificação: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("especificação não é um JSON válido: %v", err)
+	}
+	if decoded["openapi"] != "3.0.3" {
+		t.Errorf("versão OpenAPI inesperada: %v", decoded["openapi"])
+	}
+
+	ids := make(map[string]string)
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			if op.OperationID == "" {
+				t.Errorf("%s %s sem operationId", method, path)
+			}
+			if other, dup := ids[op.OperationID]; dup {
+				t.Errorf("operationId %q duplicado em %s %s e %s", op.OperationID, method, path, other)
+			}
+			ids[op.OperationID] = method + " " + path
+		}
+	}
+
+	for _, name := range []string{"AuthResponse", "Problem", "RegisterRequest", "LoginRequest"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("schema %s ausente nos componentes", name)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/whatsapp/backend/internal/storage"
+	"github.com/whatsapp/backend/internal/whatsapp"
+)
+
+// Ambientes suportados pela aplicação
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// Config reúne toda a configuração da aplicação, carregada uma única vez na inicialização
+type Config struct {
+	Environment string
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Redis       RedisConfig
+	JWT         JWTConfig
+	CORS        CORSConfig
+	Export      ExportConfig
+	Media       MediaConfig
+	WhatsApp    whatsapp.Config
+}
+
+// ServerConfig contém as configurações do servidor HTTP
+type ServerConfig struct {
+	Port            string
+	RequestTimeout  time.Duration
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+	// DrainDelay é o tempo entre o readiness passar a responder 503 e o
+	// servidor parar de aceitar conexões, para o balanceador notar a saída
+	DrainDelay time.Duration
+}
+
+// DatabaseConfig contém as configurações de conexão com o PostgreSQL
+type DatabaseConfig struct {
+	Host               string
+	Port               int
+	User               string
+	Password           string
+	Name               string
+	SSLMode            string
+	MaxConnections     int
+	MaxIdleConnections int
+	MaxLifetime        time.Duration
+}
+
+// DSN monta a string de conexão com o banco de dados
+func (c DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode)
+}
+
+// RedisConfig contém as configurações de conexão com o Redis
+type RedisConfig struct {
+	Host     string
+	Port     int
+	Password string
+	DB       int
+}
+
+// Addr retorna o endereço host:porta do Redis
+func (c RedisConfig) Addr() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+// JWTConfig contém as configurações de emissão de tokens
+type JWTConfig struct {
+	Secret             string
+	AccessTokenExpiry  time.Duration
+	RefreshTokenExpiry time.Duration
+}
+
+// CORSConfig contém as origens autorizadas a chamar a API pelo navegador
+type CORSConfig struct {
+	AllowedOrigins []string
+}
+
+// ExportConfig contém as configurações da exportação de leads
+type ExportConfig struct {
+	// SigningSecret assina os links de download; se vazio, usa o segredo do JWT
+	SigningSecret string
+	LinkExpiry    time.Duration
+	Retention     time.Duration
+	MaxSyncRows   int
+}
+
+// MediaConfig contém as configurações dos arquivos das mensagens com mídia
+type MediaConfig struct {
+	// SigningSecret assina os links das mídias; se vazio, usa o segredo do JWT
+	SigningSecret string
+	LinkExpiry    time.Duration
+	Storage       storage.Config
+}
+
+// IsProduction indica se a aplicação está rodando em modo de produção
+func (c *Config) IsProduction() bool {
+	return c.Environment == EnvProduction
+}
+
+// defaults retorna a configuração padrão, adequada apenas para desenvolvimento
+func defaults() *Config {
+	return &Config{
+		Environment: EnvDevelopment,
+		Server: ServerConfig{
+			Port:            "8080",
+			RequestTimeout:  30 * time.Second,
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    60 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+			DrainDelay:      5 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:               "localhost",
+			Port:               5432,
+			User:               "postgres",
+			Password:           "postgres",
+			Name:               "whatsapp",
+			SSLMode:            "disable",
+			MaxConnections:     10,
+			MaxIdleConnections: 5,
+			MaxLifetime:        5 * time.Minute,
+		},
+		Redis: RedisConfig{
+			Host: "localhost",
+			Port: 6379,
+		},
+		JWT: JWTConfig{
+			AccessTokenExpiry:  15 * time.Minute,
+			RefreshTokenExpiry: 7 * 24 * time.Hour,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"http://localhost:5173"},
+		},
+		Export: ExportConfig{
+			LinkExpiry:  time.Hour,
+			Retention:   24 * time.Hour,
+			MaxSyncRows: 5000,
+		},
+		Media: MediaConfig{
+			LinkExpiry: 15 * time.Minute,
+			Storage: storage.Config{
+				Driver:   storage.DriverFilesystem,
+				Dir:      "data/media",
+				S3Region: "us-east-1",
+			},
+		},
+		WhatsApp: whatsapp.Config{
+			APIURL: whatsapp.DefaultAPIURL,
+		},
+	}
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile é lido automaticamente quando CONFIG_FILE não é informado
+const defaultConfigFile = "config.yaml"
+
+// Load carrega a configuração combinando, em ordem crescente de prioridade,
+// os valores padrão, o arquivo YAML opcional, o arquivo .env e as variáveis
+// de ambiente do sistema. A configuração resultante é validada e todos os
+// problemas encontrados são retornados juntos.
+//
+// O arquivo YAML usa as mesmas chaves das variáveis de ambiente, podendo
+// agrupá-las em seções: "db: {host: x}" equivale a DB_HOST=x.
+func Load() (*Config, error) {
+	// O .env não sobrescreve variáveis já definidas no ambiente
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("erro ao ler arquivo .env: %w", err)
+	}
+
+	file, err := loadYAML()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &loader{file: file}
+	cfg := defaults()
+
+	l.str("APP_ENV", &cfg.Environment)
+
+	l.str("SERVER_PORT", &cfg.Server.Port)
+	l.duration("SERVER_TIMEOUT", &cfg.Server.RequestTimeout)
+	l.duration("SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	l.duration("SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	l.duration("SERVER_IDLE_TIMEOUT", &cfg.Server.IdleTimeout)
+	l.duration("SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+	l.duration("SERVER_DRAIN_DELAY", &cfg.Server.DrainDelay)
+
+	l.str("DB_HOST", &cfg.Database.Host)
+	l.integer("DB_PORT", &cfg.Database.Port)
+	l.str("DB_USER", &cfg.Database.User)
+	l.str("DB_PASS", &cfg.Database.Password)
+	l.str("DB_NAME", &cfg.Database.Name)
+	l.str("DB_SSL_MODE", &cfg.Database.SSLMode)
+	l.integer("DB_MAX_CONNECTIONS", &cfg.Database.MaxConnections)
+	l.integer("DB_MAX_IDLE_CONNECTIONS", &cfg.Database.MaxIdleConnections)
+	l.duration("DB_MAX_LIFETIME", &cfg.Database.MaxLifetime)
+
+	l.str("REDIS_HOST", &cfg.Redis.Host)
+	l.integer("REDIS_PORT", &cfg.Redis.Port)
+	l.str("REDIS_PASS", &cfg.Redis.Password)
+	l.integer("REDIS_DB", &cfg.Redis.DB)
+
+	l.str("JWT_SECRET", &cfg.JWT.Secret)
+	l.duration("JWT_EXPIRY", &cfg.JWT.AccessTokenExpiry)
+	l.duration("REFRESH_TOKEN_EXPIRY", &cfg.JWT.RefreshTokenExpiry)
+
+	l.list("CORS_ALLOWED_ORIGINS", &cfg.CORS.AllowedOrigins)
+
+	l.str("EXPORT_SIGNING_SECRET", &cfg.Export.SigningSecret)
+	l.duration("EXPORT_LINK_EXPIRY", &cfg.Export.LinkExpiry)
+	l.duration("EXPORT_RETENTION", &cfg.Export.Retention)
+	l.integer("EXPORT_MAX_SYNC_ROWS", &cfg.Export.MaxSyncRows)
+	if cfg.Export.SigningSecret == "" {
+		cfg.Export.SigningSecret = cfg.JWT.Secret
+	}
+
+	l.str("MEDIA_SIGNING_SECRET", &cfg.Media.SigningSecret)
+	l.duration("MEDIA_LINK_EXPIRY", &cfg.Media.LinkExpiry)
+	if cfg.Media.SigningSecret == "" {
+		cfg.Media.SigningSecret = cfg.JWT.Secret
+	}
+	l.str("STORAGE_DRIVER", &cfg.Media.Storage.Driver)
+	l.str("STORAGE_DIR", &cfg.Media.Storage.Dir)
+	l.str("STORAGE_S3_ENDPOINT", &cfg.Media.Storage.S3Endpoint)
+	l.str("STORAGE_S3_REGION", &cfg.Media.Storage.S3Region)
+	l.str("STORAGE_S3_BUCKET", &cfg.Media.Storage.S3Bucket)
+	l.str("STORAGE_S3_ACCESS_KEY", &cfg.Media.Storage.S3AccessKey)
+	l.str("STORAGE_S3_SECRET_KEY", &cfg.Media.Storage.S3SecretKey)
+
+	l.str("WHATSAPP_API_URL", &cfg.WhatsApp.APIURL)
+	l.str("WHATSAPP_PHONE_NUMBER_ID", &cfg.WhatsApp.PhoneNumberID)
+	l.str("WHATSAPP_ACCESS_TOKEN", &cfg.WhatsApp.AccessToken)
+	l.str("WHATSAPP_VERIFY_TOKEN", &cfg.WhatsApp.VerifyToken)
+	l.str("WHATSAPP_APP_SECRET", &cfg.WhatsApp.AppSecret)
+
+	if err := errors.Join(append(l.errs, cfg.Validate())...); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadYAML lê o arquivo de configuração opcional e o converte para chaves no formato das variáveis de ambiente
+func loadYAML() (map[string]string, error) {
+	path, explicit := os.LookupEnv("CONFIG_FILE")
+	if !explicit {
+		path = defaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao ler arquivo de configuração %s: %w", path, err)
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar arquivo de configuração %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", raw, values)
+	return values, nil
+}
+
+// flatten converte seções aninhadas do YAML em chaves como DB_HOST
+func flatten(prefix string, in map[string]interface{}, out map[string]string) {
+	for key, value := range in {
+		name := strings.ToUpper(key)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flatten(name, v, out)
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			out[name] = strings.Join(items, ",")
+		case nil:
+			out[name] = ""
+		default:
+			out[name] = fmt.Sprint(v)
+		}
+	}
+}
+
+// loader aplica os valores encontrados sobre a configuração, acumulando os erros de conversão
+type loader struct {
+	file map[string]string
+	errs []error
+}
+
+// lookup busca a chave nas variáveis de ambiente e, em seguida, no arquivo YAML
+func (l *loader) lookup(key string) (string, bool) {
+	if value, ok := os.LookupEnv(key); ok {
+		return strings.TrimSpace(value), true
+	}
+	value, ok := l.file[key]
+	return strings.TrimSpace(value), ok
+}
+
+func (l *loader) str(key string, dst *string) {
+	if value, ok := l.lookup(key); ok && value != "" {
+		*dst = value
+	}
+}
+
+func (l *loader) integer(key string, dst *int) {
+	value, ok := l.lookup(key)
+	if !ok || value == "" {
+		return
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: número inteiro inválido %q", key, value))
+		return
+	}
+	*dst = n
+}
+
+func (l *loader) duration(key string, dst *time.Duration) {
+	value, ok := l.lookup(key)
+	if !ok || value == "" {
+		return
+	}
+	d, err := ParseDuration(value)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: duração inválida %q", key, value))
+		return
+	}
+	*dst = d
+}
+
+func (l *loader) list(key string, dst *[]string) {
+	value, ok := l.lookup(key)
+	if !ok || value == "" {
+		return
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
+
+// ParseDuration estende time.ParseDuration aceitando dias com o sufixo "d" (ex.: "7d")
+func ParseDuration(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
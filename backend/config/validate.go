@@ -0,0 +1,169 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// minJWTSecretLength é o tamanho mínimo aceito para o segredo do JWT em produção
+const minJWTSecretLength = 32
+
+// insecureSecrets lista segredos de exemplo que nunca devem chegar à produção
+var insecureSecrets = []string{
+	"default_secret_key_change_in_production",
+	"meu_secret_super_seguro_trocar_em_producao",
+}
+
+// Validate verifica a configuração e retorna todos os problemas encontrados de uma só vez
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Environment != EnvDevelopment && c.Environment != EnvProduction {
+		add("APP_ENV: valor %q inválido, use %q ou %q", c.Environment, EnvDevelopment, EnvProduction)
+	}
+
+	if c.Server.Port == "" {
+		add("SERVER_PORT: obrigatório")
+	}
+	if c.Server.RequestTimeout <= 0 {
+		add("SERVER_TIMEOUT: deve ser positivo")
+	}
+	if c.Server.ReadTimeout <= 0 || c.Server.WriteTimeout <= 0 || c.Server.IdleTimeout <= 0 {
+		add("SERVER_READ_TIMEOUT, SERVER_WRITE_TIMEOUT e SERVER_IDLE_TIMEOUT: devem ser positivos")
+	}
+	if c.Server.WriteTimeout > 0 && c.Server.WriteTimeout < c.Server.RequestTimeout {
+		add("SERVER_WRITE_TIMEOUT: deve ser maior ou igual a SERVER_TIMEOUT")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		add("SERVER_SHUTDOWN_TIMEOUT: deve ser positivo")
+	}
+	if c.Server.DrainDelay < 0 || c.Server.DrainDelay >= c.Server.ShutdownTimeout {
+		add("SERVER_DRAIN_DELAY: não pode ser negativo e deve ser menor que SERVER_SHUTDOWN_TIMEOUT")
+	}
+
+	if c.Database.Host == "" || c.Database.User == "" || c.Database.Name == "" {
+		add("DB_HOST, DB_USER e DB_NAME: obrigatórios")
+	}
+	if c.Database.Port <= 0 || c.Database.Port > 65535 {
+		add("DB_PORT: porta %d inválida", c.Database.Port)
+	}
+	if c.Database.MaxConnections <= 0 {
+		add("DB_MAX_CONNECTIONS: deve ser positivo")
+	}
+	if c.Database.MaxIdleConnections <= 0 || c.Database.MaxIdleConnections > c.Database.MaxConnections {
+		add("DB_MAX_IDLE_CONNECTIONS: deve ser positivo e menor ou igual a DB_MAX_CONNECTIONS")
+	}
+	if c.Database.MaxLifetime <= 0 {
+		add("DB_MAX_LIFETIME: deve ser positivo")
+	}
+
+	if c.Redis.Host == "" {
+		add("REDIS_HOST: obrigatório")
+	}
+	if c.Redis.Port <= 0 || c.Redis.Port > 65535 {
+		add("REDIS_PORT: porta %d inválida", c.Redis.Port)
+	}
+	if c.Redis.DB < 0 {
+		add("REDIS_DB: não pode ser negativo")
+	}
+
+	if c.JWT.Secret == "" {
+		add("JWT_SECRET: obrigatório")
+	}
+	if c.JWT.AccessTokenExpiry <= 0 {
+		add("JWT_EXPIRY: deve ser positivo")
+	}
+	if c.JWT.RefreshTokenExpiry <= c.JWT.AccessTokenExpiry {
+		add("REFRESH_TOKEN_EXPIRY: deve ser maior que JWT_EXPIRY")
+	}
+
+	if len(c.CORS.AllowedOrigins) == 0 {
+		add("CORS_ALLOWED_ORIGINS: informe ao menos uma origem")
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" {
+			add("CORS_ALLOWED_ORIGINS: origem %q inválida", origin)
+		}
+	}
+
+	if c.Export.LinkExpiry <= 0 {
+		add("EXPORT_LINK_EXPIRY: deve ser positivo")
+	}
+	if c.Export.Retention < c.Export.LinkExpiry {
+		add("EXPORT_RETENTION: deve ser maior ou igual a EXPORT_LINK_EXPIRY")
+	}
+	if c.Export.MaxSyncRows <= 0 {
+		add("EXPORT_MAX_SYNC_ROWS: deve ser positivo")
+	}
+
+	// O WhatsApp é opcional, inclusive em produção: sem o token a API sobe e
+	// o readiness reporta o componente como degraded
+	if c.WhatsApp.AccessToken != "" {
+		if err := c.WhatsApp.Validate(); err != nil {
+			add("WHATSAPP: %v", err)
+		}
+	}
+
+	if c.Media.LinkExpiry <= 0 {
+		add("MEDIA_LINK_EXPIRY: deve ser positivo")
+	}
+	if err := c.Media.Storage.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.IsProduction() {
+		errs = append(errs, c.validateProduction()...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateProduction recusa valores padrão ou inseguros quando APP_ENV=production
+func (c *Config) validateProduction() []error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	for _, insecure := range insecureSecrets {
+		if c.JWT.Secret == insecure {
+			add("JWT_SECRET: valor de exemplo não é permitido em produção")
+		}
+	}
+	if len(c.JWT.Secret) < minJWTSecretLength {
+		add("JWT_SECRET: deve ter ao menos %d caracteres em produção", minJWTSecretLength)
+	}
+
+	if len(c.Export.SigningSecret) < minJWTSecretLength {
+		add("EXPORT_SIGNING_SECRET: deve ter ao menos %d caracteres em produção", minJWTSecretLength)
+	}
+
+	if len(c.Media.SigningSecret) < minJWTSecretLength {
+		add("MEDIA_SIGNING_SECRET: deve ter ao menos %d caracteres em produção", minJWTSecretLength)
+	}
+
+	if c.Database.Password == "" || c.Database.Password == "postgres" {
+		add("DB_PASS: senha padrão não é permitida em produção")
+	}
+	if c.Database.SSLMode == "disable" {
+		add("DB_SSL_MODE: conexão sem TLS não é permitida em produção")
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			add("CORS_ALLOWED_ORIGINS: curinga \"*\" não é permitido em produção")
+		} else if strings.Contains(origin, "localhost") || strings.Contains(origin, "127.0.0.1") {
+			add("CORS_ALLOWED_ORIGINS: origem local %q não é permitida em produção", origin)
+		}
+	}
+
+	return errs
+}
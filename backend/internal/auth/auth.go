@@ -5,10 +5,10 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/whatsapp/backend/config"
 	"github.com/whatsapp/backend/internal/logger"
 	"github.com/whatsapp/backend/internal/models/entity"
 )
@@ -22,16 +22,18 @@ var (
 
 // TokenClaims representa os claims do JWT
 type TokenClaims struct {
-	UserID int64  `json:"user_id"`
-	Email  string `json:"email"`
+	UserID         int64  `json:"user_id"`
+	OrganizationID int64  `json:"org_id"`
+	Email          string `json:"email"`
 	jwt.RegisteredClaims
 }
 
 // Service fornece funcionalidades relacionadas à autenticação
 type Service struct {
-	jwtSecret        string
-	tokenExpiry      time.Duration
-	refreshTokenRepo RefreshTokenRepository
+	jwtSecret          string
+	tokenExpiry        time.Duration
+	refreshTokenExpiry time.Duration
+	refreshTokenRepo   RefreshTokenRepository
 }
 
 // RefreshTokenRepository é uma interface para persistir tokens de atualização
@@ -43,24 +45,12 @@ type RefreshTokenRepository interface {
 }
 
 // NewAuthService cria uma nova instância do serviço de autenticação
-func NewAuthService(refreshTokenRepo RefreshTokenRepository) *Service {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		logger.Warning("JWT_SECRET não definido, usando valor padrão")
-		jwtSecret = "default_secret_key_change_in_production"
-	}
-
-	tokenExpiryStr := os.Getenv("JWT_EXPIRY")
-	tokenExpiry, err := time.ParseDuration(tokenExpiryStr)
-	if err != nil {
-		logger.Warning("JWT_EXPIRY inválido ou não definido, usando 15 minutos como padrão", err)
-		tokenExpiry = 15 * time.Minute
-	}
-
+func NewAuthService(cfg config.JWTConfig, refreshTokenRepo RefreshTokenRepository) *Service {
 	return &Service{
-		jwtSecret:        jwtSecret,
-		tokenExpiry:      tokenExpiry,
-		refreshTokenRepo: refreshTokenRepo,
+		jwtSecret:          cfg.Secret,
+		tokenExpiry:        cfg.AccessTokenExpiry,
+		refreshTokenExpiry: cfg.RefreshTokenExpiry,
+		refreshTokenRepo:   refreshTokenRepo,
 	}
 }
 
@@ -77,8 +67,9 @@ func (s *Service) GetRefreshTokenByToken(token string) (*entity.RefreshToken, er
 // GenerateJWT gera um novo token JWT para o usuário
 func (s *Service) GenerateJWT(user *entity.User) (string, error) {
 	claims := TokenClaims{
-		UserID: user.ID,
-		Email:  user.Email,
+		UserID:         user.ID,
+		OrganizationID: user.OrganizationID,
+		Email:          user.Email,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.tokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -138,16 +129,8 @@ func (s *Service) GenerateRefreshToken(userID int64) (*entity.RefreshToken, erro
 	// Converte para base64
 	tokenString := base64.URLEncoding.EncodeToString(tokenBytes)
 
-	// Obtém a duração da expiração do refresh token
-	refreshExpiryStr := os.Getenv("REFRESH_TOKEN_EXPIRY")
-	refreshExpiry, err := time.ParseDuration(refreshExpiryStr)
-	if err != nil {
-		logger.Warning("REFRESH_TOKEN_EXPIRY inválido ou não definido, usando 7 dias como padrão", err)
-		refreshExpiry = 7 * 24 * time.Hour // 7 dias
-	}
-
 	// Cria o token de atualização
-	refreshToken := entity.NewRefreshToken(userID, tokenString, refreshExpiry)
+	refreshToken := entity.NewRefreshToken(userID, tokenString, s.refreshTokenExpiry)
 
 	// Persiste o token
 	err = s.refreshTokenRepo.Create(refreshToken)
@@ -160,7 +143,7 @@ func (s *Service) GenerateRefreshToken(userID int64) (*entity.RefreshToken, erro
 }
 
 // RefreshAccessToken gera um novo token de acesso a partir de um refresh token
-func (s *Service) RefreshAccessToken(refreshTokenStr string, userEmail string) (string, *entity.RefreshToken, error) {
+func (s *Service) RefreshAccessToken(refreshTokenStr string, user *entity.User) (string, *entity.RefreshToken, error) {
 	// Busca o refresh token no repositório
 	refreshToken, err := s.refreshTokenRepo.GetByToken(refreshTokenStr)
 	if err != nil {
@@ -178,10 +161,10 @@ func (s *Service) RefreshAccessToken(refreshTokenStr string, userEmail string) (
 		return "", nil, ErrInvalidToken
 	}
 
-	// Cria um "usuário" temporário apenas para gerar o JWT
-	user := &entity.User{
-		ID:    refreshToken.UserID,
-		Email: userEmail,
+	// O usuário informado precisa ser o dono do refresh token
+	if user == nil || user.ID != refreshToken.UserID {
+		logger.Warning("Refresh token sem usuário correspondente", map[string]interface{}{"user_id": refreshToken.UserID})
+		return "", nil, ErrInvalidToken
 	}
 
 	// Gera um novo token JWT
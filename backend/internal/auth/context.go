@@ -7,8 +7,9 @@ import (
 type contextKey string
 
 const (
-	userIDKey contextKey = "user_id"
-	emailKey  contextKey = "email"
+	userIDKey         contextKey = "user_id"
+	organizationIDKey contextKey = "organization_id"
+	emailKey          contextKey = "email"
 )
 
 // WithUserID adiciona o ID do usuário ao contexto
@@ -22,6 +23,17 @@ func GetUserID(ctx context.Context) (int64, bool) {
 	return userID, ok
 }
 
+// WithOrganizationID adiciona o ID da organização do usuário ao contexto
+func WithOrganizationID(ctx context.Context, organizationID int64) context.Context {
+	return context.WithValue(ctx, organizationIDKey, organizationID)
+}
+
+// GetOrganizationID obtém o ID da organização do usuário do contexto
+func GetOrganizationID(ctx context.Context) (int64, bool) {
+	organizationID, ok := ctx.Value(organizationIDKey).(int64)
+	return organizationID, ok && organizationID > 0
+}
+
 // WithEmail adiciona o email do usuário ao contexto
 func WithEmail(ctx context.Context, email string) context.Context {
 	return context.WithValue(ctx, emailKey, email)
@@ -0,0 +1,214 @@
+// Package automation avalia as mensagens recebidas contra as regras de
+// automação da organização e executa as ações das regras atendidas
+package automation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/whatsapp/backend/internal/businesshours"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+	"github.com/whatsapp/backend/pkg/text"
+)
+
+// Limites das regras
+const (
+	MaxConditions    = 10
+	MaxActions       = 10
+	MaxKeywords      = 50
+	MaxKeywordLength = 100
+	MaxPatternLength = 500
+	MaxReplyLength   = 4096
+	MaxStageLength   = 50
+	MaxCooldown      = 7 * 24 * 60
+)
+
+// Input é a mensagem recebida avaliada pelas regras
+type Input struct {
+	Text string
+	// FirstMessage indica a primeira mensagem de um lead criado por ela
+	FirstMessage bool
+	At           time.Time
+	// Calendars são os calendários de atendimento da organização, usados
+	// pela condição outside_business_hours
+	Calendars []*entity.BusinessCalendar
+}
+
+// ConditionResult informa se uma condição da regra foi atendida
+type ConditionResult struct {
+	Type    string `json:"type"`
+	Matched bool   `json:"matched"`
+}
+
+// Match avalia as condições da regra; a regra é atendida quando todas são
+func Match(rule *entity.AutomationRule, in Input) (bool, []ConditionResult) {
+	normalized := text.Normalize(in.Text)
+	results := make([]ConditionResult, 0, len(rule.Conditions))
+	matched := len(rule.Conditions) > 0
+	for _, c := range rule.Conditions {
+		ok := matchCondition(c, in, normalized)
+		results = append(results, ConditionResult{Type: c.Type, Matched: ok})
+		matched = matched && ok
+	}
+	return matched, results
+}
+
+func matchCondition(c entity.AutomationCondition, in Input, normalized string) bool {
+	switch c.Type {
+	case entity.ConditionKeyword:
+		for _, keyword := range c.Keywords {
+			if k := text.Normalize(keyword); k != "" && strings.Contains(" "+normalized+" ", " "+k+" ") {
+				return true
+			}
+		}
+		return false
+	case entity.ConditionRegex:
+		re, err := regexp.Compile(c.Pattern)
+		return err == nil && re.MatchString(in.Text)
+	case entity.ConditionFirstMessage:
+		return in.FirstMessage
+	case entity.ConditionOutsideBusinessHours:
+		cal := calendarFor(c, in.Calendars)
+		return cal != nil && !cal.IsOpen(in.At)
+	}
+	return false
+}
+
+// calendarFor escolhe o calendário da condição: o indicado em calendar_id,
+// o horário informado na própria condição ou o calendário padrão. Sem
+// calendário a condição não é atendida.
+func calendarFor(c entity.AutomationCondition, calendars []*entity.BusinessCalendar) *businesshours.Calendar {
+	if c.CalendarID == 0 && c.BusinessHours != nil {
+		cal, err := businesshours.FromHours(c.BusinessHours)
+		if err != nil {
+			return nil
+		}
+		return cal
+	}
+	for _, bc := range calendars {
+		if (c.CalendarID != 0 && bc.ID == c.CalendarID) || (c.CalendarID == 0 && bc.IsDefault) {
+			cal, err := businesshours.New(bc)
+			if err != nil {
+				return nil
+			}
+			return cal
+		}
+	}
+	return nil
+}
+
+// UsesCalendars indica se alguma regra depende dos calendários de
+// atendimento da organização, e não apenas de um horário próprio
+func UsesCalendars(rules []*entity.AutomationRule) bool {
+	for _, rule := range rules {
+		for _, c := range rule.Conditions {
+			if c.Type == entity.ConditionOutsideBusinessHours && (c.CalendarID != 0 || c.BusinessHours == nil) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Validate verifica a estrutura da regra. A existência do modelo e do
+// atendente referenciados pelas ações é verificada pelo chamador.
+func Validate(rule *entity.AutomationRule) []response.FieldError {
+	var errs []response.FieldError
+	add := func(field, code, message string) {
+		errs = append(errs, response.FieldError{Field: field, Code: code, Message: message})
+	}
+
+	if rule.CooldownMinutes < 0 || rule.CooldownMinutes > MaxCooldown {
+		add("cooldown_minutes", "range", fmt.Sprintf("Use de 0 a %d minutos", MaxCooldown))
+	}
+
+	switch {
+	case len(rule.Conditions) == 0:
+		add("conditions", "required", "Informe ao menos uma condição")
+	case len(rule.Conditions) > MaxConditions:
+		add("conditions", "max", fmt.Sprintf("Use no máximo %d condições", MaxConditions))
+	}
+	for i, c := range rule.Conditions {
+		field := fmt.Sprintf("conditions[%d]", i)
+		switch c.Type {
+		case entity.ConditionKeyword:
+			if len(c.Keywords) == 0 || len(c.Keywords) > MaxKeywords {
+				add(field+".keywords", "range", fmt.Sprintf("Informe de 1 a %d palavras-chave", MaxKeywords))
+			}
+			for j, k := range c.Keywords {
+				if text.Normalize(k) == "" || utf8.RuneCountInString(k) > MaxKeywordLength {
+					add(fmt.Sprintf("%s.keywords[%d]", field, j), "invalid",
+						fmt.Sprintf("A palavra-chave deve ter letras ou números e no máximo %d caracteres", MaxKeywordLength))
+				}
+			}
+		case entity.ConditionRegex:
+			if c.Pattern == "" || len(c.Pattern) > MaxPatternLength {
+				add(field+".pattern", "range", fmt.Sprintf("Informe a expressão com no máximo %d caracteres", MaxPatternLength))
+			} else if _, err := regexp.Compile(c.Pattern); err != nil {
+				add(field+".pattern", "invalid", "Expressão regular inválida: "+err.Error())
+			}
+		case entity.ConditionFirstMessage:
+		case entity.ConditionOutsideBusinessHours:
+			switch {
+			case c.CalendarID < 0:
+				add(field+".calendar_id", "invalid", "Informe o ID do calendário ou omita para usar o calendário padrão")
+			case c.CalendarID > 0 && c.BusinessHours != nil:
+				add(field, "invalid", "Informe calendar_id ou business_hours, não os dois")
+			case c.BusinessHours != nil:
+				errs = append(errs, businesshours.ValidateHours(c.BusinessHours, field+".business_hours")...)
+			}
+		default:
+			add(field+".type", "oneof", "Use um dos valores: "+strings.Join(entity.AutomationConditions, ", "))
+		}
+	}
+
+	switch {
+	case len(rule.Actions) == 0:
+		add("actions", "required", "Informe ao menos uma ação")
+	case len(rule.Actions) > MaxActions:
+		add("actions", "max", fmt.Sprintf("Use no máximo %d ações", MaxActions))
+	}
+	replies := 0
+	for i, a := range rule.Actions {
+		field := fmt.Sprintf("actions[%d]", i)
+		if a.IsReply() {
+			replies++
+		}
+		switch a.Type {
+		case entity.ActionSendText:
+			if strings.TrimSpace(a.Text) == "" || utf8.RuneCountInString(a.Text) > MaxReplyLength {
+				add(field+".text", "range", fmt.Sprintf("Informe o texto com no máximo %d caracteres", MaxReplyLength))
+			}
+		case entity.ActionSendTemplate:
+			if a.TemplateID <= 0 {
+				add(field+".template_id", "required", "Informe o modelo da mensagem")
+			}
+		case entity.ActionAddTag:
+			if !entity.IsValidTagName(strings.TrimSpace(a.Tag)) {
+				add(field+".tag", "invalid", fmt.Sprintf("Informe uma etiqueta sem vírgulas, com até %d caracteres", entity.MaxTagNameLength))
+			}
+		case entity.ActionMoveStage:
+			if strings.TrimSpace(a.Stage) == "" || utf8.RuneCountInString(a.Stage) > MaxStageLength {
+				add(field+".stage", "range", fmt.Sprintf("Informe a etapa com no máximo %d caracteres", MaxStageLength))
+			}
+		case entity.ActionAssign:
+			if a.UserID < 0 {
+				add(field+".user_id", "invalid", "Informe o ID do atendente ou omita para distribuir automaticamente")
+			}
+		case entity.ActionStartFlow:
+			if a.FlowID <= 0 {
+				add(field+".flow_id", "required", "Informe o fluxo do chatbot")
+			}
+		default:
+			add(field+".type", "oneof", "Use um dos valores: "+strings.Join(entity.AutomationActions, ", "))
+		}
+	}
+	if replies > 1 {
+		add("actions", "multiple_replies", "Use no máximo uma ação de envio ou início de fluxo por regra")
+	}
+	return errs
+}
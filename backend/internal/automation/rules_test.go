@@ -0,0 +1,264 @@
+package automation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// codes resume os erros como "campo:código" para comparar nas tabelas
+func codes(errs []response.FieldError) string {
+	parts := make([]string, len(errs))
+	for i, e := range errs {
+		parts[i] = e.Field + ":" + e.Code
+	}
+	return strings.Join(parts, " ")
+}
+
+// weekdays monta o expediente de segunda a sexta no horário informado
+func weekdays(open, close string) []entity.BusinessDay {
+	days := make([]entity.BusinessDay, 0, 5)
+	for wd := 1; wd <= 5; wd++ {
+		days = append(days, entity.BusinessDay{Weekday: wd, Open: open, Close: close})
+	}
+	return days
+}
+
+func keyword(words ...string) entity.AutomationCondition {
+	return entity.AutomationCondition{Type: entity.ConditionKeyword, Keywords: words}
+}
+
+func TestMatchKeyword(t *testing.T) {
+	tests := []struct {
+		text     string
+		keywords []string
+		want     bool
+	}{
+		{"Qual o preço?", []string{"preco"}, true},
+		{"QUAL O PREÇO", []string{"Preço"}, true},
+		{"quero um orçamento", []string{"orcamento", "preco"}, true},
+		{"quero saber o valor", []string{"orcamento", "preco"}, false},
+		{"precos", []string{"preco"}, false},
+		{"apreço", []string{"preco"}, false},
+		{"segunda via do boleto", []string{"segunda via"}, true},
+		{"segunda-via", []string{"segunda via"}, true},
+		{"segunda e via", []string{"segunda via"}, false},
+		{"", []string{"preco"}, false},
+		{"qualquer texto", []string{"!!"}, false},
+	}
+
+	for _, tt := range tests {
+		rule := &entity.AutomationRule{Conditions: []entity.AutomationCondition{keyword(tt.keywords...)}}
+		if got, _ := Match(rule, Input{Text: tt.text}); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, esperado %v", tt.text, tt.keywords, got, tt.want)
+		}
+	}
+}
+
+func TestMatchRegex(t *testing.T) {
+	tests := []struct {
+		text    string
+		pattern string
+		want    bool
+	}{
+		{"pedido 12345", `\d{5}`, true},
+		{"pedido 123", `\d{5}`, false},
+		{"Preço", `^Preço$`, true},
+		// A expressão é avaliada no texto original, sem normalização
+		{"Preço", `^preco$`, false},
+		{"Preço", `(?i)^preço$`, true},
+		{"qualquer", `(`, false},
+	}
+
+	for _, tt := range tests {
+		rule := &entity.AutomationRule{Conditions: []entity.AutomationCondition{{Type: entity.ConditionRegex, Pattern: tt.pattern}}}
+		if got, _ := Match(rule, Input{Text: tt.text}); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, esperado %v", tt.text, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestMatchOutsideBusinessHours(t *testing.T) {
+	sp, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Fatalf("LoadLocation retornou erro: %v", err)
+	}
+	// 17/06/2024 é uma segunda-feira
+	open := time.Date(2024, 6, 17, 10, 0, 0, 0, sp)
+	closed := time.Date(2024, 6, 17, 20, 0, 0, 0, sp)
+	sunday := time.Date(2024, 6, 16, 10, 0, 0, 0, sp)
+
+	hours := &entity.BusinessHours{TimeZone: "America/Sao_Paulo", Days: weekdays("09:00", "18:00")}
+	calendars := []*entity.BusinessCalendar{
+		{ID: 1, TimeZone: "America/Sao_Paulo", Days: weekdays("09:00", "18:00"), IsDefault: true},
+		{ID: 2, TimeZone: "America/Sao_Paulo", Days: weekdays("08:00", "22:00")},
+	}
+	outside := func(calendarID int64, h *entity.BusinessHours) entity.AutomationCondition {
+		return entity.AutomationCondition{Type: entity.ConditionOutsideBusinessHours, CalendarID: calendarID, BusinessHours: h}
+	}
+
+	tests := []struct {
+		name      string
+		condition entity.AutomationCondition
+		calendars []*entity.BusinessCalendar
+		at        time.Time
+		want      bool
+	}{
+		{"horário próprio, aberto", outside(0, hours), nil, open, false},
+		{"horário próprio, fechado", outside(0, hours), nil, closed, true},
+		{"horário próprio, domingo", outside(0, hours), nil, sunday, true},
+		{"horário próprio com fuso inválido", outside(0, &entity.BusinessHours{TimeZone: "America/Inexistente"}), nil, closed, false},
+		{"calendário padrão, aberto", outside(0, nil), calendars, open, false},
+		{"calendário padrão, fechado", outside(0, nil), calendars, closed, true},
+		{"calendário indicado", outside(2, nil), calendars, closed, false},
+		{"calendário inexistente", outside(3, nil), calendars, closed, false},
+		{"sem calendário padrão", outside(0, nil), calendars[1:], closed, false},
+		{"sem calendários", outside(0, nil), nil, closed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &entity.AutomationRule{Conditions: []entity.AutomationCondition{tt.condition}}
+			if got, _ := Match(rule, Input{At: tt.at, Calendars: tt.calendars}); got != tt.want {
+				t.Errorf("Match(%s) = %v, esperado %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchAllConditions(t *testing.T) {
+	first := entity.AutomationCondition{Type: entity.ConditionFirstMessage}
+
+	tests := []struct {
+		name       string
+		conditions []entity.AutomationCondition
+		in         Input
+		want       bool
+		results    []ConditionResult
+	}{
+		{"sem condições", nil, Input{Text: "oi"}, false, []ConditionResult{}},
+		{"primeira mensagem", []entity.AutomationCondition{first}, Input{FirstMessage: true}, true,
+			[]ConditionResult{{entity.ConditionFirstMessage, true}}},
+		{"mensagem seguinte", []entity.AutomationCondition{first}, Input{}, false,
+			[]ConditionResult{{entity.ConditionFirstMessage, false}}},
+		{"todas atendidas", []entity.AutomationCondition{first, keyword("oi")}, Input{Text: "Oi!", FirstMessage: true}, true,
+			[]ConditionResult{{entity.ConditionFirstMessage, true}, {entity.ConditionKeyword, true}}},
+		{"uma não atendida", []entity.AutomationCondition{first, keyword("oi")}, Input{Text: "Oi!"}, false,
+			[]ConditionResult{{entity.ConditionFirstMessage, false}, {entity.ConditionKeyword, true}}},
+		{"tipo desconhecido", []entity.AutomationCondition{{Type: "sentiment"}}, Input{Text: "oi"}, false,
+			[]ConditionResult{{"sentiment", false}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, results := Match(&entity.AutomationRule{Conditions: tt.conditions}, tt.in)
+			if got != tt.want || !reflect.DeepEqual(results, tt.results) {
+				t.Errorf("Match = %v, %+v; esperado %v, %+v", got, results, tt.want, tt.results)
+			}
+		})
+	}
+}
+
+func TestUsesCalendars(t *testing.T) {
+	hours := &entity.BusinessHours{TimeZone: "America/Sao_Paulo"}
+	rule := func(conditions ...entity.AutomationCondition) *entity.AutomationRule {
+		return &entity.AutomationRule{Conditions: conditions}
+	}
+
+	tests := []struct {
+		name  string
+		rules []*entity.AutomationRule
+		want  bool
+	}{
+		{"sem regras", nil, false},
+		{"só palavras-chave", []*entity.AutomationRule{rule(keyword("oi"))}, false},
+		{"horário próprio", []*entity.AutomationRule{rule(entity.AutomationCondition{Type: entity.ConditionOutsideBusinessHours, BusinessHours: hours})}, false},
+		{"calendário padrão", []*entity.AutomationRule{rule(keyword("oi")), rule(entity.AutomationCondition{Type: entity.ConditionOutsideBusinessHours})}, true},
+		{"calendário indicado", []*entity.AutomationRule{rule(entity.AutomationCondition{Type: entity.ConditionOutsideBusinessHours, CalendarID: 2})}, true},
+	}
+
+	for _, tt := range tests {
+		if got := UsesCalendars(tt.rules); got != tt.want {
+			t.Errorf("UsesCalendars(%s) = %v, esperado %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *entity.AutomationRule {
+		return &entity.AutomationRule{
+			Conditions: []entity.AutomationCondition{keyword("preço")},
+			Actions:    []entity.AutomationAction{{Type: entity.ActionSendText, Text: "Segue nossa tabela"}},
+		}
+	}
+	condition := func(c entity.AutomationCondition) func(r *entity.AutomationRule) {
+		return func(r *entity.AutomationRule) { r.Conditions = []entity.AutomationCondition{c} }
+	}
+	action := func(a ...entity.AutomationAction) func(r *entity.AutomationRule) {
+		return func(r *entity.AutomationRule) { r.Actions = a }
+	}
+	hours := &entity.BusinessHours{TimeZone: "America/Sao_Paulo", Days: weekdays("09:00", "18:00")}
+
+	tests := []struct {
+		name string
+		edit func(r *entity.AutomationRule)
+		want string
+	}{
+		{"válida", func(r *entity.AutomationRule) {}, ""},
+		{"intervalo negativo", func(r *entity.AutomationRule) { r.CooldownMinutes = -1 }, "cooldown_minutes:range"},
+		{"intervalo acima do limite", func(r *entity.AutomationRule) { r.CooldownMinutes = MaxCooldown + 1 }, "cooldown_minutes:range"},
+		{"intervalo no limite", func(r *entity.AutomationRule) { r.CooldownMinutes = MaxCooldown }, ""},
+		{"sem condições", func(r *entity.AutomationRule) { r.Conditions = nil }, "conditions:required"},
+		{"condições demais", func(r *entity.AutomationRule) {
+			for i := 0; i < MaxConditions; i++ {
+				r.Conditions = append(r.Conditions, entity.AutomationCondition{Type: entity.ConditionFirstMessage})
+			}
+		}, "conditions:max"},
+		{"sem palavras-chave", condition(keyword()), "conditions[0].keywords:range"},
+		{"palavra-chave só com pontuação", condition(keyword("oi", "?!")), "conditions[0].keywords[1]:invalid"},
+		{"palavra-chave longa", condition(keyword(strings.Repeat("a", MaxKeywordLength+1))), "conditions[0].keywords[0]:invalid"},
+		{"expressão válida", condition(entity.AutomationCondition{Type: entity.ConditionRegex, Pattern: `\d+`}), ""},
+		{"expressão vazia", condition(entity.AutomationCondition{Type: entity.ConditionRegex}), "conditions[0].pattern:range"},
+		{"expressão longa", condition(entity.AutomationCondition{Type: entity.ConditionRegex, Pattern: strings.Repeat("a", MaxPatternLength+1)}), "conditions[0].pattern:range"},
+		{"expressão inválida", condition(entity.AutomationCondition{Type: entity.ConditionRegex, Pattern: `(`}), "conditions[0].pattern:invalid"},
+		{"primeira mensagem", condition(entity.AutomationCondition{Type: entity.ConditionFirstMessage}), ""},
+		{"fora do expediente padrão", condition(entity.AutomationCondition{Type: entity.ConditionOutsideBusinessHours}), ""},
+		{"fora do horário próprio", condition(entity.AutomationCondition{Type: entity.ConditionOutsideBusinessHours, BusinessHours: hours}), ""},
+		{"calendário negativo", condition(entity.AutomationCondition{Type: entity.ConditionOutsideBusinessHours, CalendarID: -1}), "conditions[0].calendar_id:invalid"},
+		{"calendário e horário próprio", condition(entity.AutomationCondition{Type: entity.ConditionOutsideBusinessHours, CalendarID: 1, BusinessHours: hours}), "conditions[0]:invalid"},
+		{"tipo de condição desconhecido", condition(entity.AutomationCondition{Type: "sentiment"}), "conditions[0].type:oneof"},
+		{"sem ações", action(), "actions:required"},
+		{"texto vazio", action(entity.AutomationAction{Type: entity.ActionSendText, Text: " "}), "actions[0].text:range"},
+		{"texto longo", action(entity.AutomationAction{Type: entity.ActionSendText, Text: strings.Repeat("a", MaxReplyLength+1)}), "actions[0].text:range"},
+		{"modelo sem ID", action(entity.AutomationAction{Type: entity.ActionSendTemplate}), "actions[0].template_id:required"},
+		{"etiqueta com vírgula", action(entity.AutomationAction{Type: entity.ActionAddTag, Tag: "a,b"}), "actions[0].tag:invalid"},
+		{"etapa vazia", action(entity.AutomationAction{Type: entity.ActionMoveStage}), "actions[0].stage:range"},
+		{"distribuição automática", action(entity.AutomationAction{Type: entity.ActionAssign}), ""},
+		{"atendente negativo", action(entity.AutomationAction{Type: entity.ActionAssign, UserID: -1}), "actions[0].user_id:invalid"},
+		{"fluxo sem ID", action(entity.AutomationAction{Type: entity.ActionStartFlow}), "actions[0].flow_id:required"},
+		{"tipo de ação desconhecido", action(entity.AutomationAction{Type: "webhook"}), "actions[0].type:oneof"},
+		{"resposta e etiqueta", action(
+			entity.AutomationAction{Type: entity.ActionSendText, Text: "Olá"},
+			entity.AutomationAction{Type: entity.ActionAddTag, Tag: "novo"},
+		), ""},
+		{"duas respostas", action(
+			entity.AutomationAction{Type: entity.ActionSendText, Text: "Olá"},
+			entity.AutomationAction{Type: entity.ActionStartFlow, FlowID: 1},
+		), "actions:multiple_replies"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := valid()
+			tt.edit(rule)
+			if got := codes(Validate(rule)); got != tt.want {
+				t.Errorf("Validate = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package automation
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/chatbot"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/messaging"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// Proteção contra laços: no máximo maxReplies mensagens automáticas por
+// conversa em replyWindow, interrompendo conversas entre a automação e
+// outros robôs de atendimento. Cada regra também respeita o próprio
+// intervalo (CooldownMinutes) na conversa.
+const (
+	maxReplies  = 3
+	replyWindow = 10 * time.Minute
+)
+
+// Repository lista as regras ativas e registra as execuções
+type Repository interface {
+	ListEnabled(organizationID int64) ([]*entity.AutomationRule, error)
+	RunSummary(conversationID int64, since, repliesSince time.Time) (map[int64]time.Time, int, error)
+	RecordRun(run *entity.AutomationRun) error
+}
+
+// Sender envia as respostas na conversa
+type Sender interface {
+	Send(req messaging.SendRequest) (*entity.Message, error)
+}
+
+// LeadUpdater altera a etapa do lead, registrando a mudança no histórico
+type LeadUpdater interface {
+	Update(organizationID, id, userID int64, apply func(lead *entity.Lead) error) (*entity.Lead, error)
+}
+
+// Tagger adiciona etiquetas ao lead
+type Tagger interface {
+	AddLeadTags(organizationID, leadID int64, names []string) error
+}
+
+// Assigner atribui o lead a um atendente escolhido ou pela distribuição automática
+type Assigner interface {
+	Assign(organizationID, leadID, conversationID int64, skill string) (int64, error)
+	AssignTo(organizationID, leadID, conversationID, userID int64) error
+}
+
+// FlowStarter inicia os fluxos do chatbot na conversa
+type FlowStarter interface {
+	Start(organizationID, flowID, conversationID, leadID int64) (*entity.FlowSession, error)
+}
+
+// CalendarSource lista os calendários de atendimento da organização
+type CalendarSource interface {
+	List(organizationID int64) ([]*entity.BusinessCalendar, error)
+}
+
+// Evaluation é o resultado da avaliação de uma regra no teste das regras
+type Evaluation struct {
+	RuleID     int64             `json:"rule_id"`
+	Name       string            `json:"name"`
+	Matched    bool              `json:"matched"`
+	Conditions []ConditionResult `json:"conditions"`
+	// Actions lista as ações que seriam executadas
+	Actions []entity.AutomationAction `json:"actions"`
+	// Reached é falso quando uma regra anterior com stop_processing
+	// encerrou a avaliação
+	Reached bool `json:"reached"`
+}
+
+// Service executa as regras de automação sobre as mensagens recebidas
+type Service struct {
+	rules     Repository
+	sender    Sender
+	leads     LeadUpdater
+	tags      Tagger
+	assigner  Assigner
+	flows     FlowStarter
+	calendars CalendarSource
+}
+
+// NewService cria uma nova instância do serviço de automação
+func NewService(rules Repository, sender Sender, leads LeadUpdater, tags Tagger, assigner Assigner, flows FlowStarter, calendars CalendarSource) *Service {
+	return &Service{
+		rules:     rules,
+		sender:    sender,
+		leads:     leads,
+		tags:      tags,
+		assigner:  assigner,
+		flows:     flows,
+		calendars: calendars,
+	}
+}
+
+// Evaluate avalia a mensagem contra as regras, na ordem, sem executar as
+// ações nem considerar o intervalo das regras e a proteção contra laços
+func Evaluate(rules []*entity.AutomationRule, in Input) []Evaluation {
+	evaluations := make([]Evaluation, 0, len(rules))
+	reached := true
+	for _, rule := range rules {
+		matched, conditions := Match(rule, in)
+		e := Evaluation{
+			RuleID:     rule.ID,
+			Name:       rule.Name,
+			Matched:    matched,
+			Conditions: conditions,
+			Actions:    []entity.AutomationAction{},
+			Reached:    reached,
+		}
+		if matched && reached {
+			e.Actions = rule.Actions
+			if rule.StopProcessing {
+				reached = false
+			}
+		}
+		evaluations = append(evaluations, e)
+	}
+	return evaluations
+}
+
+// HandleInbound executa as regras ativas da organização sobre a mensagem
+// recebida. Mensagens repetidas pelo provedor e pedidos de descadastro não
+// são avaliados. Falhas ficam no log e no registro da execução, sem desfazer
+// a mensagem gravada.
+func (s *Service) HandleInbound(result *entity.InboundResult) {
+	if result.Duplicate || result.OptedOut {
+		return
+	}
+	message := result.Message
+	rules, err := s.rules.ListEnabled(message.OrganizationID)
+	if err != nil || len(rules) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	lastRuns, replies, err := s.rules.RunSummary(result.Conversation.ID, now.Add(-MaxCooldown*time.Minute), now.Add(-replyWindow))
+	if err != nil {
+		return
+	}
+
+	in := Input{Text: message.Body, FirstMessage: result.LeadCreated, At: message.CreatedAt}
+	if in.At.IsZero() {
+		in.At = now
+	}
+	if UsesCalendars(rules) {
+		if in.Calendars, err = s.calendars.List(message.OrganizationID); err != nil {
+			return
+		}
+	}
+	for _, rule := range rules {
+		if matched, _ := Match(rule, in); !matched {
+			continue
+		}
+		if last, ok := lastRuns[rule.ID]; ok && now.Sub(last) < time.Duration(rule.CooldownMinutes)*time.Minute {
+			continue
+		}
+		if hasReply(rule) && replies >= maxReplies {
+			logger.Warning("Regra de automação ignorada pela proteção contra laços", map[string]interface{}{
+				"rule_id":         rule.ID,
+				"conversation_id": result.Conversation.ID,
+			})
+			continue
+		}
+
+		run := s.execute(rule, result)
+		run.CreatedAt = now
+		_ = s.rules.RecordRun(run)
+		replies += run.Replies
+
+		if rule.StopProcessing {
+			return
+		}
+	}
+}
+
+// execute executa as ações da regra em ordem; a falha de uma ação não
+// impede as seguintes
+func (s *Service) execute(rule *entity.AutomationRule, result *entity.InboundResult) *entity.AutomationRun {
+	orgID := result.Message.OrganizationID
+	leadID := result.Lead.ID
+	conversationID := result.Conversation.ID
+
+	run := &entity.AutomationRun{
+		OrganizationID: orgID,
+		RuleID:         rule.ID,
+		ConversationID: conversationID,
+		LeadID:         leadID,
+		MessageID:      result.Message.ID,
+		Results:        make([]entity.AutomationActionResult, 0, len(rule.Actions)),
+	}
+
+	for _, action := range rule.Actions {
+		status := entity.ActionResultDone
+		var err error
+		switch action.Type {
+		case entity.ActionSendText, entity.ActionSendTemplate:
+			_, err = s.sender.Send(messaging.SendRequest{
+				OrganizationID: orgID,
+				ConversationID: conversationID,
+				Text:           action.Text,
+				TemplateID:     action.TemplateID,
+			})
+			if err == nil {
+				run.Replies++
+			}
+		case entity.ActionAddTag:
+			err = s.tags.AddLeadTags(orgID, leadID, []string{strings.TrimSpace(action.Tag)})
+		case entity.ActionMoveStage:
+			_, err = s.leads.Update(orgID, leadID, 0, func(lead *entity.Lead) error {
+				lead.Stage = strings.TrimSpace(action.Stage)
+				return nil
+			})
+		case entity.ActionAssign:
+			if action.UserID > 0 {
+				err = s.assigner.AssignTo(orgID, leadID, conversationID, action.UserID)
+				break
+			}
+			var agentID int64
+			agentID, err = s.assigner.Assign(orgID, leadID, conversationID, entity.NormalizeSkill(action.Skill))
+			if err == nil && agentID == 0 {
+				status = entity.ActionResultSkipped
+			}
+		case entity.ActionStartFlow:
+			// Um fluxo já em andamento na conversa é mantido
+			_, err = s.flows.Start(orgID, action.FlowID, conversationID, leadID)
+			switch {
+			case errors.Is(err, chatbot.ErrSessionActive):
+				status, err = entity.ActionResultSkipped, nil
+			case err == nil:
+				run.Replies++
+			}
+		default:
+			status = entity.ActionResultSkipped
+		}
+
+		r := entity.AutomationActionResult{Type: action.Type, Status: status}
+		if err != nil {
+			r.Status = entity.ActionResultFailed
+			r.Error = err.Error()
+			logger.Error("Erro ao executar ação de automação", map[string]interface{}{
+				"rule_id": rule.ID,
+				"action":  action.Type,
+				"error":   err.Error(),
+			})
+		}
+		run.Results = append(run.Results, r)
+	}
+	return run
+}
+
+func hasReply(rule *entity.AutomationRule) bool {
+	for _, a := range rule.Actions {
+		if a.IsReply() {
+			return true
+		}
+	}
+	return false
+}
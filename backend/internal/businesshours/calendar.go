@@ -0,0 +1,223 @@
+// Package businesshours calcula o horário de atendimento dos calendários:
+// se o atendimento está aberto, quando abre e fecha e quanto tempo útil
+// passou entre dois instantes, descontando os feriados
+package businesshours
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// searchDays limita a busca pela próxima abertura e os prazos calculados,
+// para calendários que quase nunca abrem
+const searchDays = 3 * 366
+
+// Calendar é um calendário pronto para os cálculos, com o fuso carregado
+type Calendar struct {
+	cal *entity.BusinessCalendar
+	loc *time.Location
+}
+
+// interval é um período contínuo de atendimento
+type interval struct {
+	start, end time.Time
+}
+
+// New prepara o calendário, retornando erro se o fuso for desconhecido
+func New(cal *entity.BusinessCalendar) (*Calendar, error) {
+	loc, err := time.LoadLocation(cal.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	return &Calendar{cal: cal, loc: loc}, nil
+}
+
+// FromHours prepara um horário semanal sem feriados, como o informado
+// diretamente nas regras de automação
+func FromHours(h *entity.BusinessHours) (*Calendar, error) {
+	return New(&entity.BusinessCalendar{TimeZone: h.TimeZone, Days: h.Days})
+}
+
+// Location retorna o fuso do calendário
+func (c *Calendar) Location() *time.Location {
+	return c.loc
+}
+
+// IsOpen informa se o instante está dentro de um intervalo de atendimento
+func (c *Calendar) IsOpen(at time.Time) bool {
+	for _, iv := range c.intervals(at.In(c.loc), 0) {
+		if !at.Before(iv.start) && at.Before(iv.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpening retorna o próprio instante se o atendimento estiver aberto ou
+// o início do próximo intervalo. Retorna false se o calendário não abrir
+// dentro do limite de busca.
+func (c *Calendar) NextOpening(at time.Time) (time.Time, bool) {
+	local := at.In(c.loc)
+	for i := 0; i < searchDays; i++ {
+		for _, iv := range c.intervals(local, i) {
+			if !iv.end.After(at) {
+				continue
+			}
+			if iv.start.After(at) {
+				return iv.start, true
+			}
+			return local, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// NextClosing retorna o fim do período de atendimento em andamento, juntando
+// intervalos contíguos, como um dia até 24:00 seguido de outro desde 00:00.
+// Retorna false se o atendimento estiver fechado ou não fechar dentro do
+// limite de busca.
+func (c *Calendar) NextClosing(at time.Time) (time.Time, bool) {
+	if !c.IsOpen(at) {
+		return time.Time{}, false
+	}
+	local := at.In(c.loc)
+	end := local
+	for i := 0; i < searchDays; i++ {
+		for _, iv := range c.intervals(local, i) {
+			if !iv.end.After(end) {
+				continue
+			}
+			if iv.start.After(end) {
+				return end, true
+			}
+			end = iv.end
+		}
+	}
+	return time.Time{}, false
+}
+
+// Between retorna o tempo de atendimento entre os dois instantes, usado nos
+// relógios de SLA que param fora do horário e nos feriados
+func (c *Calendar) Between(from, to time.Time) time.Duration {
+	var total time.Duration
+	local := from.In(c.loc)
+	for i := 0; i < searchDays; i++ {
+		list := c.intervals(local, i)
+		for _, iv := range list {
+			start, end := iv.start, iv.end
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+		if !dayStart(local, i+1, c.loc).Before(to) {
+			break
+		}
+	}
+	return total
+}
+
+// Add retorna o instante em que o tempo de atendimento informado se esgota,
+// contando a partir de from. Retorna false se o prazo passar do limite de
+// busca.
+func (c *Calendar) Add(from time.Time, d time.Duration) (time.Time, bool) {
+	local := from.In(c.loc)
+	remaining := d
+	for i := 0; i < searchDays; i++ {
+		for _, iv := range c.intervals(local, i) {
+			if !iv.end.After(from) {
+				continue
+			}
+			start := iv.start
+			if start.Before(from) {
+				start = from.In(c.loc)
+			}
+			available := iv.end.Sub(start)
+			if remaining <= available {
+				return start.Add(remaining), true
+			}
+			remaining -= available
+		}
+	}
+	return time.Time{}, false
+}
+
+// Holiday retorna o nome do feriado no dia local do instante
+func (c *Calendar) Holiday(at time.Time) (string, bool) {
+	local := at.In(c.loc)
+	date := local.Format("2006-01-02")
+	for _, h := range c.cal.Holidays {
+		if h.Date == date || (h.Recurring && len(h.Date) == len(date) && h.Date[4:] == date[4:]) {
+			return h.Name, true
+		}
+	}
+	if c.cal.NationalHolidays || c.cal.OptionalHolidays {
+		for _, h := range NationalHolidays(local.Year()) {
+			if h.Date != date {
+				continue
+			}
+			if (h.Optional && c.cal.OptionalHolidays) || (!h.Optional && c.cal.NationalHolidays) {
+				return h.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// intervals retorna os intervalos do dia local deslocado em offset dias,
+// ordenados e sem sobreposição. Os feriados não têm intervalos.
+func (c *Calendar) intervals(local time.Time, offset int) []interval {
+	day := dayStart(local, offset, c.loc)
+	if _, ok := c.Holiday(day); ok {
+		return nil
+	}
+	year, month, d := day.Date()
+
+	var list []interval
+	for _, b := range c.cal.Days {
+		if b.Weekday != int(day.Weekday()) {
+			continue
+		}
+		list = append(list, interval{
+			start: clockTime(year, month, d, b.Open, c.loc),
+			end:   clockTime(year, month, d, b.Close, c.loc),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].start.Before(list[j].start) })
+
+	merged := list[:0]
+	for _, iv := range list {
+		if n := len(merged); n > 0 && !iv.start.After(merged[n-1].end) {
+			if iv.end.After(merged[n-1].end) {
+				merged[n-1].end = iv.end
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// dayStart retorna a meia-noite do dia local deslocado em offset dias
+func dayStart(local time.Time, offset int, loc *time.Location) time.Time {
+	year, month, day := local.Date()
+	return time.Date(year, month, day+offset, 0, 0, 0, 0, loc)
+}
+
+// clockTime converte um horário HH:MM do dia; 24:00 é a meia-noite seguinte
+func clockTime(year int, month time.Month, day int, clock string, loc *time.Location) time.Time {
+	var hour, minute int
+	if len(clock) == 5 {
+		hour, _ = strconv.Atoi(clock[:2])
+		minute, _ = strconv.Atoi(clock[3:])
+	}
+	return time.Date(year, month, day, hour, minute, 0, 0, loc)
+}
@@ -0,0 +1,272 @@
+package businesshours
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// weekdays monta o expediente de segunda a sexta no horário informado
+func weekdays(open, close string) []entity.BusinessDay {
+	days := make([]entity.BusinessDay, 0, 5)
+	for wd := 1; wd <= 5; wd++ {
+		days = append(days, entity.BusinessDay{Weekday: wd, Open: open, Close: close})
+	}
+	return days
+}
+
+func mustCalendar(t *testing.T, cal *entity.BusinessCalendar) *Calendar {
+	t.Helper()
+	c, err := New(cal)
+	if err != nil {
+		t.Fatalf("New(%q) retornou erro: %v", cal.TimeZone, err)
+	}
+	return c
+}
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q) retornou erro: %v", name, err)
+	}
+	return loc
+}
+
+func TestNewUnknownTimeZone(t *testing.T) {
+	if _, err := New(&entity.BusinessCalendar{TimeZone: "America/Inexistente"}); err == nil {
+		t.Error("New com fuso desconhecido não retornou erro")
+	}
+}
+
+func TestIsOpen(t *testing.T) {
+	sp := mustLocation(t, "America/Sao_Paulo")
+	c := mustCalendar(t, &entity.BusinessCalendar{
+		TimeZone:         "America/Sao_Paulo",
+		Days:             weekdays("09:00", "18:00"),
+		NationalHolidays: true,
+		Holidays: []entity.Holiday{
+			{Date: "2024-06-19", Name: "Inventário"},
+			{Date: "2000-07-09", Name: "Revolução Constitucionalista", Recurring: true},
+		},
+	})
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		open    bool
+		holiday string
+	}{
+		{"abertura", time.Date(2024, 6, 17, 9, 0, 0, 0, sp), true, ""},
+		{"antes da abertura", time.Date(2024, 6, 17, 8, 59, 0, 0, sp), false, ""},
+		{"fechamento", time.Date(2024, 6, 17, 18, 0, 0, 0, sp), false, ""},
+		{"sábado", time.Date(2024, 6, 15, 10, 0, 0, 0, sp), false, ""},
+		{"instante em UTC convertido para o fuso", time.Date(2024, 6, 17, 20, 30, 0, 0, time.UTC), true, ""},
+		{"feriado próprio em dia útil", time.Date(2024, 6, 19, 10, 0, 0, 0, sp), false, "Inventário"},
+		{"feriado recorrente em outro ano", time.Date(2025, 7, 9, 10, 0, 0, 0, sp), false, "Revolução Constitucionalista"},
+		{"feriado nacional em dia útil", time.Date(2024, 11, 20, 10, 0, 0, 0, sp), false, "Dia Nacional de Zumbi e da Consciência Negra"},
+		{"ponto facultativo sem OptionalHolidays", time.Date(2024, 2, 12, 10, 0, 0, 0, sp), true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.IsOpen(tt.at); got != tt.open {
+				t.Errorf("IsOpen(%v) = %v, esperado %v", tt.at, got, tt.open)
+			}
+			name, ok := c.Holiday(tt.at)
+			if name != tt.holiday || ok != (tt.holiday != "") {
+				t.Errorf("Holiday(%v) = %q, %v; esperado %q", tt.at, name, ok, tt.holiday)
+			}
+		})
+	}
+}
+
+func TestOptionalHolidays(t *testing.T) {
+	sp := mustLocation(t, "America/Sao_Paulo")
+	c := mustCalendar(t, &entity.BusinessCalendar{
+		TimeZone:         "America/Sao_Paulo",
+		Days:             weekdays("09:00", "18:00"),
+		OptionalHolidays: true,
+	})
+
+	tests := []struct {
+		name string
+		at   time.Time
+		open bool
+	}{
+		{"segunda de Carnaval", time.Date(2024, 2, 12, 10, 0, 0, 0, sp), false},
+		{"Corpus Christi", time.Date(2024, 5, 30, 10, 0, 0, 0, sp), false},
+		{"feriado nacional sem NationalHolidays", time.Date(2024, 11, 20, 10, 0, 0, 0, sp), true},
+	}
+
+	for _, tt := range tests {
+		if got := c.IsOpen(tt.at); got != tt.open {
+			t.Errorf("%s: IsOpen(%v) = %v, esperado %v", tt.name, tt.at, got, tt.open)
+		}
+	}
+}
+
+func TestNextOpening(t *testing.T) {
+	sp := mustLocation(t, "America/Sao_Paulo")
+	c := mustCalendar(t, &entity.BusinessCalendar{
+		TimeZone:         "America/Sao_Paulo",
+		Days:             weekdays("09:00", "18:00"),
+		NationalHolidays: true,
+	})
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want time.Time
+	}{
+		{"aberto", time.Date(2024, 6, 17, 10, 0, 0, 0, sp), time.Date(2024, 6, 17, 10, 0, 0, 0, sp)},
+		{"antes da abertura", time.Date(2024, 6, 17, 7, 0, 0, 0, sp), time.Date(2024, 6, 17, 9, 0, 0, 0, sp)},
+		{"sexta depois do fechamento", time.Date(2024, 6, 14, 18, 0, 0, 0, sp), time.Date(2024, 6, 17, 9, 0, 0, 0, sp)},
+		{"véspera de feriado", time.Date(2024, 11, 19, 19, 0, 0, 0, sp), time.Date(2024, 11, 21, 9, 0, 0, 0, sp)},
+	}
+
+	for _, tt := range tests {
+		got, ok := c.NextOpening(tt.at)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("%s: NextOpening(%v) = %v, %v; esperado %v", tt.name, tt.at, got, ok, tt.want)
+		}
+	}
+
+	closed := mustCalendar(t, &entity.BusinessCalendar{TimeZone: "America/Sao_Paulo"})
+	if got, ok := closed.NextOpening(time.Date(2024, 6, 17, 10, 0, 0, 0, sp)); ok {
+		t.Errorf("NextOpening sem expediente = %v, esperado false", got)
+	}
+}
+
+// Um expediente até 24:00 seguido de outro desde 00:00 é um único período
+func TestNextClosingAcrossMidnight(t *testing.T) {
+	sp := mustLocation(t, "America/Sao_Paulo")
+	c := mustCalendar(t, &entity.BusinessCalendar{
+		TimeZone: "America/Sao_Paulo",
+		Days: []entity.BusinessDay{
+			{Weekday: 1, Open: "18:00", Close: "24:00"},
+			{Weekday: 2, Open: "00:00", Close: "02:00"},
+			{Weekday: 2, Open: "08:00", Close: "12:00"},
+		},
+	})
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want time.Time
+		ok   bool
+	}{
+		{"segunda à noite", time.Date(2024, 6, 17, 20, 0, 0, 0, sp), time.Date(2024, 6, 18, 2, 0, 0, 0, sp), true},
+		{"último minuto de segunda", time.Date(2024, 6, 17, 23, 59, 0, 0, sp), time.Date(2024, 6, 18, 2, 0, 0, 0, sp), true},
+		{"meia-noite de terça", time.Date(2024, 6, 18, 0, 0, 0, 0, sp), time.Date(2024, 6, 18, 2, 0, 0, 0, sp), true},
+		{"intervalo seguinte não é contíguo", time.Date(2024, 6, 18, 9, 0, 0, 0, sp), time.Date(2024, 6, 18, 12, 0, 0, 0, sp), true},
+		{"fechado", time.Date(2024, 6, 18, 3, 0, 0, 0, sp), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := c.NextClosing(tt.at)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("%s: NextClosing(%v) = %v, %v; esperado %v, %v", tt.name, tt.at, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	from := time.Date(2024, 6, 17, 18, 0, 0, 0, sp)
+	to := time.Date(2024, 6, 18, 12, 0, 0, 0, sp)
+	if got := c.Between(from, to); got != 12*time.Hour {
+		t.Errorf("Between(%v, %v) = %v, esperado 12h", from, to, got)
+	}
+}
+
+func TestBetweenAndAdd(t *testing.T) {
+	sp := mustLocation(t, "America/Sao_Paulo")
+	c := mustCalendar(t, &entity.BusinessCalendar{
+		TimeZone:         "America/Sao_Paulo",
+		Days:             weekdays("09:00", "18:00"),
+		NationalHolidays: true,
+	})
+
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+		d    time.Duration
+	}{
+		{"mesmo dia", time.Date(2024, 6, 17, 10, 0, 0, 0, sp), time.Date(2024, 6, 17, 12, 30, 0, 0, sp), 150 * time.Minute},
+		{"início fora do horário", time.Date(2024, 6, 17, 6, 0, 0, 0, sp), time.Date(2024, 6, 17, 10, 0, 0, 0, sp), time.Hour},
+		{"atravessa o fim de semana", time.Date(2024, 6, 14, 17, 0, 0, 0, sp), time.Date(2024, 6, 17, 10, 0, 0, 0, sp), 2 * time.Hour},
+		{"início no sábado", time.Date(2024, 6, 15, 12, 0, 0, 0, sp), time.Date(2024, 6, 17, 13, 0, 0, 0, sp), 4 * time.Hour},
+		{"atravessa o fim de semana e o feriado", time.Date(2024, 11, 14, 17, 0, 0, 0, sp), time.Date(2024, 11, 18, 10, 0, 0, 0, sp), 2 * time.Hour},
+		{"semana inteira", time.Date(2024, 6, 17, 9, 0, 0, 0, sp), time.Date(2024, 6, 21, 18, 0, 0, 0, sp), 45 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Between(tt.from, tt.to); got != tt.d {
+				t.Errorf("Between(%v, %v) = %v, esperado %v", tt.from, tt.to, got, tt.d)
+			}
+			got, ok := c.Add(tt.from, tt.d)
+			if !ok || !got.Equal(tt.to) {
+				t.Errorf("Add(%v, %v) = %v, %v; esperado %v", tt.from, tt.d, got, ok, tt.to)
+			}
+		})
+	}
+
+	if got, ok := mustCalendar(t, &entity.BusinessCalendar{TimeZone: "America/Sao_Paulo"}).Add(time.Now(), time.Minute); ok {
+		t.Errorf("Add sem expediente = %v, esperado false", got)
+	}
+}
+
+// Nas trocas de horário de verão o dia local tem 23 ou 25 horas; o tempo de
+// atendimento conta as horas reais
+func TestDaylightSaving(t *testing.T) {
+	ny := mustLocation(t, "America/New_York")
+	c := mustCalendar(t, &entity.BusinessCalendar{
+		TimeZone: "America/New_York",
+		Days: []entity.BusinessDay{
+			{Weekday: 0, Open: "00:00", Close: "06:00"},
+			{Weekday: 1, Open: "09:00", Close: "17:00"},
+		},
+	})
+
+	tests := []struct {
+		name   string
+		sunday time.Time
+		open   time.Duration
+		// closes é o fechamento de domingo em UTC
+		closes time.Time
+	}{
+		{"início do horário de verão", time.Date(2024, 3, 10, 0, 0, 0, 0, ny), 5 * time.Hour, time.Date(2024, 3, 10, 10, 0, 0, 0, time.UTC)},
+		{"fim do horário de verão", time.Date(2024, 11, 3, 0, 0, 0, 0, ny), 7 * time.Hour, time.Date(2024, 11, 3, 11, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sunday := tt.sunday
+			monday := sunday.AddDate(0, 0, 1)
+
+			if got := c.Between(sunday, monday); got != tt.open {
+				t.Errorf("Between(%v, %v) = %v, esperado %v", sunday, monday, got, tt.open)
+			}
+			if got, ok := c.NextClosing(sunday); !ok || !got.Equal(tt.closes) {
+				t.Errorf("NextClosing(%v) = %v, %v; esperado %v", sunday, got, ok, tt.closes)
+			}
+
+			// O prazo que sobra no domingo continua na abertura de segunda
+			want := time.Date(monday.Year(), monday.Month(), monday.Day(), 10, 0, 0, 0, ny)
+			if got, ok := c.Add(sunday, tt.open+time.Hour); !ok || !got.Equal(want) {
+				t.Errorf("Add(%v, %v) = %v, %v; esperado %v", sunday, tt.open+time.Hour, got, ok, want)
+			}
+		})
+	}
+
+	// 02:30 não existe no início do horário de verão
+	at := time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC)
+	if !c.IsOpen(at) {
+		t.Errorf("IsOpen(%v) = false, esperado true", at)
+	}
+	if got := at.In(ny).Hour(); got != 3 {
+		t.Errorf("hora local de %v = %d, esperado 3", at, got)
+	}
+}
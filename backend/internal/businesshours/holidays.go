@@ -0,0 +1,69 @@
+package businesshours
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// fixedHolidays lista os feriados nacionais do Brasil com data fixa, no
+// formato MM-DD
+var fixedHolidays = []entity.Holiday{
+	{Date: "01-01", Name: "Confraternização Universal"},
+	{Date: "04-21", Name: "Tiradentes"},
+	{Date: "05-01", Name: "Dia do Trabalho"},
+	{Date: "09-07", Name: "Independência do Brasil"},
+	{Date: "10-12", Name: "Nossa Senhora Aparecida"},
+	{Date: "11-02", Name: "Finados"},
+	{Date: "11-15", Name: "Proclamação da República"},
+	{Date: "12-25", Name: "Natal"},
+}
+
+// blackConsciousnessSince é o primeiro ano em que o Dia Nacional de Zumbi e
+// da Consciência Negra é feriado nacional (Lei 14.759/2023)
+const blackConsciousnessSince = 2024
+
+// NationalHolidays retorna os feriados nacionais do Brasil no ano, em ordem
+// de data, incluindo os pontos facultativos de Carnaval e Corpus Christi
+// marcados como Optional
+func NationalHolidays(year int) []entity.Holiday {
+	holidays := make([]entity.Holiday, 0, len(fixedHolidays)+5)
+	for _, h := range fixedHolidays {
+		h.Date = fmt.Sprintf("%04d-%s", year, h.Date)
+		holidays = append(holidays, h)
+	}
+	if year >= blackConsciousnessSince {
+		holidays = append(holidays, entity.Holiday{Date: fmt.Sprintf("%04d-11-20", year), Name: "Dia Nacional de Zumbi e da Consciência Negra"})
+	}
+
+	easter := Easter(year)
+	movable := func(days int, name string, optional bool) {
+		holidays = append(holidays, entity.Holiday{Date: easter.AddDate(0, 0, days).Format("2006-01-02"), Name: name, Optional: optional})
+	}
+	movable(-48, "Carnaval", true)
+	movable(-47, "Carnaval", true)
+	movable(-2, "Sexta-feira Santa", false)
+	movable(60, "Corpus Christi", true)
+
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date < holidays[j].Date })
+	return holidays
+}
+
+// Easter calcula o domingo de Páscoa do ano pelo algoritmo de Meeus, Jones
+// e Butcher para o calendário gregoriano
+func Easter(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
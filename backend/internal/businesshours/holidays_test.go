@@ -0,0 +1,97 @@
+package businesshours
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEaster(t *testing.T) {
+	tests := []struct {
+		year int
+		want string
+	}{
+		{2000, "2000-04-23"},
+		{2019, "2019-04-21"},
+		{2023, "2023-04-09"},
+		{2024, "2024-03-31"},
+		{2025, "2025-04-20"},
+		{2038, "2038-04-25"},
+	}
+
+	for _, tt := range tests {
+		if got := Easter(tt.year).Format("2006-01-02"); got != tt.want {
+			t.Errorf("Easter(%d) = %s, esperado %s", tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestNationalHolidays(t *testing.T) {
+	type holiday struct {
+		date     string
+		name     string
+		optional bool
+	}
+
+	tests := []struct {
+		year int
+		want []holiday
+	}{
+		{2023, []holiday{
+			{"2023-01-01", "Confraternização Universal", false},
+			{"2023-02-20", "Carnaval", true},
+			{"2023-02-21", "Carnaval", true},
+			{"2023-04-07", "Sexta-feira Santa", false},
+			{"2023-04-21", "Tiradentes", false},
+			{"2023-05-01", "Dia do Trabalho", false},
+			{"2023-06-08", "Corpus Christi", true},
+			{"2023-09-07", "Independência do Brasil", false},
+			{"2023-10-12", "Nossa Senhora Aparecida", false},
+			{"2023-11-02", "Finados", false},
+			{"2023-11-15", "Proclamação da República", false},
+			{"2023-12-25", "Natal", false},
+		}},
+		{2024, []holiday{
+			{"2024-01-01", "Confraternização Universal", false},
+			{"2024-02-12", "Carnaval", true},
+			{"2024-02-13", "Carnaval", true},
+			{"2024-03-29", "Sexta-feira Santa", false},
+			{"2024-04-21", "Tiradentes", false},
+			{"2024-05-01", "Dia do Trabalho", false},
+			{"2024-05-30", "Corpus Christi", true},
+			{"2024-09-07", "Independência do Brasil", false},
+			{"2024-10-12", "Nossa Senhora Aparecida", false},
+			{"2024-11-02", "Finados", false},
+			{"2024-11-15", "Proclamação da República", false},
+			{"2024-11-20", "Dia Nacional de Zumbi e da Consciência Negra", false},
+			{"2024-12-25", "Natal", false},
+		}},
+	}
+
+	for _, tt := range tests {
+		got := NationalHolidays(tt.year)
+		if len(got) != len(tt.want) {
+			t.Errorf("NationalHolidays(%d) retornou %d feriados, esperado %d: %+v", tt.year, len(got), len(tt.want), got)
+			continue
+		}
+		for i, w := range tt.want {
+			if got[i].Date != w.date || got[i].Name != w.name || got[i].Optional != w.optional || got[i].Recurring {
+				t.Errorf("NationalHolidays(%d)[%d] = %+v, esperado %+v", tt.year, i, got[i], w)
+			}
+		}
+	}
+}
+
+// Os feriados móveis de um ano não vazam para o calendário de outro
+func TestNationalHolidaysMovableDates(t *testing.T) {
+	for year := 2000; year <= 2040; year++ {
+		easter := Easter(year)
+		if easter.Weekday() != time.Sunday {
+			t.Errorf("Easter(%d) = %s, não é domingo", year, easter.Format("2006-01-02"))
+		}
+		for _, h := range NationalHolidays(year) {
+			if h.Date[:4] != easter.Format("2006") {
+				t.Errorf("NationalHolidays(%d) contém %s", year, h.Date)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package businesshours
+
+import (
+	"time"
+)
+
+// Status informa se o atendimento está aberto no instante e quando abre
+// ou fecha, com os horários no fuso do calendário
+type Status struct {
+	At       time.Time `json:"at"`
+	TimeZone string    `json:"time_zone"`
+	Open     bool      `json:"open"`
+	// Holiday é o nome do feriado do dia, se houver
+	Holiday string `json:"holiday,omitempty"`
+	// NextOpening é nulo com o atendimento aberto ou se o calendário não
+	// abrir nos próximos anos
+	NextOpening *time.Time `json:"next_opening"`
+	// ClosesAt é nulo com o atendimento fechado ou sem interrupção prevista
+	ClosesAt *time.Time `json:"closes_at"`
+}
+
+// Status calcula a situação do atendimento no instante
+func (c *Calendar) Status(at time.Time) Status {
+	s := Status{At: at.In(c.loc), TimeZone: c.loc.String(), Open: c.IsOpen(at)}
+	s.Holiday, _ = c.Holiday(at)
+	if s.Open {
+		if t, ok := c.NextClosing(at); ok {
+			s.ClosesAt = &t
+		}
+	} else if t, ok := c.NextOpening(at); ok {
+		s.NextOpening = &t
+	}
+	return s
+}
+
+// Clock é um relógio de SLA que corre apenas no horário de atendimento
+type Clock struct {
+	StartedAt     time.Time `json:"started_at"`
+	At            time.Time `json:"at"`
+	TargetMinutes int       `json:"target_minutes"`
+	// DueAt é o prazo do SLA, nulo se passar do limite de busca
+	DueAt            *time.Time `json:"due_at"`
+	ElapsedMinutes   int        `json:"elapsed_minutes"`
+	RemainingMinutes int        `json:"remaining_minutes"`
+	Breached         bool       `json:"breached"`
+	// Running indica que o relógio está correndo, com o atendimento aberto
+	Running bool `json:"running"`
+}
+
+// Clock calcula o relógio de SLA iniciado em start com a meta informada,
+// na situação do instante at
+func (c *Calendar) Clock(start, at time.Time, target time.Duration) Clock {
+	clock := Clock{
+		StartedAt:     start.In(c.loc),
+		At:            at.In(c.loc),
+		TargetMinutes: int(target / time.Minute),
+		Running:       c.IsOpen(at),
+	}
+	if due, ok := c.Add(start, target); ok {
+		clock.DueAt = &due
+	}
+	elapsed := c.Between(start, at)
+	clock.ElapsedMinutes = int(elapsed / time.Minute)
+	if elapsed > target {
+		clock.Breached = true
+	} else {
+		clock.RemainingMinutes = int((target - elapsed + time.Minute - 1) / time.Minute)
+	}
+	return clock
+}
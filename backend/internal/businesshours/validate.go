@@ -0,0 +1,78 @@
+package businesshours
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// Limites dos calendários
+const (
+	MaxRanges         = 21
+	MaxHolidays       = 200
+	MaxHolidayName    = 100
+	maxTimeZoneLength = 64
+)
+
+// clockPattern aceita horários HH:MM de 00:00 a 24:00
+var clockPattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$|^24:00$`)
+
+// Validate verifica o fuso, os intervalos e os feriados do calendário
+func Validate(cal *entity.BusinessCalendar) []response.FieldError {
+	errs := ValidateHours(&entity.BusinessHours{TimeZone: cal.TimeZone, Days: cal.Days}, "")
+	add := func(field, code, message string) {
+		errs = append(errs, response.FieldError{Field: field, Code: code, Message: message})
+	}
+
+	if len(cal.Holidays) > MaxHolidays {
+		add("holidays", "max", fmt.Sprintf("Use no máximo %d feriados", MaxHolidays))
+	}
+	for i, h := range cal.Holidays {
+		field := fmt.Sprintf("holidays[%d]", i)
+		if _, err := time.Parse("2006-01-02", h.Date); err != nil {
+			add(field+".date", "invalid", "Informe a data no formato AAAA-MM-DD")
+		}
+		if strings.TrimSpace(h.Name) == "" || utf8.RuneCountInString(h.Name) > MaxHolidayName {
+			add(field+".name", "range", fmt.Sprintf("Informe o nome com no máximo %d caracteres", MaxHolidayName))
+		}
+	}
+	return errs
+}
+
+// ValidateHours verifica o fuso e os intervalos do horário semanal; os
+// campos dos erros recebem o prefixo field
+func ValidateHours(h *entity.BusinessHours, field string) []response.FieldError {
+	var errs []response.FieldError
+	add := func(name, code, message string) {
+		if field != "" {
+			name = field + "." + name
+		}
+		errs = append(errs, response.FieldError{Field: name, Code: code, Message: message})
+	}
+	if h == nil {
+		errs = append(errs, response.FieldError{Field: field, Code: "required", Message: "Informe o horário de atendimento"})
+		return errs
+	}
+
+	if _, err := time.LoadLocation(h.TimeZone); err != nil || h.TimeZone == "" || len(h.TimeZone) > maxTimeZoneLength {
+		add("time_zone", "invalid", "Informe um fuso horário IANA, como America/Sao_Paulo")
+	}
+	if len(h.Days) == 0 || len(h.Days) > MaxRanges {
+		add("days", "range", fmt.Sprintf("Informe de 1 a %d intervalos", MaxRanges))
+	}
+	for i, d := range h.Days {
+		day := fmt.Sprintf("days[%d]", i)
+		if d.Weekday < 0 || d.Weekday > 6 {
+			add(day+".weekday", "range", "Use de 0 (domingo) a 6 (sábado)")
+		}
+		if !clockPattern.MatchString(d.Open) || !clockPattern.MatchString(d.Close) || d.Open >= d.Close {
+			add(day, "invalid", "Informe open e close no formato HH:MM, com open antes de close")
+		}
+	}
+	return errs
+}
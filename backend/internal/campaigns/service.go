@@ -0,0 +1,356 @@
+// Package campaigns dispara modelos de mensagem para os leads de um
+// segmento, no ritmo permitido pelo número da organização
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/whatsapp/backend/internal/businesshours"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/messaging"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/ratelimit"
+	"github.com/whatsapp/backend/internal/whatsapp"
+)
+
+// Parâmetros do envio
+const (
+	pollInterval = 2 * time.Second
+	// batchSize limita os destinatários reservados por campanha a cada ciclo
+	batchSize = 50
+	// throughput é a vazão padrão de mensagens por segundo de um número na Cloud API
+	throughput = 80
+	// maxAttempts limita as tentativas de envio com erros temporários
+	maxAttempts = 5
+	// retryBase é o intervalo da primeira nova tentativa, dobrado a cada falha
+	retryBase     = 30 * time.Second
+	maxRetryDelay = 30 * time.Minute
+	// staleAfter encerra envios reservados por uma instância que caiu
+	staleAfter = 10 * time.Minute
+)
+
+// Repository reserva os destinatários e grava o andamento das campanhas
+type Repository interface {
+	StartDue() (*entity.Campaign, error)
+	ListRunning() ([]*entity.Campaign, error)
+	ClaimRecipients(campaignID int64, limit int) ([]*entity.CampaignRecipient, error)
+	Status(campaignID int64) (string, error)
+	MarkSent(recipientID, messageID int64) error
+	Retry(recipientID int64, at time.Time, reason string) error
+	Release(recipientID int64) error
+	Fail(recipientID int64, reason string) error
+	OptOut(recipientID int64) error
+	Suspend(campaignID int64, reason string) error
+	Finish(staleAfter time.Duration) (int, error)
+}
+
+// TemplateFinder busca o modelo da campanha
+type TemplateFinder interface {
+	GetByID(organizationID, id int64) (*entity.MessageTemplate, error)
+}
+
+// OrganizationFinder busca o nível de envio do número da organização
+type OrganizationFinder interface {
+	GetByID(id int64) (*entity.Organization, error)
+}
+
+// Sender envia o modelo ao lead e informa o número de envio da organização
+type Sender interface {
+	SendTemplateToLead(req messaging.LeadTemplateRequest) (*entity.Message, error)
+	PhoneNumberID(organizationID int64) (string, error)
+}
+
+// CalendarFinder busca o calendário de atendimento da campanha
+type CalendarFinder interface {
+	GetByID(organizationID, id int64) (*entity.BusinessCalendar, error)
+}
+
+// Limiter retira fichas dos baldes compartilhados entre as instâncias
+type Limiter interface {
+	Take(ctx context.Context, b ratelimit.Bucket, n int) (int, error)
+	Return(ctx context.Context, b ratelimit.Bucket, n int) error
+}
+
+// Service inicia as campanhas agendadas e envia as mensagens aos destinatários
+type Service struct {
+	campaigns     Repository
+	templates     TemplateFinder
+	organizations OrganizationFinder
+	sender        Sender
+	limiter       Limiter
+	calendars     CalendarFinder
+}
+
+// NewService cria uma nova instância do serviço de campanhas
+func NewService(campaigns Repository, templates TemplateFinder, organizations OrganizationFinder, sender Sender, limiter Limiter, calendars CalendarFinder) *Service {
+	return &Service{
+		campaigns:     campaigns,
+		templates:     templates,
+		organizations: organizations,
+		sender:        sender,
+		limiter:       limiter,
+		calendars:     calendars,
+	}
+}
+
+// Run processa as campanhas até que o contexto seja cancelado
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.startDue()
+		s.sendRunning(ctx)
+		if n, err := s.campaigns.Finish(staleAfter); err != nil {
+			logger.Error("Erro ao concluir campanhas", err)
+		} else if n > 0 {
+			logger.Info("Campanhas concluídas", map[string]interface{}{"count": n})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// startDue inicia as campanhas cujo horário agendado chegou
+func (s *Service) startDue() {
+	for {
+		c, err := s.campaigns.StartDue()
+		if err != nil {
+			if err != sql.ErrNoRows {
+				logger.Error("Erro ao iniciar campanha agendada", err)
+			}
+			return
+		}
+		logger.Info("Campanha iniciada", map[string]interface{}{"campaign_id": c.ID, "organization_id": c.OrganizationID})
+	}
+}
+
+func (s *Service) sendRunning(ctx context.Context) {
+	running, err := s.campaigns.ListRunning()
+	if err != nil {
+		logger.Error("Erro ao listar campanhas em andamento", err)
+		return
+	}
+	for _, c := range running {
+		if ctx.Err() != nil {
+			return
+		}
+		s.sendBatch(ctx, c)
+	}
+}
+
+// sendBatch envia um lote da campanha dentro das fichas disponíveis nos
+// baldes do número: a vazão por segundo e o nível de envio em 24 horas. Fora
+// do horário do calendário da campanha nada é enviado.
+func (s *Service) sendBatch(ctx context.Context, c *entity.Campaign) {
+	if !s.open(c) {
+		return
+	}
+
+	t, err := s.templates.GetByID(c.OrganizationID, c.TemplateID)
+	if err != nil {
+		logger.Error("Erro ao buscar modelo da campanha", err)
+		return
+	}
+	if t.Status != entity.TemplateStatusApproved {
+		s.suspend(c, fmt.Sprintf("O modelo %s não está aprovado (%s)", t.Name, t.Status))
+		return
+	}
+
+	org, err := s.organizations.GetByID(c.OrganizationID)
+	if err != nil {
+		logger.Error("Erro ao buscar organização da campanha", err)
+		return
+	}
+	phoneNumberID, err := s.sender.PhoneNumberID(c.OrganizationID)
+	if errors.Is(err, messaging.ErrNoPhoneNumber) {
+		s.suspend(c, "Organização sem número do WhatsApp vinculado")
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao buscar número de envio da campanha", err)
+		return
+	}
+
+	recipients, err := s.campaigns.ClaimRecipients(c.ID, batchSize)
+	if err != nil {
+		logger.Error("Erro ao reservar destinatários da campanha", err)
+		return
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	// As fichas são retiradas apenas para os destinatários reservados; os
+	// que ficarem sem ficha voltam para o próximo ciclo
+	buckets := rateBuckets(org, phoneNumberID)
+	n, err := s.take(ctx, buckets, len(recipients))
+	if err != nil {
+		logger.Error("Erro ao consultar limite de envio da campanha", err)
+	}
+	for _, pending := range recipients[n:] {
+		_ = s.campaigns.Release(pending.ID)
+	}
+	recipients = recipients[:n]
+
+	for i, recipient := range recipients {
+		// Pausar ou cancelar a campanha interrompe o lote no próximo envio;
+		// as fichas dos destinatários não enviados são devolvidas
+		if ctx.Err() != nil || !s.running(c.ID) {
+			for _, pending := range recipients[i:] {
+				_ = s.campaigns.Release(pending.ID)
+			}
+			s.giveBack(buckets, len(recipients)-i)
+			return
+		}
+		if !s.send(c, t, recipient) {
+			for _, pending := range recipients[i+1:] {
+				_ = s.campaigns.Release(pending.ID)
+			}
+			s.giveBack(buckets, len(recipients)-i-1)
+			return
+		}
+	}
+}
+
+// rateBuckets retorna os baldes do número: a vazão por segundo, que se
+// recompõe em instantes, e o nível de envio em 24 horas, quando limitado
+func rateBuckets(org *entity.Organization, phoneNumberID string) []ratelimit.Bucket {
+	buckets := []ratelimit.Bucket{{Key: "ratelimit:whatsapp:" + phoneNumberID + ":mps", Rate: throughput, Burst: throughput}}
+	if limit := entity.MessagingTierLimit(org.WhatsAppMessagingTier); limit > 0 {
+		buckets = append(buckets, ratelimit.PerDay("ratelimit:whatsapp:"+phoneNumberID+":tier", limit))
+	}
+	return buckets
+}
+
+// take retira até n fichas de cada balde, em ordem, pedindo a cada balde
+// apenas as fichas concedidas pelos anteriores. As fichas que um balde
+// recusa são devolvidas aos anteriores, para não reduzir a vazão.
+func (s *Service) take(ctx context.Context, buckets []ratelimit.Bucket, n int) (int, error) {
+	granted := n
+	for i, b := range buckets {
+		got, err := s.limiter.Take(ctx, b, granted)
+		if err != nil {
+			s.giveBack(buckets[:i], granted)
+			return 0, err
+		}
+		s.giveBack(buckets[:i], granted-got)
+		if got == 0 {
+			return 0, nil
+		}
+		granted = got
+	}
+	return granted, nil
+}
+
+// giveBack devolve n fichas não usadas aos baldes
+func (s *Service) giveBack(buckets []ratelimit.Bucket, n int) {
+	if n <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, b := range buckets {
+		if err := s.limiter.Return(ctx, b, n); err != nil {
+			logger.Error("Erro ao devolver fichas do limite de envio da campanha", err)
+		}
+	}
+}
+
+// running informa se a campanha continua em andamento. Erros na consulta
+// não interrompem o envio.
+func (s *Service) running(campaignID int64) bool {
+	status, err := s.campaigns.Status(campaignID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false
+	}
+	return err != nil || status == entity.CampaignStatusRunning
+}
+
+// send envia o modelo ao destinatário e grava o resultado. Retorna false
+// se o problema impede os demais envios da campanha.
+func (s *Service) send(c *entity.Campaign, t *entity.MessageTemplate, recipient *entity.CampaignRecipient) bool {
+	message, err := s.sender.SendTemplateToLead(messaging.LeadTemplateRequest{
+		OrganizationID: c.OrganizationID,
+		LeadID:         recipient.LeadID,
+		Template:       t,
+		CampaignID:     c.ID,
+	})
+	if err == nil {
+		_ = s.campaigns.MarkSent(recipient.ID, message.ID)
+		return true
+	}
+
+	var missing *messaging.MissingFieldsError
+	switch {
+	case errors.Is(err, messaging.ErrNoPhoneNumber), errors.Is(err, whatsapp.ErrNotConfigured), errors.Is(err, whatsapp.ErrInvalidAPIURL):
+		_ = s.campaigns.Release(recipient.ID)
+		s.suspend(c, "Provedor do WhatsApp não configurado")
+		return false
+	case errors.Is(err, messaging.ErrTemplateNotApproved):
+		_ = s.campaigns.Release(recipient.ID)
+		s.suspend(c, fmt.Sprintf("O modelo %s não está aprovado", t.Name))
+		return false
+	case errors.Is(err, messaging.ErrOptedOut):
+		_ = s.campaigns.OptOut(recipient.ID)
+	case errors.Is(err, messaging.ErrNotRecorded):
+		_ = s.campaigns.Fail(recipient.ID, err.Error())
+	case errors.As(err, &missing):
+		_ = s.campaigns.Fail(recipient.ID, missing.Error())
+	case errors.Is(err, messaging.ErrInvalidRecipient), errors.Is(err, sql.ErrNoRows):
+		_ = s.campaigns.Fail(recipient.ID, err.Error())
+	case whatsapp.IsTransient(err) && recipient.Attempts < maxAttempts:
+		_ = s.campaigns.Retry(recipient.ID, time.Now().UTC().Add(retryDelay(recipient.Attempts)), err.Error())
+	default:
+		_ = s.campaigns.Fail(recipient.ID, err.Error())
+	}
+	return true
+}
+
+// open informa se a campanha pode enviar agora pelo seu calendário de
+// atendimento; sem calendário o envio não tem restrição de horário
+func (s *Service) open(c *entity.Campaign) bool {
+	if c.CalendarID == nil {
+		return true
+	}
+	cal, err := s.calendars.GetByID(c.OrganizationID, *c.CalendarID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return true
+		}
+		logger.Error("Erro ao buscar calendário da campanha", err)
+		return false
+	}
+	calendar, err := businesshours.New(cal)
+	if err != nil {
+		logger.Error("Erro ao carregar fuso do calendário da campanha", err)
+		return false
+	}
+	return calendar.IsOpen(time.Now())
+}
+
+func (s *Service) suspend(c *entity.Campaign, reason string) {
+	logger.Warning("Campanha pausada automaticamente", map[string]interface{}{"campaign_id": c.ID, "reason": reason})
+	_ = s.campaigns.Suspend(c.ID, reason)
+}
+
+// retryDelay calcula o intervalo antes da próxima tentativa, dobrando a
+// cada tentativa feita
+func retryDelay(attempts int) time.Duration {
+	delay := retryBase
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,210 @@
+package campaigns
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/whatsapp/backend/internal/messaging"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/ratelimit"
+)
+
+// fakeLimiter guarda as fichas disponíveis de cada balde, sem reabastecer
+type fakeLimiter struct {
+	tokens map[string]int
+	fail   map[string]bool
+}
+
+func (l *fakeLimiter) Take(_ context.Context, b ratelimit.Bucket, n int) (int, error) {
+	if l.fail[b.Key] {
+		return 0, errors.New("redis indisponível")
+	}
+	if n > l.tokens[b.Key] {
+		n = l.tokens[b.Key]
+	}
+	l.tokens[b.Key] -= n
+	return n, nil
+}
+
+func (l *fakeLimiter) Return(_ context.Context, b ratelimit.Bucket, n int) error {
+	l.tokens[b.Key] += n
+	return nil
+}
+
+const (
+	mpsKey  = "ratelimit:whatsapp:123:mps"
+	tierKey = "ratelimit:whatsapp:123:tier"
+)
+
+func TestRateBuckets(t *testing.T) {
+	tests := []struct {
+		tier string
+		want []ratelimit.Bucket
+	}{
+		{entity.MessagingTierUnlimited, []ratelimit.Bucket{{Key: mpsKey, Rate: throughput, Burst: throughput}}},
+		{entity.MessagingTier1K, []ratelimit.Bucket{{Key: mpsKey, Rate: throughput, Burst: throughput}, ratelimit.PerDay(tierKey, 1000)}},
+		{"", []ratelimit.Bucket{{Key: mpsKey, Rate: throughput, Burst: throughput}, ratelimit.PerDay(tierKey, 250)}},
+	}
+
+	for _, tt := range tests {
+		got := rateBuckets(&entity.Organization{WhatsAppMessagingTier: tt.tier}, "123")
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("rateBuckets(%q) = %+v, esperado %+v", tt.tier, got, tt.want)
+		}
+	}
+}
+
+func TestTake(t *testing.T) {
+	tests := []struct {
+		name      string
+		mps, tier int
+		tierFails bool
+		request   int
+		want      int
+		wantErr   bool
+		// fichas que devem sobrar em cada balde depois da retirada
+		wantMps, wantTier int
+	}{
+		{"fichas suficientes", 80, 250, false, 50, 50, false, 30, 200},
+		{"vazão limita", 10, 250, false, 50, 10, false, 0, 240},
+		{"sem vazão não consulta o nível", 0, 250, false, 50, 0, false, 0, 250},
+		{"nível limita e devolve a vazão", 80, 20, false, 50, 20, false, 60, 0},
+		{"nível esgotado devolve toda a vazão", 80, 0, false, 50, 0, false, 80, 0},
+		{"erro no nível devolve a vazão", 80, 250, true, 50, 0, true, 80, 250},
+		{"nenhum destinatário", 80, 250, false, 0, 0, false, 80, 250},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := &fakeLimiter{
+				tokens: map[string]int{mpsKey: tt.mps, tierKey: tt.tier},
+				fail:   map[string]bool{tierKey: tt.tierFails},
+			}
+			s := &Service{limiter: limiter}
+			buckets := rateBuckets(&entity.Organization{WhatsAppMessagingTier: entity.MessagingTier1K}, "123")
+
+			got, err := s.take(context.Background(), buckets, tt.request)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("take(%d) = %d, %v; esperado %d, erro %v", tt.request, got, err, tt.want, tt.wantErr)
+			}
+			if limiter.tokens[mpsKey] != tt.wantMps || limiter.tokens[tierKey] != tt.wantTier {
+				t.Errorf("fichas restantes: vazão %d, nível %d; esperado %d e %d",
+					limiter.tokens[mpsKey], limiter.tokens[tierKey], tt.wantMps, tt.wantTier)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{7, 30 * time.Minute},
+		{20, 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %s, esperado %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// fakeCampaigns entrega os destinatários e muda o status da campanha
+// depois de pauseAfter envios
+type fakeCampaigns struct {
+	Repository
+	recipients []*entity.CampaignRecipient
+	pauseAfter int
+	sent       []int64
+	released   []int64
+}
+
+func (f *fakeCampaigns) ClaimRecipients(int64, int) ([]*entity.CampaignRecipient, error) {
+	return f.recipients, nil
+}
+
+func (f *fakeCampaigns) Status(int64) (string, error) {
+	if len(f.sent) >= f.pauseAfter {
+		return entity.CampaignStatusPaused, nil
+	}
+	return entity.CampaignStatusRunning, nil
+}
+
+func (f *fakeCampaigns) MarkSent(recipientID, _ int64) error {
+	f.sent = append(f.sent, recipientID)
+	return nil
+}
+
+func (f *fakeCampaigns) Release(recipientID int64) error {
+	f.released = append(f.released, recipientID)
+	return nil
+}
+
+type fakeTemplates struct{}
+
+func (fakeTemplates) GetByID(int64, int64) (*entity.MessageTemplate, error) {
+	return &entity.MessageTemplate{Name: "boas_vindas", Status: entity.TemplateStatusApproved}, nil
+}
+
+type fakeOrganizations struct{}
+
+func (fakeOrganizations) GetByID(int64) (*entity.Organization, error) {
+	return &entity.Organization{WhatsAppMessagingTier: entity.MessagingTier1K}, nil
+}
+
+type fakeSender struct{}
+
+func (fakeSender) SendTemplateToLead(req messaging.LeadTemplateRequest) (*entity.Message, error) {
+	return &entity.Message{ID: req.LeadID}, nil
+}
+
+func (fakeSender) PhoneNumberID(int64) (string, error) {
+	return "123", nil
+}
+
+func TestSendBatchStopsWhenCampaignLeavesRunning(t *testing.T) {
+	tests := []struct {
+		name         string
+		pauseAfter   int
+		wantSent     []int64
+		wantReleased []int64
+	}{
+		{"em andamento", 10, []int64{1, 2, 3}, nil},
+		{"pausada antes do lote", 0, nil, []int64{1, 2, 3}},
+		{"pausada durante o lote", 1, []int64{1}, []int64{2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaigns := &fakeCampaigns{pauseAfter: tt.pauseAfter}
+			for id := int64(1); id <= 3; id++ {
+				campaigns.recipients = append(campaigns.recipients, &entity.CampaignRecipient{ID: id, LeadID: id})
+			}
+			limiter := &fakeLimiter{tokens: map[string]int{mpsKey: 80, tierKey: 1000}}
+			s := NewService(campaigns, fakeTemplates{}, fakeOrganizations{}, fakeSender{}, limiter, nil)
+
+			s.sendBatch(context.Background(), &entity.Campaign{ID: 7, OrganizationID: 1})
+
+			if !reflect.DeepEqual(campaigns.sent, tt.wantSent) {
+				t.Errorf("enviados %v, esperado %v", campaigns.sent, tt.wantSent)
+			}
+			if !reflect.DeepEqual(campaigns.released, tt.wantReleased) {
+				t.Errorf("liberados %v, esperado %v", campaigns.released, tt.wantReleased)
+			}
+			// Só os envios feitos consomem fichas
+			if used := 1000 - limiter.tokens[tierKey]; used != len(tt.wantSent) {
+				t.Errorf("fichas do nível usadas %d, esperado %d", used, len(tt.wantSent))
+			}
+		})
+	}
+}
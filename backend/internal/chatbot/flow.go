@@ -0,0 +1,247 @@
+// Package chatbot executa os fluxos de qualificação de leads: máquinas de
+// estado que enviam mensagens e perguntas, gravam as respostas nos campos do
+// lead e entregam a conversa a um atendente
+package chatbot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+	"github.com/whatsapp/backend/internal/whatsapp"
+	"github.com/whatsapp/backend/pkg/text"
+)
+
+// Limites dos fluxos
+const (
+	MaxNodes       = 100
+	MaxBranches    = 10
+	MaxTextLength  = 4096
+	MaxValueLength = 100
+	// MaxTimeout mantém o prazo dentro da janela de atendimento de 24 horas,
+	// para que a mensagem de contingência ainda possa ser enviada
+	MaxTimeout = 23 * 60
+)
+
+// nodeIDPattern aceita os IDs de nós e opções
+var nodeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,50}$`)
+
+// writableFields lista os campos fixos do lead que recebem respostas, com o
+// tamanho máximo de cada um
+var writableFields = map[string]int{
+	"name": 100, "email": 100, "source": 50, "stage": 50,
+}
+
+// readableFields lista os campos fixos do lead aceitos nas ramificações
+var readableFields = map[string]bool{
+	"name": true, "phone": true, "email": true, "source": true, "status": true, "stage": true,
+}
+
+// Validate verifica a estrutura do fluxo e as referências entre os nós. O
+// schema valida os campos personalizados; a existência do atendente do
+// handoff é verificada pelo chamador.
+func Validate(flow *entity.ChatbotFlow, schema customfields.Schema) []response.FieldError {
+	var errs []response.FieldError
+	add := func(field, code, message string) {
+		errs = append(errs, response.FieldError{Field: field, Code: code, Message: message})
+	}
+
+	if flow.TimeoutMinutes < 1 || flow.TimeoutMinutes > MaxTimeout {
+		add("timeout_minutes", "range", fmt.Sprintf("Use de 1 a %d minutos", MaxTimeout))
+	}
+	if len(flow.Nodes) == 0 || len(flow.Nodes) > MaxNodes {
+		add("nodes", "range", fmt.Sprintf("Informe de 1 a %d nós", MaxNodes))
+	}
+
+	ids := make(map[string]bool, len(flow.Nodes))
+	for i, n := range flow.Nodes {
+		if !nodeIDPattern.MatchString(n.ID) {
+			add(fmt.Sprintf("nodes[%d].id", i), "invalid", "Use até 50 letras sem acento, números, _ ou -")
+		} else if ids[n.ID] {
+			add(fmt.Sprintf("nodes[%d].id", i), "duplicate", "Cada nó precisa de um ID único")
+		}
+		ids[n.ID] = true
+	}
+
+	if flow.StartNode == "" {
+		add("start_node", "required", "Informe o nó inicial")
+	} else if !ids[flow.StartNode] {
+		add("start_node", "not_found", "Nó não encontrado no fluxo")
+	}
+	if flow.FallbackNode != "" && !ids[flow.FallbackNode] {
+		add("fallback_node", "not_found", "Nó não encontrado no fluxo")
+	}
+
+	next := func(field, id string) {
+		if id != "" && !ids[id] {
+			add(field, "not_found", "Nó não encontrado no fluxo")
+		}
+	}
+
+	for i, n := range flow.Nodes {
+		field := fmt.Sprintf("nodes[%d]", i)
+		next(field+".next", n.Next)
+		if n.RetryText != "" && utf8.RuneCountInString(n.RetryText) > MaxTextLength {
+			add(field+".retry_text", "max", fmt.Sprintf("Use no máximo %d caracteres", MaxTextLength))
+		}
+
+		switch n.Type {
+		case entity.FlowNodeSend:
+			validateText(add, field+".text", n.Text, MaxTextLength, true)
+		case entity.FlowNodeButtons, entity.FlowNodeList:
+			validateText(add, field+".text", n.Text, whatsapp.MaxInteractiveBody, true)
+			maxOptions, maxTitle := whatsapp.MaxReplyButtons, whatsapp.MaxButtonTitle
+			if n.Type == entity.FlowNodeList {
+				maxOptions, maxTitle = whatsapp.MaxListRows, whatsapp.MaxListRowTitle
+				validateText(add, field+".list_button", n.ListButton, whatsapp.MaxButtonTitle, true)
+			}
+			if len(n.Options) == 0 || len(n.Options) > maxOptions {
+				add(field+".options", "range", fmt.Sprintf("Informe de 1 a %d opções", maxOptions))
+			}
+			optionIDs := make(map[string]bool, len(n.Options))
+			titles := make(map[string]bool, len(n.Options))
+			for j, o := range n.Options {
+				of := fmt.Sprintf("%s.options[%d]", field, j)
+				if !nodeIDPattern.MatchString(o.ID) || optionIDs[o.ID] {
+					add(of+".id", "invalid", "Informe um ID único no nó, com até 50 letras sem acento, números, _ ou -")
+				}
+				optionIDs[o.ID] = true
+				validateText(add, of+".title", o.Title, maxTitle, true)
+				if key := text.Normalize(o.Title); key != "" && titles[key] {
+					add(of+".title", "duplicate", "Os títulos das opções precisam ser diferentes")
+				} else {
+					titles[key] = true
+				}
+				if n.Type == entity.FlowNodeList {
+					validateText(add, of+".description", o.Description, whatsapp.MaxListRowDescription, false)
+				}
+				validateText(add, of+".value", o.Value, MaxValueLength, false)
+				next(of+".next", o.Next)
+			}
+			if n.Field != "" && !isWritableField(n.Field, schema) {
+				add(field+".field", "invalid", "Use name, email, source, stage ou custom.<chave> de um campo personalizado")
+			}
+		case entity.FlowNodeCapture:
+			validateText(add, field+".text", n.Text, MaxTextLength, true)
+			if !isWritableField(n.Field, schema) {
+				add(field+".field", "invalid", "Use name, email, source, stage ou custom.<chave> de um campo personalizado")
+			}
+			if n.Format != "" && !contains(entity.CaptureFormats, n.Format) {
+				add(field+".format", "oneof", "Use um dos valores: "+strings.Join(entity.CaptureFormats, ", "))
+			}
+		case entity.FlowNodeBranch:
+			if len(n.Branches) == 0 || len(n.Branches) > MaxBranches {
+				add(field+".branches", "range", fmt.Sprintf("Informe de 1 a %d ramificações", MaxBranches))
+			}
+			for j, b := range n.Branches {
+				bf := fmt.Sprintf("%s.branches[%d]", field, j)
+				if !isReadableField(b.Field, schema, ids) {
+					add(bf+".field", "invalid", "Use um campo do lead ou answers.<id do nó>")
+				}
+				switch b.Operator {
+				case entity.BranchEquals, entity.BranchContains:
+					validateText(add, bf+".value", b.Value, MaxValueLength, true)
+				case entity.BranchEmpty:
+				default:
+					add(bf+".operator", "oneof", "Use um dos valores: "+strings.Join(entity.BranchOperators, ", "))
+				}
+				if b.Next == "" {
+					add(bf+".next", "required", "Informe o próximo nó")
+				}
+				next(bf+".next", b.Next)
+			}
+		case entity.FlowNodeHandoff:
+			validateText(add, field+".text", n.Text, MaxTextLength, false)
+			if n.UserID < 0 {
+				add(field+".user_id", "invalid", "Informe o ID do atendente ou omita para distribuir automaticamente")
+			}
+		default:
+			add(field+".type", "oneof", "Use um dos valores: "+strings.Join(entity.FlowNodeTypes, ", "))
+		}
+	}
+	return errs
+}
+
+func validateText(add func(field, code, message string), field, text string, max int, required bool) {
+	switch {
+	case required && strings.TrimSpace(text) == "":
+		add(field, "required", "Campo obrigatório")
+	case utf8.RuneCountInString(text) > max:
+		add(field, "max", fmt.Sprintf("Use no máximo %d caracteres", max))
+	}
+}
+
+// isWritableField verifica se o campo do lead pode receber respostas
+func isWritableField(field string, schema customfields.Schema) bool {
+	if key, ok := strings.CutPrefix(field, "custom."); ok {
+		return schema[key] != nil
+	}
+	_, ok := writableFields[field]
+	return ok
+}
+
+// isReadableField verifica se a ramificação pode consultar o campo
+func isReadableField(field string, schema customfields.Schema, nodes map[string]bool) bool {
+	if id, ok := strings.CutPrefix(field, entity.FlowAnswerPrefix); ok {
+		return nodes[id]
+	}
+	if key, ok := strings.CutPrefix(field, "custom."); ok {
+		return schema[key] != nil
+	}
+	return readableFields[field]
+}
+
+// matchOption encontra a opção escolhida pelo ID devolvido pelo WhatsApp
+// ou, quando o lead digita a resposta, pelo título, pelo valor ou pelo
+// número da opção
+func matchOption(node *entity.FlowNode, replyID, typed string) (entity.FlowOption, bool) {
+	if replyID != "" {
+		for _, o := range node.Options {
+			if o.ID == replyID {
+				return o, true
+			}
+		}
+	}
+	answer := text.Normalize(typed)
+	if answer == "" {
+		return entity.FlowOption{}, false
+	}
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(node.Options) {
+		return node.Options[n-1], true
+	}
+	for _, o := range node.Options {
+		if answer == text.Normalize(o.Title) || (o.Value != "" && answer == text.Normalize(o.Value)) {
+			return o, true
+		}
+	}
+	return entity.FlowOption{}, false
+}
+
+// matchBranch compara o valor do campo com a ramificação
+func matchBranch(b entity.FlowBranch, value string) bool {
+	value = text.Normalize(value)
+	switch b.Operator {
+	case entity.BranchEquals:
+		return value == text.Normalize(b.Value)
+	case entity.BranchContains:
+		expected := text.Normalize(b.Value)
+		return expected != "" && strings.Contains(" "+value+" ", " "+expected+" ")
+	case entity.BranchEmpty:
+		return value == ""
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+package chatbot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+var schema = customfields.Schema{"orcamento": &entity.CustomFieldDefinition{Key: "orcamento"}}
+
+// codes resume os erros como "campo:código" para comparar nas tabelas
+func codes(errs []response.FieldError) string {
+	parts := make([]string, len(errs))
+	for i, e := range errs {
+		parts[i] = e.Field + ":" + e.Code
+	}
+	return strings.Join(parts, " ")
+}
+
+// qualification monta um fluxo válido com todos os tipos de nó
+func qualification() *entity.ChatbotFlow {
+	return &entity.ChatbotFlow{
+		StartNode:      "boas_vindas",
+		TimeoutMinutes: 60,
+		Nodes: []entity.FlowNode{
+			{ID: "boas_vindas", Type: entity.FlowNodeSend, Text: "Olá!", Next: "interesse"},
+			{ID: "interesse", Type: entity.FlowNodeButtons, Text: "Do que você precisa?", Field: "stage", Options: []entity.FlowOption{
+				{ID: "compra", Title: "Comprar", Value: "compra", Next: "orcamento"},
+				{ID: "suporte", Title: "Suporte", Next: "atendente"},
+			}},
+			{ID: "orcamento", Type: entity.FlowNodeCapture, Text: "Qual o seu orçamento?", Field: "custom.orcamento", Format: entity.CaptureNumber, Next: "faixa"},
+			{ID: "faixa", Type: entity.FlowNodeBranch, Next: "atendente", Branches: []entity.FlowBranch{
+				{Field: "answers.orcamento", Operator: entity.BranchEmpty, Next: "orcamento"},
+			}},
+			{ID: "atendente", Type: entity.FlowNodeHandoff, Text: "Vou chamar um atendente."},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(f *entity.ChatbotFlow)
+		want string
+	}{
+		{"válido", func(f *entity.ChatbotFlow) {}, ""},
+		{"prazo zero", func(f *entity.ChatbotFlow) { f.TimeoutMinutes = 0 }, "timeout_minutes:range"},
+		{"prazo fora da janela", func(f *entity.ChatbotFlow) { f.TimeoutMinutes = MaxTimeout + 1 }, "timeout_minutes:range"},
+		{"sem nós", func(f *entity.ChatbotFlow) { f.Nodes, f.StartNode = nil, "" }, "nodes:range start_node:required"},
+		{"ID com acento", func(f *entity.ChatbotFlow) { f.Nodes[4].ID = "atendênte" }, "nodes[4].id:invalid nodes[1].options[1].next:not_found nodes[3].next:not_found"},
+		{"ID repetido", func(f *entity.ChatbotFlow) { f.Nodes[4].ID = "faixa" }, "nodes[4].id:duplicate nodes[1].options[1].next:not_found nodes[3].next:not_found"},
+		{"nó inicial inexistente", func(f *entity.ChatbotFlow) { f.StartNode = "inicio" }, "start_node:not_found"},
+		{"contingência inexistente", func(f *entity.ChatbotFlow) { f.FallbackNode = "erro" }, "fallback_node:not_found"},
+		{"contingência existente", func(f *entity.ChatbotFlow) { f.FallbackNode = "atendente" }, ""},
+		{"próximo nó inexistente", func(f *entity.ChatbotFlow) { f.Nodes[0].Next = "fim" }, "nodes[0].next:not_found"},
+		{"mensagem vazia", func(f *entity.ChatbotFlow) { f.Nodes[0].Text = " " }, "nodes[0].text:required"},
+		{"mensagem longa", func(f *entity.ChatbotFlow) { f.Nodes[0].Text = strings.Repeat("a", MaxTextLength+1) }, "nodes[0].text:max"},
+		{"texto de nova tentativa longo", func(f *entity.ChatbotFlow) {
+			f.Nodes[2].RetryText = strings.Repeat("a", MaxTextLength+1)
+		}, "nodes[2].retry_text:max"},
+		{"tipo desconhecido", func(f *entity.ChatbotFlow) { f.Nodes[0].Type = "delay" }, "nodes[0].type:oneof"},
+		{"botões demais", func(f *entity.ChatbotFlow) {
+			f.Nodes[1].Options = append(f.Nodes[1].Options, entity.FlowOption{ID: "c", Title: "C"}, entity.FlowOption{ID: "d", Title: "D"})
+		}, "nodes[1].options:range"},
+		{"botões sem opções", func(f *entity.ChatbotFlow) { f.Nodes[1].Options = nil }, "nodes[1].options:range"},
+		{"título de botão longo", func(f *entity.ChatbotFlow) {
+			f.Nodes[1].Options[0].Title = strings.Repeat("a", 21)
+		}, "nodes[1].options[0].title:max"},
+		{"títulos iguais sem acento e caixa", func(f *entity.ChatbotFlow) {
+			f.Nodes[1].Options[1].Title = "COMPRAR!"
+		}, "nodes[1].options[1].title:duplicate"},
+		{"ID de opção repetido", func(f *entity.ChatbotFlow) { f.Nodes[1].Options[1].ID = "compra" }, "nodes[1].options[1].id:invalid"},
+		{"opção com próximo inexistente", func(f *entity.ChatbotFlow) {
+			f.Nodes[1].Options[0].Next = "fim"
+		}, "nodes[1].options[0].next:not_found"},
+		{"botões gravando campo desconhecido", func(f *entity.ChatbotFlow) { f.Nodes[1].Field = "cpf" }, "nodes[1].field:invalid"},
+		{"lista válida", func(f *entity.ChatbotFlow) {
+			f.Nodes[1].Type, f.Nodes[1].ListButton = entity.FlowNodeList, "Ver opções"
+			f.Nodes[1].Options[0].Title = strings.Repeat("a", 24)
+		}, ""},
+		{"lista sem botão", func(f *entity.ChatbotFlow) { f.Nodes[1].Type = entity.FlowNodeList }, "nodes[1].list_button:required"},
+		{"lista com descrição longa", func(f *entity.ChatbotFlow) {
+			f.Nodes[1].Type, f.Nodes[1].ListButton = entity.FlowNodeList, "Ver opções"
+			f.Nodes[1].Options[0].Description = strings.Repeat("a", 73)
+		}, "nodes[1].options[0].description:max"},
+		{"captura sem campo", func(f *entity.ChatbotFlow) { f.Nodes[2].Field = "" }, "nodes[2].field:invalid"},
+		{"captura em campo personalizado não definido", func(f *entity.ChatbotFlow) {
+			f.Nodes[2].Field = "custom.renda"
+		}, "nodes[2].field:invalid"},
+		{"captura no telefone", func(f *entity.ChatbotFlow) { f.Nodes[2].Field = "phone" }, "nodes[2].field:invalid"},
+		{"captura com formato desconhecido", func(f *entity.ChatbotFlow) { f.Nodes[2].Format = "cpf" }, "nodes[2].format:oneof"},
+		{"ramificação sem condições", func(f *entity.ChatbotFlow) { f.Nodes[3].Branches = nil }, "nodes[3].branches:range"},
+		{"ramificação em resposta de nó inexistente", func(f *entity.ChatbotFlow) {
+			f.Nodes[3].Branches[0].Field = "answers.renda"
+		}, "nodes[3].branches[0].field:invalid"},
+		{"ramificação no telefone", func(f *entity.ChatbotFlow) { f.Nodes[3].Branches[0].Field = "phone" }, ""},
+		{"ramificação sem valor", func(f *entity.ChatbotFlow) {
+			f.Nodes[3].Branches[0].Operator = entity.BranchEquals
+		}, "nodes[3].branches[0].value:required"},
+		{"operador desconhecido", func(f *entity.ChatbotFlow) {
+			f.Nodes[3].Branches[0].Operator = "greater"
+		}, "nodes[3].branches[0].operator:oneof"},
+		{"ramificação sem próximo", func(f *entity.ChatbotFlow) {
+			f.Nodes[3].Branches[0].Next = ""
+		}, "nodes[3].branches[0].next:required"},
+		{"atendente negativo", func(f *entity.ChatbotFlow) { f.Nodes[4].UserID = -1 }, "nodes[4].user_id:invalid"},
+		{"handoff sem mensagem", func(f *entity.ChatbotFlow) { f.Nodes[4].Text = "" }, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := qualification()
+			tt.edit(flow)
+			if got := codes(Validate(flow, schema)); got != tt.want {
+				t.Errorf("Validate = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchOption(t *testing.T) {
+	node := &entity.FlowNode{Options: []entity.FlowOption{
+		{ID: "compra", Title: "Quero comprar", Value: "venda"},
+		{ID: "suporte", Title: "Suporte técnico"},
+		{ID: "3", Title: "Outro assunto"},
+	}}
+
+	tests := []struct {
+		replyID string
+		typed   string
+		want    string
+		ok      bool
+	}{
+		{"suporte", "", "suporte", true},
+		{"suporte", "Quero comprar", "suporte", true},
+		{"inexistente", "Quero comprar", "compra", true},
+		{"", "quero COMPRAR!", "compra", true},
+		{"", "suporte tecnico", "suporte", true},
+		{"", "Venda", "compra", true},
+		{"", "2", "suporte", true},
+		{"", " 3. ", "3", true},
+		{"", "0", "", false},
+		{"", "4", "", false},
+		{"", "suporte", "", false},
+		{"", "??", "", false},
+		{"", "", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := matchOption(node, tt.replyID, tt.typed)
+		if ok != tt.ok || got.ID != tt.want {
+			t.Errorf("matchOption(%q, %q) = %q, %v; esperado %q, %v", tt.replyID, tt.typed, got.ID, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMatchBranch(t *testing.T) {
+	tests := []struct {
+		operator string
+		expected string
+		value    string
+		want     bool
+	}{
+		{entity.BranchEquals, "São Paulo", "sao paulo", true},
+		{entity.BranchEquals, "São Paulo", "São Paulo - SP", false},
+		{entity.BranchEquals, "", "", true},
+		{entity.BranchContains, "paulo", "São Paulo - SP", true},
+		{entity.BranchContains, "sao paulo", "São Paulo - SP", true},
+		{entity.BranchContains, "paulo", "Paulistano", false},
+		{entity.BranchContains, "", "qualquer", false},
+		{entity.BranchEmpty, "", "", true},
+		{entity.BranchEmpty, "", " - ", true},
+		{entity.BranchEmpty, "", "a", false},
+		{"greater", "1", "2", false},
+	}
+
+	for _, tt := range tests {
+		b := entity.FlowBranch{Operator: tt.operator, Value: tt.expected}
+		if got := matchBranch(b, tt.value); got != tt.want {
+			t.Errorf("matchBranch(%s %q, %q) = %v, esperado %v", tt.operator, tt.expected, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseAnswer(t *testing.T) {
+	tests := []struct {
+		format string
+		text   string
+		want   string
+		ok     bool
+	}{
+		{entity.CaptureText, "  Ana Souza ", "Ana Souza", true},
+		{entity.CaptureText, "   ", "", false},
+		{entity.CaptureText, strings.Repeat("a", customfields.MaxTextLength+1), "", false},
+		{"", "sem formato", "sem formato", true},
+		{entity.CaptureEmail, "Ana@Exemplo.com", "ana@exemplo.com", true},
+		{entity.CaptureEmail, "ana@", "", false},
+		{entity.CaptureNumber, "1500", "1500", true},
+		{entity.CaptureNumber, "1.500,50", "1500.5", true},
+		{entity.CaptureNumber, "1500.50", "1500.5", true},
+		{entity.CaptureNumber, "-3", "-3", true},
+		{entity.CaptureNumber, "mil", "", false},
+		{entity.CaptureNumber, "NaN", "", false},
+		{entity.CaptureNumber, "Inf", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseAnswer(tt.format, tt.text)
+		if ok != tt.ok || (tt.ok && got != tt.want) {
+			t.Errorf("parseAnswer(%q, %q) = %q, %v; esperado %q, %v", tt.format, tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSetLeadField(t *testing.T) {
+	tests := []struct {
+		field string
+		value string
+		want  string
+		ok    bool
+	}{
+		{"name", "Ana", "Ana", true},
+		{"name", strings.Repeat("a", 101), "", false},
+		{"email", "Ana@Exemplo.com", "ana@exemplo.com", true},
+		{"email", "ana", "", false},
+		{"stage", "qualificado", "qualificado", true},
+		{"source", "instagram", "instagram", true},
+		{"phone", "+5511987654321", "", false},
+	}
+
+	for _, tt := range tests {
+		lead := &entity.Lead{}
+		err := setLeadField(lead, tt.field, tt.value)
+		got := map[string]string{"name": lead.Name, "email": lead.Email, "stage": lead.Stage, "source": lead.Source}[tt.field]
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("setLeadField(%q, %q) = %q, %v; esperado %q", tt.field, tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestFieldValue(t *testing.T) {
+	lead := &entity.Lead{Name: "Ana", CustomFields: map[string]interface{}{"orcamento": float64(1500)}}
+	session := &entity.FlowSession{Answers: map[string]string{"interesse": "compra"}}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"name", "Ana"},
+		{"email", ""},
+		{"custom.orcamento", "1500"},
+		{"answers.interesse", "compra"},
+		{"answers.orcamento", ""},
+	}
+
+	for _, tt := range tests {
+		if got := fieldValue(lead, session, tt.field); got != tt.want {
+			t.Errorf("fieldValue(%q) = %q, esperado %q", tt.field, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,509 @@
+package chatbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/messaging"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/templates"
+	"github.com/whatsapp/backend/internal/validation"
+	"github.com/whatsapp/backend/internal/whatsapp"
+)
+
+// Parâmetros da execução dos fluxos
+const (
+	// maxAttempts é o número de respostas não aceitas em um nó antes da
+	// contingência
+	maxAttempts = 3
+	// maxSteps interrompe fluxos que circulam entre nós sem perguntas
+	maxSteps = 50
+	// timeoutInterval é o intervalo entre as verificações de prazos esgotados
+	timeoutInterval = 30 * time.Second
+	timeoutBatch    = 100
+	// timeoutRetry é o novo prazo das sessões reservadas, que voltam a
+	// expirar se o tratamento falhar antes de gravá-las
+	timeoutRetry = 5 * time.Minute
+)
+
+// Erros do início de um fluxo
+var (
+	ErrFlowDisabled  = errors.New("o fluxo está desativado")
+	ErrSessionActive = errors.New("a conversa já está em um fluxo do chatbot")
+)
+
+// errInvalidAnswer indica uma resposta não aceita pelo campo do lead
+var errInvalidAnswer = errors.New("resposta inválida")
+
+// Repository busca os fluxos e grava o estado das sessões
+type Repository interface {
+	GetByID(organizationID, id int64) (*entity.ChatbotFlow, error)
+	// StartSession grava a sessão, retornando false se a conversa já tiver
+	// uma sessão ativa
+	StartSession(session *entity.FlowSession) (bool, error)
+	ActiveSession(organizationID, conversationID int64) (*entity.FlowSession, error)
+	SaveSession(session *entity.FlowSession) error
+	ClaimExpired(now, retryAt time.Time, limit int) ([]*entity.FlowSession, error)
+}
+
+// Sender envia as mensagens do fluxo na conversa
+type Sender interface {
+	Send(req messaging.SendRequest) (*entity.Message, error)
+}
+
+// LeadStore busca o lead e grava as respostas nos campos, registrando as
+// alterações no histórico
+type LeadStore interface {
+	GetByID(organizationID, id int64) (*entity.Lead, error)
+	Update(organizationID, id, userID int64, apply func(lead *entity.Lead) error) (*entity.Lead, error)
+}
+
+// DefinitionSource fornece as definições de campos personalizados da organização
+type DefinitionSource interface {
+	ListByOrganization(organizationID int64) ([]*entity.CustomFieldDefinition, error)
+}
+
+// Assigner entrega o lead a um atendente escolhido ou pela distribuição automática
+type Assigner interface {
+	Assign(organizationID, leadID, conversationID int64, skill string) (int64, error)
+	AssignTo(organizationID, leadID, conversationID, userID int64) error
+}
+
+// Service executa os fluxos do chatbot nas conversas
+type Service struct {
+	flows    Repository
+	sender   Sender
+	leads    LeadStore
+	fields   DefinitionSource
+	assigner Assigner
+}
+
+// NewService cria uma nova instância do serviço do chatbot
+func NewService(flows Repository, sender Sender, leads LeadStore, fields DefinitionSource, assigner Assigner) *Service {
+	return &Service{
+		flows:    flows,
+		sender:   sender,
+		leads:    leads,
+		fields:   fields,
+		assigner: assigner,
+	}
+}
+
+// Start inicia o fluxo na conversa e executa os nós até a primeira
+// pergunta. Falhas na execução encerram a sessão com o motivo em Error, sem
+// retornar erro. Retorna sql.ErrNoRows se o fluxo ou o lead não existirem,
+// ErrFlowDisabled e ErrSessionActive.
+func (s *Service) Start(organizationID, flowID, conversationID, leadID int64) (*entity.FlowSession, error) {
+	flow, err := s.flows.GetByID(organizationID, flowID)
+	if err != nil {
+		return nil, err
+	}
+	if !flow.Enabled {
+		return nil, ErrFlowDisabled
+	}
+	lead, err := s.leads.GetByID(organizationID, leadID)
+	if err != nil {
+		return nil, err
+	}
+
+	session := entity.NewFlowSession(flow, conversationID, leadID)
+	started, err := s.flows.StartSession(session)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, ErrSessionActive
+	}
+	s.advance(flow, session, lead, flow.StartNode)
+	return session, nil
+}
+
+// HandleInbound entrega a mensagem recebida à sessão ativa da conversa,
+// retornando false se não houver uma. Um pedido de descadastro cancela a
+// sessão. Fluxos desativados durante a sessão continuam até o fim.
+func (s *Service) HandleInbound(result *entity.InboundResult) bool {
+	if result.Duplicate {
+		return false
+	}
+	message := result.Message
+	session, err := s.flows.ActiveSession(message.OrganizationID, result.Conversation.ID)
+	if err != nil {
+		return false
+	}
+	if result.OptedOut {
+		s.end(session, entity.FlowSessionCancelled, "o lead pediu o descadastro")
+		return false
+	}
+
+	flow, err := s.flows.GetByID(session.OrganizationID, session.FlowID)
+	if err != nil {
+		return false
+	}
+	s.answer(flow, session, result.Lead, message)
+	return true
+}
+
+// Active informa se a conversa está em um fluxo; enquanto estiver, o lead
+// não é distribuído
+func (s *Service) Active(organizationID, conversationID int64) bool {
+	_, err := s.flows.ActiveSession(organizationID, conversationID)
+	return err == nil
+}
+
+// Cancel encerra a sessão ativa da conversa, retornando sql.ErrNoRows se
+// não houver uma
+func (s *Service) Cancel(organizationID, conversationID int64) (*entity.FlowSession, error) {
+	session, err := s.flows.ActiveSession(organizationID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	session.Error = ""
+	session.End(entity.FlowSessionCancelled, time.Now().UTC())
+	if err := s.flows.SaveSession(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Run trata periodicamente as sessões com o prazo de resposta esgotado até
+// que o contexto seja cancelado
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(timeoutInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now().UTC()
+		sessions, err := s.flows.ClaimExpired(now, now.Add(timeoutRetry), timeoutBatch)
+		if err != nil {
+			continue
+		}
+		for _, session := range sessions {
+			if ctx.Err() != nil {
+				return
+			}
+			s.timeout(session)
+		}
+	}
+}
+
+// timeout segue pelo nó de contingência ou encerra a sessão; sem o handoff,
+// o lead segue para a distribuição automática
+func (s *Service) timeout(session *entity.FlowSession) {
+	flow, err := s.flows.GetByID(session.OrganizationID, session.FlowID)
+	if err != nil {
+		return
+	}
+	lead, err := s.leads.GetByID(session.OrganizationID, session.LeadID)
+	if err != nil {
+		return
+	}
+
+	s.fallback(flow, session, lead, entity.FlowSessionTimedOut, "o lead não respondeu no prazo")
+	if session.Status == entity.FlowSessionActive || session.Status == entity.FlowSessionHandedOff {
+		return
+	}
+	if _, err := s.assigner.Assign(session.OrganizationID, session.LeadID, session.ConversationID, ""); err != nil {
+		logger.Error("Erro ao distribuir lead após o fluxo do chatbot", err)
+	}
+}
+
+// answer trata a resposta do lead ao nó em que a sessão aguarda
+func (s *Service) answer(flow *entity.ChatbotFlow, session *entity.FlowSession, lead *entity.Lead, message *entity.Message) {
+	node := flow.Node(session.CurrentNode)
+	if node == nil || !node.WaitsForAnswer() {
+		s.end(session, entity.FlowSessionFailed, fmt.Sprintf("nó %q não encontrado no fluxo", session.CurrentNode))
+		return
+	}
+
+	var value string
+	ok := false
+	next := node.Next
+	if node.Type == entity.FlowNodeCapture {
+		value, ok = parseAnswer(node.Format, message.Body)
+	} else if option, matched := matchOption(node, message.ReplyID, message.Body); matched {
+		value, ok = option.Answer(), true
+		if option.Next != "" {
+			next = option.Next
+		}
+	}
+
+	if ok && node.Field != "" {
+		updated, err := s.saveField(lead, node.Field, value)
+		switch {
+		case errors.Is(err, errInvalidAnswer):
+			ok = false
+		case err != nil:
+			s.end(session, entity.FlowSessionFailed, "erro ao gravar a resposta no lead: "+err.Error())
+			return
+		default:
+			lead = updated
+		}
+	}
+	if !ok {
+		s.retry(flow, session, lead, node)
+		return
+	}
+
+	session.Answers[node.ID] = value
+	s.advance(flow, session, lead, next)
+}
+
+// retry repete a pergunta após uma resposta não aceita, seguindo pela
+// contingência depois de maxAttempts tentativas
+func (s *Service) retry(flow *entity.ChatbotFlow, session *entity.FlowSession, lead *entity.Lead, node *entity.FlowNode) {
+	session.Attempts++
+	if session.Attempts >= maxAttempts {
+		s.fallback(flow, session, lead, entity.FlowSessionFailed, fmt.Sprintf("resposta não aceita após %d tentativas", maxAttempts))
+		return
+	}
+	if node.RetryText != "" {
+		if err := s.send(session, messaging.SendRequest{Text: node.RetryText}); err != nil {
+			s.end(session, entity.FlowSessionFailed, err.Error())
+			return
+		}
+	}
+	s.wait(flow, session, node)
+}
+
+// fallback executa o nó de contingência uma única vez por sessão; sem ele,
+// a sessão é encerrada com status e o motivo
+func (s *Service) fallback(flow *entity.ChatbotFlow, session *entity.FlowSession, lead *entity.Lead, status, reason string) {
+	if flow.FallbackNode == "" || session.Fallback {
+		s.end(session, status, reason)
+		return
+	}
+	session.Fallback = true
+	s.advance(flow, session, lead, flow.FallbackNode)
+}
+
+// advance executa os nós a partir de nodeID até um nó que aguarde resposta,
+// um handoff ou o fim do fluxo
+func (s *Service) advance(flow *entity.ChatbotFlow, session *entity.FlowSession, lead *entity.Lead, nodeID string) {
+	for steps := 0; ; steps++ {
+		if nodeID == "" {
+			s.end(session, entity.FlowSessionCompleted, "")
+			return
+		}
+		if steps == maxSteps {
+			s.end(session, entity.FlowSessionFailed, fmt.Sprintf("o fluxo passou por %d nós sem aguardar resposta", maxSteps))
+			return
+		}
+		node := flow.Node(nodeID)
+		if node == nil {
+			s.end(session, entity.FlowSessionFailed, fmt.Sprintf("nó %q não encontrado no fluxo", nodeID))
+			return
+		}
+		session.CurrentNode = node.ID
+		session.Attempts = 0
+
+		switch node.Type {
+		case entity.FlowNodeSend:
+			if err := s.send(session, messaging.SendRequest{Text: node.Text}); err != nil {
+				s.end(session, entity.FlowSessionFailed, err.Error())
+				return
+			}
+			nodeID = node.Next
+		case entity.FlowNodeButtons, entity.FlowNodeList, entity.FlowNodeCapture:
+			s.wait(flow, session, node)
+			return
+		case entity.FlowNodeBranch:
+			nodeID = node.Next
+			for _, b := range node.Branches {
+				if matchBranch(b, fieldValue(lead, session, b.Field)) {
+					nodeID = b.Next
+					break
+				}
+			}
+		case entity.FlowNodeHandoff:
+			s.handoff(session, node)
+			return
+		default:
+			s.end(session, entity.FlowSessionFailed, fmt.Sprintf("tipo de nó %q desconhecido", node.Type))
+			return
+		}
+	}
+}
+
+// wait envia a pergunta do nó e aguarda a resposta até o prazo do fluxo
+func (s *Service) wait(flow *entity.ChatbotFlow, session *entity.FlowSession, node *entity.FlowNode) {
+	req := messaging.SendRequest{Text: node.Text}
+	if node.Type != entity.FlowNodeCapture {
+		interactive := &whatsapp.Interactive{Body: node.Text, Options: make([]whatsapp.InteractiveOption, len(node.Options))}
+		if node.Type == entity.FlowNodeList {
+			interactive.ListButton = node.ListButton
+		}
+		for i, o := range node.Options {
+			interactive.Options[i] = whatsapp.InteractiveOption{ID: o.ID, Title: o.Title, Description: o.Description}
+		}
+		req.Interactive = interactive
+	}
+	if err := s.send(session, req); err != nil {
+		s.end(session, entity.FlowSessionFailed, err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+	expires := now.Add(time.Duration(flow.TimeoutMinutes) * time.Minute)
+	session.ExpiresAt = &expires
+	session.UpdatedAt = now
+	_ = s.flows.SaveSession(session)
+}
+
+// handoff envia a mensagem de transferência e entrega o lead ao atendente;
+// sem atendente disponível o lead fica na fila da distribuição
+func (s *Service) handoff(session *entity.FlowSession, node *entity.FlowNode) {
+	reason := ""
+	if node.Text != "" {
+		if err := s.send(session, messaging.SendRequest{Text: node.Text}); err != nil {
+			reason = err.Error()
+		}
+	}
+
+	var err error
+	if node.UserID > 0 {
+		err = s.assigner.AssignTo(session.OrganizationID, session.LeadID, session.ConversationID, node.UserID)
+	} else {
+		_, err = s.assigner.Assign(session.OrganizationID, session.LeadID, session.ConversationID, entity.NormalizeSkill(node.Skill))
+	}
+	if err != nil {
+		logger.Error("Erro ao entregar conversa do chatbot ao atendente", map[string]interface{}{
+			"session_id": session.ID,
+			"error":      err.Error(),
+		})
+		reason = err.Error()
+	}
+	s.end(session, entity.FlowSessionHandedOff, reason)
+}
+
+// send envia a mensagem na conversa da sessão
+func (s *Service) send(session *entity.FlowSession, req messaging.SendRequest) error {
+	req.OrganizationID = session.OrganizationID
+	req.ConversationID = session.ConversationID
+	_, err := s.sender.Send(req)
+	return err
+}
+
+// end encerra e grava a sessão
+func (s *Service) end(session *entity.FlowSession, status, reason string) {
+	session.Error = reason
+	session.End(status, time.Now().UTC())
+	if status == entity.FlowSessionFailed {
+		logger.Warning("Fluxo do chatbot encerrado com falha", map[string]interface{}{
+			"session_id": session.ID,
+			"flow_id":    session.FlowID,
+			"reason":     reason,
+		})
+	}
+	_ = s.flows.SaveSession(session)
+}
+
+// saveField grava a resposta no campo do lead, retornando errInvalidAnswer
+// se o campo não aceitar o valor
+func (s *Service) saveField(lead *entity.Lead, field, value string) (*entity.Lead, error) {
+	var def *entity.CustomFieldDefinition
+	key, custom := strings.CutPrefix(field, "custom.")
+	if custom {
+		defs, err := s.fields.ListByOrganization(lead.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		def = customfields.NewSchema(defs)[key]
+	}
+
+	updated, err := s.leads.Update(lead.OrganizationID, lead.ID, 0, func(l *entity.Lead) error {
+		if custom {
+			// Campos removidos depois da criação do fluxo são gravados como texto
+			var normalized interface{} = value
+			if def != nil {
+				var verr *customfields.ValueError
+				if normalized, verr = customfields.Normalize(def, value); verr != nil {
+					return errInvalidAnswer
+				}
+			}
+			if l.CustomFields == nil {
+				l.CustomFields = make(map[string]interface{})
+			}
+			l.CustomFields[key] = normalized
+			return nil
+		}
+		return setLeadField(l, field, value)
+	})
+	return updated, err
+}
+
+// setLeadField grava a resposta em um campo fixo do lead
+func setLeadField(lead *entity.Lead, field, value string) error {
+	if max, ok := writableFields[field]; !ok || utf8.RuneCountInString(value) > max {
+		return errInvalidAnswer
+	}
+	switch field {
+	case "name":
+		lead.Name = value
+	case "email":
+		value = strings.ToLower(value)
+		if !validEmail(value) {
+			return errInvalidAnswer
+		}
+		lead.Email = value
+	case "source":
+		lead.Source = value
+	case "stage":
+		lead.Stage = value
+	}
+	return nil
+}
+
+// parseAnswer valida a resposta digitada conforme o formato do nó capture
+func parseAnswer(format, text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", false
+	}
+	switch format {
+	case entity.CaptureEmail:
+		text = strings.ToLower(text)
+		return text, validEmail(text)
+	case entity.CaptureNumber:
+		// Com vírgula decimal, os pontos separam os milhares (1.500,50)
+		if strings.Contains(text, ",") {
+			text = strings.ReplaceAll(strings.ReplaceAll(text, ".", ""), ",", ".")
+		}
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return "", false
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), true
+	}
+	return text, utf8.RuneCountInString(text) <= customfields.MaxTextLength
+}
+
+// emailAnswer aplica a validação de email das requisições às respostas
+type emailAnswer struct {
+	Email string `json:"email" validate:"email,max=100"`
+}
+
+func validEmail(value string) bool {
+	return len(validation.Struct(emailAnswer{Email: value})) == 0
+}
+
+// fieldValue retorna o valor do campo do lead ou da resposta dada em um nó
+func fieldValue(lead *entity.Lead, session *entity.FlowSession, field string) string {
+	if id, ok := strings.CutPrefix(field, entity.FlowAnswerPrefix); ok {
+		return session.Answers[id]
+	}
+	return templates.LeadFieldValue(lead, field)
+}
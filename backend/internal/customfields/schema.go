@@ -0,0 +1,117 @@
+package customfields
+
+import (
+	"sort"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// Schema reúne as definições de campos personalizados de uma organização, por chave
+type Schema map[string]*entity.CustomFieldDefinition
+
+// NewSchema indexa as definições pela chave
+func NewSchema(defs []*entity.CustomFieldDefinition) Schema {
+	s := make(Schema, len(defs))
+	for _, def := range defs {
+		s[def.Key] = def
+	}
+	return s
+}
+
+// Ordered retorna as definições na ordem de exibição
+func (s Schema) Ordered() []*entity.CustomFieldDefinition {
+	defs := make([]*entity.CustomFieldDefinition, 0, len(s))
+	for _, def := range s {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].Position != defs[j].Position {
+			return defs[i].Position < defs[j].Position
+		}
+		return defs[i].ID < defs[j].ID
+	})
+	return defs
+}
+
+// Required retorna as chaves dos campos obrigatórios, em ordem alfabética
+func (s Schema) Required() []string {
+	var keys []string
+	for key, def := range s {
+		if def.Required {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NormalizeFields normaliza em values os campos definidos, removendo os
+// vazios, e verifica os obrigatórios. Chaves sem definição são mantidas como
+// recebidas, preservando dados gravados antes da criação das definições. Os
+// erros usam prefix seguido da chave como nome do campo.
+func (s Schema) NormalizeFields(values map[string]interface{}, prefix string) []response.FieldError {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []response.FieldError
+	for _, key := range keys {
+		def, ok := s[key]
+		if !ok {
+			continue
+		}
+		value, verr := Normalize(def, values[key])
+		switch {
+		case verr != nil:
+			errs = append(errs, response.FieldError{Field: prefix + key, Code: verr.Code, Message: verr.Message})
+		case value == nil:
+			delete(values, key)
+		default:
+			values[key] = value
+		}
+	}
+
+	for _, key := range s.Required() {
+		if _, ok := values[key]; !ok {
+			errs = append(errs, response.FieldError{Field: prefix + key, Code: "required", Message: "Campo obrigatório"})
+		}
+	}
+	return errs
+}
+
+// NormalizeCondition verifica se a condição de filtro se refere a um campo
+// definido e aceita o operador, normalizando o valor conforme o tipo
+func (s Schema) NormalizeCondition(c *entity.CustomFieldCondition) *response.FieldError {
+	field := entity.CustomFieldPrefix + c.Key
+	if c.Op != entity.CustomFieldOpEq {
+		field += "." + c.Op
+	}
+
+	def, ok := s[c.Key]
+	if !ok {
+		return &response.FieldError{Field: field, Code: "unknown_field", Message: "Campo personalizado não definido"}
+	}
+
+	switch c.Op {
+	case entity.CustomFieldOpEq:
+	case entity.CustomFieldOpMin, entity.CustomFieldOpMax:
+		if !def.IsRangeable() {
+			return &response.FieldError{Field: field, Code: "invalid_operator", Message: "Intervalos só se aplicam a campos de número ou data"}
+		}
+	default:
+		return &response.FieldError{Field: field, Code: "invalid_operator", Message: "Use eq, min ou max"}
+	}
+
+	value, verr := Normalize(def, c.Value)
+	if verr != nil {
+		return &response.FieldError{Field: field, Code: verr.Code, Message: verr.Message}
+	}
+	if value == nil {
+		return &response.FieldError{Field: field, Code: "required", Message: "Informe o valor do filtro"}
+	}
+	c.Value = value
+	return nil
+}
@@ -0,0 +1,240 @@
+// Package customfields valida e normaliza os valores dos campos
+// personalizados dos leads conforme as definições de cada organização.
+package customfields
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// Limites dos valores
+const (
+	MaxTextLength   = 1000
+	MaxOptionLength = 100
+)
+
+// DateLayout é o formato em que datas são gravadas
+const DateLayout = "2006-01-02"
+
+// dateLayouts lista os formatos de data aceitos na entrada
+var dateLayouts = []string{DateLayout, "02/01/2006", time.RFC3339}
+
+// excelEpoch é a data zero dos números de série de datas do Excel
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// ValueError descreve por que um valor não é aceito pelo campo
+type ValueError struct {
+	Code    string
+	Message string
+}
+
+// Normalize converte o valor para a forma gravada no lead: string para
+// texto, data (AAAA-MM-DD) e seleção; float64 para número; bool; e []string
+// para múltipla escolha. Textos são aceitos em todos os tipos, como ocorre
+// na importação de planilhas. Valores vazios retornam nil sem erro.
+func Normalize(def *entity.CustomFieldDefinition, raw interface{}) (interface{}, *ValueError) {
+	if s, ok := raw.(string); ok {
+		raw = strings.TrimSpace(s)
+		if raw == "" {
+			return nil, nil
+		}
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	switch def.Type {
+	case entity.CustomFieldText:
+		return normalizeText(raw)
+	case entity.CustomFieldNumber:
+		return normalizeNumber(raw)
+	case entity.CustomFieldDate:
+		return normalizeDate(raw)
+	case entity.CustomFieldBoolean:
+		return normalizeBoolean(raw)
+	case entity.CustomFieldSelect:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, optionsError(def)
+		}
+		option, ok := matchOption(def, s)
+		if !ok {
+			return nil, optionsError(def)
+		}
+		return option, nil
+	case entity.CustomFieldMultiSelect:
+		return normalizeMultiSelect(def, raw)
+	}
+	return nil, &ValueError{Code: "invalid_type", Message: fmt.Sprintf("Tipo de campo %q desconhecido", def.Type)}
+}
+
+func normalizeText(raw interface{}) (interface{}, *ValueError) {
+	var s string
+	switch v := raw.(type) {
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		s = strconv.FormatBool(v)
+	default:
+		return nil, &ValueError{Code: "text", Message: "Deve ser um texto"}
+	}
+	if utf8.RuneCountInString(s) > MaxTextLength {
+		return nil, &ValueError{Code: "max", Message: fmt.Sprintf("Deve ter no máximo %d caracteres", MaxTextLength)}
+	}
+	return s, nil
+}
+
+// normalizeNumber aceita números JSON e textos como "1234.5" ou "1.234,50"
+func normalizeNumber(raw interface{}) (interface{}, *ValueError) {
+	invalid := &ValueError{Code: "number", Message: "Deve ser um número"}
+
+	var n float64
+	switch v := raw.(type) {
+	case float64:
+		n = v
+	case int:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case string:
+		s := strings.ReplaceAll(v, " ", "")
+		if strings.Contains(s, ",") {
+			// Formato brasileiro: ponto separa milhares e vírgula, decimais
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		}
+		parsed, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, invalid
+		}
+		n = parsed
+	default:
+		return nil, invalid
+	}
+
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return nil, invalid
+	}
+	return n, nil
+}
+
+// normalizeDate aceita AAAA-MM-DD, DD/MM/AAAA, RFC 3339 e o número de série
+// usado pelo Excel em células formatadas como data
+func normalizeDate(raw interface{}) (interface{}, *ValueError) {
+	invalid := &ValueError{Code: "date", Message: "Use o formato AAAA-MM-DD ou DD/MM/AAAA"}
+
+	var serial float64
+	switch v := raw.(type) {
+	case string:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.Format(DateLayout), nil
+			}
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, invalid
+		}
+		serial = n
+	case float64:
+		serial = v
+	default:
+		return nil, invalid
+	}
+
+	// Números de série entre 1900 e 2199
+	if serial < 1 || serial > 109574 {
+		return nil, invalid
+	}
+	return excelEpoch.AddDate(0, 0, int(serial)).Format(DateLayout), nil
+}
+
+// booleanWords associa as respostas aceitas em texto aos valores lógicos
+var booleanWords = map[string]bool{
+	"true": true, "sim": true, "s": true, "yes": true, "y": true, "1": true,
+	"false": false, "nao": false, "não": false, "n": false, "no": false, "0": false,
+}
+
+func normalizeBoolean(raw interface{}) (interface{}, *ValueError) {
+	switch v := raw.(type) {
+	case bool:
+		return v, nil
+	case float64:
+		if v == 0 || v == 1 {
+			return v == 1, nil
+		}
+	case string:
+		if b, ok := booleanWords[strings.ToLower(v)]; ok {
+			return b, nil
+		}
+	}
+	return nil, &ValueError{Code: "boolean", Message: "Use true/false ou sim/não"}
+}
+
+// normalizeMultiSelect aceita listas ou textos separados por ponto e vírgula
+// ou vírgula, removendo opções repetidas
+func normalizeMultiSelect(def *entity.CustomFieldDefinition, raw interface{}) (interface{}, *ValueError) {
+	var items []string
+	switch v := raw.(type) {
+	case string:
+		sep := ","
+		if strings.Contains(v, ";") {
+			sep = ";"
+		}
+		items = strings.Split(v, sep)
+	case []string:
+		items = v
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, optionsError(def)
+			}
+			items = append(items, s)
+		}
+	default:
+		return nil, optionsError(def)
+	}
+
+	values := []string{}
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		option, ok := matchOption(def, item)
+		if !ok {
+			return nil, optionsError(def)
+		}
+		if !seen[option] {
+			seen[option] = true
+			values = append(values, option)
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return values, nil
+}
+
+// matchOption encontra a opção ignorando maiúsculas e minúsculas
+func matchOption(def *entity.CustomFieldDefinition, value string) (string, bool) {
+	for _, option := range def.Options {
+		if strings.EqualFold(option, value) {
+			return option, true
+		}
+	}
+	return "", false
+}
+
+func optionsError(def *entity.CustomFieldDefinition) *ValueError {
+	return &ValueError{Code: "oneof", Message: "Use um dos valores: " + strings.Join(def.Options, ", ")}
+}
@@ -0,0 +1,134 @@
+// Package duplicates encontra leads possivelmente duplicados de uma
+// organização, comparando telefone, email e semelhança de nomes.
+package duplicates
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/pkg/phone"
+)
+
+// Motivos pelos quais um lead é apontado como possível duplicado
+const (
+	ReasonPhone = "phone"
+	ReasonEmail = "email"
+	ReasonName  = "name"
+)
+
+// Parâmetros da busca
+const (
+	// NameThreshold é a semelhança mínima para nomes serem considerados parecidos
+	NameThreshold = 0.5
+	// MaxCandidates limita os candidatos retornados
+	MaxCandidates = 20
+
+	maxSearchTerms   = 3
+	maxPrefiltered   = 200
+	phoneScore       = 1.0
+	emailScore       = 0.9
+	nameWeight       = 0.8
+	extraReasonBonus = 0.05
+)
+
+// LeadFinder pré-seleciona no banco os leads que podem ser duplicados
+type LeadFinder interface {
+	FindDuplicateCandidates(organizationID int64, phone, email string, nameTerms []string, excludeID int64, limit int) ([]*entity.Lead, error)
+}
+
+// Query descreve os dados comparados com os leads existentes
+type Query struct {
+	Name  string
+	Phone string
+	Email string
+	// ExcludeID ignora o próprio lead ao buscar duplicados de um lead existente
+	ExcludeID int64
+}
+
+// Candidate é um lead possivelmente duplicado, com a pontuação de 0 a 1 e os motivos
+type Candidate struct {
+	Lead    *entity.Lead `json:"lead"`
+	Score   float64      `json:"score"`
+	Reasons []string     `json:"reasons"`
+}
+
+// Service busca e pontua possíveis duplicados
+type Service struct {
+	leads LeadFinder
+}
+
+// NewService cria uma nova instância do serviço de duplicados
+func NewService(leads LeadFinder) *Service {
+	return &Service{
+		leads: leads,
+	}
+}
+
+// Find retorna os leads da organização parecidos com a consulta, do mais ao menos provável
+func (s *Service) Find(organizationID int64, q Query) ([]Candidate, error) {
+	normalizedPhone, _ := phone.Normalize(q.Phone)
+	email := strings.ToLower(strings.TrimSpace(q.Email))
+
+	leads, err := s.leads.FindDuplicateCandidates(organizationID, normalizedPhone, email,
+		searchTerms(q.Name, maxSearchTerms), q.ExcludeID, maxPrefiltered)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []Candidate{}
+	for _, lead := range leads {
+		if c, ok := score(lead, normalizedPhone, email, q.Name); ok {
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if len(candidates) > MaxCandidates {
+		candidates = candidates[:MaxCandidates]
+	}
+	return candidates, nil
+}
+
+// FindForLead retorna os possíveis duplicados de um lead existente
+func (s *Service) FindForLead(lead *entity.Lead) ([]Candidate, error) {
+	return s.Find(lead.OrganizationID, Query{
+		Name:      lead.Name,
+		Phone:     lead.Phone,
+		Email:     lead.Email,
+		ExcludeID: lead.ID,
+	})
+}
+
+// score compara o lead com a consulta. Leads trazidos apenas por um termo do
+// nome são descartados se a semelhança ficar abaixo de NameThreshold.
+func score(lead *entity.Lead, phone, email, name string) (Candidate, bool) {
+	c := Candidate{Lead: lead, Reasons: []string{}}
+	best := 0.0
+
+	if phone != "" && lead.Phone == phone {
+		c.Reasons = append(c.Reasons, ReasonPhone)
+		best = math.Max(best, phoneScore)
+	}
+	if email != "" && strings.EqualFold(lead.Email, email) {
+		c.Reasons = append(c.Reasons, ReasonEmail)
+		best = math.Max(best, emailScore)
+	}
+	if name != "" {
+		if similarity := nameSimilarity(lead.Name, name); similarity >= NameThreshold {
+			c.Reasons = append(c.Reasons, ReasonName)
+			best = math.Max(best, similarity*nameWeight)
+		}
+	}
+
+	if len(c.Reasons) == 0 {
+		return c, false
+	}
+
+	best += extraReasonBonus * float64(len(c.Reasons)-1)
+	c.Score = math.Round(math.Min(best, 1)*100) / 100
+	return c, true
+}
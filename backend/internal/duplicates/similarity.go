@@ -0,0 +1,53 @@
+package duplicates
+
+import (
+	"strings"
+
+	"github.com/whatsapp/backend/pkg/text"
+)
+
+// trigrams gera o conjunto de trigramas das palavras, com o mesmo
+// preenchimento usado pela extensão pg_trgm do PostgreSQL
+func trigrams(normalized string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, word := range strings.Fields(normalized) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// nameSimilarity retorna a semelhança entre dois nomes, de 0 a 1, pela
+// proporção de trigramas em comum
+func nameSimilarity(a, b string) float64 {
+	ta, tb := trigrams(text.Normalize(a)), trigrams(text.Normalize(b))
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// searchTerms escolhe as palavras do nome usadas para pré-selecionar candidatos no banco
+func searchTerms(name string, max int) []string {
+	var terms []string
+	for _, word := range strings.Fields(name) {
+		word = strings.Trim(word, ".,;-")
+		if len([]rune(word)) < 3 {
+			continue
+		}
+		terms = append(terms, word)
+		if len(terms) == max {
+			break
+		}
+	}
+	return terms
+}
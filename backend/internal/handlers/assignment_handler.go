@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// Limites da configuração de distribuição
+const (
+	maxRoutingRules  = 50
+	maxAgentSkills   = 20
+	maxSkillLength   = 50
+	maxRuleMatchTerm = 50
+)
+
+// AssignmentHandler gerencia a distribuição automática de leads e a
+// disponibilidade dos atendentes
+type AssignmentHandler struct {
+	assignmentRepo *repository.AssignmentRepository
+}
+
+// AssignmentSettingsRequest representa a configuração de distribuição da organização
+type AssignmentSettingsRequest struct {
+	Enabled  bool                 `json:"enabled" doc:"Distribui automaticamente os leads sem responsável que enviam mensagens pelo WhatsApp"`
+	Strategy string               `json:"strategy" validate:"required,oneof=round_robin least_open" doc:"round_robin reveza os atendentes; least_open escolhe quem tem menos conversas abertas"`
+	Rules    []entity.RoutingRule `json:"rules" doc:"Avaliadas em ordem; a primeira regra cuja etiqueta o lead possui ou cuja palavra aparece na mensagem define a habilidade exigida"`
+}
+
+// Validate verifica as regras de direcionamento
+func (req AssignmentSettingsRequest) Validate() []response.FieldError {
+	var errs []response.FieldError
+	if len(req.Rules) > maxRoutingRules {
+		errs = append(errs, response.FieldError{Field: "rules", Code: "max", Message: fmt.Sprintf("Informe no máximo %d regras", maxRoutingRules)})
+	}
+	for i, rule := range req.Rules {
+		field := fmt.Sprintf("rules[%d]", i)
+		if skill := entity.NormalizeSkill(rule.Skill); skill == "" || len(skill) > maxSkillLength {
+			errs = append(errs, response.FieldError{Field: field + ".skill", Code: "required", Message: fmt.Sprintf("Informe a habilidade com até %d caracteres", maxSkillLength)})
+		}
+		if len(rule.Tags) == 0 && len(rule.Keywords) == 0 {
+			errs = append(errs, response.FieldError{Field: field, Code: "required", Message: "Informe etiquetas ou palavras-chave"})
+		}
+		for _, term := range append(append([]string{}, rule.Tags...), rule.Keywords...) {
+			if t := strings.TrimSpace(term); t == "" || len(t) > maxRuleMatchTerm {
+				errs = append(errs, response.FieldError{Field: field, Code: "invalid", Message: fmt.Sprintf("Etiquetas e palavras-chave não podem ser vazias nem ter mais de %d caracteres", maxRuleMatchTerm)})
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// AgentSettingsRequest representa a configuração de atendimento de um usuário
+type AgentSettingsRequest struct {
+	Available            bool     `json:"available" doc:"Atendentes indisponíveis não recebem novos leads"`
+	Skills               []string `json:"skills" doc:"Habilidades usadas pelas regras de direcionamento"`
+	MaxOpenConversations int      `json:"max_open_conversations" validate:"min=0,max=1000" doc:"Limite de conversas abertas para receber novos leads; 0 para sem limite"`
+}
+
+// Validate verifica as habilidades
+func (req AgentSettingsRequest) Validate() []response.FieldError {
+	if len(req.Skills) > maxAgentSkills {
+		return []response.FieldError{{Field: "skills", Code: "max", Message: fmt.Sprintf("Informe no máximo %d habilidades", maxAgentSkills)}}
+	}
+	for _, skill := range req.Skills {
+		if s := entity.NormalizeSkill(skill); s == "" || len(s) > maxSkillLength {
+			return []response.FieldError{{Field: "skills", Code: "invalid", Message: fmt.Sprintf("Habilidades não podem ser vazias nem ter mais de %d caracteres", maxSkillLength)}}
+		}
+	}
+	return nil
+}
+
+// AgentListResponse lista os atendentes da organização
+type AgentListResponse struct {
+	Data []*entity.AgentSettings `json:"data"`
+}
+
+// NewAssignmentHandler cria uma nova instância do manipulador de distribuição
+func NewAssignmentHandler(assignmentRepo *repository.AssignmentRepository) *AssignmentHandler {
+	return &AssignmentHandler{
+		assignmentRepo: assignmentRepo,
+	}
+}
+
+// GetSettings retorna a configuração de distribuição da organização
+func (h *AssignmentHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.assignmentRepo.GetSettings(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, settings)
+}
+
+// UpdateSettings substitui a configuração de distribuição da organização
+func (h *AssignmentHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	var req AssignmentSettingsRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	settings := &entity.AssignmentSettings{
+		OrganizationID: orgID,
+		Enabled:        req.Enabled,
+		Strategy:       req.Strategy,
+		Rules:          make([]entity.RoutingRule, 0, len(req.Rules)),
+		UpdatedAt:      time.Now(),
+	}
+	for _, rule := range req.Rules {
+		settings.Rules = append(settings.Rules, entity.RoutingRule{
+			Skill:    entity.NormalizeSkill(rule.Skill),
+			Tags:     trimmedTerms(rule.Tags),
+			Keywords: trimmedTerms(rule.Keywords),
+		})
+	}
+
+	if err := h.assignmentRepo.SaveSettings(settings); err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, settings)
+}
+
+// ListAgents lista os usuários da organização com disponibilidade,
+// habilidades e conversas abertas
+func (h *AssignmentHandler) ListAgents(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	agents, err := h.assignmentRepo.ListAgents(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, AgentListResponse{Data: agents})
+}
+
+// UpdateAgent altera a configuração de atendimento de um usuário da organização
+func (h *AssignmentHandler) UpdateAgent(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req AgentSettingsRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	agent, err := h.assignmentRepo.GetAgent(orgID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	agent.Available = req.Available
+	agent.MaxOpenConversations = req.MaxOpenConversations
+	agent.Skills = []string{}
+	seen := make(map[string]bool)
+	for _, skill := range req.Skills {
+		if s := entity.NormalizeSkill(skill); !seen[s] {
+			seen[s] = true
+			agent.Skills = append(agent.Skills, s)
+		}
+	}
+
+	if err := h.assignmentRepo.SaveAgent(orgID, agent); err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, agent)
+}
+
+// trimmedTerms remove espaços nas pontas das etiquetas e palavras-chave das regras
+func trimmedTerms(terms []string) []string {
+	result := make([]string, 0, len(terms))
+	for _, t := range terms {
+		result = append(result, strings.TrimSpace(t))
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// codes resume os erros como "campo:código" para comparar nas tabelas
+func codes(errs []response.FieldError) string {
+	parts := make([]string, len(errs))
+	for i, e := range errs {
+		parts[i] = e.Field + ":" + e.Code
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestAssignmentSettingsRequestValidate(t *testing.T) {
+	rule := entity.RoutingRule{Skill: "vendas", Keywords: []string{"preço"}}
+	rules := func(n int) []entity.RoutingRule {
+		r := make([]entity.RoutingRule, n)
+		for i := range r {
+			r[i] = rule
+		}
+		return r
+	}
+
+	tests := []struct {
+		name  string
+		rules []entity.RoutingRule
+		want  string
+	}{
+		{"sem regras", nil, ""},
+		{"regra por palavra-chave", []entity.RoutingRule{rule}, ""},
+		{"regra por etiqueta", []entity.RoutingRule{{Skill: "financeiro", Tags: []string{"inadimplente"}}}, ""},
+		{"regras no limite", rules(maxRoutingRules), ""},
+		{"regras demais", rules(maxRoutingRules + 1), "rules:max"},
+		{"sem habilidade", []entity.RoutingRule{{Skill: "  ", Tags: []string{"vip"}}}, "rules[0].skill:required"},
+		{"habilidade longa", []entity.RoutingRule{{Skill: strings.Repeat("a", maxSkillLength+1), Tags: []string{"vip"}}}, "rules[0].skill:required"},
+		{"sem etiquetas nem palavras", []entity.RoutingRule{rule, {Skill: "vendas"}}, "rules[1]:required"},
+		{"palavra vazia", []entity.RoutingRule{{Skill: "vendas", Keywords: []string{"preço", " "}}}, "rules[0]:invalid"},
+		{"etiqueta longa", []entity.RoutingRule{{Skill: "vendas", Tags: []string{strings.Repeat("a", maxRuleMatchTerm+1), ""}}}, "rules[0]:invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := AssignmentSettingsRequest{Strategy: entity.AssignmentRoundRobin, Rules: tt.rules}
+			if got := codes(req.Validate()); got != tt.want {
+				t.Errorf("Validate = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgentSettingsRequestValidate(t *testing.T) {
+	skills := make([]string, maxAgentSkills+1)
+	for i := range skills {
+		skills[i] = "vendas"
+	}
+
+	tests := []struct {
+		name   string
+		skills []string
+		want   string
+	}{
+		{"sem habilidades", nil, ""},
+		{"habilidades", []string{"vendas", " Financeiro "}, ""},
+		{"habilidades no limite", skills[:maxAgentSkills], ""},
+		{"habilidades demais", skills, "skills:max"},
+		{"habilidade vazia", []string{"vendas", "  "}, "skills:invalid"},
+		{"habilidade longa", []string{strings.Repeat("a", maxSkillLength+1)}, "skills:invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := AgentSettingsRequest{Skills: tt.skills}
+			if got := codes(req.Validate()); got != tt.want {
+				t.Errorf("Validate = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
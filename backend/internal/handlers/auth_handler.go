@@ -12,30 +12,40 @@ import (
 	"github.com/whatsapp/backend/internal/logger"
 	"github.com/whatsapp/backend/internal/models/entity"
 	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
 )
 
 // AuthHandler gerencia as rotas de autenticação
 type AuthHandler struct {
 	userRepo    *repository.UserRepository
+	inviteRepo  *repository.OrganizationInviteRepository
 	authService *auth.Service
 }
 
 // RegisterRequest representa os dados para registro de usuário
 type RegisterRequest struct {
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Name     string `json:"name" validate:"required,max=100"`
+	Email    string `json:"email" validate:"required,email,max=100"`
+	Password string `json:"password" validate:"required,password"`
 }
 
 // LoginRequest representa os dados para login
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,max=100"`
+	Password string `json:"password" validate:"required,max=72"`
+}
+
+// AcceptInviteRequest representa os dados para entrar em uma organização
+// pelo convite. O email é o do convite.
+type AcceptInviteRequest struct {
+	Token    string `json:"token" validate:"required,max=255"`
+	Name     string `json:"name" validate:"required,max=100"`
+	Password string `json:"password" validate:"required,password"`
 }
 
 // RefreshTokenRequest representa os dados para renovação de token
 type RefreshTokenRequest struct {
-	RefreshToken string `json:"refresh_token"`
+	RefreshToken string `json:"refresh_token" validate:"required,max=255"`
 }
 
 // AuthResponse representa a resposta de autenticação
@@ -47,9 +57,10 @@ type AuthResponse struct {
 }
 
 // NewAuthHandler cria uma nova instância do manipulador de autenticação
-func NewAuthHandler(userRepo *repository.UserRepository, authService *auth.Service) *AuthHandler {
+func NewAuthHandler(userRepo *repository.UserRepository, inviteRepo *repository.OrganizationInviteRepository, authService *auth.Service) *AuthHandler {
 	return &AuthHandler{
 		userRepo:    userRepo,
+		inviteRepo:  inviteRepo,
 		authService: authService,
 	}
 }
@@ -57,31 +68,22 @@ func NewAuthHandler(userRepo *repository.UserRepository, authService *auth.Servi
 // Register registra um novo usuário
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req RegisterRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		logger.Error("Erro ao decodificar corpo da requisição", err)
-		http.Error(w, "Formato de requisição inválido", http.StatusBadRequest)
-		return
-	}
-
-	// Validações básicas
-	if req.Name == "" || req.Email == "" || req.Password == "" {
-		http.Error(w, "Todos os campos são obrigatórios", http.StatusBadRequest)
+	if !decodeAndValidate(w, r, &req) {
 		return
 	}
 
 	// Verificar se o email já existe
-	_, err = h.userRepo.GetByEmail(req.Email)
+	_, err := h.userRepo.GetByEmail(req.Email)
 	if err == nil {
 		// Adicionando um pequeno atraso para dificultar enumeração de emails
 		time.Sleep(time.Duration(200+rand.Intn(300)) * time.Millisecond)
 		logger.Warning("Tentativa de registro com email já existente", map[string]interface{}{"email": req.Email})
 		// Resposta genérica para não confirmar que o email existe
-		http.Error(w, "Erro ao processar solicitação", http.StatusConflict)
+		response.Error(w, r, http.StatusConflict, response.CodeConflict, "Erro ao processar solicitação")
 		return
 	} else if !errors.Is(err, sql.ErrNoRows) {
 		logger.Error("Erro ao verificar email existente", err)
-		http.Error(w, "Erro ao processar solicitação", http.StatusInternalServerError)
+		response.Internal(w, r)
 		return
 	}
 
@@ -89,59 +91,66 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	user, err := entity.NewUser(req.Name, req.Email, req.Password)
 	if err != nil {
 		logger.Error("Erro ao criar novo usuário", err)
-		http.Error(w, "Erro ao processar solicitação", http.StatusInternalServerError)
+		response.Internal(w, r)
 		return
 	}
 
-	// Salvar usuário no banco
-	err = h.userRepo.Create(user)
+	// Salvar usuário no banco junto com sua organização
+	err = h.userRepo.CreateWithOrganization(user, entity.NewOrganization(req.Name))
 	if err != nil {
 		logger.Error("Erro ao salvar usuário no banco", err)
-		http.Error(w, "Erro ao processar solicitação", http.StatusInternalServerError)
+		response.Internal(w, r)
 		return
 	}
 
-	// Gerar tokens
-	accessToken, err := h.authService.GenerateJWT(user)
+	h.respondWithTokens(w, r, user, http.StatusCreated)
+}
+
+// AcceptInvite cria o usuário convidado na organização do convite
+func (h *AuthHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
+	var req AcceptInviteRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	invite, err := h.inviteRepo.GetByToken(req.Token)
 	if err != nil {
-		logger.Error("Erro ao gerar JWT", err)
-		http.Error(w, "Erro ao gerar token de acesso", http.StatusInternalServerError)
+		if errors.Is(err, repository.ErrInviteUnavailable) {
+			// Atraso para dificultar ataques de força bruta em tokens
+			time.Sleep(time.Duration(200+rand.Intn(300)) * time.Millisecond)
+			response.Error(w, r, http.StatusBadRequest, response.CodeInvalidToken, "Convite inválido ou expirado")
+			return
+		}
+		response.Internal(w, r)
 		return
 	}
 
-	refreshToken, err := h.authService.GenerateRefreshToken(user.ID)
+	user, err := entity.NewUser(req.Name, invite.Email, req.Password)
 	if err != nil {
-		logger.Error("Erro ao gerar refresh token", err)
-		http.Error(w, "Erro ao gerar token de atualização", http.StatusInternalServerError)
+		logger.Error("Erro ao criar novo usuário", err)
+		response.Internal(w, r)
 		return
 	}
 
-	// Preparar resposta
-	resp := AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken.Token,
-		ExpiresIn:    int64(h.authService.GetTokenExpiry().Seconds()),
-		TokenType:    "Bearer",
+	if err := h.inviteRepo.Accept(invite, user); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInviteUnavailable):
+			response.Error(w, r, http.StatusBadRequest, response.CodeInvalidToken, "Convite inválido ou expirado")
+		case errors.Is(err, repository.ErrEmailInUse):
+			response.Error(w, r, http.StatusConflict, response.CodeConflict, "Email já cadastrado")
+		default:
+			response.Internal(w, r)
+		}
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(resp)
+	h.respondWithTokens(w, r, user, http.StatusCreated)
 }
 
 // Login autentica um usuário
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		logger.Error("Erro ao decodificar corpo da requisição", err)
-		http.Error(w, "Formato de requisição inválido", http.StatusBadRequest)
-		return
-	}
-
-	// Validações básicas
-	if req.Email == "" || req.Password == "" {
-		http.Error(w, "Email e senha são obrigatórios", http.StatusBadRequest)
+	if !decodeAndValidate(w, r, &req) {
 		return
 	}
 
@@ -155,102 +164,50 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Warning("Tentativa de login com email não cadastrado", map[string]interface{}{"email": req.Email})
-			http.Error(w, "Credenciais inválidas", http.StatusUnauthorized)
+			response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidCredentials, "Credenciais inválidas")
 			return
 		}
 		logger.Error("Erro ao buscar usuário por email", err)
-		http.Error(w, "Erro ao processar solicitação", http.StatusInternalServerError)
+		response.Internal(w, r)
 		return
 	}
 
 	// Verificar senha
 	if !user.ComparePassword(req.Password) {
 		logger.Warning("Tentativa de login com senha incorreta", map[string]interface{}{"email": req.Email})
-		http.Error(w, "Credenciais inválidas", http.StatusUnauthorized)
+		response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidCredentials, "Credenciais inválidas")
 		return
 	}
 
-	// Gerar tokens
-	accessToken, err := h.authService.GenerateJWT(user)
-	if err != nil {
-		logger.Error("Erro ao gerar JWT", err)
-		http.Error(w, "Erro ao gerar token de acesso", http.StatusInternalServerError)
-		return
-	}
-
-	refreshToken, err := h.authService.GenerateRefreshToken(user.ID)
-	if err != nil {
-		logger.Error("Erro ao gerar refresh token", err)
-		http.Error(w, "Erro ao gerar token de atualização", http.StatusInternalServerError)
-		return
-	}
-
-	// Preparar resposta
-	resp := AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken.Token,
-		ExpiresIn:    int64(h.authService.GetTokenExpiry().Seconds()),
-		TokenType:    "Bearer",
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	h.respondWithTokens(w, r, user, http.StatusOK)
 }
 
 // RefreshToken renova o token de acesso usando um refresh token
 func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	var req RefreshTokenRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		logger.Error("Erro ao decodificar corpo da requisição", err)
-		http.Error(w, "Formato de requisição inválido", http.StatusBadRequest)
+	if !decodeAndValidate(w, r, &req) {
 		return
 	}
 
-	// Validações básicas
-	if req.RefreshToken == "" {
-		http.Error(w, "Token de atualização é obrigatório", http.StatusBadRequest)
-		return
-	}
-
-	// Extrair informações do token atual para obter o email
-	tokenString := r.Header.Get("Authorization")
-	if len(tokenString) > 7 && tokenString[:7] == "Bearer " {
-		tokenString = tokenString[7:]
-	}
-
-	var userEmail string
-	if tokenString != "" {
-		claims, _ := h.authService.ValidateJWT(tokenString)
-		if claims != nil {
-			userEmail = claims.Email
-		}
-	}
-
-	// Se não conseguir extrair o email do token atual, buscar pelo ID do usuário no refresh token
-	if userEmail == "" {
-		refreshToken, err := h.authService.GetRefreshTokenByToken(req.RefreshToken)
-		if err == nil {
-			user, err := h.userRepo.GetByID(refreshToken.UserID)
-			if err == nil {
-				userEmail = user.Email
-			}
-		}
+	// Buscar o usuário dono do refresh token para emitir o JWT com os dados atuais
+	var user *entity.User
+	if refreshToken, err := h.authService.GetRefreshTokenByToken(req.RefreshToken); err == nil {
+		user, _ = h.userRepo.GetByID(refreshToken.UserID)
 	}
 
 	// Renovar tokens
-	newAccessToken, newRefreshToken, err := h.authService.RefreshAccessToken(req.RefreshToken, userEmail)
+	newAccessToken, newRefreshToken, err := h.authService.RefreshAccessToken(req.RefreshToken, user)
 	if err != nil {
 		// Adicionar atraso para dificultar ataques de força bruta em tokens
 		time.Sleep(time.Duration(200+rand.Intn(300)) * time.Millisecond)
 
 		if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrExpiredToken) {
 			logger.Warning("Tentativa de usar refresh token inválido", map[string]interface{}{"token": req.RefreshToken})
-			http.Error(w, "Autenticação inválida", http.StatusUnauthorized)
+			response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidToken, "Autenticação inválida")
 			return
 		}
 		logger.Error("Erro ao renovar tokens", err)
-		http.Error(w, "Erro ao processar solicitação", http.StatusInternalServerError)
+		response.Internal(w, r)
 		return
 	}
 
@@ -262,8 +219,7 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		TokenType:    "Bearer",
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	response.JSON(w, http.StatusOK, resp)
 }
 
 // Logout invalida todos os tokens de atualização do usuário
@@ -271,7 +227,7 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	// Extrair claims do token
 	tokenString := r.Header.Get("Authorization")
 	if len(tokenString) <= 7 || tokenString[:7] != "Bearer " {
-		http.Error(w, "Autenticação necessária", http.StatusBadRequest)
+		response.Error(w, r, http.StatusBadRequest, response.CodeAuthRequired, "Autenticação necessária")
 		return
 	}
 	tokenString = tokenString[7:]
@@ -292,7 +248,7 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 			}
 		} else {
 			logger.Warning("Tentativa de logout com token inválido", err)
-			http.Error(w, "Autenticação inválida", http.StatusUnauthorized)
+			response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidToken, "Autenticação inválida")
 			return
 		}
 	} else {
@@ -303,5 +259,29 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	response.NoContent(w)
+}
+
+// respondWithTokens emite o token de acesso e o refresh token do usuário
+func (h *AuthHandler) respondWithTokens(w http.ResponseWriter, r *http.Request, user *entity.User, status int) {
+	accessToken, err := h.authService.GenerateJWT(user)
+	if err != nil {
+		logger.Error("Erro ao gerar JWT", err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Erro ao gerar token de acesso")
+		return
+	}
+
+	refreshToken, err := h.authService.GenerateRefreshToken(user.ID)
+	if err != nil {
+		logger.Error("Erro ao gerar refresh token", err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Erro ao gerar token de atualização")
+		return
+	}
+
+	response.JSON(w, status, AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken.Token,
+		ExpiresIn:    int64(h.authService.GetTokenExpiry().Seconds()),
+		TokenType:    "Bearer",
+	})
 }
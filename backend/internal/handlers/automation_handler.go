@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/automation"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// AutomationHandler gerencia as regras de automação das mensagens recebidas
+type AutomationHandler struct {
+	automationRepo *repository.AutomationRepository
+	templateRepo   *repository.TemplateRepository
+	userRepo       *repository.UserRepository
+	chatbotRepo    *repository.ChatbotRepository
+	calendarRepo   *repository.BusinessCalendarRepository
+}
+
+// AutomationRuleRequest representa o conteúdo de uma regra de automação
+type AutomationRuleRequest struct {
+	Name            string                       `json:"name" validate:"required,max=200"`
+	Enabled         *bool                        `json:"enabled,omitempty" doc:"Padrão true"`
+	Conditions      []entity.AutomationCondition `json:"conditions" doc:"Todas precisam ser atendidas: keyword (keywords), regex (pattern), first_message ou outside_business_hours (calendar_id ou business_hours; sem eles vale o calendário padrão)"`
+	Actions         []entity.AutomationAction    `json:"actions" doc:"Executadas em ordem: send_text (text), send_template (template_id), add_tag (tag), move_stage (stage) ou assign (user_id, ou skill para a distribuição automática) ou start_flow (flow_id)"`
+	StopProcessing  bool                         `json:"stop_processing" doc:"Encerra a avaliação das regras seguintes quando a regra é executada"`
+	CooldownMinutes *int                         `json:"cooldown_minutes,omitempty" doc:"Intervalo mínimo entre execuções da regra na mesma conversa; padrão 60"`
+}
+
+// AutomationOrderRequest representa a nova ordem de avaliação das regras
+type AutomationOrderRequest struct {
+	IDs []int64 `json:"ids" validate:"required" doc:"IDs de todas as regras da organização, na ordem de avaliação"`
+}
+
+// AutomationTestRequest representa uma mensagem simulada para o teste das regras
+type AutomationTestRequest struct {
+	Text         string                 `json:"text" validate:"max=4096" doc:"Texto da mensagem recebida"`
+	FirstMessage bool                   `json:"first_message" doc:"Simula a primeira mensagem de um lead novo"`
+	At           *time.Time             `json:"at,omitempty" doc:"Horário do recebimento em RFC 3339; padrão agora"`
+	Rule         *AutomationRuleRequest `json:"rule,omitempty" doc:"Regra ainda não salva a testar; sem ela são avaliadas as regras ativas"`
+}
+
+// AutomationTestResponse representa o resultado do teste das regras, sem
+// que nenhuma ação seja executada
+type AutomationTestResponse struct {
+	Rules []automation.Evaluation `json:"rules"`
+}
+
+// AutomationRunListResponse representa uma página de execuções de uma regra
+type AutomationRunListResponse struct {
+	Data   []*entity.AutomationRun `json:"data"`
+	Total  int                     `json:"total"`
+	Limit  int                     `json:"limit"`
+	Offset int                     `json:"offset"`
+}
+
+// NewAutomationHandler cria uma nova instância do manipulador de automações
+func NewAutomationHandler(automationRepo *repository.AutomationRepository, templateRepo *repository.TemplateRepository, userRepo *repository.UserRepository, chatbotRepo *repository.ChatbotRepository, calendarRepo *repository.BusinessCalendarRepository) *AutomationHandler {
+	return &AutomationHandler{
+		automationRepo: automationRepo,
+		templateRepo:   templateRepo,
+		userRepo:       userRepo,
+		chatbotRepo:    chatbotRepo,
+		calendarRepo:   calendarRepo,
+	}
+}
+
+// List retorna as regras da organização na ordem de avaliação
+func (h *AutomationHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	rules, err := h.automationRepo.List(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusOK, rules)
+}
+
+// Create grava uma nova regra ao final da ordem de avaliação
+func (h *AutomationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	var req AutomationRuleRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	rule := entity.NewAutomationRule(orgID, userID)
+	if !h.applyRequest(w, r, rule, req) {
+		return
+	}
+
+	if err := h.automationRepo.Create(rule); err != nil {
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusCreated, rule)
+}
+
+// Get retorna uma regra
+func (h *AutomationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.loadRule(w, r)
+	if !ok {
+		return
+	}
+	response.JSON(w, http.StatusOK, rule)
+}
+
+// Update substitui o conteúdo de uma regra, mantendo a posição
+func (h *AutomationHandler) Update(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.loadRule(w, r)
+	if !ok {
+		return
+	}
+
+	var req AutomationRuleRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if !h.applyRequest(w, r, rule, req) {
+		return
+	}
+
+	if err := h.automationRepo.Update(rule); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusOK, rule)
+}
+
+// Delete remove uma regra
+func (h *AutomationHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.automationRepo.Delete(orgID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	response.NoContent(w)
+}
+
+// Reorder define a ordem de avaliação das regras
+func (h *AutomationHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	var req AutomationOrderRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	if err := h.automationRepo.Reorder(orgID, req.IDs); err != nil {
+		if errors.Is(err, repository.ErrInvalidRuleOrder) {
+			response.ValidationError(w, r, []response.FieldError{{Field: "ids", Code: "invalid_order", Message: "Informe todas as regras da organização, cada uma uma única vez"}})
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	rules, err := h.automationRepo.List(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusOK, rules)
+}
+
+// Test avalia uma mensagem simulada contra as regras ativas, ou contra a
+// regra informada, sem executar nenhuma ação
+func (h *AutomationHandler) Test(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	var req AutomationTestRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	in := automation.Input{Text: req.Text, FirstMessage: req.FirstMessage, At: time.Now()}
+	if req.At != nil {
+		in.At = *req.At
+	}
+
+	var rules []*entity.AutomationRule
+	if req.Rule != nil {
+		rule := entity.NewAutomationRule(orgID, 0)
+		if strings.TrimSpace(req.Rule.Name) == "" {
+			req.Rule.Name = "teste"
+		}
+		if !h.applyRequest(w, r, rule, *req.Rule) {
+			return
+		}
+		rules = []*entity.AutomationRule{rule}
+	} else {
+		var err error
+		if rules, err = h.automationRepo.ListEnabled(orgID); err != nil {
+			response.Internal(w, r)
+			return
+		}
+	}
+
+	if automation.UsesCalendars(rules) {
+		var err error
+		if in.Calendars, err = h.calendarRepo.List(orgID); err != nil {
+			response.Internal(w, r)
+			return
+		}
+	}
+
+	response.JSON(w, http.StatusOK, AutomationTestResponse{Rules: automation.Evaluate(rules, in)})
+}
+
+// Runs retorna o registro das execuções de uma regra
+func (h *AutomationHandler) Runs(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.loadRule(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset, errs := parsePagination(r.URL.Query())
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	runs, total, err := h.automationRepo.ListRuns(rule.OrganizationID, rule.ID, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, AutomationRunListResponse{
+		Data:   runs,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// applyRequest copia o corpo para a regra e valida a estrutura e as
+// referências das ações, respondendo 422 se inválidas
+func (h *AutomationHandler) applyRequest(w http.ResponseWriter, r *http.Request, rule *entity.AutomationRule, req AutomationRuleRequest) bool {
+	rule.Name = strings.TrimSpace(req.Name)
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	rule.Conditions = req.Conditions
+	rule.Actions = req.Actions
+	rule.StopProcessing = req.StopProcessing
+	if req.CooldownMinutes != nil {
+		rule.CooldownMinutes = *req.CooldownMinutes
+	}
+
+	errs := automation.Validate(rule)
+	for i, c := range rule.Conditions {
+		if c.Type != entity.ConditionOutsideBusinessHours || c.CalendarID <= 0 {
+			continue
+		}
+		if _, err := h.calendarRepo.GetByID(rule.OrganizationID, c.CalendarID); err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				response.Internal(w, r)
+				return false
+			}
+			errs = append(errs, response.FieldError{Field: fmt.Sprintf("conditions[%d].calendar_id", i), Code: "not_found", Message: "Calendário não encontrado"})
+		}
+	}
+	for i, a := range rule.Actions {
+		field := fmt.Sprintf("actions[%d]", i)
+		switch {
+		case a.Type == entity.ActionSendTemplate && a.TemplateID > 0:
+			if _, err := h.templateRepo.GetByID(rule.OrganizationID, a.TemplateID); err != nil {
+				if !errors.Is(err, sql.ErrNoRows) {
+					response.Internal(w, r)
+					return false
+				}
+				errs = append(errs, response.FieldError{Field: field + ".template_id", Code: "not_found", Message: "Modelo não encontrado"})
+			}
+		case a.Type == entity.ActionAssign && a.UserID > 0:
+			user, err := h.userRepo.GetByID(a.UserID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				response.Internal(w, r)
+				return false
+			}
+			if user == nil || user.OrganizationID != rule.OrganizationID {
+				errs = append(errs, response.FieldError{Field: field + ".user_id", Code: "not_found", Message: "Usuário não encontrado na organização"})
+			}
+		case a.Type == entity.ActionStartFlow && a.FlowID > 0:
+			if _, err := h.chatbotRepo.GetByID(rule.OrganizationID, a.FlowID); err != nil {
+				if !errors.Is(err, sql.ErrNoRows) {
+					response.Internal(w, r)
+					return false
+				}
+				errs = append(errs, response.FieldError{Field: field + ".flow_id", Code: "not_found", Message: "Fluxo do chatbot não encontrado"})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return false
+	}
+	return true
+}
+
+// loadRule busca a regra da rota, respondendo 404 se não existir
+func (h *AutomationHandler) loadRule(w http.ResponseWriter, r *http.Request) (*entity.AutomationRule, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	rule, err := h.automationRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+	return rule, true
+}
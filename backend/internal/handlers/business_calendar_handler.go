@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/businesshours"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// maxSLAMinutes limita a meta dos relógios de SLA a 30 dias de atendimento
+const maxSLAMinutes = 30 * 24 * 60
+
+// BusinessCalendarHandler gerencia os calendários de atendimento
+type BusinessCalendarHandler struct {
+	calendarRepo *repository.BusinessCalendarRepository
+}
+
+// BusinessCalendarRequest representa o conteúdo de um calendário de atendimento
+type BusinessCalendarRequest struct {
+	Name             string               `json:"name" validate:"required,max=100" doc:"Nome da organização ou da equipe atendida pelo calendário"`
+	IsDefault        bool                 `json:"is_default" doc:"Usado pelas regras de automação sem calendário; desmarca o padrão anterior"`
+	TimeZone         string               `json:"time_zone" doc:"Fuso horário IANA, como America/Sao_Paulo"`
+	Days             []entity.BusinessDay `json:"days" doc:"Intervalos semanais: weekday de 0 (domingo) a 6 (sábado), open e close em HH:MM"`
+	NationalHolidays *bool                `json:"national_holidays,omitempty" doc:"Fecha nos feriados nacionais do Brasil; padrão true"`
+	OptionalHolidays bool                 `json:"optional_holidays" doc:"Fecha também na segunda e na terça de Carnaval e em Corpus Christi"`
+	Holidays         []entity.Holiday     `json:"holidays" doc:"Feriados locais: date em AAAA-MM-DD, name e recurring para repetir todo ano"`
+}
+
+// NewBusinessCalendarHandler cria uma nova instância do manipulador de calendários
+func NewBusinessCalendarHandler(calendarRepo *repository.BusinessCalendarRepository) *BusinessCalendarHandler {
+	return &BusinessCalendarHandler{
+		calendarRepo: calendarRepo,
+	}
+}
+
+// List retorna os calendários da organização
+func (h *BusinessCalendarHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	calendars, err := h.calendarRepo.List(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusOK, calendars)
+}
+
+// Create grava um novo calendário
+func (h *BusinessCalendarHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	var req BusinessCalendarRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	cal := entity.NewBusinessCalendar(orgID, userID)
+	if !applyCalendarRequest(w, r, cal, req) {
+		return
+	}
+
+	if err := h.calendarRepo.Create(cal); err != nil {
+		if errors.Is(err, repository.ErrCalendarExists) {
+			calendarConflict(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusCreated, cal)
+}
+
+// Get retorna um calendário
+func (h *BusinessCalendarHandler) Get(w http.ResponseWriter, r *http.Request) {
+	cal, ok := h.loadCalendar(w, r)
+	if !ok {
+		return
+	}
+	response.JSON(w, http.StatusOK, cal)
+}
+
+// Update substitui o conteúdo de um calendário
+func (h *BusinessCalendarHandler) Update(w http.ResponseWriter, r *http.Request) {
+	cal, ok := h.loadCalendar(w, r)
+	if !ok {
+		return
+	}
+
+	var req BusinessCalendarRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if !applyCalendarRequest(w, r, cal, req) {
+		return
+	}
+
+	if err := h.calendarRepo.Update(cal); err != nil {
+		if errors.Is(err, repository.ErrCalendarExists) {
+			calendarConflict(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusOK, cal)
+}
+
+// Delete remove um calendário que não é usado por regras nem campanhas
+func (h *BusinessCalendarHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.calendarRepo.Delete(orgID, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(w, r)
+		case errors.Is(err, repository.ErrCalendarInUse):
+			response.Error(w, r, http.StatusConflict, response.CodeConflict, "O calendário é usado por regras de automação ou campanhas não encerradas")
+		default:
+			response.Internal(w, r)
+		}
+		return
+	}
+	response.NoContent(w)
+}
+
+// Status informa se o calendário está aberto no instante at (padrão agora)
+// e quando abre ou fecha
+func (h *BusinessCalendarHandler) Status(w http.ResponseWriter, r *http.Request) {
+	cal, ok := h.loadCalendar(w, r)
+	if !ok {
+		return
+	}
+	h.writeStatus(w, r, cal)
+}
+
+// DefaultStatus informa a situação do calendário padrão da organização
+func (h *BusinessCalendarHandler) DefaultStatus(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	calendars, err := h.calendarRepo.List(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	for _, cal := range calendars {
+		if cal.IsDefault {
+			h.writeStatus(w, r, cal)
+			return
+		}
+	}
+	response.Error(w, r, http.StatusNotFound, response.CodeNotFound, "A organização não tem calendário padrão")
+}
+
+// SLA calcula um relógio de SLA que corre apenas no horário do calendário:
+// o prazo, o tempo útil decorrido e se a meta foi ultrapassada
+func (h *BusinessCalendarHandler) SLA(w http.ResponseWriter, r *http.Request) {
+	cal, ok := h.loadCalendar(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	var errs []response.FieldError
+	at := time.Now()
+	if t, e := queryTime(query, "at", false); e != nil {
+		errs = append(errs, *e)
+	} else if t != nil {
+		at = *t
+	}
+	start, e := queryTime(query, "started_at", false)
+	switch {
+	case e != nil:
+		errs = append(errs, *e)
+	case start == nil:
+		errs = append(errs, response.FieldError{Field: "started_at", Code: "required", Message: "Informe o início do relógio"})
+	}
+	target, err := strconv.Atoi(query.Get("target_minutes"))
+	if err != nil || target < 1 || target > maxSLAMinutes {
+		errs = append(errs, response.FieldError{Field: "target_minutes", Code: "range", Message: fmt.Sprintf("Deve estar entre 1 e %d", maxSLAMinutes)})
+	}
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	calendar, ok := prepareCalendar(w, r, cal)
+	if !ok {
+		return
+	}
+	response.JSON(w, http.StatusOK, calendar.Clock(*start, at, time.Duration(target)*time.Minute))
+}
+
+// NationalHolidays lista os feriados nacionais do Brasil e os pontos
+// facultativos do ano (padrão o ano atual)
+func (h *BusinessCalendarHandler) NationalHolidays(w http.ResponseWriter, r *http.Request) {
+	year := time.Now().Year()
+	if v := r.URL.Query().Get("year"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1900 || n > 2200 {
+			response.ValidationError(w, r, []response.FieldError{{Field: "year", Code: "range", Message: "Deve estar entre 1900 e 2200"}})
+			return
+		}
+		year = n
+	}
+	response.JSON(w, http.StatusOK, businesshours.NationalHolidays(year))
+}
+
+func (h *BusinessCalendarHandler) writeStatus(w http.ResponseWriter, r *http.Request, cal *entity.BusinessCalendar) {
+	at := time.Now()
+	t, e := queryTime(r.URL.Query(), "at", false)
+	if e != nil {
+		response.ValidationError(w, r, []response.FieldError{*e})
+		return
+	}
+	if t != nil {
+		at = *t
+	}
+
+	calendar, ok := prepareCalendar(w, r, cal)
+	if !ok {
+		return
+	}
+	response.JSON(w, http.StatusOK, calendar.Status(at))
+}
+
+// loadCalendar busca o calendário da rota, respondendo 404 se não existir
+func (h *BusinessCalendarHandler) loadCalendar(w http.ResponseWriter, r *http.Request) (*entity.BusinessCalendar, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	cal, err := h.calendarRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+	return cal, true
+}
+
+// applyCalendarRequest copia o corpo para o calendário e valida os
+// intervalos e feriados, respondendo 422 se inválidos
+func applyCalendarRequest(w http.ResponseWriter, r *http.Request, cal *entity.BusinessCalendar, req BusinessCalendarRequest) bool {
+	cal.Name = strings.TrimSpace(req.Name)
+	cal.IsDefault = req.IsDefault
+	cal.TimeZone = strings.TrimSpace(req.TimeZone)
+	cal.Days = req.Days
+	if cal.Days == nil {
+		cal.Days = []entity.BusinessDay{}
+	}
+	if req.NationalHolidays != nil {
+		cal.NationalHolidays = *req.NationalHolidays
+	}
+	cal.OptionalHolidays = req.OptionalHolidays
+	cal.Holidays = req.Holidays
+	if cal.Holidays == nil {
+		cal.Holidays = []entity.Holiday{}
+	}
+	for i := range cal.Holidays {
+		cal.Holidays[i].Name = strings.TrimSpace(cal.Holidays[i].Name)
+		cal.Holidays[i].Optional = false
+	}
+
+	if errs := businesshours.Validate(cal); len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return false
+	}
+	return true
+}
+
+// prepareCalendar carrega o fuso do calendário para os cálculos
+func prepareCalendar(w http.ResponseWriter, r *http.Request, cal *entity.BusinessCalendar) (*businesshours.Calendar, bool) {
+	calendar, err := businesshours.New(cal)
+	if err != nil {
+		response.Internal(w, r)
+		return nil, false
+	}
+	return calendar, true
+}
+
+// loadCalendarReference confere um calendário informado no corpo da
+// requisição, respondendo 422 no campo se não existir na organização
+func loadCalendarReference(w http.ResponseWriter, r *http.Request, calendarRepo *repository.BusinessCalendarRepository, orgID, id int64, field string) bool {
+	if _, err := calendarRepo.GetByID(orgID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.ValidationError(w, r, []response.FieldError{{Field: field, Code: "not_found", Message: "Calendário não encontrado"}})
+			return false
+		}
+		response.Internal(w, r)
+		return false
+	}
+	return true
+}
+
+func calendarConflict(w http.ResponseWriter, r *http.Request) {
+	response.WriteProblem(w, r, response.NewProblem(http.StatusConflict, response.CodeConflict,
+		"Já existe um calendário com este nome").WithErrors([]response.FieldError{{
+		Field:   "name",
+		Code:    "duplicate",
+		Message: "Nome já utilizado",
+	}}))
+}
@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// CampaignHandler gerencia as campanhas de disparo de modelos
+type CampaignHandler struct {
+	campaignRepo *repository.CampaignRepository
+	templateRepo *repository.TemplateRepository
+	segmentRepo  *repository.SegmentRepository
+	leadRepo     *repository.LeadRepository
+	calendarRepo *repository.BusinessCalendarRepository
+}
+
+// CampaignRequest representa o conteúdo de uma campanha em rascunho
+type CampaignRequest struct {
+	Name        string     `json:"name" validate:"required,max=200"`
+	TemplateID  int64      `json:"template_id" validate:"required" doc:"Modelo aprovado enviado aos leads"`
+	SegmentID   int64      `json:"segment_id" validate:"required" doc:"Segmento com o público; o filtro é copiado para a campanha e avaliado no início do envio"`
+	CalendarID  *int64     `json:"calendar_id,omitempty" doc:"Calendário de atendimento; fora do horário o envio espera a próxima abertura"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty" doc:"Horário sugerido do envio em RFC 3339, confirmado no agendamento"`
+}
+
+// CampaignScheduleRequest representa o agendamento de uma campanha
+type CampaignScheduleRequest struct {
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty" doc:"Horário do envio em RFC 3339; vazio usa o horário do rascunho ou envia imediatamente"`
+}
+
+// CampaignListResponse representa uma página de campanhas
+type CampaignListResponse struct {
+	Data   []*entity.Campaign `json:"data"`
+	Total  int                `json:"total"`
+	Limit  int                `json:"limit"`
+	Offset int                `json:"offset"`
+}
+
+// CampaignRecipientListResponse representa uma página de destinatários
+type CampaignRecipientListResponse struct {
+	Data   []*entity.CampaignRecipient `json:"data"`
+	Total  int                         `json:"total"`
+	Limit  int                         `json:"limit"`
+	Offset int                         `json:"offset"`
+}
+
+// NewCampaignHandler cria uma nova instância do manipulador de campanhas
+func NewCampaignHandler(campaignRepo *repository.CampaignRepository, templateRepo *repository.TemplateRepository, segmentRepo *repository.SegmentRepository, leadRepo *repository.LeadRepository, calendarRepo *repository.BusinessCalendarRepository) *CampaignHandler {
+	return &CampaignHandler{
+		campaignRepo: campaignRepo,
+		templateRepo: templateRepo,
+		segmentRepo:  segmentRepo,
+		leadRepo:     leadRepo,
+		calendarRepo: calendarRepo,
+	}
+}
+
+// List retorna as campanhas da organização, filtradas por status
+func (h *CampaignHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, offset, errs := parsePagination(query)
+	status := query.Get("status")
+	if status != "" && !containsString(entity.CampaignStatuses, status) {
+		errs = append(errs, response.FieldError{Field: "status", Code: "oneof", Message: "Use um dos valores: " + strings.Join(entity.CampaignStatuses, ", ")})
+	}
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	list, total, err := h.campaignRepo.List(orgID, status, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, CampaignListResponse{
+		Data:   list,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// Create grava uma nova campanha em rascunho
+func (h *CampaignHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	var req CampaignRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	c := entity.NewCampaign(orgID, userID)
+	if !h.applyRequest(w, r, c, req) {
+		return
+	}
+
+	if err := h.campaignRepo.Create(c); err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, c)
+}
+
+// Get retorna uma campanha
+func (h *CampaignHandler) Get(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.loadCampaign(w, r)
+	if !ok {
+		return
+	}
+	response.JSON(w, http.StatusOK, c)
+}
+
+// Update altera uma campanha em rascunho
+func (h *CampaignHandler) Update(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.loadCampaign(w, r)
+	if !ok {
+		return
+	}
+
+	var req CampaignRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if c.Status != entity.CampaignStatusDraft {
+		campaignStateConflict(w, r)
+		return
+	}
+	if !h.applyRequest(w, r, c, req) {
+		return
+	}
+
+	if err := h.campaignRepo.Update(c); err != nil {
+		if errors.Is(err, repository.ErrCampaignState) {
+			campaignStateConflict(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, c)
+}
+
+// Delete remove uma campanha em rascunho
+func (h *CampaignHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.loadCampaign(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.campaignRepo.Delete(c.OrganizationID, c.ID); err != nil {
+		if errors.Is(err, repository.ErrCampaignState) {
+			campaignStateConflict(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Schedule agenda o envio de um rascunho. O modelo precisa estar aprovado e
+// o segmento precisa ter leads.
+func (h *CampaignHandler) Schedule(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.loadCampaign(w, r)
+	if !ok {
+		return
+	}
+
+	var req CampaignScheduleRequest
+	if r.ContentLength != 0 && !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if c.Status != entity.CampaignStatusDraft {
+		campaignStateConflict(w, r)
+		return
+	}
+	if _, ok := h.approvedTemplate(w, r, c.OrganizationID, c.TemplateID); !ok {
+		return
+	}
+
+	audience, err := h.leadRepo.Count(c.OrganizationID, c.Filter)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	if audience == 0 {
+		response.ValidationError(w, r, []response.FieldError{{Field: "segment_id", Code: "empty", Message: "O segmento não tem leads"}})
+		return
+	}
+
+	at := time.Now().UTC()
+	if req.ScheduledAt != nil {
+		at = req.ScheduledAt.UTC()
+	} else if c.ScheduledAt != nil {
+		at = c.ScheduledAt.UTC()
+	}
+
+	scheduled, err := h.campaignRepo.Schedule(c.OrganizationID, c.ID, at)
+	if err != nil {
+		h.transitionError(w, r, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, scheduled)
+}
+
+// Pause interrompe o envio de uma campanha agendada ou em andamento
+func (h *CampaignHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.campaignRepo.Pause)
+}
+
+// Resume retoma uma campanha pausada
+func (h *CampaignHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.campaignRepo.Resume)
+}
+
+// Cancel encerra a campanha sem enviar aos destinatários restantes
+func (h *CampaignHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.campaignRepo.Cancel)
+}
+
+// Recipients retorna os destinatários da campanha com o andamento do envio
+func (h *CampaignHandler) Recipients(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.loadCampaign(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, offset, errs := parsePagination(query)
+	status := query.Get("status")
+	if status != "" && !containsString(entity.RecipientStatuses, status) {
+		errs = append(errs, response.FieldError{Field: "status", Code: "oneof", Message: "Use um dos valores: " + strings.Join(entity.RecipientStatuses, ", ")})
+	}
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	list, total, err := h.campaignRepo.ListRecipients(c.OrganizationID, c.ID, status, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, CampaignRecipientListResponse{
+		Data:   list,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// Report resume os envios, entregas, leituras, respostas e falhas da campanha
+func (h *CampaignHandler) Report(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.loadCampaign(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.campaignRepo.Report(c.OrganizationID, c.ID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusOK, report)
+}
+
+// applyRequest copia a requisição para a campanha, conferindo o modelo, o
+// segmento e o calendário, e responde 422 se houver problemas
+func (h *CampaignHandler) applyRequest(w http.ResponseWriter, r *http.Request, c *entity.Campaign, req CampaignRequest) bool {
+	if _, ok := h.approvedTemplate(w, r, c.OrganizationID, req.TemplateID); !ok {
+		return false
+	}
+	segment, ok := loadSegmentReference(w, r, h.segmentRepo, c.OrganizationID, req.SegmentID)
+	if !ok {
+		return false
+	}
+	if req.CalendarID != nil && !loadCalendarReference(w, r, h.calendarRepo, c.OrganizationID, *req.CalendarID, "calendar_id") {
+		return false
+	}
+
+	c.Name = strings.TrimSpace(req.Name)
+	c.TemplateID = req.TemplateID
+	c.SegmentID = &segment.ID
+	c.Filter = segment.Filter
+	c.CalendarID = req.CalendarID
+	c.ScheduledAt = nil
+	if req.ScheduledAt != nil {
+		at := req.ScheduledAt.UTC()
+		c.ScheduledAt = &at
+	}
+	return true
+}
+
+// approvedTemplate busca o modelo da campanha, respondendo 422 se ele não
+// existir ou não estiver aprovado
+func (h *CampaignHandler) approvedTemplate(w http.ResponseWriter, r *http.Request, orgID, id int64) (*entity.MessageTemplate, bool) {
+	t, err := h.templateRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.ValidationError(w, r, []response.FieldError{{Field: "template_id", Code: "not_found", Message: "Modelo não encontrado"}})
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+	if t.Status != entity.TemplateStatusApproved {
+		response.ValidationError(w, r, []response.FieldError{{Field: "template_id", Code: "not_approved", Message: "O modelo não está aprovado"}})
+		return nil, false
+	}
+	return t, true
+}
+
+func (h *CampaignHandler) transition(w http.ResponseWriter, r *http.Request, apply func(orgID, id int64) (*entity.Campaign, error)) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	c, err := apply(orgID, id)
+	if err != nil {
+		h.transitionError(w, r, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, c)
+}
+
+func (h *CampaignHandler) transitionError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		response.NotFound(w, r)
+	case errors.Is(err, repository.ErrCampaignState):
+		campaignStateConflict(w, r)
+	default:
+		response.Internal(w, r)
+	}
+}
+
+// loadCampaign busca a campanha da rota, respondendo 404 se não existir
+func (h *CampaignHandler) loadCampaign(w http.ResponseWriter, r *http.Request) (*entity.Campaign, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	c, err := h.campaignRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+	return c, true
+}
+
+func campaignStateConflict(w http.ResponseWriter, r *http.Request) {
+	response.Error(w, r, http.StatusConflict, response.CodeConflict, "Operação não permitida no status atual da campanha")
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/chatbot"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// ChatbotHandler gerencia os fluxos do chatbot e as sessões nas conversas
+type ChatbotHandler struct {
+	chatbotRepo      *repository.ChatbotRepository
+	fieldRepo        *repository.CustomFieldRepository
+	userRepo         *repository.UserRepository
+	conversationRepo *repository.ConversationRepository
+	chatbot          *chatbot.Service
+}
+
+// ChatbotFlowRequest representa o conteúdo de um fluxo do chatbot
+type ChatbotFlowRequest struct {
+	Name           string            `json:"name" validate:"required,max=200"`
+	Enabled        *bool             `json:"enabled,omitempty" doc:"Padrão true"`
+	StartNode      string            `json:"start_node" doc:"ID do nó inicial"`
+	Nodes          []entity.FlowNode `json:"nodes" doc:"Nós do fluxo: send (text, next), buttons (text, options), list (text, list_button, options), capture (text, field, format), branch (branches, next) ou handoff (text, user_id ou skill)"`
+	TimeoutMinutes *int              `json:"timeout_minutes,omitempty" doc:"Prazo para o lead responder a cada pergunta; padrão 60"`
+	FallbackNode   string            `json:"fallback_node" doc:"Nó executado quando o prazo acaba ou a resposta não é aceita 3 vezes; vazio encerra o fluxo"`
+}
+
+// FlowStartRequest representa o início manual de um fluxo em uma conversa
+type FlowStartRequest struct {
+	FlowID int64 `json:"flow_id" validate:"required"`
+}
+
+// FlowSessionListResponse representa uma página de sessões de um fluxo
+type FlowSessionListResponse struct {
+	Data   []*entity.FlowSession `json:"data"`
+	Total  int                   `json:"total"`
+	Limit  int                   `json:"limit"`
+	Offset int                   `json:"offset"`
+}
+
+// NewChatbotHandler cria uma nova instância do manipulador do chatbot
+func NewChatbotHandler(chatbotRepo *repository.ChatbotRepository, fieldRepo *repository.CustomFieldRepository, userRepo *repository.UserRepository, conversationRepo *repository.ConversationRepository, chatbotService *chatbot.Service) *ChatbotHandler {
+	return &ChatbotHandler{
+		chatbotRepo:      chatbotRepo,
+		fieldRepo:        fieldRepo,
+		userRepo:         userRepo,
+		conversationRepo: conversationRepo,
+		chatbot:          chatbotService,
+	}
+}
+
+// List retorna os fluxos da organização
+func (h *ChatbotHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	flows, err := h.chatbotRepo.List(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusOK, flows)
+}
+
+// Create grava um novo fluxo
+func (h *ChatbotHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	var req ChatbotFlowRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	flow := entity.NewChatbotFlow(orgID, userID)
+	if !h.applyRequest(w, r, flow, req) {
+		return
+	}
+
+	if err := h.chatbotRepo.Create(flow); err != nil {
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusCreated, flow)
+}
+
+// Get retorna um fluxo
+func (h *ChatbotHandler) Get(w http.ResponseWriter, r *http.Request) {
+	flow, ok := h.loadFlow(w, r)
+	if !ok {
+		return
+	}
+	response.JSON(w, http.StatusOK, flow)
+}
+
+// Update substitui o conteúdo de um fluxo
+func (h *ChatbotHandler) Update(w http.ResponseWriter, r *http.Request) {
+	flow, ok := h.loadFlow(w, r)
+	if !ok {
+		return
+	}
+
+	var req ChatbotFlowRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if !h.applyRequest(w, r, flow, req) {
+		return
+	}
+
+	if err := h.chatbotRepo.Update(flow); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusOK, flow)
+}
+
+// Delete remove um fluxo e as suas sessões
+func (h *ChatbotHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.chatbotRepo.Delete(orgID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	response.NoContent(w)
+}
+
+// Sessions retorna as sessões do fluxo, filtradas por status
+func (h *ChatbotHandler) Sessions(w http.ResponseWriter, r *http.Request) {
+	flow, ok := h.loadFlow(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, offset, errs := parsePagination(query)
+	status := query.Get("status")
+	if status != "" && !containsString(entity.FlowSessionStatuses, status) {
+		errs = append(errs, response.FieldError{Field: "status", Code: "oneof", Message: "Use um dos valores: " + strings.Join(entity.FlowSessionStatuses, ", ")})
+	}
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	sessions, total, err := h.chatbotRepo.ListSessions(flow.OrganizationID, flow.ID, status, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, FlowSessionListResponse{
+		Data:   sessions,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// ConversationSession retorna a sessão ativa da conversa
+func (h *ChatbotHandler) ConversationSession(w http.ResponseWriter, r *http.Request) {
+	conversation, ok := h.loadConversation(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := h.chatbotRepo.ActiveSession(conversation.OrganizationID, conversation.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, r, http.StatusNotFound, response.CodeNotFound, "A conversa não está em um fluxo do chatbot")
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusOK, session)
+}
+
+// StartSession inicia um fluxo na conversa, executando os nós até a
+// primeira pergunta
+func (h *ChatbotHandler) StartSession(w http.ResponseWriter, r *http.Request) {
+	conversation, ok := h.loadConversation(w, r)
+	if !ok {
+		return
+	}
+
+	var req FlowStartRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if conversation.Status != entity.ConversationStatusOpen {
+		response.Error(w, r, http.StatusConflict, response.CodeConflict, "A conversa está encerrada")
+		return
+	}
+
+	session, err := h.chatbot.Start(conversation.OrganizationID, req.FlowID, conversation.ID, conversation.LeadID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.ValidationError(w, r, []response.FieldError{{Field: "flow_id", Code: "not_found", Message: "Fluxo do chatbot não encontrado"}})
+		case errors.Is(err, chatbot.ErrFlowDisabled):
+			response.ValidationError(w, r, []response.FieldError{{Field: "flow_id", Code: "disabled", Message: "O fluxo está desativado"}})
+		case errors.Is(err, chatbot.ErrSessionActive):
+			response.Error(w, r, http.StatusConflict, response.CodeConflict, "A conversa já está em um fluxo do chatbot")
+		default:
+			response.Internal(w, r)
+		}
+		return
+	}
+	response.JSON(w, http.StatusCreated, session)
+}
+
+// CancelSession encerra o fluxo em andamento na conversa, para que um
+// atendente assuma o atendimento
+func (h *ChatbotHandler) CancelSession(w http.ResponseWriter, r *http.Request) {
+	conversation, ok := h.loadConversation(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.chatbot.Cancel(conversation.OrganizationID, conversation.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, r, http.StatusNotFound, response.CodeNotFound, "A conversa não está em um fluxo do chatbot")
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	response.NoContent(w)
+}
+
+// applyRequest copia o corpo para o fluxo e valida os nós e as referências,
+// respondendo 422 se inválidos
+func (h *ChatbotHandler) applyRequest(w http.ResponseWriter, r *http.Request, flow *entity.ChatbotFlow, req ChatbotFlowRequest) bool {
+	flow.Name = strings.TrimSpace(req.Name)
+	if req.Enabled != nil {
+		flow.Enabled = *req.Enabled
+	}
+	flow.StartNode = req.StartNode
+	flow.Nodes = req.Nodes
+	if flow.Nodes == nil {
+		flow.Nodes = []entity.FlowNode{}
+	}
+	if req.TimeoutMinutes != nil {
+		flow.TimeoutMinutes = *req.TimeoutMinutes
+	}
+	flow.FallbackNode = req.FallbackNode
+
+	schema, ok := loadCustomFieldSchema(w, r, h.fieldRepo, flow.OrganizationID)
+	if !ok {
+		return false
+	}
+	errs := chatbot.Validate(flow, schema)
+	for i, n := range flow.Nodes {
+		if n.Type != entity.FlowNodeHandoff || n.UserID <= 0 {
+			continue
+		}
+		user, err := h.userRepo.GetByID(n.UserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			response.Internal(w, r)
+			return false
+		}
+		if user == nil || user.OrganizationID != flow.OrganizationID {
+			errs = append(errs, response.FieldError{Field: fmt.Sprintf("nodes[%d].user_id", i), Code: "not_found", Message: "Usuário não encontrado na organização"})
+		}
+	}
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return false
+	}
+	return true
+}
+
+// loadFlow busca o fluxo da rota, respondendo 404 se não existir
+func (h *ChatbotHandler) loadFlow(w http.ResponseWriter, r *http.Request) (*entity.ChatbotFlow, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	flow, err := h.chatbotRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+	return flow, true
+}
+
+// loadConversation busca a conversa da rota, respondendo 404 se não existir
+func (h *ChatbotHandler) loadConversation(w http.ResponseWriter, r *http.Request) (*entity.Conversation, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	conversation, err := h.conversationRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+	return conversation, true
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// ConsentHandler gerencia o consentimento dos leads por canal
+type ConsentHandler struct {
+	consentRepo *repository.ConsentRepository
+	leadRepo    *repository.LeadRepository
+}
+
+// ConsentRequest representa uma alteração de consentimento informada pela equipe
+type ConsentRequest struct {
+	Channel  string `json:"channel" validate:"required,oneof=whatsapp email sms"`
+	Status   string `json:"status" validate:"required,oneof=opted_in opted_out"`
+	Source   string `json:"source" validate:"required,oneof=manual form import api" doc:"inbound_keyword é reservada aos descadastros por mensagem recebida"`
+	Evidence string `json:"evidence" validate:"max=2000" doc:"Prova do consentimento, como o formulário, a página ou o arquivo de origem"`
+}
+
+// ConsentListResponse representa a situação atual do lead em cada canal e
+// uma página do histórico de alterações
+type ConsentListResponse struct {
+	Current []*entity.Consent `json:"current"`
+	Data    []*entity.Consent `json:"data"`
+	Total   int               `json:"total"`
+	Limit   int               `json:"limit"`
+	Offset  int               `json:"offset"`
+}
+
+// NewConsentHandler cria uma nova instância do manipulador de consentimento
+func NewConsentHandler(consentRepo *repository.ConsentRepository, leadRepo *repository.LeadRepository) *ConsentHandler {
+	return &ConsentHandler{
+		consentRepo: consentRepo,
+		leadRepo:    leadRepo,
+	}
+}
+
+// List retorna a situação atual do consentimento do lead e o histórico,
+// das alterações mais recentes para as mais antigas
+func (h *ConsentHandler) List(w http.ResponseWriter, r *http.Request) {
+	lead, ok := loadRouteLead(w, r, h.leadRepo)
+	if !ok {
+		return
+	}
+
+	limit, offset, errs := parsePagination(r.URL.Query())
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	current, err := h.consentRepo.Current(lead.OrganizationID, lead.ID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	consents, total, err := h.consentRepo.ListByLead(lead.OrganizationID, lead.ID, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, ConsentListResponse{
+		Current: current,
+		Data:    consents,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
+
+// Record registra a concessão ou a retirada do consentimento do lead em um canal
+func (h *ConsentHandler) Record(w http.ResponseWriter, r *http.Request) {
+	lead, ok := loadRouteLead(w, r, h.leadRepo)
+	if !ok {
+		return
+	}
+
+	var req ConsentRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	consent := entity.NewConsent(lead.OrganizationID, lead.ID, userID, req.Channel, req.Status, req.Source)
+	consent.Evidence = strings.TrimSpace(req.Evidence)
+
+	if err := h.consentRepo.Record(consent); err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, consent)
+}
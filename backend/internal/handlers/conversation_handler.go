@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/media"
+	"github.com/whatsapp/backend/internal/messaging"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+	"github.com/whatsapp/backend/internal/whatsapp"
+)
+
+const (
+	// maxMessageLength é o limite de caracteres de uma mensagem de texto do WhatsApp
+	maxMessageLength = 4096
+	// maxCaptionLength é o limite de caracteres da legenda de uma mídia
+	maxCaptionLength = 1024
+	// mediaMemory é a parte do arquivo enviado mantida em memória; o
+	// restante é gravado em disco temporário
+	mediaMemory = 8 << 20
+)
+
+// ConversationHandler gerencia as conversas do WhatsApp e o envio de mensagens
+type ConversationHandler struct {
+	conversationRepo *repository.ConversationRepository
+	messaging        *messaging.Service
+	media            *media.Service
+}
+
+// SendMessageRequest representa uma mensagem enviada por um atendente
+type SendMessageRequest struct {
+	Type       string `json:"type" validate:"required,oneof=text template" doc:"text só é aceito com a janela de atendimento aberta; fora dela envie um modelo aprovado"`
+	Body       string `json:"body" validate:"max=4096" doc:"Texto da mensagem, obrigatório para type=text"`
+	TemplateID int64  `json:"template_id" doc:"Modelo aprovado, obrigatório para type=template"`
+}
+
+// Validate verifica o conteúdo exigido por cada tipo de mensagem
+func (req SendMessageRequest) Validate() []response.FieldError {
+	switch req.Type {
+	case "text":
+		if strings.TrimSpace(req.Body) == "" {
+			return []response.FieldError{{Field: "body", Code: "required", Message: "Informe o texto da mensagem"}}
+		}
+	case "template":
+		if req.TemplateID <= 0 {
+			return []response.FieldError{{Field: "template_id", Code: "required", Message: "Informe o modelo da mensagem"}}
+		}
+	}
+	return nil
+}
+
+// SendMediaForm documenta o formulário de envio de mídia
+type SendMediaForm struct {
+	File    []byte `json:"file" validate:"required" doc:"Arquivo da mídia. O tipo de mensagem (image, audio, video, document ou sticker) é definido pelo tipo MIME, com os limites de tamanho do WhatsApp"`
+	Caption string `json:"caption" validate:"max=1024" doc:"Legenda, ignorada em áudios e figurinhas"`
+}
+
+// ConversationListResponse representa uma página de conversas
+type ConversationListResponse struct {
+	Data   []*entity.Conversation `json:"data"`
+	Total  int                    `json:"total"`
+	Limit  int                    `json:"limit"`
+	Offset int                    `json:"offset"`
+}
+
+// MessageListResponse representa uma página de mensagens, das mais recentes
+// para as mais antigas
+type MessageListResponse struct {
+	Data   []*entity.Message `json:"data"`
+	Total  int               `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
+// NewConversationHandler cria uma nova instância do manipulador de conversas
+func NewConversationHandler(conversationRepo *repository.ConversationRepository, messaging *messaging.Service, media *media.Service) *ConversationHandler {
+	return &ConversationHandler{
+		conversationRepo: conversationRepo,
+		messaging:        messaging,
+		media:            media,
+	}
+}
+
+// List retorna as conversas da organização, filtradas por status e responsável
+func (h *ConversationHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, offset, errs := parsePagination(query)
+
+	var filter repository.ConversationQuery
+	if status := query.Get("status"); status != "" {
+		if status != entity.ConversationStatusOpen && status != entity.ConversationStatusClosed {
+			errs = append(errs, response.FieldError{Field: "status", Code: "oneof", Message: "Use um dos valores: open, closed"})
+		}
+		filter.Status = status
+	}
+	if assigned := query.Get("assigned_user_id"); assigned != "" {
+		if assigned == "me" {
+			if userID, ok := auth.GetUserID(r.Context()); ok {
+				filter.AssignedUserID = &userID
+			}
+		} else if id, err := strconv.ParseInt(assigned, 10, 64); err == nil && id > 0 {
+			filter.AssignedUserID = &id
+		} else {
+			errs = append(errs, response.FieldError{Field: "assigned_user_id", Code: "invalid", Message: "Informe o ID do atendente ou me"})
+		}
+	}
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	list, total, err := h.conversationRepo.List(orgID, filter, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, ConversationListResponse{
+		Data:   list,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// Get retorna a conversa com o prazo da janela de atendimento
+func (h *ConversationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	conversation, ok := h.loadConversation(w, r)
+	if !ok {
+		return
+	}
+	response.JSON(w, http.StatusOK, conversation)
+}
+
+// ListMessages retorna as mensagens da conversa
+func (h *ConversationHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	conversation, ok := h.loadConversation(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset, errs := parsePagination(r.URL.Query())
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	list, total, err := h.conversationRepo.ListMessages(conversation.OrganizationID, conversation.ID, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	h.media.AttachURLs(list...)
+
+	response.JSON(w, http.StatusOK, MessageListResponse{
+		Data:   list,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// SendMessage envia uma mensagem ao lead da conversa. Texto livre fora da
+// janela de atendimento é recusado com 409 window_closed.
+func (h *ConversationHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	var req SendMessageRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	send := messaging.SendRequest{OrganizationID: orgID, ConversationID: id, UserID: userID}
+	if req.Type == "template" {
+		send.TemplateID = req.TemplateID
+	} else {
+		send.Text = req.Body
+	}
+
+	message, err := h.messaging.Send(send)
+	if err != nil {
+		writeSendError(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, message)
+}
+
+// SendMedia envia um arquivo ao lead da conversa, recebido em multipart no
+// campo file. O tipo MIME informado na parte do arquivo define o tipo da
+// mensagem; sem ele, o tipo é detectado pelo conteúdo.
+func (h *ConversationHandler) SendMedia(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, whatsapp.MaxMediaSize+(1<<20))
+	if err := r.ParseMultipartForm(mediaMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(w, r, http.StatusRequestEntityTooLarge, response.CodeBodyTooLarge,
+				fmt.Sprintf("O arquivo deve ter no máximo %d MB", whatsapp.MaxMediaSize>>20))
+			return
+		}
+		response.InvalidBody(w, r)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		response.ValidationError(w, r, []response.FieldError{{Field: "file", Code: "required", Message: "Envie o arquivo no campo file"}})
+		return
+	}
+	defer file.Close()
+
+	caption := strings.TrimSpace(r.FormValue("caption"))
+	if len([]rune(caption)) > maxCaptionLength {
+		response.ValidationError(w, r, []response.FieldError{{Field: "caption", Code: "max",
+			Message: fmt.Sprintf("A legenda deve ter no máximo %d caracteres", maxCaptionLength)}})
+		return
+	}
+
+	mimeType := header.Header.Get("Content-Type")
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		if mimeType, err = detectContentType(file); err != nil {
+			response.InvalidBody(w, r)
+			return
+		}
+	}
+
+	message, err := h.messaging.SendMedia(messaging.MediaRequest{
+		OrganizationID: orgID,
+		ConversationID: id,
+		UserID:         userID,
+		File:           file,
+		FileName:       header.Filename,
+		MIMEType:       mimeType,
+		Size:           header.Size,
+		Caption:        caption,
+	})
+	if err != nil {
+		var tooLarge *whatsapp.MediaTooLargeError
+		switch {
+		case errors.Is(err, whatsapp.ErrUnsupportedMedia):
+			response.ValidationError(w, r, []response.FieldError{{Field: "file", Code: "unsupported_type",
+				Message: fmt.Sprintf("Tipo de arquivo %s não aceito pelo WhatsApp; use um de: %s", mimeType, strings.Join(whatsapp.SupportedMediaTypes(), ", "))}})
+		case errors.As(err, &tooLarge):
+			response.ValidationError(w, r, []response.FieldError{{Field: "file", Code: "too_large", Message: "O arquivo excede o limite: " + tooLarge.Error()}})
+		default:
+			writeSendError(w, r, err)
+		}
+		return
+	}
+
+	h.media.AttachURLs(message)
+	response.JSON(w, http.StatusCreated, message)
+}
+
+// detectContentType identifica o tipo MIME pelo início do arquivo e volta
+// a leitura ao início
+func detectContentType(file io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// writeSendError responde a falha de um envio de mensagem na conversa
+func writeSendError(w http.ResponseWriter, r *http.Request, err error) {
+	var missing *messaging.MissingFieldsError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		response.NotFound(w, r)
+	case errors.Is(err, messaging.ErrWindowClosed):
+		response.Error(w, r, http.StatusConflict, response.CodeWindowClosed,
+			"A janela de atendimento de 24 horas está fechada; envie um modelo aprovado para retomar a conversa")
+	case errors.Is(err, messaging.ErrOptedOut):
+		response.Error(w, r, http.StatusConflict, response.CodeOptedOut,
+			"O lead retirou o consentimento para mensagens no WhatsApp; modelos não podem ser enviados")
+	case errors.Is(err, messaging.ErrConversationClosed):
+		response.Error(w, r, http.StatusConflict, response.CodeConflict, "A conversa está encerrada")
+	case errors.Is(err, messaging.ErrTemplateNotApproved):
+		response.ValidationError(w, r, []response.FieldError{{Field: "template_id", Code: "not_approved", Message: "O modelo não está aprovado"}})
+	case errors.As(err, &missing):
+		response.ValidationError(w, r, []response.FieldError{{Field: "template_id", Code: "missing_fields",
+			Message: "O lead não tem valor para os campos: " + strings.Join(missing.Fields, ", ")}})
+	case errors.Is(err, messaging.ErrInvalidRecipient):
+		response.Error(w, r, http.StatusConflict, response.CodeConflict, "O telefone do lead não é válido para o WhatsApp")
+	case errors.Is(err, messaging.ErrNoPhoneNumber):
+		response.Error(w, r, http.StatusServiceUnavailable, response.CodeProviderDisabled, "Organização sem número do WhatsApp vinculado")
+	default:
+		providerError(w, r, err)
+	}
+}
+
+// loadConversation busca a conversa da rota, respondendo 404 se não existir
+func (h *ConversationHandler) loadConversation(w http.ResponseWriter, r *http.Request) (*entity.Conversation, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	conversation, err := h.conversationRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+	return conversation, true
+}
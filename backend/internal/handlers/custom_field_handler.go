@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// maxCustomFields limita as definições de campos personalizados por organização
+const maxCustomFields = 100
+
+// CustomFieldHandler gerencia as rotas de definição de campos personalizados
+type CustomFieldHandler struct {
+	fieldRepo *repository.CustomFieldRepository
+}
+
+// CreateCustomFieldRequest representa os dados para definir um campo personalizado
+type CreateCustomFieldRequest struct {
+	Key      string   `json:"key" validate:"required,max=50" doc:"Letras minúsculas, números e _; não pode ser alterada"`
+	Label    string   `json:"label" validate:"required,max=100"`
+	Type     string   `json:"type" validate:"required,oneof=text number date select multi_select boolean" doc:"Não pode ser alterado"`
+	Options  []string `json:"options,omitempty" validate:"max=100" doc:"Obrigatório em select e multi_select"`
+	Required bool     `json:"required,omitempty"`
+	Position int      `json:"position,omitempty"`
+}
+
+// Validate verifica a chave e as opções conforme o tipo
+func (req CreateCustomFieldRequest) Validate() []response.FieldError {
+	var errs []response.FieldError
+	if req.Key != "" && !entity.IsValidCustomFieldKey(req.Key) {
+		errs = append(errs, response.FieldError{
+			Field:   "key",
+			Code:    "invalid_key",
+			Message: "Use letras minúsculas, números e _, começando por uma letra",
+		})
+	}
+	return append(errs, validateFieldOptions(req.Type, req.Options)...)
+}
+
+// UpdateCustomFieldRequest representa os atributos alteráveis de um campo personalizado
+type UpdateCustomFieldRequest struct {
+	Label    string   `json:"label" validate:"required,max=100"`
+	Options  []string `json:"options,omitempty" validate:"max=100" doc:"Obrigatório em select e multi_select; valores já gravados fora da lista são mantidos"`
+	Required bool     `json:"required,omitempty"`
+	Position int      `json:"position,omitempty"`
+}
+
+// CustomFieldListResponse lista as definições da organização
+type CustomFieldListResponse struct {
+	Data []*entity.CustomFieldDefinition `json:"data"`
+}
+
+// NewCustomFieldHandler cria uma nova instância do manipulador de campos personalizados
+func NewCustomFieldHandler(fieldRepo *repository.CustomFieldRepository) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		fieldRepo: fieldRepo,
+	}
+}
+
+// List retorna as definições da organização na ordem de exibição
+func (h *CustomFieldHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	defs, err := h.fieldRepo.ListByOrganization(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, CustomFieldListResponse{Data: defs})
+}
+
+// Create define um novo campo personalizado
+func (h *CustomFieldHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateCustomFieldRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	defs, err := h.fieldRepo.ListByOrganization(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	if len(defs) >= maxCustomFields {
+		response.Error(w, r, http.StatusUnprocessableEntity, response.CodeValidationFailed,
+			fmt.Sprintf("A organização pode definir no máximo %d campos personalizados", maxCustomFields))
+		return
+	}
+
+	def := entity.NewCustomFieldDefinition(orgID, req.Key, strings.TrimSpace(req.Label), req.Type)
+	def.Options = trimOptions(req.Options)
+	def.Required = req.Required
+	def.Position = req.Position
+
+	if err := h.fieldRepo.Create(def); err != nil {
+		if errors.Is(err, repository.ErrCustomFieldKeyExists) {
+			response.WriteProblem(w, r, response.NewProblem(http.StatusConflict, response.CodeConflict,
+				"Já existe um campo personalizado com esta chave").WithErrors([]response.FieldError{{
+				Field:   "key",
+				Code:    "duplicate",
+				Message: "Chave já utilizada",
+			}}))
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/leads/custom-fields/%d", def.ID))
+	response.JSON(w, http.StatusCreated, def)
+}
+
+// Update altera o rótulo, as opções, a obrigatoriedade e a posição do campo
+func (h *CustomFieldHandler) Update(w http.ResponseWriter, r *http.Request) {
+	def, ok := h.loadDefinition(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateCustomFieldRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if errs := validateFieldOptions(def.Type, req.Options); len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	def.Label = strings.TrimSpace(req.Label)
+	def.Options = trimOptions(req.Options)
+	def.Required = req.Required
+	def.Position = req.Position
+
+	if err := h.fieldRepo.Update(def); err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, def)
+}
+
+// Delete remove a definição, mantendo os valores já gravados nos leads
+func (h *CustomFieldHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	def, ok := h.loadDefinition(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.fieldRepo.Delete(def.OrganizationID, def.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// loadDefinition busca a definição da rota na organização do usuário, respondendo 404 se não existir
+func (h *CustomFieldHandler) loadDefinition(w http.ResponseWriter, r *http.Request) (*entity.CustomFieldDefinition, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	def, err := h.fieldRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+
+	return def, true
+}
+
+// validateFieldOptions exige opções únicas e não vazias nos campos de
+// seleção e recusa opções nos demais tipos
+func validateFieldOptions(fieldType string, options []string) []response.FieldError {
+	if fieldType != entity.CustomFieldSelect && fieldType != entity.CustomFieldMultiSelect {
+		if len(options) > 0 {
+			return []response.FieldError{{Field: "options", Code: "not_allowed", Message: "Opções só se aplicam a select e multi_select"}}
+		}
+		return nil
+	}
+
+	if len(options) == 0 {
+		return []response.FieldError{{Field: "options", Code: "required", Message: "Informe ao menos uma opção"}}
+	}
+	seen := make(map[string]bool, len(options))
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		switch {
+		case option == "":
+			return []response.FieldError{{Field: "options", Code: "invalid", Message: "As opções não podem ser vazias"}}
+		case utf8.RuneCountInString(option) > customfields.MaxOptionLength:
+			return []response.FieldError{{Field: "options", Code: "max", Message: fmt.Sprintf("Cada opção deve ter no máximo %d caracteres", customfields.MaxOptionLength)}}
+		case seen[strings.ToLower(option)]:
+			return []response.FieldError{{Field: "options", Code: "duplicate", Message: fmt.Sprintf("Opção %q repetida", option)}}
+		}
+		seen[strings.ToLower(option)] = true
+	}
+	return nil
+}
+
+func trimOptions(options []string) []string {
+	trimmed := make([]string, 0, len(options))
+	for _, option := range options {
+		trimmed = append(trimmed, strings.TrimSpace(option))
+	}
+	return trimmed
+}
+
+// loadCustomFieldSchema carrega as definições de campos personalizados da
+// organização, respondendo 500 em caso de falha
+func loadCustomFieldSchema(w http.ResponseWriter, r *http.Request, fieldRepo *repository.CustomFieldRepository, orgID int64) (customfields.Schema, bool) {
+	defs, err := fieldRepo.ListByOrganization(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return nil, false
+	}
+	return customfields.NewSchema(defs), true
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+
+	swaggerFiles "github.com/swaggo/files/v2"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/openapi"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// swaggerInitializer aponta a Swagger UI embutida para a especificação da API
+const swaggerInitializer = `window.onload = function () {
+  window.ui = SwaggerUIBundle({
+    url: "/api/openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    persistAuthorization: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// DocsHandler serve a especificação OpenAPI e a Swagger UI
+type DocsHandler struct {
+	spec   []byte
+	assets http.Handler
+}
+
+// NewDocsHandler cria uma nova instância do manipulador de documentação
+func NewDocsHandler(doc *openapi.Document) (*DocsHandler, error) {
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DocsHandler{
+		spec:   spec,
+		assets: http.StripPrefix("/api/docs/", http.FileServer(http.FS(swaggerFiles.FS))),
+	}, nil
+}
+
+// Spec retorna o documento OpenAPI em JSON
+func (h *DocsHandler) Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(h.spec); err != nil {
+		logger.Error("Erro ao enviar especificação OpenAPI", err)
+	}
+}
+
+// RedirectUI redireciona /api/docs para a página da Swagger UI
+func (h *DocsHandler) RedirectUI(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, "/api/docs/", http.StatusMovedPermanently)
+}
+
+// UI serve os arquivos estáticos da Swagger UI embutidos no binário
+func (h *DocsHandler) UI(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/docs/swagger-initializer.js" {
+		w.Header().Set("Content-Type", "application/javascript")
+		w.Write([]byte(swaggerInitializer))
+		return
+	}
+
+	if _, err := fs.Stat(swaggerFiles.FS, r.URL.Path[len("/api/docs/"):]); err != nil && r.URL.Path != "/api/docs/" {
+		response.NotFound(w, r)
+		return
+	}
+
+	h.assets.ServeHTTP(w, r)
+}
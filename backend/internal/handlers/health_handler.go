@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/whatsapp/backend/internal/health"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// HealthHandler expõe as rotas de liveness e readiness
+type HealthHandler struct {
+	checker   *health.Checker
+	startedAt time.Time
+
+	// lastStatus é o status da última verificação de readiness, para que o
+	// log registre apenas as mudanças e não cada sonda do orquestrador
+	mu         sync.Mutex
+	lastStatus string
+}
+
+// LivenessResponse representa a resposta da rota de liveness
+type LivenessResponse struct {
+	Status        string  `json:"status"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+}
+
+// NewHealthHandler cria uma nova instância do manipulador de health check
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{
+		checker:    checker,
+		startedAt:  time.Now(),
+		lastStatus: health.StatusOK,
+	}
+}
+
+// Liveness indica apenas que o processo está de pé e atendendo requisições.
+// Dependências externas ficam de fora para que uma queda do banco não
+// provoque reinicializações em cascata pelo orquestrador.
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	resp := LivenessResponse{
+		Status:        health.StatusOK,
+		UptimeSeconds: time.Since(h.startedAt).Seconds(),
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// Readiness verifica todas as dependências e responde 503 se alguma
+// obrigatória falhar. Falhas das opcionais deixam o status degraded com 200.
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.checker.Run(r.Context())
+
+	status := http.StatusOK
+	if report.Status == health.StatusError {
+		status = http.StatusServiceUnavailable
+	}
+	h.logTransition(report)
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, status, report)
+}
+
+// logTransition registra a mudança do status de readiness em relação à
+// verificação anterior
+func (h *HealthHandler) logTransition(report health.Report) {
+	h.mu.Lock()
+	previous := h.lastStatus
+	h.lastStatus = report.Status
+	h.mu.Unlock()
+
+	if report.Status == previous {
+		return
+	}
+	switch report.Status {
+	case health.StatusError:
+		logger.Warning("Readiness check falhou", report.Components)
+	case health.StatusDegraded:
+		logger.Warning("Readiness check com componente opcional indisponível", report.Components)
+	default:
+		logger.Info("Readiness check normalizado")
+	}
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/leadexport"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// LeadExportHandler gerencia as rotas de exportação de leads
+type LeadExportHandler struct {
+	exportRepo    *repository.LeadExportRepository
+	fieldRepo     *repository.CustomFieldRepository
+	segmentRepo   *repository.SegmentRepository
+	exportService *leadexport.Service
+}
+
+// LeadExportRequest representa os dados para criar uma exportação em segundo plano
+type LeadExportRequest struct {
+	Format    string            `json:"format" validate:"required,oneof=csv xlsx json"`
+	Columns   []string          `json:"columns,omitempty" doc:"Colunas padrão ou custom.<chave>; se omitido, exporta as colunas padrão e os campos personalizados definidos"`
+	Filters   entity.LeadFilter `json:"filters"`
+	SegmentID int64             `json:"segment_id,omitempty" doc:"Exporta os leads do segmento salvo, no lugar de filters"`
+}
+
+// LeadExportResponse representa uma exportação com o link de download, quando disponível
+type LeadExportResponse struct {
+	*entity.LeadExport
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// NewLeadExportHandler cria uma nova instância do manipulador de exportação
+func NewLeadExportHandler(exportRepo *repository.LeadExportRepository, fieldRepo *repository.CustomFieldRepository, segmentRepo *repository.SegmentRepository, exportService *leadexport.Service) *LeadExportHandler {
+	return &LeadExportHandler{
+		exportRepo:    exportRepo,
+		fieldRepo:     fieldRepo,
+		segmentRepo:   segmentRepo,
+		exportService: exportService,
+	}
+}
+
+// Export gera o arquivo em fluxo na própria resposta, com os mesmos filtros
+// da listagem. Exportações acima do limite síncrono devem usar Create.
+func (h *LeadExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	schema, ok := loadCustomFieldSchema(w, r, h.fieldRepo, orgID)
+	if !ok {
+		return
+	}
+
+	filter, errs := parseLeadFilter(r, schema)
+	req := h.newRequest(r, orgID, r.URL.Query().Get("format"), queryList(r.URL.Query(), "columns"), filter, schema)
+	if req.Format == "" {
+		req.Format = leadexport.FormatCSV
+	}
+	if errs = append(errs, req.Validate()...); len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	export, err := h.exportService.StartSync(req)
+	if err != nil {
+		if errors.Is(err, leadexport.ErrTooManyRows) {
+			response.Error(w, r, http.StatusUnprocessableEntity, response.CodeTooManyRows,
+				fmt.Sprintf("A exportação direta é limitada a %d leads, use POST /api/leads/exports", h.exportService.MaxSyncRows()))
+			return
+		}
+		logger.Error("Erro ao iniciar exportação de leads", err)
+		response.Internal(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", leadexport.ContentType(export.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName()))
+	w.Header().Set("X-Export-ID", fmt.Sprint(export.ID))
+	w.WriteHeader(http.StatusOK)
+
+	// Erros no meio do fluxo não podem mais alterar a resposta; ficam registrados na auditoria
+	h.exportService.WriteSync(r.Context(), w, export)
+}
+
+// Create registra uma exportação para geração em segundo plano
+func (h *LeadExportHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	var body LeadExportRequest
+	if !decodeAndValidate(w, r, &body) {
+		return
+	}
+
+	schema, ok := loadCustomFieldSchema(w, r, h.fieldRepo, orgID)
+	if !ok {
+		return
+	}
+
+	if body.SegmentID != 0 {
+		segment, ok := loadSegmentReference(w, r, h.segmentRepo, orgID, body.SegmentID)
+		if !ok {
+			return
+		}
+		body.Filters = segment.Filter
+	}
+
+	req := h.newRequest(r, orgID, body.Format, body.Columns, body.Filters, schema)
+	errs := validateLeadFilter(&req.Filter, schema)
+	if errs = append(errs, req.Validate()...); len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	export, err := h.exportService.Enqueue(req)
+	if err != nil {
+		if errors.Is(err, leadexport.ErrTooManyRows) {
+			response.Error(w, r, http.StatusUnprocessableEntity, response.CodeTooManyRows,
+				fmt.Sprintf("A exportação é limitada a %d leads, refine os filtros", leadexport.MaxAsyncRows))
+			return
+		}
+		logger.Error("Erro ao criar exportação de leads", err)
+		response.Internal(w, r)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/leads/exports/%d", export.ID))
+	response.JSON(w, http.StatusAccepted, LeadExportResponse{LeadExport: export})
+}
+
+// Get retorna o estado de uma exportação e, se concluída, o link assinado para download
+func (h *LeadExportHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	export, err := h.exportRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, LeadExportResponse{LeadExport: export, DownloadURL: h.exportService.DownloadURL(export)})
+}
+
+// List retorna o histórico de exportações da organização (trilha de auditoria)
+func (h *LeadExportHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	exports, err := h.exportRepo.ListByOrganization(orgID, 100)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	resp := make([]LeadExportResponse, 0, len(exports))
+	for _, export := range exports {
+		resp = append(resp, LeadExportResponse{LeadExport: export, DownloadURL: h.exportService.DownloadURL(export)})
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// Download entrega o arquivo de uma exportação pelo link assinado, sem exigir o token de acesso
+func (h *LeadExportHandler) Download(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	export, file, err := h.exportService.Download(r.Context(), id, r.URL.Query())
+	if err != nil {
+		switch {
+		case errors.Is(err, leadexport.ErrInvalidLink):
+			response.Error(w, r, http.StatusForbidden, response.CodeForbidden, "Link de download inválido ou expirado")
+		case errors.Is(err, leadexport.ErrNotAvailable):
+			response.Error(w, r, http.StatusNotFound, response.CodeNotFound, "Arquivo da exportação não está mais disponível")
+		default:
+			response.Internal(w, r)
+		}
+		return
+	}
+
+	defer file.Close()
+
+	w.Header().Set("Content-Type", leadexport.ContentType(export.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName()))
+	w.Header().Set("Content-Length", fmt.Sprint(export.FileSize))
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, file); err != nil {
+		logger.Error("Erro ao enviar arquivo da exportação de leads", err)
+	}
+}
+
+// newRequest monta a solicitação de exportação com os dados de auditoria da
+// requisição. Sem colunas informadas, exporta as colunas padrão seguidas dos
+// campos personalizados definidos.
+func (h *LeadExportHandler) newRequest(r *http.Request, orgID int64, format string, columns []string, filter entity.LeadFilter, schema customfields.Schema) *leadexport.Request {
+	if len(columns) == 0 {
+		columns = leadexport.DefaultColumns(schema.Ordered())
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	return &leadexport.Request{
+		OrganizationID: orgID,
+		UserID:         userID,
+		Format:         strings.ToLower(format),
+		Columns:        columns,
+		Filter:         filter,
+		IPAddress:      clientIP(r),
+		UserAgent:      truncate(r.UserAgent(), 255),
+	}
+}
+
+// clientIP retorna o IP de origem da requisição (já resolvido pelo middleware RealIP)
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// truncate limita s a n bytes sem cortar caracteres multibyte
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,687 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/duplicates"
+	"github.com/whatsapp/backend/internal/leadmerge"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+	"github.com/whatsapp/backend/pkg/phone"
+)
+
+// Limites de paginação da listagem de leads
+const (
+	defaultLeadPageSize = 50
+	maxLeadPageSize     = 200
+)
+
+// Limites dos filtros de etiquetas e de janelas de interação em dias
+const (
+	maxFilterTags = 50
+	maxFilterDays = 3650
+)
+
+// LeadHandler gerencia as rotas de leads
+type LeadHandler struct {
+	leadRepo          *repository.LeadRepository
+	fieldRepo         *repository.CustomFieldRepository
+	userRepo          *repository.UserRepository
+	duplicatesService *duplicates.Service
+	mergeService      *leadmerge.Service
+}
+
+// CreateLeadRequest representa os dados para cadastro manual de um lead
+type CreateLeadRequest struct {
+	Name         string                 `json:"name" validate:"max=100"`
+	Phone        string                 `json:"phone" validate:"required,phone" doc:"Aceita formatação livre; é gravado em E.164"`
+	Email        string                 `json:"email,omitempty" validate:"email,max=100"`
+	Source       string                 `json:"source,omitempty" validate:"max=50"`
+	Status       string                 `json:"status,omitempty" validate:"oneof=new contacted qualified won lost"`
+	Stage        string                 `json:"stage,omitempty" validate:"max=50"`
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty" doc:"Valores validados conforme o tipo de cada campo definido em /api/leads/custom-fields"`
+}
+
+// Validate verifica as chaves dos campos personalizados
+func (req CreateLeadRequest) Validate() []response.FieldError {
+	var errs []response.FieldError
+	for key := range req.CustomFields {
+		if !entity.IsValidCustomFieldKey(key) {
+			errs = append(errs, response.FieldError{
+				Field:   "custom_fields." + key,
+				Code:    "invalid_key",
+				Message: "Use letras minúsculas, números e _ (até 50 caracteres)",
+			})
+		}
+	}
+	return errs
+}
+
+// UpdateLeadRequest representa os novos dados de um lead. Os campos
+// substituem os atuais, exceto status, que é mantido quando omitido.
+type UpdateLeadRequest struct {
+	Name         string                 `json:"name" validate:"max=100"`
+	Phone        string                 `json:"phone" validate:"required,phone" doc:"Aceita formatação livre; é gravado em E.164"`
+	Email        string                 `json:"email,omitempty" validate:"email,max=100"`
+	Source       string                 `json:"source,omitempty" validate:"max=50"`
+	Status       string                 `json:"status,omitempty" validate:"oneof=new contacted qualified won lost"`
+	Stage        string                 `json:"stage,omitempty" validate:"max=50"`
+	OwnerID      *int64                 `json:"owner_id,omitempty" doc:"Responsável, um usuário da organização; omitido deixa o lead sem responsável"`
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty" doc:"Valores validados conforme o tipo de cada campo definido em /api/leads/custom-fields"`
+}
+
+// Validate verifica as chaves dos campos personalizados
+func (req UpdateLeadRequest) Validate() []response.FieldError {
+	return CreateLeadRequest{CustomFields: req.CustomFields}.Validate()
+}
+
+// MergeLeadsRequest representa a mesclagem de leads no lead da rota, que é o sobrevivente
+type MergeLeadsRequest struct {
+	LeadIDs []int64          `json:"lead_ids" validate:"required,min=1,max=10" doc:"Leads incorporados ao sobrevivente e removidos; seus IDs passam a redirecionar para ele"`
+	Policy  string           `json:"policy,omitempty" validate:"oneof=survivor newest oldest" doc:"Origem dos valores não escolhidos em fields (padrão survivor)"`
+	Fields  map[string]int64 `json:"fields,omitempty" doc:"ID do lead de onde vem cada campo, como name, phone, owner_id ou custom.<chave>"`
+}
+
+// Validate verifica IDs repetidos e os campos escolhidos
+func (req MergeLeadsRequest) Validate() []response.FieldError {
+	var errs []response.FieldError
+	seen := make(map[int64]bool, len(req.LeadIDs))
+	for _, id := range req.LeadIDs {
+		if id <= 0 || seen[id] {
+			errs = append(errs, response.FieldError{Field: "lead_ids", Code: "invalid", Message: "Informe IDs de leads válidos e sem repetição"})
+			break
+		}
+		seen[id] = true
+	}
+	for field := range req.Fields {
+		if !leadmerge.IsValidField(field) {
+			errs = append(errs, response.FieldError{
+				Field:   "fields." + field,
+				Code:    "invalid_field",
+				Message: "Use " + strings.Join(leadmerge.Fields, ", ") + " ou " + leadmerge.CustomFieldPrefix + "<chave>",
+			})
+		}
+	}
+	return errs
+}
+
+// DuplicatesResponse lista os possíveis duplicados encontrados
+type DuplicatesResponse struct {
+	Data []duplicates.Candidate `json:"data"`
+}
+
+// LeadListResponse representa uma página da listagem de leads
+type LeadListResponse struct {
+	Data   []*entity.Lead `json:"data"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// NewLeadHandler cria uma nova instância do manipulador de leads
+func NewLeadHandler(leadRepo *repository.LeadRepository, fieldRepo *repository.CustomFieldRepository, userRepo *repository.UserRepository, duplicatesService *duplicates.Service, mergeService *leadmerge.Service) *LeadHandler {
+	return &LeadHandler{
+		leadRepo:          leadRepo,
+		fieldRepo:         fieldRepo,
+		userRepo:          userRepo,
+		duplicatesService: duplicatesService,
+		mergeService:      mergeService,
+	}
+}
+
+// List retorna os leads da organização que atendem aos filtros informados
+func (h *LeadHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	schema, ok := loadCustomFieldSchema(w, r, h.fieldRepo, orgID)
+	if !ok {
+		return
+	}
+
+	filter, errs := parseLeadFilter(r, schema)
+	limit, offset, pageErrs := parsePagination(r.URL.Query())
+	if errs = append(errs, pageErrs...); len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	leads, total, err := h.leadRepo.List(orgID, filter, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, LeadListResponse{
+		Data:   leads,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// Create cadastra um lead, recusando telefones já existentes na organização
+func (h *LeadHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateLeadRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	schema, ok := loadCustomFieldSchema(w, r, h.fieldRepo, orgID)
+	if !ok {
+		return
+	}
+	if req.CustomFields == nil {
+		req.CustomFields = make(map[string]interface{})
+	}
+	if errs := schema.NormalizeFields(req.CustomFields, "custom_fields."); len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	normalized, _ := phone.Normalize(req.Phone)
+	lead := entity.NewLead(orgID, strings.TrimSpace(req.Name), normalized)
+	lead.Email = strings.ToLower(req.Email)
+	lead.Source = req.Source
+	lead.Stage = req.Stage
+	if req.Status != "" {
+		lead.Status = req.Status
+	}
+	lead.CustomFields = req.CustomFields
+
+	userID, _ := auth.GetUserID(r.Context())
+	if err := h.leadRepo.Create(lead, userID); err != nil {
+		var dup *repository.DuplicateLeadError
+		if errors.As(err, &dup) {
+			duplicatePhoneConflict(w, r, dup.ExistingID)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/leads/%d", lead.ID))
+	response.JSON(w, http.StatusCreated, lead)
+}
+
+// Get retorna um lead da organização
+func (h *LeadHandler) Get(w http.ResponseWriter, r *http.Request) {
+	lead, ok := h.loadLead(w, r)
+	if !ok {
+		return
+	}
+
+	response.JSON(w, http.StatusOK, lead)
+}
+
+// Update altera os dados do lead, registrando no histórico cada campo
+// alterado com os valores anterior e novo
+func (h *LeadHandler) Update(w http.ResponseWriter, r *http.Request) {
+	lead, ok := h.loadLead(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateLeadRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	schema, ok := loadCustomFieldSchema(w, r, h.fieldRepo, lead.OrganizationID)
+	if !ok {
+		return
+	}
+	if req.CustomFields == nil {
+		req.CustomFields = make(map[string]interface{})
+	}
+	if errs := schema.NormalizeFields(req.CustomFields, "custom_fields."); len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	if req.OwnerID != nil {
+		owner, err := h.userRepo.GetByID(*req.OwnerID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			response.Internal(w, r)
+			return
+		}
+		if owner == nil || owner.OrganizationID != lead.OrganizationID {
+			response.ValidationError(w, r, []response.FieldError{{Field: "owner_id", Code: "not_found", Message: "Usuário não encontrado na organização"}})
+			return
+		}
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	normalized, _ := phone.Normalize(req.Phone)
+	updated, err := h.leadRepo.Update(lead.OrganizationID, lead.ID, userID, func(l *entity.Lead) error {
+		l.Name = strings.TrimSpace(req.Name)
+		l.Phone = normalized
+		l.Email = strings.ToLower(req.Email)
+		l.Source = req.Source
+		l.Stage = req.Stage
+		if req.Status != "" {
+			l.Status = req.Status
+		}
+		l.OwnerID = req.OwnerID
+		l.CustomFields = req.CustomFields
+		return nil
+	})
+	if err != nil {
+		var dup *repository.DuplicateLeadError
+		switch {
+		case errors.As(err, &dup):
+			duplicatePhoneConflict(w, r, dup.ExistingID)
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(w, r)
+		default:
+			response.Internal(w, r)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, updated)
+}
+
+// Duplicates retorna os possíveis duplicados de um lead existente
+func (h *LeadHandler) Duplicates(w http.ResponseWriter, r *http.Request) {
+	lead, ok := h.loadLead(w, r)
+	if !ok {
+		return
+	}
+
+	candidates, err := h.duplicatesService.FindForLead(lead)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, DuplicatesResponse{Data: candidates})
+}
+
+// CheckDuplicates procura leads parecidos com os dados informados, permitindo
+// avisar o usuário antes de cadastrar um novo lead
+func (h *LeadHandler) CheckDuplicates(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	q := duplicates.Query{
+		Name:  strings.TrimSpace(query.Get("name")),
+		Phone: strings.TrimSpace(query.Get("phone")),
+		Email: strings.TrimSpace(query.Get("email")),
+	}
+	if q.Name == "" && q.Phone == "" && q.Email == "" {
+		response.ValidationError(w, r, []response.FieldError{{
+			Field:   "phone",
+			Code:    "required",
+			Message: "Informe ao menos nome, telefone ou email",
+		}})
+		return
+	}
+
+	candidates, err := h.duplicatesService.Find(orgID, q)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, DuplicatesResponse{Data: candidates})
+}
+
+// Merge combina os leads informados no lead da rota, em uma única transação
+func (h *LeadHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	survivorID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req MergeLeadsRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	var errs []response.FieldError
+	involved := map[int64]bool{survivorID: true}
+	for _, id := range req.LeadIDs {
+		if id == survivorID {
+			errs = append(errs, response.FieldError{Field: "lead_ids", Code: "invalid", Message: "Não inclua o lead sobrevivente"})
+		}
+		involved[id] = true
+	}
+	for field, id := range req.Fields {
+		if !involved[id] {
+			errs = append(errs, response.FieldError{
+				Field:   "fields." + field,
+				Code:    "invalid",
+				Message: "Escolha o sobrevivente ou um dos leads mesclados",
+			})
+		}
+	}
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	lead, err := h.mergeService.Merge(leadmerge.Request{
+		OrganizationID: orgID,
+		UserID:         userID,
+		SurvivorID:     survivorID,
+		LeadIDs:        req.LeadIDs,
+		Policy:         req.Policy,
+		Choices:        req.Fields,
+		IPAddress:      clientIP(r),
+	})
+	if err != nil {
+		var missing *repository.MissingLeadsError
+		if errors.As(err, &missing) {
+			for _, id := range missing.IDs {
+				if id == survivorID {
+					response.NotFound(w, r)
+					return
+				}
+			}
+			response.ValidationError(w, r, []response.FieldError{{
+				Field:   "lead_ids",
+				Code:    "not_found",
+				Message: fmt.Sprintf("Leads não encontrados: %s", joinIDs(missing.IDs)),
+			}})
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, lead)
+}
+
+// loadLead busca o lead da rota na organização do usuário, respondendo 404 se não existir
+func (h *LeadHandler) loadLead(w http.ResponseWriter, r *http.Request) (*entity.Lead, bool) {
+	return loadRouteLead(w, r, h.leadRepo)
+}
+
+// loadRouteLead busca o lead do parâmetro id da rota, resolvendo IDs de
+// leads mesclados para o sobrevivente. Responde 404 se não existir.
+func loadRouteLead(w http.ResponseWriter, r *http.Request, leadRepo *repository.LeadRepository) (*entity.Lead, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	lead, err := leadRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+
+	if lead.ID != id {
+		// ID de um lead mesclado: informa o endereço canônico do sobrevivente
+		w.Header().Set("Content-Location", fmt.Sprintf("/api/leads/%d", lead.ID))
+	}
+
+	return lead, true
+}
+
+// duplicatePhoneConflict responde 409 indicando o lead que já usa o telefone
+func duplicatePhoneConflict(w http.ResponseWriter, r *http.Request, existingID int64) {
+	response.WriteProblem(w, r, response.NewProblem(http.StatusConflict, response.CodeConflict,
+		"Já existe um lead com este telefone").WithErrors([]response.FieldError{{
+		Field:   "phone",
+		Code:    "duplicate",
+		Message: fmt.Sprintf("Telefone já cadastrado no lead %d", existingID),
+	}}))
+}
+
+// joinIDs formata uma lista de IDs separados por vírgula
+func joinIDs(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseLeadFilter lê os filtros da listagem da query string. Listas aceitam
+// valores separados por vírgula ou o parâmetro repetido; owner_id=me
+// seleciona os leads do usuário autenticado. Campos personalizados são
+// filtrados por custom.<chave>=valor e, para números e datas, também por
+// custom.<chave>.min e custom.<chave>.max.
+func parseLeadFilter(r *http.Request, schema customfields.Schema) (entity.LeadFilter, []response.FieldError) {
+	query := r.URL.Query()
+	filter := entity.LeadFilter{
+		Statuses:    queryList(query, "status"),
+		Stage:       strings.TrimSpace(query.Get("stage")),
+		Source:      strings.TrimSpace(query.Get("source")),
+		Tags:        queryList(query, "tag"),
+		TagMode:     query.Get("tag_mode"),
+		ExcludeTags: queryList(query, "exclude_tag"),
+		Search:      strings.TrimSpace(query.Get("q")),
+		Sort:        query.Get("sort"),
+	}
+
+	var errs []response.FieldError
+	if owner := query.Get("owner_id"); owner != "" {
+		if owner == "me" {
+			if userID, ok := auth.GetUserID(r.Context()); ok {
+				filter.OwnerID = &userID
+			}
+		} else if id, err := strconv.ParseInt(owner, 10, 64); err == nil && id > 0 {
+			filter.OwnerID = &id
+		} else {
+			errs = append(errs, response.FieldError{Field: "owner_id", Code: "invalid", Message: "Informe o ID do responsável ou me"})
+		}
+	}
+
+	var err *response.FieldError
+	if filter.CreatedFrom, err = queryTime(query, "created_from", false); err != nil {
+		errs = append(errs, *err)
+	}
+	if filter.CreatedTo, err = queryTime(query, "created_to", true); err != nil {
+		errs = append(errs, *err)
+	}
+	if filter.LastMessageFrom, err = queryTime(query, "last_message_from", false); err != nil {
+		errs = append(errs, *err)
+	}
+	if filter.LastMessageTo, err = queryTime(query, "last_message_to", true); err != nil {
+		errs = append(errs, *err)
+	}
+
+	if filter.ActiveWithinDays, err = queryDays(query, "active_within_days"); err != nil {
+		errs = append(errs, *err)
+	}
+	if filter.InactiveForDays, err = queryDays(query, "inactive_for_days"); err != nil {
+		errs = append(errs, *err)
+	}
+
+	filter.CustomFields = queryCustomFieldConditions(query)
+
+	return filter, append(errs, validateLeadFilter(&filter, schema)...)
+}
+
+// queryCustomFieldConditions lê os parâmetros custom.<chave>[.min|.max], em
+// ordem alfabética para que a consulta gerada seja estável
+func queryCustomFieldConditions(query url.Values) []entity.CustomFieldCondition {
+	var names []string
+	for name := range query {
+		if strings.HasPrefix(name, entity.CustomFieldPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var conds []entity.CustomFieldCondition
+	for _, name := range names {
+		key, op := strings.TrimPrefix(name, entity.CustomFieldPrefix), entity.CustomFieldOpEq
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			key, op = key[:i], key[i+1:]
+		}
+		conds = append(conds, entity.CustomFieldCondition{Key: key, Op: op, Value: query.Get(name)})
+	}
+	return conds
+}
+
+// validateLeadFilter verifica os filtros recebidos pela query string ou no
+// corpo da exportação, normalizando os valores das condições sobre campos
+// personalizados conforme as definições da organização
+func validateLeadFilter(filter *entity.LeadFilter, schema customfields.Schema) []response.FieldError {
+	var errs []response.FieldError
+	for _, status := range filter.Statuses {
+		if !entity.IsValidLeadStatus(status) {
+			errs = append(errs, response.FieldError{
+				Field:   "status",
+				Code:    "oneof",
+				Message: "Use um dos valores: " + strings.Join(entity.LeadStatuses, ", "),
+			})
+			break
+		}
+	}
+	if filter.TagMode != "" && filter.TagMode != entity.TagModeAny && filter.TagMode != entity.TagModeAll {
+		errs = append(errs, response.FieldError{Field: "tag_mode", Code: "oneof", Message: "Use any ou all"})
+	}
+	if len(filter.Tags)+len(filter.ExcludeTags) > maxFilterTags {
+		errs = append(errs, response.FieldError{Field: "tag", Code: "max", Message: fmt.Sprintf("Informe no máximo %d etiquetas", maxFilterTags)})
+	}
+	if filter.Sort != "" && !entity.IsValidLeadSort(filter.Sort) {
+		errs = append(errs, response.FieldError{
+			Field:   "sort",
+			Code:    "oneof",
+			Message: "Ordene por " + strings.Join(entity.LeadSortFields, ", ") + " ou custom.<chave> (prefixo - para ordem decrescente)",
+		})
+	} else if key, ok := strings.CutPrefix(strings.TrimPrefix(filter.Sort, "-"), entity.CustomFieldPrefix); ok && schema[key] == nil {
+		errs = append(errs, response.FieldError{Field: "sort", Code: "unknown_field", Message: "Campo personalizado não definido"})
+	}
+	for i := range filter.CustomFields {
+		if fe := schema.NormalizeCondition(&filter.CustomFields[i]); fe != nil {
+			errs = append(errs, *fe)
+		}
+	}
+	if len(filter.Search) > 100 {
+		errs = append(errs, response.FieldError{Field: "q", Code: "max", Message: "Deve ter no máximo 100 caracteres"})
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedTo.After(*filter.CreatedFrom) {
+		errs = append(errs, response.FieldError{Field: "created_to", Code: "range", Message: "Deve ser posterior a created_from"})
+	}
+	if fe := validateFilterDays("active_within_days", filter.ActiveWithinDays); fe != nil {
+		errs = append(errs, *fe)
+	}
+	if fe := validateFilterDays("inactive_for_days", filter.InactiveForDays); fe != nil {
+		errs = append(errs, *fe)
+	}
+	if filter.LastMessageFrom != nil && filter.LastMessageTo != nil && !filter.LastMessageTo.After(*filter.LastMessageFrom) {
+		errs = append(errs, response.FieldError{Field: "last_message_to", Code: "range", Message: "Deve ser posterior a last_message_from"})
+	}
+	return errs
+}
+
+func validateFilterDays(field string, days *int) *response.FieldError {
+	if days != nil && (*days < 1 || *days > maxFilterDays) {
+		return &response.FieldError{Field: field, Code: "range", Message: fmt.Sprintf("Deve estar entre 1 e %d", maxFilterDays)}
+	}
+	return nil
+}
+
+// parsePagination lê limit e offset, aplicando o tamanho de página padrão
+func parsePagination(query url.Values) (int, int, []response.FieldError) {
+	var errs []response.FieldError
+
+	limit := defaultLeadPageSize
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxLeadPageSize {
+			errs = append(errs, response.FieldError{Field: "limit", Code: "range", Message: fmt.Sprintf("Deve estar entre 1 e %d", maxLeadPageSize)})
+		} else {
+			limit = n
+		}
+	}
+
+	offset := 0
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			errs = append(errs, response.FieldError{Field: "offset", Code: "min", Message: "Não pode ser negativo"})
+		} else {
+			offset = n
+		}
+	}
+
+	return limit, offset, errs
+}
+
+// queryList junta os valores repetidos e separados por vírgula de um parâmetro
+func queryList(query url.Values, name string) []string {
+	var values []string
+	for _, raw := range query[name] {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// queryDays lê um número inteiro de dias; o intervalo é verificado por validateLeadFilter
+func queryDays(query url.Values, name string) (*int, *response.FieldError) {
+	v := query.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, &response.FieldError{Field: name, Code: "number", Message: "Deve ser um número inteiro de dias"}
+	}
+	return &n, nil
+}
+
+// queryTime lê uma data (AAAA-MM-DD) ou data e hora RFC 3339. Para o fim de
+// um intervalo, uma data sem hora inclui o dia inteiro.
+func queryTime(query url.Values, name string, endOfRange bool) (*time.Time, *response.FieldError) {
+	v := query.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		if endOfRange {
+			t = t.AddDate(0, 0, 1)
+		}
+		return &t, nil
+	}
+
+	return nil, &response.FieldError{Field: name, Code: "date", Message: "Use o formato AAAA-MM-DD ou RFC 3339"}
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/leadimport"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// maxImportFileBytes limita o tamanho das planilhas enviadas
+const maxImportFileBytes = 10 << 20
+
+// LeadImportHandler gerencia as rotas de importação de leads
+type LeadImportHandler struct {
+	importRepo    *repository.LeadImportRepository
+	importService *leadimport.Service
+}
+
+// LeadImportResponse representa um job de importação com seu progresso
+type LeadImportResponse struct {
+	*entity.LeadImport
+	Progress float64 `json:"progress"`
+}
+
+// LeadImportUploadForm descreve os campos do formulário multipart de importação
+type LeadImportUploadForm struct {
+	File    []byte `json:"file" validate:"required" doc:"Planilha CSV ou XLSX"`
+	Mapping string `json:"mapping,omitempty" doc:"JSON associando cada coluna a um campo: name, phone, email, source, status, stage, custom.<chave> ou - para ignorar. Se omitido, é sugerido pelos nomes das colunas"`
+	DryRun  bool   `json:"dry_run,omitempty" doc:"Apenas valida e pré-visualiza, sem gravar"`
+}
+
+// NewLeadImportHandler cria uma nova instância do manipulador de importação
+func NewLeadImportHandler(importRepo *repository.LeadImportRepository, importService *leadimport.Service) *LeadImportHandler {
+	return &LeadImportHandler{
+		importRepo:    importRepo,
+		importService: importService,
+	}
+}
+
+// Upload recebe a planilha. Em modo dry run responde com a pré-visualização;
+// caso contrário cria o job e responde 202 para acompanhamento do progresso.
+func (h *LeadImportHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileBytes+(1<<20))
+	if err := r.ParseMultipartForm(maxImportFileBytes); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(w, r, http.StatusRequestEntityTooLarge, response.CodeBodyTooLarge,
+				fmt.Sprintf("O arquivo deve ter no máximo %d MB", maxImportFileBytes>>20))
+			return
+		}
+		response.InvalidBody(w, r)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		response.ValidationError(w, r, []response.FieldError{{Field: "file", Code: "required", Message: "Envie a planilha no campo file"}})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		logger.Error("Erro ao ler arquivo enviado", err)
+		response.InvalidBody(w, r)
+		return
+	}
+
+	upload := &leadimport.Upload{
+		OrganizationID: orgID,
+		UserID:         userID,
+		FileName:       header.Filename,
+		Data:           data,
+	}
+
+	if raw := r.FormValue("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &upload.Mapping); err != nil {
+			response.ValidationError(w, r, []response.FieldError{{Field: "mapping", Code: "invalid_json", Message: "O mapeamento deve ser um objeto JSON de coluna para campo"}})
+			return
+		}
+	}
+
+	dryRun, _ := strconv.ParseBool(r.FormValue("dry_run"))
+	if !dryRun {
+		dryRun, _ = strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	}
+
+	if dryRun {
+		preview, err := h.importService.Preview(upload)
+		if err != nil {
+			h.writeUploadError(w, r, err)
+			return
+		}
+		response.JSON(w, http.StatusOK, preview)
+		return
+	}
+
+	job, err := h.importService.Enqueue(upload)
+	if err != nil {
+		h.writeUploadError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/leads/imports/%d", job.ID))
+	response.JSON(w, http.StatusAccepted, LeadImportResponse{LeadImport: job, Progress: job.Progress()})
+}
+
+// Get retorna o estado e o progresso de uma importação
+func (h *LeadImportHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.importRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, LeadImportResponse{LeadImport: job, Progress: job.Progress()})
+}
+
+// List retorna as importações mais recentes da organização
+func (h *LeadImportHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	jobs, err := h.importRepo.ListByOrganization(orgID, 50)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	resp := make([]LeadImportResponse, 0, len(jobs))
+	for _, job := range jobs {
+		resp = append(resp, LeadImportResponse{LeadImport: job, Progress: job.Progress()})
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// writeUploadError traduz os erros de leitura e mapeamento em respostas de validação
+func (h *LeadImportHandler) writeUploadError(w http.ResponseWriter, r *http.Request, err error) {
+	var mappingErr *leadimport.MappingError
+	var fileErr *leadimport.FileError
+	switch {
+	case errors.As(err, &mappingErr):
+		response.ValidationError(w, r, mappingErr.Errors)
+	case errors.As(err, &fileErr):
+		response.ValidationError(w, r, []response.FieldError{{Field: "file", Code: fileErr.Code, Message: fileErr.Error()}})
+	default:
+		logger.Error("Erro ao criar importação de leads", err)
+		response.Internal(w, r)
+	}
+}
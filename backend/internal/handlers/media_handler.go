@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/media"
+	"github.com/whatsapp/backend/internal/response"
+	"github.com/whatsapp/backend/internal/whatsapp"
+)
+
+// MediaHandler entrega os arquivos das mensagens com mídia
+type MediaHandler struct {
+	media *media.Service
+}
+
+// NewMediaHandler cria uma nova instância do manipulador de mídias
+func NewMediaHandler(media *media.Service) *MediaHandler {
+	return &MediaHandler{
+		media: media,
+	}
+}
+
+// Download entrega o arquivo de uma mídia pelo link assinado, sem exigir o token de acesso
+func (h *MediaHandler) Download(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	m, file, err := h.media.Open(r.Context(), id, r.URL.Query())
+	if err != nil {
+		switch {
+		case errors.Is(err, media.ErrInvalidLink):
+			response.Error(w, r, http.StatusForbidden, response.CodeForbidden, "Link da mídia inválido ou expirado")
+		case errors.Is(err, media.ErrNotAvailable):
+			response.Error(w, r, http.StatusNotFound, response.CodeNotFound, "Arquivo da mídia não está disponível")
+		default:
+			response.Internal(w, r)
+		}
+		return
+	}
+	defer file.Close()
+
+	contentType := m.MIMEType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", fmt.Sprint(m.Size))
+	w.Header().Set("Content-Disposition", contentDisposition(contentType, m.FileName))
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, file); err != nil {
+		logger.Error("Erro ao enviar arquivo da mídia", err)
+	}
+}
+
+// contentDisposition exibe no navegador apenas imagens, áudios e vídeos.
+// O tipo é o declarado por quem enviou o arquivo, de modo que documentos,
+// que podem ser HTML ou SVG, são sempre baixados. O nome segue a RFC 6266,
+// com filename* para nomes fora do ASCII.
+func contentDisposition(contentType, fileName string) string {
+	disposition := "attachment"
+	if whatsapp.Inline(contentType) {
+		disposition = "inline"
+	}
+	if fileName == "" {
+		return disposition
+	}
+	if value := mime.FormatMediaType(disposition, map[string]string{"filename": fileName}); value != "" {
+		return value
+	}
+	return disposition
+}
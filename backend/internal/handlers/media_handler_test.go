@@ -0,0 +1,27 @@
+package handlers
+
+import "testing"
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		contentType string
+		fileName    string
+		want        string
+	}{
+		{"image/jpeg", "", "inline"},
+		{"image/jpeg", "foto.jpg", "inline; filename=foto.jpg"},
+		{"audio/ogg; codecs=opus", "audio.ogg", "inline; filename=audio.ogg"},
+		{"application/pdf", "proposta.pdf", "attachment; filename=proposta.pdf"},
+		{"text/html", "pagina.html", "attachment; filename=pagina.html"},
+		{"image/svg+xml", "", "attachment"},
+		{"application/pdf", "proposta final.pdf", `attachment; filename="proposta final.pdf"`},
+		{"application/pdf", `a"b.pdf`, `attachment; filename="a\"b.pdf"`},
+		{"application/pdf", "orçamento.pdf", "attachment; filename*=utf-8''or%C3%A7amento.pdf"},
+	}
+
+	for _, tt := range tests {
+		if got := contentDisposition(tt.contentType, tt.fileName); got != tt.want {
+			t.Errorf("contentDisposition(%q, %q) = %q, esperado %q", tt.contentType, tt.fileName, got, tt.want)
+		}
+	}
+}
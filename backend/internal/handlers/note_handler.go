@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/mentions"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// notificationPreviewLength limita o trecho da nota copiado para a notificação
+const notificationPreviewLength = 200
+
+// NoteHandler gerencia as notas internas dos leads
+type NoteHandler struct {
+	noteRepo *repository.NoteRepository
+	leadRepo *repository.LeadRepository
+	userRepo *repository.UserRepository
+}
+
+// NoteRequest representa o texto de uma nota
+type NoteRequest struct {
+	Body string `json:"body" validate:"required,max=5000" doc:"Colegas são mencionados com @ seguido do email ou da parte antes do @ (@maria) e recebem uma notificação"`
+}
+
+// NoteListResponse representa uma página das notas de um lead
+type NoteListResponse struct {
+	Data   []*entity.Note `json:"data"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// NewNoteHandler cria uma nova instância do manipulador de notas
+func NewNoteHandler(noteRepo *repository.NoteRepository, leadRepo *repository.LeadRepository, userRepo *repository.UserRepository) *NoteHandler {
+	return &NoteHandler{
+		noteRepo: noteRepo,
+		leadRepo: leadRepo,
+		userRepo: userRepo,
+	}
+}
+
+// List retorna as notas do lead, das mais recentes para as mais antigas
+func (h *NoteHandler) List(w http.ResponseWriter, r *http.Request) {
+	lead, ok := loadRouteLead(w, r, h.leadRepo)
+	if !ok {
+		return
+	}
+
+	limit, offset, errs := parsePagination(r.URL.Query())
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	notes, total, err := h.noteRepo.ListByLead(lead.OrganizationID, lead.ID, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, NoteListResponse{
+		Data:   notes,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// Create registra uma nota no lead e notifica os colegas mencionados
+func (h *NoteHandler) Create(w http.ResponseWriter, r *http.Request) {
+	lead, ok := loadRouteLead(w, r, h.leadRepo)
+	if !ok {
+		return
+	}
+
+	var req NoteRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	users, err := h.userRepo.ListByOrganization(lead.OrganizationID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	note := entity.NewNote(lead.OrganizationID, lead.ID, userID, strings.TrimSpace(req.Body))
+	note.Mentions = mentions.Resolve(note.Body, users)
+
+	if err := h.noteRepo.Create(note, mentionNotifications(note, lead, users, note.Mentions)); err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, note)
+}
+
+// Update altera o texto da nota. Apenas o autor pode editá-la, e só os
+// colegas mencionados pela primeira vez são notificados.
+func (h *NoteHandler) Update(w http.ResponseWriter, r *http.Request) {
+	note, ok := h.loadOwnNote(w, r)
+	if !ok {
+		return
+	}
+
+	var req NoteRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	lead, err := h.leadRepo.GetByID(note.OrganizationID, note.LeadID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	users, err := h.userRepo.ListByOrganization(note.OrganizationID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	previous := make(map[int64]bool, len(note.Mentions))
+	for _, id := range note.Mentions {
+		previous[id] = true
+	}
+
+	note.Body = strings.TrimSpace(req.Body)
+	note.Mentions = mentions.Resolve(note.Body, users)
+
+	var added []int64
+	for _, id := range note.Mentions {
+		if !previous[id] {
+			added = append(added, id)
+		}
+	}
+
+	if err := h.noteRepo.Update(note, mentionNotifications(note, lead, users, added)); err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, note)
+}
+
+// Delete remove a nota. Apenas o autor pode removê-la.
+func (h *NoteHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	note, ok := h.loadOwnNote(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.noteRepo.Delete(note.OrganizationID, note.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// loadOwnNote busca a nota da rota, respondendo 404 se não existir e 403 se
+// o usuário não for o autor
+func (h *NoteHandler) loadOwnNote(w http.ResponseWriter, r *http.Request) (*entity.Note, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	note, err := h.noteRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+
+	if userID, _ := auth.GetUserID(r.Context()); note.UserID != userID {
+		response.Error(w, r, http.StatusForbidden, response.CodeForbidden,
+			"Apenas o autor pode alterar ou remover a nota")
+		return nil, false
+	}
+
+	return note, true
+}
+
+// mentionNotifications monta as notificações dos usuários mencionados,
+// exceto o próprio autor
+func mentionNotifications(note *entity.Note, lead *entity.Lead, users []*entity.User, userIDs []int64) []*entity.Notification {
+	author := "Um colega"
+	for _, user := range users {
+		if user.ID == note.UserID && user.Name != "" {
+			author = user.Name
+			break
+		}
+	}
+
+	var notifications []*entity.Notification
+	for _, id := range userIDs {
+		if id == note.UserID {
+			continue
+		}
+		n := entity.NewNotification(note.OrganizationID, id, entity.NotificationNoteMention,
+			author+" mencionou você em uma nota")
+		n.Body = truncateRunes(note.Body, notificationPreviewLength)
+		n.Data["lead_id"] = lead.ID
+		n.Data["lead_name"] = lead.Name
+		n.Data["author_id"] = note.UserID
+		notifications = append(notifications, n)
+	}
+	return notifications
+}
+
+// truncateRunes corta o texto em max caracteres, indicando o corte com reticências
+func truncateRunes(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// NotificationHandler gerencia as notificações do usuário autenticado
+type NotificationHandler struct {
+	notificationRepo *repository.NotificationRepository
+}
+
+// NotificationListResponse representa uma página das notificações do usuário
+type NotificationListResponse struct {
+	Data        []*entity.Notification `json:"data"`
+	UnreadCount int                    `json:"unread_count"`
+	Limit       int                    `json:"limit"`
+	Offset      int                    `json:"offset"`
+}
+
+// MarkAllReadResponse informa quantas notificações foram marcadas como lidas
+type MarkAllReadResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+// NewNotificationHandler cria uma nova instância do manipulador de notificações
+func NewNotificationHandler(notificationRepo *repository.NotificationRepository) *NotificationHandler {
+	return &NotificationHandler{
+		notificationRepo: notificationRepo,
+	}
+}
+
+// List retorna as notificações do usuário, das mais recentes para as mais
+// antigas. Com unread=true, apenas as não lidas.
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, offset, errs := parsePagination(query)
+	unreadOnly := false
+	switch query.Get("unread") {
+	case "", "false":
+	case "true":
+		unreadOnly = true
+	default:
+		errs = append(errs, response.FieldError{Field: "unread", Code: "boolean", Message: "Use true ou false"})
+	}
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	notifications, unread, err := h.notificationRepo.ListByUser(orgID, userID, unreadOnly, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, NotificationListResponse{
+		Data:        notifications,
+		UnreadCount: unread,
+		Limit:       limit,
+		Offset:      offset,
+	})
+}
+
+// MarkRead marca uma notificação do usuário como lida
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	if err := h.notificationRepo.MarkRead(orgID, userID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// MarkAllRead marca todas as notificações do usuário como lidas
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	updated, err := h.notificationRepo.MarkAllRead(orgID, userID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, MarkAllReadResponse{Updated: updated})
+}
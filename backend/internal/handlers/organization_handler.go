@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// OrganizationHandler gerencia os membros da organização e os convites para
+// novos atendentes
+type OrganizationHandler struct {
+	userRepo   *repository.UserRepository
+	inviteRepo *repository.OrganizationInviteRepository
+}
+
+// InviteRequest representa um convite para a organização
+type InviteRequest struct {
+	Email string `json:"email" validate:"required,email,max=100"`
+	Role  string `json:"role" validate:"required,oneof=admin agent" doc:"admin também gerencia membros, convites e o canal do WhatsApp"`
+}
+
+// InviteResponse é o convite criado, com o token a entregar ao convidado
+type InviteResponse struct {
+	*entity.OrganizationInvite
+	Token string `json:"token" doc:"Entregue ao convidado para POST /api/auth/invites/accept; não é exibido novamente"`
+}
+
+// MemberRoleRequest representa a alteração do papel de um membro
+type MemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin agent"`
+}
+
+// MemberListResponse lista os membros da organização
+type MemberListResponse struct {
+	Data []*entity.User `json:"data"`
+}
+
+// InviteListResponse lista os convites pendentes da organização
+type InviteListResponse struct {
+	Data []*entity.OrganizationInvite `json:"data"`
+}
+
+// NewOrganizationHandler cria uma nova instância do manipulador da organização
+func NewOrganizationHandler(userRepo *repository.UserRepository, inviteRepo *repository.OrganizationInviteRepository) *OrganizationHandler {
+	return &OrganizationHandler{
+		userRepo:   userRepo,
+		inviteRepo: inviteRepo,
+	}
+}
+
+// ListMembers lista os usuários da organização com seus papéis
+func (h *OrganizationHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	users, err := h.userRepo.ListByOrganization(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, MemberListResponse{Data: users})
+}
+
+// UpdateMemberRole altera o papel de um membro da organização. Restrito aos
+// administradores; a organização mantém ao menos um administrador.
+func (h *OrganizationHandler) UpdateMemberRole(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	if !requireAdmin(w, r, h.userRepo) {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req MemberRoleRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	user, err := h.userRepo.UpdateRole(orgID, id, req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(w, r)
+		case errors.Is(err, repository.ErrLastAdmin):
+			response.Error(w, r, http.StatusConflict, response.CodeConflict, "A organização precisa de ao menos um administrador")
+		default:
+			response.Internal(w, r)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, user)
+}
+
+// ListInvites lista os convites ainda não aceitos. Restrito aos administradores.
+func (h *OrganizationHandler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	if !requireAdmin(w, r, h.userRepo) {
+		return
+	}
+
+	invites, err := h.inviteRepo.ListPending(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, InviteListResponse{Data: invites})
+}
+
+// CreateInvite convida uma pessoa a entrar na organização. O token do convite
+// só é retornado nesta resposta. Restrito aos administradores.
+func (h *OrganizationHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	if !requireAdmin(w, r, h.userRepo) {
+		return
+	}
+
+	var req InviteRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	invite, token, err := entity.NewOrganizationInvite(orgID, userID, req.Email, req.Role)
+	if err != nil {
+		logger.Error("Erro ao gerar token do convite", err)
+		response.Internal(w, r)
+		return
+	}
+
+	// Cada email pertence a um único usuário, e portanto a uma organização
+	if _, err := h.userRepo.GetByEmail(invite.Email); err == nil {
+		response.Error(w, r, http.StatusConflict, response.CodeConflict, "Email já cadastrado")
+		return
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		response.Internal(w, r)
+		return
+	}
+
+	if err := h.inviteRepo.Create(invite); err != nil {
+		if errors.Is(err, repository.ErrInvitePending) {
+			response.Error(w, r, http.StatusConflict, response.CodeConflict, "Já existe um convite pendente para este email")
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, InviteResponse{OrganizationInvite: invite, Token: token})
+}
+
+// DeleteInvite revoga um convite pendente. Restrito aos administradores.
+func (h *OrganizationHandler) DeleteInvite(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	if !requireAdmin(w, r, h.userRepo) {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.inviteRepo.Delete(orgID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.NoContent(w)
+}
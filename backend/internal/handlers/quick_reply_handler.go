@@ -0,0 +1,423 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/media"
+	"github.com/whatsapp/backend/internal/messaging"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/quickreplies"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+	"github.com/whatsapp/backend/internal/whatsapp"
+)
+
+// QuickReplyHandler gerencia a biblioteca de respostas rápidas e o envio
+// nas conversas
+type QuickReplyHandler struct {
+	replyRepo    *repository.QuickReplyRepository
+	fieldRepo    *repository.CustomFieldRepository
+	quickReplies *quickreplies.Service
+	media        *media.Service
+}
+
+// QuickReplyRequest representa o conteúdo de uma resposta rápida
+type QuickReplyRequest struct {
+	Shortcut string `json:"shortcut" validate:"required" doc:"Atalho digitado pelo atendente, como /preco; a barra é opcional"`
+	Title    string `json:"title" validate:"required"`
+	Body     string `json:"body" doc:"Texto com variáveis {{lead.name}}, {{lead.first_name}}, {{lead.phone}}, {{lead.email}}, {{lead.source}}, {{lead.status}}, {{lead.stage}}, {{lead.custom.<chave>}}, {{agent.name}}, {{agent.first_name}} e {{agent.email}}"`
+	Scope    string `json:"scope" doc:"organization (padrão) compartilha a resposta com a organização; personal a deixa visível só ao atendente. Usado apenas na criação"`
+}
+
+// QuickReplySendRequest representa o envio de uma resposta rápida na conversa
+type QuickReplySendRequest struct {
+	QuickReplyID int64   `json:"quick_reply_id" validate:"required"`
+	Body         *string `json:"body,omitempty" doc:"Texto editado pelo atendente, no lugar do salvo; as variáveis também são preenchidas"`
+}
+
+// QuickReplyAttachmentForm documenta o formulário de anexo de uma resposta rápida
+type QuickReplyAttachmentForm struct {
+	File []byte `json:"file" validate:"required" doc:"Arquivo de até 16 MB em um tipo aceito pelo WhatsApp: imagem, áudio, vídeo, documento ou figurinha"`
+}
+
+// NewQuickReplyHandler cria uma nova instância do manipulador de respostas rápidas
+func NewQuickReplyHandler(replyRepo *repository.QuickReplyRepository, fieldRepo *repository.CustomFieldRepository, quickReplies *quickreplies.Service, media *media.Service) *QuickReplyHandler {
+	return &QuickReplyHandler{
+		replyRepo:    replyRepo,
+		fieldRepo:    fieldRepo,
+		quickReplies: quickReplies,
+		media:        media,
+	}
+}
+
+// List retorna as respostas da organização e as pessoais do atendente, as
+// mais usadas primeiro. q busca no atalho e no título; scope filtra por
+// organization ou personal.
+func (h *QuickReplyHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	query := r.URL.Query()
+	filter := entity.QuickReplyFilter{
+		Query: strings.TrimPrefix(strings.ToLower(strings.TrimSpace(query.Get("q"))), "/"),
+		Scope: query.Get("scope"),
+	}
+	if filter.Scope != "" && !containsString(entity.QuickReplyScopes, filter.Scope) {
+		response.ValidationError(w, r, []response.FieldError{{Field: "scope", Code: "invalid", Message: "Use organization ou personal"}})
+		return
+	}
+
+	replies, err := h.replyRepo.List(orgID, userID, filter)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusOK, replies)
+}
+
+// Create grava uma nova resposta rápida
+func (h *QuickReplyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	var req QuickReplyRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	q := entity.NewQuickReply(orgID, userID)
+	switch req.Scope {
+	case "", entity.QuickReplyScopeOrganization:
+	case entity.QuickReplyScopePersonal:
+		q.Scope = entity.QuickReplyScopePersonal
+		q.UserID = &userID
+	default:
+		response.ValidationError(w, r, []response.FieldError{{Field: "scope", Code: "invalid", Message: "Use organization ou personal"}})
+		return
+	}
+	if !h.applyRequest(w, r, q, req) {
+		return
+	}
+
+	if err := h.replyRepo.Create(q); err != nil {
+		if errors.Is(err, repository.ErrQuickReplyExists) {
+			quickReplyConflict(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusCreated, q)
+}
+
+// Get retorna uma resposta rápida
+func (h *QuickReplyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	q, ok := h.loadQuickReply(w, r)
+	if !ok {
+		return
+	}
+	response.JSON(w, http.StatusOK, q)
+}
+
+// Update substitui o atalho, o título e o texto da resposta; o escopo não muda
+func (h *QuickReplyHandler) Update(w http.ResponseWriter, r *http.Request) {
+	q, ok := h.loadQuickReply(w, r)
+	if !ok {
+		return
+	}
+
+	var req QuickReplyRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if !h.applyRequest(w, r, q, req) {
+		return
+	}
+
+	if err := h.replyRepo.Update(q); err != nil {
+		if errors.Is(err, repository.ErrQuickReplyExists) {
+			quickReplyConflict(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusOK, q)
+}
+
+// Delete remove a resposta rápida e os arquivos dos anexos
+func (h *QuickReplyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	q, ok := h.loadQuickReply(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	if err := h.quickReplies.Delete(q, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	response.NoContent(w)
+}
+
+// Preview retorna o texto da resposta preenchido com os campos do lead
+// informado e do atendente, com as variáveis sem valor
+func (h *QuickReplyHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	q, ok := h.loadQuickReply(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	leadID, err := strconv.ParseInt(r.URL.Query().Get("lead_id"), 10, 64)
+	if err != nil || leadID <= 0 {
+		response.ValidationError(w, r, []response.FieldError{{Field: "lead_id", Code: "required", Message: "Informe o ID do lead"}})
+		return
+	}
+
+	rendered, err := h.quickReplies.Preview(q, leadID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.ValidationError(w, r, []response.FieldError{{Field: "lead_id", Code: "not_found", Message: "Lead não encontrado"}})
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusOK, rendered)
+}
+
+// AddAttachment anexa um arquivo à resposta, recebido em multipart no campo
+// file. O arquivo é enviado depois do texto, na ordem dos anexos.
+func (h *QuickReplyHandler) AddAttachment(w http.ResponseWriter, r *http.Request) {
+	q, ok := h.loadQuickReply(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, quickreplies.MaxAttachmentSize+(1<<20))
+	if err := r.ParseMultipartForm(mediaMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(w, r, http.StatusRequestEntityTooLarge, response.CodeBodyTooLarge,
+				fmt.Sprintf("O anexo deve ter no máximo %d MB", quickreplies.MaxAttachmentSize>>20))
+			return
+		}
+		response.InvalidBody(w, r)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		response.ValidationError(w, r, []response.FieldError{{Field: "file", Code: "required", Message: "Envie o arquivo no campo file"}})
+		return
+	}
+	defer file.Close()
+
+	mimeType := header.Header.Get("Content-Type")
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		if mimeType, err = detectContentType(file); err != nil {
+			response.InvalidBody(w, r)
+			return
+		}
+	}
+
+	a, err := h.quickReplies.AddAttachment(r.Context(), q, file, header.Filename, mimeType, header.Size)
+	if err != nil {
+		var tooLarge *whatsapp.MediaTooLargeError
+		switch {
+		case errors.Is(err, whatsapp.ErrUnsupportedMedia):
+			response.ValidationError(w, r, []response.FieldError{{Field: "file", Code: "unsupported_type",
+				Message: fmt.Sprintf("Tipo de arquivo %s não aceito pelo WhatsApp; use um de: %s", mimeType, strings.Join(whatsapp.SupportedMediaTypes(), ", "))}})
+		case errors.As(err, &tooLarge):
+			response.ValidationError(w, r, []response.FieldError{{Field: "file", Code: "too_large", Message: "O arquivo excede o limite: " + tooLarge.Error()}})
+		case errors.Is(err, quickreplies.ErrAttachmentTooLarge):
+			response.ValidationError(w, r, []response.FieldError{{Field: "file", Code: "too_large",
+				Message: fmt.Sprintf("O anexo deve ter no máximo %d MB", quickreplies.MaxAttachmentSize>>20)}})
+		case errors.Is(err, quickreplies.ErrTooManyAttachments):
+			response.Error(w, r, http.StatusConflict, response.CodeConflict,
+				fmt.Sprintf("A resposta rápida já tem %d anexos", quickreplies.MaxAttachments))
+		default:
+			response.Internal(w, r)
+		}
+		return
+	}
+	response.JSON(w, http.StatusCreated, a)
+}
+
+// DownloadAttachment entrega o arquivo de um anexo da resposta
+func (h *QuickReplyHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	q, ok := h.loadQuickReply(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "attachmentId")
+	if !ok {
+		return
+	}
+
+	a, file, err := h.quickReplies.OpenAttachment(r.Context(), q, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", a.MIMEType)
+	w.Header().Set("Content-Length", fmt.Sprint(a.Size))
+	if a.FileName != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", a.FileName))
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, file); err != nil {
+		logger.Error("Erro ao enviar anexo da resposta rápida", err)
+	}
+}
+
+// RemoveAttachment remove um anexo da resposta
+func (h *QuickReplyHandler) RemoveAttachment(w http.ResponseWriter, r *http.Request) {
+	q, ok := h.loadQuickReply(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "attachmentId")
+	if !ok {
+		return
+	}
+
+	if err := h.quickReplies.RemoveAttachment(q, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+	response.NoContent(w)
+}
+
+// Send envia uma resposta rápida ao lead da conversa: o texto com as
+// variáveis preenchidas e os anexos, somando um uso à resposta
+func (h *QuickReplyHandler) Send(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	var req QuickReplySendRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	messages, err := h.quickReplies.Send(quickreplies.SendRequest{
+		OrganizationID: orgID,
+		ConversationID: id,
+		UserID:         userID,
+		QuickReplyID:   req.QuickReplyID,
+		Body:           req.Body,
+	})
+	if err != nil {
+		var missing *messaging.MissingFieldsError
+		switch {
+		case errors.Is(err, quickreplies.ErrNotFound):
+			response.ValidationError(w, r, []response.FieldError{{Field: "quick_reply_id", Code: "not_found", Message: "Resposta rápida não encontrada"}})
+		case errors.As(err, &missing):
+			response.ValidationError(w, r, []response.FieldError{{Field: "body", Code: "missing_fields",
+				Message: "Sem valor para as variáveis: " + strings.Join(missing.Fields, ", ")}})
+		case errors.Is(err, quickreplies.ErrEmpty):
+			response.ValidationError(w, r, []response.FieldError{{Field: "body", Code: "required", Message: "A resposta não tem texto nem anexos"}})
+		case errors.Is(err, quickreplies.ErrTooLong):
+			response.ValidationError(w, r, []response.FieldError{{Field: "body", Code: "max",
+				Message: fmt.Sprintf("O texto preenchido deve ter no máximo %d caracteres", quickreplies.MaxBodyLength)}})
+		default:
+			writeSendError(w, r, err)
+		}
+		return
+	}
+
+	h.media.AttachURLs(messages...)
+	response.JSON(w, http.StatusCreated, messages)
+}
+
+// loadQuickReply busca a resposta da rota, respondendo 404 se não existir ou
+// for pessoal de outro atendente
+func (h *QuickReplyHandler) loadQuickReply(w http.ResponseWriter, r *http.Request) (*entity.QuickReply, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	q, err := h.replyRepo.GetByID(orgID, userID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+	return q, true
+}
+
+// applyRequest copia o corpo para a resposta e valida o atalho e as
+// variáveis, respondendo 422 se inválidos
+func (h *QuickReplyHandler) applyRequest(w http.ResponseWriter, r *http.Request, q *entity.QuickReply, req QuickReplyRequest) bool {
+	schema, ok := loadCustomFieldSchema(w, r, h.fieldRepo, q.OrganizationID)
+	if !ok {
+		return false
+	}
+
+	q.Shortcut = quickreplies.NormalizeShortcut(req.Shortcut)
+	q.Title = strings.TrimSpace(req.Title)
+	q.Body = strings.TrimSpace(req.Body)
+
+	if errs := quickreplies.Validate(q, schema); len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return false
+	}
+	return true
+}
+
+func quickReplyConflict(w http.ResponseWriter, r *http.Request) {
+	response.WriteProblem(w, r, response.NewProblem(http.StatusConflict, response.CodeConflict,
+		"Já existe uma resposta rápida com este atalho").WithErrors([]response.FieldError{{
+		Field:   "shortcut",
+		Code:    "duplicate",
+		Message: "Atalho já utilizado",
+	}}))
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+	"github.com/whatsapp/backend/internal/validation"
+)
+
+// decodeAndValidate lê o corpo JSON em dst e aplica as regras de validação.
+// Em caso de falha já responde com o problema adequado e retorna false.
+func decodeAndValidate(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	if err := validation.DecodeJSON(w, r, dst, validation.DefaultMaxBodyBytes); err != nil {
+		if errors.Is(err, validation.ErrBodyTooLarge) {
+			response.Error(w, r, http.StatusRequestEntityTooLarge, response.CodeBodyTooLarge,
+				"Corpo da requisição excede o tamanho máximo permitido")
+			return false
+		}
+		logger.Warning("Erro ao decodificar corpo da requisição", err.Error())
+		response.InvalidBody(w, r)
+		return false
+	}
+
+	if errs := validation.Struct(dst); len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return false
+	}
+
+	return true
+}
+
+// organizationID obtém a organização do usuário autenticado. Tokens emitidos
+// antes da criação das organizações não a possuem e recebem 403.
+func organizationID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	orgID, ok := auth.GetOrganizationID(r.Context())
+	if !ok {
+		response.Error(w, r, http.StatusForbidden, response.CodeForbidden,
+			"Usuário sem organização associada, faça login novamente")
+		return 0, false
+	}
+	return orgID, true
+}
+
+// pathID lê um parâmetro numérico da rota, respondendo 404 se inválido
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	if err != nil || id <= 0 {
+		response.NotFound(w, r)
+		return 0, false
+	}
+	return id, true
+}
+
+// requireAdmin verifica se o usuário autenticado administra a organização.
+// O papel é lido do banco, e não do token, para que uma alteração valha
+// imediatamente. Em caso de falha já responde e retorna false.
+func requireAdmin(w http.ResponseWriter, r *http.Request, users *repository.UserRepository) bool {
+	userID, _ := auth.GetUserID(r.Context())
+	user, err := users.GetByID(userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		response.Internal(w, r)
+		return false
+	}
+	if user == nil || !user.IsAdmin() {
+		response.Error(w, r, http.StatusForbidden, response.CodeForbidden,
+			"Apenas administradores da organização podem realizar esta operação")
+		return false
+	}
+	return true
+}
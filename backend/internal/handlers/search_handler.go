@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// Limites da busca textual
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 50
+	minSearchLength       = 2
+	maxSearchLength       = 200
+)
+
+// SearchHandler gerencia a busca textual em leads, notas e mensagens
+type SearchHandler struct {
+	searchRepo *repository.SearchRepository
+	leadRepo   *repository.LeadRepository
+}
+
+// SearchResponse representa uma página de resultados da busca
+type SearchResponse struct {
+	Data    []*entity.SearchHit `json:"data"`
+	Limit   int                 `json:"limit"`
+	Offset  int                 `json:"offset"`
+	HasMore bool                `json:"has_more"`
+}
+
+// NewSearchHandler cria uma nova instância do manipulador de busca
+func NewSearchHandler(searchRepo *repository.SearchRepository, leadRepo *repository.LeadRepository) *SearchHandler {
+	return &SearchHandler{
+		searchRepo: searchRepo,
+		leadRepo:   leadRepo,
+	}
+}
+
+// Search procura o texto de q nos leads, notas e mensagens da organização
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	query, errs := parseSearchQuery(r)
+	limit, offset, pageErrs := parseSearchPagination(r)
+	if errs = append(errs, pageErrs...); len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	// IDs de leads mesclados são resolvidos para o sobrevivente
+	if query.LeadID != nil {
+		lead, err := h.leadRepo.GetByID(orgID, *query.LeadID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.ValidationError(w, r, []response.FieldError{{Field: "lead_id", Code: "not_found", Message: "Lead não encontrado"}})
+				return
+			}
+			response.Internal(w, r)
+			return
+		}
+		query.LeadID = &lead.ID
+	}
+
+	hits, hasMore, err := h.searchRepo.Search(orgID, query, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, SearchResponse{
+		Data:    hits,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: hasMore,
+	})
+}
+
+// parseSearchQuery lê o texto, os tipos, o lead e o período da query string
+func parseSearchQuery(r *http.Request) (entity.SearchQuery, []response.FieldError) {
+	values := r.URL.Query()
+	query := entity.SearchQuery{
+		Text: strings.TrimSpace(values.Get("q")),
+	}
+
+	var errs []response.FieldError
+	switch n := utf8.RuneCountInString(query.Text); {
+	case n == 0:
+		errs = append(errs, response.FieldError{Field: "q", Code: "required", Message: "Informe o texto da busca"})
+	case n < minSearchLength || n > maxSearchLength:
+		errs = append(errs, response.FieldError{Field: "q", Code: "range", Message: fmt.Sprintf("Deve ter entre %d e %d caracteres", minSearchLength, maxSearchLength)})
+	}
+
+	types := queryList(values, "type")
+	if len(types) == 0 {
+		types = entity.SearchTypes
+	}
+	seen := make(map[string]bool, len(types))
+	for _, t := range types {
+		if !entity.IsValidSearchType(t) {
+			errs = append(errs, response.FieldError{Field: "type", Code: "oneof", Message: "Use um dos valores: " + strings.Join(entity.SearchTypes, ", ")})
+			break
+		}
+		if !seen[t] {
+			seen[t] = true
+			query.Types = append(query.Types, t)
+		}
+	}
+
+	if v := values.Get("lead_id"); v != "" {
+		if id, err := strconv.ParseInt(v, 10, 64); err == nil && id > 0 {
+			query.LeadID = &id
+		} else {
+			errs = append(errs, response.FieldError{Field: "lead_id", Code: "invalid", Message: "Informe o ID do lead"})
+		}
+	}
+
+	var err *response.FieldError
+	if query.From, err = queryTime(values, "from", false); err != nil {
+		errs = append(errs, *err)
+	}
+	if query.To, err = queryTime(values, "to", true); err != nil {
+		errs = append(errs, *err)
+	}
+	if query.From != nil && query.To != nil && !query.To.After(*query.From) {
+		errs = append(errs, response.FieldError{Field: "to", Code: "range", Message: "Deve ser posterior a from"})
+	}
+
+	return query, errs
+}
+
+// parseSearchPagination lê limit e offset com os limites da busca, menores
+// que os da listagem por causa do custo dos trechos destacados
+func parseSearchPagination(r *http.Request) (int, int, []response.FieldError) {
+	query := r.URL.Query()
+	limit := defaultSearchPageSize
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSearchPageSize {
+			return 0, 0, []response.FieldError{{Field: "limit", Code: "range", Message: fmt.Sprintf("Deve estar entre 1 e %d", maxSearchPageSize)}}
+		}
+		limit = n
+	}
+
+	_, offset, errs := parsePagination(query)
+	return limit, offset, errs
+}
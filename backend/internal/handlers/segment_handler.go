@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// Limites dos segmentos
+const (
+	maxSegments       = 200
+	segmentPreviewMax = 10
+)
+
+// SegmentHandler gerencia as rotas de segmentos salvos de leads
+type SegmentHandler struct {
+	segmentRepo *repository.SegmentRepository
+	leadRepo    *repository.LeadRepository
+	fieldRepo   *repository.CustomFieldRepository
+}
+
+// SegmentRequest representa os dados para criar ou alterar um segmento
+type SegmentRequest struct {
+	Name        string            `json:"name" validate:"required,max=100"`
+	Description string            `json:"description,omitempty" validate:"max=500"`
+	Filters     entity.LeadFilter `json:"filters" doc:"Mesmos critérios da listagem de leads; vazio seleciona todos os leads"`
+}
+
+// SegmentPreviewRequest representa um filtro avaliado sem ser salvo
+type SegmentPreviewRequest struct {
+	Filters entity.LeadFilter `json:"filters"`
+}
+
+// SegmentResponse representa um segmento com o número atual de leads
+type SegmentResponse struct {
+	*entity.Segment
+	LeadCount int `json:"lead_count"`
+}
+
+// SegmentListResponse lista os segmentos da organização
+type SegmentListResponse struct {
+	Data []*entity.Segment `json:"data"`
+}
+
+// SegmentPreviewResponse traz o total de leads do filtro e uma amostra
+type SegmentPreviewResponse struct {
+	Total int            `json:"total"`
+	Data  []*entity.Lead `json:"data"`
+}
+
+// NewSegmentHandler cria uma nova instância do manipulador de segmentos
+func NewSegmentHandler(segmentRepo *repository.SegmentRepository, leadRepo *repository.LeadRepository, fieldRepo *repository.CustomFieldRepository) *SegmentHandler {
+	return &SegmentHandler{
+		segmentRepo: segmentRepo,
+		leadRepo:    leadRepo,
+		fieldRepo:   fieldRepo,
+	}
+}
+
+// List retorna os segmentos da organização
+func (h *SegmentHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	segments, err := h.segmentRepo.ListByOrganization(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, SegmentListResponse{Data: segments})
+}
+
+// Create salva um segmento
+func (h *SegmentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	var req SegmentRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if !h.validateFilter(w, r, orgID, &req.Filters) {
+		return
+	}
+
+	segments, err := h.segmentRepo.ListByOrganization(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	if len(segments) >= maxSegments {
+		response.Error(w, r, http.StatusUnprocessableEntity, response.CodeValidationFailed,
+			fmt.Sprintf("A organização pode salvar no máximo %d segmentos", maxSegments))
+		return
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	segment := entity.NewSegment(orgID, userID, strings.TrimSpace(req.Name), req.Filters)
+	segment.Description = strings.TrimSpace(req.Description)
+
+	if err := h.segmentRepo.Create(segment); err != nil {
+		if errors.Is(err, repository.ErrSegmentExists) {
+			segmentConflict(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/leads/segments/%d", segment.ID))
+	response.JSON(w, http.StatusCreated, segment)
+}
+
+// Get retorna o segmento com o número de leads que o atendem agora
+func (h *SegmentHandler) Get(w http.ResponseWriter, r *http.Request) {
+	segment, ok := h.loadSegment(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := h.leadRepo.Count(segment.OrganizationID, segment.Filter)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, SegmentResponse{Segment: segment, LeadCount: count})
+}
+
+// Update altera o nome, a descrição e os filtros do segmento
+func (h *SegmentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	segment, ok := h.loadSegment(w, r)
+	if !ok {
+		return
+	}
+
+	var req SegmentRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if !h.validateFilter(w, r, segment.OrganizationID, &req.Filters) {
+		return
+	}
+
+	segment.Name = strings.TrimSpace(req.Name)
+	segment.Description = strings.TrimSpace(req.Description)
+	segment.Filter = req.Filters
+
+	if err := h.segmentRepo.Update(segment); err != nil {
+		if errors.Is(err, repository.ErrSegmentExists) {
+			segmentConflict(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, segment)
+}
+
+// Delete remove o segmento
+func (h *SegmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	segment, ok := h.loadSegment(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.segmentRepo.Delete(segment.OrganizationID, segment.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Leads avalia o segmento, retornando uma página dos leads que o atendem.
+// A ordenação salva pode ser substituída pelo parâmetro sort.
+func (h *SegmentHandler) Leads(w http.ResponseWriter, r *http.Request) {
+	segment, ok := h.loadSegment(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := segment.Filter
+	limit, offset, errs := parsePagination(query)
+	if sort := query.Get("sort"); sort != "" {
+		filter.Sort = sort
+		schema, ok := loadCustomFieldSchema(w, r, h.fieldRepo, segment.OrganizationID)
+		if !ok {
+			return
+		}
+		errs = append(errs, validateLeadFilter(&filter, schema)...)
+	}
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	leads, total, err := h.leadRepo.List(segment.OrganizationID, filter, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, LeadListResponse{
+		Data:   leads,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// Preview avalia filtros ainda não salvos, para a montagem de segmentos
+func (h *SegmentHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	var req SegmentPreviewRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if !h.validateFilter(w, r, orgID, &req.Filters) {
+		return
+	}
+
+	leads, total, err := h.leadRepo.List(orgID, req.Filters, segmentPreviewMax, 0)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, SegmentPreviewResponse{Total: total, Data: leads})
+}
+
+// validateFilter verifica os filtros do corpo conforme os campos
+// personalizados da organização, respondendo 422 se inválidos
+func (h *SegmentHandler) validateFilter(w http.ResponseWriter, r *http.Request, orgID int64, filter *entity.LeadFilter) bool {
+	schema, ok := loadCustomFieldSchema(w, r, h.fieldRepo, orgID)
+	if !ok {
+		return false
+	}
+	if errs := validateSegmentFilter(filter, schema); len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return false
+	}
+	return true
+}
+
+// loadSegment busca o segmento da rota na organização do usuário, respondendo 404 se não existir
+func (h *SegmentHandler) loadSegment(w http.ResponseWriter, r *http.Request) (*entity.Segment, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	segment, err := h.segmentRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+
+	return segment, true
+}
+
+// validateSegmentFilter aplica as regras da listagem e normaliza as listas
+// de etiquetas, que são comparadas pelo nome
+func validateSegmentFilter(filter *entity.LeadFilter, schema customfields.Schema) []response.FieldError {
+	errs := validateLeadFilter(filter, schema)
+	if tags, tagErrs := normalizeTagNames("tags", filter.Tags); len(tagErrs) > 0 {
+		errs = append(errs, tagErrs...)
+	} else {
+		filter.Tags = tags
+	}
+	if tags, tagErrs := normalizeTagNames("exclude_tags", filter.ExcludeTags); len(tagErrs) > 0 {
+		errs = append(errs, tagErrs...)
+	} else {
+		filter.ExcludeTags = tags
+	}
+	return errs
+}
+
+// loadSegmentReference busca um segmento informado no corpo da requisição,
+// respondendo 422 em segment_id se não existir na organização
+func loadSegmentReference(w http.ResponseWriter, r *http.Request, segmentRepo *repository.SegmentRepository, orgID, id int64) (*entity.Segment, bool) {
+	segment, err := segmentRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.ValidationError(w, r, []response.FieldError{{
+				Field:   "segment_id",
+				Code:    "not_found",
+				Message: "Segmento não encontrado",
+			}})
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+	return segment, true
+}
+
+func segmentConflict(w http.ResponseWriter, r *http.Request) {
+	response.WriteProblem(w, r, response.NewProblem(http.StatusConflict, response.CodeConflict,
+		"Já existe um segmento com este nome").WithErrors([]response.FieldError{{
+		Field:   "name",
+		Code:    "duplicate",
+		Message: "Nome já utilizado",
+	}}))
+}
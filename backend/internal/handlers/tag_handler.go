@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// maxTags limita as etiquetas por organização
+const maxTags = 500
+
+// TagHandler gerencia as rotas de etiquetas e sua aplicação aos leads
+type TagHandler struct {
+	tagRepo     *repository.TagRepository
+	leadRepo    *repository.LeadRepository
+	segmentRepo *repository.SegmentRepository
+	fieldRepo   *repository.CustomFieldRepository
+}
+
+// TagRequest representa o nome de uma etiqueta a criar ou renomear
+type TagRequest struct {
+	Name string `json:"name" validate:"required,max=50" doc:"Único na organização sem distinção de maiúsculas; não pode conter vírgulas"`
+}
+
+// Validate verifica o nome já sem espaços nas pontas
+func (req TagRequest) Validate() []response.FieldError {
+	if name := strings.TrimSpace(req.Name); req.Name != "" && !entity.IsValidTagName(name) {
+		return []response.FieldError{{Field: "name", Code: "invalid", Message: "O nome não pode ser vazio nem conter vírgulas"}}
+	}
+	return nil
+}
+
+// LeadTagsRequest representa o conjunto completo de etiquetas de um lead
+type LeadTagsRequest struct {
+	Tags []string `json:"tags" validate:"max=50" doc:"Substitui as etiquetas do lead; nomes inexistentes criam novas etiquetas"`
+}
+
+// BulkTagRequest representa a aplicação de etiquetas em massa. Os leads são
+// escolhidos por exatamente um entre lead_ids, segment_id e filters.
+type BulkTagRequest struct {
+	LeadIDs   []int64            `json:"lead_ids,omitempty" validate:"max=1000"`
+	SegmentID int64              `json:"segment_id,omitempty" doc:"Aplica aos leads do segmento salvo"`
+	Filters   *entity.LeadFilter `json:"filters,omitempty" doc:"Aplica aos leads que atendem aos filtros da listagem"`
+	Add       []string           `json:"add,omitempty" validate:"max=50" doc:"Etiquetas adicionadas; nomes inexistentes criam novas etiquetas"`
+	Remove    []string           `json:"remove,omitempty" validate:"max=50"`
+}
+
+// Validate exige uma única forma de seleção e ao menos uma alteração
+func (req BulkTagRequest) Validate() []response.FieldError {
+	var errs []response.FieldError
+
+	selectors := 0
+	if len(req.LeadIDs) > 0 {
+		selectors++
+	}
+	if req.SegmentID != 0 {
+		selectors++
+	}
+	if req.Filters != nil {
+		selectors++
+	}
+	if selectors != 1 {
+		errs = append(errs, response.FieldError{Field: "lead_ids", Code: "required", Message: "Informe exatamente um entre lead_ids, segment_id e filters"})
+	}
+
+	if len(req.Add) == 0 && len(req.Remove) == 0 {
+		errs = append(errs, response.FieldError{Field: "add", Code: "required", Message: "Informe etiquetas em add ou remove"})
+	}
+	for _, name := range req.Add {
+		for _, other := range req.Remove {
+			if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(other)) {
+				errs = append(errs, response.FieldError{Field: "remove", Code: "conflict", Message: fmt.Sprintf("A etiqueta %q não pode ser adicionada e removida", name)})
+			}
+		}
+	}
+	return errs
+}
+
+// TagListResponse lista as etiquetas da organização
+type TagListResponse struct {
+	Data []*entity.Tag `json:"data"`
+}
+
+// NewTagHandler cria uma nova instância do manipulador de etiquetas
+func NewTagHandler(tagRepo *repository.TagRepository, leadRepo *repository.LeadRepository, segmentRepo *repository.SegmentRepository, fieldRepo *repository.CustomFieldRepository) *TagHandler {
+	return &TagHandler{
+		tagRepo:     tagRepo,
+		leadRepo:    leadRepo,
+		segmentRepo: segmentRepo,
+		fieldRepo:   fieldRepo,
+	}
+}
+
+// List retorna as etiquetas da organização com o número de leads de cada uma
+func (h *TagHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	tags, err := h.tagRepo.ListByOrganization(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, TagListResponse{Data: tags})
+}
+
+// Create cria uma etiqueta
+func (h *TagHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	var req TagRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	tags, err := h.tagRepo.ListByOrganization(orgID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+	if len(tags) >= maxTags {
+		response.Error(w, r, http.StatusUnprocessableEntity, response.CodeValidationFailed,
+			fmt.Sprintf("A organização pode ter no máximo %d etiquetas", maxTags))
+		return
+	}
+
+	tag := entity.NewTag(orgID, strings.TrimSpace(req.Name))
+	if err := h.tagRepo.Create(tag); err != nil {
+		if errors.Is(err, repository.ErrTagExists) {
+			tagConflict(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/leads/tags/%d", tag.ID))
+	response.JSON(w, http.StatusCreated, tag)
+}
+
+// Update renomeia a etiqueta, atualizando os segmentos que a utilizam
+func (h *TagHandler) Update(w http.ResponseWriter, r *http.Request) {
+	tag, ok := h.loadTag(w, r)
+	if !ok {
+		return
+	}
+
+	var req TagRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	if err := h.tagRepo.Rename(tag, strings.TrimSpace(req.Name)); err != nil {
+		if errors.Is(err, repository.ErrTagExists) {
+			tagConflict(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, tag)
+}
+
+// Delete remove a etiqueta de todos os leads
+func (h *TagHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	tag, ok := h.loadTag(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.tagRepo.Delete(tag.OrganizationID, tag.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// SetLeadTags substitui as etiquetas do lead da rota e retorna o lead atualizado
+func (h *TagHandler) SetLeadTags(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req LeadTagsRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	names, errs := normalizeTagNames("tags", req.Tags)
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	lead, err := h.leadRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	if err := h.tagRepo.SetLeadTags(orgID, lead.ID, names); err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	if lead, err = h.leadRepo.GetByID(orgID, lead.ID); err != nil {
+		response.Internal(w, r)
+		return
+	}
+	response.JSON(w, http.StatusOK, lead)
+}
+
+// Bulk adiciona e remove etiquetas dos leads escolhidos por ID, segmento ou filtros
+func (h *TagHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	var req BulkTagRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	add, errs := normalizeTagNames("add", req.Add)
+	remove, removeErrs := normalizeTagNames("remove", req.Remove)
+	if errs = append(errs, removeErrs...); len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	var filter entity.LeadFilter
+	switch {
+	case req.SegmentID != 0:
+		segment, ok := loadSegmentReference(w, r, h.segmentRepo, orgID, req.SegmentID)
+		if !ok {
+			return
+		}
+		filter = segment.Filter
+	case req.Filters != nil:
+		schema, ok := loadCustomFieldSchema(w, r, h.fieldRepo, orgID)
+		if !ok {
+			return
+		}
+		filter = *req.Filters
+		if errs := validateLeadFilter(&filter, schema); len(errs) > 0 {
+			response.ValidationError(w, r, errs)
+			return
+		}
+	}
+
+	changes, err := h.tagRepo.Apply(orgID, filter, req.LeadIDs, add, remove)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, changes)
+}
+
+// loadTag busca a etiqueta da rota na organização do usuário, respondendo 404 se não existir
+func (h *TagHandler) loadTag(w http.ResponseWriter, r *http.Request) (*entity.Tag, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	tag, err := h.tagRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+
+	return tag, true
+}
+
+func tagConflict(w http.ResponseWriter, r *http.Request) {
+	response.WriteProblem(w, r, response.NewProblem(http.StatusConflict, response.CodeConflict,
+		"Já existe uma etiqueta com este nome").WithErrors([]response.FieldError{{
+		Field:   "name",
+		Code:    "duplicate",
+		Message: "Nome já utilizado",
+	}}))
+}
+
+// normalizeTagNames remove espaços nas pontas e repetições (sem distinção de
+// maiúsculas, mantendo a primeira grafia) e valida cada nome
+func normalizeTagNames(field string, names []string) ([]string, []response.FieldError) {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !entity.IsValidTagName(name) {
+			return nil, []response.FieldError{{
+				Field:   field,
+				Code:    "invalid",
+				Message: fmt.Sprintf("Os nomes devem ter de 1 a %d caracteres, sem vírgulas", entity.MaxTagNameLength),
+			}}
+		}
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized, nil
+}
@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// defaultWorklistTimezone define o "hoje" das listas de trabalho quando tz não é informado
+const defaultWorklistTimezone = "America/Sao_Paulo"
+
+// TaskHandler gerencia as tarefas dos leads e as listas de trabalho dos usuários
+type TaskHandler struct {
+	taskRepo *repository.TaskRepository
+	leadRepo *repository.LeadRepository
+	userRepo *repository.UserRepository
+}
+
+// TaskRequest representa os dados para criar ou alterar uma tarefa
+type TaskRequest struct {
+	Title       string     `json:"title" validate:"required,max=200"`
+	Description string     `json:"description,omitempty" validate:"max=2000"`
+	AssigneeID  *int64     `json:"assignee_id,omitempty" doc:"Usuário da organização; se omitido, quem cria a tarefa ou, na alteração, o responsável atual"`
+	DueAt       *time.Time `json:"due_at" validate:"required" doc:"Prazo em RFC 3339"`
+	RemindAt    *time.Time `json:"remind_at,omitempty" doc:"Horário do lembrete ao responsável; padrão é o prazo"`
+	Status      string     `json:"status,omitempty" validate:"oneof=open done" doc:"Padrão open; done registra a conclusão"`
+}
+
+// Validate verifica o horário do lembrete
+func (req TaskRequest) Validate() []response.FieldError {
+	if req.RemindAt != nil && req.DueAt != nil && req.RemindAt.After(*req.DueAt) {
+		return []response.FieldError{{Field: "remind_at", Code: "range", Message: "Não pode ser posterior a due_at"}}
+	}
+	return nil
+}
+
+// TaskListResponse lista tarefas
+type TaskListResponse struct {
+	Data []*entity.Task `json:"data"`
+}
+
+// NewTaskHandler cria uma nova instância do manipulador de tarefas
+func NewTaskHandler(taskRepo *repository.TaskRepository, leadRepo *repository.LeadRepository, userRepo *repository.UserRepository) *TaskHandler {
+	return &TaskHandler{
+		taskRepo: taskRepo,
+		leadRepo: leadRepo,
+		userRepo: userRepo,
+	}
+}
+
+// ListByLead retorna as tarefas do lead
+func (h *TaskHandler) ListByLead(w http.ResponseWriter, r *http.Request) {
+	lead, ok := loadRouteLead(w, r, h.leadRepo)
+	if !ok {
+		return
+	}
+
+	tasks, err := h.taskRepo.ListByLead(lead.OrganizationID, lead.ID)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, TaskListResponse{Data: tasks})
+}
+
+// Create registra uma tarefa no lead e notifica o responsável, se for outro usuário
+func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
+	lead, ok := loadRouteLead(w, r, h.leadRepo)
+	if !ok {
+		return
+	}
+
+	var req TaskRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	task := entity.NewTask(lead.OrganizationID, lead.ID, userID, strings.TrimSpace(req.Title), req.DueAt.UTC())
+	task.LeadName = lead.Name
+	task.AssigneeID = &userID
+
+	if req.AssigneeID != nil {
+		if !h.validateAssignee(w, r, lead.OrganizationID, *req.AssigneeID) {
+			return
+		}
+		task.AssigneeID = req.AssigneeID
+	}
+	applyTaskRequest(task, req)
+
+	if err := h.taskRepo.Create(task, assignmentNotification(task, userID)); err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/tasks/%d", task.ID))
+	response.JSON(w, http.StatusCreated, task)
+}
+
+// Get retorna uma tarefa
+func (h *TaskHandler) Get(w http.ResponseWriter, r *http.Request) {
+	task, ok := h.loadTask(w, r)
+	if !ok {
+		return
+	}
+
+	response.JSON(w, http.StatusOK, task)
+}
+
+// Update altera a tarefa. A troca de responsável é notificada ao novo
+// responsável, e a conclusão registra completed_at.
+func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
+	task, ok := h.loadTask(w, r)
+	if !ok {
+		return
+	}
+
+	var req TaskRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	var notification *entity.Notification
+	if req.AssigneeID != nil && (task.AssigneeID == nil || *task.AssigneeID != *req.AssigneeID) {
+		if !h.validateAssignee(w, r, task.OrganizationID, *req.AssigneeID) {
+			return
+		}
+		task.AssigneeID = req.AssigneeID
+		notification = assignmentNotification(task, userID)
+	}
+
+	task.Title = strings.TrimSpace(req.Title)
+	task.DueAt = req.DueAt.UTC()
+	applyTaskRequest(task, req)
+
+	if err := h.taskRepo.Update(task, userID, notification); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, task)
+}
+
+// Delete remove a tarefa
+func (h *TaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	task, ok := h.loadTask(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.taskRepo.Delete(task.OrganizationID, task.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Today retorna as tarefas abertas do usuário com prazo no dia de hoje,
+// no fuso horário de tz
+func (h *TaskHandler) Today(w http.ResponseWriter, r *http.Request) {
+	h.worklist(w, r, func(today time.Time) (*time.Time, *time.Time) {
+		tomorrow := today.AddDate(0, 0, 1)
+		return &today, &tomorrow
+	})
+}
+
+// Overdue retorna as tarefas abertas do usuário com prazo anterior a hoje,
+// no fuso horário de tz
+func (h *TaskHandler) Overdue(w http.ResponseWriter, r *http.Request) {
+	h.worklist(w, r, func(today time.Time) (*time.Time, *time.Time) {
+		return nil, &today
+	})
+}
+
+// worklist lista as tarefas abertas do usuário no intervalo calculado a
+// partir do início do dia atual
+func (h *TaskHandler) worklist(w http.ResponseWriter, r *http.Request, window func(today time.Time) (*time.Time, *time.Time)) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		tz = defaultWorklistTimezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		response.ValidationError(w, r, []response.FieldError{{Field: "tz", Code: "invalid", Message: "Informe um fuso horário IANA, como America/Sao_Paulo"}})
+		return
+	}
+
+	// Os prazos são gravados em UTC, assim como os limites do intervalo
+	now := time.Now().In(loc)
+	from, to := window(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).UTC())
+
+	userID, _ := auth.GetUserID(r.Context())
+	tasks, err := h.taskRepo.ListForAssignee(orgID, userID, from, to)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, TaskListResponse{Data: tasks})
+}
+
+// loadTask busca a tarefa da rota na organização do usuário, respondendo 404 se não existir
+func (h *TaskHandler) loadTask(w http.ResponseWriter, r *http.Request) (*entity.Task, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	task, err := h.taskRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+
+	return task, true
+}
+
+// validateAssignee verifica se o responsável pertence à organização,
+// respondendo 422 em assignee_id se não pertencer
+func (h *TaskHandler) validateAssignee(w http.ResponseWriter, r *http.Request, orgID, userID int64) bool {
+	user, err := h.userRepo.GetByID(userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		response.Internal(w, r)
+		return false
+	}
+	if user == nil || user.OrganizationID != orgID {
+		response.ValidationError(w, r, []response.FieldError{{Field: "assignee_id", Code: "not_found", Message: "Usuário não encontrado na organização"}})
+		return false
+	}
+	return true
+}
+
+// applyTaskRequest copia a descrição, o lembrete e o status do corpo. Os
+// horários são gravados em UTC, pois as colunas não guardam o fuso.
+func applyTaskRequest(task *entity.Task, req TaskRequest) {
+	task.Description = strings.TrimSpace(req.Description)
+
+	task.RemindAt = task.DueAt
+	if req.RemindAt != nil {
+		task.RemindAt = req.RemindAt.UTC()
+	}
+
+	status := req.Status
+	if status == "" {
+		status = entity.TaskStatusOpen
+	}
+	switch {
+	case status == entity.TaskStatusDone && task.Status != entity.TaskStatusDone:
+		now := time.Now().UTC()
+		task.CompletedAt = &now
+	case status == entity.TaskStatusOpen:
+		task.CompletedAt = nil
+	}
+	task.Status = status
+}
+
+// assignmentNotification avisa o responsável da tarefa, exceto quando ele
+// mesmo fez a atribuição
+func assignmentNotification(task *entity.Task, actorID int64) *entity.Notification {
+	if task.AssigneeID == nil || *task.AssigneeID == actorID {
+		return nil
+	}
+
+	n := entity.NewNotification(task.OrganizationID, *task.AssigneeID, entity.NotificationTaskAssigned,
+		"Nova tarefa atribuída a você")
+	n.Body = task.Title
+	n.Data["lead_id"] = task.LeadID
+	n.Data["lead_name"] = task.LeadName
+	n.Data["due_at"] = task.DueAt
+	n.Data["assigned_by"] = actorID
+	return n
+}
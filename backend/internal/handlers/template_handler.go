@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+	"github.com/whatsapp/backend/internal/templates"
+)
+
+// TemplateHandler gerencia os modelos de mensagem do WhatsApp
+type TemplateHandler struct {
+	templateRepo *repository.TemplateRepository
+	fieldRepo    *repository.CustomFieldRepository
+	leadRepo     *repository.LeadRepository
+	service      *templates.Service
+}
+
+// TemplateRequest representa o conteúdo de um modelo de mensagem
+type TemplateRequest struct {
+	Name            string                    `json:"name" validate:"required,max=512" doc:"Letras minúsculas, números e _; único por idioma na organização"`
+	Language        string                    `json:"language" validate:"required,max=15" doc:"Código do idioma do WhatsApp, como pt_BR"`
+	Category        string                    `json:"category" validate:"required,oneof=MARKETING UTILITY AUTHENTICATION"`
+	Header          string                    `json:"header" doc:"Cabeçalho de texto opcional, até 60 caracteres e um marcador"`
+	HeaderVariables []entity.TemplateVariable `json:"header_variables"`
+	Body            string                    `json:"body" validate:"required" doc:"Até 1024 caracteres com marcadores {{1}}, {{2}}..., sem começar ou terminar com um marcador"`
+	BodyVariables   []entity.TemplateVariable `json:"body_variables" doc:"Campo do lead e exemplo de cada marcador do corpo, em ordem"`
+	Footer          string                    `json:"footer" doc:"Rodapé opcional, até 60 caracteres e sem marcadores"`
+	Buttons         []entity.TemplateButton   `json:"buttons" doc:"Até 10 botões QUICK_REPLY, URL (no máximo 2) ou PHONE_NUMBER (no máximo 1)"`
+}
+
+// TemplateListResponse representa uma página de modelos
+type TemplateListResponse struct {
+	Data   []*entity.MessageTemplate `json:"data"`
+	Total  int                       `json:"total"`
+	Limit  int                       `json:"limit"`
+	Offset int                       `json:"offset"`
+}
+
+// NewTemplateHandler cria uma nova instância do manipulador de modelos
+func NewTemplateHandler(templateRepo *repository.TemplateRepository, fieldRepo *repository.CustomFieldRepository, leadRepo *repository.LeadRepository, service *templates.Service) *TemplateHandler {
+	return &TemplateHandler{
+		templateRepo: templateRepo,
+		fieldRepo:    fieldRepo,
+		leadRepo:     leadRepo,
+		service:      service,
+	}
+}
+
+// List retorna os modelos da organização, filtrados por status
+func (h *TemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, offset, errs := parsePagination(query)
+	status := query.Get("status")
+	if status != "" && !isTemplateStatus(status) {
+		errs = append(errs, response.FieldError{Field: "status", Code: "oneof", Message: "Use um dos valores: " + strings.Join(entity.TemplateStatuses, ", ")})
+	}
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	list, total, err := h.templateRepo.List(orgID, status, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, TemplateListResponse{
+		Data:   list,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// Create grava um novo modelo em rascunho
+func (h *TemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserID(r.Context())
+
+	var req TemplateRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	t := entity.NewMessageTemplate(orgID, userID)
+	if !h.applyRequest(w, r, t, req) {
+		return
+	}
+
+	if err := h.templateRepo.Create(t); err != nil {
+		if errors.Is(err, repository.ErrTemplateExists) {
+			response.Error(w, r, http.StatusConflict, response.CodeConflict, err.Error())
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/templates/%d", t.ID))
+	response.JSON(w, http.StatusCreated, t)
+}
+
+// Get retorna um modelo
+func (h *TemplateHandler) Get(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.loadTemplate(w, r)
+	if !ok {
+		return
+	}
+	response.JSON(w, http.StatusOK, t)
+}
+
+// Update substitui o conteúdo de um rascunho ou de um modelo rejeitado, que
+// volta a ser rascunho até ser enviado novamente
+func (h *TemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.loadTemplate(w, r)
+	if !ok {
+		return
+	}
+	if !t.IsEditable() {
+		response.Error(w, r, http.StatusConflict, response.CodeConflict, "Apenas rascunhos e modelos rejeitados podem ser alterados")
+		return
+	}
+
+	var req TemplateRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	// Modelos já cadastrados no provedor são editados por ID, sem trocar nome e idioma
+	if t.ExternalID != "" && (req.Name != t.Name || req.Language != t.Language) {
+		response.Error(w, r, http.StatusConflict, response.CodeConflict, "Nome e idioma de um modelo enviado ao WhatsApp não podem ser alterados")
+		return
+	}
+
+	if !h.applyRequest(w, r, t, req) {
+		return
+	}
+	t.Status = entity.TemplateStatusDraft
+	t.RejectionReason = ""
+	t.UpdatedAt = time.Now()
+
+	if err := h.templateRepo.Update(t); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTemplateExists):
+			response.Error(w, r, http.StatusConflict, response.CodeConflict, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(w, r)
+		default:
+			response.Internal(w, r)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, t)
+}
+
+// Delete remove um modelo que ainda não foi enviado ao WhatsApp
+func (h *TemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.loadTemplate(w, r)
+	if !ok {
+		return
+	}
+	if t.ExternalID != "" {
+		response.Error(w, r, http.StatusConflict, response.CodeConflict, "Modelos enviados ao WhatsApp precisam ser removidos no gerenciador da Meta")
+		return
+	}
+
+	if err := h.templateRepo.Delete(t.OrganizationID, t.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Submit envia o modelo para aprovação no WhatsApp
+func (h *TemplateHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	t, err := h.service.Submit(orgID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(w, r)
+		case errors.Is(err, templates.ErrNotSubmittable), errors.Is(err, templates.ErrNoBusinessAccount):
+			response.Error(w, r, http.StatusConflict, response.CodeConflict, err.Error())
+		default:
+			providerError(w, r, err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, t)
+}
+
+// Sync atualiza o status de aprovação dos modelos com o WhatsApp
+func (h *TemplateHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Sync(orgID)
+	if err != nil {
+		if errors.Is(err, templates.ErrNoBusinessAccount) {
+			response.Error(w, r, http.StatusConflict, response.CodeConflict, err.Error())
+			return
+		}
+		providerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, result)
+}
+
+// Preview preenche o modelo com os campos do lead informado em lead_id
+func (h *TemplateHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.loadTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	leadID, err := strconv.ParseInt(r.URL.Query().Get("lead_id"), 10, 64)
+	if err != nil || leadID <= 0 {
+		response.ValidationError(w, r, []response.FieldError{{Field: "lead_id", Code: "required", Message: "Informe o ID do lead"}})
+		return
+	}
+
+	lead, err := h.leadRepo.GetByID(t.OrganizationID, leadID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.ValidationError(w, r, []response.FieldError{{Field: "lead_id", Code: "not_found", Message: "Lead não encontrado"}})
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, templates.Render(t, lead))
+}
+
+// applyRequest copia a requisição para o modelo e aplica as regras do
+// WhatsApp, respondendo 422 se houver problemas
+func (h *TemplateHandler) applyRequest(w http.ResponseWriter, r *http.Request, t *entity.MessageTemplate, req TemplateRequest) bool {
+	schema, ok := loadCustomFieldSchema(w, r, h.fieldRepo, t.OrganizationID)
+	if !ok {
+		return false
+	}
+
+	t.Name = strings.TrimSpace(req.Name)
+	t.Language = strings.TrimSpace(req.Language)
+	t.Category = req.Category
+	t.Header = strings.TrimSpace(req.Header)
+	t.HeaderVariables = nonNilVariables(req.HeaderVariables)
+	t.Body = strings.TrimSpace(req.Body)
+	t.BodyVariables = nonNilVariables(req.BodyVariables)
+	t.Footer = strings.TrimSpace(req.Footer)
+	t.Buttons = req.Buttons
+	if t.Buttons == nil {
+		t.Buttons = []entity.TemplateButton{}
+	}
+
+	if errs := templates.Validate(t, schema); len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return false
+	}
+	return true
+}
+
+// loadTemplate busca o modelo da rota na organização do usuário
+func (h *TemplateHandler) loadTemplate(w http.ResponseWriter, r *http.Request) (*entity.MessageTemplate, bool) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	t, err := h.templateRepo.GetByID(orgID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return nil, false
+		}
+		response.Internal(w, r)
+		return nil, false
+	}
+	return t, true
+}
+
+func nonNilVariables(variables []entity.TemplateVariable) []entity.TemplateVariable {
+	if variables == nil {
+		return []entity.TemplateVariable{}
+	}
+	return variables
+}
+
+func isTemplateStatus(status string) bool {
+	for _, s := range entity.TemplateStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
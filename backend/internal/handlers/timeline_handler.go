@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// TimelineHandler expõe o histórico de atividades dos leads
+type TimelineHandler struct {
+	activityRepo *repository.ActivityRepository
+	leadRepo     *repository.LeadRepository
+}
+
+// TimelineResponse representa uma página do histórico de um lead
+type TimelineResponse struct {
+	Data   []*entity.Activity `json:"data"`
+	Total  int                `json:"total"`
+	Limit  int                `json:"limit"`
+	Offset int                `json:"offset"`
+}
+
+// NewTimelineHandler cria uma nova instância do manipulador do histórico
+func NewTimelineHandler(activityRepo *repository.ActivityRepository, leadRepo *repository.LeadRepository) *TimelineHandler {
+	return &TimelineHandler{
+		activityRepo: activityRepo,
+		leadRepo:     leadRepo,
+	}
+}
+
+// Timeline retorna o histórico do lead em ordem cronológica, das atividades
+// mais recentes para as mais antigas (ou o inverso com order=asc). O
+// histórico de leads mesclados faz parte do histórico do sobrevivente.
+func (h *TimelineHandler) Timeline(w http.ResponseWriter, r *http.Request) {
+	lead, ok := loadRouteLead(w, r, h.leadRepo)
+	if !ok {
+		return
+	}
+
+	values := r.URL.Query()
+	limit, offset, errs := parsePagination(values)
+
+	var query repository.ActivityQuery
+	seen := make(map[string]bool)
+	for _, t := range queryList(values, "type") {
+		if !entity.IsValidActivityType(t) {
+			errs = append(errs, response.FieldError{Field: "type", Code: "oneof", Message: "Use um dos valores: " + strings.Join(entity.ActivityTypes, ", ")})
+			break
+		}
+		if !seen[t] {
+			seen[t] = true
+			query.Types = append(query.Types, t)
+		}
+	}
+
+	switch values.Get("order") {
+	case "", "desc":
+	case "asc":
+		query.Ascending = true
+	default:
+		errs = append(errs, response.FieldError{Field: "order", Code: "oneof", Message: "Use asc ou desc"})
+	}
+
+	if len(errs) > 0 {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	activities, total, err := h.activityRepo.ListByLead(lead.OrganizationID, lead.ID, query, limit, offset)
+	if err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, TimelineResponse{
+		Data:   activities,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/whatsapp/backend/internal/auth"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// MeResponse representa os dados do usuário autenticado
+type MeResponse struct {
+	UserID int64  `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+// Me retorna as informações do usuário autenticado
+func Me(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, response.CodeAuthRequired, "Autenticação necessária")
+		return
+	}
+	email, _ := auth.GetEmail(r.Context())
+
+	response.JSON(w, http.StatusOK, MeResponse{
+		UserID: userID,
+		Email:  email,
+	})
+}
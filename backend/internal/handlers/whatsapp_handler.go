@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/inbox"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/repository"
+	"github.com/whatsapp/backend/internal/response"
+	"github.com/whatsapp/backend/internal/validation"
+	"github.com/whatsapp/backend/internal/whatsapp"
+)
+
+// WhatsAppHandler recebe o webhook da WhatsApp Cloud API e gerencia o
+// número vinculado à organização
+type WhatsAppHandler struct {
+	cfg      whatsapp.Config
+	orgRepo  *repository.OrganizationRepository
+	userRepo *repository.UserRepository
+	phones   whatsapp.PhoneNumberProvider
+	inbox    *inbox.Service
+}
+
+// WhatsAppChannelRequest vincula um número da WhatsApp Cloud API à organização
+type WhatsAppChannelRequest struct {
+	PhoneNumberID     string `json:"phone_number_id" validate:"max=50" doc:"Phone number ID do número na Meta, que deve pertencer à conta comercial; vazio remove o vínculo"`
+	BusinessAccountID string `json:"business_account_id" validate:"max=50" doc:"ID da conta comercial (WABA), obrigatório com o número e necessário para os modelos de mensagem"`
+}
+
+// WhatsAppChannelResponse representa o número vinculado à organização
+type WhatsAppChannelResponse struct {
+	PhoneNumberID     string `json:"phone_number_id"`
+	BusinessAccountID string `json:"business_account_id"`
+	MessagingTier     string `json:"messaging_tier"`
+}
+
+// NewWhatsAppHandler cria uma nova instância do manipulador do WhatsApp
+func NewWhatsAppHandler(cfg whatsapp.Config, orgRepo *repository.OrganizationRepository, userRepo *repository.UserRepository, phones whatsapp.PhoneNumberProvider, inboxService *inbox.Service) *WhatsAppHandler {
+	return &WhatsAppHandler{
+		cfg:      cfg,
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+		phones:   phones,
+		inbox:    inboxService,
+	}
+}
+
+// VerifyWebhook responde ao desafio de verificação enviado pela Meta ao
+// cadastrar o webhook, conferindo o token configurado
+func (h *WhatsAppHandler) VerifyWebhook(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if h.cfg.VerifyToken == "" || query.Get("hub.mode") != "subscribe" || query.Get("hub.verify_token") != h.cfg.VerifyToken {
+		response.Error(w, r, http.StatusForbidden, response.CodeForbidden, "Token de verificação inválido")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, query.Get("hub.challenge"))
+}
+
+// ReceiveWebhook processa as mensagens e status enviados pela Meta. O corpo
+// precisa estar assinado com o App Secret; falhas ao gravar respondem 500
+// para que a notificação seja reenviada.
+func (h *WhatsAppHandler) ReceiveWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, validation.DefaultMaxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(w, r, http.StatusRequestEntityTooLarge, response.CodeBodyTooLarge,
+				"Corpo da requisição excede o tamanho máximo permitido")
+			return
+		}
+		response.InvalidBody(w, r)
+		return
+	}
+
+	if !whatsapp.VerifySignature(h.cfg.AppSecret, body, r.Header.Get(whatsapp.SignatureHeader)) {
+		logger.Warning("Webhook do WhatsApp com assinatura inválida")
+		response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidToken, "Assinatura inválida")
+		return
+	}
+
+	events, err := whatsapp.ParseWebhook(body)
+	if err != nil {
+		logger.Warning("Erro ao decodificar webhook do WhatsApp", err.Error())
+		response.InvalidBody(w, r)
+		return
+	}
+
+	if err := h.inbox.Process(events); err != nil {
+		response.Internal(w, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// channelResponse monta a resposta com o canal da organização
+func channelResponse(org *entity.Organization) WhatsAppChannelResponse {
+	return WhatsAppChannelResponse{
+		PhoneNumberID:     org.WhatsAppPhoneNumberID,
+		BusinessAccountID: org.WhatsAppBusinessAccountID,
+		MessagingTier:     org.WhatsAppMessagingTier,
+	}
+}
+
+// GetChannel retorna o número e a conta comercial do WhatsApp vinculados à organização
+func (h *WhatsAppHandler) GetChannel(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	org, err := h.orgRepo.GetByID(orgID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFound(w, r)
+			return
+		}
+		response.Internal(w, r)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, channelResponse(org))
+}
+
+// UpdateChannel vincula o número do WhatsApp à organização. As mensagens
+// recebidas pelo número passam a criar leads e conversas na organização, por
+// isso a conta comercial é consultada no provedor: o número precisa
+// pertencer a ela, e o nível de envio é o informado pelo provedor. Restrito
+// aos administradores.
+func (h *WhatsAppHandler) UpdateChannel(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := organizationID(w, r)
+	if !ok {
+		return
+	}
+	if !requireAdmin(w, r, h.userRepo) {
+		return
+	}
+
+	var req WhatsAppChannelRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	phoneNumberID := strings.TrimSpace(req.PhoneNumberID)
+	businessAccountID := strings.TrimSpace(req.BusinessAccountID)
+
+	if phoneNumberID != "" && businessAccountID == "" {
+		response.ValidationError(w, r, []response.FieldError{{Field: "business_account_id", Code: "required", Message: "Informe a conta comercial do número"}})
+		return
+	}
+	if phoneNumberID != "" && phoneNumberID == h.cfg.PhoneNumberID {
+		response.ValidationError(w, r, []response.FieldError{{Field: "phone_number_id", Code: "reserved", Message: "Este número é reservado pela plataforma"}})
+		return
+	}
+
+	tier := ""
+	if businessAccountID != "" {
+		numbers, err := h.phones.PhoneNumbers(r.Context(), businessAccountID)
+		if err != nil {
+			var apiErr *whatsapp.APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && apiErr.StatusCode != http.StatusTooManyRequests {
+				logger.Warning("Conta comercial do WhatsApp inacessível", map[string]interface{}{"business_account_id": businessAccountID, "error": err.Error()})
+				response.ValidationError(w, r, []response.FieldError{{Field: "business_account_id", Code: "not_found", Message: "Conta comercial não encontrada ou não compartilhada com a plataforma"}})
+				return
+			}
+			logger.Error("Erro ao consultar números da conta comercial", err)
+			providerError(w, r, err)
+			return
+		}
+
+		if phoneNumberID != "" {
+			found := false
+			for _, n := range numbers {
+				if n.ID == phoneNumberID {
+					found = true
+					tier = whatsapp.MessagingTier(n.MessagingLimitTier)
+					break
+				}
+			}
+			if !found {
+				response.ValidationError(w, r, []response.FieldError{{Field: "phone_number_id", Code: "not_found", Message: "O número não pertence à conta comercial informada"}})
+				return
+			}
+		}
+	}
+
+	org, err := h.orgRepo.SetWhatsAppChannel(orgID, phoneNumberID, businessAccountID, tier)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrPhoneNumberIDInUse), errors.Is(err, repository.ErrBusinessAccountInUse):
+			response.Error(w, r, http.StatusConflict, response.CodeConflict, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(w, r)
+		default:
+			response.Internal(w, r)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, channelResponse(org))
+}
+
+// providerError responde falhas de chamadas ao provedor do WhatsApp: 503 se
+// ele não estiver configurado e 502 com a mensagem do provedor se recusar a
+// operação. Outros erros resultam em 500.
+func providerError(w http.ResponseWriter, r *http.Request, err error) {
+	var apiErr *whatsapp.APIError
+	switch {
+	case errors.Is(err, whatsapp.ErrNotConfigured), errors.Is(err, whatsapp.ErrInvalidAPIURL):
+		response.Error(w, r, http.StatusServiceUnavailable, response.CodeProviderDisabled, "Provedor do WhatsApp não configurado")
+	case errors.As(err, &apiErr):
+		response.Error(w, r, http.StatusBadGateway, response.CodeProviderError, apiErr.Error())
+	default:
+		response.Internal(w, r)
+	}
+}
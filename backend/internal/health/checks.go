@@ -0,0 +1,48 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/whatsapp/backend/internal/whatsapp"
+	"github.com/whatsapp/backend/pkg/database"
+)
+
+// PostgresCheck verifica se o banco de dados responde ao ping
+func PostgresCheck(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// RedisCheck verifica se o Redis responde ao ping
+func RedisCheck(client *redis.Client) CheckFunc {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
+// MigrationsCheck verifica se todas as migrações conhecidas foram aplicadas
+func MigrationsCheck(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		current, err := database.CurrentMigrationVersion(ctx, db)
+		if err != nil {
+			return err
+		}
+
+		latest := database.LatestMigrationVersion()
+		if current < latest {
+			return fmt.Errorf("migrações pendentes: versão atual %d, esperada %d", current, latest)
+		}
+		return nil
+	}
+}
+
+// WhatsAppConfigCheck verifica se o provedor de mensagens está configurado
+func WhatsAppConfigCheck(cfg whatsapp.Config) CheckFunc {
+	return func(ctx context.Context) error {
+		return cfg.Validate()
+	}
+}
@@ -0,0 +1,138 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status possíveis de um componente ou do serviço como um todo
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+	// StatusDegraded indica a falha de um componente opcional, que não
+	// impede o serviço de receber tráfego
+	StatusDegraded = "degraded"
+)
+
+// ErrShuttingDown indica que a aplicação está encerrando e não deve receber tráfego
+var ErrShuttingDown = errors.New("aplicação em desligamento")
+
+// CheckFunc verifica a disponibilidade de uma dependência
+type CheckFunc func(ctx context.Context) error
+
+// ComponentStatus representa o resultado da verificação de um componente
+type ComponentStatus struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report representa o resultado agregado das verificações
+type Report struct {
+	Status     string                     `json:"status"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+}
+
+// Checker executa um conjunto de verificações nomeadas
+type Checker struct {
+	timeout      time.Duration
+	names        []string
+	checks       map[string]CheckFunc
+	optional     map[string]bool
+	shuttingDown atomic.Bool
+}
+
+// NewChecker cria um verificador cujas verificações respeitam o timeout informado
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout:  timeout,
+		checks:   make(map[string]CheckFunc),
+		optional: make(map[string]bool),
+	}
+}
+
+// Register adiciona uma verificação identificada pelo nome do componente
+func (c *Checker) Register(name string, check CheckFunc) {
+	if _, exists := c.checks[name]; !exists {
+		c.names = append(c.names, name)
+	}
+	c.checks[name] = check
+	delete(c.optional, name)
+}
+
+// RegisterOptional adiciona uma verificação cuja falha apenas degrada o
+// serviço, sem retirá-lo do balanceamento
+func (c *Checker) RegisterOptional(name string, check CheckFunc) {
+	c.Register(name, check)
+	c.optional[name] = true
+}
+
+// MarkShuttingDown faz com que as próximas verificações falhem, retirando a
+// instância do balanceamento enquanto as requisições em andamento são drenadas
+func (c *Checker) MarkShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Run executa todas as verificações em paralelo e agrega o resultado
+func (c *Checker) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	report := Report{
+		Status:     StatusOK,
+		CheckedAt:  time.Now().UTC(),
+		Components: make(map[string]ComponentStatus, len(c.names)+1),
+	}
+
+	if c.shuttingDown.Load() {
+		report.Status = StatusError
+		report.Components["lifecycle"] = ComponentStatus{
+			Status: StatusError,
+			Error:  ErrShuttingDown.Error(),
+		}
+		return report
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for _, name := range c.names {
+		wg.Add(1)
+		go func(name string, check CheckFunc, optional bool) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := check(ctx)
+			result := ComponentStatus{
+				Status:    StatusOK,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status = StatusError
+				if optional {
+					result.Status = StatusDegraded
+				}
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			report.Components[name] = result
+			switch {
+			case result.Status == StatusError:
+				report.Status = StatusError
+			case result.Status == StatusDegraded && report.Status == StatusOK:
+				report.Status = StatusDegraded
+			}
+			mu.Unlock()
+		}(name, c.checks[name], c.optional[name])
+	}
+
+	wg.Wait()
+	return report
+}
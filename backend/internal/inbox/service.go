@@ -0,0 +1,184 @@
+// Package inbox processa as notificações recebidas do WhatsApp: grava as
+// mensagens dos leads, conduz os fluxos do chatbot, executa as regras de
+// automação e distribui os novos leads entre os atendentes
+package inbox
+
+import (
+	"database/sql"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/whatsapp"
+	"github.com/whatsapp/backend/pkg/phone"
+)
+
+// OrganizationFinder resolve a organização dona do número que recebeu a mensagem
+type OrganizationFinder interface {
+	GetByWhatsAppPhoneNumberID(phoneNumberID string) (*entity.Organization, error)
+}
+
+// MessageStore grava as mensagens recebidas e os status de entrega
+type MessageStore interface {
+	ReceiveInbound(organizationID int64, phone, name string, message *entity.Message) (*entity.InboundResult, error)
+	UpdateMessageStatus(organizationID int64, externalID, status string) error
+}
+
+// Assigner distribui leads entre os atendentes
+type Assigner interface {
+	GetSettings(organizationID int64) (*entity.AssignmentSettings, error)
+	Assign(organizationID, leadID, conversationID int64, skill string) (int64, error)
+}
+
+// Automator executa as regras de automação sobre as mensagens gravadas
+type Automator interface {
+	HandleInbound(result *entity.InboundResult)
+}
+
+// Chatbot conduz os fluxos de qualificação em andamento nas conversas
+type Chatbot interface {
+	HandleInbound(result *entity.InboundResult) bool
+	Active(organizationID, conversationID int64) bool
+}
+
+// Service processa as notificações do webhook do WhatsApp
+type Service struct {
+	organizations OrganizationFinder
+	messages      MessageStore
+	assigner      Assigner
+	automation    Automator
+	chatbot       Chatbot
+}
+
+// NewService cria uma nova instância do serviço de entrada de mensagens
+func NewService(organizations OrganizationFinder, messages MessageStore, assigner Assigner, automation Automator, chatbot Chatbot) *Service {
+	return &Service{
+		organizations: organizations,
+		messages:      messages,
+		assigner:      assigner,
+		automation:    automation,
+		chatbot:       chatbot,
+	}
+}
+
+// Process grava as mensagens e status da notificação. Mensagens de números
+// sem organização vinculada ou de remetentes com telefone inválido são
+// descartadas com um aviso no log; o erro retornado indica uma falha ao
+// gravar, para que o provedor reenvie a notificação.
+func (s *Service) Process(events *whatsapp.WebhookEvents) error {
+	organizations := make(map[string]int64)
+	resolve := func(phoneNumberID string) (int64, error) {
+		if id, ok := organizations[phoneNumberID]; ok {
+			return id, nil
+		}
+		org, err := s.organizations.GetByWhatsAppPhoneNumberID(phoneNumberID)
+		if err == sql.ErrNoRows {
+			logger.Warning("Notificação do WhatsApp para número sem organização vinculada", phoneNumberID)
+			organizations[phoneNumberID] = 0
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		organizations[phoneNumberID] = org.ID
+		return org.ID, nil
+	}
+
+	for _, in := range events.Messages {
+		orgID, err := resolve(in.PhoneNumberID)
+		if err != nil {
+			return err
+		}
+		if orgID == 0 {
+			continue
+		}
+		if err := s.receive(orgID, in); err != nil {
+			return err
+		}
+	}
+
+	for _, st := range events.Statuses {
+		orgID, err := resolve(st.PhoneNumberID)
+		if err != nil {
+			return err
+		}
+		if orgID == 0 {
+			continue
+		}
+		if err := s.messages.UpdateMessageStatus(orgID, st.MessageID, st.Status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// receive grava a mensagem, entrega a resposta ao fluxo do chatbot em
+// andamento ou executa as regras de automação e, se o lead ainda não tiver
+// responsável nem estiver em um fluxo, distribui o lead conforme a
+// configuração da organização
+func (s *Service) receive(organizationID int64, in whatsapp.InboundMessage) error {
+	number, err := phone.FromWAID(in.From)
+	if err != nil {
+		logger.Warning("Mensagem do WhatsApp com remetente inválido", in.From)
+		return nil
+	}
+
+	message := &entity.Message{
+		Type:       in.Type,
+		Body:       in.Body,
+		ExternalID: in.ID,
+		CreatedAt:  in.Timestamp,
+		ReplyID:    in.ReplyID,
+	}
+	// O arquivo da mídia é baixado do provedor em segundo plano
+	if in.Media != nil {
+		message.Media = &entity.MessageMedia{
+			Status:          entity.MediaPending,
+			ProviderMediaID: in.Media.ID,
+			MIMEType:        in.Media.MIMEType,
+			FileName:        in.Media.FileName,
+			SHA256:          in.Media.SHA256,
+		}
+	}
+	result, err := s.messages.ReceiveInbound(organizationID, number, in.ProfileName, message)
+	if err != nil {
+		return err
+	}
+	if result.OptedOut {
+		logger.Info("Lead descadastrado por palavra-chave", map[string]interface{}{"organization_id": organizationID, "lead_id": result.Lead.ID})
+	}
+	if result.Duplicate {
+		return nil
+	}
+
+	// Respostas a um fluxo em andamento não passam pelas regras. As regras
+	// podem responder, etiquetar, atribuir o lead e iniciar fluxos antes da
+	// distribuição; um lead já atribuído por elas é mantido
+	if !s.chatbot.HandleInbound(result) {
+		s.automation.HandleInbound(result)
+	}
+
+	// O lead em qualificação só é distribuído quando o fluxo termina
+	if s.chatbot.Active(organizationID, result.Conversation.ID) {
+		return nil
+	}
+	if result.Lead.OwnerID != nil && result.Conversation.AssignedUserID != nil {
+		return nil
+	}
+
+	settings, err := s.assigner.GetSettings(organizationID)
+	if err != nil {
+		return err
+	}
+	if !settings.Enabled {
+		return nil
+	}
+
+	// A distribuição não desfaz a mensagem gravada: falhas ficam no log e o
+	// lead continua sem responsável até uma atribuição manual
+	skill := entity.NormalizeSkill(settings.RequiredSkill(result.Lead.Tags, in.Body))
+	_, err = s.assigner.Assign(organizationID, result.Lead.ID, result.Conversation.ID, skill)
+	if err != nil {
+		logger.Error("Erro ao distribuir lead recebido pelo WhatsApp", err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package leadexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// Colunas disponíveis na exportação
+const (
+	ColumnID            = "id"
+	ColumnName          = "name"
+	ColumnPhone         = "phone"
+	ColumnEmail         = "email"
+	ColumnSource        = "source"
+	ColumnStatus        = "status"
+	ColumnStage         = "stage"
+	ColumnOwnerID       = "owner_id"
+	ColumnTags          = "tags"
+	ColumnLastMessageAt = "last_message_at"
+	ColumnCreatedAt     = "created_at"
+	ColumnUpdatedAt     = "updated_at"
+
+	// CustomFieldPrefix seleciona um campo personalizado, como "custom.modelo_carro"
+	CustomFieldPrefix = entity.CustomFieldPrefix
+)
+
+// Columns lista as colunas padrão do lead, na ordem usada quando nenhuma é informada
+var Columns = []string{
+	ColumnID, ColumnName, ColumnPhone, ColumnEmail, ColumnSource, ColumnStatus, ColumnStage,
+	ColumnOwnerID, ColumnTags, ColumnLastMessageAt, ColumnCreatedAt, ColumnUpdatedAt,
+}
+
+// DefaultColumns retorna as colunas padrão seguidas dos campos personalizados
+// definidos, no formato aceito de volta pela importação
+func DefaultColumns(defs []*entity.CustomFieldDefinition) []string {
+	columns := append([]string(nil), Columns...)
+	for _, def := range defs {
+		columns = append(columns, CustomFieldPrefix+def.Key)
+	}
+	return columns
+}
+
+// MaxColumns limita a quantidade de colunas selecionadas
+const MaxColumns = 100
+
+// spreadsheetTimeLayout é reconhecido como data pelo Excel e pelo LibreOffice
+const spreadsheetTimeLayout = "2006-01-02 15:04:05"
+
+// ValidateColumns verifica as colunas selecionadas
+func ValidateColumns(columns []string) []response.FieldError {
+	if len(columns) > MaxColumns {
+		return []response.FieldError{{
+			Field:   "columns",
+			Code:    "max",
+			Message: fmt.Sprintf("Selecione no máximo %d colunas", MaxColumns),
+		}}
+	}
+
+	var errs []response.FieldError
+	seen := make(map[string]bool)
+	for _, column := range columns {
+		switch {
+		case seen[column]:
+			errs = append(errs, response.FieldError{Field: "columns", Code: "duplicate", Message: fmt.Sprintf("Coluna %q repetida", column)})
+		case strings.HasPrefix(column, CustomFieldPrefix):
+			if !entity.IsValidCustomFieldKey(strings.TrimPrefix(column, CustomFieldPrefix)) {
+				errs = append(errs, response.FieldError{Field: "columns", Code: "invalid_column", Message: fmt.Sprintf("Campo personalizado %q inválido", column)})
+			}
+		case !isStandardColumn(column):
+			errs = append(errs, response.FieldError{Field: "columns", Code: "invalid_column", Message: fmt.Sprintf("Coluna %q desconhecida", column)})
+		}
+		seen[column] = true
+	}
+	return errs
+}
+
+func isStandardColumn(column string) bool {
+	for _, c := range Columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// value retorna o valor tipado da coluna, usado na exportação em JSON
+func value(lead *entity.Lead, column string) interface{} {
+	switch column {
+	case ColumnID:
+		return lead.ID
+	case ColumnName:
+		return lead.Name
+	case ColumnPhone:
+		return lead.Phone
+	case ColumnEmail:
+		return lead.Email
+	case ColumnSource:
+		return lead.Source
+	case ColumnStatus:
+		return lead.Status
+	case ColumnStage:
+		return lead.Stage
+	case ColumnOwnerID:
+		return lead.OwnerID
+	case ColumnTags:
+		if lead.Tags == nil {
+			return []string{}
+		}
+		return lead.Tags
+	case ColumnLastMessageAt:
+		return lead.LastMessageAt
+	case ColumnCreatedAt:
+		return lead.CreatedAt
+	case ColumnUpdatedAt:
+		return lead.UpdatedAt
+	}
+	return lead.CustomFields[strings.TrimPrefix(column, CustomFieldPrefix)]
+}
+
+// text retorna o valor da coluna como texto, usado em CSV e XLSX
+func text(lead *entity.Lead, column string) string {
+	switch v := value(lead, column).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case *int64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatInt(*v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(spreadsheetTimeLayout)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(spreadsheetTimeLayout)
+	case []string:
+		return strings.Join(v, ", ")
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ", ")
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,181 @@
+package leadexport
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/pkg/xlsx"
+)
+
+// Formatos de exportação suportados
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatJSON = "json"
+)
+
+// Formats lista os formatos aceitos
+var Formats = []string{FormatCSV, FormatXLSX, FormatJSON}
+
+// ErrUnsupportedFormat indica um formato de exportação desconhecido
+var ErrUnsupportedFormat = errors.New("formato de exportação não suportado")
+
+// ContentType retorna o tipo MIME do arquivo gerado no formato informado
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/json"
+	}
+}
+
+// encoder grava os leads um a um no formato escolhido
+type encoder interface {
+	Write(lead *entity.Lead) error
+	Close() error
+}
+
+// newEncoder cria o encoder do formato e escreve o cabeçalho, quando houver
+func newEncoder(format string, w io.Writer, columns []string) (encoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVEncoder(w, columns)
+	case FormatXLSX:
+		return newXLSXEncoder(w, columns)
+	case FormatJSON:
+		return newJSONEncoder(w, columns), nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// utf8BOM marca o CSV como UTF-8 para o Excel
+const utf8BOM = "\uFEFF"
+
+// csvEncoder grava CSV com BOM UTF-8 para que o Excel reconheça a acentuação
+type csvEncoder struct {
+	w       *csv.Writer
+	columns []string
+	record  []string
+}
+
+func newCSVEncoder(w io.Writer, columns []string) (*csvEncoder, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return nil, err
+	}
+	return &csvEncoder{w: cw, columns: columns, record: make([]string, len(columns))}, nil
+}
+
+func (e *csvEncoder) Write(lead *entity.Lead) error {
+	for i, column := range e.columns {
+		e.record[i] = escapeFormula(text(lead, column))
+	}
+	return e.w.Write(e.record)
+}
+
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// escapeFormula impede que valores digitados pelos contatos sejam
+// interpretados como fórmulas ao abrir o CSV em planilhas (CSV injection).
+// Telefones e números negativos são preservados.
+func escapeFormula(s string) string {
+	if s == "" {
+		return s
+	}
+	switch s[0] {
+	case '=', '@', '\t', '\r':
+		return "'" + s
+	case '+', '-':
+		if len(s) > 1 && (s[1] < '0' || s[1] > '9') {
+			return "'" + s
+		}
+	}
+	return s
+}
+
+// xlsxEncoder grava uma planilha com células de texto
+type xlsxEncoder struct {
+	w       *xlsx.Writer
+	columns []string
+	record  []string
+}
+
+func newXLSXEncoder(w io.Writer, columns []string) (*xlsxEncoder, error) {
+	xw, err := xlsx.NewWriter(w, "Leads")
+	if err != nil {
+		return nil, err
+	}
+	if err := xw.WriteRow(columns); err != nil {
+		return nil, err
+	}
+	return &xlsxEncoder{w: xw, columns: columns, record: make([]string, len(columns))}, nil
+}
+
+func (e *xlsxEncoder) Write(lead *entity.Lead) error {
+	for i, column := range e.columns {
+		e.record[i] = text(lead, column)
+	}
+	return e.w.WriteRow(e.record)
+}
+
+func (e *xlsxEncoder) Close() error {
+	return e.w.Close()
+}
+
+// jsonEncoder grava uma lista de objetos mantendo a ordem das colunas
+type jsonEncoder struct {
+	w       *bufio.Writer
+	columns []string
+	keys    [][]byte
+	count   int
+}
+
+func newJSONEncoder(w io.Writer, columns []string) *jsonEncoder {
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		keys[i], _ = json.Marshal(column)
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('[')
+	return &jsonEncoder{w: bw, columns: columns, keys: keys}
+}
+
+func (e *jsonEncoder) Write(lead *entity.Lead) error {
+	if e.count > 0 {
+		e.w.WriteByte(',')
+	}
+	e.count++
+
+	e.w.WriteString("\n{")
+	for i, column := range e.columns {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		data, err := json.Marshal(value(lead, column))
+		if err != nil {
+			return err
+		}
+		e.w.Write(e.keys[i])
+		e.w.WriteByte(':')
+		e.w.Write(data)
+	}
+	return e.w.WriteByte('}')
+}
+
+func (e *jsonEncoder) Close() error {
+	e.w.WriteString("\n]")
+	return e.w.Flush()
+}
@@ -0,0 +1,367 @@
+package leadexport
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/whatsapp/backend/config"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+	"github.com/whatsapp/backend/internal/signedurl"
+	"github.com/whatsapp/backend/internal/storage"
+)
+
+// Parâmetros do processamento em segundo plano
+const (
+	pollInterval  = 2 * time.Second
+	purgeInterval = 10 * time.Minute
+	// staleAfter define quando uma exportação em processamento é considerada abandonada
+	staleAfter = 30 * time.Minute
+	// generateTimeout limita o tempo de geração de um arquivo assíncrono
+	generateTimeout = 20 * time.Minute
+)
+
+// MaxAsyncRows limita a quantidade de leads de uma exportação em segundo plano
+const MaxAsyncRows = 200000
+
+// Erros da exportação de leads
+var (
+	ErrTooManyRows  = errors.New("a exportação excede o limite de linhas")
+	ErrInvalidLink  = errors.New("link de download inválido ou expirado")
+	ErrNotAvailable = errors.New("arquivo da exportação não está mais disponível")
+)
+
+// ExportRepository é uma interface para registrar e reservar exportações
+type ExportRepository interface {
+	Create(export *entity.LeadExport) error
+	ClaimNext(staleAfter time.Duration) (*entity.LeadExport, error)
+	Complete(export *entity.LeadExport, rowCount int, storageKey string, fileSize int64, expiresAt *time.Time) error
+	Fail(export *entity.LeadExport, rowCount int, message string) error
+	Release(export *entity.LeadExport) error
+	GetForDownload(id int64) (*entity.LeadExport, error)
+	RecordDownload(export *entity.LeadExport) error
+	ExpireFiles() ([]string, error)
+}
+
+// LeadSource fornece os leads a exportar, com os mesmos filtros da listagem
+type LeadSource interface {
+	Count(organizationID int64, filter entity.LeadFilter) (int, error)
+	Stream(ctx context.Context, organizationID int64, filter entity.LeadFilter, fn func(*entity.Lead) error) error
+}
+
+// Request descreve uma exportação solicitada por um usuário
+type Request struct {
+	OrganizationID int64
+	UserID         int64
+	Format         string
+	Columns        []string
+	Filter         entity.LeadFilter
+	IPAddress      string
+	UserAgent      string
+}
+
+// Validate verifica o formato e as colunas, aplicando as colunas padrão se nenhuma for informada
+func (r *Request) Validate() []response.FieldError {
+	var errs []response.FieldError
+	if !isFormat(r.Format) {
+		errs = append(errs, response.FieldError{Field: "format", Code: "oneof", Message: "Use csv, xlsx ou json"})
+	}
+	if len(r.Columns) == 0 {
+		r.Columns = Columns
+	}
+	return append(errs, ValidateColumns(r.Columns)...)
+}
+
+func isFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Service gera as exportações de leads, de forma síncrona (em fluxo na
+// própria resposta) ou em segundo plano, guardando o arquivo no
+// armazenamento, e controla os links de download
+type Service struct {
+	exports ExportRepository
+	leads   LeadSource
+	store   storage.BlobStore
+	signer  *signedurl.Signer
+	cfg     config.ExportConfig
+}
+
+// NewService cria uma nova instância do serviço de exportação
+func NewService(exports ExportRepository, leads LeadSource, store storage.BlobStore, cfg config.ExportConfig) *Service {
+	return &Service{
+		exports: exports,
+		leads:   leads,
+		store:   store,
+		signer:  signedurl.New(cfg.SigningSecret),
+		cfg:     cfg,
+	}
+}
+
+// MaxSyncRows retorna o limite de linhas da exportação síncrona
+func (s *Service) MaxSyncRows() int {
+	return s.cfg.MaxSyncRows
+}
+
+// StartSync registra uma exportação síncrona, recusando-a com ErrTooManyRows
+// se o filtro retornar mais leads do que o permitido para o fluxo direto
+func (s *Service) StartSync(req *Request) (*entity.LeadExport, error) {
+	total, err := s.leads.Count(req.OrganizationID, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if total > s.cfg.MaxSyncRows {
+		return nil, ErrTooManyRows
+	}
+
+	return s.create(req, false)
+}
+
+// WriteSync grava a exportação em w e registra o resultado na auditoria
+func (s *Service) WriteSync(ctx context.Context, w io.Writer, export *entity.LeadExport) error {
+	count, err := s.write(ctx, w, export)
+	if err != nil {
+		logger.Error("Erro ao exportar leads", err)
+		s.exports.Fail(export, count, "Exportação interrompida")
+		return err
+	}
+
+	return s.exports.Complete(export, count, "", 0, nil)
+}
+
+// Enqueue registra uma exportação para geração em segundo plano
+func (s *Service) Enqueue(req *Request) (*entity.LeadExport, error) {
+	total, err := s.leads.Count(req.OrganizationID, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if total > MaxAsyncRows {
+		return nil, ErrTooManyRows
+	}
+
+	export, err := s.create(req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Exportação de leads enfileirada", map[string]interface{}{
+		"export_id": export.ID,
+		"rows":      total,
+	})
+	return export, nil
+}
+
+func (s *Service) create(req *Request, async bool) (*entity.LeadExport, error) {
+	export := entity.NewLeadExport(req.OrganizationID, req.UserID, req.Format, req.Columns, req.Filter, async)
+	export.IPAddress = req.IPAddress
+	export.UserAgent = req.UserAgent
+
+	if err := s.exports.Create(export); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// write percorre os leads do filtro gravando-os no formato da exportação
+func (s *Service) write(ctx context.Context, w io.Writer, export *entity.LeadExport) (int, error) {
+	enc, err := newEncoder(export.Format, w, export.Columns)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = s.leads.Stream(ctx, export.OrganizationID, export.Filter, func(lead *entity.Lead) error {
+		count++
+		return enc.Write(lead)
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, enc.Close()
+}
+
+// DownloadURL retorna o link assinado do arquivo, ou "" se ele não estiver disponível
+func (s *Service) DownloadURL(export *entity.LeadExport) string {
+	if !export.IsDownloadable() {
+		return ""
+	}
+
+	expiresAt := time.Now().Add(s.cfg.LinkExpiry)
+	if export.ExpiresAt.Before(expiresAt) {
+		expiresAt = *export.ExpiresAt
+	}
+	return s.signer.Sign(downloadPath(export.ID), expiresAt)
+}
+
+// Download valida o link assinado e abre o arquivo da exportação,
+// contabilizando o download na trilha de auditoria. O arquivo deve ser fechado.
+func (s *Service) Download(ctx context.Context, id int64, query url.Values) (*entity.LeadExport, io.ReadCloser, error) {
+	if err := s.signer.Verify(downloadPath(id), query); err != nil {
+		return nil, nil, ErrInvalidLink
+	}
+
+	export, err := s.exports.GetForDownload(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrNotAvailable
+		}
+		return nil, nil, err
+	}
+	if !export.IsDownloadable() || export.StorageKey == "" {
+		return nil, nil, ErrNotAvailable
+	}
+
+	file, err := s.store.Get(ctx, export.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, ErrNotAvailable
+		}
+		logger.Error("Erro ao abrir arquivo da exportação de leads", err)
+		return nil, nil, err
+	}
+
+	s.exports.RecordDownload(export)
+	logger.Info("Download de exportação de leads", map[string]interface{}{
+		"export_id": export.ID,
+		"downloads": export.DownloadCount,
+	})
+	return export, file, nil
+}
+
+func downloadPath(id int64) string {
+	return fmt.Sprintf("/api/leads/exports/%d/download", id)
+}
+
+// Run processa a fila de exportações e descarta arquivos vencidos até o contexto ser cancelado
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var lastPurge time.Time
+	for {
+		for ctx.Err() == nil {
+			export, err := s.exports.ClaimNext(staleAfter)
+			if err != nil {
+				if !errors.Is(err, sql.ErrNoRows) {
+					logger.Error("Erro ao buscar exportações pendentes", err)
+				}
+				break
+			}
+			s.process(ctx, export)
+		}
+
+		if time.Since(lastPurge) >= purgeInterval {
+			lastPurge = time.Now()
+			s.purgeExpired(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeExpired remove do armazenamento os arquivos vencidos, mantendo o
+// registro das exportações
+func (s *Service) purgeExpired(ctx context.Context) {
+	keys, err := s.exports.ExpireFiles()
+	if err != nil {
+		return
+	}
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			logger.Error("Erro ao remover arquivo de exportação expirada", err)
+		}
+	}
+	if len(keys) > 0 {
+		logger.Info("Arquivos de exportação expirados descartados", map[string]interface{}{"count": len(keys)})
+	}
+}
+
+// process gera o arquivo da exportação em um arquivo temporário, para não
+// manter exportações grandes em memória, e o guarda no armazenamento até o
+// fim da retenção
+func (s *Service) process(ctx context.Context, export *entity.LeadExport) {
+	genCtx, cancel := context.WithTimeout(ctx, generateTimeout)
+	defer cancel()
+
+	tmp, err := os.CreateTemp("", "lead-export-*")
+	if err != nil {
+		logger.Error("Erro ao criar arquivo temporário da exportação de leads", err)
+		s.exports.Release(export)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	count, err := s.write(genCtx, tmp, export)
+	if err != nil {
+		if ctx.Err() != nil {
+			s.exports.Release(export)
+			return
+		}
+		logger.Error("Erro ao gerar exportação de leads", err)
+		s.exports.Fail(export, count, "Erro ao gerar arquivo da exportação")
+		return
+	}
+
+	key, size, err := s.save(genCtx, export, tmp)
+	if err != nil {
+		if ctx.Err() != nil {
+			s.exports.Release(export)
+			return
+		}
+		logger.Error("Erro ao guardar arquivo da exportação de leads", err)
+		s.exports.Fail(export, count, "Erro ao guardar arquivo da exportação")
+		return
+	}
+
+	expiresAt := time.Now().Add(s.cfg.Retention)
+	if err := s.exports.Complete(export, count, key, size, &expiresAt); err != nil {
+		_ = s.store.Delete(context.Background(), key)
+		return
+	}
+
+	logger.Info("Exportação de leads concluída", map[string]interface{}{
+		"export_id": export.ID,
+		"rows":      count,
+	})
+}
+
+// save envia o arquivo gerado ao armazenamento, retornando a chave e o tamanho
+func (s *Service) save(ctx context.Context, export *entity.LeadExport, file *os.File) (string, int64, error) {
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", 0, err
+	}
+	key := fmt.Sprintf("%d/exports/%s.%s", export.OrganizationID, hex.EncodeToString(random), export.Format)
+	if err := s.store.Put(ctx, key, file, size, ContentType(export.Format)); err != nil {
+		return "", 0, err
+	}
+	return key, size, nil
+}
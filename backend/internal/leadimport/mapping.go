@@ -0,0 +1,165 @@
+package leadimport
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Campos do lead que podem receber colunas da planilha
+const (
+	FieldName   = "name"
+	FieldPhone  = "phone"
+	FieldEmail  = "email"
+	FieldSource = "source"
+	FieldStatus = "status"
+	FieldStage  = "stage"
+
+	// CustomFieldPrefix identifica destinos de campos personalizados, como "custom.modelo_carro"
+	CustomFieldPrefix = entity.CustomFieldPrefix
+
+	// Ignore descarta a coluna
+	Ignore = "-"
+)
+
+// Fields lista os campos padrão do lead aceitos no mapeamento
+var Fields = []string{FieldName, FieldPhone, FieldEmail, FieldSource, FieldStatus, FieldStage}
+
+// aliases associa nomes comuns de colunas aos campos do lead
+var aliases = map[string]string{
+	"name":     FieldName,
+	"nome":     FieldName,
+	"cliente":  FieldName,
+	"contato":  FieldName,
+	"phone":    FieldPhone,
+	"telefone": FieldPhone,
+	"celular":  FieldPhone,
+	"whatsapp": FieldPhone,
+	"fone":     FieldPhone,
+	"email":    FieldEmail,
+	"e-mail":   FieldEmail,
+	"source":   FieldSource,
+	"origem":   FieldSource,
+	"fonte":    FieldSource,
+	"status":   FieldStatus,
+	"situacao": FieldStatus,
+	"stage":    FieldStage,
+	"etapa":    FieldStage,
+	"estagio":  FieldStage,
+}
+
+// Mapping associa o nome de cada coluna da planilha a um campo do lead
+type Mapping map[string]string
+
+// SuggestMapping propõe um mapeamento com base nos nomes das colunas. Além
+// dos nomes comuns dos campos padrão, reconhece a chave e o rótulo dos
+// campos personalizados definidos pela organização.
+func SuggestMapping(headers []string, schema customfields.Schema) Mapping {
+	m := make(Mapping, len(headers))
+	used := make(map[string]bool)
+
+	known := make(map[string]string, len(aliases)+2*len(schema))
+	for key, def := range schema {
+		for _, name := range []string{def.Label, key, CustomFieldPrefix + key} {
+			known[normalizeHeader(name)] = CustomFieldPrefix + key
+		}
+	}
+	// Os nomes dos campos padrão prevalecem sobre rótulos coincidentes
+	for alias, field := range aliases {
+		known[alias] = field
+	}
+
+	for _, h := range headers {
+		if h == "" {
+			continue
+		}
+		if field, ok := known[normalizeHeader(h)]; ok && !used[field] {
+			m[h] = field
+			used[field] = true
+			continue
+		}
+		m[h] = Ignore
+	}
+	return m
+}
+
+// Validate verifica se o mapeamento referencia colunas existentes, usa destinos
+// conhecidos, não repete destinos e inclui a coluna de telefone e as dos
+// campos personalizados obrigatórios
+func (m Mapping) Validate(headers []string, schema customfields.Schema) []response.FieldError {
+	var errs []response.FieldError
+	add := func(column, code, message string) {
+		errs = append(errs, response.FieldError{Field: "mapping." + column, Code: code, Message: message})
+	}
+
+	known := make(map[string]bool, len(headers))
+	for _, h := range headers {
+		known[h] = true
+	}
+
+	targets := make(map[string]string)
+	for column, target := range m {
+		if !known[column] {
+			add(column, "unknown_column", "Coluna não encontrada no arquivo")
+			continue
+		}
+		if target == Ignore || target == "" {
+			continue
+		}
+		if !isValidTarget(target) {
+			add(column, "unknown_field", fmt.Sprintf("Destino %q inválido; use %s ou custom.<chave>", target, strings.Join(Fields, ", ")))
+			continue
+		}
+		if other, dup := targets[target]; dup {
+			add(column, "duplicate_field", fmt.Sprintf("O campo %q já está mapeado para a coluna %q", target, other))
+			continue
+		}
+		targets[target] = column
+	}
+
+	if _, ok := targets[FieldPhone]; !ok {
+		errs = append(errs, response.FieldError{Field: "mapping", Code: "phone_required", Message: "Mapeie uma coluna para o campo phone"})
+	}
+	for _, key := range schema.Required() {
+		if _, ok := targets[CustomFieldPrefix+key]; !ok {
+			errs = append(errs, response.FieldError{
+				Field:   "mapping",
+				Code:    "required_field",
+				Message: fmt.Sprintf("Mapeie uma coluna para o campo obrigatório %s%s", CustomFieldPrefix, key),
+			})
+		}
+	}
+
+	return errs
+}
+
+// isValidTarget aceita campos padrão e campos personalizados
+func isValidTarget(target string) bool {
+	if strings.HasPrefix(target, CustomFieldPrefix) {
+		return entity.IsValidCustomFieldKey(strings.TrimPrefix(target, CustomFieldPrefix))
+	}
+	for _, f := range Fields {
+		if f == target {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeHeader remove acentos, espaços e caixa para comparar nomes de colunas
+func normalizeHeader(h string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(strings.TrimSpace(h))) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			b.WriteRune(r)
+		case r == ' ' || r == '_':
+			// ignorados para que "E mail" e "e_mail" casem com "email"
+		}
+	}
+	return b.String()
+}
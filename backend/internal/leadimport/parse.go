@@ -0,0 +1,133 @@
+package leadimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/whatsapp/backend/pkg/xlsx"
+)
+
+// Formatos de arquivo suportados
+const (
+	FileTypeCSV  = "csv"
+	FileTypeXLSX = "xlsx"
+)
+
+// Erros de leitura de arquivos
+var (
+	ErrUnsupportedFile = errors.New("formato de arquivo não suportado, envie CSV ou XLSX")
+	ErrEmptyFile       = errors.New("arquivo sem linhas de cabeçalho")
+)
+
+// utf8BOM é adicionado por algumas versões do Excel ao exportar CSV
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// DetectFileType identifica o formato pelo nome do arquivo
+func DetectFileType(fileName string) (string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv", ".txt":
+		return FileTypeCSV, nil
+	case ".xlsx":
+		return FileTypeXLSX, nil
+	default:
+		return "", ErrUnsupportedFile
+	}
+}
+
+// Sheet representa o conteúdo tabular de um arquivo importado
+type Sheet struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// Parse lê o arquivo e separa o cabeçalho das linhas de dados, descartando linhas vazias
+func Parse(fileType string, data []byte) (*Sheet, error) {
+	var (
+		records [][]string
+		err     error
+	)
+
+	switch fileType {
+	case FileTypeCSV:
+		records, err = parseCSV(data)
+	case FileTypeXLSX:
+		records, err = xlsx.ReadFirstSheet(data)
+	default:
+		return nil, ErrUnsupportedFile
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &Sheet{}
+	for _, record := range records {
+		if isBlank(record) {
+			continue
+		}
+		if sheet.Headers == nil {
+			sheet.Headers = trimAll(record)
+			continue
+		}
+		sheet.Rows = append(sheet.Rows, record)
+	}
+
+	if len(sheet.Headers) == 0 {
+		return nil, ErrEmptyFile
+	}
+	return sheet, nil
+}
+
+// parseCSV lê um CSV detectando o separador usado na primeira linha
+func parseCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = detectDelimiter(data)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("arquivo CSV inválido: %w", err)
+	}
+	return records, nil
+}
+
+// detectDelimiter escolhe entre vírgula, ponto e vírgula e tabulação conforme a
+// frequência na primeira linha. Planilhas exportadas no Brasil costumam usar ";".
+func detectDelimiter(data []byte) rune {
+	line := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		line = data[:i]
+	}
+
+	best, bestCount := ',', 0
+	for _, candidate := range []rune{',', ';', '\t'} {
+		if n := bytes.Count(line, []byte(string(candidate))); n > bestCount {
+			best, bestCount = candidate, n
+		}
+	}
+	return best
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func trimAll(record []string) []string {
+	out := make([]string, len(record))
+	for i, v := range record {
+		out[i] = strings.TrimSpace(v)
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package leadimport
+
+import (
+	"strings"
+
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/validation"
+	"github.com/whatsapp/backend/pkg/phone"
+)
+
+// leadRow reúne os campos padrão de uma linha para validação declarativa
+type leadRow struct {
+	Name   string `json:"name" validate:"max=100"`
+	Phone  string `json:"phone" validate:"required,phone"`
+	Email  string `json:"email" validate:"email,max=100"`
+	Source string `json:"source" validate:"max=50"`
+	Status string `json:"status" validate:"oneof=new contacted qualified won lost"`
+	Stage  string `json:"stage" validate:"max=50"`
+}
+
+// rowBuilder converte linhas da planilha em leads conforme o mapeamento
+type rowBuilder struct {
+	organizationID int64
+	schema         customfields.Schema
+	columns        map[string]int    // campo -> índice da coluna
+	columnNames    map[string]string // campo -> nome da coluna
+}
+
+// newRowBuilder pré-calcula os índices das colunas mapeadas
+func newRowBuilder(organizationID int64, headers []string, mapping Mapping, schema customfields.Schema) *rowBuilder {
+	b := &rowBuilder{
+		organizationID: organizationID,
+		schema:         schema,
+		columns:        make(map[string]int),
+		columnNames:    make(map[string]string),
+	}
+	for i, h := range headers {
+		target, ok := mapping[h]
+		if !ok || target == Ignore || target == "" {
+			continue
+		}
+		if _, dup := b.columns[target]; dup {
+			continue
+		}
+		b.columns[target] = i
+		b.columnNames[target] = h
+	}
+	return b
+}
+
+// value retorna o conteúdo da coluna mapeada para o campo
+func (b *rowBuilder) value(record []string, field string) string {
+	i, ok := b.columns[field]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// build valida a linha e cria o lead. rowNumber é a numeração exibida ao
+// usuário, contando o cabeçalho como linha 1.
+func (b *rowBuilder) build(rowNumber int, record []string) (*entity.Lead, []entity.ImportRowError) {
+	// Telefones inválidos seguem sem normalização para a validação apontar o erro
+	rawPhone := b.value(record, FieldPhone)
+	normalized, err := phone.Normalize(rawPhone)
+	if err != nil {
+		normalized = rawPhone
+	}
+
+	row := leadRow{
+		Name:   b.value(record, FieldName),
+		Phone:  normalized,
+		Email:  strings.ToLower(b.value(record, FieldEmail)),
+		Source: b.value(record, FieldSource),
+		Status: strings.ToLower(b.value(record, FieldStatus)),
+		Stage:  b.value(record, FieldStage),
+	}
+
+	// Campos personalizados definidos são convertidos para o tipo do campo;
+	// os demais são gravados como texto
+	customFields := make(map[string]interface{})
+	for field := range b.columns {
+		if key, ok := strings.CutPrefix(field, CustomFieldPrefix); ok {
+			if v := b.value(record, field); v != "" {
+				customFields[key] = v
+			}
+		}
+	}
+
+	fieldErrs := validation.Struct(row)
+	fieldErrs = append(fieldErrs, b.schema.NormalizeFields(customFields, CustomFieldPrefix)...)
+	if len(fieldErrs) > 0 {
+		errs := make([]entity.ImportRowError, 0, len(fieldErrs))
+		for _, fe := range fieldErrs {
+			errs = append(errs, entity.ImportRowError{
+				Row:     rowNumber,
+				Column:  b.columnNames[fe.Field],
+				Field:   fe.Field,
+				Code:    fe.Code,
+				Message: fe.Message,
+			})
+		}
+		return nil, errs
+	}
+
+	lead := entity.NewLead(b.organizationID, row.Name, row.Phone)
+	lead.Email = row.Email
+	lead.Source = row.Source
+	lead.Stage = row.Stage
+	lead.CustomFields = customFields
+	if row.Status != "" {
+		lead.Status = row.Status
+	}
+
+	return lead, nil
+}
@@ -0,0 +1,331 @@
+package leadimport
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// Parâmetros do processamento em segundo plano
+const (
+	batchSize    = 200
+	previewSize  = 20
+	pollInterval = 2 * time.Second
+	// staleAfter define quando um job em processamento sem progresso é considerado abandonado
+	staleAfter = 10 * time.Minute
+)
+
+// MaxRows limita a quantidade de linhas aceitas em uma importação
+const MaxRows = 50000
+
+// ErrTooManyRows indica que o arquivo excede MaxRows
+var ErrTooManyRows = fmt.Errorf("o arquivo excede o limite de %d linhas", MaxRows)
+
+// JobRepository é uma interface para persistir e reservar jobs de importação
+type JobRepository interface {
+	Create(job *entity.LeadImport) error
+	ClaimNext(staleAfter time.Duration) (*entity.LeadImport, error)
+	SaveBatch(job *entity.LeadImport, leads []*entity.Lead, onDuplicate func(index int, existingID int64)) error
+	Complete(job *entity.LeadImport) error
+	Fail(job *entity.LeadImport, message string) error
+	Release(job *entity.LeadImport) error
+}
+
+// DefinitionSource fornece as definições de campos personalizados da organização
+type DefinitionSource interface {
+	ListByOrganization(organizationID int64) ([]*entity.CustomFieldDefinition, error)
+}
+
+// MappingError indica que o mapeamento de colunas informado é inválido
+type MappingError struct {
+	Errors []response.FieldError
+}
+
+func (e *MappingError) Error() string {
+	return "mapeamento de colunas inválido"
+}
+
+// FileError indica que o arquivo enviado não pode ser lido como planilha
+type FileError struct {
+	Code string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// Preview é o resultado de uma importação em modo dry run
+type Preview struct {
+	FileName    string                  `json:"file_name"`
+	FileType    string                  `json:"file_type"`
+	Columns     []string                `json:"columns"`
+	Mapping     Mapping                 `json:"mapping"`
+	TotalRows   int                     `json:"total_rows"`
+	ValidRows   int                     `json:"valid_rows"`
+	InvalidRows int                     `json:"invalid_rows"`
+	Errors      []entity.ImportRowError `json:"errors"`
+	Leads       []*entity.Lead          `json:"leads"`
+}
+
+// Upload representa um arquivo enviado para importação
+type Upload struct {
+	OrganizationID int64
+	UserID         int64
+	FileName       string
+	Data           []byte
+	// Mapping vazio usa o mapeamento sugerido a partir dos nomes das colunas
+	Mapping Mapping
+}
+
+// Service coordena a pré-visualização e a importação de leads
+type Service struct {
+	jobs   JobRepository
+	fields DefinitionSource
+}
+
+// NewService cria uma nova instância do serviço de importação
+func NewService(jobs JobRepository, fields DefinitionSource) *Service {
+	return &Service{
+		jobs:   jobs,
+		fields: fields,
+	}
+}
+
+// prepared reúne o arquivo lido e o mapeamento resolvido de um upload
+type prepared struct {
+	fileType string
+	sheet    *Sheet
+	mapping  Mapping
+	schema   customfields.Schema
+}
+
+// prepare lê o arquivo e resolve o mapeamento que será aplicado
+func (s *Service) prepare(u *Upload) (*prepared, error) {
+	fileType, err := DetectFileType(u.FileName)
+	if err != nil {
+		return nil, &FileError{Code: "unsupported_file", Err: err}
+	}
+
+	sheet, err := Parse(fileType, u.Data)
+	if err != nil {
+		code := "invalid_file"
+		if errors.Is(err, ErrEmptyFile) {
+			code = "empty_file"
+		}
+		return nil, &FileError{Code: code, Err: err}
+	}
+	if len(sheet.Rows) > MaxRows {
+		return nil, &FileError{Code: "too_many_rows", Err: ErrTooManyRows}
+	}
+
+	schema, err := s.schema(u.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := u.Mapping
+	if len(mapping) == 0 {
+		mapping = SuggestMapping(sheet.Headers, schema)
+	}
+	if errs := mapping.Validate(sheet.Headers, schema); len(errs) > 0 {
+		return nil, &MappingError{Errors: errs}
+	}
+
+	return &prepared{fileType: fileType, sheet: sheet, mapping: mapping, schema: schema}, nil
+}
+
+// schema carrega as definições de campos personalizados da organização
+func (s *Service) schema(organizationID int64) (customfields.Schema, error) {
+	defs, err := s.fields.ListByOrganization(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	return customfields.NewSchema(defs), nil
+}
+
+// Preview valida todas as linhas sem gravar nada, retornando os erros por
+// linha e uma amostra dos leads que seriam criados
+func (s *Service) Preview(u *Upload) (*Preview, error) {
+	prep, err := s.prepare(u)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Preview{
+		FileName:  u.FileName,
+		FileType:  prep.fileType,
+		Columns:   prep.sheet.Headers,
+		Mapping:   prep.mapping,
+		TotalRows: len(prep.sheet.Rows),
+		Errors:    []entity.ImportRowError{},
+		Leads:     []*entity.Lead{},
+	}
+
+	builder := newRowBuilder(u.OrganizationID, prep.sheet.Headers, prep.mapping, prep.schema)
+	for i, record := range prep.sheet.Rows {
+		lead, errs := builder.build(i+2, record)
+		if len(errs) > 0 {
+			p.InvalidRows++
+			if len(p.Errors) < entity.MaxLeadImportErrors {
+				p.Errors = append(p.Errors, errs...)
+			}
+			continue
+		}
+		p.ValidRows++
+		if len(p.Leads) < previewSize {
+			p.Leads = append(p.Leads, lead)
+		}
+	}
+
+	return p, nil
+}
+
+// Enqueue valida o arquivo e o mapeamento e cria o job para processamento em segundo plano
+func (s *Service) Enqueue(u *Upload) (*entity.LeadImport, error) {
+	prep, err := s.prepare(u)
+	if err != nil {
+		return nil, err
+	}
+
+	job := entity.NewLeadImport(u.OrganizationID, u.UserID, u.FileName, prep.fileType, u.Data, prep.mapping)
+	job.TotalRows = len(prep.sheet.Rows)
+
+	if err := s.jobs.Create(job); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Importação de leads enfileirada", map[string]interface{}{
+		"import_id": job.ID,
+		"rows":      job.TotalRows,
+	})
+	return job, nil
+}
+
+// Run processa a fila de importações até o contexto ser cancelado
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Processa todos os jobs disponíveis antes de voltar a esperar
+		for ctx.Err() == nil {
+			job, err := s.jobs.ClaimNext(staleAfter)
+			if err != nil {
+				if !errors.Is(err, sql.ErrNoRows) {
+					logger.Error("Erro ao buscar importações pendentes", err)
+				}
+				break
+			}
+			s.process(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// process importa as linhas restantes do job em lotes, retomando a partir de ProcessedRows
+func (s *Service) process(ctx context.Context, job *entity.LeadImport) {
+	logger.Info("Processando importação de leads", map[string]interface{}{
+		"import_id": job.ID,
+		"from_row":  job.ProcessedRows,
+	})
+
+	sheet, err := Parse(job.FileType, job.FileData)
+	if err != nil {
+		logger.Error("Erro ao ler arquivo da importação", err)
+		s.jobs.Fail(job, err.Error())
+		return
+	}
+
+	// As definições são lidas a cada retomada, aplicando alterações feitas
+	// enquanto o job aguardava na fila
+	schema, err := s.schema(job.OrganizationID)
+	if err != nil {
+		// O job continua reservado e é retomado quando considerado abandonado
+		logger.Error("Erro ao carregar campos personalizados da importação", err)
+		return
+	}
+
+	builder := newRowBuilder(job.OrganizationID, sheet.Headers, Mapping(job.Mapping), schema)
+	for start := job.ProcessedRows; start < len(sheet.Rows); start += batchSize {
+		if ctx.Err() != nil {
+			s.jobs.Release(job)
+			return
+		}
+
+		end := start + batchSize
+		if end > len(sheet.Rows) {
+			end = len(sheet.Rows)
+		}
+
+		// Guarda o progresso confirmado para não registrar um lote que falhou
+		processed, created, failed, errCount := job.ProcessedRows, job.CreatedRows, job.FailedRows, len(job.Errors)
+
+		var leads []*entity.Lead
+		var rowNumbers []int
+		for i := start; i < end; i++ {
+			lead, errs := builder.build(i+2, sheet.Rows[i])
+			if len(errs) > 0 {
+				job.FailedRows++
+				job.AppendErrors(errs)
+				continue
+			}
+			lead.Source = defaultString(lead.Source, "import")
+			leads = append(leads, lead)
+			rowNumbers = append(rowNumbers, i+2)
+		}
+
+		job.ProcessedRows = end
+		job.CreatedRows += len(leads)
+		onDuplicate := func(index int, existingID int64) {
+			job.CreatedRows--
+			job.FailedRows++
+			job.AppendErrors([]entity.ImportRowError{{
+				Row:     rowNumbers[index],
+				Column:  builder.columnNames[FieldPhone],
+				Field:   FieldPhone,
+				Code:    "duplicate",
+				Message: fmt.Sprintf("Telefone já cadastrado no lead %d", existingID),
+			}})
+		}
+		if err := s.jobs.SaveBatch(job, leads, onDuplicate); err != nil {
+			job.ProcessedRows, job.CreatedRows, job.FailedRows = processed, created, failed
+			job.Errors = job.Errors[:errCount]
+			s.jobs.Fail(job, "Erro ao gravar leads importados")
+			return
+		}
+	}
+
+	if err := s.jobs.Complete(job); err != nil {
+		return
+	}
+
+	logger.Info("Importação de leads concluída", map[string]interface{}{
+		"import_id": job.ID,
+		"created":   job.CreatedRows,
+		"failed":    job.FailedRows,
+	})
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,169 @@
+// Package leadmerge combina leads duplicados em um único lead sobrevivente,
+// escolhendo o valor de cada campo por uma política ou por escolha explícita.
+package leadmerge
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// Políticas de escolha dos valores do lead resultante
+const (
+	// PolicySurvivor mantém os valores do sobrevivente, preenchendo os vazios com os dos mesclados
+	PolicySurvivor = "survivor"
+	// PolicyNewest usa o valor do lead atualizado mais recentemente que o possua
+	PolicyNewest = "newest"
+	// PolicyOldest usa o valor do lead criado há mais tempo que o possua
+	PolicyOldest = "oldest"
+)
+
+// Policies lista as políticas aceitas
+var Policies = []string{PolicySurvivor, PolicyNewest, PolicyOldest}
+
+// Campos do lead que podem ser escolhidos explicitamente
+const (
+	FieldName    = "name"
+	FieldPhone   = "phone"
+	FieldEmail   = "email"
+	FieldSource  = "source"
+	FieldStatus  = "status"
+	FieldStage   = "stage"
+	FieldOwnerID = "owner_id"
+)
+
+// Fields lista os campos escolhíveis; campos personalizados usam o prefixo custom.
+var Fields = []string{FieldName, FieldPhone, FieldEmail, FieldSource, FieldStatus, FieldStage, FieldOwnerID}
+
+// CustomFieldPrefix identifica a escolha de um campo personalizado, como custom.cpf
+const CustomFieldPrefix = entity.CustomFieldPrefix
+
+// IsValidField verifica se o campo pode receber uma escolha explícita
+func IsValidField(field string) bool {
+	if key := strings.TrimPrefix(field, CustomFieldPrefix); key != field {
+		return entity.IsValidCustomFieldKey(key)
+	}
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve calcula o lead resultante da mesclagem. Choices associa campos ao
+// ID do lead de onde o valor deve vir, prevalecendo sobre a política; os
+// demais campos recebem o primeiro valor preenchido na ordem da política.
+// Etiquetas são unidas, a criação é a mais antiga e a última mensagem, a
+// mais recente.
+func Resolve(survivor *entity.Lead, merged []*entity.Lead, policy string, choices map[string]int64) *entity.Lead {
+	all := append([]*entity.Lead{survivor}, merged...)
+	byID := make(map[int64]*entity.Lead, len(all))
+	for _, lead := range all {
+		byID[lead.ID] = lead
+	}
+	order := preferenceOrder(all, policy)
+
+	chosen := func(field string) (*entity.Lead, bool) {
+		lead, ok := byID[choices[field]]
+		return lead, ok
+	}
+	pick := func(field string, get func(*entity.Lead) string) string {
+		if lead, ok := chosen(field); ok {
+			return get(lead)
+		}
+		for _, lead := range order {
+			if v := get(lead); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	result := *survivor
+	result.Name = pick(FieldName, func(l *entity.Lead) string { return l.Name })
+	result.Phone = pick(FieldPhone, func(l *entity.Lead) string { return l.Phone })
+	result.Email = pick(FieldEmail, func(l *entity.Lead) string { return l.Email })
+	result.Source = pick(FieldSource, func(l *entity.Lead) string { return l.Source })
+	result.Status = pick(FieldStatus, func(l *entity.Lead) string { return l.Status })
+	result.Stage = pick(FieldStage, func(l *entity.Lead) string { return l.Stage })
+
+	result.OwnerID = nil
+	if lead, ok := chosen(FieldOwnerID); ok {
+		result.OwnerID = lead.OwnerID
+	} else {
+		for _, lead := range order {
+			if lead.OwnerID != nil {
+				result.OwnerID = lead.OwnerID
+				break
+			}
+		}
+	}
+
+	result.CustomFields = make(map[string]interface{})
+	for _, lead := range all {
+		for key := range lead.CustomFields {
+			if _, done := result.CustomFields[key]; done {
+				continue
+			}
+			if value, ok := pickCustomField(key, chosen, order); ok {
+				result.CustomFields[key] = value
+			}
+		}
+	}
+
+	tags := make(map[string]bool)
+	result.Tags = []string{}
+	for _, lead := range all {
+		for _, tag := range lead.Tags {
+			if !tags[tag] {
+				tags[tag] = true
+				result.Tags = append(result.Tags, tag)
+			}
+		}
+		if lead.CreatedAt.Before(result.CreatedAt) {
+			result.CreatedAt = lead.CreatedAt
+		}
+		if lead.LastMessageAt != nil && (result.LastMessageAt == nil || lead.LastMessageAt.After(*result.LastMessageAt)) {
+			result.LastMessageAt = lead.LastMessageAt
+		}
+	}
+	sort.Strings(result.Tags)
+	result.UpdatedAt = time.Now()
+
+	return &result
+}
+
+// pickCustomField escolhe o valor de um campo personalizado. Um lead
+// escolhido explicitamente que não possua o campo o remove do resultado.
+func pickCustomField(key string, chosen func(string) (*entity.Lead, bool), order []*entity.Lead) (interface{}, bool) {
+	if lead, ok := chosen(CustomFieldPrefix + key); ok {
+		value, exists := lead.CustomFields[key]
+		return value, exists
+	}
+	for _, lead := range order {
+		if value, ok := lead.CustomFields[key]; ok && value != nil && value != "" {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// preferenceOrder ordena os leads conforme a política, com o sobrevivente
+// primeiro em PolicySurvivor e como desempate nas demais
+func preferenceOrder(all []*entity.Lead, policy string) []*entity.Lead {
+	order := append([]*entity.Lead(nil), all...)
+	switch policy {
+	case PolicyNewest:
+		sort.SliceStable(order, func(i, j int) bool {
+			return order[i].UpdatedAt.After(order[j].UpdatedAt)
+		})
+	case PolicyOldest:
+		sort.SliceStable(order, func(i, j int) bool {
+			return order[i].CreatedAt.Before(order[j].CreatedAt)
+		})
+	}
+	return order
+}
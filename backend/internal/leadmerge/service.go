@@ -0,0 +1,60 @@
+package leadmerge
+
+import (
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// MaxMergedLeads limita quantos leads podem ser mesclados de uma vez no sobrevivente
+const MaxMergedLeads = 10
+
+// LeadMerger executa a mesclagem de forma atômica no banco de dados
+type LeadMerger interface {
+	Merge(organizationID, survivorID int64, mergedIDs []int64, resolve func(survivor *entity.Lead, merged []*entity.Lead) (*entity.Lead, error), audit *entity.AuditEntry) (*entity.Lead, error)
+}
+
+// Request descreve uma mesclagem solicitada por um usuário
+type Request struct {
+	OrganizationID int64
+	UserID         int64
+	SurvivorID     int64
+	LeadIDs        []int64
+	Policy         string
+	Choices        map[string]int64
+	IPAddress      string
+}
+
+// Service mescla leads registrando a operação na trilha de auditoria
+type Service struct {
+	leads LeadMerger
+}
+
+// NewService cria uma nova instância do serviço de mesclagem
+func NewService(leads LeadMerger) *Service {
+	return &Service{
+		leads: leads,
+	}
+}
+
+// Merge combina os leads da requisição no sobrevivente e retorna o lead
+// resultante. A auditoria guarda a política, as escolhas e o estado de todos
+// os leads antes da mesclagem, permitindo reconstituir os dados descartados.
+func (s *Service) Merge(req Request) (*entity.Lead, error) {
+	policy := req.Policy
+	if policy == "" {
+		policy = PolicySurvivor
+	}
+
+	audit := entity.NewAuditEntry(req.OrganizationID, req.UserID, entity.AuditLeadMerged, "lead", req.SurvivorID)
+	audit.IPAddress = req.IPAddress
+
+	resolve := func(survivor *entity.Lead, merged []*entity.Lead) (*entity.Lead, error) {
+		audit.Data["policy"] = policy
+		audit.Data["merged_ids"] = req.LeadIDs
+		audit.Data["choices"] = req.Choices
+		audit.Data["survivor"] = survivor
+		audit.Data["merged"] = merged
+		return Resolve(survivor, merged, policy, req.Choices), nil
+	}
+
+	return s.leads.Merge(req.OrganizationID, req.SurvivorID, req.LeadIDs, resolve, audit)
+}
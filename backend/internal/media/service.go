@@ -0,0 +1,282 @@
+// Package media baixa os arquivos das mídias recebidas pelo WhatsApp, guarda
+// os arquivos das conversas no armazenamento e os entrega por links
+// assinados com prazo de validade
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/whatsapp/backend/config"
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/signedurl"
+	"github.com/whatsapp/backend/internal/storage"
+	"github.com/whatsapp/backend/internal/whatsapp"
+)
+
+// Parâmetros do download das mídias recebidas
+const (
+	pollInterval = 5 * time.Second
+	batchSize    = 10
+	// lease reserva a mídia para a instância que a baixa
+	lease = 10 * time.Minute
+	// downloadTimeout limita o download e o armazenamento de um arquivo
+	downloadTimeout = 5 * time.Minute
+	maxAttempts     = 5
+	// retryBase é o intervalo da primeira nova tentativa, dobrado a cada falha
+	retryBase = time.Minute
+)
+
+// Erros do acesso às mídias
+var (
+	ErrInvalidLink  = errors.New("link da mídia inválido ou expirado")
+	ErrNotAvailable = errors.New("arquivo da mídia não está disponível")
+)
+
+// Repository reserva as mídias a baixar e grava o resultado
+type Repository interface {
+	GetByID(id int64) (*entity.MessageMedia, error)
+	ClaimPending(limit int, lease time.Duration) ([]*entity.MessageMedia, error)
+	MarkStored(media *entity.MessageMedia) error
+	Retry(id int64, at time.Time, reason string) error
+	Fail(id int64, reason string) error
+}
+
+// Service guarda e entrega os arquivos das mensagens com mídia
+type Service struct {
+	media      Repository
+	store      storage.BlobStore
+	downloader whatsapp.MediaDownloader
+	signer     *signedurl.Signer
+	linkExpiry time.Duration
+}
+
+// NewService cria uma nova instância do serviço de mídias
+func NewService(media Repository, store storage.BlobStore, downloader whatsapp.MediaDownloader, cfg config.MediaConfig) *Service {
+	return &Service{
+		media:      media,
+		store:      store,
+		downloader: downloader,
+		signer:     signedurl.New(cfg.SigningSecret),
+		linkExpiry: cfg.LinkExpiry,
+	}
+}
+
+// AttachURLs preenche o link temporário das mídias armazenadas das mensagens
+func (s *Service) AttachURLs(messages ...*entity.Message) {
+	expiresAt := time.Now().Add(s.linkExpiry)
+	for _, m := range messages {
+		if m.Media != nil && m.Media.Status == entity.MediaStored {
+			m.Media.URL = s.signer.Sign(downloadPath(m.Media.ID), expiresAt)
+		}
+	}
+}
+
+// Open valida o link assinado e abre o arquivo da mídia. O arquivo deve ser fechado.
+func (s *Service) Open(ctx context.Context, id int64, query url.Values) (*entity.MessageMedia, io.ReadCloser, error) {
+	if err := s.signer.Verify(downloadPath(id), query); err != nil {
+		return nil, nil, ErrInvalidLink
+	}
+
+	media, err := s.media.GetByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrNotAvailable
+		}
+		return nil, nil, err
+	}
+	if media.Status != entity.MediaStored {
+		return nil, nil, ErrNotAvailable
+	}
+
+	file, err := s.store.Get(ctx, media.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, ErrNotAvailable
+		}
+		logger.Error("Erro ao abrir arquivo da mídia", err)
+		return nil, nil, err
+	}
+	return media, file, nil
+}
+
+// Save grava no armazenamento o arquivo de uma mídia enviada, com Size
+// bytes, preenchendo a chave e o hash do conteúdo
+func (s *Service) Save(ctx context.Context, media *entity.MessageMedia, r io.Reader) error {
+	if err := s.put(ctx, media, r, media.Size); err != nil {
+		logger.Error("Erro ao armazenar arquivo da mídia", err)
+		return err
+	}
+	media.Status = entity.MediaStored
+	return nil
+}
+
+// Remove apaga o arquivo de uma mídia que não chegou a ser enviada
+func (s *Service) Remove(media *entity.MessageMedia) {
+	if media.StorageKey == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.store.Delete(ctx, media.StorageKey); err != nil {
+		logger.Error("Erro ao remover arquivo da mídia", err)
+	}
+}
+
+// Run baixa as mídias recebidas até que o contexto seja cancelado
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			pending, err := s.media.ClaimPending(batchSize, lease)
+			if err != nil || len(pending) == 0 {
+				break
+			}
+			for _, m := range pending {
+				s.download(ctx, m)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// download baixa o arquivo da mídia no provedor e o grava no armazenamento
+func (s *Service) download(ctx context.Context, media *entity.MessageMedia) {
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+
+	err := s.fetch(ctx, media)
+	if err == nil {
+		if err := s.media.MarkStored(media); err != nil {
+			s.Remove(media)
+		}
+		return
+	}
+
+	var apiErr *whatsapp.APIError
+	var tooLarge *whatsapp.MediaTooLargeError
+	switch {
+	case errors.As(err, &tooLarge), errors.As(err, &apiErr) && !whatsapp.IsTransient(err), media.Attempts >= maxAttempts:
+		logger.Warning("Mídia recebida não pôde ser baixada", map[string]interface{}{"media_id": media.ID, "error": err.Error()})
+		_ = s.media.Fail(media.ID, err.Error())
+	default:
+		_ = s.media.Retry(media.ID, time.Now().UTC().Add(retryDelay(media.Attempts)), err.Error())
+	}
+}
+
+func (s *Service) fetch(ctx context.Context, media *entity.MessageMedia) error {
+	file, err := s.downloader.DownloadMedia(ctx, media.ProviderMediaID)
+	if err != nil {
+		return err
+	}
+	defer file.Body.Close()
+
+	if file.MIMEType != "" {
+		media.MIMEType = file.MIMEType
+	}
+	if file.SHA256 != "" {
+		media.SHA256 = file.SHA256
+	}
+	expected := media.SHA256
+
+	body := io.LimitReader(file.Body, whatsapp.MaxMediaSize)
+	size := file.Size
+	if size <= 0 {
+		// Sem o tamanho informado, o arquivo é lido antes de gravar, já que
+		// o armazenamento S3 exige o tamanho do envio. Um byte além do
+		// limite indica um arquivo grande demais, que não é gravado truncado.
+		data, err := io.ReadAll(io.LimitReader(file.Body, whatsapp.MaxMediaSize+1))
+		if err != nil {
+			return err
+		}
+		if int64(len(data)) > whatsapp.MaxMediaSize {
+			return &whatsapp.MediaTooLargeError{MessageType: entity.MediaDocument, Limit: whatsapp.MaxMediaSize}
+		}
+		body, size = bytes.NewReader(data), int64(len(data))
+	}
+	media.Size = size
+
+	if err := s.put(ctx, media, body, size); err != nil {
+		return err
+	}
+	if expected != "" && !matchesHash(expected, media.SHA256) {
+		s.Remove(media)
+		return fmt.Errorf("hash do arquivo baixado não confere com o informado pelo provedor")
+	}
+	return nil
+}
+
+// put grava o conteúdo em uma nova chave, calculando o hash SHA-256 enquanto grava
+func (s *Service) put(ctx context.Context, media *entity.MessageMedia, r io.Reader, size int64) error {
+	key, err := newKey(media.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.New()
+	if err := s.store.Put(ctx, key, io.TeeReader(r, digest), size, media.MIMEType); err != nil {
+		return err
+	}
+	media.StorageKey = key
+	media.SHA256 = hexDigest(digest)
+	return nil
+}
+
+// newKey gera uma chave aleatória agrupada por organização e mês
+func newKey(organizationID int64) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d/%s/%s", organizationID, time.Now().UTC().Format("2006/01"), hex.EncodeToString(random)), nil
+}
+
+func hexDigest(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// matchesHash compara o hash informado pelo provedor, em hexadecimal ou
+// base64, com o hash hexadecimal calculado
+func matchesHash(expected, actual string) bool {
+	if expected == actual {
+		return true
+	}
+	raw, err := hex.DecodeString(actual)
+	if err != nil {
+		return false
+	}
+	return expected == base64.StdEncoding.EncodeToString(raw)
+}
+
+func downloadPath(id int64) string {
+	return fmt.Sprintf("/api/media/%d", id)
+}
+
+// retryDelay calcula o intervalo antes da próxima tentativa, dobrando a
+// cada tentativa feita
+func retryDelay(attempts int) time.Duration {
+	delay := retryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+	}
+	return delay
+}
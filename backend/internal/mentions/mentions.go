@@ -0,0 +1,60 @@
+// Package mentions encontra as menções a colegas de equipe (@) no texto das
+// notas. Um usuário é mencionado pelo email completo (@maria@empresa.com) ou
+// pela parte antes do @ (@maria).
+package mentions
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// pattern captura @ seguido de um identificador ou email, exigindo que o @
+// não faça parte de uma palavra (como em um email escrito no texto)
+var pattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}._%+-])@([\p{L}\p{N}._%+-]+(?:@[\p{L}\p{N}-]+(?:\.[\p{L}\p{N}-]+)+)?)`)
+
+// Handles retorna os identificadores mencionados, em minúsculas e sem repetição
+func Handles(text string) []string {
+	var handles []string
+	seen := make(map[string]bool)
+	for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+		// Pontuação no fim da frase não faz parte do identificador
+		handle := strings.ToLower(strings.TrimRight(match[1], "."))
+		if handle != "" && !seen[handle] {
+			seen[handle] = true
+			handles = append(handles, handle)
+		}
+	}
+	return handles
+}
+
+// Resolve retorna, em ordem crescente, os IDs dos usuários mencionados no
+// texto. Um identificador sem domínio menciona todos os usuários cujo email
+// comece por ele.
+func Resolve(text string, users []*entity.User) []int64 {
+	handles := Handles(text)
+	if len(handles) == 0 {
+		return []int64{}
+	}
+
+	matched := make(map[int64]bool)
+	for _, user := range users {
+		email := strings.ToLower(user.Email)
+		local, _, _ := strings.Cut(email, "@")
+		for _, handle := range handles {
+			if handle == email || handle == local {
+				matched[user.ID] = true
+				break
+			}
+		}
+	}
+
+	ids := make([]int64, 0, len(matched))
+	for id := range matched {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
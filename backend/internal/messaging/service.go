@@ -0,0 +1,392 @@
+// Package messaging envia as mensagens dos atendentes pelo WhatsApp,
+// respeitando a janela de atendimento de 24 horas do provedor
+package messaging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/templates"
+	"github.com/whatsapp/backend/internal/whatsapp"
+	"github.com/whatsapp/backend/pkg/phone"
+)
+
+// Prazos das chamadas ao provedor em cada envio
+const (
+	sendTimeout = 20 * time.Second
+	// mediaTimeout inclui a gravação do arquivo e o envio ao provedor
+	mediaTimeout = 5 * time.Minute
+)
+
+// Erros do envio de mensagens
+var (
+	ErrWindowClosed        = errors.New("a janela de atendimento de 24 horas está fechada")
+	ErrConversationClosed  = errors.New("a conversa está encerrada")
+	ErrTemplateNotApproved = errors.New("o modelo não está aprovado")
+	ErrNoPhoneNumber       = errors.New("organização sem número do WhatsApp vinculado")
+	ErrInvalidRecipient    = errors.New("telefone do lead inválido para o WhatsApp")
+	ErrOptedOut            = errors.New("o lead retirou o consentimento para mensagens no WhatsApp")
+	// ErrNotRecorded indica uma mensagem aceita pelo provedor que não pôde
+	// ser gravada; o envio não deve ser repetido
+	ErrNotRecorded = errors.New("mensagem enviada sem registro na conversa")
+)
+
+// MissingFieldsError indica campos vazios no lead usados pelas variáveis do
+// modelo; o modelo não é enviado com os valores de exemplo
+type MissingFieldsError struct {
+	Fields []string
+}
+
+func (e *MissingFieldsError) Error() string {
+	return "campos do lead sem valor para o modelo: " + strings.Join(e.Fields, ", ")
+}
+
+// ConversationStore busca as conversas e grava as mensagens enviadas
+type ConversationStore interface {
+	GetByID(organizationID, id int64) (*entity.Conversation, error)
+	OpenForLead(lead *entity.Lead) (*entity.Conversation, error)
+	RecordOutbound(message *entity.Message) error
+}
+
+// LeadFinder busca o lead da conversa
+type LeadFinder interface {
+	GetByID(organizationID, id int64) (*entity.Lead, error)
+}
+
+// TemplateFinder busca os modelos de mensagem
+type TemplateFinder interface {
+	GetByID(organizationID, id int64) (*entity.MessageTemplate, error)
+}
+
+// OrganizationFinder busca o número de WhatsApp vinculado à organização
+type OrganizationFinder interface {
+	GetByID(id int64) (*entity.Organization, error)
+}
+
+// ConsentChecker verifica o descadastro do lead no canal
+type ConsentChecker interface {
+	IsOptedOut(organizationID, leadID int64, channel string) (bool, error)
+}
+
+// MediaStore guarda os arquivos das mídias enviadas
+type MediaStore interface {
+	Save(ctx context.Context, media *entity.MessageMedia, r io.Reader) error
+	Remove(media *entity.MessageMedia)
+}
+
+// SendRequest é uma mensagem a enviar em uma conversa: texto livre em Text
+// ou o modelo TemplateID. Com Interactive, a mensagem leva botões ou uma
+// lista de opções e segue as regras do texto livre.
+type SendRequest struct {
+	OrganizationID int64
+	ConversationID int64
+	UserID         int64
+	Text           string
+	TemplateID     int64
+	Interactive    *whatsapp.Interactive
+}
+
+// MediaRequest é um arquivo a enviar em uma conversa, com Size bytes. O
+// arquivo é lido duas vezes: para o armazenamento e para o provedor.
+type MediaRequest struct {
+	OrganizationID int64
+	ConversationID int64
+	UserID         int64
+	File           io.ReadSeeker
+	FileName       string
+	MIMEType       string
+	Size           int64
+	Caption        string
+}
+
+// LeadTemplateRequest é um modelo a enviar a um lead fora de uma conversa
+// aberta pelo atendente, como nas campanhas
+type LeadTemplateRequest struct {
+	OrganizationID int64
+	LeadID         int64
+	Template       *entity.MessageTemplate
+	CampaignID     int64
+}
+
+// Service envia mensagens pelo WhatsApp e grava o envio na conversa
+type Service struct {
+	conversations ConversationStore
+	leads         LeadFinder
+	templates     TemplateFinder
+	organizations OrganizationFinder
+	consents      ConsentChecker
+	media         MediaStore
+	sender        whatsapp.MessageSender
+}
+
+// NewService cria uma nova instância do serviço de envio
+func NewService(conversations ConversationStore, leads LeadFinder, templates TemplateFinder, organizations OrganizationFinder, consents ConsentChecker, media MediaStore, sender whatsapp.MessageSender) *Service {
+	return &Service{
+		conversations: conversations,
+		leads:         leads,
+		templates:     templates,
+		organizations: organizations,
+		consents:      consents,
+		media:         media,
+		sender:        sender,
+	}
+}
+
+// Send envia a mensagem na conversa. Texto livre só é aceito com a janela
+// de atendimento aberta; fora dela apenas modelos aprovados. Modelos não são
+// enviados a leads descadastrados. Retorna sql.ErrNoRows se a conversa ou o
+// modelo não existirem.
+func (s *Service) Send(req SendRequest) (*entity.Message, error) {
+	conversation, lead, err := s.openConversation(req.OrganizationID, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &entity.Message{
+		OrganizationID: req.OrganizationID,
+		ConversationID: conversation.ID,
+		LeadID:         conversation.LeadID,
+	}
+	if req.UserID != 0 {
+		userID := req.UserID
+		message.UserID = &userID
+	}
+
+	if req.TemplateID != 0 {
+		t, err := s.templates.GetByID(req.OrganizationID, req.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.sendTemplate(lead, t, message); err != nil {
+			return nil, err
+		}
+		return message, nil
+	}
+
+	conversation.UpdateWindow(time.Now())
+	if !conversation.WindowOpen {
+		return nil, ErrWindowClosed
+	}
+	to, phoneNumberID, err := s.route(lead)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	if req.Interactive != nil {
+		message.Type = "interactive"
+		message.Body = req.Interactive.Body
+		message.ExternalID, err = s.sender.SendInteractive(ctx, phoneNumberID, to, *req.Interactive)
+	} else {
+		message.Type = "text"
+		message.Body = req.Text
+		message.ExternalID, err = s.sender.SendText(ctx, phoneNumberID, to, req.Text)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// SendMedia envia um arquivo na conversa, com a legenda opcional. Como o
+// texto livre, só é aceito com a janela de atendimento aberta. O tipo e o
+// tamanho do arquivo são validados pelos limites do WhatsApp
+// (whatsapp.ErrUnsupportedMedia e *whatsapp.MediaTooLargeError).
+func (s *Service) SendMedia(req MediaRequest) (*entity.Message, error) {
+	mediaType, err := whatsapp.MediaType(req.MIMEType, req.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation, lead, err := s.openConversation(req.OrganizationID, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	conversation.UpdateWindow(time.Now())
+	if !conversation.WindowOpen {
+		return nil, ErrWindowClosed
+	}
+	to, phoneNumberID, err := s.route(lead)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mediaTimeout)
+	defer cancel()
+
+	message := &entity.Message{
+		OrganizationID: req.OrganizationID,
+		ConversationID: conversation.ID,
+		LeadID:         lead.ID,
+		Type:           mediaType,
+		Body:           req.Caption,
+		Media: &entity.MessageMedia{
+			OrganizationID: req.OrganizationID,
+			MIMEType:       req.MIMEType,
+			FileName:       req.FileName,
+			Size:           req.Size,
+		},
+	}
+	if req.UserID != 0 {
+		userID := req.UserID
+		message.UserID = &userID
+	}
+
+	if err := s.media.Save(ctx, message.Media, req.File); err != nil {
+		return nil, err
+	}
+	if _, err := req.File.Seek(0, io.SeekStart); err != nil {
+		s.media.Remove(message.Media)
+		return nil, err
+	}
+	mediaID, err := s.sender.UploadMedia(ctx, phoneNumberID, req.FileName, req.MIMEType, req.File)
+	if err == nil {
+		message.ExternalID, err = s.sender.SendMedia(ctx, phoneNumberID, to, whatsapp.OutboundMedia{
+			Type:     mediaType,
+			MediaID:  mediaID,
+			Caption:  req.Caption,
+			FileName: req.FileName,
+		})
+	}
+	if err != nil {
+		s.media.Remove(message.Media)
+		return nil, err
+	}
+	if err := s.record(message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// SendTemplateToLead envia o modelo ao lead na conversa aberta, abrindo uma
+// se necessário. Modelos podem ser enviados com a janela de atendimento
+// fechada.
+func (s *Service) SendTemplateToLead(req LeadTemplateRequest) (*entity.Message, error) {
+	lead, err := s.leads.GetByID(req.OrganizationID, req.LeadID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := phone.Parse(lead.Phone); err != nil {
+		return nil, ErrInvalidRecipient
+	}
+	conversation, err := s.conversations.OpenForLead(lead)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &entity.Message{
+		OrganizationID: req.OrganizationID,
+		ConversationID: conversation.ID,
+		LeadID:         lead.ID,
+		CampaignID:     req.CampaignID,
+	}
+	if err := s.sendTemplate(lead, req.Template, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// openConversation busca a conversa, que precisa estar aberta, e o lead
+func (s *Service) openConversation(organizationID, conversationID int64) (*entity.Conversation, *entity.Lead, error) {
+	conversation, err := s.conversations.GetByID(organizationID, conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if conversation.Status != entity.ConversationStatusOpen {
+		return nil, nil, ErrConversationClosed
+	}
+	lead, err := s.leads.GetByID(organizationID, conversation.LeadID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conversation, lead, nil
+}
+
+// sendTemplate envia o modelo aprovado com as variáveis preenchidas pelos
+// dados do lead e grava a mensagem. Leads descadastrados do WhatsApp não
+// recebem modelos, exigência da política do WhatsApp e da LGPD.
+func (s *Service) sendTemplate(lead *entity.Lead, t *entity.MessageTemplate, message *entity.Message) error {
+	if t.Status != entity.TemplateStatusApproved {
+		return ErrTemplateNotApproved
+	}
+	optedOut, err := s.consents.IsOptedOut(lead.OrganizationID, lead.ID, entity.ConsentChannelWhatsApp)
+	if err != nil {
+		return err
+	}
+	if optedOut {
+		return ErrOptedOut
+	}
+	params := templates.Resolve(t, lead)
+	if len(params.Missing) > 0 {
+		return &MissingFieldsError{Fields: params.Missing}
+	}
+	to, phoneNumberID, err := s.route(lead)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	message.Type = "template"
+	message.Body = templates.Render(t, lead).Body
+	message.ExternalID, err = s.sender.SendTemplate(ctx, phoneNumberID, to, t, whatsapp.TemplateParameters{
+		Header:  params.Header,
+		Body:    params.Body,
+		Buttons: params.Buttons,
+	})
+	if err != nil {
+		return err
+	}
+	return s.record(message)
+}
+
+// route retorna o destinatário (wa_id) e o número de envio da organização
+func (s *Service) route(lead *entity.Lead) (string, string, error) {
+	number, err := phone.Parse(lead.Phone)
+	if err != nil {
+		return "", "", ErrInvalidRecipient
+	}
+	phoneNumberID, err := s.PhoneNumberID(lead.OrganizationID)
+	if err != nil {
+		return "", "", err
+	}
+	return number.WAID(), phoneNumberID, nil
+}
+
+// record grava a mensagem aceita pelo provedor
+func (s *Service) record(message *entity.Message) error {
+	message.Status = "sent"
+	message.CreatedAt = time.Now().UTC()
+	if err := s.conversations.RecordOutbound(message); err != nil {
+		// A mensagem já saiu pelo provedor; o erro é registrado para
+		// conciliação, mas o envio não é repetido
+		logger.Error("Mensagem enviada pelo WhatsApp sem registro na conversa", message.ExternalID)
+		return ErrNotRecorded
+	}
+	return nil
+}
+
+// PhoneNumberID retorna o número de envio da organização, ou
+// ErrNoPhoneNumber se ela não tiver um vinculado. Não há número padrão: as
+// respostas dos leads chegariam a outra organização.
+func (s *Service) PhoneNumberID(organizationID int64) (string, error) {
+	org, err := s.organizations.GetByID(organizationID)
+	if err != nil {
+		return "", err
+	}
+	if org.WhatsAppPhoneNumberID == "" {
+		return "", ErrNoPhoneNumber
+	}
+	return org.WhatsAppPhoneNumberID, nil
+}
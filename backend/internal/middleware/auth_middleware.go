@@ -8,6 +8,7 @@ import (
 
 	"github.com/whatsapp/backend/internal/auth"
 	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/response"
 )
 
 // AuthMiddleware é um middleware para verificar a autenticação
@@ -31,7 +32,7 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 			// Adicionar atraso aleatório para dificultar timing attacks
 			time.Sleep(time.Duration(100+rand.Intn(200)) * time.Millisecond)
 			logger.Warning("Requisição sem token de autorização")
-			http.Error(w, "Autenticação necessária", http.StatusUnauthorized)
+			response.Error(w, r, http.StatusUnauthorized, response.CodeAuthRequired, "Autenticação necessária")
 			return
 		}
 
@@ -41,7 +42,7 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 			// Adicionar atraso aleatório para dificultar timing attacks
 			time.Sleep(time.Duration(100+rand.Intn(200)) * time.Millisecond)
 			logger.Warning("Formato de token inválido", map[string]interface{}{"header": authHeader})
-			http.Error(w, "Autenticação inválida", http.StatusUnauthorized)
+			response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidToken, "Autenticação inválida")
 			return
 		}
 
@@ -55,17 +56,18 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 
 			if err == auth.ErrExpiredToken {
 				logger.Warning("Token expirado")
-				http.Error(w, "Autenticação expirada", http.StatusUnauthorized)
+				response.Error(w, r, http.StatusUnauthorized, response.CodeTokenExpired, "Autenticação expirada")
 				return
 			}
 			logger.Warning("Token inválido", err)
-			http.Error(w, "Autenticação inválida", http.StatusUnauthorized)
+			response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidToken, "Autenticação inválida")
 			return
 		}
 
 		// Adicionar claims ao contexto da requisição
 		ctx := r.Context()
 		ctx = auth.WithUserID(ctx, claims.UserID)
+		ctx = auth.WithOrganizationID(ctx, claims.OrganizationID)
 		ctx = auth.WithEmail(ctx, claims.Email)
 
 		// Prosseguir com a requisição
@@ -0,0 +1,179 @@
+package entity
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Tipos de atividade do histórico dos leads
+const (
+	ActivityLeadCreated     = "lead.created"
+	ActivityLeadUpdated     = "lead.updated"
+	ActivityLeadMerged      = "lead.merged"
+	ActivityStatusChanged   = "lead.status_changed"
+	ActivityStageChanged    = "lead.stage_changed"
+	ActivityAssigned        = "lead.assigned"
+	ActivityMessageInbound  = "message.inbound"
+	ActivityMessageOutbound = "message.outbound"
+	ActivityNoteCreated     = "note.created"
+	ActivityTaskCreated     = "task.created"
+	ActivityTaskCompleted   = "task.completed"
+	ActivityCampaignSent    = "campaign.sent"
+	ActivityConsentChanged  = "consent.changed"
+)
+
+// activityPreviewMaxLength limita os textos copiados para o histórico, como o de notas e mensagens
+const activityPreviewMaxLength = 200
+
+// ActivityTypes lista os tipos de atividade aceitos no filtro do histórico
+var ActivityTypes = []string{
+	ActivityLeadCreated,
+	ActivityLeadUpdated,
+	ActivityLeadMerged,
+	ActivityStatusChanged,
+	ActivityStageChanged,
+	ActivityAssigned,
+	ActivityMessageInbound,
+	ActivityMessageOutbound,
+	ActivityNoteCreated,
+	ActivityTaskCreated,
+	ActivityTaskCompleted,
+	ActivityCampaignSent,
+	ActivityConsentChanged,
+}
+
+// IsValidActivityType verifica se o tipo de atividade é conhecido
+func IsValidActivityType(activityType string) bool {
+	for _, t := range ActivityTypes {
+		if t == activityType {
+			return true
+		}
+	}
+	return false
+}
+
+// Activity é um evento do histórico de um lead. O histórico é somente de
+// inclusão: as atividades não são alteradas depois de gravadas.
+type Activity struct {
+	ID             int64                  `json:"id"`
+	OrganizationID int64                  `json:"organization_id"`
+	LeadID         int64                  `json:"lead_id"`
+	UserID         *int64                 `json:"user_id"`
+	UserName       string                 `json:"user_name,omitempty"`
+	Type           string                 `json:"type"`
+	Data           map[string]interface{} `json:"data"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
+
+// FieldChange descreve a alteração de um campo do lead, com os valores
+// anterior e novo
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// NewActivity cria uma atividade do lead. userID zero indica uma ação do sistema.
+func NewActivity(organizationID, leadID, userID int64, activityType string) *Activity {
+	a := &Activity{
+		OrganizationID: organizationID,
+		LeadID:         leadID,
+		Type:           activityType,
+		Data:           make(map[string]interface{}),
+		CreatedAt:      time.Now(),
+	}
+	if userID != 0 {
+		a.UserID = &userID
+	}
+	return a
+}
+
+// LeadChangeActivities compara o lead antes e depois de uma alteração e
+// retorna as atividades correspondentes: mudança de status, de etapa e de
+// responsável têm atividades próprias, e os demais campos são reunidos em
+// uma única lead.updated com os valores anterior e novo.
+func LeadChangeActivities(before, after *Lead, userID int64) []*Activity {
+	var activities []*Activity
+	transition := func(activityType string, from, to interface{}) {
+		a := NewActivity(after.OrganizationID, after.ID, userID, activityType)
+		a.Data["from"] = from
+		a.Data["to"] = to
+		activities = append(activities, a)
+	}
+
+	if before.Status != after.Status {
+		transition(ActivityStatusChanged, before.Status, after.Status)
+	}
+	if before.Stage != after.Stage {
+		transition(ActivityStageChanged, before.Stage, after.Stage)
+	}
+	if !sameOwner(before.OwnerID, after.OwnerID) {
+		transition(ActivityAssigned, before.OwnerID, after.OwnerID)
+	}
+
+	var changes []FieldChange
+	for _, f := range []struct {
+		name          string
+		before, after string
+	}{
+		{"name", before.Name, after.Name},
+		{"phone", before.Phone, after.Phone},
+		{"email", before.Email, after.Email},
+		{"source", before.Source, after.Source},
+	} {
+		if f.before != f.after {
+			changes = append(changes, FieldChange{Field: f.name, Before: f.before, After: f.after})
+		}
+	}
+
+	keys := make(map[string]bool)
+	for key := range before.CustomFields {
+		keys[key] = true
+	}
+	for key := range after.CustomFields {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	for _, key := range sorted {
+		old, updated := before.CustomFields[key], after.CustomFields[key]
+		if !sameValue(old, updated) {
+			changes = append(changes, FieldChange{Field: "custom." + key, Before: old, After: updated})
+		}
+	}
+
+	if len(changes) > 0 {
+		a := NewActivity(after.OrganizationID, after.ID, userID, ActivityLeadUpdated)
+		a.Data["changes"] = changes
+		activities = append(activities, a)
+	}
+	return activities
+}
+
+// ActivityPreview corta textos longos copiados para o histórico
+func ActivityPreview(text string) string {
+	runes := []rune(text)
+	if len(runes) <= activityPreviewMaxLength {
+		return text
+	}
+	return string(runes[:activityPreviewMaxLength-1]) + "…"
+}
+
+func sameOwner(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// sameValue compara valores de campos personalizados pela forma em JSON,
+// que é como são gravados
+func sameValue(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// Estratégias de distribuição automática de leads
+const (
+	// AssignmentRoundRobin reveza os atendentes, escolhendo quem recebeu um lead há mais tempo
+	AssignmentRoundRobin = "round_robin"
+	// AssignmentLeastOpen escolhe o atendente com menos conversas abertas
+	AssignmentLeastOpen = "least_open"
+)
+
+// AssignmentStrategies lista as estratégias aceitas
+var AssignmentStrategies = []string{AssignmentRoundRobin, AssignmentLeastOpen}
+
+// AssignmentSettings configura a distribuição automática dos leads que
+// chegam pelo WhatsApp em uma organização
+type AssignmentSettings struct {
+	OrganizationID int64         `json:"organization_id"`
+	Enabled        bool          `json:"enabled"`
+	Strategy       string        `json:"strategy"`
+	Rules          []RoutingRule `json:"rules"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// DefaultAssignmentSettings retorna a configuração de organizações que ainda
+// não definiram a distribuição: desativada, com rodízio
+func DefaultAssignmentSettings(organizationID int64) *AssignmentSettings {
+	return &AssignmentSettings{
+		OrganizationID: organizationID,
+		Strategy:       AssignmentRoundRobin,
+		Rules:          []RoutingRule{},
+	}
+}
+
+// RoutingRule direciona o lead para atendentes com a habilidade Skill quando
+// ele possui alguma das etiquetas Tags ou quando a primeira mensagem contém
+// alguma das palavras de Keywords
+type RoutingRule struct {
+	Skill    string   `json:"skill"`
+	Tags     []string `json:"tags"`
+	Keywords []string `json:"keywords"`
+}
+
+// RequiredSkill retorna a habilidade da primeira regra que corresponde ao
+// lead ou à mensagem, ou vazio se nenhuma corresponder
+func (s *AssignmentSettings) RequiredSkill(tags []string, message string) string {
+	text := strings.ToLower(message)
+	for _, rule := range s.Rules {
+		for _, tag := range rule.Tags {
+			for _, leadTag := range tags {
+				if strings.EqualFold(tag, leadTag) {
+					return rule.Skill
+				}
+			}
+		}
+		for _, keyword := range rule.Keywords {
+			if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+				return rule.Skill
+			}
+		}
+	}
+	return ""
+}
+
+// AgentSettings define a disponibilidade e as habilidades de um atendente
+// para a distribuição automática. MaxOpenConversations zero significa sem
+// limite. Usuários sem configuração estão disponíveis e sem habilidades.
+type AgentSettings struct {
+	UserID               int64      `json:"user_id"`
+	Name                 string     `json:"name"`
+	Email                string     `json:"email"`
+	Available            bool       `json:"available"`
+	Skills               []string   `json:"skills"`
+	MaxOpenConversations int        `json:"max_open_conversations"`
+	OpenConversations    int        `json:"open_conversations"`
+	LastAssignedAt       *time.Time `json:"last_assigned_at"`
+}
+
+// NormalizeSkill padroniza habilidades para comparação
+func NormalizeSkill(skill string) string {
+	return strings.ToLower(strings.TrimSpace(skill))
+}
@@ -0,0 +1,53 @@
+package entity
+
+import "testing"
+
+func TestRequiredSkill(t *testing.T) {
+	settings := &AssignmentSettings{Rules: []RoutingRule{
+		{Skill: "financeiro", Tags: []string{"Inadimplente"}, Keywords: []string{"boleto", "segunda via"}},
+		{Skill: "vendas", Keywords: []string{"preço", "orçamento"}},
+		{Skill: "vazia", Keywords: []string{""}},
+	}}
+
+	tests := []struct {
+		name    string
+		tags    []string
+		message string
+		want    string
+	}{
+		{"etiqueta", []string{"vip", "inadimplente"}, "oi", "financeiro"},
+		{"palavra-chave", nil, "Preciso da SEGUNDA VIA", "financeiro"},
+		{"segunda regra", nil, "Qual o preço?", "vendas"},
+		{"primeira regra vence", nil, "orçamento e boleto", "financeiro"},
+		{"etiqueta antes da mensagem", []string{"INADIMPLENTE"}, "qual o preço", "financeiro"},
+		{"nenhuma regra", []string{"vip"}, "bom dia", ""},
+		{"mensagem vazia", nil, "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := settings.RequiredSkill(tt.tags, tt.message); got != tt.want {
+			t.Errorf("RequiredSkill(%q, %q) = %q, esperado %q", tt.tags, tt.message, got, tt.want)
+		}
+	}
+
+	if got := DefaultAssignmentSettings(1).RequiredSkill([]string{"vip"}, "preço"); got != "" {
+		t.Errorf("RequiredSkill sem regras = %q, esperado vazio", got)
+	}
+}
+
+func TestNormalizeSkill(t *testing.T) {
+	tests := []struct {
+		skill string
+		want  string
+	}{
+		{"Vendas", "vendas"},
+		{"  Financeiro ", "financeiro"},
+		{"   ", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeSkill(tt.skill); got != tt.want {
+			t.Errorf("NormalizeSkill(%q) = %q, esperado %q", tt.skill, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+)
+
+// Ações registradas na trilha de auditoria
+const (
+	AuditLeadMerged = "lead.merged"
+)
+
+// AuditEntry registra uma operação sensível realizada por um usuário
+type AuditEntry struct {
+	ID             int64                  `json:"id"`
+	OrganizationID int64                  `json:"organization_id"`
+	UserID         int64                  `json:"user_id"`
+	Action         string                 `json:"action"`
+	EntityType     string                 `json:"entity_type"`
+	EntityID       int64                  `json:"entity_id"`
+	Data           map[string]interface{} `json:"data"`
+	IPAddress      string                 `json:"ip_address"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
+
+// NewAuditEntry cria uma nova entrada de auditoria
+func NewAuditEntry(organizationID, userID int64, action, entityType string, entityID int64) *AuditEntry {
+	return &AuditEntry{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Action:         action,
+		EntityType:     entityType,
+		EntityID:       entityID,
+		Data:           make(map[string]interface{}),
+		CreatedAt:      time.Now(),
+	}
+}
@@ -0,0 +1,149 @@
+package entity
+
+import (
+	"time"
+)
+
+// Condições das regras de automação
+const (
+	// ConditionKeyword corresponde a mensagens que contêm uma das palavras ou
+	// expressões, sem distinção de maiúsculas e acentos
+	ConditionKeyword = "keyword"
+	// ConditionRegex corresponde a mensagens em que a expressão regular é encontrada
+	ConditionRegex = "regex"
+	// ConditionFirstMessage corresponde à primeira mensagem de um lead novo
+	ConditionFirstMessage = "first_message"
+	// ConditionOutsideBusinessHours corresponde a mensagens recebidas fora
+	// do horário de atendimento
+	ConditionOutsideBusinessHours = "outside_business_hours"
+)
+
+// AutomationConditions lista as condições aceitas
+var AutomationConditions = []string{
+	ConditionKeyword,
+	ConditionRegex,
+	ConditionFirstMessage,
+	ConditionOutsideBusinessHours,
+}
+
+// Ações das regras de automação
+const (
+	ActionSendText     = "send_text"
+	ActionSendTemplate = "send_template"
+	ActionAddTag       = "add_tag"
+	ActionMoveStage    = "move_stage"
+	ActionAssign       = "assign"
+	// ActionStartFlow inicia um fluxo do chatbot na conversa
+	ActionStartFlow = "start_flow"
+)
+
+// AutomationActions lista as ações aceitas
+var AutomationActions = []string{
+	ActionSendText,
+	ActionSendTemplate,
+	ActionAddTag,
+	ActionMoveStage,
+	ActionAssign,
+	ActionStartFlow,
+}
+
+// Resultado de cada ação executada por uma regra
+const (
+	ActionResultDone    = "done"
+	ActionResultSkipped = "skipped"
+	ActionResultFailed  = "failed"
+)
+
+// DefaultAutomationCooldown é o intervalo padrão, em minutos, antes que uma
+// regra volte a ser executada na mesma conversa
+const DefaultAutomationCooldown = 60
+
+// AutomationRule executa ações sobre as mensagens recebidas que atendem a
+// todas as condições. As regras são avaliadas na ordem de Position; uma
+// regra com StopProcessing encerra a avaliação quando executada.
+type AutomationRule struct {
+	ID             int64                 `json:"id"`
+	OrganizationID int64                 `json:"organization_id"`
+	Name           string                `json:"name"`
+	Position       int                   `json:"position"`
+	Enabled        bool                  `json:"enabled"`
+	Conditions     []AutomationCondition `json:"conditions"`
+	Actions        []AutomationAction    `json:"actions"`
+	StopProcessing bool                  `json:"stop_processing"`
+	// CooldownMinutes impede que a regra seja executada de novo na mesma
+	// conversa antes do intervalo
+	CooldownMinutes int       `json:"cooldown_minutes"`
+	CreatedBy       *int64    `json:"created_by"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// NewAutomationRule cria uma nova regra ativa
+func NewAutomationRule(organizationID, userID int64) *AutomationRule {
+	rule := &AutomationRule{
+		OrganizationID:  organizationID,
+		Enabled:         true,
+		Conditions:      []AutomationCondition{},
+		Actions:         []AutomationAction{},
+		CooldownMinutes: DefaultAutomationCooldown,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	if userID != 0 {
+		rule.CreatedBy = &userID
+	}
+	return rule
+}
+
+// AutomationCondition é uma condição da regra; os campos usados dependem do tipo.
+// Em outside_business_hours, CalendarID escolhe o calendário de atendimento;
+// sem ele vale o horário informado em BusinessHours ou, sem nenhum dos dois,
+// o calendário padrão da organização.
+type AutomationCondition struct {
+	Type          string         `json:"type"`
+	Keywords      []string       `json:"keywords,omitempty"`
+	Pattern       string         `json:"pattern,omitempty"`
+	CalendarID    int64          `json:"calendar_id,omitempty"`
+	BusinessHours *BusinessHours `json:"business_hours,omitempty"`
+}
+
+// AutomationAction é uma ação da regra; os campos usados dependem do tipo.
+// Em assign, UserID zero distribui o lead pela configuração da organização,
+// preferindo atendentes com a habilidade Skill.
+type AutomationAction struct {
+	Type       string `json:"type"`
+	Text       string `json:"text,omitempty"`
+	TemplateID int64  `json:"template_id,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	Stage      string `json:"stage,omitempty"`
+	UserID     int64  `json:"user_id,omitempty"`
+	Skill      string `json:"skill,omitempty"`
+	FlowID     int64  `json:"flow_id,omitempty"`
+}
+
+// IsReply indica as ações que enviam mensagens ao lead; o início de um
+// fluxo conta como resposta
+func (a AutomationAction) IsReply() bool {
+	return a.Type == ActionSendText || a.Type == ActionSendTemplate || a.Type == ActionStartFlow
+}
+
+// AutomationRun registra a execução de uma regra sobre uma mensagem recebida
+type AutomationRun struct {
+	ID             int64                    `json:"id"`
+	OrganizationID int64                    `json:"organization_id"`
+	RuleID         int64                    `json:"rule_id"`
+	ConversationID int64                    `json:"conversation_id"`
+	LeadID         int64                    `json:"lead_id"`
+	MessageID      int64                    `json:"message_id"`
+	Results        []AutomationActionResult `json:"results"`
+	// Replies conta as mensagens enviadas ao lead, usadas na proteção contra laços
+	Replies   int       `json:"replies"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AutomationActionResult é o resultado de uma ação executada
+type AutomationActionResult struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"time"
+)
+
+// BusinessCalendar é o horário de atendimento de uma organização ou de uma
+// equipe: intervalos semanais em um fuso horário, fechados nos feriados. O
+// calendário padrão vale para as regras e campanhas que não indicam outro.
+type BusinessCalendar struct {
+	ID             int64         `json:"id"`
+	OrganizationID int64         `json:"organization_id"`
+	Name           string        `json:"name"`
+	IsDefault      bool          `json:"is_default"`
+	TimeZone       string        `json:"time_zone"`
+	Days           []BusinessDay `json:"days"`
+	// NationalHolidays fecha o atendimento nos feriados nacionais do Brasil
+	NationalHolidays bool `json:"national_holidays"`
+	// OptionalHolidays fecha também nos pontos facultativos nacionais: a
+	// segunda e a terça de Carnaval e Corpus Christi
+	OptionalHolidays bool      `json:"optional_holidays"`
+	Holidays         []Holiday `json:"holidays"`
+	CreatedBy        *int64    `json:"created_by"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// NewBusinessCalendar cria um novo calendário sem intervalos, fechado nos
+// feriados nacionais
+func NewBusinessCalendar(organizationID, userID int64) *BusinessCalendar {
+	cal := &BusinessCalendar{
+		OrganizationID:   organizationID,
+		Days:             []BusinessDay{},
+		NationalHolidays: true,
+		Holidays:         []Holiday{},
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+	if userID != 0 {
+		cal.CreatedBy = &userID
+	}
+	return cal
+}
+
+// BusinessHours é o horário de atendimento semanal em um fuso horário
+type BusinessHours struct {
+	TimeZone string        `json:"time_zone"`
+	Days     []BusinessDay `json:"days"`
+}
+
+// BusinessDay é um intervalo de atendimento em um dia da semana (0 é
+// domingo), com horários no formato HH:MM
+type BusinessDay struct {
+	Weekday int    `json:"weekday"`
+	Open    string `json:"open"`
+	Close   string `json:"close"`
+}
+
+// Holiday é um dia sem atendimento, com a data no formato AAAA-MM-DD.
+// Recurring repete o feriado todo ano no mesmo dia e mês.
+type Holiday struct {
+	Date      string `json:"date"`
+	Name      string `json:"name"`
+	Recurring bool   `json:"recurring,omitempty"`
+	// Optional marca os pontos facultativos na lista de feriados nacionais
+	Optional bool `json:"optional,omitempty"`
+}
@@ -0,0 +1,167 @@
+package entity
+
+import (
+	"time"
+)
+
+// Status de uma campanha
+const (
+	CampaignStatusDraft     = "draft"
+	CampaignStatusScheduled = "scheduled"
+	CampaignStatusRunning   = "running"
+	CampaignStatusPaused    = "paused"
+	CampaignStatusCompleted = "completed"
+	CampaignStatusCancelled = "cancelled"
+)
+
+// CampaignStatuses lista os status aceitos no filtro de campanhas
+var CampaignStatuses = []string{
+	CampaignStatusDraft,
+	CampaignStatusScheduled,
+	CampaignStatusRunning,
+	CampaignStatusPaused,
+	CampaignStatusCompleted,
+	CampaignStatusCancelled,
+}
+
+// Status de um destinatário da campanha. sent, delivered, read e failed
+// acompanham o status de entrega da mensagem no provedor; opted_out indica
+// um lead descadastrado do WhatsApp, que não recebe a campanha.
+const (
+	RecipientPending   = "pending"
+	RecipientSending   = "sending"
+	RecipientSent      = "sent"
+	RecipientDelivered = "delivered"
+	RecipientRead      = "read"
+	RecipientFailed    = "failed"
+	RecipientCancelled = "cancelled"
+	RecipientOptedOut  = "opted_out"
+)
+
+// RecipientStatuses lista os status aceitos no filtro de destinatários
+var RecipientStatuses = []string{
+	RecipientPending,
+	RecipientSending,
+	RecipientSent,
+	RecipientDelivered,
+	RecipientRead,
+	RecipientFailed,
+	RecipientCancelled,
+	RecipientOptedOut,
+}
+
+// CampaignReplyWindow é o prazo em que uma mensagem do lead depois do envio
+// conta como resposta à campanha
+const CampaignReplyWindow = 72 * time.Hour
+
+// Níveis de envio do número na Meta
+const (
+	MessagingTier50        = "TIER_50"
+	MessagingTier250       = "TIER_250"
+	MessagingTier1K        = "TIER_1K"
+	MessagingTier10K       = "TIER_10K"
+	MessagingTier100K      = "TIER_100K"
+	MessagingTierUnlimited = "TIER_UNLIMITED"
+)
+
+// MessagingTierLimit retorna quantos destinatários o nível permite em 24
+// horas, ou 0 para sem limite
+func MessagingTierLimit(tier string) int {
+	switch tier {
+	case MessagingTier50:
+		return 50
+	case MessagingTier1K:
+		return 1000
+	case MessagingTier10K:
+		return 10000
+	case MessagingTier100K:
+		return 100000
+	case MessagingTierUnlimited:
+		return 0
+	default:
+		return 250
+	}
+}
+
+// Campaign é o disparo de um modelo aprovado para os leads de um segmento.
+// O filtro do segmento é copiado na criação e avaliado quando o envio
+// começa; os destinatários são gravados nesse momento.
+type Campaign struct {
+	ID             int64      `json:"id"`
+	OrganizationID int64      `json:"organization_id"`
+	Name           string     `json:"name"`
+	TemplateID     int64      `json:"template_id"`
+	SegmentID      *int64     `json:"segment_id"`
+	Filter         LeadFilter `json:"filters"`
+	// CalendarID limita o envio ao horário de atendimento do calendário;
+	// fora dele a campanha continua em andamento, sem enviar, até a
+	// próxima abertura
+	CalendarID  *int64     `json:"calendar_id"`
+	Status      string     `json:"status"`
+	ScheduledAt *time.Time `json:"scheduled_at"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	// Error explica a pausa automática da campanha, como um modelo que
+	// deixou de estar aprovado
+	Error     string    `json:"error"`
+	CreatedBy *int64    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewCampaign cria uma campanha em rascunho
+func NewCampaign(organizationID, createdBy int64) *Campaign {
+	now := time.Now().UTC()
+	c := &Campaign{
+		OrganizationID: organizationID,
+		Status:         CampaignStatusDraft,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if createdBy != 0 {
+		c.CreatedBy = &createdBy
+	}
+	return c
+}
+
+// IsFinished indica campanhas concluídas ou canceladas
+func (c *Campaign) IsFinished() bool {
+	return c.Status == CampaignStatusCompleted || c.Status == CampaignStatusCancelled
+}
+
+// CampaignRecipient é um lead do público da campanha com o andamento do envio
+type CampaignRecipient struct {
+	ID            int64      `json:"id"`
+	CampaignID    int64      `json:"campaign_id"`
+	LeadID        int64      `json:"lead_id"`
+	LeadName      string     `json:"lead_name"`
+	LeadPhone     string     `json:"lead_phone"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	MessageID     *int64     `json:"message_id"`
+	Error         string     `json:"error"`
+	SentAt        *time.Time `json:"sent_at"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	ReadAt        *time.Time `json:"read_at"`
+	RepliedAt     *time.Time `json:"replied_at"`
+	FailedAt      *time.Time `json:"failed_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	// OrganizationID é usado pelo envio e não é exposto
+	OrganizationID int64 `json:"-"`
+}
+
+// CampaignReport resume os destinatários da campanha. As contagens são
+// cumulativas: uma mensagem lida também conta como enviada e entregue.
+type CampaignReport struct {
+	CampaignID int64 `json:"campaign_id"`
+	Total      int   `json:"total"`
+	Pending    int   `json:"pending"`
+	Sent       int   `json:"sent"`
+	Delivered  int   `json:"delivered"`
+	Read       int   `json:"read"`
+	Replied    int   `json:"replied"`
+	Failed     int   `json:"failed"`
+	Cancelled  int   `json:"cancelled"`
+	OptedOut   int   `json:"opted_out"`
+}
@@ -0,0 +1,240 @@
+package entity
+
+import (
+	"time"
+)
+
+// Tipos dos nós de um fluxo do chatbot
+const (
+	// FlowNodeSend envia um texto e segue para o próximo nó
+	FlowNodeSend = "send"
+	// FlowNodeButtons envia até 3 botões de resposta e aguarda a escolha
+	FlowNodeButtons = "buttons"
+	// FlowNodeList envia uma lista de até 10 opções e aguarda a escolha
+	FlowNodeList = "list"
+	// FlowNodeCapture faz uma pergunta e grava a resposta em um campo do lead
+	FlowNodeCapture = "capture"
+	// FlowNodeBranch escolhe o próximo nó pelos campos do lead ou respostas
+	FlowNodeBranch = "branch"
+	// FlowNodeHandoff encerra o fluxo e atribui o lead a um atendente
+	FlowNodeHandoff = "handoff"
+)
+
+// FlowNodeTypes lista os tipos de nó aceitos
+var FlowNodeTypes = []string{
+	FlowNodeSend,
+	FlowNodeButtons,
+	FlowNodeList,
+	FlowNodeCapture,
+	FlowNodeBranch,
+	FlowNodeHandoff,
+}
+
+// Formatos aceitos pelas respostas dos nós capture
+const (
+	CaptureText   = "text"
+	CaptureEmail  = "email"
+	CaptureNumber = "number"
+)
+
+// CaptureFormats lista os formatos de resposta aceitos
+var CaptureFormats = []string{CaptureText, CaptureEmail, CaptureNumber}
+
+// Operadores das ramificações, comparando sem distinção de maiúsculas e acentos
+const (
+	BranchEquals   = "equals"
+	BranchContains = "contains"
+	BranchEmpty    = "empty"
+)
+
+// BranchOperators lista os operadores aceitos
+var BranchOperators = []string{BranchEquals, BranchContains, BranchEmpty}
+
+// FlowAnswerPrefix indica, no campo de uma ramificação, a resposta dada em
+// um nó do fluxo (answers.<id do nó>) em vez de um campo do lead
+const FlowAnswerPrefix = "answers."
+
+// Status de uma sessão de fluxo em uma conversa
+const (
+	FlowSessionActive    = "active"
+	FlowSessionCompleted = "completed"
+	FlowSessionHandedOff = "handed_off"
+	FlowSessionTimedOut  = "timed_out"
+	FlowSessionCancelled = "cancelled"
+	FlowSessionFailed    = "failed"
+)
+
+// FlowSessionStatuses lista os status aceitos nos filtros
+var FlowSessionStatuses = []string{
+	FlowSessionActive,
+	FlowSessionCompleted,
+	FlowSessionHandedOff,
+	FlowSessionTimedOut,
+	FlowSessionCancelled,
+	FlowSessionFailed,
+}
+
+// DefaultFlowTimeout é o prazo padrão, em minutos, para o lead responder
+// a um nó antes que o fluxo siga pelo nó de contingência
+const DefaultFlowTimeout = 60
+
+// ChatbotFlow é uma máquina de estados que conversa com o lead antes do
+// atendimento humano. A execução começa em StartNode e segue pelo Next de
+// cada nó; um nó sem próximo encerra o fluxo.
+type ChatbotFlow struct {
+	ID             int64      `json:"id"`
+	OrganizationID int64      `json:"organization_id"`
+	Name           string     `json:"name"`
+	Enabled        bool       `json:"enabled"`
+	StartNode      string     `json:"start_node"`
+	Nodes          []FlowNode `json:"nodes"`
+	// TimeoutMinutes é o prazo para o lead responder a cada pergunta
+	TimeoutMinutes int `json:"timeout_minutes"`
+	// FallbackNode é executado quando o prazo acaba ou o lead erra a
+	// resposta repetidas vezes; sem ele o fluxo é encerrado
+	FallbackNode string    `json:"fallback_node"`
+	CreatedBy    *int64    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// NewChatbotFlow cria um novo fluxo ativo
+func NewChatbotFlow(organizationID, userID int64) *ChatbotFlow {
+	flow := &ChatbotFlow{
+		OrganizationID: organizationID,
+		Enabled:        true,
+		Nodes:          []FlowNode{},
+		TimeoutMinutes: DefaultFlowTimeout,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if userID != 0 {
+		flow.CreatedBy = &userID
+	}
+	return flow
+}
+
+// Node busca o nó pelo ID
+func (f *ChatbotFlow) Node(id string) *FlowNode {
+	for i := range f.Nodes {
+		if f.Nodes[i].ID == id {
+			return &f.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// FlowNode é um nó do fluxo; os campos usados dependem do tipo. Field é o
+// campo do lead que recebe a resposta (name, email, source, stage ou
+// custom.<chave>), obrigatório em capture e opcional em buttons e list.
+type FlowNode struct {
+	ID         string       `json:"id"`
+	Type       string       `json:"type"`
+	Text       string       `json:"text,omitempty"`
+	Next       string       `json:"next,omitempty"`
+	Options    []FlowOption `json:"options,omitempty"`
+	ListButton string       `json:"list_button,omitempty"`
+	Field      string       `json:"field,omitempty"`
+	Format     string       `json:"format,omitempty"`
+	// RetryText é enviado quando a resposta não é aceita, antes de repetir
+	// a pergunta
+	RetryText string       `json:"retry_text,omitempty"`
+	Branches  []FlowBranch `json:"branches,omitempty"`
+	// UserID e Skill escolhem o atendente no handoff, como na ação assign
+	// das regras de automação
+	UserID int64  `json:"user_id,omitempty"`
+	Skill  string `json:"skill,omitempty"`
+	// Position guarda a posição do nó no editor visual
+	Position *FlowPosition `json:"position,omitempty"`
+}
+
+// WaitsForAnswer indica os nós que aguardam a resposta do lead
+func (n *FlowNode) WaitsForAnswer() bool {
+	return n.Type == FlowNodeButtons || n.Type == FlowNodeList || n.Type == FlowNodeCapture
+}
+
+// FlowOption é um botão ou item de lista. Value é gravado no campo e nas
+// respostas; vazio, vale o título.
+type FlowOption struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Value       string `json:"value,omitempty"`
+	Next        string `json:"next,omitempty"`
+}
+
+// Answer retorna o valor gravado quando a opção é escolhida
+func (o FlowOption) Answer() string {
+	if o.Value != "" {
+		return o.Value
+	}
+	return o.Title
+}
+
+// FlowBranch desvia para Next quando o campo atende ao operador; as
+// ramificações são avaliadas em ordem e, sem nenhuma atendida, segue o
+// Next do nó
+type FlowBranch struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value,omitempty"`
+	Next     string `json:"next"`
+}
+
+// FlowPosition é a posição de um nó no editor visual
+type FlowPosition struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// FlowSession é o estado de um fluxo em uma conversa. Cada conversa tem no
+// máximo uma sessão ativa, parada no nó CurrentNode até a resposta do lead
+// ou até ExpiresAt.
+type FlowSession struct {
+	ID             int64  `json:"id"`
+	OrganizationID int64  `json:"organization_id"`
+	FlowID         int64  `json:"flow_id"`
+	ConversationID int64  `json:"conversation_id"`
+	LeadID         int64  `json:"lead_id"`
+	Status         string `json:"status"`
+	CurrentNode    string `json:"current_node"`
+	// Attempts conta as respostas não aceitas no nó atual
+	Attempts int `json:"attempts"`
+	// Fallback indica que o nó de contingência já foi executado; um novo
+	// prazo esgotado encerra a sessão
+	Fallback bool `json:"fallback"`
+	// Answers guarda a resposta aceita em cada nó, pelo ID do nó
+	Answers   map[string]string `json:"answers"`
+	ExpiresAt *time.Time        `json:"expires_at"`
+	Error     string            `json:"error,omitempty"`
+	StartedAt time.Time         `json:"started_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	EndedAt   *time.Time        `json:"ended_at"`
+	// Version muda a cada gravação da sessão, para que duas gravações a
+	// partir da mesma leitura não se sobreponham
+	Version int `json:"-"`
+}
+
+// NewFlowSession cria uma sessão ativa do fluxo na conversa
+func NewFlowSession(flow *ChatbotFlow, conversationID, leadID int64) *FlowSession {
+	now := time.Now().UTC()
+	return &FlowSession{
+		OrganizationID: flow.OrganizationID,
+		FlowID:         flow.ID,
+		ConversationID: conversationID,
+		LeadID:         leadID,
+		Status:         FlowSessionActive,
+		CurrentNode:    flow.StartNode,
+		Answers:        map[string]string{},
+		StartedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// End encerra a sessão com o status informado
+func (s *FlowSession) End(status string, now time.Time) {
+	s.Status = status
+	s.ExpiresAt = nil
+	s.UpdatedAt = now
+	s.EndedAt = &now
+}
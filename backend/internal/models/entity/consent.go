@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Canais de comunicação com consentimento registrado
+const (
+	ConsentChannelWhatsApp = "whatsapp"
+	ConsentChannelEmail    = "email"
+	ConsentChannelSMS      = "sms"
+)
+
+// ConsentChannels lista os canais aceitos no consentimento
+var ConsentChannels = []string{
+	ConsentChannelWhatsApp,
+	ConsentChannelEmail,
+	ConsentChannelSMS,
+}
+
+// Situações do consentimento do lead em um canal
+const (
+	ConsentOptedIn  = "opted_in"
+	ConsentOptedOut = "opted_out"
+)
+
+// Origens de uma alteração de consentimento
+const (
+	ConsentSourceManual  = "manual"
+	ConsentSourceForm    = "form"
+	ConsentSourceImport  = "import"
+	ConsentSourceAPI     = "api"
+	ConsentSourceKeyword = "inbound_keyword"
+)
+
+// ConsentSources lista as origens aceitas ao registrar o consentimento pela API.
+// inbound_keyword é exclusiva das mensagens recebidas.
+var ConsentSources = []string{
+	ConsentSourceManual,
+	ConsentSourceForm,
+	ConsentSourceImport,
+	ConsentSourceAPI,
+}
+
+// OptOutKeywords são as palavras que, enviadas sozinhas pelo lead, retiram o
+// consentimento para mensagens no WhatsApp
+var OptOutKeywords = []string{"SAIR", "PARAR", "STOP"}
+
+// Consent é uma alteração do consentimento do lead em um canal. Os registros
+// são somente de inclusão; a situação atual é a do registro mais recente.
+type Consent struct {
+	ID             int64  `json:"id"`
+	OrganizationID int64  `json:"organization_id"`
+	LeadID         int64  `json:"lead_id"`
+	Channel        string `json:"channel"`
+	Status         string `json:"status"`
+	Source         string `json:"source"`
+	// Evidence descreve a prova do consentimento, como o formulário, o
+	// arquivo importado ou a mensagem recebida
+	Evidence  string    `json:"evidence"`
+	MessageID *int64    `json:"message_id"`
+	UserID    *int64    `json:"user_id"`
+	UserName  string    `json:"user_name,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewConsent cria uma alteração de consentimento. userID zero indica uma ação do sistema.
+func NewConsent(organizationID, leadID, userID int64, channel, status, source string) *Consent {
+	c := &Consent{
+		OrganizationID: organizationID,
+		LeadID:         leadID,
+		Channel:        channel,
+		Status:         status,
+		Source:         source,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if userID != 0 {
+		c.UserID = &userID
+	}
+	return c
+}
+
+// IsOptOutMessage verifica se o texto é apenas uma das palavras de
+// descadastro, ignorando maiúsculas, espaços e pontuação ("Sair.", " stop!")
+func IsOptOutMessage(text string) bool {
+	word := strings.ToUpper(strings.TrimFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	}))
+	for _, keyword := range OptOutKeywords {
+		if word == keyword {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"time"
+)
+
+// Status possíveis de uma conversa
+const (
+	ConversationStatusOpen   = "open"
+	ConversationStatusClosed = "closed"
+)
+
+// CustomerServiceWindow é a janela de atendimento do WhatsApp: mensagens
+// livres só podem ser enviadas até 24 horas depois da última mensagem do
+// lead; fora dela apenas modelos aprovados
+const CustomerServiceWindow = 24 * time.Hour
+
+// Direções de uma mensagem
+const (
+	MessageInbound  = "inbound"
+	MessageOutbound = "outbound"
+)
+
+// Conversation é o atendimento de um lead pelo WhatsApp. Cada lead tem no
+// máximo uma conversa aberta; mensagens recebidas depois do encerramento
+// abrem uma nova.
+type Conversation struct {
+	ID             int64      `json:"id"`
+	OrganizationID int64      `json:"organization_id"`
+	LeadID         int64      `json:"lead_id"`
+	AssignedUserID *int64     `json:"assigned_user_id"`
+	Status         string     `json:"status"`
+	LastMessageAt  *time.Time `json:"last_message_at"`
+	LastInboundAt  *time.Time `json:"last_inbound_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	ClosedAt       *time.Time `json:"closed_at"`
+	LeadName       string     `json:"lead_name,omitempty"`
+	LeadPhone      string     `json:"lead_phone,omitempty"`
+	// WindowExpiresAt é o fim da janela de atendimento, nulo se o lead
+	// nunca enviou mensagens
+	WindowExpiresAt *time.Time `json:"window_expires_at"`
+	WindowOpen      bool       `json:"window_open"`
+}
+
+// UpdateWindow calcula a janela de atendimento a partir da última mensagem
+// recebida do lead
+func (c *Conversation) UpdateWindow(now time.Time) {
+	c.WindowExpiresAt = nil
+	c.WindowOpen = false
+	if c.LastInboundAt == nil {
+		return
+	}
+	expires := c.LastInboundAt.Add(CustomerServiceWindow)
+	c.WindowExpiresAt = &expires
+	c.WindowOpen = now.Before(expires)
+}
+
+// Message é uma mensagem trocada em uma conversa. ExternalID é o
+// identificador do provedor e evita gravar a mesma mensagem duas vezes.
+type Message struct {
+	ID             int64     `json:"id"`
+	OrganizationID int64     `json:"organization_id"`
+	ConversationID int64     `json:"conversation_id"`
+	LeadID         int64     `json:"lead_id"`
+	UserID         *int64    `json:"user_id"`
+	Direction      string    `json:"direction"`
+	Type           string    `json:"type"`
+	Body           string    `json:"body"`
+	ExternalID     string    `json:"external_id"`
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"created_at"`
+	// Media é o arquivo das mensagens de imagem, áudio, vídeo, documento e figurinha
+	Media *MessageMedia `json:"media,omitempty"`
+	// CampaignID indica o envio por uma campanha; não é gravado na mensagem,
+	// apenas no histórico do lead e no destinatário da campanha
+	CampaignID int64 `json:"-"`
+	// ReplyID é o ID da opção escolhida pelo lead em botões e listas; não é
+	// gravado, apenas repassado ao fluxo do chatbot
+	ReplyID string `json:"-"`
+}
+
+// InboundResult descreve o que foi gravado para uma mensagem recebida
+type InboundResult struct {
+	Lead         *Lead
+	Conversation *Conversation
+	Message      *Message
+	// LeadCreated indica que o remetente ainda não era um lead da organização
+	LeadCreated bool
+	// Duplicate indica que a mensagem já havia sido recebida; nada foi gravado
+	Duplicate bool
+	// OptedOut indica que a mensagem era uma palavra de descadastro e o
+	// consentimento do lead no WhatsApp foi retirado
+	OptedOut bool
+}
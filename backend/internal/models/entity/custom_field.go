@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"time"
+)
+
+// Tipos de campos personalizados
+const (
+	CustomFieldText        = "text"
+	CustomFieldNumber      = "number"
+	CustomFieldDate        = "date"
+	CustomFieldSelect      = "select"
+	CustomFieldMultiSelect = "multi_select"
+	CustomFieldBoolean     = "boolean"
+)
+
+// CustomFieldTypes lista os tipos aceitos
+var CustomFieldTypes = []string{
+	CustomFieldText,
+	CustomFieldNumber,
+	CustomFieldDate,
+	CustomFieldSelect,
+	CustomFieldMultiSelect,
+	CustomFieldBoolean,
+}
+
+// CustomFieldDefinition descreve um campo personalizado dos leads de uma
+// organização. Os valores ficam em Lead.CustomFields, indexados por Key.
+type CustomFieldDefinition struct {
+	ID             int64     `json:"id"`
+	OrganizationID int64     `json:"organization_id"`
+	Key            string    `json:"key"`
+	Label          string    `json:"label"`
+	Type           string    `json:"type"`
+	Options        []string  `json:"options"`
+	Required       bool      `json:"required"`
+	Position       int       `json:"position"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// NewCustomFieldDefinition cria uma nova definição de campo personalizado
+func NewCustomFieldDefinition(organizationID int64, key, label, fieldType string) *CustomFieldDefinition {
+	return &CustomFieldDefinition{
+		OrganizationID: organizationID,
+		Key:            key,
+		Label:          label,
+		Type:           fieldType,
+		Options:        []string{},
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+}
+
+// HasOptions indica se o campo restringe os valores a uma lista de opções
+func (d *CustomFieldDefinition) HasOptions() bool {
+	return d.Type == CustomFieldSelect || d.Type == CustomFieldMultiSelect
+}
+
+// IsRangeable indica se o campo aceita filtros de intervalo (min e max)
+func (d *CustomFieldDefinition) IsRangeable() bool {
+	return d.Type == CustomFieldNumber || d.Type == CustomFieldDate
+}
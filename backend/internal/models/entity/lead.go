@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"regexp"
+	"time"
+)
+
+// Status possíveis de um lead
+const (
+	LeadStatusNew       = "new"
+	LeadStatusContacted = "contacted"
+	LeadStatusQualified = "qualified"
+	LeadStatusWon       = "won"
+	LeadStatusLost      = "lost"
+)
+
+// LeadStatuses lista os status válidos, na ordem do funil
+var LeadStatuses = []string{
+	LeadStatusNew,
+	LeadStatusContacted,
+	LeadStatusQualified,
+	LeadStatusWon,
+	LeadStatusLost,
+}
+
+// IsValidLeadStatus verifica se o status informado é conhecido
+func IsValidLeadStatus(status string) bool {
+	for _, s := range LeadStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// customFieldKeyPattern restringe as chaves dos campos personalizados
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// IsValidCustomFieldKey verifica se a chave de campo personalizado é aceita
+func IsValidCustomFieldKey(key string) bool {
+	return customFieldKeyPattern.MatchString(key)
+}
+
+// Lead representa um contato comercial de uma organização
+type Lead struct {
+	ID             int64                  `json:"id"`
+	OrganizationID int64                  `json:"organization_id"`
+	OwnerID        *int64                 `json:"owner_id"`
+	Name           string                 `json:"name"`
+	Phone          string                 `json:"phone"`
+	Email          string                 `json:"email"`
+	Source         string                 `json:"source"`
+	Status         string                 `json:"status"`
+	Stage          string                 `json:"stage"`
+	CustomFields   map[string]interface{} `json:"custom_fields"`
+	Tags           []string               `json:"tags"`
+	LastMessageAt  *time.Time             `json:"last_message_at"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+}
+
+// NewLead cria uma nova instância de lead com status inicial
+func NewLead(organizationID int64, name, phone string) *Lead {
+	return &Lead{
+		OrganizationID: organizationID,
+		Name:           name,
+		Phone:          phone,
+		Status:         LeadStatusNew,
+		CustomFields:   make(map[string]interface{}),
+		Tags:           []string{},
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"time"
+)
+
+// Status possíveis de uma exportação de leads
+const (
+	LeadExportPending    = "pending"
+	LeadExportProcessing = "processing"
+	LeadExportCompleted  = "completed"
+	LeadExportFailed     = "failed"
+	LeadExportExpired    = "expired"
+)
+
+// LeadExport registra uma exportação de leads. O registro é mantido mesmo
+// após a remoção do arquivo, servindo de trilha de auditoria (LGPD) de quem
+// exportou quais dados e quando.
+type LeadExport struct {
+	ID               int64      `json:"id"`
+	OrganizationID   int64      `json:"organization_id"`
+	UserID           int64      `json:"user_id"`
+	Format           string     `json:"format"`
+	Columns          []string   `json:"columns"`
+	Filter           LeadFilter `json:"filters"`
+	Async            bool       `json:"async"`
+	Status           string     `json:"status"`
+	RowCount         int        `json:"row_count"`
+	StorageKey       string     `json:"-"`
+	FileSize         int64      `json:"file_size"`
+	ErrorMessage     string     `json:"error_message,omitempty"`
+	IPAddress        string     `json:"ip_address"`
+	UserAgent        string     `json:"user_agent"`
+	DownloadCount    int        `json:"download_count"`
+	LastDownloadedAt *time.Time `json:"last_downloaded_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	FinishedAt       *time.Time `json:"finished_at"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+}
+
+// NewLeadExport cria o registro de uma nova exportação
+func NewLeadExport(organizationID, userID int64, format string, columns []string, filter LeadFilter, async bool) *LeadExport {
+	status := LeadExportProcessing
+	if async {
+		status = LeadExportPending
+	}
+	return &LeadExport{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Format:         format,
+		Columns:        columns,
+		Filter:         filter,
+		Async:          async,
+		Status:         status,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+}
+
+// IsDownloadable indica se o arquivo gerado ainda está disponível
+func (le *LeadExport) IsDownloadable() bool {
+	return le.Async && le.Status == LeadExportCompleted && le.ExpiresAt != nil && time.Now().Before(*le.ExpiresAt)
+}
+
+// FileName monta o nome do arquivo oferecido no download
+func (le *LeadExport) FileName() string {
+	return "leads-" + le.CreatedAt.Format("20060102-150405") + "." + le.Format
+}
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultLeadSort é a ordenação padrão da listagem de leads
+const DefaultLeadSort = "-created_at"
+
+// LeadSortFields lista os campos aceitos na ordenação; o prefixo "-" inverte a ordem
+var LeadSortFields = []string{"created_at", "updated_at", "name", "last_message_at"}
+
+// Modos de combinação das etiquetas do filtro
+const (
+	// TagModeAny seleciona leads com qualquer uma das etiquetas (padrão)
+	TagModeAny = "any"
+	// TagModeAll seleciona leads com todas as etiquetas
+	TagModeAll = "all"
+)
+
+// CustomFieldPrefix identifica um campo personalizado em filtros, ordenação,
+// importação e exportação, como "custom.modelo_carro"
+const CustomFieldPrefix = "custom."
+
+// Operadores das condições sobre campos personalizados
+const (
+	CustomFieldOpEq  = "eq"
+	CustomFieldOpMin = "min"
+	CustomFieldOpMax = "max"
+)
+
+// CustomFieldCondition filtra leads pelo valor de um campo personalizado.
+// Com eq, campos de múltipla escolha devem conter todas as opções de Value;
+// min e max delimitam o intervalo (inclusivo) de números e datas.
+type CustomFieldCondition struct {
+	Key   string      `json:"key"`
+	Op    string      `json:"op"`
+	Value interface{} `json:"value"`
+}
+
+// LeadFilter reúne os critérios da listagem de leads. É compartilhado pela
+// listagem e pela exportação para que ambas retornem exatamente os mesmos leads.
+type LeadFilter struct {
+	Statuses    []string   `json:"status,omitempty"`
+	Stage       string     `json:"stage,omitempty"`
+	Source      string     `json:"source,omitempty"`
+	OwnerID     *int64     `json:"owner_id,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	TagMode     string     `json:"tag_mode,omitempty"`
+	ExcludeTags []string   `json:"exclude_tags,omitempty"`
+	Search      string     `json:"q,omitempty"`
+	CreatedFrom *time.Time `json:"created_from,omitempty"`
+	CreatedTo   *time.Time `json:"created_to,omitempty"`
+	// Intervalo da última interação (mensagem) com o lead
+	LastMessageFrom *time.Time `json:"last_message_from,omitempty"`
+	LastMessageTo   *time.Time `json:"last_message_to,omitempty"`
+	// Janelas relativas à data da consulta, para segmentos que não envelhecem:
+	// interação nos últimos N dias e nenhuma interação há N dias (ou nunca)
+	ActiveWithinDays *int   `json:"active_within_days,omitempty"`
+	InactiveForDays  *int   `json:"inactive_for_days,omitempty"`
+	Sort             string `json:"sort,omitempty"`
+
+	CustomFields []CustomFieldCondition `json:"custom_fields,omitempty"`
+}
+
+// IsValidLeadSort verifica se a ordenação informada é suportada. Campos
+// personalizados são aceitos pela sintaxe; cabe ao chamador verificar se a
+// organização os definiu.
+func IsValidLeadSort(sort string) bool {
+	field := strings.TrimPrefix(sort, "-")
+	if key, ok := strings.CutPrefix(field, CustomFieldPrefix); ok {
+		return IsValidCustomFieldKey(key)
+	}
+	for _, f := range LeadSortFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"time"
+)
+
+// Status possíveis de uma importação de leads
+const (
+	LeadImportPending    = "pending"
+	LeadImportProcessing = "processing"
+	LeadImportCompleted  = "completed"
+	LeadImportFailed     = "failed"
+)
+
+// MaxLeadImportErrors limita quantos erros por linha são guardados em uma importação
+const MaxLeadImportErrors = 1000
+
+// ImportRowError descreve um problema de validação em uma linha da planilha
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// LeadImport representa um job de importação de leads a partir de uma planilha
+type LeadImport struct {
+	ID             int64             `json:"id"`
+	OrganizationID int64             `json:"organization_id"`
+	UserID         int64             `json:"user_id"`
+	FileName       string            `json:"file_name"`
+	FileType       string            `json:"file_type"`
+	FileData       []byte            `json:"-"`
+	Mapping        map[string]string `json:"mapping"`
+	Status         string            `json:"status"`
+	TotalRows      int               `json:"total_rows"`
+	ProcessedRows  int               `json:"processed_rows"`
+	CreatedRows    int               `json:"created_rows"`
+	FailedRows     int               `json:"failed_rows"`
+	Errors         []ImportRowError  `json:"errors"`
+	ErrorMessage   string            `json:"error_message,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	StartedAt      *time.Time        `json:"started_at"`
+	FinishedAt     *time.Time        `json:"finished_at"`
+}
+
+// Progress retorna o percentual de linhas processadas
+func (li *LeadImport) Progress() float64 {
+	if li.TotalRows == 0 {
+		if li.Status == LeadImportCompleted {
+			return 100
+		}
+		return 0
+	}
+	return float64(li.ProcessedRows) * 100 / float64(li.TotalRows)
+}
+
+// AppendErrors acumula erros respeitando o limite de MaxLeadImportErrors
+func (li *LeadImport) AppendErrors(errs []ImportRowError) {
+	room := MaxLeadImportErrors - len(li.Errors)
+	if room <= 0 {
+		return
+	}
+	if len(errs) > room {
+		errs = errs[:room]
+	}
+	li.Errors = append(li.Errors, errs...)
+}
+
+// NewLeadImport cria um novo job de importação pendente
+func NewLeadImport(organizationID, userID int64, fileName, fileType string, data []byte, mapping map[string]string) *LeadImport {
+	return &LeadImport{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		FileName:       fileName,
+		FileType:       fileType,
+		FileData:       data,
+		Mapping:        mapping,
+		Status:         LeadImportPending,
+		Errors:         []ImportRowError{},
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+}
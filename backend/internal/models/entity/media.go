@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"time"
+)
+
+// Tipos de mensagem com mídia
+const (
+	MediaImage    = "image"
+	MediaAudio    = "audio"
+	MediaVideo    = "video"
+	MediaDocument = "document"
+	MediaSticker  = "sticker"
+)
+
+// MediaTypes lista os tipos de mensagem com mídia
+var MediaTypes = []string{
+	MediaImage,
+	MediaAudio,
+	MediaVideo,
+	MediaDocument,
+	MediaSticker,
+}
+
+// IsMediaType verifica se o tipo de mensagem carrega uma mídia
+func IsMediaType(messageType string) bool {
+	for _, t := range MediaTypes {
+		if t == messageType {
+			return true
+		}
+	}
+	return false
+}
+
+// Status do arquivo de uma mídia
+const (
+	// MediaPending indica uma mídia recebida ainda não baixada do provedor
+	MediaPending = "pending"
+	MediaStored  = "stored"
+	// MediaFailed indica uma mídia que não pôde ser baixada do provedor
+	MediaFailed = "failed"
+)
+
+// MessageMedia é o arquivo de uma mensagem com mídia. O conteúdo fica no
+// armazenamento de arquivos e é acessado por URL com assinatura e validade.
+type MessageMedia struct {
+	ID        int64  `json:"id"`
+	MessageID int64  `json:"message_id"`
+	Status    string `json:"status"`
+	MIMEType  string `json:"mime_type"`
+	FileName  string `json:"file_name"`
+	Size      int64  `json:"size"`
+	SHA256    string `json:"sha256"`
+	// URL é o link temporário do arquivo, presente apenas nas mídias armazenadas
+	URL       string    `json:"url,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	OrganizationID int64 `json:"-"`
+	// ProviderMediaID é o ID da mídia no provedor, usado para baixar as
+	// mídias recebidas
+	ProviderMediaID string `json:"-"`
+	// StorageKey é a chave do arquivo no armazenamento
+	StorageKey string `json:"-"`
+	Attempts   int    `json:"-"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+)
+
+// Note é uma anotação interna da equipe sobre um lead. Mentions lista os
+// usuários mencionados com @ no texto, que são notificados.
+type Note struct {
+	ID             int64     `json:"id"`
+	OrganizationID int64     `json:"organization_id"`
+	LeadID         int64     `json:"lead_id"`
+	UserID         int64     `json:"user_id"`
+	Body           string    `json:"body"`
+	Mentions       []int64   `json:"mentions"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// NewNote cria uma nova nota
+func NewNote(organizationID, leadID, userID int64, body string) *Note {
+	return &Note{
+		OrganizationID: organizationID,
+		LeadID:         leadID,
+		UserID:         userID,
+		Body:           body,
+		Mentions:       []int64{},
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+}
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+)
+
+// Tipos de notificação
+const (
+	NotificationTaskDue      = "task.due"
+	NotificationTaskAssigned = "task.assigned"
+	NotificationNoteMention  = "note.mention"
+	NotificationLeadAssigned = "lead.assigned"
+)
+
+// Notification é um aviso para um usuário, exibido na aplicação até ser lido
+type Notification struct {
+	ID             int64                  `json:"id"`
+	OrganizationID int64                  `json:"organization_id"`
+	UserID         int64                  `json:"user_id"`
+	Type           string                 `json:"type"`
+	Title          string                 `json:"title"`
+	Body           string                 `json:"body"`
+	Data           map[string]interface{} `json:"data"`
+	ReadAt         *time.Time             `json:"read_at"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
+
+// NewNotification cria uma nova notificação não lida
+func NewNotification(organizationID, userID int64, notificationType, title string) *Notification {
+	return &Notification{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Type:           notificationType,
+		Title:          title,
+		Data:           make(map[string]interface{}),
+		CreatedAt:      time.Now(),
+	}
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+)
+
+// Organization representa uma empresa cliente (tenant). Todos os dados de
+// leads e atendimento pertencem a uma organização.
+type Organization struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// WhatsAppPhoneNumberID identifica o número da WhatsApp Cloud API que
+	// recebe as mensagens da organização
+	WhatsAppPhoneNumberID string `json:"whatsapp_phone_number_id"`
+	// WhatsAppBusinessAccountID identifica a conta comercial (WABA) onde
+	// ficam os modelos de mensagem da organização
+	WhatsAppBusinessAccountID string `json:"whatsapp_business_account_id"`
+	// WhatsAppMessagingTier é o nível de envio do número na Meta, que limita
+	// os destinatários de mensagens iniciadas pela empresa em 24 horas
+	WhatsAppMessagingTier string    `json:"whatsapp_messaging_tier"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+// NewOrganization cria uma nova instância de organização
+func NewOrganization(name string) *Organization {
+	return &Organization{
+		Name:                  name,
+		WhatsAppMessagingTier: MessagingTier250,
+		CreatedAt:             time.Now(),
+	}
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// OrganizationInviteTTL é a validade de um convite para a organização
+const OrganizationInviteTTL = 7 * 24 * time.Hour
+
+// OrganizationInvite convida uma pessoa, pelo email, a entrar na organização
+// com um papel. O token é entregue apenas na criação; o banco guarda somente
+// o seu hash.
+type OrganizationInvite struct {
+	ID             int64      `json:"id"`
+	OrganizationID int64      `json:"organization_id"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`
+	InvitedBy      *int64     `json:"invited_by"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// TokenHash é o SHA-256 do token enviado ao convidado
+	TokenHash string `json:"-"`
+}
+
+// NewOrganizationInvite cria um convite e retorna o token a entregar ao
+// convidado
+func NewOrganizationInvite(organizationID, invitedBy int64, email, role string) (*OrganizationInvite, string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	token := hex.EncodeToString(random)
+
+	invite := &OrganizationInvite{
+		OrganizationID: organizationID,
+		Email:          strings.TrimSpace(email),
+		Role:           role,
+		TokenHash:      HashInviteToken(token),
+		ExpiresAt:      time.Now().Add(OrganizationInviteTTL),
+		CreatedAt:      time.Now(),
+	}
+	if invitedBy != 0 {
+		invite.InvitedBy = &invitedBy
+	}
+	return invite, token, nil
+}
+
+// HashInviteToken calcula o hash guardado para o token do convite
+func HashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
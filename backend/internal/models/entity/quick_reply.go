@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"time"
+)
+
+// Escopos de uma resposta rápida
+const (
+	// QuickReplyScopeOrganization é uma resposta compartilhada por todos os
+	// atendentes da organização
+	QuickReplyScopeOrganization = "organization"
+	// QuickReplyScopePersonal é uma resposta visível apenas ao atendente que
+	// a criou
+	QuickReplyScopePersonal = "personal"
+)
+
+// QuickReplyScopes lista os escopos aceitos
+var QuickReplyScopes = []string{QuickReplyScopeOrganization, QuickReplyScopePersonal}
+
+// QuickReply é uma resposta pronta inserida pelos atendentes nas conversas
+// pelo atalho, como "/preco". O texto aceita variáveis como {{lead.name}} e
+// {{agent.name}}, preenchidas no envio.
+type QuickReply struct {
+	ID             int64  `json:"id"`
+	OrganizationID int64  `json:"organization_id"`
+	Scope          string `json:"scope"`
+	// UserID é o dono das respostas pessoais; nulo nas da organização
+	UserID      *int64                  `json:"user_id"`
+	Shortcut    string                  `json:"shortcut"`
+	Title       string                  `json:"title"`
+	Body        string                  `json:"body"`
+	Attachments []*QuickReplyAttachment `json:"attachments"`
+	UsageCount  int                     `json:"usage_count"`
+	LastUsedAt  *time.Time              `json:"last_used_at"`
+	CreatedBy   *int64                  `json:"created_by"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+}
+
+// NewQuickReply cria uma nova resposta rápida da organização
+func NewQuickReply(organizationID, userID int64) *QuickReply {
+	q := &QuickReply{
+		OrganizationID: organizationID,
+		Scope:          QuickReplyScopeOrganization,
+		Attachments:    []*QuickReplyAttachment{},
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if userID != 0 {
+		q.CreatedBy = &userID
+	}
+	return q
+}
+
+// QuickReplyAttachment é um arquivo enviado junto com a resposta rápida. O
+// conteúdo fica no armazenamento de arquivos.
+type QuickReplyAttachment struct {
+	ID           int64     `json:"id"`
+	QuickReplyID int64     `json:"quick_reply_id"`
+	MIMEType     string    `json:"mime_type"`
+	FileName     string    `json:"file_name"`
+	Size         int64     `json:"size"`
+	SHA256       string    `json:"sha256"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	OrganizationID int64 `json:"-"`
+	// StorageKey é a chave do arquivo no armazenamento
+	StorageKey string `json:"-"`
+}
+
+// QuickReplyFilter restringe a listagem das respostas rápidas
+type QuickReplyFilter struct {
+	// Query busca no atalho e no título
+	Query string
+	Scope string
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+)
+
+// Tipos de registro encontrados pela busca textual
+const (
+	SearchTypeLead    = "lead"
+	SearchTypeNote    = "note"
+	SearchTypeMessage = "message"
+)
+
+// SearchTypes lista os tipos pesquisáveis
+var SearchTypes = []string{SearchTypeLead, SearchTypeNote, SearchTypeMessage}
+
+// IsValidSearchType verifica se o tipo informado é pesquisável
+func IsValidSearchType(searchType string) bool {
+	for _, t := range SearchTypes {
+		if t == searchType {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchQuery reúne os critérios da busca textual de uma organização
+type SearchQuery struct {
+	Text   string
+	Types  []string
+	LeadID *int64
+	From   *time.Time
+	To     *time.Time
+}
+
+// SearchHit é um registro encontrado pela busca. Snippet traz o trecho do
+// texto com os termos encontrados entre <mark> e </mark>, com o restante
+// escapado para HTML.
+type SearchHit struct {
+	Type           string    `json:"type"`
+	ID             int64     `json:"id"`
+	LeadID         int64     `json:"lead_id"`
+	LeadName       string    `json:"lead_name"`
+	ConversationID *int64    `json:"conversation_id,omitempty"`
+	Snippet        string    `json:"snippet"`
+	Rank           float64   `json:"rank"`
+	CreatedAt      time.Time `json:"created_at"`
+}
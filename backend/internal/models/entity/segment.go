@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+)
+
+// Segment é um filtro de leads salvo pela organização. Os leads não são
+// gravados: o segmento é avaliado a cada uso, servindo de público para
+// campanhas, exportações e relatórios.
+type Segment struct {
+	ID             int64      `json:"id"`
+	OrganizationID int64      `json:"organization_id"`
+	UserID         int64      `json:"user_id"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	Filter         LeadFilter `json:"filters"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// NewSegment cria um novo segmento
+func NewSegment(organizationID, userID int64, name string, filter LeadFilter) *Segment {
+	return &Segment{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Name:           name,
+		Filter:         filter,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+}
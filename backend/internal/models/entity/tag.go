@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxTagNameLength limita o tamanho do nome de uma etiqueta
+const MaxTagNameLength = 50
+
+// Tag é uma etiqueta da organização aplicada aos leads. O nome é único na
+// organização sem distinção de maiúsculas.
+type Tag struct {
+	ID             int64     `json:"id"`
+	OrganizationID int64     `json:"organization_id"`
+	Name           string    `json:"name"`
+	LeadCount      int       `json:"lead_count"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// NewTag cria uma nova etiqueta
+func NewTag(organizationID int64, name string) *Tag {
+	return &Tag{
+		OrganizationID: organizationID,
+		Name:           name,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// IsValidTagName verifica o nome já sem espaços nas pontas. Vírgulas não são
+// aceitas, pois separam as etiquetas nos filtros da query string.
+func IsValidTagName(name string) bool {
+	return name != "" && utf8.RuneCountInString(name) <= MaxTagNameLength && !strings.Contains(name, ",")
+}
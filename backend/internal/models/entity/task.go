@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"time"
+)
+
+// Status possíveis de uma tarefa
+const (
+	TaskStatusOpen = "open"
+	TaskStatusDone = "done"
+)
+
+// Task é uma tarefa de acompanhamento de um lead, com prazo e responsável.
+// Em RemindAt (por padrão o próprio prazo) o responsável recebe um lembrete.
+type Task struct {
+	ID             int64      `json:"id"`
+	OrganizationID int64      `json:"organization_id"`
+	LeadID         int64      `json:"lead_id"`
+	LeadName       string     `json:"lead_name"`
+	CreatedBy      int64      `json:"created_by"`
+	AssigneeID     *int64     `json:"assignee_id"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Status         string     `json:"status"`
+	DueAt          time.Time  `json:"due_at"`
+	RemindAt       time.Time  `json:"remind_at"`
+	ReminderSentAt *time.Time `json:"reminder_sent_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// NewTask cria uma nova tarefa aberta, com lembrete no prazo
+func NewTask(organizationID, leadID, createdBy int64, title string, dueAt time.Time) *Task {
+	return &Task{
+		OrganizationID: organizationID,
+		LeadID:         leadID,
+		CreatedBy:      createdBy,
+		Title:          title,
+		Status:         TaskStatusOpen,
+		DueAt:          dueAt,
+		RemindAt:       dueAt,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+}
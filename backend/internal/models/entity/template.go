@@ -0,0 +1,103 @@
+package entity
+
+import (
+	"time"
+)
+
+// Categorias de modelo de mensagem aceitas pelo WhatsApp
+const (
+	TemplateCategoryMarketing      = "MARKETING"
+	TemplateCategoryUtility        = "UTILITY"
+	TemplateCategoryAuthentication = "AUTHENTICATION"
+)
+
+// Status de um modelo de mensagem. Modelos nascem como rascunho e só podem
+// ser usados fora da janela de atendimento depois de aprovados pelo WhatsApp.
+const (
+	TemplateStatusDraft    = "draft"
+	TemplateStatusPending  = "pending"
+	TemplateStatusApproved = "approved"
+	TemplateStatusRejected = "rejected"
+	TemplateStatusPaused   = "paused"
+	TemplateStatusDisabled = "disabled"
+)
+
+// TemplateStatuses lista os status aceitos no filtro da listagem
+var TemplateStatuses = []string{
+	TemplateStatusDraft,
+	TemplateStatusPending,
+	TemplateStatusApproved,
+	TemplateStatusRejected,
+	TemplateStatusPaused,
+	TemplateStatusDisabled,
+}
+
+// Tipos de botão de um modelo de mensagem
+const (
+	TemplateButtonQuickReply  = "QUICK_REPLY"
+	TemplateButtonURL         = "URL"
+	TemplateButtonPhoneNumber = "PHONE_NUMBER"
+)
+
+// MessageTemplate é um modelo de mensagem do WhatsApp. Os textos usam
+// marcadores {{1}}, {{2}}... preenchidos, na ordem, pelos campos do lead
+// indicados nas variáveis de cada componente.
+type MessageTemplate struct {
+	ID              int64              `json:"id"`
+	OrganizationID  int64              `json:"organization_id"`
+	Name            string             `json:"name"`
+	Language        string             `json:"language"`
+	Category        string             `json:"category"`
+	Header          string             `json:"header"`
+	HeaderVariables []TemplateVariable `json:"header_variables"`
+	Body            string             `json:"body"`
+	BodyVariables   []TemplateVariable `json:"body_variables"`
+	Footer          string             `json:"footer"`
+	Buttons         []TemplateButton   `json:"buttons"`
+	Status          string             `json:"status"`
+	ExternalID      string             `json:"external_id"`
+	RejectionReason string             `json:"rejection_reason"`
+	CreatedBy       *int64             `json:"created_by"`
+	SubmittedAt     *time.Time         `json:"submitted_at"`
+	SyncedAt        *time.Time         `json:"synced_at"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// TemplateVariable associa um marcador a um campo do lead (name, phone,
+// email, source, status, stage ou custom.<chave>). Example é o valor enviado
+// ao WhatsApp na aprovação e usado na prévia quando o lead não tem o campo.
+type TemplateVariable struct {
+	Field   string `json:"field"`
+	Example string `json:"example"`
+}
+
+// TemplateButton é um botão do modelo. Botões de URL aceitam um marcador
+// {{1}} no fim da URL, preenchido por Variable.
+type TemplateButton struct {
+	Type        string            `json:"type"`
+	Text        string            `json:"text"`
+	URL         string            `json:"url,omitempty"`
+	PhoneNumber string            `json:"phone_number,omitempty"`
+	Variable    *TemplateVariable `json:"variable,omitempty"`
+}
+
+// NewMessageTemplate cria um modelo em rascunho
+func NewMessageTemplate(organizationID, createdBy int64) *MessageTemplate {
+	return &MessageTemplate{
+		OrganizationID:  organizationID,
+		CreatedBy:       &createdBy,
+		HeaderVariables: []TemplateVariable{},
+		BodyVariables:   []TemplateVariable{},
+		Buttons:         []TemplateButton{},
+		Status:          TemplateStatusDraft,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+}
+
+// IsEditable indica se o modelo pode ser alterado: rascunhos e modelos
+// rejeitados, que voltam a rascunho para nova submissão
+func (t *MessageTemplate) IsEditable() bool {
+	return t.Status == TemplateStatusDraft || t.Status == TemplateStatusRejected
+}
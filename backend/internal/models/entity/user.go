@@ -6,14 +6,27 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Papéis dos usuários na organização
+const (
+	// UserRoleAdmin gerencia a organização: membros, convites e canal do WhatsApp
+	UserRoleAdmin = "admin"
+	// UserRoleAgent atende os leads e conversas da organização
+	UserRoleAgent = "agent"
+)
+
+// UserRoles lista os papéis aceitos
+var UserRoles = []string{UserRoleAdmin, UserRoleAgent}
+
 // User representa um usuário no sistema
 type User struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Password  string    `json:"-"` // O campo password não é serializado para JSON
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             int64     `json:"id"`
+	OrganizationID int64     `json:"organization_id"`
+	Name           string    `json:"name"`
+	Email          string    `json:"email"`
+	Role           string    `json:"role"`
+	Password       string    `json:"-"` // O campo password não é serializado para JSON
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 // HashPassword cria um hash da senha do usuário
@@ -32,12 +45,18 @@ func (u *User) ComparePassword(password string) bool {
 	return err == nil
 }
 
+// IsAdmin indica se o usuário administra a organização
+func (u *User) IsAdmin() bool {
+	return u.Role == UserRoleAdmin
+}
+
 // NewUser cria uma nova instância de usuário
 func NewUser(name, email, password string) (*User, error) {
 	user := &User{
 		Name:      name,
 		Email:     email,
 		Password:  password,
+		Role:      UserRoleAgent,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -0,0 +1,219 @@
+package openapi
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version é a versão da especificação OpenAPI gerada
+const Version = "3.0.3"
+
+// BearerAuth é o nome do esquema de segurança usado pelas rotas protegidas
+const BearerAuth = "bearerAuth"
+
+// Document representa a raiz de um documento OpenAPI 3
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info descreve a API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server descreve um endereço base da API
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag agrupa operações relacionadas
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem mapeia o método HTTP (em minúsculas) para a operação correspondente
+type PathItem map[string]*Operation
+
+// Operation descreve uma rota da API
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter descreve um parâmetro de caminho, query ou cabeçalho
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody descreve o corpo de uma requisição
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response descreve uma resposta da operação
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType associa um content type a um schema
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components reúne os schemas e esquemas de segurança reutilizáveis
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme descreve uma forma de autenticação
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// NewDocument cria um documento vazio já com o esquema de autenticação Bearer
+func NewDocument(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]SecurityScheme{
+				BearerAuth: {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+					Description:  "Token de acesso obtido em /api/auth/login",
+				},
+			},
+		},
+	}
+}
+
+// Add registra uma operação para o método e caminho informados
+func (d *Document) Add(method, path string, op *Operation) {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		d.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+}
+
+// Has indica se existe uma operação documentada para o método e caminho
+func (d *Document) Has(method, path string) bool {
+	item, ok := d.Paths[path]
+	if !ok {
+		return false
+	}
+	_, ok = item[strings.ToLower(method)]
+	return ok
+}
+
+// Routes lista as operações documentadas no formato "MÉTODO caminho", em ordem
+func (d *Document) Routes() []string {
+	var routes []string
+	for path, item := range d.Paths {
+		for method := range item {
+			routes = append(routes, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// Schema obtém (e registra nos componentes, se necessário) o schema do tipo de v
+func (d *Document) Schema(v interface{}) *Schema {
+	return schemaOf(d.Components.Schemas, v)
+}
+
+// JSONBody cria o corpo de requisição obrigatório a partir do tipo de v
+func (d *Document) JSONBody(v interface{}) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{"application/json": {Schema: d.Schema(v)}},
+	}
+}
+
+// MultipartBody cria o corpo multipart/form-data a partir do tipo de v.
+// Campos []byte são descritos como arquivos.
+func (d *Document) MultipartBody(v interface{}) *RequestBody {
+	schema := structSchema(d.Components.Schemas, reflect.TypeOf(v))
+	for _, prop := range schema.Properties {
+		if prop.Format == "byte" {
+			prop.Format = "binary"
+		}
+	}
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{"multipart/form-data": {Schema: schema}},
+	}
+}
+
+// JSONResponse cria uma resposta JSON a partir do tipo de v
+func (d *Document) JSONResponse(description string, v interface{}) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: d.Schema(v)}},
+	}
+}
+
+// ProblemResponse cria uma resposta de erro no formato RFC 7807
+func (d *Document) ProblemResponse(description string, v interface{}) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/problem+json": {Schema: d.Schema(v)}},
+	}
+}
+
+// EmptyResponse cria uma resposta sem corpo
+func EmptyResponse(description string) Response {
+	return Response{Description: description}
+}
+
+// Secured exige o token Bearer na operação
+func Secured() []map[string][]string {
+	return []map[string][]string{{BearerAuth: {}}}
+}
+
+// PathParam cria um parâmetro de caminho obrigatório
+func PathParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Required: true, Schema: schema}
+}
+
+// QueryParam cria um parâmetro de query opcional
+func QueryParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+// Status converte o código HTTP na chave usada em Responses
+func Status(code int) string {
+	return strconv.Itoa(code)
+}
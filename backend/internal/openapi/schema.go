@@ -0,0 +1,218 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema representa um JSON Schema no dialeto do OpenAPI 3.0
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// String cria um schema de texto
+func String() *Schema { return &Schema{Type: "string"} }
+
+// Integer cria um schema de número inteiro de 64 bits
+func Integer() *Schema { return &Schema{Type: "integer", Format: "int64"} }
+
+// Boolean cria um schema booleano
+func Boolean() *Schema { return &Schema{Type: "boolean"} }
+
+// Enum cria um schema de texto restrito aos valores informados
+func Enum(values ...string) *Schema { return &Schema{Type: "string", Enum: values} }
+
+// ArrayOf cria um schema de lista com os itens informados
+func ArrayOf(items *Schema) *Schema { return &Schema{Type: "array", Items: items} }
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf gera o schema de v a partir das tags json e validate. Structs
+// nomeados são registrados em components e referenciados por $ref.
+func schemaOf(components map[string]*Schema, v interface{}) *Schema {
+	if s, ok := v.(*Schema); ok {
+		return s
+	}
+	return schemaForType(components, reflect.TypeOf(v))
+}
+
+func schemaForType(components map[string]*Schema, t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var s *Schema
+	switch {
+	case t == timeType:
+		s = &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct:
+		name := t.Name()
+		if name == "" {
+			return structSchema(components, t)
+		}
+		if _, ok := components[name]; !ok {
+			// Registra antes de percorrer os campos para suportar tipos recursivos
+			components[name] = &Schema{}
+			*components[name] = *structSchema(components, t)
+		}
+		s = &Schema{Ref: "#/components/schemas/" + name}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s = &Schema{Type: "string", Format: "byte"}
+		} else {
+			s = ArrayOf(schemaForType(components, t.Elem()))
+		}
+	case t.Kind() == reflect.Map:
+		s = &Schema{Type: "object", AdditionalProperties: schemaForType(components, t.Elem())}
+	case t.Kind() == reflect.String:
+		s = String()
+	case t.Kind() == reflect.Bool:
+		s = Boolean()
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		s = Integer()
+		if t.Kind() == reflect.Int32 || t.Kind() == reflect.Uint32 {
+			s.Format = "int32"
+		}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s = &Schema{Type: "number", Format: "double"}
+	default:
+		// interface{} e afins aceitam qualquer valor
+		s = &Schema{}
+	}
+
+	if nullable && s.Ref == "" {
+		s.Nullable = true
+	}
+	return s
+}
+
+func structSchema(components map[string]*Schema, t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	request := isRequestType(t)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		embeddedType := f.Type
+		if embeddedType.Kind() == reflect.Ptr {
+			embeddedType = embeddedType.Elem()
+		}
+		// Como no encoding/json, os campos de structs embutidos são promovidos
+		// mesmo que o tipo embutido não seja exportado
+		if f.Anonymous && name == "" && embeddedType.Kind() == reflect.Struct {
+			embedded := structSchema(components, embeddedType)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := schemaForType(components, f.Type)
+		if prop.Ref != "" {
+			// Restrições não podem acompanhar $ref no OpenAPI 3.0
+			s.Properties[name] = prop
+			continue
+		}
+		if desc := f.Tag.Get("doc"); desc != "" {
+			prop.Description = desc
+		}
+		if applyValidateTag(prop, f.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		} else if !request && !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Ptr {
+			// Campos de resposta sempre presentes também são obrigatórios
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+
+	return s
+}
+
+// applyValidateTag traduz as regras de validação em restrições do schema e
+// informa se o campo é obrigatório
+func applyValidateTag(s *Schema, tag string) bool {
+	required := false
+	for _, part := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		n, _ := strconv.Atoi(param)
+
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "e164":
+			s.Pattern = `^\+[1-9][0-9]{7,14}$`
+		case "phone":
+			s.Format = "phone"
+		case "password":
+			s.Format = "password"
+			minLen, maxLen := 8, 72
+			s.MinLength, s.MaxLength = &minLen, &maxLen
+		case "oneof":
+			s.Enum = strings.Fields(param)
+		case "min":
+			switch s.Type {
+			case "string":
+				s.MinLength = &n
+			case "array":
+				s.MinItems = &n
+			default:
+				f := float64(n)
+				s.Minimum = &f
+			}
+		case "max":
+			switch s.Type {
+			case "string":
+				s.MaxLength = &n
+			case "array":
+				s.MaxItems = &n
+			default:
+				f := float64(n)
+				s.Maximum = &f
+			}
+		}
+	}
+	return required
+}
+
+// isRequestType identifica DTOs de entrada pela presença de tags validate;
+// neles apenas a regra required torna um campo obrigatório
+func isRequestType(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("validate"); ok {
+			return true
+		}
+	}
+	return false
+}
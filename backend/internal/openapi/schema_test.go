@@ -0,0 +1,197 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type createLeadRequest struct {
+	Name   string   `json:"name" validate:"required,min=2,max=100" doc:"Nome completo"`
+	Email  string   `json:"email" validate:"email,max=100"`
+	Phone  string   `json:"phone" validate:"required,e164"`
+	Status string   `json:"status" validate:"oneof=new won lost"`
+	Tags   []string `json:"tags" validate:"max=10"`
+	Score  int      `json:"score" validate:"min=0,max=100"`
+	Note   *string  `json:"note"`
+}
+
+type auditFields struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type leadResponse struct {
+	auditFields
+	ID       int64             `json:"id"`
+	Name     string            `json:"name"`
+	Owner    *int64            `json:"owner_id"`
+	Source   string            `json:"source,omitempty"`
+	Custom   map[string]string `json:"custom"`
+	Avatar   []byte            `json:"avatar"`
+	Weight   float64           `json:"weight"`
+	Count32  int32             `json:"count32"`
+	Extra    interface{}       `json:"extra"`
+	Internal string            `json:"-"`
+	Parent   *leadResponse     `json:"parent"`
+	hidden   string
+}
+
+type uploadRequest struct {
+	File   []byte `json:"file" validate:"required"`
+	DryRun bool   `json:"dry_run"`
+}
+
+func TestRequestSchema(t *testing.T) {
+	components := map[string]*Schema{}
+	ref := schemaOf(components, createLeadRequest{})
+	if ref.Ref != "#/components/schemas/createLeadRequest" {
+		t.Fatalf("schemaOf = %+v, esperado $ref para createLeadRequest", ref)
+	}
+	s := components["createLeadRequest"]
+
+	if !reflect.DeepEqual(s.Required, []string{"name", "phone"}) {
+		t.Errorf("Required = %v, esperado [name phone]", s.Required)
+	}
+
+	tests := []struct {
+		field string
+		check func(p *Schema) bool
+	}{
+		{"name", func(p *Schema) bool {
+			return p.Type == "string" && *p.MinLength == 2 && *p.MaxLength == 100 && p.Description == "Nome completo"
+		}},
+		{"email", func(p *Schema) bool { return p.Format == "email" && *p.MaxLength == 100 }},
+		{"phone", func(p *Schema) bool { return p.Pattern == `^\+[1-9][0-9]{7,14}$` }},
+		{"status", func(p *Schema) bool { return reflect.DeepEqual(p.Enum, []string{"new", "won", "lost"}) }},
+		{"tags", func(p *Schema) bool { return p.Type == "array" && p.Items.Type == "string" && *p.MaxItems == 10 }},
+		{"score", func(p *Schema) bool {
+			return p.Type == "integer" && *p.Minimum == 0 && *p.Maximum == 100 && p.MinLength == nil
+		}},
+		{"note", func(p *Schema) bool { return p.Type == "string" && p.Nullable }},
+	}
+
+	for _, tt := range tests {
+		p, ok := s.Properties[tt.field]
+		if !ok {
+			t.Errorf("propriedade %q ausente", tt.field)
+			continue
+		}
+		if !tt.check(p) {
+			data, _ := json.Marshal(p)
+			t.Errorf("propriedade %q = %s", tt.field, data)
+		}
+	}
+}
+
+func TestResponseSchema(t *testing.T) {
+	components := map[string]*Schema{}
+	schemaOf(components, &leadResponse{})
+	s := components["leadResponse"]
+	if s == nil {
+		t.Fatal("leadResponse não registrado nos componentes")
+	}
+
+	// Campos sempre presentes são obrigatórios; ponteiros e omitempty não
+	want := []string{"created_at", "id", "name", "custom", "avatar", "weight", "count32", "extra"}
+	if !reflect.DeepEqual(s.Required, want) {
+		t.Errorf("Required = %v, esperado %v", s.Required, want)
+	}
+
+	tests := []struct {
+		field string
+		want  Schema
+	}{
+		{"created_at", Schema{Type: "string", Format: "date-time"}},
+		{"id", Schema{Type: "integer", Format: "int64"}},
+		{"owner_id", Schema{Type: "integer", Format: "int64", Nullable: true}},
+		{"custom", Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}},
+		{"avatar", Schema{Type: "string", Format: "byte"}},
+		{"weight", Schema{Type: "number", Format: "double"}},
+		{"count32", Schema{Type: "integer", Format: "int32"}},
+		{"extra", Schema{}},
+		{"parent", Schema{Ref: "#/components/schemas/leadResponse"}},
+	}
+
+	for _, tt := range tests {
+		got, ok := s.Properties[tt.field]
+		if !ok {
+			t.Errorf("propriedade %q ausente", tt.field)
+			continue
+		}
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("propriedade %q = %+v, esperado %+v", tt.field, *got, tt.want)
+		}
+	}
+
+	for _, field := range []string{"Internal", "-", "hidden"} {
+		if _, ok := s.Properties[field]; ok {
+			t.Errorf("propriedade %q não deveria ser documentada", field)
+		}
+	}
+}
+
+func TestSchemaOfExplicitSchema(t *testing.T) {
+	explicit := ArrayOf(Enum("a", "b"))
+	if got := schemaOf(map[string]*Schema{}, explicit); got != explicit {
+		t.Errorf("schemaOf(*Schema) = %+v, esperado o próprio schema", got)
+	}
+}
+
+func TestDocument(t *testing.T) {
+	doc := NewDocument(Info{Title: "API", Version: "1"})
+	doc.Add("GET", "/api/leads", &Operation{OperationID: "listLeads", Security: Secured()})
+	doc.Add("post", "/api/leads", &Operation{OperationID: "createLead", RequestBody: doc.JSONBody(createLeadRequest{})})
+	doc.Add("DELETE", "/api/leads/{id}", &Operation{OperationID: "deleteLead"})
+
+	tests := []struct {
+		method, path string
+		want         bool
+	}{
+		{"GET", "/api/leads", true},
+		{"get", "/api/leads", true},
+		{"POST", "/api/leads", true},
+		{"PUT", "/api/leads", false},
+		{"DELETE", "/api/leads/{id}", true},
+		{"GET", "/api/leads/{id}", false},
+		{"GET", "/api/inexistente", false},
+	}
+	for _, tt := range tests {
+		if got := doc.Has(tt.method, tt.path); got != tt.want {
+			t.Errorf("Has(%q, %q) = %v, esperado %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+
+	want := []string{"DELETE /api/leads/{id}", "GET /api/leads", "POST /api/leads"}
+	if got := doc.Routes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Routes() = %v, esperado %v", got, want)
+	}
+	if _, ok := doc.Components.Schemas["createLeadRequest"]; !ok {
+		t.Error("JSONBody não registrou o schema do corpo")
+	}
+	if _, ok := doc.Components.SecuritySchemes[BearerAuth]; !ok {
+		t.Error("documento sem o esquema de segurança Bearer")
+	}
+	if _, err := json.Marshal(doc); err != nil {
+		t.Errorf("documento não serializa: %v", err)
+	}
+}
+
+func TestMultipartBody(t *testing.T) {
+	doc := NewDocument(Info{Title: "API", Version: "1"})
+	body := doc.MultipartBody(uploadRequest{})
+
+	s := body.Content["multipart/form-data"].Schema
+	if s == nil {
+		t.Fatal("corpo sem multipart/form-data")
+	}
+	if got := s.Properties["file"].Format; got != "binary" {
+		t.Errorf("file.Format = %q, esperado binary", got)
+	}
+	if !reflect.DeepEqual(s.Required, []string{"file"}) {
+		t.Errorf("Required = %v, esperado [file]", s.Required)
+	}
+	if _, ok := doc.Components.Schemas["uploadRequest"]; ok {
+		t.Error("o corpo multipart não deveria ser registrado nos componentes")
+	}
+}
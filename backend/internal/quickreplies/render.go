@@ -0,0 +1,166 @@
+// Package quickreplies valida e envia as respostas rápidas dos atendentes,
+// preenchendo as variáveis com os campos do lead e do atendente
+package quickreplies
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+	"github.com/whatsapp/backend/internal/templates"
+)
+
+// Limites das respostas rápidas
+const (
+	MaxShortcutLength = 30
+	MaxTitleLength    = 100
+	// MaxBodyLength é o limite do WhatsApp para mensagens de texto
+	MaxBodyLength  = 4096
+	MaxAttachments = 5
+	// MaxAttachmentSize limita cada anexo, lido em memória a cada envio
+	MaxAttachmentSize = 16 << 20
+)
+
+// Prefixos das variáveis
+const (
+	leadPrefix  = "lead."
+	agentPrefix = "agent."
+)
+
+var (
+	shortcutPattern = regexp.MustCompile(`^/[a-z0-9_-]+$`)
+	variablePattern = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+)
+
+// agentFields lista os campos do atendente aceitos nas variáveis
+var agentFields = map[string]bool{"name": true, "first_name": true, "email": true}
+
+// Rendered é o texto de uma resposta com as variáveis preenchidas. Missing
+// lista as variáveis sem valor, deixadas vazias no texto.
+type Rendered struct {
+	Text    string   `json:"text"`
+	Missing []string `json:"missing"`
+}
+
+// NormalizeShortcut padroniza o atalho em minúsculas, começando por "/"
+func NormalizeShortcut(shortcut string) string {
+	shortcut = strings.ToLower(strings.TrimSpace(shortcut))
+	if shortcut != "" && !strings.HasPrefix(shortcut, "/") {
+		shortcut = "/" + shortcut
+	}
+	return shortcut
+}
+
+// Variables retorna as variáveis do texto, em ordem de aparição e sem
+// repetições, ou false se houver chaves fora do formato {{variável}}
+func Variables(body string) ([]string, bool) {
+	matches := variablePattern.FindAllStringSubmatch(body, -1)
+	if strings.Count(body, "{{") != len(matches) || strings.Count(body, "}}") != len(matches) {
+		return nil, false
+	}
+	seen := make(map[string]bool, len(matches))
+	variables := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			variables = append(variables, m[1])
+		}
+	}
+	return variables, true
+}
+
+// Validate verifica o atalho, o título e as variáveis do texto. As variáveis
+// de lead aceitam os campos fixos, lead.first_name e os campos
+// personalizados definidos em schema.
+func Validate(q *entity.QuickReply, schema customfields.Schema) []response.FieldError {
+	var errs []response.FieldError
+	add := func(field, code, message string) {
+		errs = append(errs, response.FieldError{Field: field, Code: code, Message: message})
+	}
+
+	if !shortcutPattern.MatchString(q.Shortcut) || utf8.RuneCountInString(q.Shortcut) > MaxShortcutLength+1 {
+		add("shortcut", "invalid", fmt.Sprintf("Use até %d letras minúsculas sem acento, números, - ou _, como /preco", MaxShortcutLength))
+	}
+	if q.Title == "" || utf8.RuneCountInString(q.Title) > MaxTitleLength {
+		add("title", "range", fmt.Sprintf("Informe o título com no máximo %d caracteres", MaxTitleLength))
+	}
+	if utf8.RuneCountInString(q.Body) > MaxBodyLength {
+		add("body", "max", fmt.Sprintf("O texto deve ter no máximo %d caracteres", MaxBodyLength))
+	}
+
+	variables, ok := Variables(q.Body)
+	if !ok {
+		add("body", "invalid_placeholder", "Use as variáveis no formato {{lead.name}} ou {{agent.name}}")
+		return errs
+	}
+	for _, v := range variables {
+		if !isVariable(v, schema) {
+			add("body", "unknown_variable", fmt.Sprintf("Variável {{%s}} não existe; use lead.<campo>, lead.custom.<chave> ou agent.name, agent.first_name e agent.email", v))
+		}
+	}
+	return errs
+}
+
+// Render preenche as variáveis do texto com os campos do lead e do atendente
+func Render(body string, lead *entity.Lead, agent *entity.User) Rendered {
+	rendered := Rendered{Missing: []string{}}
+	missing := map[string]bool{}
+	rendered.Text = variablePattern.ReplaceAllStringFunc(body, func(m string) string {
+		variable := variablePattern.FindStringSubmatch(m)[1]
+		value := strings.TrimSpace(variableValue(variable, lead, agent))
+		if value == "" && !missing[variable] {
+			missing[variable] = true
+			rendered.Missing = append(rendered.Missing, variable)
+		}
+		return value
+	})
+	rendered.Text = strings.TrimSpace(rendered.Text)
+	return rendered
+}
+
+func isVariable(variable string, schema customfields.Schema) bool {
+	if field, ok := strings.CutPrefix(variable, leadPrefix); ok {
+		return field == "first_name" || templates.IsLeadField(field, schema)
+	}
+	if field, ok := strings.CutPrefix(variable, agentPrefix); ok {
+		return agentFields[field]
+	}
+	return false
+}
+
+func variableValue(variable string, lead *entity.Lead, agent *entity.User) string {
+	if field, ok := strings.CutPrefix(variable, leadPrefix); ok {
+		if lead == nil {
+			return ""
+		}
+		if field == "first_name" {
+			return firstName(lead.Name)
+		}
+		return templates.LeadFieldValue(lead, field)
+	}
+
+	field, ok := strings.CutPrefix(variable, agentPrefix)
+	if !ok || agent == nil {
+		return ""
+	}
+	switch field {
+	case "name":
+		return agent.Name
+	case "first_name":
+		return firstName(agent.Name)
+	case "email":
+		return agent.Email
+	}
+	return ""
+}
+
+func firstName(name string) string {
+	if fields := strings.Fields(name); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+package quickreplies
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+var schema = customfields.Schema{"plano": &entity.CustomFieldDefinition{Key: "plano"}}
+
+// codes resume os erros como "campo:código" para comparar nas tabelas
+func codes(errs []response.FieldError) string {
+	parts := make([]string, len(errs))
+	for i, e := range errs {
+		parts[i] = e.Field + ":" + e.Code
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestNormalizeShortcut(t *testing.T) {
+	tests := []struct {
+		shortcut string
+		want     string
+	}{
+		{"/preco", "/preco"},
+		{"preco", "/preco"},
+		{" /PRECO ", "/preco"},
+		{"", ""},
+		{"  ", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeShortcut(tt.shortcut); got != tt.want {
+			t.Errorf("NormalizeShortcut(%q) = %q, esperado %q", tt.shortcut, got, tt.want)
+		}
+	}
+}
+
+func TestVariables(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+		ok   bool
+	}{
+		{"", []string{}, true},
+		{"Olá", []string{}, true},
+		{"Olá {{lead.name}}", []string{"lead.name"}, true},
+		{"Olá {{ lead.name }}", []string{"lead.name"}, true},
+		{"{{agent.name}} e {{lead.name}} e {{agent.name}}", []string{"agent.name", "lead.name"}, true},
+		{"Olá {{}}", []string{""}, true},
+		{"Olá {{lead.name}", nil, false},
+		{"Olá {lead.name}}", nil, false},
+		{"Olá {{lead.{{name}}}}", nil, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Variables(tt.body)
+		if ok != tt.ok || (tt.ok && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("Variables(%q) = %q, %v; esperado %q, %v", tt.body, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *entity.QuickReply {
+		return &entity.QuickReply{
+			Shortcut: "/preco",
+			Title:    "Tabela de preços",
+			Body:     "Olá {{lead.first_name}}, seu plano {{lead.custom.plano}} custa R$ 99. Att, {{agent.first_name}}",
+		}
+	}
+
+	tests := []struct {
+		name string
+		edit func(q *entity.QuickReply)
+		want string
+	}{
+		{"válida", func(q *entity.QuickReply) {}, ""},
+		{"sem variáveis", func(q *entity.QuickReply) { q.Body = "Segue a tabela" }, ""},
+		{"texto vazio", func(q *entity.QuickReply) { q.Body = "" }, ""},
+		{"atalho sem barra", func(q *entity.QuickReply) { q.Shortcut = "preco" }, "shortcut:invalid"},
+		{"atalho com maiúsculas", func(q *entity.QuickReply) { q.Shortcut = "/Preco" }, "shortcut:invalid"},
+		{"atalho com acento", func(q *entity.QuickReply) { q.Shortcut = "/preço" }, "shortcut:invalid"},
+		{"atalho só com barra", func(q *entity.QuickReply) { q.Shortcut = "/" }, "shortcut:invalid"},
+		{"atalho no limite", func(q *entity.QuickReply) { q.Shortcut = "/" + strings.Repeat("a", MaxShortcutLength) }, ""},
+		{"atalho longo", func(q *entity.QuickReply) { q.Shortcut = "/" + strings.Repeat("a", MaxShortcutLength+1) }, "shortcut:invalid"},
+		{"sem título", func(q *entity.QuickReply) { q.Title = "" }, "title:range"},
+		{"título longo", func(q *entity.QuickReply) { q.Title = strings.Repeat("á", MaxTitleLength+1) }, "title:range"},
+		{"texto longo", func(q *entity.QuickReply) { q.Body = strings.Repeat("a", MaxBodyLength+1) }, "body:max"},
+		{"chave sem fechar", func(q *entity.QuickReply) { q.Body = "Olá {{lead.name}" }, "body:invalid_placeholder"},
+		{"campo fixo do lead", func(q *entity.QuickReply) { q.Body = "{{lead.name}} {{lead.phone}} {{lead.stage}}" }, ""},
+		{"campo desconhecido do lead", func(q *entity.QuickReply) { q.Body = "CPF {{lead.cpf}}" }, "body:unknown_variable"},
+		{"campo personalizado não definido", func(q *entity.QuickReply) { q.Body = "{{lead.custom.cidade}}" }, "body:unknown_variable"},
+		{"email do atendente", func(q *entity.QuickReply) { q.Body = "Escreva para {{agent.email}}" }, ""},
+		{"telefone do atendente", func(q *entity.QuickReply) { q.Body = "Ligue {{agent.phone}}" }, "body:unknown_variable"},
+		{"variável sem prefixo", func(q *entity.QuickReply) { q.Body = "Olá {{name}}" }, "body:unknown_variable"},
+		{"variável vazia", func(q *entity.QuickReply) { q.Body = "Olá {{}}" }, "body:unknown_variable"},
+		{"duas desconhecidas", func(q *entity.QuickReply) { q.Body = "{{lead.cpf}} {{agent.cpf}} {{lead.cpf}}" }, "body:unknown_variable body:unknown_variable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply := valid()
+			tt.edit(reply)
+			if got := codes(Validate(reply, schema)); got != tt.want {
+				t.Errorf("Validate(%q) = %q, esperado %q", reply.Body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	lead := &entity.Lead{Name: "Ana Souza", CustomFields: map[string]interface{}{"plano": "Pro"}}
+	agent := &entity.User{Name: "Bruno Lima", Email: "bruno@loja.com"}
+
+	tests := []struct {
+		name  string
+		body  string
+		lead  *entity.Lead
+		agent *entity.User
+		want  Rendered
+	}{
+		{"sem variáveis", "Segue a tabela", lead, agent, Rendered{Text: "Segue a tabela", Missing: []string{}}},
+		{
+			"lead e atendente",
+			"Olá {{lead.first_name}}, seu plano {{ lead.custom.plano }}. Att, {{agent.name}} ({{agent.email}})",
+			lead, agent,
+			Rendered{Text: "Olá Ana, seu plano Pro. Att, Bruno Lima (bruno@loja.com)", Missing: []string{}},
+		},
+		{"primeiro nome do atendente", "Sou {{agent.first_name}}", lead, agent, Rendered{Text: "Sou Bruno", Missing: []string{}}},
+		{
+			// Variáveis sem valor ficam vazias e aparecem uma vez em Missing
+			"campos vazios",
+			"{{lead.email}} e {{lead.email}} e {{lead.custom.plano}}",
+			&entity.Lead{Name: "Ana"}, agent,
+			Rendered{Text: "e  e", Missing: []string{"lead.email", "lead.custom.plano"}},
+		},
+		{"sem lead", "Olá {{lead.name}}, sou {{agent.first_name}}", nil, agent, Rendered{Text: "Olá , sou Bruno", Missing: []string{"lead.name"}}},
+		{"sem atendente", "Olá {{lead.first_name}}, sou {{agent.name}}", lead, nil, Rendered{Text: "Olá Ana, sou", Missing: []string{"agent.name"}}},
+		{"nome só com espaços", "Olá {{lead.first_name}}", &entity.Lead{Name: "  "}, agent, Rendered{Text: "Olá", Missing: []string{"lead.first_name"}}},
+		{"variável desconhecida", "{{lead.cpf}} {{foo}}", lead, agent, Rendered{Text: "", Missing: []string{"lead.cpf", "foo"}}},
+		{"valor com chaves", "Plano {{lead.custom.plano}}", &entity.Lead{CustomFields: map[string]interface{}{"plano": "{{agent.name}}"}}, agent,
+			Rendered{Text: "Plano {{agent.name}}", Missing: []string{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.body, tt.lead, tt.agent); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Render(%q) = %+v, esperado %+v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package quickreplies
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/messaging"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/storage"
+	"github.com/whatsapp/backend/internal/whatsapp"
+)
+
+// storageTimeout limita a gravação e a leitura de um anexo
+const storageTimeout = 2 * time.Minute
+
+// Erros das respostas rápidas
+var (
+	ErrTooManyAttachments = errors.New("a resposta rápida atingiu o limite de anexos")
+	ErrAttachmentTooLarge = errors.New("o anexo excede o tamanho máximo")
+	ErrEmpty              = errors.New("a resposta rápida não tem texto nem anexos")
+	ErrTooLong            = errors.New("o texto preenchido excede o limite de caracteres")
+	// ErrNotFound indica uma resposta rápida inexistente ou pessoal de outro
+	// atendente
+	ErrNotFound = errors.New("resposta rápida não encontrada")
+)
+
+// Repository busca as respostas rápidas e grava os anexos e os usos
+type Repository interface {
+	GetByID(organizationID, userID, id int64) (*entity.QuickReply, error)
+	Delete(organizationID, userID, id int64) error
+	RecordUsage(organizationID, id int64, at time.Time) error
+	AddAttachment(a *entity.QuickReplyAttachment, limit int) (bool, error)
+	RemoveAttachment(organizationID, quickReplyID, id int64) (*entity.QuickReplyAttachment, error)
+}
+
+// ConversationFinder busca a conversa em que a resposta é enviada
+type ConversationFinder interface {
+	GetByID(organizationID, id int64) (*entity.Conversation, error)
+}
+
+// LeadFinder busca o lead que preenche as variáveis
+type LeadFinder interface {
+	GetByID(organizationID, id int64) (*entity.Lead, error)
+}
+
+// UserFinder busca o atendente que preenche as variáveis
+type UserFinder interface {
+	GetByID(id int64) (*entity.User, error)
+}
+
+// Sender envia o texto e os anexos na conversa
+type Sender interface {
+	Send(req messaging.SendRequest) (*entity.Message, error)
+	SendMedia(req messaging.MediaRequest) (*entity.Message, error)
+}
+
+// SendRequest é uma resposta rápida a enviar em uma conversa. Body, se
+// informado, substitui o texto salvo, como quando o atendente o edita antes
+// do envio; as variáveis são preenchidas da mesma forma.
+type SendRequest struct {
+	OrganizationID int64
+	ConversationID int64
+	UserID         int64
+	QuickReplyID   int64
+	Body           *string
+}
+
+// Service guarda os anexos e envia as respostas rápidas
+type Service struct {
+	replies       Repository
+	conversations ConversationFinder
+	leads         LeadFinder
+	users         UserFinder
+	sender        Sender
+	store         storage.BlobStore
+}
+
+// NewService cria uma nova instância do serviço de respostas rápidas
+func NewService(replies Repository, conversations ConversationFinder, leads LeadFinder, users UserFinder, sender Sender, store storage.BlobStore) *Service {
+	return &Service{
+		replies:       replies,
+		conversations: conversations,
+		leads:         leads,
+		users:         users,
+		sender:        sender,
+		store:         store,
+	}
+}
+
+// Preview preenche o texto da resposta com os campos do lead e do atendente.
+// Retorna sql.ErrNoRows se o lead não existir.
+func (s *Service) Preview(q *entity.QuickReply, leadID, userID int64) (Rendered, error) {
+	lead, err := s.leads.GetByID(q.OrganizationID, leadID)
+	if err != nil {
+		return Rendered{}, err
+	}
+	agent, err := s.users.GetByID(userID)
+	if err != nil {
+		return Rendered{}, err
+	}
+	return Render(q.Body, lead, agent), nil
+}
+
+// Send envia a resposta na conversa: o texto preenchido e, em seguida, cada
+// anexo. O envio para no primeiro erro; as mensagens já enviadas são
+// retornadas com ele e contam um uso da resposta. Retorna sql.ErrNoRows se a
+// conversa não existir, ErrNotFound, messaging.MissingFieldsError com as
+// variáveis sem valor e os erros do envio.
+func (s *Service) Send(req SendRequest) ([]*entity.Message, error) {
+	q, err := s.replies.GetByID(req.OrganizationID, req.UserID, req.QuickReplyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	conversation, err := s.conversations.GetByID(req.OrganizationID, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	lead, err := s.leads.GetByID(req.OrganizationID, conversation.LeadID)
+	if err != nil {
+		return nil, err
+	}
+	agent, err := s.users.GetByID(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := q.Body
+	if req.Body != nil {
+		body = *req.Body
+	}
+	rendered := Render(body, lead, agent)
+	if len(rendered.Missing) > 0 {
+		return nil, &messaging.MissingFieldsError{Fields: rendered.Missing}
+	}
+	if rendered.Text == "" && len(q.Attachments) == 0 {
+		return nil, ErrEmpty
+	}
+	if utf8.RuneCountInString(rendered.Text) > MaxBodyLength {
+		return nil, ErrTooLong
+	}
+
+	messages := []*entity.Message{}
+	defer func() {
+		if len(messages) > 0 {
+			_ = s.replies.RecordUsage(q.OrganizationID, q.ID, time.Now())
+		}
+	}()
+
+	if rendered.Text != "" {
+		message, err := s.sender.Send(messaging.SendRequest{
+			OrganizationID: req.OrganizationID,
+			ConversationID: conversation.ID,
+			UserID:         req.UserID,
+			Text:           rendered.Text,
+		})
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, message)
+	}
+
+	for _, a := range q.Attachments {
+		message, err := s.sendAttachment(req, conversation.ID, a)
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// AddAttachment valida o arquivo pelos limites do WhatsApp, grava-o no
+// armazenamento e o vincula à resposta. Retorna whatsapp.ErrUnsupportedMedia,
+// whatsapp.MediaTooLargeError, ErrAttachmentTooLarge e ErrTooManyAttachments.
+func (s *Service) AddAttachment(ctx context.Context, q *entity.QuickReply, file io.Reader, fileName, mimeType string, size int64) (*entity.QuickReplyAttachment, error) {
+	if _, err := whatsapp.MediaType(mimeType, size); err != nil {
+		return nil, err
+	}
+	if size > MaxAttachmentSize {
+		return nil, ErrAttachmentTooLarge
+	}
+	if len(q.Attachments) >= MaxAttachments {
+		return nil, ErrTooManyAttachments
+	}
+
+	key, err := newKey(q.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	digest := sha256.New()
+	if err := s.store.Put(ctx, key, io.TeeReader(file, digest), size, mimeType); err != nil {
+		logger.Error("Erro ao armazenar anexo da resposta rápida", err)
+		return nil, err
+	}
+
+	a := &entity.QuickReplyAttachment{
+		QuickReplyID:   q.ID,
+		OrganizationID: q.OrganizationID,
+		StorageKey:     key,
+		MIMEType:       mimeType,
+		FileName:       fileName,
+		Size:           size,
+		SHA256:         hex.EncodeToString(digest.Sum(nil)),
+		CreatedAt:      time.Now(),
+	}
+	added, err := s.replies.AddAttachment(a, MaxAttachments)
+	if err != nil || !added {
+		s.removeFile(key)
+		if err == nil {
+			err = ErrTooManyAttachments
+		}
+		return nil, err
+	}
+	q.Attachments = append(q.Attachments, a)
+	return a, nil
+}
+
+// RemoveAttachment apaga o anexo da resposta e seu arquivo. Retorna
+// sql.ErrNoRows se o anexo não existir.
+func (s *Service) RemoveAttachment(q *entity.QuickReply, id int64) error {
+	a, err := s.replies.RemoveAttachment(q.OrganizationID, q.ID, id)
+	if err != nil {
+		return err
+	}
+	s.removeFile(a.StorageKey)
+	return nil
+}
+
+// OpenAttachment abre o arquivo de um anexo da resposta. O arquivo deve ser
+// fechado. Retorna sql.ErrNoRows se o anexo não existir.
+func (s *Service) OpenAttachment(ctx context.Context, q *entity.QuickReply, id int64) (*entity.QuickReplyAttachment, io.ReadCloser, error) {
+	for _, a := range q.Attachments {
+		if a.ID != id {
+			continue
+		}
+		file, err := s.store.Get(ctx, a.StorageKey)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, nil, sql.ErrNoRows
+			}
+			logger.Error("Erro ao abrir anexo da resposta rápida", err)
+			return nil, nil, err
+		}
+		return a, file, nil
+	}
+	return nil, nil, sql.ErrNoRows
+}
+
+// Delete remove a resposta e os arquivos dos anexos. Retorna sql.ErrNoRows
+// se a resposta não existir.
+func (s *Service) Delete(q *entity.QuickReply, userID int64) error {
+	if err := s.replies.Delete(q.OrganizationID, userID, q.ID); err != nil {
+		return err
+	}
+	for _, a := range q.Attachments {
+		s.removeFile(a.StorageKey)
+	}
+	return nil
+}
+
+// sendAttachment lê o anexo em memória, já que o envio lê o arquivo duas
+// vezes, e o envia na conversa
+func (s *Service) sendAttachment(req SendRequest, conversationID int64, a *entity.QuickReplyAttachment) (*entity.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+
+	file, err := s.store.Get(ctx, a.StorageKey)
+	if err != nil {
+		logger.Error("Erro ao abrir anexo da resposta rápida", err)
+		return nil, err
+	}
+	content, err := io.ReadAll(io.LimitReader(file, MaxAttachmentSize+1))
+	file.Close()
+	if err != nil {
+		logger.Error("Erro ao ler anexo da resposta rápida", err)
+		return nil, err
+	}
+
+	return s.sender.SendMedia(messaging.MediaRequest{
+		OrganizationID: req.OrganizationID,
+		ConversationID: conversationID,
+		UserID:         req.UserID,
+		File:           bytes.NewReader(content),
+		FileName:       a.FileName,
+		MIMEType:       a.MIMEType,
+		Size:           int64(len(content)),
+	})
+}
+
+func (s *Service) removeFile(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.store.Delete(ctx, key); err != nil {
+		logger.Error("Erro ao remover anexo da resposta rápida", err)
+	}
+}
+
+// newKey gera uma chave aleatória agrupada por organização
+func newKey(organizationID int64) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d/quick-replies/%s", organizationID, hex.EncodeToString(random)), nil
+}
@@ -0,0 +1,111 @@
+// Package ratelimit limita o ritmo de operações compartilhadas entre as
+// instâncias da API com baldes de fichas (token bucket) guardados no Redis
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript reabastece o balde conforme o tempo decorrido desde o último
+// uso e retira até ARGV[3] fichas inteiras. O relógio é o do Redis, comum a
+// todas as instâncias. Retorna quantas fichas foram concedidas.
+//
+// KEYS[1] balde; ARGV[1] fichas por segundo; ARGV[2] capacidade; ARGV[3] fichas pedidas
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local requested = tonumber(ARGV[3])
+
+local now = redis.call('TIME')
+local nowMs = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = nowMs
+end
+
+tokens = math.min(burst, tokens + math.max(0, nowMs - ts) * rate / 1000)
+local granted = math.min(requested, math.floor(tokens))
+tokens = tokens - granted
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(nowMs))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return granted
+`)
+
+// returnScript devolve ARGV[3] fichas ao balde, sem passar da capacidade.
+// Um balde que já expirou está cheio e não muda.
+//
+// KEYS[1] balde; ARGV[1] fichas por segundo; ARGV[2] capacidade; ARGV[3] fichas devolvidas
+var returnScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local returned = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	return 0
+end
+
+local now = redis.call('TIME')
+local nowMs = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+
+tokens = math.min(burst, tokens + math.max(0, nowMs - ts) * rate / 1000 + returned)
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(nowMs))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return 0
+`)
+
+// Bucket é um balde de fichas no Redis: guarda até Burst fichas e recebe
+// Rate fichas por segundo
+type Bucket struct {
+	Key   string
+	Rate  float64
+	Burst int
+}
+
+// PerDay cria um balde que permite limit operações em 24 horas, todas de
+// uma vez se o balde estiver cheio
+func PerDay(key string, limit int) Bucket {
+	return Bucket{Key: key, Rate: float64(limit) / (24 * time.Hour).Seconds(), Burst: limit}
+}
+
+// Limiter retira fichas dos baldes guardados no Redis
+type Limiter struct {
+	client *redis.Client
+}
+
+// NewLimiter cria um limitador sobre o cliente Redis
+func NewLimiter(client *redis.Client) *Limiter {
+	return &Limiter{client: client}
+}
+
+// Take retira até n fichas do balde e retorna quantas foram concedidas,
+// possivelmente zero
+func (l *Limiter) Take(ctx context.Context, b Bucket, n int) (int, error) {
+	if n <= 0 {
+		return 0, nil
+	}
+	granted, err := takeScript.Run(ctx, l.client, []string{b.Key}, b.Rate, b.Burst, n).Int()
+	if err != nil {
+		return 0, err
+	}
+	return granted, nil
+}
+
+// Return devolve ao balde n fichas retiradas e não usadas
+func (l *Limiter) Return(ctx context.Context, b Bucket, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	return returnScript.Run(ctx, l.client, []string{b.Key}, b.Rate, b.Burst, n).Err()
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// ActivityRepository é responsável pelo histórico de atividades dos leads
+type ActivityRepository struct {
+	db *sql.DB
+}
+
+// NewActivityRepository cria uma nova instância do repositório de atividades
+func NewActivityRepository(db *sql.DB) *ActivityRepository {
+	return &ActivityRepository{
+		db: db,
+	}
+}
+
+// ActivityQuery filtra e ordena o histórico de um lead
+type ActivityQuery struct {
+	Types     []string
+	Ascending bool
+}
+
+// Record grava uma atividade fora de outra transação
+func (r *ActivityRepository) Record(activity *entity.Activity) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := insertActivity(ctx, r.db, activity); err != nil {
+		logger.Error("Erro ao registrar atividade do lead", err)
+		return err
+	}
+	return nil
+}
+
+// ListByLead retorna uma página do histórico do lead em ordem cronológica
+// (das mais recentes para as mais antigas, ou o inverso com Ascending),
+// junto com o total
+func (r *ActivityRepository) ListByLead(organizationID, leadID int64, query ActivityQuery, limit, offset int) ([]*entity.Activity, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var args sqlArgs
+	where := `a.lead_id = ` + args.add(leadID) + ` AND a.organization_id = ` + args.add(organizationID)
+	if len(query.Types) > 0 {
+		where += ` AND a.type = ANY(` + args.add(query.Types) + `)`
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM lead_activities a WHERE `+where, args...).Scan(&total); err != nil {
+		logger.Error("Erro ao contar atividades do lead", err)
+		return nil, 0, err
+	}
+
+	direction := "DESC"
+	if query.Ascending {
+		direction = "ASC"
+	}
+	sqlQuery := `
+		SELECT a.id, a.organization_id, a.lead_id, a.user_id, COALESCE(u.name, ''), a.type, a.data, a.created_at
+		FROM lead_activities a
+		LEFT JOIN users u ON u.id = a.user_id
+		WHERE ` + where + `
+		ORDER BY a.created_at ` + direction + `, a.id ` + direction + `
+		LIMIT ` + args.add(limit) + ` OFFSET ` + args.add(offset)
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		logger.Error("Erro ao listar atividades do lead", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	activities := []*entity.Activity{}
+	for rows.Next() {
+		a := &entity.Activity{}
+		var userID sql.NullInt64
+		var data []byte
+		if err := rows.Scan(&a.ID, &a.OrganizationID, &a.LeadID, &userID, &a.UserName, &a.Type, &data, &a.CreatedAt); err != nil {
+			logger.Error("Erro ao ler atividade do lead", err)
+			return nil, 0, err
+		}
+		if userID.Valid {
+			a.UserID = &userID.Int64
+		}
+		if err := json.Unmarshal(data, &a.Data); err != nil {
+			logger.Error("Erro ao ler dados da atividade do lead", err)
+			return nil, 0, err
+		}
+		activities = append(activities, a)
+	}
+
+	return activities, total, rows.Err()
+}
+
+// insertActivity grava uma atividade na conexão ou na transação da operação
+// que a originou, para que o histórico acompanhe a alteração
+func insertActivity(ctx context.Context, q queryRower, activity *entity.Activity) error {
+	data, err := json.Marshal(activity.Data)
+	if err != nil {
+		return err
+	}
+
+	return q.QueryRowContext(ctx, `
+		INSERT INTO lead_activities (organization_id, lead_id, user_id, type, data, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, activity.OrganizationID, activity.LeadID, activity.UserID, activity.Type, data, activity.CreatedAt).Scan(&activity.ID)
+}
+
+// insertActivities grava as atividades em sequência na transação
+func insertActivities(ctx context.Context, tx *sql.Tx, activities []*entity.Activity) error {
+	for _, a := range activities {
+		if err := insertActivity(ctx, tx, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
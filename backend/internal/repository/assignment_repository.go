@@ -0,0 +1,389 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// AssignmentRepository é responsável pela configuração e execução da
+// distribuição automática de leads entre os atendentes
+type AssignmentRepository struct {
+	db *sql.DB
+}
+
+// NewAssignmentRepository cria uma nova instância do repositório de distribuição
+func NewAssignmentRepository(db *sql.DB) *AssignmentRepository {
+	return &AssignmentRepository{
+		db: db,
+	}
+}
+
+// GetSettings retorna a configuração de distribuição da organização, ou a
+// padrão (desativada) se ainda não houver uma
+func (r *AssignmentRepository) GetSettings(organizationID int64) (*entity.AssignmentSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	settings, err := scanAssignmentSettings(r.db.QueryRowContext(ctx, `
+		SELECT organization_id, enabled, strategy, rules, updated_at
+		FROM assignment_settings WHERE organization_id = $1
+	`, organizationID))
+	if err == sql.ErrNoRows {
+		return entity.DefaultAssignmentSettings(organizationID), nil
+	}
+	if err != nil {
+		logger.Error("Erro ao buscar configuração de distribuição", err)
+		return nil, err
+	}
+	return settings, nil
+}
+
+// SaveSettings grava a configuração de distribuição da organização
+func (r *AssignmentRepository) SaveSettings(settings *entity.AssignmentSettings) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rules, err := json.Marshal(settings.Rules)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO assignment_settings (organization_id, enabled, strategy, rules, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled, strategy = EXCLUDED.strategy, rules = EXCLUDED.rules, updated_at = EXCLUDED.updated_at
+	`, settings.OrganizationID, settings.Enabled, settings.Strategy, rules, settings.UpdatedAt)
+	if err != nil {
+		logger.Error("Erro ao gravar configuração de distribuição", err)
+		return err
+	}
+	return nil
+}
+
+// ListAgents retorna os usuários da organização com a configuração de
+// atendimento e o número de conversas abertas de cada um
+func (r *AssignmentRepository) ListAgents(organizationID int64) ([]*entity.AgentSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, agentSelectQuery+` WHERE u.organization_id = $1 ORDER BY u.name, u.id`, organizationID)
+	if err != nil {
+		logger.Error("Erro ao listar atendentes", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	agents := []*entity.AgentSettings{}
+	for rows.Next() {
+		agent, err := scanAgent(rows)
+		if err != nil {
+			logger.Error("Erro ao ler atendente", err)
+			return nil, err
+		}
+		agents = append(agents, agent)
+	}
+	return agents, rows.Err()
+}
+
+// GetAgent retorna a configuração de atendimento de um usuário da organização
+func (r *AssignmentRepository) GetAgent(organizationID, userID int64) (*entity.AgentSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	agent, err := scanAgent(r.db.QueryRowContext(ctx, agentSelectQuery+` WHERE u.organization_id = $1 AND u.id = $2`, organizationID, userID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar atendente", err)
+		}
+		return nil, err
+	}
+	return agent, nil
+}
+
+// SaveAgent grava a disponibilidade, as habilidades e o limite de conversas
+// do atendente, preservando o horário da última distribuição
+func (r *AssignmentRepository) SaveAgent(organizationID int64, agent *entity.AgentSettings) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO agent_settings (user_id, organization_id, available, skills, max_open_conversations, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET available = EXCLUDED.available, skills = EXCLUDED.skills,
+			max_open_conversations = EXCLUDED.max_open_conversations, updated_at = EXCLUDED.updated_at
+	`, agent.UserID, organizationID, agent.Available, agent.Skills, agent.MaxOpenConversations, time.Now())
+	if err != nil {
+		logger.Error("Erro ao gravar configuração do atendente", err)
+		return err
+	}
+	return nil
+}
+
+// Assign escolhe um atendente para o lead pela estratégia configurada e o
+// torna responsável pelo lead e pela conversa. A linha de configuração da
+// organização é bloqueada durante a escolha, serializando distribuições
+// concorrentes entre instâncias da API: cada uma vê a contagem de conversas
+// e o horário da última distribuição já atualizados pela anterior.
+//
+// skill restringe os candidatos aos atendentes com a habilidade; sem nenhum
+// disponível, qualquer atendente disponível é escolhido. Retorna o ID do
+// responsável, ou zero se a distribuição estiver desativada ou não houver
+// atendente disponível. Se o lead já tiver responsável, a
+// conversa é atribuída a ele e nenhuma escolha é feita.
+func (r *AssignmentRepository) Assign(organizationID, leadID, conversationID int64, skill string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de distribuição", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	settings, err := scanAssignmentSettings(tx.QueryRowContext(ctx, `
+		SELECT organization_id, enabled, strategy, rules, updated_at
+		FROM assignment_settings WHERE organization_id = $1
+		FOR UPDATE
+	`, organizationID))
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		logger.Error("Erro ao bloquear configuração de distribuição", err)
+		return 0, err
+	}
+	if !settings.Enabled {
+		return 0, nil
+	}
+
+	var ownerID sql.NullInt64
+	if err := tx.QueryRowContext(ctx, `SELECT owner_id FROM leads WHERE id = $1 AND organization_id = $2 FOR UPDATE`,
+		leadID, organizationID).Scan(&ownerID); err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao bloquear lead para distribuição", err)
+		}
+		return 0, err
+	}
+	if ownerID.Valid {
+		if err := assignConversation(ctx, tx, conversationID, ownerID.Int64); err != nil {
+			logger.Error("Erro ao atribuir conversa ao responsável do lead", err)
+			return 0, err
+		}
+		return ownerID.Int64, tx.Commit()
+	}
+
+	agentID, err := pickAgent(ctx, tx, organizationID, settings.Strategy, skill)
+	if err == sql.ErrNoRows && skill != "" {
+		agentID, err = pickAgent(ctx, tx, organizationID, settings.Strategy, "")
+	}
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		logger.Error("Erro ao escolher atendente", err)
+		return 0, err
+	}
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, `UPDATE leads SET owner_id = $1, updated_at = $2 WHERE id = $3`, agentID, now, leadID); err != nil {
+		logger.Error("Erro ao atribuir lead ao atendente", err)
+		return 0, err
+	}
+	if err := assignConversation(ctx, tx, conversationID, agentID); err != nil {
+		logger.Error("Erro ao atribuir conversa ao atendente", err)
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO agent_settings (user_id, organization_id, last_assigned_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (user_id) DO UPDATE SET last_assigned_at = EXCLUDED.last_assigned_at
+	`, agentID, organizationID, now); err != nil {
+		logger.Error("Erro ao registrar distribuição do atendente", err)
+		return 0, err
+	}
+
+	assigned := entity.NewActivity(organizationID, leadID, 0, entity.ActivityAssigned)
+	assigned.Data["from"] = nil
+	assigned.Data["to"] = agentID
+	assigned.Data["automatic"] = true
+	assigned.Data["strategy"] = settings.Strategy
+	if skill != "" {
+		assigned.Data["skill"] = skill
+	}
+	if err := insertActivity(ctx, tx, assigned); err != nil {
+		logger.Error("Erro ao registrar distribuição no histórico do lead", err)
+		return 0, err
+	}
+
+	notification := entity.NewNotification(organizationID, agentID, entity.NotificationLeadAssigned, "Novo lead atribuído a você")
+	notification.Data["lead_id"] = leadID
+	notification.Data["conversation_id"] = conversationID
+	if err := insertNotification(ctx, tx, notification); err != nil {
+		logger.Error("Erro ao notificar atendente do novo lead", err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar distribuição do lead", err)
+		return 0, err
+	}
+	return agentID, nil
+}
+
+// AssignTo atribui o lead e a conversa ao atendente da organização,
+// substituindo o responsável anterior, e notifica o atendente. Usado pelas
+// regras de automação; retorna sql.ErrNoRows se o lead ou o atendente não
+// pertencerem à organização.
+func (r *AssignmentRepository) AssignTo(organizationID, leadID, conversationID, userID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de atribuição", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var agentOrg int64
+	if err := tx.QueryRowContext(ctx, `SELECT organization_id FROM users WHERE id = $1`, userID).Scan(&agentOrg); err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar atendente para atribuição", err)
+		}
+		return err
+	}
+	if agentOrg != organizationID {
+		return sql.ErrNoRows
+	}
+
+	var ownerID sql.NullInt64
+	if err := tx.QueryRowContext(ctx, `SELECT owner_id FROM leads WHERE id = $1 AND organization_id = $2 FOR UPDATE`,
+		leadID, organizationID).Scan(&ownerID); err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao bloquear lead para atribuição", err)
+		}
+		return err
+	}
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE conversations SET assigned_user_id = $1, updated_at = $2 WHERE id = $3 AND organization_id = $4
+	`, userID, now, conversationID, organizationID); err != nil {
+		logger.Error("Erro ao atribuir conversa ao atendente", err)
+		return err
+	}
+	if ownerID.Valid && ownerID.Int64 == userID {
+		return tx.Commit()
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE leads SET owner_id = $1, updated_at = $2 WHERE id = $3`, userID, now, leadID); err != nil {
+		logger.Error("Erro ao atribuir lead ao atendente", err)
+		return err
+	}
+
+	assigned := entity.NewActivity(organizationID, leadID, 0, entity.ActivityAssigned)
+	if ownerID.Valid {
+		assigned.Data["from"] = ownerID.Int64
+	} else {
+		assigned.Data["from"] = nil
+	}
+	assigned.Data["to"] = userID
+	assigned.Data["automatic"] = true
+	if err := insertActivity(ctx, tx, assigned); err != nil {
+		logger.Error("Erro ao registrar atribuição no histórico do lead", err)
+		return err
+	}
+
+	notification := entity.NewNotification(organizationID, userID, entity.NotificationLeadAssigned, "Novo lead atribuído a você")
+	notification.Data["lead_id"] = leadID
+	notification.Data["conversation_id"] = conversationID
+	if err := insertNotification(ctx, tx, notification); err != nil {
+		logger.Error("Erro ao notificar atendente do novo lead", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar atribuição do lead", err)
+		return err
+	}
+	return nil
+}
+
+// pickAgent escolhe entre os atendentes disponíveis, abaixo do limite de
+// conversas abertas e, se informada, com a habilidade. No rodízio vence quem
+// recebeu um lead há mais tempo (ou nunca recebeu); em least_open, quem tem
+// menos conversas abertas, com o rodízio como desempate.
+func pickAgent(ctx context.Context, tx *sql.Tx, organizationID int64, strategy, skill string) (int64, error) {
+	order := `a.last_assigned_at ASC NULLS FIRST, u.id`
+	if strategy == entity.AssignmentLeastOpen {
+		order = `o.total, ` + order
+	}
+
+	var userID int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT u.id
+		FROM users u
+		LEFT JOIN agent_settings a ON a.user_id = u.id
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS total FROM conversations c
+			WHERE c.assigned_user_id = u.id AND c.status = 'open'
+		) o
+		WHERE u.organization_id = $1
+			AND COALESCE(a.available, TRUE)
+			AND (COALESCE(a.max_open_conversations, 0) = 0 OR o.total < a.max_open_conversations)
+			AND ($2::text = '' OR $2::text = ANY(a.skills))
+		ORDER BY `+order+`
+		LIMIT 1
+	`, organizationID, skill).Scan(&userID)
+	return userID, err
+}
+
+// assignConversation atribui a conversa ao atendente se ela ainda não tiver um
+func assignConversation(ctx context.Context, tx *sql.Tx, conversationID, userID int64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE conversations SET assigned_user_id = $1, updated_at = $2
+		WHERE id = $3 AND assigned_user_id IS NULL
+	`, userID, time.Now(), conversationID)
+	return err
+}
+
+const agentSelectQuery = `
+	SELECT u.id, u.name, u.email, COALESCE(a.available, TRUE), to_json(COALESCE(a.skills, '{}')),
+		COALESCE(a.max_open_conversations, 0), a.last_assigned_at,
+		(SELECT COUNT(*) FROM conversations c WHERE c.assigned_user_id = u.id AND c.status = 'open')
+	FROM users u
+	LEFT JOIN agent_settings a ON a.user_id = u.id`
+
+func scanAgent(row rowScanner) (*entity.AgentSettings, error) {
+	agent := &entity.AgentSettings{}
+	var skills []byte
+	err := row.Scan(&agent.UserID, &agent.Name, &agent.Email, &agent.Available, &skills,
+		&agent.MaxOpenConversations, &agent.LastAssignedAt, &agent.OpenConversations)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(skills, &agent.Skills); err != nil {
+		return nil, err
+	}
+	return agent, nil
+}
+
+func scanAssignmentSettings(row rowScanner) (*entity.AssignmentSettings, error) {
+	settings := &entity.AssignmentSettings{}
+	var rules []byte
+	if err := row.Scan(&settings.OrganizationID, &settings.Enabled, &settings.Strategy, &rules, &settings.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rules, &settings.Rules); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// insertAuditEntry grava a entrada de auditoria na transação da operação
+// auditada, garantindo que uma não exista sem a outra
+func insertAuditEntry(ctx context.Context, tx *sql.Tx, entry *entity.AuditEntry) error {
+	data, err := json.Marshal(entry.Data)
+	if err != nil {
+		return err
+	}
+
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO audit_logs (organization_id, user_id, action, entity_type, entity_id, data, ip_address, created_at)
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`,
+		entry.OrganizationID,
+		entry.UserID,
+		entry.Action,
+		entry.EntityType,
+		entry.EntityID,
+		data,
+		entry.IPAddress,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+}
@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// ErrInvalidRuleOrder indica uma reordenação que não lista exatamente as
+// regras da organização
+var ErrInvalidRuleOrder = errors.New("a ordem deve listar todas as regras da organização, uma única vez")
+
+// AutomationRepository é responsável pelas regras de automação e pelo
+// registro das suas execuções
+type AutomationRepository struct {
+	db *sql.DB
+}
+
+// NewAutomationRepository cria uma nova instância do repositório de automações
+func NewAutomationRepository(db *sql.DB) *AutomationRepository {
+	return &AutomationRepository{
+		db: db,
+	}
+}
+
+const automationRuleSelectColumns = `
+	id, organization_id, name, position, enabled, conditions, actions, stop_processing, cooldown_minutes,
+	created_by, created_at, updated_at`
+
+// Create grava uma nova regra ao final da ordem da organização
+func (r *AutomationRepository) Create(rule *entity.AutomationRule) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conditions, actions, err := marshalRuleContent(rule)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO automation_rules (organization_id, name, position, enabled, conditions, actions, stop_processing,
+			cooldown_minutes, created_by, created_at, updated_at)
+		SELECT $1, $2, COALESCE(MAX(position), 0) + 1, $3, $4, $5, $6, $7, $8, $9, $10
+		FROM automation_rules WHERE organization_id = $1
+		RETURNING id, position
+	`, rule.OrganizationID, rule.Name, rule.Enabled, conditions, actions, rule.StopProcessing,
+		rule.CooldownMinutes, rule.CreatedBy, rule.CreatedAt, rule.UpdatedAt).Scan(&rule.ID, &rule.Position)
+	if err != nil {
+		logger.Error("Erro ao criar regra de automação", err)
+		return err
+	}
+	return nil
+}
+
+// GetByID busca uma regra da organização pelo ID
+func (r *AutomationRepository) GetByID(organizationID, id int64) (*entity.AutomationRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rule, err := scanAutomationRule(r.db.QueryRowContext(ctx, `SELECT `+automationRuleSelectColumns+`
+		FROM automation_rules WHERE id = $1 AND organization_id = $2`, id, organizationID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar regra de automação", err)
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+// List retorna as regras da organização na ordem de avaliação
+func (r *AutomationRepository) List(organizationID int64) ([]*entity.AutomationRule, error) {
+	return r.list(organizationID, false)
+}
+
+// ListEnabled retorna as regras ativas da organização na ordem de avaliação
+func (r *AutomationRepository) ListEnabled(organizationID int64) ([]*entity.AutomationRule, error) {
+	return r.list(organizationID, true)
+}
+
+func (r *AutomationRepository) list(organizationID int64, enabledOnly bool) ([]*entity.AutomationRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+automationRuleSelectColumns+`
+		FROM automation_rules
+		WHERE organization_id = $1 AND (enabled OR NOT $2)
+		ORDER BY position, id`, organizationID, enabledOnly)
+	if err != nil {
+		logger.Error("Erro ao listar regras de automação", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*entity.AutomationRule{}
+	for rows.Next() {
+		rule, err := scanAutomationRule(rows)
+		if err != nil {
+			logger.Error("Erro ao ler regra de automação", err)
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// Update grava o conteúdo da regra; a posição é alterada apenas por Reorder
+func (r *AutomationRepository) Update(rule *entity.AutomationRule) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conditions, actions, err := marshalRuleContent(rule)
+	if err != nil {
+		return err
+	}
+
+	rule.UpdatedAt = time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE automation_rules
+		SET name = $1, enabled = $2, conditions = $3, actions = $4, stop_processing = $5, cooldown_minutes = $6, updated_at = $7
+		WHERE id = $8 AND organization_id = $9
+	`, rule.Name, rule.Enabled, conditions, actions, rule.StopProcessing, rule.CooldownMinutes, rule.UpdatedAt,
+		rule.ID, rule.OrganizationID)
+	if err != nil {
+		logger.Error("Erro ao atualizar regra de automação", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Delete remove a regra e o registro das suas execuções
+func (r *AutomationRepository) Delete(organizationID, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM automation_rules WHERE id = $1 AND organization_id = $2`, id, organizationID)
+	if err != nil {
+		logger.Error("Erro ao remover regra de automação", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Reorder define a ordem de avaliação pela posição de cada ID na lista, que
+// deve conter todas as regras da organização; caso contrário retorna
+// ErrInvalidRuleOrder
+func (r *AutomationRepository) Reorder(organizationID int64, ids []int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de ordenação das regras", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	// O bloqueio serializa com criações e ordenações concorrentes
+	var total, listed int
+	err = tx.QueryRowContext(ctx, `
+		WITH locked AS (
+			SELECT id FROM automation_rules WHERE organization_id = $1 FOR UPDATE
+		)
+		SELECT (SELECT COUNT(*) FROM locked), (SELECT COUNT(*) FROM locked WHERE id = ANY($2))
+	`, organizationID, ids).Scan(&total, &listed)
+	if err != nil {
+		logger.Error("Erro ao bloquear regras de automação", err)
+		return err
+	}
+	if total != len(ids) || listed != len(ids) {
+		return ErrInvalidRuleOrder
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE automation_rules r SET position = o.position, updated_at = $3
+		FROM unnest($2::integer[]) WITH ORDINALITY AS o(id, position)
+		WHERE r.id = o.id AND r.organization_id = $1
+	`, organizationID, ids, time.Now()); err != nil {
+		logger.Error("Erro ao ordenar regras de automação", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar ordenação das regras", err)
+		return err
+	}
+	return nil
+}
+
+// RunSummary retorna, para a proteção contra laços na conversa, a última
+// execução de cada regra desde since e o total de mensagens enviadas pelas
+// regras desde repliesSince
+func (r *AutomationRepository) RunSummary(conversationID int64, since, repliesSince time.Time) (map[int64]time.Time, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rule_id, MAX(created_at), COALESCE(SUM(replies) FILTER (WHERE created_at >= $3), 0)
+		FROM automation_runs
+		WHERE conversation_id = $1 AND created_at >= $2
+		GROUP BY rule_id
+	`, conversationID, since, repliesSince)
+	if err != nil {
+		logger.Error("Erro ao consultar execuções de automação da conversa", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	lastRuns := make(map[int64]time.Time)
+	replies := 0
+	for rows.Next() {
+		var ruleID int64
+		var last time.Time
+		var n int
+		if err := rows.Scan(&ruleID, &last, &n); err != nil {
+			logger.Error("Erro ao ler execuções de automação da conversa", err)
+			return nil, 0, err
+		}
+		lastRuns[ruleID] = last
+		replies += n
+	}
+	return lastRuns, replies, rows.Err()
+}
+
+// RecordRun grava a execução de uma regra
+func (r *AutomationRepository) RecordRun(run *entity.AutomationRun) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results, err := json.Marshal(run.Results)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO automation_runs (organization_id, rule_id, conversation_id, lead_id, message_id, results, replies, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0), $6, $7, $8)
+		RETURNING id
+	`, run.OrganizationID, run.RuleID, run.ConversationID, run.LeadID, run.MessageID, results, run.Replies, run.CreatedAt).Scan(&run.ID)
+	if err != nil {
+		logger.Error("Erro ao registrar execução de automação", err)
+		return err
+	}
+	return nil
+}
+
+// ListRuns retorna uma página das execuções da regra, das mais recentes
+// para as mais antigas, junto com o total
+func (r *AutomationRepository) ListRuns(organizationID, ruleID int64, limit, offset int) ([]*entity.AutomationRun, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM automation_runs WHERE rule_id = $1 AND organization_id = $2
+	`, ruleID, organizationID).Scan(&total); err != nil {
+		logger.Error("Erro ao contar execuções de automação", err)
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, organization_id, rule_id, conversation_id, lead_id, COALESCE(message_id, 0), results, replies, created_at
+		FROM automation_runs
+		WHERE rule_id = $1 AND organization_id = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`, ruleID, organizationID, limit, offset)
+	if err != nil {
+		logger.Error("Erro ao listar execuções de automação", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	runs := []*entity.AutomationRun{}
+	for rows.Next() {
+		run := &entity.AutomationRun{}
+		var results []byte
+		if err := rows.Scan(&run.ID, &run.OrganizationID, &run.RuleID, &run.ConversationID, &run.LeadID, &run.MessageID,
+			&results, &run.Replies, &run.CreatedAt); err != nil {
+			logger.Error("Erro ao ler execução de automação", err)
+			return nil, 0, err
+		}
+		if err := json.Unmarshal(results, &run.Results); err != nil {
+			return nil, 0, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, total, rows.Err()
+}
+
+func marshalRuleContent(rule *entity.AutomationRule) ([]byte, []byte, error) {
+	conditions, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return nil, nil, err
+	}
+	actions, err := json.Marshal(rule.Actions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conditions, actions, nil
+}
+
+func scanAutomationRule(row rowScanner) (*entity.AutomationRule, error) {
+	rule := &entity.AutomationRule{}
+	var conditions, actions []byte
+	var createdBy sql.NullInt64
+	err := row.Scan(&rule.ID, &rule.OrganizationID, &rule.Name, &rule.Position, &rule.Enabled, &conditions, &actions,
+		&rule.StopProcessing, &rule.CooldownMinutes, &createdBy, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if createdBy.Valid {
+		rule.CreatedBy = &createdBy.Int64
+	}
+	if err := json.Unmarshal(conditions, &rule.Conditions); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(actions, &rule.Actions); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// Erros dos calendários de atendimento
+var (
+	ErrCalendarExists = errors.New("já existe um calendário com este nome")
+	ErrCalendarInUse  = errors.New("o calendário é usado por regras de automação ou campanhas não encerradas")
+)
+
+// BusinessCalendarRepository é responsável pelos calendários de atendimento
+type BusinessCalendarRepository struct {
+	db *sql.DB
+}
+
+// NewBusinessCalendarRepository cria uma nova instância do repositório de calendários
+func NewBusinessCalendarRepository(db *sql.DB) *BusinessCalendarRepository {
+	return &BusinessCalendarRepository{
+		db: db,
+	}
+}
+
+const businessCalendarSelectColumns = `
+	id, organization_id, name, is_default, time_zone, days, national_holidays, optional_holidays, holidays,
+	created_by, created_at, updated_at`
+
+// Create grava um novo calendário, retornando ErrCalendarExists se o nome já
+// existir. Um calendário padrão deixa de sê-lo quando outro é marcado.
+func (r *BusinessCalendarRepository) Create(cal *entity.BusinessCalendar) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	days, holidays, err := marshalCalendarContent(cal)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação do calendário", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if cal.IsDefault {
+		if err := clearDefaultCalendar(ctx, tx, cal.OrganizationID, 0); err != nil {
+			return err
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO business_calendars (organization_id, name, is_default, time_zone, days, national_holidays,
+			optional_holidays, holidays, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (organization_id, (LOWER(name))) DO NOTHING
+		RETURNING id
+	`, cal.OrganizationID, cal.Name, cal.IsDefault, cal.TimeZone, days, cal.NationalHolidays,
+		cal.OptionalHolidays, holidays, cal.CreatedBy, cal.CreatedAt, cal.UpdatedAt).Scan(&cal.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrCalendarExists
+		}
+		logger.Error("Erro ao criar calendário de atendimento", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar criação do calendário", err)
+		return err
+	}
+	return nil
+}
+
+// GetByID busca um calendário da organização pelo ID
+func (r *BusinessCalendarRepository) GetByID(organizationID, id int64) (*entity.BusinessCalendar, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cal, err := scanBusinessCalendar(r.db.QueryRowContext(ctx, `SELECT `+businessCalendarSelectColumns+`
+		FROM business_calendars WHERE id = $1 AND organization_id = $2`, id, organizationID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar calendário de atendimento", err)
+		}
+		return nil, err
+	}
+	return cal, nil
+}
+
+// List retorna os calendários da organização, o padrão primeiro e os demais
+// em ordem alfabética
+func (r *BusinessCalendarRepository) List(organizationID int64) ([]*entity.BusinessCalendar, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+businessCalendarSelectColumns+`
+		FROM business_calendars
+		WHERE organization_id = $1
+		ORDER BY is_default DESC, LOWER(name), id`, organizationID)
+	if err != nil {
+		logger.Error("Erro ao listar calendários de atendimento", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	calendars := []*entity.BusinessCalendar{}
+	for rows.Next() {
+		cal, err := scanBusinessCalendar(rows)
+		if err != nil {
+			logger.Error("Erro ao ler calendário de atendimento", err)
+			return nil, err
+		}
+		calendars = append(calendars, cal)
+	}
+	return calendars, rows.Err()
+}
+
+// Update grava o conteúdo do calendário, retornando ErrCalendarExists se
+// outro calendário já usar o nome
+func (r *BusinessCalendarRepository) Update(cal *entity.BusinessCalendar) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	days, holidays, err := marshalCalendarContent(cal)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação do calendário", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if cal.IsDefault {
+		if err := clearDefaultCalendar(ctx, tx, cal.OrganizationID, cal.ID); err != nil {
+			return err
+		}
+	}
+
+	cal.UpdatedAt = time.Now()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE business_calendars
+		SET name = $1, is_default = $2, time_zone = $3, days = $4, national_holidays = $5,
+			optional_holidays = $6, holidays = $7, updated_at = $8
+		WHERE id = $9 AND organization_id = $10
+		AND NOT EXISTS (
+			SELECT 1 FROM business_calendars
+			WHERE organization_id = $10 AND LOWER(name) = LOWER($1) AND id <> $9
+		)
+	`, cal.Name, cal.IsDefault, cal.TimeZone, days, cal.NationalHolidays, cal.OptionalHolidays, holidays,
+		cal.UpdatedAt, cal.ID, cal.OrganizationID)
+	if err != nil {
+		logger.Error("Erro ao atualizar calendário de atendimento", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrCalendarExists
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar atualização do calendário", err)
+		return err
+	}
+	return nil
+}
+
+// Delete remove o calendário, retornando ErrCalendarInUse se uma regra de
+// automação ou uma campanha não encerrada depender dele
+func (r *BusinessCalendarRepository) Delete(organizationID, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var inUse bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM campaigns
+			WHERE calendar_id = $1 AND status NOT IN ($3, $4)
+		) OR EXISTS (
+			SELECT 1 FROM automation_rules r, jsonb_array_elements(r.conditions) c
+			WHERE r.organization_id = $2 AND (c->>'calendar_id')::integer = $1
+		)
+	`, id, organizationID, entity.CampaignStatusCompleted, entity.CampaignStatusCancelled).Scan(&inUse)
+	if err != nil {
+		logger.Error("Erro ao verificar uso do calendário", err)
+		return err
+	}
+	if inUse {
+		return ErrCalendarInUse
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM business_calendars WHERE id = $1 AND organization_id = $2`, id, organizationID)
+	if err != nil {
+		logger.Error("Erro ao remover calendário de atendimento", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// clearDefaultCalendar desmarca o calendário padrão da organização, exceto
+// o calendário exceptID
+func clearDefaultCalendar(ctx context.Context, tx *sql.Tx, organizationID, exceptID int64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE business_calendars SET is_default = FALSE, updated_at = $3
+		WHERE organization_id = $1 AND is_default AND id <> $2
+	`, organizationID, exceptID, time.Now())
+	if err != nil {
+		logger.Error("Erro ao desmarcar calendário padrão", err)
+	}
+	return err
+}
+
+func marshalCalendarContent(cal *entity.BusinessCalendar) ([]byte, []byte, error) {
+	days, err := json.Marshal(cal.Days)
+	if err != nil {
+		return nil, nil, err
+	}
+	holidays, err := json.Marshal(cal.Holidays)
+	if err != nil {
+		return nil, nil, err
+	}
+	return days, holidays, nil
+}
+
+func scanBusinessCalendar(row rowScanner) (*entity.BusinessCalendar, error) {
+	cal := &entity.BusinessCalendar{}
+	var days, holidays []byte
+	var createdBy sql.NullInt64
+	err := row.Scan(&cal.ID, &cal.OrganizationID, &cal.Name, &cal.IsDefault, &cal.TimeZone, &days,
+		&cal.NationalHolidays, &cal.OptionalHolidays, &holidays, &createdBy, &cal.CreatedAt, &cal.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if createdBy.Valid {
+		cal.CreatedBy = &createdBy.Int64
+	}
+	if err := json.Unmarshal(days, &cal.Days); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(holidays, &cal.Holidays); err != nil {
+		return nil, err
+	}
+	return cal, nil
+}
@@ -0,0 +1,606 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// ErrCampaignState indica uma operação não permitida no status atual da campanha
+var ErrCampaignState = errors.New("operação não permitida no status atual da campanha")
+
+// CampaignRepository é responsável pelas campanhas e seus destinatários
+type CampaignRepository struct {
+	db *sql.DB
+}
+
+// NewCampaignRepository cria uma nova instância do repositório de campanhas
+func NewCampaignRepository(db *sql.DB) *CampaignRepository {
+	return &CampaignRepository{
+		db: db,
+	}
+}
+
+const campaignSelectColumns = `
+	id, organization_id, name, template_id, segment_id, filters, calendar_id, status, scheduled_at, started_at,
+	completed_at, error, created_by, created_at, updated_at`
+
+const recipientSelectColumns = `
+	cr.id, cr.campaign_id, cr.organization_id, cr.lead_id, l.name, l.phone, cr.status, cr.attempts,
+	cr.next_attempt_at, cr.message_id, cr.error, cr.sent_at, cr.delivered_at, cr.read_at, cr.replied_at,
+	cr.failed_at, cr.updated_at`
+
+// Create grava uma nova campanha em rascunho
+func (r *CampaignRepository) Create(c *entity.Campaign) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filters, err := json.Marshal(c.Filter)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO campaigns (organization_id, name, template_id, segment_id, filters, calendar_id, status, scheduled_at,
+			created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`, c.OrganizationID, c.Name, c.TemplateID, c.SegmentID, filters, c.CalendarID, c.Status, c.ScheduledAt,
+		c.CreatedBy, c.CreatedAt, c.UpdatedAt).Scan(&c.ID)
+	if err != nil {
+		logger.Error("Erro ao criar campanha", err)
+		return err
+	}
+	return nil
+}
+
+// GetByID busca uma campanha da organização pelo ID
+func (r *CampaignRepository) GetByID(organizationID, id int64) (*entity.Campaign, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c, err := scanCampaign(r.db.QueryRowContext(ctx, `SELECT `+campaignSelectColumns+` FROM campaigns
+		WHERE id = $1 AND organization_id = $2`, id, organizationID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar campanha", err)
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// List retorna uma página das campanhas da organização, das mais recentes
+// para as mais antigas, junto com o total
+func (r *CampaignRepository) List(organizationID int64, status string, limit, offset int) ([]*entity.Campaign, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var args sqlArgs
+	where := `organization_id = ` + args.add(organizationID)
+	if status != "" {
+		where += ` AND status = ` + args.add(status)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM campaigns WHERE `+where, args...).Scan(&total); err != nil {
+		logger.Error("Erro ao contar campanhas", err)
+		return nil, 0, err
+	}
+
+	campaigns, err := r.query(ctx, `SELECT `+campaignSelectColumns+` FROM campaigns WHERE `+where+`
+		ORDER BY created_at DESC, id DESC LIMIT `+args.add(limit)+` OFFSET `+args.add(offset), args...)
+	if err != nil {
+		logger.Error("Erro ao listar campanhas", err)
+		return nil, 0, err
+	}
+	return campaigns, total, nil
+}
+
+// Update grava o conteúdo de uma campanha em rascunho, retornando
+// ErrCampaignState se ela já tiver sido agendada
+func (r *CampaignRepository) Update(c *entity.Campaign) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filters, err := json.Marshal(c.Filter)
+	if err != nil {
+		return err
+	}
+
+	c.UpdatedAt = time.Now().UTC()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE campaigns
+		SET name = $1, template_id = $2, segment_id = $3, filters = $4, calendar_id = $5, scheduled_at = $6, updated_at = $7
+		WHERE id = $8 AND organization_id = $9 AND status = $10
+	`, c.Name, c.TemplateID, c.SegmentID, filters, c.CalendarID, c.ScheduledAt, c.UpdatedAt, c.ID, c.OrganizationID, entity.CampaignStatusDraft)
+	if err != nil {
+		logger.Error("Erro ao atualizar campanha", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrCampaignState
+	}
+	return nil
+}
+
+// Delete remove uma campanha em rascunho, retornando ErrCampaignState se ela
+// já tiver sido agendada
+func (r *CampaignRepository) Delete(organizationID, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM campaigns WHERE id = $1 AND organization_id = $2 AND status = $3`,
+		id, organizationID, entity.CampaignStatusDraft)
+	if err != nil {
+		logger.Error("Erro ao remover campanha", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrCampaignState
+	}
+	return nil
+}
+
+// Schedule agenda o envio de um rascunho para o horário informado
+func (r *CampaignRepository) Schedule(organizationID, id int64, at time.Time) (*entity.Campaign, error) {
+	return r.transition(organizationID, id, []string{entity.CampaignStatusDraft},
+		`status = 'scheduled', scheduled_at = $4`, at)
+}
+
+// Pause interrompe o envio de uma campanha agendada ou em andamento
+func (r *CampaignRepository) Pause(organizationID, id int64) (*entity.Campaign, error) {
+	return r.transition(organizationID, id, []string{entity.CampaignStatusScheduled, entity.CampaignStatusRunning},
+		`status = 'paused'`)
+}
+
+// Resume retoma uma campanha pausada: volta a agendada se o envio ainda não
+// tinha começado
+func (r *CampaignRepository) Resume(organizationID, id int64) (*entity.Campaign, error) {
+	return r.transition(organizationID, id, []string{entity.CampaignStatusPaused},
+		`status = CASE WHEN started_at IS NULL THEN 'scheduled' ELSE 'running' END, error = ''`)
+}
+
+// Cancel encerra a campanha; os destinatários ainda não atendidos são
+// marcados como cancelados
+func (r *CampaignRepository) Cancel(organizationID, id int64) (*entity.Campaign, error) {
+	c, err := r.transition(organizationID, id, []string{
+		entity.CampaignStatusDraft, entity.CampaignStatusScheduled, entity.CampaignStatusRunning, entity.CampaignStatusPaused,
+	}, `status = 'cancelled', completed_at = $3`)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE campaign_recipients SET status = $1, updated_at = $2 WHERE campaign_id = $3 AND status = $4
+	`, entity.RecipientCancelled, time.Now().UTC(), c.ID, entity.RecipientPending); err != nil {
+		logger.Error("Erro ao cancelar destinatários da campanha", err)
+		return nil, err
+	}
+	return c, nil
+}
+
+// transition altera a campanha se o status atual estiver em from,
+// retornando ErrCampaignState caso contrário. set pode usar $3 (horário
+// atual) e $4 em diante (extra).
+func (r *CampaignRepository) transition(organizationID, id int64, from []string, set string, extra ...interface{}) (*entity.Campaign, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	args := append([]interface{}{id, organizationID, time.Now().UTC()}, extra...)
+	args = append(args, from)
+	c, err := scanCampaign(r.db.QueryRowContext(ctx, `
+		UPDATE campaigns SET `+set+`, updated_at = $3
+		WHERE id = $1 AND organization_id = $2 AND status = ANY(`+fmt.Sprintf("$%d", len(args))+`)
+		RETURNING `+campaignSelectColumns, args...))
+	if err == sql.ErrNoRows {
+		if _, err := r.GetByID(organizationID, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrCampaignState
+	}
+	if err != nil {
+		logger.Error("Erro ao alterar status da campanha", err)
+		return nil, err
+	}
+	return c, nil
+}
+
+// StartDue inicia a próxima campanha agendada cujo horário chegou: grava
+// como destinatários os leads que atendem ao filtro nesse momento, com os
+// descadastrados do WhatsApp já como opted_out, e passa a campanha para em
+// andamento. Retorna sql.ErrNoRows se não houver
+// campanhas a iniciar.
+func (r *CampaignRepository) StartDue() (*entity.Campaign, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de início de campanha", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	c, err := scanCampaign(tx.QueryRowContext(ctx, `SELECT `+campaignSelectColumns+` FROM campaigns
+		WHERE status = $1 AND scheduled_at <= $2
+		ORDER BY scheduled_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`, entity.CampaignStatusScheduled, now))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar campanha agendada", err)
+		}
+		return nil, err
+	}
+
+	var args sqlArgs
+	where := leadWhere(c.OrganizationID, c.Filter, &args)
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO campaign_recipients (campaign_id, organization_id, lead_id, status, next_attempt_at, updated_at)
+		SELECT `+args.add(c.ID)+`, l.organization_id, l.id,
+			CASE WHEN `+consentStatus("l", args.add(entity.ConsentChannelWhatsApp)+"::text")+` = `+args.add(entity.ConsentOptedOut)+`::text
+				THEN `+args.add(entity.RecipientOptedOut)+`::text ELSE `+args.add(entity.RecipientPending)+`::text END,
+			`+args.add(now)+`, `+args.add(now)+`
+		FROM leads l WHERE `+where+`
+		ON CONFLICT (campaign_id, lead_id) DO NOTHING
+	`, args...); err != nil {
+		logger.Error("Erro ao gravar destinatários da campanha", err)
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE campaigns SET status = $1, started_at = $2, updated_at = $2 WHERE id = $3
+	`, entity.CampaignStatusRunning, now, c.ID); err != nil {
+		logger.Error("Erro ao iniciar campanha", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar início de campanha", err)
+		return nil, err
+	}
+	c.Status = entity.CampaignStatusRunning
+	c.StartedAt = &now
+	c.UpdatedAt = now
+	return c, nil
+}
+
+// ListRunning retorna as campanhas em andamento de todas as organizações
+func (r *CampaignRepository) ListRunning() ([]*entity.Campaign, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	campaigns, err := r.query(ctx, `SELECT `+campaignSelectColumns+` FROM campaigns
+		WHERE status = $1 ORDER BY started_at, id`, entity.CampaignStatusRunning)
+	if err != nil {
+		logger.Error("Erro ao listar campanhas em andamento", err)
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+// ClaimRecipients reserva até limit destinatários pendentes da campanha
+// cuja próxima tentativa já venceu, contando a tentativa. O SKIP LOCKED
+// garante que cada destinatário seja reservado por apenas uma instância.
+func (r *CampaignRepository) ClaimRecipients(campaignID int64, limit int) ([]*entity.CampaignRecipient, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	rows, err := r.db.QueryContext(ctx, `
+		WITH claimed AS (
+			UPDATE campaign_recipients
+			SET status = $1, attempts = attempts + 1, updated_at = $2
+			WHERE id IN (
+				SELECT r.id FROM campaign_recipients r
+				JOIN campaigns c ON c.id = r.campaign_id
+				WHERE r.campaign_id = $3 AND c.status = $4 AND r.status = $5 AND r.next_attempt_at <= $2
+				ORDER BY r.next_attempt_at, r.id
+				LIMIT $6
+				FOR UPDATE OF r SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT `+recipientSelectColumns+` FROM claimed cr JOIN leads l ON l.id = cr.lead_id
+		ORDER BY cr.id
+	`, entity.RecipientSending, now, campaignID, entity.CampaignStatusRunning, entity.RecipientPending, limit)
+	if err != nil {
+		logger.Error("Erro ao reservar destinatários da campanha", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipients := []*entity.CampaignRecipient{}
+	for rows.Next() {
+		recipient, err := scanRecipient(rows)
+		if err != nil {
+			logger.Error("Erro ao ler destinatário da campanha", err)
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, rows.Err()
+}
+
+// MarkSent associa a mensagem enviada ao destinatário. O status parte do
+// status atual da mensagem, que o webhook pode já ter avançado.
+func (r *CampaignRepository) MarkSent(recipientID, messageID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE campaign_recipients cr
+		SET message_id = m.id, status = COALESCE(NULLIF(m.status, ''), $1), error = '', sent_at = $2,
+			delivered_at = CASE WHEN m.status IN ('delivered', 'read') THEN $2 END,
+			read_at = CASE WHEN m.status = 'read' THEN $2 END,
+			updated_at = $2
+		FROM messages m
+		WHERE cr.id = $3 AND m.id = $4
+	`, entity.RecipientSent, time.Now().UTC(), recipientID, messageID)
+	if err != nil {
+		logger.Error("Erro ao registrar envio ao destinatário da campanha", err)
+		return err
+	}
+	return nil
+}
+
+// Retry devolve o destinatário à fila para uma nova tentativa em at
+func (r *CampaignRepository) Retry(recipientID int64, at time.Time, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE campaign_recipients SET status = $1, next_attempt_at = $2, error = $3, updated_at = $4 WHERE id = $5
+	`, entity.RecipientPending, at, reason, time.Now().UTC(), recipientID)
+	if err != nil {
+		logger.Error("Erro ao reagendar destinatário da campanha", err)
+		return err
+	}
+	return nil
+}
+
+// Release devolve o destinatário à fila sem contar a tentativa, quando o
+// envio foi interrompido antes de chegar ao provedor
+func (r *CampaignRepository) Release(recipientID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE campaign_recipients SET status = $1, attempts = GREATEST(attempts - 1, 0), updated_at = $2
+		WHERE id = $3 AND status = $4
+	`, entity.RecipientPending, time.Now().UTC(), recipientID, entity.RecipientSending)
+	if err != nil {
+		logger.Error("Erro ao devolver destinatário da campanha à fila", err)
+		return err
+	}
+	return nil
+}
+
+// Fail marca o envio ao destinatário como falho
+func (r *CampaignRepository) Fail(recipientID int64, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE campaign_recipients SET status = $1, error = $2, failed_at = $3, updated_at = $3 WHERE id = $4
+	`, entity.RecipientFailed, reason, now, recipientID)
+	if err != nil {
+		logger.Error("Erro ao registrar falha no destinatário da campanha", err)
+		return err
+	}
+	return nil
+}
+
+// OptOut marca o destinatário descadastrado depois do início da campanha
+func (r *CampaignRepository) OptOut(recipientID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE campaign_recipients SET status = $1, updated_at = $2 WHERE id = $3
+	`, entity.RecipientOptedOut, time.Now().UTC(), recipientID)
+	if err != nil {
+		logger.Error("Erro ao registrar descadastro do destinatário da campanha", err)
+		return err
+	}
+	return nil
+}
+
+// Status retorna o status atual da campanha
+func (r *CampaignRepository) Status(campaignID int64) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var status string
+	err := r.db.QueryRowContext(ctx, `SELECT status FROM campaigns WHERE id = $1`, campaignID).Scan(&status)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar status da campanha", err)
+		}
+		return "", err
+	}
+	return status, nil
+}
+
+// Suspend pausa uma campanha em andamento por um problema que impede todos
+// os envios, registrando o motivo
+func (r *CampaignRepository) Suspend(campaignID int64, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE campaigns SET status = $1, error = $2, updated_at = $3 WHERE id = $4 AND status = $5
+	`, entity.CampaignStatusPaused, reason, time.Now().UTC(), campaignID, entity.CampaignStatusRunning)
+	if err != nil {
+		logger.Error("Erro ao pausar campanha", err)
+		return err
+	}
+	return nil
+}
+
+// Finish marca como falhos os envios interrompidos há mais de staleAfter
+// (instância que caiu entre a reserva e a resposta do provedor; o envio não
+// é repetido para não duplicar a mensagem) e conclui as campanhas em
+// andamento sem destinatários pendentes. Retorna as campanhas concluídas.
+func (r *CampaignRepository) Finish(staleAfter time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE campaign_recipients SET status = $1, error = $2, failed_at = $3, updated_at = $3
+		WHERE status = $4 AND updated_at < $5
+	`, entity.RecipientFailed, "envio interrompido", now, entity.RecipientSending, now.Add(-staleAfter)); err != nil {
+		logger.Error("Erro ao encerrar envios interrompidos de campanhas", err)
+		return 0, err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE campaigns c SET status = $1, completed_at = $2, updated_at = $2
+		WHERE c.status = $3 AND NOT EXISTS (
+			SELECT 1 FROM campaign_recipients cr WHERE cr.campaign_id = c.id AND cr.status IN ($4, $5)
+		)
+	`, entity.CampaignStatusCompleted, now, entity.CampaignStatusRunning, entity.RecipientPending, entity.RecipientSending)
+	if err != nil {
+		logger.Error("Erro ao concluir campanhas", err)
+		return 0, err
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+// ListRecipients retorna uma página dos destinatários da campanha,
+// filtrados por status, junto com o total
+func (r *CampaignRepository) ListRecipients(organizationID, campaignID int64, status string, limit, offset int) ([]*entity.CampaignRecipient, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var args sqlArgs
+	where := `cr.campaign_id = ` + args.add(campaignID) + ` AND cr.organization_id = ` + args.add(organizationID)
+	if status != "" {
+		where += ` AND cr.status = ` + args.add(status)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM campaign_recipients cr WHERE `+where, args...).Scan(&total); err != nil {
+		logger.Error("Erro ao contar destinatários da campanha", err)
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+recipientSelectColumns+`
+		FROM campaign_recipients cr JOIN leads l ON l.id = cr.lead_id
+		WHERE `+where+`
+		ORDER BY cr.id
+		LIMIT `+args.add(limit)+` OFFSET `+args.add(offset), args...)
+	if err != nil {
+		logger.Error("Erro ao listar destinatários da campanha", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	recipients := []*entity.CampaignRecipient{}
+	for rows.Next() {
+		recipient, err := scanRecipient(rows)
+		if err != nil {
+			logger.Error("Erro ao ler destinatário da campanha", err)
+			return nil, 0, err
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, total, rows.Err()
+}
+
+// Report conta os destinatários da campanha por etapa de entrega
+func (r *CampaignRepository) Report(organizationID, campaignID int64) (*entity.CampaignReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	report := &entity.CampaignReport{CampaignID: campaignID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE status IN ('pending', 'sending')),
+			COUNT(*) FILTER (WHERE sent_at IS NOT NULL),
+			COUNT(*) FILTER (WHERE delivered_at IS NOT NULL),
+			COUNT(*) FILTER (WHERE read_at IS NOT NULL),
+			COUNT(*) FILTER (WHERE replied_at IS NOT NULL),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COUNT(*) FILTER (WHERE status = 'cancelled'),
+			COUNT(*) FILTER (WHERE status = 'opted_out')
+		FROM campaign_recipients
+		WHERE campaign_id = $1 AND organization_id = $2
+	`, campaignID, organizationID).Scan(&report.Total, &report.Pending, &report.Sent, &report.Delivered,
+		&report.Read, &report.Replied, &report.Failed, &report.Cancelled, &report.OptedOut)
+	if err != nil {
+		logger.Error("Erro ao gerar relatório da campanha", err)
+		return nil, err
+	}
+	return report, nil
+}
+
+func (r *CampaignRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entity.Campaign, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	campaigns := []*entity.Campaign{}
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, c)
+	}
+	return campaigns, rows.Err()
+}
+
+func scanCampaign(row rowScanner) (*entity.Campaign, error) {
+	c := &entity.Campaign{}
+	var segmentID, calendarID, createdBy sql.NullInt64
+	var filters []byte
+	err := row.Scan(&c.ID, &c.OrganizationID, &c.Name, &c.TemplateID, &segmentID, &filters, &calendarID, &c.Status,
+		&c.ScheduledAt, &c.StartedAt, &c.CompletedAt, &c.Error, &createdBy, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if segmentID.Valid {
+		c.SegmentID = &segmentID.Int64
+	}
+	if calendarID.Valid {
+		c.CalendarID = &calendarID.Int64
+	}
+	if createdBy.Valid {
+		c.CreatedBy = &createdBy.Int64
+	}
+	if err := json.Unmarshal(filters, &c.Filter); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func scanRecipient(row rowScanner) (*entity.CampaignRecipient, error) {
+	cr := &entity.CampaignRecipient{}
+	var messageID sql.NullInt64
+	err := row.Scan(&cr.ID, &cr.CampaignID, &cr.OrganizationID, &cr.LeadID, &cr.LeadName, &cr.LeadPhone, &cr.Status,
+		&cr.Attempts, &cr.NextAttemptAt, &messageID, &cr.Error, &cr.SentAt, &cr.DeliveredAt, &cr.ReadAt,
+		&cr.RepliedAt, &cr.FailedAt, &cr.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if messageID.Valid {
+		cr.MessageID = &messageID.Int64
+	}
+	return cr, nil
+}
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// ChatbotRepository é responsável pelos fluxos do chatbot e pelo estado das
+// sessões nas conversas
+type ChatbotRepository struct {
+	db *sql.DB
+}
+
+// NewChatbotRepository cria uma nova instância do repositório do chatbot
+func NewChatbotRepository(db *sql.DB) *ChatbotRepository {
+	return &ChatbotRepository{
+		db: db,
+	}
+}
+
+const chatbotFlowSelectColumns = `
+	id, organization_id, name, enabled, start_node, nodes, timeout_minutes, fallback_node,
+	created_by, created_at, updated_at`
+
+const flowSessionSelectColumns = `
+	id, organization_id, flow_id, conversation_id, lead_id, status, current_node, attempts, fallback,
+	answers, expires_at, error, started_at, updated_at, ended_at, version`
+
+// Create grava um novo fluxo
+func (r *ChatbotRepository) Create(flow *entity.ChatbotFlow) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	nodes, err := json.Marshal(flow.Nodes)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO chatbot_flows (organization_id, name, enabled, start_node, nodes, timeout_minutes, fallback_node,
+			created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`, flow.OrganizationID, flow.Name, flow.Enabled, flow.StartNode, nodes, flow.TimeoutMinutes, flow.FallbackNode,
+		flow.CreatedBy, flow.CreatedAt, flow.UpdatedAt).Scan(&flow.ID)
+	if err != nil {
+		logger.Error("Erro ao criar fluxo do chatbot", err)
+		return err
+	}
+	return nil
+}
+
+// GetByID busca um fluxo da organização pelo ID
+func (r *ChatbotRepository) GetByID(organizationID, id int64) (*entity.ChatbotFlow, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	flow, err := scanChatbotFlow(r.db.QueryRowContext(ctx, `SELECT `+chatbotFlowSelectColumns+`
+		FROM chatbot_flows WHERE id = $1 AND organization_id = $2`, id, organizationID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar fluxo do chatbot", err)
+		}
+		return nil, err
+	}
+	return flow, nil
+}
+
+// List retorna os fluxos da organização em ordem alfabética
+func (r *ChatbotRepository) List(organizationID int64) ([]*entity.ChatbotFlow, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+chatbotFlowSelectColumns+`
+		FROM chatbot_flows WHERE organization_id = $1
+		ORDER BY name, id`, organizationID)
+	if err != nil {
+		logger.Error("Erro ao listar fluxos do chatbot", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	flows := []*entity.ChatbotFlow{}
+	for rows.Next() {
+		flow, err := scanChatbotFlow(rows)
+		if err != nil {
+			logger.Error("Erro ao ler fluxo do chatbot", err)
+			return nil, err
+		}
+		flows = append(flows, flow)
+	}
+	return flows, rows.Err()
+}
+
+// Update grava o conteúdo do fluxo. Sessões em andamento continuam no nó
+// atual, se ele ainda existir.
+func (r *ChatbotRepository) Update(flow *entity.ChatbotFlow) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	nodes, err := json.Marshal(flow.Nodes)
+	if err != nil {
+		return err
+	}
+
+	flow.UpdatedAt = time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE chatbot_flows
+		SET name = $1, enabled = $2, start_node = $3, nodes = $4, timeout_minutes = $5, fallback_node = $6, updated_at = $7
+		WHERE id = $8 AND organization_id = $9
+	`, flow.Name, flow.Enabled, flow.StartNode, nodes, flow.TimeoutMinutes, flow.FallbackNode, flow.UpdatedAt,
+		flow.ID, flow.OrganizationID)
+	if err != nil {
+		logger.Error("Erro ao atualizar fluxo do chatbot", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Delete remove o fluxo e as suas sessões, liberando as conversas em
+// andamento para a distribuição
+func (r *ChatbotRepository) Delete(organizationID, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM chatbot_flows WHERE id = $1 AND organization_id = $2`, id, organizationID)
+	if err != nil {
+		logger.Error("Erro ao remover fluxo do chatbot", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// StartSession grava uma nova sessão ativa, retornando false se a conversa
+// já tiver uma
+func (r *ChatbotRepository) StartSession(session *entity.FlowSession) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	answers, err := json.Marshal(session.Answers)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO chatbot_sessions (organization_id, flow_id, conversation_id, lead_id, status, current_node, answers,
+			started_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (conversation_id) WHERE status = 'active' DO NOTHING
+		RETURNING id
+	`, session.OrganizationID, session.FlowID, session.ConversationID, session.LeadID, session.Status, session.CurrentNode,
+		answers, session.StartedAt, session.UpdatedAt).Scan(&session.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		logger.Error("Erro ao iniciar sessão do chatbot", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// ActiveSession busca a sessão ativa da conversa
+func (r *ChatbotRepository) ActiveSession(organizationID, conversationID int64) (*entity.FlowSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, err := scanFlowSession(r.db.QueryRowContext(ctx, `SELECT `+flowSessionSelectColumns+`
+		FROM chatbot_sessions
+		WHERE conversation_id = $1 AND organization_id = $2 AND status = 'active'`, conversationID, organizationID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar sessão ativa do chatbot", err)
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+// SaveSession grava o estado da sessão. Apenas sessões ainda ativas e na
+// versão lida são alteradas: uma sessão cancelada, avançada por outra
+// resposta ou reservada pelo prazo esgotado durante o processamento
+// permanece como está, e sql.ErrNoRows é retornado.
+func (r *ChatbotRepository) SaveSession(session *entity.FlowSession) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	answers, err := json.Marshal(session.Answers)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE chatbot_sessions
+		SET status = $1, current_node = $2, attempts = $3, fallback = $4, answers = $5, expires_at = $6, error = $7,
+			updated_at = $8, ended_at = $9, version = version + 1
+		WHERE id = $10 AND status = 'active' AND version = $11
+	`, session.Status, session.CurrentNode, session.Attempts, session.Fallback, answers, session.ExpiresAt, session.Error,
+		session.UpdatedAt, session.EndedAt, session.ID, session.Version)
+	if err != nil {
+		logger.Error("Erro ao gravar sessão do chatbot", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	session.Version++
+	return nil
+}
+
+// ClaimExpired reserva até limit sessões ativas com o prazo de resposta
+// esgotado, adiando o prazo para retryAt para que outra instância não as
+// processe. Se o tratamento falhar antes de gravar a sessão, ela volta a
+// expirar em retryAt.
+func (r *ChatbotRepository) ClaimExpired(now, retryAt time.Time, limit int) ([]*entity.FlowSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE chatbot_sessions SET expires_at = $3, updated_at = $1, version = version + 1
+		WHERE id IN (
+			SELECT id FROM chatbot_sessions
+			WHERE status = 'active' AND expires_at <= $1
+			ORDER BY expires_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+flowSessionSelectColumns, now, limit, retryAt)
+	if err != nil {
+		logger.Error("Erro ao reservar sessões do chatbot expiradas", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*entity.FlowSession
+	for rows.Next() {
+		session, err := scanFlowSession(rows)
+		if err != nil {
+			logger.Error("Erro ao ler sessão do chatbot", err)
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// ListSessions retorna uma página das sessões do fluxo, das mais recentes
+// para as mais antigas, junto com o total. status vazio não filtra.
+func (r *ChatbotRepository) ListSessions(organizationID, flowID int64, status string, limit, offset int) ([]*entity.FlowSession, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM chatbot_sessions
+		WHERE flow_id = $1 AND organization_id = $2 AND ($3 = '' OR status = $3)
+	`, flowID, organizationID, status).Scan(&total); err != nil {
+		logger.Error("Erro ao contar sessões do chatbot", err)
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+flowSessionSelectColumns+`
+		FROM chatbot_sessions
+		WHERE flow_id = $1 AND organization_id = $2 AND ($3 = '' OR status = $3)
+		ORDER BY started_at DESC, id DESC
+		LIMIT $4 OFFSET $5`, flowID, organizationID, status, limit, offset)
+	if err != nil {
+		logger.Error("Erro ao listar sessões do chatbot", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	sessions := []*entity.FlowSession{}
+	for rows.Next() {
+		session, err := scanFlowSession(rows)
+		if err != nil {
+			logger.Error("Erro ao ler sessão do chatbot", err)
+			return nil, 0, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, total, rows.Err()
+}
+
+func scanChatbotFlow(row rowScanner) (*entity.ChatbotFlow, error) {
+	flow := &entity.ChatbotFlow{}
+	var nodes []byte
+	var createdBy sql.NullInt64
+	err := row.Scan(&flow.ID, &flow.OrganizationID, &flow.Name, &flow.Enabled, &flow.StartNode, &nodes,
+		&flow.TimeoutMinutes, &flow.FallbackNode, &createdBy, &flow.CreatedAt, &flow.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if createdBy.Valid {
+		flow.CreatedBy = &createdBy.Int64
+	}
+	if err := json.Unmarshal(nodes, &flow.Nodes); err != nil {
+		return nil, err
+	}
+	return flow, nil
+}
+
+func scanFlowSession(row rowScanner) (*entity.FlowSession, error) {
+	session := &entity.FlowSession{}
+	var answers []byte
+	var expiresAt, endedAt sql.NullTime
+	err := row.Scan(&session.ID, &session.OrganizationID, &session.FlowID, &session.ConversationID, &session.LeadID,
+		&session.Status, &session.CurrentNode, &session.Attempts, &session.Fallback, &answers, &expiresAt, &session.Error,
+		&session.StartedAt, &session.UpdatedAt, &endedAt, &session.Version)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		session.ExpiresAt = &expiresAt.Time
+	}
+	if endedAt.Valid {
+		session.EndedAt = &endedAt.Time
+	}
+	if err := json.Unmarshal(answers, &session.Answers); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
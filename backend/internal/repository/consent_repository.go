@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// ConsentRepository é responsável pelo registro de consentimento dos leads
+type ConsentRepository struct {
+	db *sql.DB
+}
+
+// NewConsentRepository cria uma nova instância do repositório de consentimento
+func NewConsentRepository(db *sql.DB) *ConsentRepository {
+	return &ConsentRepository{
+		db: db,
+	}
+}
+
+const consentSelectColumns = `
+	c.id, c.organization_id, c.lead_id, c.channel, c.status, c.source, c.evidence, c.message_id,
+	c.user_id, COALESCE(u.name, ''), c.created_at`
+
+// Record grava a alteração de consentimento e a atividade no histórico do lead
+func (r *ConsentRepository) Record(c *entity.Consent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de consentimento", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertConsent(ctx, tx, c); err != nil {
+		logger.Error("Erro ao registrar consentimento do lead", err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar consentimento do lead", err)
+		return err
+	}
+	return nil
+}
+
+// ListByLead retorna uma página das alterações de consentimento do lead,
+// das mais recentes para as mais antigas, junto com o total
+func (r *ConsentRepository) ListByLead(organizationID, leadID int64, limit, offset int) ([]*entity.Consent, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM lead_consents WHERE lead_id = $1 AND organization_id = $2
+	`, leadID, organizationID).Scan(&total); err != nil {
+		logger.Error("Erro ao contar consentimentos do lead", err)
+		return nil, 0, err
+	}
+
+	consents, err := r.query(ctx, `SELECT `+consentSelectColumns+`
+		FROM lead_consents c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.lead_id = $1 AND c.organization_id = $2
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT $3 OFFSET $4`, leadID, organizationID, limit, offset)
+	if err != nil {
+		logger.Error("Erro ao listar consentimentos do lead", err)
+		return nil, 0, err
+	}
+	return consents, total, nil
+}
+
+// Current retorna a alteração mais recente do lead em cada canal
+func (r *ConsentRepository) Current(organizationID, leadID int64) ([]*entity.Consent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	consents, err := r.query(ctx, `SELECT DISTINCT ON (c.channel) `+consentSelectColumns+`
+		FROM lead_consents c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.lead_id = $1 AND c.organization_id = $2
+		ORDER BY c.channel, c.created_at DESC, c.id DESC`, leadID, organizationID)
+	if err != nil {
+		logger.Error("Erro ao buscar consentimento atual do lead", err)
+		return nil, err
+	}
+	return consents, nil
+}
+
+// IsOptedOut verifica se a alteração mais recente do lead no canal é um
+// descadastro. Leads sem registro no canal não estão descadastrados.
+func (r *ConsentRepository) IsOptedOut(organizationID, leadID int64, channel string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var optedOut bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM leads l WHERE l.id = $1 AND l.organization_id = $2 AND `+consentStatus("l", "$3")+` = $4)
+	`, leadID, organizationID, channel, entity.ConsentOptedOut).Scan(&optedOut)
+	if err != nil {
+		logger.Error("Erro ao verificar consentimento do lead", err)
+		return false, err
+	}
+	return optedOut, nil
+}
+
+func (r *ConsentRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entity.Consent, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	consents := []*entity.Consent{}
+	for rows.Next() {
+		c := &entity.Consent{}
+		var messageID, userID sql.NullInt64
+		if err := rows.Scan(&c.ID, &c.OrganizationID, &c.LeadID, &c.Channel, &c.Status, &c.Source, &c.Evidence,
+			&messageID, &userID, &c.UserName, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		if messageID.Valid {
+			c.MessageID = &messageID.Int64
+		}
+		if userID.Valid {
+			c.UserID = &userID.Int64
+		}
+		consents = append(consents, c)
+	}
+	return consents, rows.Err()
+}
+
+// consentStatus retorna a subconsulta com a situação atual do consentimento
+// do lead do alias no canal do parâmetro, ou NULL sem registro
+func consentStatus(leadAlias, channelParam string) string {
+	return `(SELECT lc.status FROM lead_consents lc
+		WHERE lc.lead_id = ` + leadAlias + `.id AND lc.channel = ` + channelParam + `
+		ORDER BY lc.created_at DESC, lc.id DESC LIMIT 1)`
+}
+
+// insertConsent grava a alteração de consentimento e a atividade na
+// transação da operação que a originou
+func insertConsent(ctx context.Context, tx *sql.Tx, c *entity.Consent) error {
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO lead_consents (organization_id, lead_id, channel, status, source, evidence, message_id, user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, c.OrganizationID, c.LeadID, c.Channel, c.Status, c.Source, c.Evidence, c.MessageID, c.UserID, c.CreatedAt).Scan(&c.ID)
+	if err != nil {
+		return err
+	}
+
+	changed := entity.NewActivity(c.OrganizationID, c.LeadID, 0, entity.ActivityConsentChanged)
+	changed.UserID = c.UserID
+	changed.Data["channel"] = c.Channel
+	changed.Data["status"] = c.Status
+	changed.Data["source"] = c.Source
+	changed.CreatedAt = c.CreatedAt
+	return insertActivity(ctx, tx, changed)
+}
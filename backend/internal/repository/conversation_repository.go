@@ -0,0 +1,536 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// ConversationRepository é responsável pelas conversas e mensagens do WhatsApp
+type ConversationRepository struct {
+	db *sql.DB
+}
+
+// NewConversationRepository cria uma nova instância do repositório de conversas
+func NewConversationRepository(db *sql.DB) *ConversationRepository {
+	return &ConversationRepository{
+		db: db,
+	}
+}
+
+// ReceiveInbound grava uma mensagem recebida do telefone informado em uma
+// única transação: encontra ou cria o lead (origem whatsapp, com o nome do
+// perfil), encontra a conversa aberta ou abre uma nova, grava a mensagem e
+// registra as atividades no histórico. Mensagens com ExternalID já gravado
+// são ignoradas, pois o provedor reenvia notificações não confirmadas.
+func (r *ConversationRepository) ReceiveInbound(organizationID int64, phone, name string, message *entity.Message) (*entity.InboundResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de mensagem recebida", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Mesmo lock de insertUniqueLead: serializa mensagens do mesmo telefone
+	// e cadastros concorrentes do número
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, int32(organizationID), phone); err != nil {
+		logger.Error("Erro ao bloquear telefone da mensagem recebida", err)
+		return nil, err
+	}
+
+	if message.ExternalID != "" {
+		var exists bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM messages WHERE organization_id = $1 AND external_id = $2)
+		`, organizationID, message.ExternalID).Scan(&exists)
+		if err != nil {
+			logger.Error("Erro ao verificar mensagem recebida", err)
+			return nil, err
+		}
+		if exists {
+			return &entity.InboundResult{Duplicate: true}, nil
+		}
+	}
+
+	result := &entity.InboundResult{}
+	lead, err := scanLead(tx.QueryRowContext(ctx, `SELECT `+leadSelectColumns+` FROM leads l
+		WHERE l.organization_id = $1 AND l.phone = $2
+		ORDER BY l.id LIMIT 1`, organizationID, phone))
+	switch {
+	case err == sql.ErrNoRows:
+		if name == "" {
+			name = phone
+		}
+		lead = entity.NewLead(organizationID, name, phone)
+		lead.Source = "whatsapp"
+		if err := insertLead(ctx, tx, lead); err != nil {
+			logger.Error("Erro ao criar lead da mensagem recebida", err)
+			return nil, err
+		}
+		result.LeadCreated = true
+
+		created := entity.NewActivity(organizationID, lead.ID, 0, entity.ActivityLeadCreated)
+		created.Data["origin"] = "whatsapp"
+		created.Data["source"] = lead.Source
+		if err := insertActivity(ctx, tx, created); err != nil {
+			logger.Error("Erro ao registrar criação do lead no histórico", err)
+			return nil, err
+		}
+	case err != nil:
+		logger.Error("Erro ao buscar lead da mensagem recebida", err)
+		return nil, err
+	}
+
+	conversation, err := openConversation(ctx, tx, lead, message.CreatedAt)
+	if err != nil {
+		logger.Error("Erro ao abrir conversa da mensagem recebida", err)
+		return nil, err
+	}
+
+	message.OrganizationID = organizationID
+	message.ConversationID = conversation.ID
+	message.LeadID = lead.ID
+	message.Direction = entity.MessageInbound
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO messages (organization_id, conversation_id, lead_id, direction, type, body, external_id, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, message.OrganizationID, message.ConversationID, message.LeadID, message.Direction, message.Type,
+		message.Body, message.ExternalID, message.Status, message.CreatedAt).Scan(&message.ID)
+	if err != nil {
+		logger.Error("Erro ao gravar mensagem recebida", err)
+		return nil, err
+	}
+	if message.Media != nil {
+		if err := insertMessageMedia(ctx, tx, message); err != nil {
+			logger.Error("Erro ao gravar mídia da mensagem recebida", err)
+			return nil, err
+		}
+	}
+
+	// Notificações atrasadas não fazem o horário da conversa voltar
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE conversations
+		SET last_message_at = GREATEST(last_message_at, $1), last_inbound_at = GREATEST(last_inbound_at, $1), updated_at = $2
+		WHERE id = $3
+	`, message.CreatedAt, time.Now(), conversation.ID); err != nil {
+		logger.Error("Erro ao atualizar conversa da mensagem recebida", err)
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE leads SET last_message_at = GREATEST(last_message_at, $1) WHERE id = $2
+	`, message.CreatedAt, lead.ID); err != nil {
+		logger.Error("Erro ao atualizar última mensagem do lead", err)
+		return nil, err
+	}
+
+	// A mensagem conta como resposta às campanhas enviadas ao lead no prazo
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE campaign_recipients SET replied_at = $1, updated_at = $2
+		WHERE lead_id = $3 AND replied_at IS NULL AND sent_at <= $1 AND sent_at > $4
+	`, message.CreatedAt, time.Now().UTC(), lead.ID, message.CreatedAt.Add(-entity.CampaignReplyWindow)); err != nil {
+		logger.Error("Erro ao registrar resposta à campanha", err)
+		return nil, err
+	}
+
+	received := entity.NewActivity(organizationID, lead.ID, 0, entity.ActivityMessageInbound)
+	received.Data["message_id"] = message.ID
+	received.Data["conversation_id"] = conversation.ID
+	received.Data["message_type"] = message.Type
+	received.Data["body"] = entity.ActivityPreview(message.Body)
+	received.CreatedAt = message.CreatedAt
+	if err := insertActivity(ctx, tx, received); err != nil {
+		logger.Error("Erro ao registrar mensagem recebida no histórico", err)
+		return nil, err
+	}
+
+	// Uma palavra de descadastro retira o consentimento do lead no WhatsApp
+	// na mesma transação, para não se perder em reenvios do webhook
+	if message.Type == "text" && entity.IsOptOutMessage(message.Body) {
+		optOut := entity.NewConsent(organizationID, lead.ID, 0, entity.ConsentChannelWhatsApp, entity.ConsentOptedOut, entity.ConsentSourceKeyword)
+		optOut.Evidence = message.Body
+		optOut.MessageID = &message.ID
+		optOut.CreatedAt = message.CreatedAt
+		if err := insertConsent(ctx, tx, optOut); err != nil {
+			logger.Error("Erro ao registrar descadastro do lead", err)
+			return nil, err
+		}
+		result.OptedOut = true
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar mensagem recebida", err)
+		return nil, err
+	}
+
+	result.Lead = lead
+	result.Conversation = conversation
+	result.Message = message
+	return result, nil
+}
+
+// OpenForLead retorna a conversa aberta do lead ou abre uma nova, atribuída
+// ao responsável pelo lead, para mensagens iniciadas pela empresa
+func (r *ConversationRepository) OpenForLead(lead *entity.Lead) (*entity.Conversation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de abertura de conversa", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Mesmo lock de ReceiveInbound, para não abrir duas conversas do lead
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, int32(lead.OrganizationID), lead.Phone); err != nil {
+		logger.Error("Erro ao bloquear telefone do lead", err)
+		return nil, err
+	}
+
+	conversation, err := openConversation(ctx, tx, lead, time.Now().UTC())
+	if err != nil {
+		logger.Error("Erro ao abrir conversa do lead", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar abertura de conversa", err)
+		return nil, err
+	}
+	conversation.UpdateWindow(time.Now())
+	return conversation, nil
+}
+
+// openConversation busca a conversa aberta do lead, bloqueando-a, ou abre
+// uma nova atribuída ao responsável pelo lead. A nova conversa herda a
+// última mensagem recebida das anteriores, pois a janela de atendimento é
+// do lead e não fecha com a conversa.
+func openConversation(ctx context.Context, tx *sql.Tx, lead *entity.Lead, at time.Time) (*entity.Conversation, error) {
+	conversation, err := scanConversation(tx.QueryRowContext(ctx, `SELECT `+conversationSelectColumns+` FROM `+conversationFrom+`
+		WHERE c.lead_id = $1 AND c.status = $2
+		ORDER BY c.id DESC LIMIT 1
+		FOR UPDATE OF c`, lead.ID, entity.ConversationStatusOpen))
+	if err != sql.ErrNoRows {
+		return conversation, err
+	}
+
+	conversation = &entity.Conversation{
+		OrganizationID: lead.OrganizationID,
+		LeadID:         lead.ID,
+		LeadName:       lead.Name,
+		LeadPhone:      lead.Phone,
+		AssignedUserID: lead.OwnerID,
+		Status:         entity.ConversationStatusOpen,
+		CreatedAt:      at,
+		UpdatedAt:      at,
+	}
+	var lastInboundAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO conversations (organization_id, lead_id, assigned_user_id, status, last_inbound_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, (SELECT MAX(last_inbound_at) FROM conversations WHERE lead_id = $2), $5, $6)
+		RETURNING id, last_inbound_at
+	`, conversation.OrganizationID, conversation.LeadID, conversation.AssignedUserID, conversation.Status,
+		conversation.CreatedAt, conversation.UpdatedAt).Scan(&conversation.ID, &lastInboundAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastInboundAt.Valid {
+		conversation.LastInboundAt = &lastInboundAt.Time
+	}
+	return conversation, nil
+}
+
+// ConversationQuery filtra a listagem de conversas
+type ConversationQuery struct {
+	Status         string
+	AssignedUserID *int64
+}
+
+// GetByID busca uma conversa da organização pelo ID
+func (r *ConversationRepository) GetByID(organizationID, id int64) (*entity.Conversation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conversation, err := scanConversation(r.db.QueryRowContext(ctx, `SELECT `+conversationSelectColumns+` FROM `+conversationFrom+`
+		WHERE c.id = $1 AND c.organization_id = $2`, id, organizationID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar conversa", err)
+		}
+		return nil, err
+	}
+	return conversation, nil
+}
+
+// List retorna uma página das conversas da organização, das mais recentes
+// para as mais antigas pela última mensagem, junto com o total
+func (r *ConversationRepository) List(organizationID int64, query ConversationQuery, limit, offset int) ([]*entity.Conversation, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var args sqlArgs
+	where := `c.organization_id = ` + args.add(organizationID)
+	if query.Status != "" {
+		where += ` AND c.status = ` + args.add(query.Status)
+	}
+	if query.AssignedUserID != nil {
+		where += ` AND c.assigned_user_id = ` + args.add(*query.AssignedUserID)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM conversations c WHERE `+where, args...).Scan(&total); err != nil {
+		logger.Error("Erro ao contar conversas", err)
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+conversationSelectColumns+` FROM `+conversationFrom+`
+		WHERE `+where+`
+		ORDER BY c.last_message_at DESC NULLS LAST, c.id DESC
+		LIMIT `+args.add(limit)+` OFFSET `+args.add(offset), args...)
+	if err != nil {
+		logger.Error("Erro ao listar conversas", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	conversations := []*entity.Conversation{}
+	for rows.Next() {
+		conversation, err := scanConversation(rows)
+		if err != nil {
+			logger.Error("Erro ao ler conversa", err)
+			return nil, 0, err
+		}
+		conversations = append(conversations, conversation)
+	}
+	return conversations, total, rows.Err()
+}
+
+// ListMessages retorna uma página das mensagens da conversa, das mais
+// recentes para as mais antigas, junto com o total
+func (r *ConversationRepository) ListMessages(organizationID, conversationID int64, limit, offset int) ([]*entity.Message, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM messages WHERE conversation_id = $1 AND organization_id = $2
+	`, conversationID, organizationID).Scan(&total); err != nil {
+		logger.Error("Erro ao contar mensagens da conversa", err)
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+messageSelectColumns+` FROM `+messageFrom+`
+		WHERE m.conversation_id = $1 AND m.organization_id = $2
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT $3 OFFSET $4`, conversationID, organizationID, limit, offset)
+	if err != nil {
+		logger.Error("Erro ao listar mensagens da conversa", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	messages := []*entity.Message{}
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			logger.Error("Erro ao ler mensagem", err)
+			return nil, 0, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, total, rows.Err()
+}
+
+// RecordOutbound grava uma mensagem enviada pelo provedor, atualiza o
+// horário da última mensagem da conversa e do lead e registra o envio no
+// histórico em nome do usuário da mensagem, como campaign.sent quando a
+// mensagem vem de uma campanha
+func (r *ConversationRepository) RecordOutbound(message *entity.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de mensagem enviada", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	message.Direction = entity.MessageOutbound
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO messages (organization_id, conversation_id, lead_id, user_id, direction, type, body, external_id, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`, message.OrganizationID, message.ConversationID, message.LeadID, message.UserID, message.Direction, message.Type,
+		message.Body, message.ExternalID, message.Status, message.CreatedAt).Scan(&message.ID)
+	if err != nil {
+		logger.Error("Erro ao gravar mensagem enviada", err)
+		return err
+	}
+	if message.Media != nil {
+		if err := insertMessageMedia(ctx, tx, message); err != nil {
+			logger.Error("Erro ao gravar mídia da mensagem enviada", err)
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE conversations SET last_message_at = GREATEST(last_message_at, $1), updated_at = $1 WHERE id = $2
+	`, message.CreatedAt, message.ConversationID); err != nil {
+		logger.Error("Erro ao atualizar conversa da mensagem enviada", err)
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE leads SET last_message_at = GREATEST(last_message_at, $1) WHERE id = $2
+	`, message.CreatedAt, message.LeadID); err != nil {
+		logger.Error("Erro ao atualizar última mensagem do lead", err)
+		return err
+	}
+
+	var userID int64
+	if message.UserID != nil {
+		userID = *message.UserID
+	}
+	activityType := entity.ActivityMessageOutbound
+	if message.CampaignID != 0 {
+		activityType = entity.ActivityCampaignSent
+	}
+	sent := entity.NewActivity(message.OrganizationID, message.LeadID, userID, activityType)
+	if message.CampaignID != 0 {
+		sent.Data["campaign_id"] = message.CampaignID
+	}
+	sent.Data["message_id"] = message.ID
+	sent.Data["conversation_id"] = message.ConversationID
+	sent.Data["message_type"] = message.Type
+	sent.Data["body"] = entity.ActivityPreview(message.Body)
+	sent.CreatedAt = message.CreatedAt
+	if err := insertActivity(ctx, tx, sent); err != nil {
+		logger.Error("Erro ao registrar mensagem enviada no histórico", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar mensagem enviada", err)
+		return err
+	}
+	return nil
+}
+
+// messageStatusOrder ordena os status de entrega; notificações fora de ordem
+// não fazem o status de uma mensagem regredir
+var messageStatusOrder = []string{"", "sent", "delivered", "read", "failed"}
+
+// UpdateMessageStatus grava o status de entrega informado pelo provedor para
+// uma mensagem enviada e para o destinatário de campanha correspondente.
+// Mensagens e status desconhecidos são ignorados.
+func (r *ConversationRepository) UpdateMessageStatus(organizationID int64, externalID, status string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		WITH updated AS (
+			UPDATE messages SET status = $1
+			WHERE organization_id = $2 AND external_id = $3 AND direction = $4
+				AND COALESCE(array_position($5::text[], status), 0) < array_position($5::text[], $1::text)
+			RETURNING id
+		)
+		UPDATE campaign_recipients cr
+		SET status = $1,
+			delivered_at = CASE WHEN $1 IN ('delivered', 'read') THEN COALESCE(cr.delivered_at, $6) ELSE cr.delivered_at END,
+			read_at = CASE WHEN $1 = 'read' THEN COALESCE(cr.read_at, $6) ELSE cr.read_at END,
+			failed_at = CASE WHEN $1 = 'failed' THEN $6 ELSE cr.failed_at END,
+			updated_at = $6
+		FROM updated u
+		WHERE cr.message_id = u.id
+	`, status, organizationID, externalID, entity.MessageOutbound, messageStatusOrder, time.Now().UTC())
+	if err != nil {
+		logger.Error("Erro ao atualizar status da mensagem", err)
+		return err
+	}
+	return nil
+}
+
+const conversationSelectColumns = `c.id, c.organization_id, c.lead_id, c.assigned_user_id, c.status,
+	c.last_message_at, c.last_inbound_at, c.created_at, c.updated_at, c.closed_at, l.name, l.phone`
+
+const messageSelectColumns = `
+	m.id, m.organization_id, m.conversation_id, m.lead_id, m.user_id, m.direction, m.type, m.body, m.external_id,
+	m.status, m.created_at, mm.id, mm.status, mm.mime_type, mm.file_name, mm.size, mm.sha256, mm.storage_key,
+	mm.error, mm.created_at`
+
+const messageFrom = `messages m LEFT JOIN message_media mm ON mm.message_id = m.id`
+
+const conversationFrom = `conversations c JOIN leads l ON l.id = c.lead_id`
+
+// scanConversation lê as colunas de conversationSelectColumns e calcula a
+// janela de atendimento
+func scanConversation(row rowScanner) (*entity.Conversation, error) {
+	c := &entity.Conversation{}
+	var assignedUserID sql.NullInt64
+	err := row.Scan(&c.ID, &c.OrganizationID, &c.LeadID, &assignedUserID, &c.Status,
+		&c.LastMessageAt, &c.LastInboundAt, &c.CreatedAt, &c.UpdatedAt, &c.ClosedAt, &c.LeadName, &c.LeadPhone)
+	if err != nil {
+		return nil, err
+	}
+	if assignedUserID.Valid {
+		c.AssignedUserID = &assignedUserID.Int64
+	}
+	c.UpdateWindow(time.Now())
+	return c, nil
+}
+
+// scanMessage lê as colunas de messageSelectColumns, com a mídia da
+// mensagem se houver
+func scanMessage(row rowScanner) (*entity.Message, error) {
+	m := &entity.Message{}
+	var userID, mediaID, mediaSize sql.NullInt64
+	var mediaStatus, mimeType, fileName, sha, storageKey, mediaError sql.NullString
+	var mediaCreatedAt sql.NullTime
+	err := row.Scan(&m.ID, &m.OrganizationID, &m.ConversationID, &m.LeadID, &userID, &m.Direction, &m.Type,
+		&m.Body, &m.ExternalID, &m.Status, &m.CreatedAt, &mediaID, &mediaStatus, &mimeType, &fileName, &mediaSize,
+		&sha, &storageKey, &mediaError, &mediaCreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if userID.Valid {
+		m.UserID = &userID.Int64
+	}
+	if mediaID.Valid {
+		m.Media = &entity.MessageMedia{
+			ID:             mediaID.Int64,
+			MessageID:      m.ID,
+			Status:         mediaStatus.String,
+			MIMEType:       mimeType.String,
+			FileName:       fileName.String,
+			Size:           mediaSize.Int64,
+			SHA256:         sha.String,
+			Error:          mediaError.String,
+			CreatedAt:      mediaCreatedAt.Time,
+			OrganizationID: m.OrganizationID,
+			StorageKey:     storageKey.String,
+		}
+	}
+	return m, nil
+}
+
+// insertMessageMedia grava a mídia da mensagem na transação que grava a mensagem
+func insertMessageMedia(ctx context.Context, tx *sql.Tx, message *entity.Message) error {
+	media := message.Media
+	media.MessageID = message.ID
+	media.OrganizationID = message.OrganizationID
+	media.CreatedAt = message.CreatedAt
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO message_media (organization_id, message_id, status, provider_media_id, storage_key, mime_type,
+			file_name, size, sha256, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10, $10)
+		RETURNING id
+	`, media.OrganizationID, media.MessageID, media.Status, media.ProviderMediaID, media.StorageKey, media.MIMEType,
+		media.FileName, media.Size, media.SHA256, media.CreatedAt).Scan(&media.ID)
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// ErrCustomFieldKeyExists indica que a organização já definiu um campo com a mesma chave
+var ErrCustomFieldKeyExists = errors.New("já existe um campo personalizado com esta chave")
+
+// CustomFieldRepository é responsável pelas operações de banco de dados relacionadas às definições de campos personalizados
+type CustomFieldRepository struct {
+	db *sql.DB
+}
+
+// NewCustomFieldRepository cria uma nova instância do repositório de campos personalizados
+func NewCustomFieldRepository(db *sql.DB) *CustomFieldRepository {
+	return &CustomFieldRepository{
+		db: db,
+	}
+}
+
+// customFieldColumns lista as colunas lidas em todas as consultas
+const customFieldColumns = `
+	id, organization_id, key, label, type, options, required, position, created_at, updated_at
+`
+
+// Create grava uma nova definição, retornando ErrCustomFieldKeyExists se a chave já existir
+func (r *CustomFieldRepository) Create(def *entity.CustomFieldDefinition) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	options, err := json.Marshal(def.Options)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO custom_field_definitions (organization_id, key, label, type, options, required, position, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (organization_id, key) DO NOTHING
+		RETURNING id
+	`
+
+	err = r.db.QueryRowContext(
+		ctx,
+		query,
+		def.OrganizationID,
+		def.Key,
+		def.Label,
+		def.Type,
+		options,
+		def.Required,
+		def.Position,
+		def.CreatedAt,
+		def.UpdatedAt,
+	).Scan(&def.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrCustomFieldKeyExists
+		}
+		logger.Error("Erro ao criar campo personalizado no banco de dados", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetByID busca uma definição da organização pelo ID
+func (r *CustomFieldRepository) GetByID(organizationID, id int64) (*entity.CustomFieldDefinition, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + customFieldColumns + ` FROM custom_field_definitions WHERE id = $1 AND organization_id = $2`
+
+	def, err := scanCustomField(r.db.QueryRowContext(ctx, query, id, organizationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.Error("Erro ao buscar campo personalizado no banco de dados", err)
+		return nil, err
+	}
+
+	return def, nil
+}
+
+// ListByOrganization lista as definições da organização na ordem de exibição
+func (r *CustomFieldRepository) ListByOrganization(organizationID int64) ([]*entity.CustomFieldDefinition, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + customFieldColumns + ` FROM custom_field_definitions
+		WHERE organization_id = $1
+		ORDER BY position, id`
+
+	rows, err := r.db.QueryContext(ctx, query, organizationID)
+	if err != nil {
+		logger.Error("Erro ao listar campos personalizados no banco de dados", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	defs := []*entity.CustomFieldDefinition{}
+	for rows.Next() {
+		def, err := scanCustomField(rows)
+		if err != nil {
+			logger.Error("Erro ao ler campo personalizado", err)
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+
+	return defs, rows.Err()
+}
+
+// Update grava o rótulo, as opções, a obrigatoriedade e a posição. A chave e
+// o tipo não mudam, pois os valores já gravados nos leads dependem deles.
+func (r *CustomFieldRepository) Update(def *entity.CustomFieldDefinition) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	options, err := json.Marshal(def.Options)
+	if err != nil {
+		return err
+	}
+
+	def.UpdatedAt = time.Now()
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE custom_field_definitions
+		SET label = $1, options = $2, required = $3, position = $4, updated_at = $5
+		WHERE id = $6 AND organization_id = $7
+	`, def.Label, options, def.Required, def.Position, def.UpdatedAt, def.ID, def.OrganizationID)
+	if err != nil {
+		logger.Error("Erro ao atualizar campo personalizado no banco de dados", err)
+		return err
+	}
+
+	return nil
+}
+
+// Delete remove a definição. Os valores gravados nos leads são mantidos,
+// passando a ser tratados como campos sem definição.
+func (r *CustomFieldRepository) Delete(organizationID, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM custom_field_definitions WHERE id = $1 AND organization_id = $2
+	`, id, organizationID)
+	if err != nil {
+		logger.Error("Erro ao remover campo personalizado no banco de dados", err)
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// scanCustomField lê as colunas de customFieldColumns
+func scanCustomField(row rowScanner) (*entity.CustomFieldDefinition, error) {
+	def := &entity.CustomFieldDefinition{}
+	var options []byte
+
+	err := row.Scan(
+		&def.ID,
+		&def.OrganizationID,
+		&def.Key,
+		&def.Label,
+		&def.Type,
+		&options,
+		&def.Required,
+		&def.Position,
+		&def.CreatedAt,
+		&def.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(options, &def.Options); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
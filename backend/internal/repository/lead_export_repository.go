@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// LeadExportRepository é responsável pelas operações de banco de dados relacionadas às exportações de leads
+type LeadExportRepository struct {
+	db *sql.DB
+}
+
+// NewLeadExportRepository cria uma nova instância do repositório de exportações
+func NewLeadExportRepository(db *sql.DB) *LeadExportRepository {
+	return &LeadExportRepository{
+		db: db,
+	}
+}
+
+// leadExportColumns lista as colunas lidas em todas as consultas
+const leadExportColumns = `
+	id, organization_id, COALESCE(user_id, 0), format, columns, filters, async, status, row_count,
+	storage_key, file_size, error_message, ip_address, user_agent, download_count, last_downloaded_at,
+	created_at, updated_at, finished_at, expires_at
+`
+
+// Create registra uma nova exportação
+func (r *LeadExportRepository) Create(export *entity.LeadExport) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	columns, err := json.Marshal(export.Columns)
+	if err != nil {
+		return err
+	}
+	filters, err := json.Marshal(export.Filter)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO lead_exports (organization_id, user_id, format, columns, filters, async, status,
+			ip_address, user_agent, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+
+	err = r.db.QueryRowContext(
+		ctx,
+		query,
+		export.OrganizationID,
+		export.UserID,
+		export.Format,
+		columns,
+		filters,
+		export.Async,
+		export.Status,
+		export.IPAddress,
+		export.UserAgent,
+		export.CreatedAt,
+		export.UpdatedAt,
+	).Scan(&export.ID)
+
+	if err != nil {
+		logger.Error("Erro ao registrar exportação de leads no banco de dados", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetByID busca uma exportação da organização pelo ID
+func (r *LeadExportRepository) GetByID(organizationID, id int64) (*entity.LeadExport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + leadExportColumns + ` FROM lead_exports WHERE id = $1 AND organization_id = $2`
+
+	export, err := scanLeadExport(r.db.QueryRowContext(ctx, query, id, organizationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.Error("Erro ao buscar exportação de leads no banco de dados", err)
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// GetForDownload busca uma exportação pelo ID, sem filtrar pela
+// organização. Usado apenas no download por link assinado, que já
+// identifica a exportação.
+func (r *LeadExportRepository) GetForDownload(id int64) (*entity.LeadExport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + leadExportColumns + ` FROM lead_exports WHERE id = $1`
+
+	export, err := scanLeadExport(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar arquivo da exportação de leads", err)
+		}
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// ListByOrganization lista as exportações mais recentes da organização
+func (r *LeadExportRepository) ListByOrganization(organizationID int64, limit int) ([]*entity.LeadExport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + leadExportColumns + ` FROM lead_exports
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, organizationID, limit)
+	if err != nil {
+		logger.Error("Erro ao listar exportações de leads no banco de dados", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	exports := []*entity.LeadExport{}
+	for rows.Next() {
+		export, err := scanLeadExport(rows)
+		if err != nil {
+			logger.Error("Erro ao ler exportação de leads", err)
+			return nil, err
+		}
+		exports = append(exports, export)
+	}
+
+	return exports, rows.Err()
+}
+
+// ClaimNext reserva a próxima exportação pendente, incluindo as que ficaram
+// presas em processamento há mais de staleAfter. Retorna sql.ErrNoRows se não houver.
+func (r *LeadExportRepository) ClaimNext(staleAfter time.Duration) (*entity.LeadExport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE lead_exports
+		SET status = 'processing', updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM lead_exports
+			WHERE status = 'pending'
+				OR (status = 'processing' AND async AND updated_at < NOW() - make_interval(secs => $1))
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + leadExportColumns
+
+	export, err := scanLeadExport(r.db.QueryRowContext(ctx, query, staleAfter.Seconds()))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao reservar exportação de leads", err)
+		}
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// Complete marca a exportação como concluída, registrando a chave do arquivo
+// gerado no armazenamento (exportações assíncronas), disponível até expiresAt
+func (r *LeadExportRepository) Complete(export *entity.LeadExport, rowCount int, storageKey string, fileSize int64, expiresAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE lead_exports
+		SET status = $1, row_count = $2, storage_key = $3, file_size = $4, expires_at = $5,
+			finished_at = $6, updated_at = $6
+		WHERE id = $7
+	`, entity.LeadExportCompleted, rowCount, storageKey, fileSize, expiresAt, now, export.ID)
+	if err != nil {
+		logger.Error("Erro ao concluir exportação de leads", err)
+		return err
+	}
+
+	export.Status = entity.LeadExportCompleted
+	export.RowCount = rowCount
+	export.StorageKey = storageKey
+	export.FileSize = fileSize
+	export.ExpiresAt = expiresAt
+	export.FinishedAt = &now
+	export.UpdatedAt = now
+	return nil
+}
+
+// Fail marca a exportação como falha, preservando as linhas já enviadas
+func (r *LeadExportRepository) Fail(export *entity.LeadExport, rowCount int, message string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE lead_exports
+		SET status = $1, row_count = $2, error_message = $3, finished_at = $4, updated_at = $4
+		WHERE id = $5
+	`, entity.LeadExportFailed, rowCount, message, now, export.ID)
+	if err != nil {
+		logger.Error("Erro ao registrar falha da exportação de leads", err)
+		return err
+	}
+
+	export.Status = entity.LeadExportFailed
+	export.RowCount = rowCount
+	export.ErrorMessage = message
+	export.FinishedAt = &now
+	return nil
+}
+
+// Release devolve a exportação para a fila, usado no desligamento da aplicação
+func (r *LeadExportRepository) Release(export *entity.LeadExport) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE lead_exports SET status = 'pending', updated_at = NOW()
+		WHERE id = $1 AND status = 'processing'
+	`, export.ID)
+	if err != nil {
+		logger.Error("Erro ao devolver exportação para a fila", err)
+		return err
+	}
+
+	export.Status = entity.LeadExportPending
+	return nil
+}
+
+// RecordDownload contabiliza um download do arquivo para a trilha de auditoria
+func (r *LeadExportRepository) RecordDownload(export *entity.LeadExport) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE lead_exports
+		SET download_count = download_count + 1, last_downloaded_at = $1
+		WHERE id = $2
+	`, now, export.ID)
+	if err != nil {
+		logger.Error("Erro ao registrar download da exportação de leads", err)
+		return err
+	}
+
+	export.DownloadCount++
+	export.LastDownloadedAt = &now
+	return nil
+}
+
+// ExpireFiles marca como expiradas as exportações com o arquivo vencido,
+// mantendo o registro, e retorna as chaves dos arquivos a remover do
+// armazenamento
+func (r *LeadExportRepository) ExpireFiles() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		WITH expired AS (
+			SELECT id, storage_key FROM lead_exports
+			WHERE status = 'completed' AND expires_at < NOW()
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE lead_exports e
+		SET status = 'expired', storage_key = '', updated_at = NOW()
+		FROM expired
+		WHERE e.id = expired.id
+		RETURNING expired.storage_key
+	`)
+	if err != nil {
+		logger.Error("Erro ao descartar exportações expiradas", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			logger.Error("Erro ao ler exportação expirada", err)
+			return nil, err
+		}
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys, rows.Err()
+}
+
+// scanLeadExport lê as colunas de leadExportColumns
+func scanLeadExport(row rowScanner) (*entity.LeadExport, error) {
+	export := &entity.LeadExport{}
+	var columns, filters []byte
+
+	dest := []interface{}{
+		&export.ID,
+		&export.OrganizationID,
+		&export.UserID,
+		&export.Format,
+		&columns,
+		&filters,
+		&export.Async,
+		&export.Status,
+		&export.RowCount,
+		&export.StorageKey,
+		&export.FileSize,
+		&export.ErrorMessage,
+		&export.IPAddress,
+		&export.UserAgent,
+		&export.DownloadCount,
+		&export.LastDownloadedAt,
+		&export.CreatedAt,
+		&export.UpdatedAt,
+		&export.FinishedAt,
+		&export.ExpiresAt,
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(columns, &export.Columns); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filters, &export.Filter); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
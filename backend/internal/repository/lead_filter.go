@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// sqlArgs acumula os argumentos de uma consulta montada dinamicamente
+type sqlArgs []interface{}
+
+// add acrescenta um argumento e retorna seu placeholder ($n)
+func (a *sqlArgs) add(v interface{}) string {
+	*a = append(*a, v)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// likePattern escapa os curingas do LIKE e envolve o termo em %
+func likePattern(term string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(term) + "%"
+}
+
+// leadWhere monta a cláusula WHERE da listagem de leads, sempre restrita à organização
+func leadWhere(organizationID int64, filter entity.LeadFilter, args *sqlArgs) string {
+	conds := []string{"l.organization_id = " + args.add(organizationID)}
+
+	if len(filter.Statuses) > 0 {
+		conds = append(conds, "l.status = ANY("+args.add(filter.Statuses)+")")
+	}
+	if filter.Stage != "" {
+		conds = append(conds, "l.stage = "+args.add(filter.Stage))
+	}
+	if filter.Source != "" {
+		conds = append(conds, "l.source = "+args.add(filter.Source))
+	}
+	if filter.OwnerID != nil {
+		conds = append(conds, "l.owner_id = "+args.add(*filter.OwnerID))
+	}
+	if len(filter.Tags) > 0 {
+		tags := lowerTags(filter.Tags)
+		if filter.TagMode == entity.TagModeAll {
+			conds = append(conds, `(
+			SELECT COUNT(DISTINCT LOWER(t.name)) FROM lead_tags lt JOIN tags t ON t.id = lt.tag_id
+			WHERE lt.lead_id = l.id AND LOWER(t.name) = ANY(`+args.add(tags)+`)) = `+args.add(len(tags)))
+		} else {
+			conds = append(conds, `EXISTS (
+			SELECT 1 FROM lead_tags lt JOIN tags t ON t.id = lt.tag_id
+			WHERE lt.lead_id = l.id AND LOWER(t.name) = ANY(`+args.add(tags)+`))`)
+		}
+	}
+	if len(filter.ExcludeTags) > 0 {
+		conds = append(conds, `NOT EXISTS (
+			SELECT 1 FROM lead_tags lt JOIN tags t ON t.id = lt.tag_id
+			WHERE lt.lead_id = l.id AND LOWER(t.name) = ANY(`+args.add(lowerTags(filter.ExcludeTags))+`))`)
+	}
+	if filter.Search != "" {
+		p := args.add(likePattern(filter.Search))
+		conds = append(conds, "(l.name ILIKE "+p+" OR l.email ILIKE "+p+" OR l.phone LIKE "+p+")")
+	}
+	if filter.CreatedFrom != nil {
+		conds = append(conds, "l.created_at >= "+args.add(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conds = append(conds, "l.created_at < "+args.add(*filter.CreatedTo))
+	}
+	if filter.LastMessageFrom != nil {
+		conds = append(conds, "l.last_message_at >= "+args.add(*filter.LastMessageFrom))
+	}
+	if filter.LastMessageTo != nil {
+		conds = append(conds, "l.last_message_at < "+args.add(*filter.LastMessageTo))
+	}
+	if filter.ActiveWithinDays != nil {
+		conds = append(conds, "l.last_message_at >= NOW() - make_interval(days => "+args.add(*filter.ActiveWithinDays)+"::integer)")
+	}
+	if filter.InactiveForDays != nil {
+		conds = append(conds, "(l.last_message_at IS NULL OR l.last_message_at < NOW() - make_interval(days => "+args.add(*filter.InactiveForDays)+"::integer))")
+	}
+	for _, c := range filter.CustomFields {
+		if cond := customFieldCondition(c, args); cond != "" {
+			conds = append(conds, cond)
+		}
+	}
+
+	return strings.Join(conds, " AND ")
+}
+
+// lowerTags normaliza os nomes de etiquetas para a comparação sem distinção
+// de maiúsculas, removendo repetições para que o modo "all" conte cada uma
+// uma única vez
+func lowerTags(names []string) []string {
+	tags := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		tag := strings.ToLower(strings.TrimSpace(name))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// leadOrderBy traduz a ordenação do filtro, desempatando pelo ID para paginação estável
+func leadOrderBy(sort string) string {
+	if sort == "" || !entity.IsValidLeadSort(sort) {
+		sort = entity.DefaultLeadSort
+	}
+
+	direction := "ASC"
+	if strings.HasPrefix(sort, "-") {
+		direction = "DESC"
+		sort = sort[1:]
+	}
+
+	column, nulls := "l."+sort, ""
+	switch {
+	case sort == "name":
+		column = "LOWER(l.name)"
+	case sort == "last_message_at":
+		nulls = " NULLS LAST"
+	case strings.HasPrefix(sort, entity.CustomFieldPrefix):
+		// A chave já foi validada por IsValidLeadSort. A ordem do jsonb compara
+		// números numericamente e datas ISO como texto, servindo a todos os tipos.
+		column = "l.custom_fields->'" + strings.TrimPrefix(sort, entity.CustomFieldPrefix) + "'"
+		nulls = " NULLS LAST"
+	}
+
+	return column + " " + direction + nulls + ", l.id " + direction
+}
+
+// customFieldCondition traduz uma condição sobre campo personalizado. A
+// igualdade usa containment (@>), atendido pelo índice GIN de custom_fields;
+// os intervalos exigem que o valor gravado seja do mesmo tipo jsonb do limite.
+func customFieldCondition(c entity.CustomFieldCondition, args *sqlArgs) string {
+	switch c.Op {
+	case entity.CustomFieldOpEq:
+		doc, err := json.Marshal(map[string]interface{}{c.Key: c.Value})
+		if err != nil {
+			return ""
+		}
+		return "l.custom_fields @> " + args.add(string(doc)) + "::jsonb"
+	case entity.CustomFieldOpMin, entity.CustomFieldOpMax:
+		bound, err := json.Marshal(c.Value)
+		if err != nil {
+			return ""
+		}
+		operator := ">="
+		if c.Op == entity.CustomFieldOpMax {
+			operator = "<="
+		}
+		field := "l.custom_fields->" + args.add(c.Key)
+		b := args.add(string(bound)) + "::jsonb"
+		return "(jsonb_typeof(" + field + ") = jsonb_typeof(" + b + ") AND " + field + " " + operator + " " + b + ")"
+	}
+	return ""
+}
@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// LeadImportRepository é responsável pelas operações de banco de dados relacionadas às importações de leads
+type LeadImportRepository struct {
+	db *sql.DB
+}
+
+// NewLeadImportRepository cria uma nova instância do repositório de importações
+func NewLeadImportRepository(db *sql.DB) *LeadImportRepository {
+	return &LeadImportRepository{
+		db: db,
+	}
+}
+
+// leadImportColumns lista as colunas lidas em todas as consultas (sem o conteúdo do arquivo)
+const leadImportColumns = `
+	id, organization_id, COALESCE(user_id, 0), file_name, file_type, mapping, status,
+	total_rows, processed_rows, created_rows, failed_rows, errors, error_message,
+	created_at, updated_at, started_at, finished_at
+`
+
+// Create insere um novo job de importação
+func (r *LeadImportRepository) Create(job *entity.LeadImport) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	mapping, err := json.Marshal(job.Mapping)
+	if err != nil {
+		return err
+	}
+	errs, err := json.Marshal(job.Errors)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO lead_imports (organization_id, user_id, file_name, file_type, file_data, mapping, status,
+			total_rows, errors, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+
+	err = r.db.QueryRowContext(
+		ctx,
+		query,
+		job.OrganizationID,
+		job.UserID,
+		job.FileName,
+		job.FileType,
+		job.FileData,
+		mapping,
+		job.Status,
+		job.TotalRows,
+		errs,
+		job.CreatedAt,
+		job.UpdatedAt,
+	).Scan(&job.ID)
+
+	if err != nil {
+		logger.Error("Erro ao criar importação de leads no banco de dados", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetByID busca uma importação da organização pelo ID
+func (r *LeadImportRepository) GetByID(organizationID, id int64) (*entity.LeadImport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + leadImportColumns + ` FROM lead_imports WHERE id = $1 AND organization_id = $2`
+
+	job, err := scanLeadImport(r.db.QueryRowContext(ctx, query, id, organizationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.Error("Erro ao buscar importação de leads no banco de dados", err)
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// ListByOrganization lista as importações mais recentes da organização
+func (r *LeadImportRepository) ListByOrganization(organizationID int64, limit int) ([]*entity.LeadImport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + leadImportColumns + ` FROM lead_imports
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, organizationID, limit)
+	if err != nil {
+		logger.Error("Erro ao listar importações de leads no banco de dados", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*entity.LeadImport{}
+	for rows.Next() {
+		job, err := scanLeadImport(rows)
+		if err != nil {
+			logger.Error("Erro ao ler importação de leads", err)
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// ClaimNext reserva o próximo job pendente, incluindo jobs em processamento
+// sem atualização há mais de staleAfter (instância que caiu no meio do
+// processamento). O SKIP LOCKED garante que cada job seja reservado por
+// apenas uma instância da API. Retorna sql.ErrNoRows se não houver jobs.
+func (r *LeadImportRepository) ClaimNext(staleAfter time.Duration) (*entity.LeadImport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE lead_imports
+		SET status = 'processing',
+			started_at = COALESCE(started_at, NOW()),
+			updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM lead_imports
+			WHERE status = 'pending'
+				OR (status = 'processing' AND updated_at < NOW() - make_interval(secs => $1))
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + leadImportColumns + `, file_data`
+
+	var fileData []byte
+	job, err := scanLeadImport(r.db.QueryRowContext(ctx, query, staleAfter.Seconds()), &fileData)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao reservar importação de leads", err)
+		}
+		return nil, err
+	}
+	job.FileData = fileData
+
+	return job, nil
+}
+
+// SaveBatch grava os leads de um lote e o progresso do job na mesma
+// transação, permitindo retomar a importação exatamente de onde parou.
+// Leads cujo telefone já existe na organização não são gravados; para cada
+// um, onDuplicate é chamado antes de salvar o progresso, para que o job
+// registre o erro da linha.
+func (r *LeadImportRepository) SaveBatch(job *entity.LeadImport, leads []*entity.Lead, onDuplicate func(index int, existingID int64)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação do lote de importação", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, lead := range leads {
+		if err := insertUniqueLead(ctx, tx, lead); err != nil {
+			var dup *DuplicateLeadError
+			if errors.As(err, &dup) {
+				onDuplicate(i, dup.ExistingID)
+				continue
+			}
+			logger.Error("Erro ao inserir lead importado", err)
+			return err
+		}
+
+		created := entity.NewActivity(lead.OrganizationID, lead.ID, job.UserID, entity.ActivityLeadCreated)
+		created.Data["origin"] = "import"
+		created.Data["import_id"] = job.ID
+		created.Data["source"] = lead.Source
+		if err := insertActivity(ctx, tx, created); err != nil {
+			logger.Error("Erro ao registrar lead importado no histórico", err)
+			return err
+		}
+	}
+
+	if err := updateLeadImportProgress(ctx, tx, job); err != nil {
+		logger.Error("Erro ao atualizar progresso da importação", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Complete marca o job como concluído e descarta o arquivo armazenado
+func (r *LeadImportRepository) Complete(job *entity.LeadImport) error {
+	return r.finish(job, entity.LeadImportCompleted, "")
+}
+
+// Fail marca o job como falho com a mensagem informada
+func (r *LeadImportRepository) Fail(job *entity.LeadImport, message string) error {
+	return r.finish(job, entity.LeadImportFailed, message)
+}
+
+// Release devolve o job para a fila, usado quando a aplicação é desligada no meio do processamento
+func (r *LeadImportRepository) Release(job *entity.LeadImport) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE lead_imports SET status = 'pending', updated_at = NOW()
+		WHERE id = $1 AND status = 'processing'
+	`, job.ID)
+	if err != nil {
+		logger.Error("Erro ao devolver importação para a fila", err)
+		return err
+	}
+
+	job.Status = entity.LeadImportPending
+	return nil
+}
+
+func (r *LeadImportRepository) finish(job *entity.LeadImport, status, message string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errs, err := json.Marshal(job.Errors)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE lead_imports
+		SET status = $1, error_message = $2, errors = $3, processed_rows = $4, created_rows = $5,
+			failed_rows = $6, file_data = NULL, finished_at = $7, updated_at = $7
+		WHERE id = $8
+	`, status, message, errs, job.ProcessedRows, job.CreatedRows, job.FailedRows, now, job.ID)
+	if err != nil {
+		logger.Error("Erro ao finalizar importação de leads", err)
+		return err
+	}
+
+	job.Status = status
+	job.ErrorMessage = message
+	job.FinishedAt = &now
+	job.FileData = nil
+	return nil
+}
+
+// updateLeadImportProgress grava os contadores e erros acumulados do job
+func updateLeadImportProgress(ctx context.Context, tx *sql.Tx, job *entity.LeadImport) error {
+	errs, err := json.Marshal(job.Errors)
+	if err != nil {
+		return err
+	}
+
+	job.UpdatedAt = time.Now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE lead_imports
+		SET processed_rows = $1, created_rows = $2, failed_rows = $3, errors = $4, updated_at = $5
+		WHERE id = $6
+	`, job.ProcessedRows, job.CreatedRows, job.FailedRows, errs, job.UpdatedAt, job.ID)
+	return err
+}
+
+// rowScanner é satisfeito por *sql.Row e *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanLeadImport lê as colunas de leadImportColumns seguidas dos destinos extras informados
+func scanLeadImport(row rowScanner, extra ...interface{}) (*entity.LeadImport, error) {
+	job := &entity.LeadImport{}
+	var mapping, errs []byte
+
+	dest := []interface{}{
+		&job.ID,
+		&job.OrganizationID,
+		&job.UserID,
+		&job.FileName,
+		&job.FileType,
+		&mapping,
+		&job.Status,
+		&job.TotalRows,
+		&job.ProcessedRows,
+		&job.CreatedRows,
+		&job.FailedRows,
+		&errs,
+		&job.ErrorMessage,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(mapping, &job.Mapping); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(errs, &job.Errors); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
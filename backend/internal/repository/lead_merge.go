@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// MissingLeadsError indica leads da mesclagem que não existem na organização
+type MissingLeadsError struct {
+	IDs []int64
+}
+
+func (e *MissingLeadsError) Error() string {
+	return fmt.Sprintf("leads não encontrados: %v", e.IDs)
+}
+
+// leadReference descreve uma tabela com registros pertencentes a um lead
+type leadReference struct {
+	table string
+	// uniqueColumn indica uma tabela de associação com chave (lead_id,
+	// uniqueColumn); associações que o sobrevivente já possui são descartadas
+	uniqueColumn string
+}
+
+// leadReferences lista as tabelas transferidas para o lead sobrevivente em
+// uma mesclagem. Toda nova tabela com lead_id precisa ser registrada aqui,
+// caso contrário seus registros seriam apagados em cascata junto com os
+// leads mesclados.
+var leadReferences = []leadReference{
+	{table: "lead_tags", uniqueColumn: "tag_id"},
+	{table: "conversations"},
+	{table: "messages"},
+	{table: "lead_notes"},
+	{table: "tasks"},
+	{table: "lead_activities"},
+	{table: "campaign_recipients", uniqueColumn: "campaign_id"},
+	{table: "lead_consents"},
+	{table: "automation_runs"},
+	{table: "chatbot_sessions"},
+}
+
+// Merge combina os leads mergedIDs no sobrevivente em uma única transação:
+// bloqueia os leads, grava os valores escolhidos por resolve (que recebe os
+// mesclados na ordem solicitada), transfere os registros de leadReferences,
+// cria redirecionamentos dos IDs antigos, remove os leads mesclados e grava
+// o histórico do sobrevivente e a entrada de auditoria. Leads inexistentes na organização resultam em
+// *MissingLeadsError.
+func (r *LeadRepository) Merge(organizationID, survivorID int64, mergedIDs []int64, resolve func(survivor *entity.Lead, merged []*entity.Lead) (*entity.Lead, error), audit *entity.AuditEntry) (*entity.Lead, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de mesclagem de leads", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// A ordem por ID evita deadlock entre mesclagens concorrentes dos mesmos leads
+	ids := append([]int64{survivorID}, mergedIDs...)
+	rows, err := tx.QueryContext(ctx, `SELECT `+leadSelectColumns+` FROM leads l
+		WHERE l.organization_id = $1 AND l.id = ANY($2)
+		ORDER BY l.id
+		FOR UPDATE OF l`, organizationID, ids)
+	if err != nil {
+		logger.Error("Erro ao bloquear leads para mesclagem", err)
+		return nil, err
+	}
+	found := make(map[int64]*entity.Lead, len(ids))
+	for rows.Next() {
+		lead, err := scanLead(rows)
+		if err != nil {
+			rows.Close()
+			logger.Error("Erro ao ler lead", err)
+			return nil, err
+		}
+		found[lead.ID] = lead
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro ao bloquear leads para mesclagem", err)
+		return nil, err
+	}
+
+	var missing []int64
+	merged := make([]*entity.Lead, 0, len(mergedIDs))
+	for _, id := range ids {
+		lead, ok := found[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		if id != survivorID {
+			merged = append(merged, lead)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingLeadsError{IDs: missing}
+	}
+
+	survivor := found[survivorID]
+	result, err := resolve(survivor, merged)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Phone != survivor.Phone {
+		// Mesmo lock de insertUniqueLead, serializando com cadastros do novo telefone
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, int32(organizationID), result.Phone); err != nil {
+			logger.Error("Erro ao bloquear telefone do lead mesclado", err)
+			return nil, err
+		}
+	}
+
+	if err := updateLead(ctx, tx, result); err != nil {
+		logger.Error("Erro ao atualizar lead sobrevivente", err)
+		return nil, err
+	}
+
+	for _, ref := range leadReferences {
+		if err := moveLeadReference(ctx, tx, ref, survivorID, mergedIDs); err != nil {
+			logger.Error("Erro ao transferir registros de "+ref.table, err)
+			return nil, err
+		}
+	}
+
+	// Redirecionamentos de mesclagens anteriores passam a apontar para o novo sobrevivente
+	if _, err := tx.ExecContext(ctx, `UPDATE lead_redirects SET lead_id = $1 WHERE lead_id = ANY($2)`, survivorID, mergedIDs); err != nil {
+		logger.Error("Erro ao atualizar redirecionamentos de leads", err)
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO lead_redirects (old_lead_id, lead_id, organization_id, merged_at)
+		SELECT unnest($1::integer[]), $2, $3, $4
+	`, mergedIDs, survivorID, organizationID, result.UpdatedAt); err != nil {
+		logger.Error("Erro ao criar redirecionamentos de leads", err)
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM leads WHERE id = ANY($1)`, mergedIDs); err != nil {
+		logger.Error("Erro ao remover leads mesclados", err)
+		return nil, err
+	}
+
+	mergedActivity := entity.NewActivity(organizationID, survivorID, audit.UserID, entity.ActivityLeadMerged)
+	mergedActivity.Data["merged_ids"] = mergedIDs
+	activities := append([]*entity.Activity{mergedActivity}, entity.LeadChangeActivities(survivor, result, audit.UserID)...)
+	if err := insertActivities(ctx, tx, activities); err != nil {
+		logger.Error("Erro ao registrar mesclagem no histórico do lead", err)
+		return nil, err
+	}
+
+	if err := insertAuditEntry(ctx, tx, audit); err != nil {
+		logger.Error("Erro ao registrar auditoria da mesclagem", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar mesclagem de leads", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// moveLeadReference transfere os registros dos leads mesclados para o
+// sobrevivente. Em tabelas de associação, cada lead é tratado em sequência
+// para que associações repetidas entre os mesclados não violem a chave.
+func moveLeadReference(ctx context.Context, tx *sql.Tx, ref leadReference, survivorID int64, mergedIDs []int64) error {
+	if ref.uniqueColumn == "" {
+		_, err := tx.ExecContext(ctx, `UPDATE `+ref.table+` SET lead_id = $1 WHERE lead_id = ANY($2)`, survivorID, mergedIDs)
+		return err
+	}
+
+	query := `UPDATE ` + ref.table + ` SET lead_id = $1
+		WHERE lead_id = $2
+			AND ` + ref.uniqueColumn + ` NOT IN (SELECT ` + ref.uniqueColumn + ` FROM ` + ref.table + ` WHERE lead_id = $1)`
+	for _, id := range mergedIDs {
+		if _, err := tx.ExecContext(ctx, query, survivorID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateLead grava os campos editáveis do lead
+func updateLead(ctx context.Context, tx *sql.Tx, lead *entity.Lead) error {
+	customFields, err := json.Marshal(lead.CustomFields)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE leads
+		SET owner_id = $1, name = $2, phone = $3, email = $4, source = $5, status = $6, stage = $7,
+			custom_fields = $8, last_message_at = $9, created_at = $10, updated_at = $11
+		WHERE id = $12
+	`,
+		lead.OwnerID,
+		lead.Name,
+		lead.Phone,
+		lead.Email,
+		lead.Source,
+		lead.Status,
+		lead.Stage,
+		customFields,
+		lead.LastMessageAt,
+		lead.CreatedAt,
+		lead.UpdatedAt,
+		lead.ID,
+	)
+	return err
+}
@@ -0,0 +1,410 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// queryRower é satisfeito tanto por *sql.DB quanto por *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// LeadRepository é responsável pelas operações de banco de dados relacionadas aos leads
+type LeadRepository struct {
+	db *sql.DB
+}
+
+// NewLeadRepository cria uma nova instância do repositório de leads
+func NewLeadRepository(db *sql.DB) *LeadRepository {
+	return &LeadRepository{
+		db: db,
+	}
+}
+
+// DuplicateLeadError indica que a organização já possui um lead com o mesmo telefone
+type DuplicateLeadError struct {
+	ExistingID int64
+}
+
+func (e *DuplicateLeadError) Error() string {
+	return fmt.Sprintf("já existe o lead %d com este telefone", e.ExistingID)
+}
+
+// Create insere um novo lead, recusando com *DuplicateLeadError telefones já
+// cadastrados na organização, e registra a criação no histórico em nome de userID
+func (r *LeadRepository) Create(lead *entity.Lead, userID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de criação de lead", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertUniqueLead(ctx, tx, lead); err != nil {
+		var dup *DuplicateLeadError
+		if !errors.As(err, &dup) {
+			logger.Error("Erro ao criar lead no banco de dados", err)
+		}
+		return err
+	}
+
+	created := entity.NewActivity(lead.OrganizationID, lead.ID, userID, entity.ActivityLeadCreated)
+	created.Data["origin"] = "manual"
+	created.Data["source"] = lead.Source
+	if err := insertActivity(ctx, tx, created); err != nil {
+		logger.Error("Erro ao registrar criação do lead no histórico", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetByID busca um lead da organização pelo ID. IDs de leads mesclados são
+// resolvidos para o lead sobrevivente, que é retornado com o seu próprio ID.
+func (r *LeadRepository) GetByID(organizationID, id int64) (*entity.Lead, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + leadSelectColumns + ` FROM leads l
+		WHERE l.id = COALESCE((
+				SELECT lead_id FROM lead_redirects WHERE old_lead_id = $1 AND organization_id = $2
+			), $1)
+			AND l.organization_id = $2`
+
+	lead, err := scanLead(r.db.QueryRowContext(ctx, query, id, organizationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.Error("Erro ao buscar lead no banco de dados", err)
+		return nil, err
+	}
+
+	return lead, nil
+}
+
+// Update altera o lead em uma transação: bloqueia o registro, aplica as
+// alterações de apply sobre uma cópia, recusa com *DuplicateLeadError um
+// telefone já usado por outro lead da organização e registra no histórico,
+// em nome de userID, os campos alterados com os valores anterior e novo.
+func (r *LeadRepository) Update(organizationID, id, userID int64, apply func(lead *entity.Lead) error) (*entity.Lead, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de atualização de lead", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := scanLead(tx.QueryRowContext(ctx, `SELECT `+leadSelectColumns+` FROM leads l
+		WHERE l.id = $1 AND l.organization_id = $2
+		FOR UPDATE OF l`, id, organizationID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao bloquear lead para atualização", err)
+		}
+		return nil, err
+	}
+
+	lead := *before
+	lead.CustomFields = make(map[string]interface{}, len(before.CustomFields))
+	for key, value := range before.CustomFields {
+		lead.CustomFields[key] = value
+	}
+	if err := apply(&lead); err != nil {
+		return nil, err
+	}
+
+	if lead.Phone != before.Phone {
+		// Mesmo lock de insertUniqueLead, serializando com cadastros do novo telefone
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, int32(organizationID), lead.Phone); err != nil {
+			logger.Error("Erro ao bloquear telefone do lead", err)
+			return nil, err
+		}
+		var existingID int64
+		err := tx.QueryRowContext(ctx, `
+			SELECT id FROM leads WHERE organization_id = $1 AND phone = $2 AND id <> $3 ORDER BY id LIMIT 1
+		`, organizationID, lead.Phone, lead.ID).Scan(&existingID)
+		if err == nil {
+			return nil, &DuplicateLeadError{ExistingID: existingID}
+		}
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao verificar telefone do lead", err)
+			return nil, err
+		}
+	}
+
+	activities := entity.LeadChangeActivities(before, &lead, userID)
+	if len(activities) == 0 {
+		return before, nil
+	}
+
+	lead.UpdatedAt = time.Now()
+	if err := updateLead(ctx, tx, &lead); err != nil {
+		logger.Error("Erro ao atualizar lead no banco de dados", err)
+		return nil, err
+	}
+	if err := insertActivities(ctx, tx, activities); err != nil {
+		logger.Error("Erro ao registrar alteração do lead no histórico", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar atualização de lead", err)
+		return nil, err
+	}
+
+	return &lead, nil
+}
+
+// FindDuplicateCandidates retorna os leads da organização, exceto excludeID,
+// com o mesmo telefone ou email ou cujo nome contenha algum dos termos
+// informados. A pontuação final, incluindo a semelhança de nomes, é
+// calculada pelo chamador.
+func (r *LeadRepository) FindDuplicateCandidates(organizationID int64, phone, email string, nameTerms []string, excludeID int64, limit int) ([]*entity.Lead, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var args sqlArgs
+	var matches []string
+	if phone != "" {
+		matches = append(matches, "l.phone = "+args.add(phone))
+	}
+	if email != "" {
+		matches = append(matches, "LOWER(l.email) = LOWER("+args.add(email)+")")
+	}
+	for _, term := range nameTerms {
+		matches = append(matches, "l.name ILIKE "+args.add(likePattern(term)))
+	}
+	if len(matches) == 0 {
+		return []*entity.Lead{}, nil
+	}
+
+	query := `SELECT ` + leadSelectColumns + ` FROM leads l
+		WHERE l.organization_id = ` + args.add(organizationID) + `
+			AND l.id <> ` + args.add(excludeID) + `
+			AND (` + strings.Join(matches, " OR ") + `)
+		ORDER BY l.created_at
+		LIMIT ` + args.add(limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error("Erro ao buscar possíveis leads duplicados", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	leads := []*entity.Lead{}
+	for rows.Next() {
+		lead, err := scanLead(rows)
+		if err != nil {
+			logger.Error("Erro ao ler lead", err)
+			return nil, err
+		}
+		leads = append(leads, lead)
+	}
+
+	return leads, rows.Err()
+}
+
+// leadSelectColumns lista as colunas lidas nas consultas de leads, incluindo as etiquetas
+const leadSelectColumns = `
+	l.id, l.organization_id, l.owner_id, l.name, l.phone, l.email, l.source, l.status, l.stage,
+	l.custom_fields, l.last_message_at, l.created_at, l.updated_at,
+	COALESCE((
+		SELECT json_agg(t.name ORDER BY t.name)
+		FROM lead_tags lt JOIN tags t ON t.id = lt.tag_id
+		WHERE lt.lead_id = l.id
+	), '[]')
+`
+
+// List retorna uma página de leads da organização que atendem ao filtro, junto com o total
+func (r *LeadRepository) List(organizationID int64, filter entity.LeadFilter, limit, offset int) ([]*entity.Lead, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	total, err := r.count(ctx, organizationID, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var args sqlArgs
+	query := `SELECT ` + leadSelectColumns + ` FROM leads l
+		WHERE ` + leadWhere(organizationID, filter, &args) + `
+		ORDER BY ` + leadOrderBy(filter.Sort) + `
+		LIMIT ` + args.add(limit) + ` OFFSET ` + args.add(offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error("Erro ao listar leads no banco de dados", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	leads := []*entity.Lead{}
+	for rows.Next() {
+		lead, err := scanLead(rows)
+		if err != nil {
+			logger.Error("Erro ao ler lead", err)
+			return nil, 0, err
+		}
+		leads = append(leads, lead)
+	}
+
+	return leads, total, rows.Err()
+}
+
+// Count retorna quantos leads da organização atendem ao filtro
+func (r *LeadRepository) Count(organizationID int64, filter entity.LeadFilter) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return r.count(ctx, organizationID, filter)
+}
+
+func (r *LeadRepository) count(ctx context.Context, organizationID int64, filter entity.LeadFilter) (int, error) {
+	var args sqlArgs
+	query := `SELECT COUNT(*) FROM leads l WHERE ` + leadWhere(organizationID, filter, &args)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		logger.Error("Erro ao contar leads no banco de dados", err)
+		return 0, err
+	}
+	return total, nil
+}
+
+// Stream percorre todos os leads que atendem ao filtro, na ordem da listagem,
+// sem carregá-los de uma vez em memória. A iteração para no primeiro erro de fn.
+func (r *LeadRepository) Stream(ctx context.Context, organizationID int64, filter entity.LeadFilter, fn func(*entity.Lead) error) error {
+	var args sqlArgs
+	query := `SELECT ` + leadSelectColumns + ` FROM leads l
+		WHERE ` + leadWhere(organizationID, filter, &args) + `
+		ORDER BY ` + leadOrderBy(filter.Sort)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error("Erro ao consultar leads para exportação", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		lead, err := scanLead(rows)
+		if err != nil {
+			logger.Error("Erro ao ler lead", err)
+			return err
+		}
+		if err := fn(lead); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// scanLead lê as colunas de leadSelectColumns
+func scanLead(row rowScanner) (*entity.Lead, error) {
+	lead := &entity.Lead{}
+	var ownerID sql.NullInt64
+	var customFields, tags []byte
+
+	err := row.Scan(
+		&lead.ID,
+		&lead.OrganizationID,
+		&ownerID,
+		&lead.Name,
+		&lead.Phone,
+		&lead.Email,
+		&lead.Source,
+		&lead.Status,
+		&lead.Stage,
+		&customFields,
+		&lead.LastMessageAt,
+		&lead.CreatedAt,
+		&lead.UpdatedAt,
+		&tags,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if ownerID.Valid {
+		lead.OwnerID = &ownerID.Int64
+	}
+	if err := json.Unmarshal(customFields, &lead.CustomFields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(tags, &lead.Tags); err != nil {
+		return nil, err
+	}
+	return lead, nil
+}
+
+// insertUniqueLead grava o lead se a organização ainda não tiver outro com o
+// mesmo telefone. O advisory lock por organização e telefone serializa
+// criações concorrentes do mesmo número até o fim da transação, sem exigir
+// um índice único que falharia com duplicados cadastrados antes desta regra.
+func insertUniqueLead(ctx context.Context, tx *sql.Tx, lead *entity.Lead) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, int32(lead.OrganizationID), lead.Phone)
+	if err != nil {
+		return err
+	}
+
+	var existingID int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT id FROM leads WHERE organization_id = $1 AND phone = $2 ORDER BY id LIMIT 1
+	`, lead.OrganizationID, lead.Phone).Scan(&existingID)
+	if err == nil {
+		return &DuplicateLeadError{ExistingID: existingID}
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	return insertLead(ctx, tx, lead)
+}
+
+// insertLead grava o lead usando a conexão ou transação informada
+func insertLead(ctx context.Context, q queryRower, lead *entity.Lead) error {
+	customFields, err := json.Marshal(lead.CustomFields)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO leads (organization_id, owner_id, name, phone, email, source, status, stage, custom_fields, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+
+	return q.QueryRowContext(
+		ctx,
+		query,
+		lead.OrganizationID,
+		lead.OwnerID,
+		lead.Name,
+		lead.Phone,
+		lead.Email,
+		lead.Source,
+		lead.Status,
+		lead.Stage,
+		customFields,
+		lead.CreatedAt,
+		lead.UpdatedAt,
+	).Scan(&lead.ID)
+}
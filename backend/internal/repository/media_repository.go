@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// MediaRepository é responsável pelos arquivos das mensagens com mídia
+type MediaRepository struct {
+	db *sql.DB
+}
+
+// NewMediaRepository cria uma nova instância do repositório de mídias
+func NewMediaRepository(db *sql.DB) *MediaRepository {
+	return &MediaRepository{
+		db: db,
+	}
+}
+
+const mediaSelectColumns = `
+	id, organization_id, message_id, status, provider_media_id, storage_key, mime_type, file_name, size, sha256,
+	attempts, error, created_at`
+
+// GetByID busca uma mídia pelo ID. Usado pelos links assinados, que já
+// identificam a mídia sem a organização.
+func (r *MediaRepository) GetByID(id int64) (*entity.MessageMedia, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	media, err := scanMedia(r.db.QueryRowContext(ctx, `SELECT `+mediaSelectColumns+` FROM message_media WHERE id = $1`, id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar mídia", err)
+		}
+		return nil, err
+	}
+	return media, nil
+}
+
+// ClaimPending reserva até limit mídias recebidas a baixar, adiando a
+// próxima tentativa por lease para que outras instâncias não as baixem ao
+// mesmo tempo. Uma instância que cair libera a mídia ao fim do prazo.
+func (r *MediaRepository) ClaimPending(limit int, lease time.Duration) ([]*entity.MessageMedia, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE message_media SET attempts = attempts + 1, next_attempt_at = $1, updated_at = $2
+		WHERE id IN (
+			SELECT id FROM message_media
+			WHERE status = $3 AND next_attempt_at <= $2
+			ORDER BY next_attempt_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+mediaSelectColumns, now.Add(lease), now, entity.MediaPending, limit)
+	if err != nil {
+		logger.Error("Erro ao reservar mídias pendentes", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	medias := []*entity.MessageMedia{}
+	for rows.Next() {
+		media, err := scanMedia(rows)
+		if err != nil {
+			logger.Error("Erro ao ler mídia pendente", err)
+			return nil, err
+		}
+		medias = append(medias, media)
+	}
+	return medias, rows.Err()
+}
+
+// MarkStored grava o arquivo baixado da mídia
+func (r *MediaRepository) MarkStored(media *entity.MessageMedia) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE message_media
+		SET status = $1, storage_key = $2, mime_type = $3, size = $4, sha256 = $5, error = '', updated_at = $6
+		WHERE id = $7
+	`, entity.MediaStored, media.StorageKey, media.MIMEType, media.Size, media.SHA256, time.Now().UTC(), media.ID)
+	if err != nil {
+		logger.Error("Erro ao registrar mídia armazenada", err)
+		return err
+	}
+	return nil
+}
+
+// Retry agenda uma nova tentativa de download da mídia
+func (r *MediaRepository) Retry(id int64, at time.Time, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE message_media SET next_attempt_at = $1, error = $2, updated_at = $3 WHERE id = $4
+	`, at, reason, time.Now().UTC(), id)
+	if err != nil {
+		logger.Error("Erro ao reagendar download de mídia", err)
+		return err
+	}
+	return nil
+}
+
+// Fail marca a mídia como não baixada, com o motivo
+func (r *MediaRepository) Fail(id int64, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE message_media SET status = $1, error = $2, updated_at = $3 WHERE id = $4
+	`, entity.MediaFailed, reason, time.Now().UTC(), id)
+	if err != nil {
+		logger.Error("Erro ao registrar falha no download de mídia", err)
+		return err
+	}
+	return nil
+}
+
+func scanMedia(row rowScanner) (*entity.MessageMedia, error) {
+	m := &entity.MessageMedia{}
+	err := row.Scan(&m.ID, &m.OrganizationID, &m.MessageID, &m.Status, &m.ProviderMediaID, &m.StorageKey, &m.MIMEType,
+		&m.FileName, &m.Size, &m.SHA256, &m.Attempts, &m.Error, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// NoteRepository é responsável pelas operações de banco de dados relacionadas às notas dos leads
+type NoteRepository struct {
+	db *sql.DB
+}
+
+// NewNoteRepository cria uma nova instância do repositório de notas
+func NewNoteRepository(db *sql.DB) *NoteRepository {
+	return &NoteRepository{
+		db: db,
+	}
+}
+
+// noteSelectColumns lista as colunas lidas nas consultas de notas, incluindo as menções
+const noteSelectColumns = `
+	n.id, n.organization_id, n.lead_id, COALESCE(n.user_id, 0), n.body, n.created_at, n.updated_at,
+	COALESCE((SELECT json_agg(nm.user_id ORDER BY nm.user_id) FROM note_mentions nm WHERE nm.note_id = n.id), '[]')
+`
+
+// Create grava a nota com suas menções, as notificações dos mencionados,
+// que recebem o ID da nota em data.note_id, e a atividade no histórico do lead
+func (r *NoteRepository) Create(note *entity.Note, notifications []*entity.Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de criação de nota", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO lead_notes (organization_id, lead_id, user_id, body, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6)
+		RETURNING id
+	`, note.OrganizationID, note.LeadID, note.UserID, note.Body, note.CreatedAt, note.UpdatedAt).Scan(&note.ID); err != nil {
+		logger.Error("Erro ao criar nota no banco de dados", err)
+		return err
+	}
+
+	if err := r.saveMentions(ctx, tx, note, notifications); err != nil {
+		return err
+	}
+
+	activity := entity.NewActivity(note.OrganizationID, note.LeadID, note.UserID, entity.ActivityNoteCreated)
+	activity.Data["note_id"] = note.ID
+	activity.Data["body"] = entity.ActivityPreview(note.Body)
+	if err := insertActivity(ctx, tx, activity); err != nil {
+		logger.Error("Erro ao registrar nota no histórico do lead", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar criação de nota", err)
+		return err
+	}
+	return nil
+}
+
+// GetByID busca uma nota da organização pelo ID
+func (r *NoteRepository) GetByID(organizationID, id int64) (*entity.Note, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + noteSelectColumns + ` FROM lead_notes n WHERE n.id = $1 AND n.organization_id = $2`
+
+	note, err := scanNote(r.db.QueryRowContext(ctx, query, id, organizationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.Error("Erro ao buscar nota no banco de dados", err)
+		return nil, err
+	}
+
+	return note, nil
+}
+
+// ListByLead retorna uma página das notas do lead, das mais recentes para as
+// mais antigas, junto com o total
+func (r *NoteRepository) ListByLead(organizationID, leadID int64, limit, offset int) ([]*entity.Note, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM lead_notes WHERE lead_id = $1 AND organization_id = $2
+	`, leadID, organizationID).Scan(&total); err != nil {
+		logger.Error("Erro ao contar notas do lead", err)
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + noteSelectColumns + ` FROM lead_notes n
+		WHERE n.lead_id = $1 AND n.organization_id = $2
+		ORDER BY n.created_at DESC, n.id DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, leadID, organizationID, limit, offset)
+	if err != nil {
+		logger.Error("Erro ao listar notas no banco de dados", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notes := []*entity.Note{}
+	for rows.Next() {
+		note, err := scanNote(rows)
+		if err != nil {
+			logger.Error("Erro ao ler nota", err)
+			return nil, 0, err
+		}
+		notes = append(notes, note)
+	}
+
+	return notes, total, rows.Err()
+}
+
+// Update grava o novo texto e substitui as menções. As notificações
+// informadas devem se referir apenas aos usuários mencionados pela primeira vez.
+func (r *NoteRepository) Update(note *entity.Note, notifications []*entity.Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de atualização de nota", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	note.UpdatedAt = time.Now()
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE lead_notes SET body = $1, updated_at = $2 WHERE id = $3 AND organization_id = $4
+	`, note.Body, note.UpdatedAt, note.ID, note.OrganizationID); err != nil {
+		logger.Error("Erro ao atualizar nota no banco de dados", err)
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM note_mentions WHERE note_id = $1`, note.ID); err != nil {
+		logger.Error("Erro ao remover menções da nota", err)
+		return err
+	}
+	if err := r.saveMentions(ctx, tx, note, notifications); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar atualização de nota", err)
+		return err
+	}
+	return nil
+}
+
+// Delete remove a nota
+func (r *NoteRepository) Delete(organizationID, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM lead_notes WHERE id = $1 AND organization_id = $2`, id, organizationID)
+	if err != nil {
+		logger.Error("Erro ao remover nota no banco de dados", err)
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// saveMentions grava as menções da nota e as notificações dos mencionados
+func (r *NoteRepository) saveMentions(ctx context.Context, tx *sql.Tx, note *entity.Note, notifications []*entity.Notification) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO note_mentions (note_id, user_id)
+		SELECT $1, unnest($2::integer[])
+	`, note.ID, note.Mentions); err != nil {
+		logger.Error("Erro ao gravar menções da nota", err)
+		return err
+	}
+
+	for _, n := range notifications {
+		n.Data["note_id"] = note.ID
+		if err := insertNotification(ctx, tx, n); err != nil {
+			logger.Error("Erro ao notificar menção em nota", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// scanNote lê as colunas de noteSelectColumns
+func scanNote(row rowScanner) (*entity.Note, error) {
+	note := &entity.Note{}
+	var mentions []byte
+
+	err := row.Scan(
+		&note.ID,
+		&note.OrganizationID,
+		&note.LeadID,
+		&note.UserID,
+		&note.Body,
+		&note.CreatedAt,
+		&note.UpdatedAt,
+		&mentions,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(mentions, &note.Mentions); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// NotificationRepository é responsável pelas operações de banco de dados relacionadas às notificações
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository cria uma nova instância do repositório de notificações
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db: db,
+	}
+}
+
+// notificationColumns lista as colunas lidas em todas as consultas
+const notificationColumns = `
+	id, organization_id, user_id, type, title, body, data, read_at, created_at
+`
+
+// ListByUser retorna as notificações do usuário, das mais recentes para as
+// mais antigas, junto com o total de não lidas
+func (r *NotificationRepository) ListByUser(organizationID, userID int64, unreadOnly bool, limit, offset int) ([]*entity.Notification, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var unread int
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM notifications
+		WHERE user_id = $1 AND organization_id = $2 AND read_at IS NULL
+	`, userID, organizationID).Scan(&unread); err != nil {
+		logger.Error("Erro ao contar notificações não lidas", err)
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + notificationColumns + ` FROM notifications
+		WHERE user_id = $1 AND organization_id = $2 AND ($3 = FALSE OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4 OFFSET $5`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, organizationID, unreadOnly, limit, offset)
+	if err != nil {
+		logger.Error("Erro ao listar notificações no banco de dados", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notifications := []*entity.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			logger.Error("Erro ao ler notificação", err)
+			return nil, 0, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, unread, rows.Err()
+}
+
+// MarkRead marca a notificação do usuário como lida, retornando
+// sql.ErrNoRows se ela não existir
+func (r *NotificationRepository) MarkRead(organizationID, userID, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE notifications SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2 AND organization_id = $3
+	`, id, userID, organizationID)
+	if err != nil {
+		logger.Error("Erro ao marcar notificação como lida", err)
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkAllRead marca todas as notificações do usuário como lidas
+func (r *NotificationRepository) MarkAllRead(organizationID, userID int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE notifications SET read_at = NOW()
+		WHERE user_id = $1 AND organization_id = $2 AND read_at IS NULL
+	`, userID, organizationID)
+	if err != nil {
+		logger.Error("Erro ao marcar notificações como lidas", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// insertNotification grava uma notificação na transação da operação que a originou
+func insertNotification(ctx context.Context, tx *sql.Tx, n *entity.Notification) error {
+	data, err := json.Marshal(n.Data)
+	if err != nil {
+		return err
+	}
+
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO notifications (organization_id, user_id, type, title, body, data, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, n.OrganizationID, n.UserID, n.Type, n.Title, n.Body, data, n.CreatedAt).Scan(&n.ID)
+}
+
+// scanNotification lê as colunas de notificationColumns
+func scanNotification(row rowScanner) (*entity.Notification, error) {
+	n := &entity.Notification{}
+	var data []byte
+	var readAt sql.NullTime
+
+	if err := row.Scan(&n.ID, &n.OrganizationID, &n.UserID, &n.Type, &n.Title, &n.Body, &data, &readAt, &n.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if readAt.Valid {
+		n.ReadAt = &readAt.Time
+	}
+	if err := json.Unmarshal(data, &n.Data); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
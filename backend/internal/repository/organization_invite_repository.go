@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// Erros dos convites para a organização
+var (
+	ErrInvitePending = errors.New("já existe um convite pendente para este email")
+	// ErrInviteUnavailable indica um convite inexistente, expirado, revogado
+	// ou já aceito
+	ErrInviteUnavailable = errors.New("convite inválido ou expirado")
+	ErrEmailInUse        = errors.New("email já cadastrado")
+)
+
+// OrganizationInviteRepository é responsável pelas operações de banco de dados
+// relacionadas aos convites para as organizações
+type OrganizationInviteRepository struct {
+	db *sql.DB
+}
+
+// NewOrganizationInviteRepository cria uma nova instância do repositório de convites
+func NewOrganizationInviteRepository(db *sql.DB) *OrganizationInviteRepository {
+	return &OrganizationInviteRepository{
+		db: db,
+	}
+}
+
+const organizationInviteSelectColumns = `
+	id, organization_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at`
+
+// Create grava um novo convite, retornando ErrInvitePending se o email já
+// tiver um convite pendente na organização. Convites expirados para o mesmo
+// email são descartados.
+func (r *OrganizationInviteRepository) Create(invite *entity.OrganizationInvite) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação do convite", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM organization_invites
+		WHERE organization_id = $1 AND LOWER(email) = LOWER($2) AND accepted_at IS NULL AND expires_at <= $3
+	`, invite.OrganizationID, invite.Email, time.Now())
+	if err != nil {
+		logger.Error("Erro ao descartar convites expirados", err)
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO organization_invites (organization_id, email, role, token_hash, invited_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT DO NOTHING
+		RETURNING id
+	`,
+		invite.OrganizationID,
+		invite.Email,
+		invite.Role,
+		invite.TokenHash,
+		invite.InvitedBy,
+		invite.ExpiresAt,
+		invite.CreatedAt,
+	).Scan(&invite.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvitePending
+		}
+		logger.Error("Erro ao criar convite no banco de dados", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar criação do convite", err)
+		return err
+	}
+	return nil
+}
+
+// ListPending lista os convites ainda não aceitos da organização, dos mais
+// recentes aos mais antigos, incluindo os expirados
+func (r *OrganizationInviteRepository) ListPending(organizationID int64) ([]*entity.OrganizationInvite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+organizationInviteSelectColumns+`
+		FROM organization_invites
+		WHERE organization_id = $1 AND accepted_at IS NULL
+		ORDER BY created_at DESC, id DESC
+	`, organizationID)
+	if err != nil {
+		logger.Error("Erro ao listar convites da organização", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []*entity.OrganizationInvite{}
+	for rows.Next() {
+		invite, err := scanOrganizationInvite(rows)
+		if err != nil {
+			logger.Error("Erro ao ler convite", err)
+			return nil, err
+		}
+		invites = append(invites, invite)
+	}
+	return invites, rows.Err()
+}
+
+// GetByToken busca um convite pendente e dentro da validade pelo token,
+// retornando ErrInviteUnavailable se não houver
+func (r *OrganizationInviteRepository) GetByToken(token string) (*entity.OrganizationInvite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	invite, err := scanOrganizationInvite(r.db.QueryRowContext(ctx, `
+		SELECT `+organizationInviteSelectColumns+`
+		FROM organization_invites
+		WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > $2
+	`, entity.HashInviteToken(token), time.Now()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInviteUnavailable
+		}
+		logger.Error("Erro ao buscar convite no banco de dados", err)
+		return nil, err
+	}
+	return invite, nil
+}
+
+// Delete revoga um convite pendente da organização, retornando sql.ErrNoRows
+// se não houver
+func (r *OrganizationInviteRepository) Delete(organizationID, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM organization_invites
+		WHERE id = $1 AND organization_id = $2 AND accepted_at IS NULL
+	`, id, organizationID)
+	if err != nil {
+		logger.Error("Erro ao revogar convite", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Accept marca o convite como aceito e cria o usuário na organização com o
+// papel do convite, em uma única transação. Retorna ErrInviteUnavailable se
+// o convite já tiver sido aceito, revogado ou expirado e ErrEmailInUse se o
+// email já estiver cadastrado.
+func (r *OrganizationInviteRepository) Accept(invite *entity.OrganizationInvite, user *entity.User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de aceite do convite", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE organization_invites
+		SET accepted_at = $1
+		WHERE id = $2 AND accepted_at IS NULL AND expires_at > $1
+	`, now, invite.ID)
+	if err != nil {
+		logger.Error("Erro ao aceitar convite", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrInviteUnavailable
+	}
+
+	user.OrganizationID = invite.OrganizationID
+	user.Email = invite.Email
+	user.Role = invite.Role
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO users (organization_id, name, email, password, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (email) DO NOTHING
+		RETURNING id
+	`, user.OrganizationID, user.Name, user.Email, user.Password, user.Role, user.CreatedAt, user.UpdatedAt).Scan(&user.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEmailInUse
+		}
+		logger.Error("Erro ao criar usuário convidado no banco de dados", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar aceite do convite", err)
+		return err
+	}
+	invite.AcceptedAt = &now
+	return nil
+}
+
+func scanOrganizationInvite(row rowScanner) (*entity.OrganizationInvite, error) {
+	invite := &entity.OrganizationInvite{}
+	var invitedBy sql.NullInt64
+	var acceptedAt sql.NullTime
+	err := row.Scan(
+		&invite.ID,
+		&invite.OrganizationID,
+		&invite.Email,
+		&invite.Role,
+		&invite.TokenHash,
+		&invitedBy,
+		&invite.ExpiresAt,
+		&acceptedAt,
+		&invite.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if invitedBy.Valid {
+		invite.InvitedBy = &invitedBy.Int64
+	}
+	if acceptedAt.Valid {
+		invite.AcceptedAt = &acceptedAt.Time
+	}
+	return invite, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// Erros do vínculo do WhatsApp
+var (
+	// ErrPhoneNumberIDInUse indica que o número do WhatsApp já está vinculado a outra organização
+	ErrPhoneNumberIDInUse = errors.New("número do WhatsApp já vinculado a outra organização")
+	// ErrBusinessAccountInUse indica que a conta comercial já está vinculada a outra organização
+	ErrBusinessAccountInUse = errors.New("conta comercial do WhatsApp já vinculada a outra organização")
+)
+
+// OrganizationRepository é responsável pelas operações de banco de dados relacionadas às organizações
+type OrganizationRepository struct {
+	db *sql.DB
+}
+
+// NewOrganizationRepository cria uma nova instância do repositório de organizações
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{
+		db: db,
+	}
+}
+
+const organizationSelectColumns = `id, name, COALESCE(whatsapp_phone_number_id, ''), COALESCE(whatsapp_business_account_id, ''), whatsapp_messaging_tier, created_at`
+
+// GetByID busca uma organização pelo ID
+func (r *OrganizationRepository) GetByID(id int64) (*entity.Organization, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	org, err := scanOrganization(r.db.QueryRowContext(ctx, `SELECT `+organizationSelectColumns+` FROM organizations WHERE id = $1`, id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar organização", err)
+		}
+		return nil, err
+	}
+	return org, nil
+}
+
+// GetByWhatsAppPhoneNumberID busca a organização vinculada ao número do WhatsApp
+func (r *OrganizationRepository) GetByWhatsAppPhoneNumberID(phoneNumberID string) (*entity.Organization, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	org, err := scanOrganization(r.db.QueryRowContext(ctx, `SELECT `+organizationSelectColumns+` FROM organizations WHERE whatsapp_phone_number_id = $1`, phoneNumberID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar organização pelo número do WhatsApp", err)
+		}
+		return nil, err
+	}
+	return org, nil
+}
+
+// SetWhatsAppChannel vincula o número e a conta comercial do WhatsApp à
+// organização (vazios removem o vínculo) e grava o nível de envio do número
+// (vazio mantém o atual), retornando ErrPhoneNumberIDInUse ou
+// ErrBusinessAccountInUse se outra organização já usar o número ou a conta
+func (r *OrganizationRepository) SetWhatsAppChannel(id int64, phoneNumberID, businessAccountID, messagingTier string) (*entity.Organization, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if phoneNumberID != "" {
+		var ownerID int64
+		err := r.db.QueryRowContext(ctx, `SELECT id FROM organizations WHERE whatsapp_phone_number_id = $1`, phoneNumberID).Scan(&ownerID)
+		if err == nil && ownerID != id {
+			return nil, ErrPhoneNumberIDInUse
+		}
+		if err != nil && err != sql.ErrNoRows {
+			logger.Error("Erro ao verificar número do WhatsApp", err)
+			return nil, err
+		}
+	}
+	if businessAccountID != "" {
+		var ownerID int64
+		err := r.db.QueryRowContext(ctx, `SELECT id FROM organizations WHERE whatsapp_business_account_id = $1 AND id <> $2 LIMIT 1`, businessAccountID, id).Scan(&ownerID)
+		if err == nil {
+			return nil, ErrBusinessAccountInUse
+		}
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao verificar conta comercial do WhatsApp", err)
+			return nil, err
+		}
+	}
+
+	org, err := scanOrganization(r.db.QueryRowContext(ctx, `
+		UPDATE organizations
+		SET whatsapp_phone_number_id = NULLIF($1, ''), whatsapp_business_account_id = NULLIF($2, ''),
+			whatsapp_messaging_tier = COALESCE(NULLIF($3, ''), whatsapp_messaging_tier)
+		WHERE id = $4
+		RETURNING `+organizationSelectColumns, phoneNumberID, businessAccountID, messagingTier, id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao vincular número do WhatsApp à organização", err)
+		}
+		return nil, err
+	}
+	return org, nil
+}
+
+func scanOrganization(row rowScanner) (*entity.Organization, error) {
+	org := &entity.Organization{}
+	if err := row.Scan(&org.ID, &org.Name, &org.WhatsAppPhoneNumberID, &org.WhatsAppBusinessAccountID, &org.WhatsAppMessagingTier, &org.CreatedAt); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
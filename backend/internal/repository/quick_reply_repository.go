@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// ErrQuickReplyExists indica um atalho já usado no mesmo escopo
+var ErrQuickReplyExists = errors.New("já existe uma resposta rápida com este atalho")
+
+// QuickReplyRepository é responsável pelas respostas rápidas e seus anexos
+type QuickReplyRepository struct {
+	db *sql.DB
+}
+
+// NewQuickReplyRepository cria uma nova instância do repositório de respostas rápidas
+func NewQuickReplyRepository(db *sql.DB) *QuickReplyRepository {
+	return &QuickReplyRepository{
+		db: db,
+	}
+}
+
+const quickReplySelectColumns = `
+	id, organization_id, user_id, shortcut, title, body, usage_count, last_used_at,
+	created_by, created_at, updated_at`
+
+const quickReplyAttachmentColumns = `
+	id, quick_reply_id, organization_id, storage_key, mime_type, file_name, size, sha256, created_at`
+
+// visibleQuickReply restringe as respostas às da organização e às pessoais
+// do atendente; $1 é a organização e $2 o atendente
+const visibleQuickReply = `organization_id = $1 AND (user_id IS NULL OR user_id = $2)`
+
+// Create grava uma nova resposta rápida, retornando ErrQuickReplyExists se o
+// atalho já existir no mesmo escopo
+func (r *QuickReplyRepository) Create(q *entity.QuickReply) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO quick_replies (organization_id, user_id, shortcut, title, body, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (organization_id, (COALESCE(user_id, 0)), shortcut) DO NOTHING
+		RETURNING id
+	`, q.OrganizationID, q.UserID, q.Shortcut, q.Title, q.Body, q.CreatedBy, q.CreatedAt, q.UpdatedAt).Scan(&q.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrQuickReplyExists
+		}
+		logger.Error("Erro ao criar resposta rápida", err)
+		return err
+	}
+	return nil
+}
+
+// GetByID busca uma resposta rápida da organização ou pessoal do atendente,
+// com os anexos
+func (r *QuickReplyRepository) GetByID(organizationID, userID, id int64) (*entity.QuickReply, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	q, err := scanQuickReply(r.db.QueryRowContext(ctx, `SELECT `+quickReplySelectColumns+`
+		FROM quick_replies WHERE `+visibleQuickReply+` AND id = $3`, organizationID, userID, id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar resposta rápida", err)
+		}
+		return nil, err
+	}
+	if err := r.loadAttachments(ctx, organizationID, []*entity.QuickReply{q}); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// List retorna as respostas da organização e as pessoais do atendente, as
+// mais usadas primeiro
+func (r *QuickReplyRepository) List(organizationID, userID int64, filter entity.QuickReplyFilter) ([]*entity.QuickReply, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	args := sqlArgs{organizationID, userID}
+	conds := []string{visibleQuickReply}
+	switch filter.Scope {
+	case entity.QuickReplyScopeOrganization:
+		conds = append(conds, "user_id IS NULL")
+	case entity.QuickReplyScopePersonal:
+		conds = append(conds, "user_id IS NOT NULL")
+	}
+	if filter.Query != "" {
+		p := args.add(likePattern(filter.Query))
+		conds = append(conds, "(shortcut LIKE "+p+" OR title ILIKE "+p+")")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+quickReplySelectColumns+`
+		FROM quick_replies
+		WHERE `+strings.Join(conds, " AND ")+`
+		ORDER BY usage_count DESC, shortcut, id`, args...)
+	if err != nil {
+		logger.Error("Erro ao listar respostas rápidas", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	replies := []*entity.QuickReply{}
+	for rows.Next() {
+		q, err := scanQuickReply(rows)
+		if err != nil {
+			logger.Error("Erro ao ler resposta rápida", err)
+			return nil, err
+		}
+		replies = append(replies, q)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro ao listar respostas rápidas", err)
+		return nil, err
+	}
+
+	if err := r.loadAttachments(ctx, organizationID, replies); err != nil {
+		return nil, err
+	}
+	return replies, nil
+}
+
+// Update grava o atalho, o título e o texto, retornando ErrQuickReplyExists
+// se outra resposta do mesmo escopo já usar o atalho
+func (r *QuickReplyRepository) Update(q *entity.QuickReply) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	q.UpdatedAt = time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE quick_replies
+		SET shortcut = $1, title = $2, body = $3, updated_at = $4
+		WHERE id = $5 AND organization_id = $6
+		AND NOT EXISTS (
+			SELECT 1 FROM quick_replies
+			WHERE organization_id = $6 AND COALESCE(user_id, 0) = COALESCE($7, 0)
+			AND shortcut = $1 AND id <> $5
+		)
+	`, q.Shortcut, q.Title, q.Body, q.UpdatedAt, q.ID, q.OrganizationID, q.UserID)
+	if err != nil {
+		logger.Error("Erro ao atualizar resposta rápida", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrQuickReplyExists
+	}
+	return nil
+}
+
+// Delete remove a resposta rápida e os registros dos anexos; os arquivos
+// devem ser apagados do armazenamento por quem chama
+func (r *QuickReplyRepository) Delete(organizationID, userID, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM quick_replies WHERE `+visibleQuickReply+` AND id = $3`,
+		organizationID, userID, id)
+	if err != nil {
+		logger.Error("Erro ao remover resposta rápida", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RecordUsage soma um uso à resposta rápida
+func (r *QuickReplyRepository) RecordUsage(organizationID, id int64, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE quick_replies SET usage_count = usage_count + 1, last_used_at = $3
+		WHERE id = $1 AND organization_id = $2
+	`, id, organizationID, at)
+	if err != nil {
+		logger.Error("Erro ao registrar uso da resposta rápida", err)
+	}
+	return err
+}
+
+// AddAttachment grava o anexo se a resposta tiver menos de limit anexos,
+// retornando false caso contrário
+func (r *QuickReplyRepository) AddAttachment(a *entity.QuickReplyAttachment, limit int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO quick_reply_attachments (organization_id, quick_reply_id, storage_key, mime_type, file_name, size, sha256, created_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8
+		WHERE (SELECT COUNT(*) FROM quick_reply_attachments WHERE quick_reply_id = $2) < $9
+		RETURNING id
+	`, a.OrganizationID, a.QuickReplyID, a.StorageKey, a.MIMEType, a.FileName, a.Size, a.SHA256, a.CreatedAt, limit).Scan(&a.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		logger.Error("Erro ao gravar anexo da resposta rápida", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveAttachment apaga o registro do anexo e o retorna, para que o
+// arquivo seja removido do armazenamento
+func (r *QuickReplyRepository) RemoveAttachment(organizationID, quickReplyID, id int64) (*entity.QuickReplyAttachment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	a, err := scanQuickReplyAttachment(r.db.QueryRowContext(ctx, `
+		DELETE FROM quick_reply_attachments
+		WHERE id = $1 AND quick_reply_id = $2 AND organization_id = $3
+		RETURNING `+quickReplyAttachmentColumns, id, quickReplyID, organizationID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao remover anexo da resposta rápida", err)
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+// loadAttachments preenche os anexos das respostas, em ordem de envio
+func (r *QuickReplyRepository) loadAttachments(ctx context.Context, organizationID int64, replies []*entity.QuickReply) error {
+	if len(replies) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*entity.QuickReply, len(replies))
+	args := sqlArgs{organizationID}
+	placeholders := make([]string, 0, len(replies))
+	for _, q := range replies {
+		q.Attachments = []*entity.QuickReplyAttachment{}
+		byID[q.ID] = q
+		placeholders = append(placeholders, args.add(q.ID))
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+quickReplyAttachmentColumns+`
+		FROM quick_reply_attachments
+		WHERE organization_id = $1 AND quick_reply_id IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY quick_reply_id, id`, args...)
+	if err != nil {
+		logger.Error("Erro ao buscar anexos das respostas rápidas", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		a, err := scanQuickReplyAttachment(rows)
+		if err != nil {
+			logger.Error("Erro ao ler anexo da resposta rápida", err)
+			return err
+		}
+		if q := byID[a.QuickReplyID]; q != nil {
+			q.Attachments = append(q.Attachments, a)
+		}
+	}
+	return rows.Err()
+}
+
+func scanQuickReply(row rowScanner) (*entity.QuickReply, error) {
+	q := &entity.QuickReply{Scope: entity.QuickReplyScopeOrganization}
+	var userID, createdBy sql.NullInt64
+	var lastUsedAt sql.NullTime
+	err := row.Scan(&q.ID, &q.OrganizationID, &userID, &q.Shortcut, &q.Title, &q.Body, &q.UsageCount, &lastUsedAt,
+		&createdBy, &q.CreatedAt, &q.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if userID.Valid {
+		q.UserID = &userID.Int64
+		q.Scope = entity.QuickReplyScopePersonal
+	}
+	if lastUsedAt.Valid {
+		q.LastUsedAt = &lastUsedAt.Time
+	}
+	if createdBy.Valid {
+		q.CreatedBy = &createdBy.Int64
+	}
+	return q, nil
+}
+
+func scanQuickReplyAttachment(row rowScanner) (*entity.QuickReplyAttachment, error) {
+	a := &entity.QuickReplyAttachment{}
+	err := row.Scan(&a.ID, &a.QuickReplyID, &a.OrganizationID, &a.StorageKey, &a.MIMEType, &a.FileName, &a.Size,
+		&a.SHA256, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
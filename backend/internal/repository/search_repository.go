@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// SearchRepository executa a busca textual sobre leads, notas e mensagens
+type SearchRepository struct {
+	db *sql.DB
+}
+
+// NewSearchRepository cria uma nova instância do repositório de busca
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{
+		db: db,
+	}
+}
+
+// Delimitadores dos termos destacados por ts_headline. São caracteres de uso
+// privado, trocados por <mark> depois que o trecho é escapado para HTML.
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+// headlineOptions configura os trechos devolvidos pela busca
+const headlineOptions = "StartSel=" + highlightStart + ", StopSel=" + highlightStop +
+	`, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`
+
+// Search procura os termos nos nomes dos leads, nas notas e nas mensagens da
+// organização, pela configuração pt_unaccent (português sem acentos). O texto
+// segue a sintaxe de websearch_to_tsquery: aspas para frases, "or" e "-" para
+// excluir termos. Os resultados são ordenados por relevância e, em empate,
+// pelos mais recentes; hasMore indica se há uma próxima página.
+func (r *SearchRepository) Search(organizationID int64, query entity.SearchQuery, limit, offset int) ([]*entity.SearchHit, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var args sqlArgs
+	text := args.add(query.Text)
+	org := args.add(organizationID)
+
+	var branches []string
+	for _, t := range query.Types {
+		switch t {
+		case entity.SearchTypeLead:
+			branches = append(branches, `
+				SELECT 'lead' AS type, l.id::bigint AS id, l.id AS lead_id, l.name AS lead_name,
+					NULL::integer AS conversation_id, l.name AS body,
+					ts_rank(l.search, q.query, 1) AS rank, l.created_at
+				FROM leads l, q
+				WHERE l.organization_id = `+org+` AND l.search @@ q.query`+searchConditions("l.id", "l.created_at", query, &args))
+		case entity.SearchTypeNote:
+			branches = append(branches, `
+				SELECT 'note', n.id::bigint, n.lead_id, l.name,
+					NULL::integer, n.body,
+					ts_rank(n.search, q.query, 1), n.created_at
+				FROM lead_notes n JOIN leads l ON l.id = n.lead_id, q
+				WHERE n.organization_id = `+org+` AND n.search @@ q.query`+searchConditions("n.lead_id", "n.created_at", query, &args))
+		case entity.SearchTypeMessage:
+			branches = append(branches, `
+				SELECT 'message', m.id, m.lead_id, l.name,
+					m.conversation_id, m.body,
+					ts_rank(m.search, q.query, 1), m.created_at
+				FROM messages m JOIN leads l ON l.id = m.lead_id, q
+				WHERE m.organization_id = `+org+` AND m.search @@ q.query`+searchConditions("m.lead_id", "m.created_at", query, &args))
+		}
+	}
+	if len(branches) == 0 {
+		return []*entity.SearchHit{}, false, nil
+	}
+
+	// O trecho destacado é gerado apenas para a página, pois ts_headline
+	// precisa reprocessar o texto completo
+	sqlQuery := `
+		WITH q AS (SELECT websearch_to_tsquery('pt_unaccent', ` + text + `) AS query)
+		SELECT h.type, h.id, h.lead_id, h.lead_name, h.conversation_id,
+			ts_headline('pt_unaccent', h.body, q.query, ` + args.add(headlineOptions) + `),
+			h.rank, h.created_at
+		FROM (
+			SELECT * FROM (` + strings.Join(branches, " UNION ALL ") + `) hits
+			ORDER BY rank DESC, created_at DESC, id DESC
+			LIMIT ` + args.add(limit+1) + ` OFFSET ` + args.add(offset) + `
+		) h, q
+		ORDER BY h.rank DESC, h.created_at DESC, h.id DESC`
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		logger.Error("Erro ao executar busca textual", err)
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	hits := []*entity.SearchHit{}
+	for rows.Next() {
+		hit := &entity.SearchHit{}
+		var conversationID sql.NullInt64
+		var snippet string
+		if err := rows.Scan(&hit.Type, &hit.ID, &hit.LeadID, &hit.LeadName, &conversationID, &snippet, &hit.Rank, &hit.CreatedAt); err != nil {
+			logger.Error("Erro ao ler resultado da busca", err)
+			return nil, false, err
+		}
+		if conversationID.Valid {
+			hit.ConversationID = &conversationID.Int64
+		}
+		hit.Snippet = highlightSnippet(snippet)
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro ao ler resultados da busca", err)
+		return nil, false, err
+	}
+
+	if len(hits) > limit {
+		return hits[:limit], true, nil
+	}
+	return hits, false, nil
+}
+
+// searchConditions aplica os filtros opcionais de lead e de período a um dos
+// tipos pesquisados
+func searchConditions(leadColumn, createdColumn string, query entity.SearchQuery, args *sqlArgs) string {
+	var conds string
+	if query.LeadID != nil {
+		conds += " AND " + leadColumn + " = " + args.add(*query.LeadID)
+	}
+	if query.From != nil {
+		conds += " AND " + createdColumn + " >= " + args.add(*query.From)
+	}
+	if query.To != nil {
+		conds += " AND " + createdColumn + " < " + args.add(*query.To)
+	}
+	return conds
+}
+
+// highlightSnippet escapa o trecho para HTML e troca os delimitadores dos
+// termos encontrados por <mark>
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	return strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>").Replace(escaped)
+}
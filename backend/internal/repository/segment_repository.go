@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// ErrSegmentExists indica que a organização já possui um segmento com o mesmo nome
+var ErrSegmentExists = errors.New("já existe um segmento com este nome")
+
+// SegmentRepository é responsável pelas operações de banco de dados relacionadas aos segmentos
+type SegmentRepository struct {
+	db *sql.DB
+}
+
+// NewSegmentRepository cria uma nova instância do repositório de segmentos
+func NewSegmentRepository(db *sql.DB) *SegmentRepository {
+	return &SegmentRepository{
+		db: db,
+	}
+}
+
+// segmentColumns lista as colunas lidas em todas as consultas
+const segmentColumns = `
+	id, organization_id, COALESCE(user_id, 0), name, description, filters, created_at, updated_at
+`
+
+// Create grava um novo segmento, retornando ErrSegmentExists se o nome já existir
+func (r *SegmentRepository) Create(segment *entity.Segment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filters, err := json.Marshal(segment.Filter)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO segments (organization_id, user_id, name, description, filters, created_at, updated_at)
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7)
+		ON CONFLICT (organization_id, (LOWER(name))) DO NOTHING
+		RETURNING id
+	`,
+		segment.OrganizationID,
+		segment.UserID,
+		segment.Name,
+		segment.Description,
+		filters,
+		segment.CreatedAt,
+		segment.UpdatedAt,
+	).Scan(&segment.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrSegmentExists
+		}
+		logger.Error("Erro ao criar segmento no banco de dados", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetByID busca um segmento da organização pelo ID
+func (r *SegmentRepository) GetByID(organizationID, id int64) (*entity.Segment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + segmentColumns + ` FROM segments WHERE id = $1 AND organization_id = $2`
+
+	segment, err := scanSegment(r.db.QueryRowContext(ctx, query, id, organizationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.Error("Erro ao buscar segmento no banco de dados", err)
+		return nil, err
+	}
+
+	return segment, nil
+}
+
+// ListByOrganization lista os segmentos da organização em ordem alfabética
+func (r *SegmentRepository) ListByOrganization(organizationID int64) ([]*entity.Segment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + segmentColumns + ` FROM segments
+		WHERE organization_id = $1
+		ORDER BY LOWER(name), id`
+
+	rows, err := r.db.QueryContext(ctx, query, organizationID)
+	if err != nil {
+		logger.Error("Erro ao listar segmentos no banco de dados", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	segments := []*entity.Segment{}
+	for rows.Next() {
+		segment, err := scanSegment(rows)
+		if err != nil {
+			logger.Error("Erro ao ler segmento", err)
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments, rows.Err()
+}
+
+// Update grava o nome, a descrição e o filtro, retornando ErrSegmentExists
+// se outro segmento já usar o nome
+func (r *SegmentRepository) Update(segment *entity.Segment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filters, err := json.Marshal(segment.Filter)
+	if err != nil {
+		return err
+	}
+
+	segment.UpdatedAt = time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE segments
+		SET name = $1, description = $2, filters = $3, updated_at = $4
+		WHERE id = $5 AND organization_id = $6
+		AND NOT EXISTS (
+			SELECT 1 FROM segments
+			WHERE organization_id = $6 AND LOWER(name) = LOWER($1) AND id <> $5
+		)
+	`, segment.Name, segment.Description, filters, segment.UpdatedAt, segment.ID, segment.OrganizationID)
+	if err != nil {
+		logger.Error("Erro ao atualizar segmento no banco de dados", err)
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrSegmentExists
+	}
+	return nil
+}
+
+// Delete remove o segmento
+func (r *SegmentRepository) Delete(organizationID, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM segments WHERE id = $1 AND organization_id = $2`, id, organizationID)
+	if err != nil {
+		logger.Error("Erro ao remover segmento no banco de dados", err)
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// scanSegment lê as colunas de segmentColumns
+func scanSegment(row rowScanner) (*entity.Segment, error) {
+	segment := &entity.Segment{}
+	var filters []byte
+
+	err := row.Scan(
+		&segment.ID,
+		&segment.OrganizationID,
+		&segment.UserID,
+		&segment.Name,
+		&segment.Description,
+		&filters,
+		&segment.CreatedAt,
+		&segment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(filters, &segment.Filter); err != nil {
+		return nil, err
+	}
+	return segment, nil
+}
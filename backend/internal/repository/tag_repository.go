@@ -0,0 +1,408 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// ErrTagExists indica que a organização já possui uma etiqueta com o mesmo nome
+var ErrTagExists = errors.New("já existe uma etiqueta com este nome")
+
+// TagRepository é responsável pelas operações de banco de dados relacionadas às etiquetas
+type TagRepository struct {
+	db *sql.DB
+}
+
+// NewTagRepository cria uma nova instância do repositório de etiquetas
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{
+		db: db,
+	}
+}
+
+// TagChanges resume uma aplicação de etiquetas em massa
+type TagChanges struct {
+	Matched int64 `json:"matched"`
+	Added   int64 `json:"added"`
+	Removed int64 `json:"removed"`
+}
+
+// tagSelectColumns lista as colunas lidas em todas as consultas, incluindo o
+// número de leads com a etiqueta
+const tagSelectColumns = `
+	t.id, t.organization_id, t.name, t.created_at,
+	(SELECT COUNT(*) FROM lead_tags lt WHERE lt.tag_id = t.id)
+`
+
+// Create grava uma nova etiqueta, retornando ErrTagExists se o nome já existir
+func (r *TagRepository) Create(tag *entity.Tag) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO tags (organization_id, name, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_id, (LOWER(name))) DO NOTHING
+		RETURNING id
+	`, tag.OrganizationID, tag.Name, tag.CreatedAt).Scan(&tag.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrTagExists
+		}
+		logger.Error("Erro ao criar etiqueta no banco de dados", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetByID busca uma etiqueta da organização pelo ID
+func (r *TagRepository) GetByID(organizationID, id int64) (*entity.Tag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + tagSelectColumns + ` FROM tags t WHERE t.id = $1 AND t.organization_id = $2`
+
+	tag, err := scanTag(r.db.QueryRowContext(ctx, query, id, organizationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.Error("Erro ao buscar etiqueta no banco de dados", err)
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// ListByOrganization lista as etiquetas da organização em ordem alfabética
+func (r *TagRepository) ListByOrganization(organizationID int64) ([]*entity.Tag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + tagSelectColumns + ` FROM tags t
+		WHERE t.organization_id = $1
+		ORDER BY LOWER(t.name), t.id`
+
+	rows, err := r.db.QueryContext(ctx, query, organizationID)
+	if err != nil {
+		logger.Error("Erro ao listar etiquetas no banco de dados", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*entity.Tag{}
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			logger.Error("Erro ao ler etiqueta", err)
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// Rename altera o nome da etiqueta, retornando ErrTagExists se outro já
+// usar o novo nome. Os segmentos que filtram pelo nome antigo são
+// atualizados na mesma transação, continuando a selecionar os mesmos leads.
+func (r *TagRepository) Rename(tag *entity.Tag, name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de renomeação de etiqueta", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM tags WHERE organization_id = $1 AND LOWER(name) = LOWER($2) AND id <> $3)
+	`, tag.OrganizationID, name, tag.ID).Scan(&exists); err != nil {
+		logger.Error("Erro ao verificar nome da etiqueta", err)
+		return err
+	}
+	if exists {
+		return ErrTagExists
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE tags SET name = $1 WHERE id = $2 AND organization_id = $3`,
+		name, tag.ID, tag.OrganizationID); err != nil {
+		logger.Error("Erro ao renomear etiqueta no banco de dados", err)
+		return err
+	}
+
+	if err := renameSegmentTags(ctx, tx, tag.OrganizationID, tag.Name, name); err != nil {
+		logger.Error("Erro ao atualizar segmentos da etiqueta renomeada", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar renomeação de etiqueta", err)
+		return err
+	}
+
+	tag.Name = name
+	return nil
+}
+
+// Delete remove a etiqueta e suas associações com leads. Segmentos que a
+// referenciam são mantidos; o nome deixa de selecionar leads.
+func (r *TagRepository) Delete(organizationID, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tags WHERE id = $1 AND organization_id = $2`, id, organizationID)
+	if err != nil {
+		logger.Error("Erro ao remover etiqueta no banco de dados", err)
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetLeadTags substitui as etiquetas do lead pelas informadas, criando as
+// que ainda não existem na organização
+func (r *TagRepository) SetLeadTags(organizationID, leadID int64, names []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de etiquetas do lead", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	tagIDs, err := ensureTags(ctx, tx, organizationID, names)
+	if err != nil {
+		logger.Error("Erro ao criar etiquetas no banco de dados", err)
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM lead_tags WHERE lead_id = $1 AND NOT (tag_id = ANY($2))`,
+		leadID, tagIDs); err != nil {
+		logger.Error("Erro ao remover etiquetas do lead", err)
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO lead_tags (lead_id, tag_id, created_at)
+		SELECT $1, unnest($2::integer[]), NOW()
+		ON CONFLICT DO NOTHING
+	`, leadID, tagIDs); err != nil {
+		logger.Error("Erro ao adicionar etiquetas ao lead", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar etiquetas do lead", err)
+		return err
+	}
+	return nil
+}
+
+// AddLeadTags adiciona as etiquetas ao lead, criando as que ainda não
+// existem na organização e mantendo as demais
+func (r *TagRepository) AddLeadTags(organizationID, leadID int64, names []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de etiquetas do lead", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	tagIDs, err := ensureTags(ctx, tx, organizationID, names)
+	if err != nil {
+		logger.Error("Erro ao criar etiquetas no banco de dados", err)
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO lead_tags (lead_id, tag_id, created_at)
+		SELECT l.id, t.id, NOW() FROM leads l CROSS JOIN unnest($3::integer[]) AS t(id)
+		WHERE l.id = $1 AND l.organization_id = $2
+		ON CONFLICT DO NOTHING
+	`, leadID, organizationID, tagIDs); err != nil {
+		logger.Error("Erro ao adicionar etiquetas ao lead", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar etiquetas do lead", err)
+		return err
+	}
+	return nil
+}
+
+// Apply adiciona e remove etiquetas dos leads da organização que atendem ao
+// filtro, restritos a leadIDs quando informados. As etiquetas adicionadas
+// são criadas se necessário; as removidas que não existem são ignoradas. A
+// seleção é avaliada uma única vez, de modo que adicionar ou remover
+// etiquetas usadas pelo próprio filtro não altera os leads afetados.
+func (r *TagRepository) Apply(organizationID int64, filter entity.LeadFilter, leadIDs []int64, add, remove []string) (*TagChanges, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de etiquetas em massa", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	addIDs, err := ensureTags(ctx, tx, organizationID, add)
+	if err != nil {
+		logger.Error("Erro ao criar etiquetas no banco de dados", err)
+		return nil, err
+	}
+
+	var args sqlArgs
+	where := leadWhere(organizationID, filter, &args)
+	if leadIDs != nil {
+		where += " AND l.id = ANY(" + args.add(leadIDs) + ")"
+	}
+	org := args.add(organizationID)
+
+	// Comandos de uma mesma instrução compartilham o snapshot, então
+	// selected não enxerga as alterações de added e removed
+	query := `
+		WITH selected AS (
+			SELECT l.id FROM leads l WHERE ` + where + `
+		), added AS (
+			INSERT INTO lead_tags (lead_id, tag_id, created_at)
+			SELECT s.id, t.id, NOW() FROM selected s CROSS JOIN unnest(` + args.add(addIDs) + `::integer[]) AS t(id)
+			ON CONFLICT DO NOTHING
+			RETURNING 1
+		), removed AS (
+			DELETE FROM lead_tags lt
+			WHERE lt.lead_id IN (SELECT id FROM selected)
+			AND lt.tag_id IN (SELECT id FROM tags WHERE organization_id = ` + org + ` AND LOWER(name) = ANY(` + args.add(lowerTags(remove)) + `))
+			RETURNING 1
+		)
+		SELECT (SELECT COUNT(*) FROM selected), (SELECT COUNT(*) FROM added), (SELECT COUNT(*) FROM removed)
+	`
+
+	changes := &TagChanges{}
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&changes.Matched, &changes.Added, &changes.Removed); err != nil {
+		logger.Error("Erro ao aplicar etiquetas em massa", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar etiquetas em massa", err)
+		return nil, err
+	}
+	return changes, nil
+}
+
+// ensureTags cria as etiquetas que ainda não existem e retorna os IDs de
+// todas. Nomes que diferem apenas em maiúsculas resultam na mesma etiqueta.
+func ensureTags(ctx context.Context, tx *sql.Tx, organizationID int64, names []string) ([]int64, error) {
+	ids := []int64{}
+	if len(names) == 0 {
+		return ids, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO tags (organization_id, name, created_at)
+		SELECT $1, n, NOW() FROM unnest($2::text[]) AS n
+		ON CONFLICT (organization_id, (LOWER(name))) DO NOTHING
+	`, organizationID, names); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM tags WHERE organization_id = $1 AND LOWER(name) = ANY($2)`,
+		organizationID, lowerTags(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// renameSegmentTags troca o nome da etiqueta nos filtros dos segmentos da organização
+func renameSegmentTags(ctx context.Context, tx *sql.Tx, organizationID int64, oldName, newName string) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, filters FROM segments WHERE organization_id = $1 FOR UPDATE`, organizationID)
+	if err != nil {
+		return err
+	}
+
+	changed := make(map[int64][]byte)
+	for rows.Next() {
+		var id int64
+		var raw []byte
+		if err := rows.Scan(&id, &raw); err != nil {
+			rows.Close()
+			return err
+		}
+		var filter entity.LeadFilter
+		if err := json.Unmarshal(raw, &filter); err != nil {
+			rows.Close()
+			return err
+		}
+		if replaceTagName(filter.Tags, oldName, newName) || replaceTagName(filter.ExcludeTags, oldName, newName) {
+			if raw, err = json.Marshal(filter); err != nil {
+				rows.Close()
+				return err
+			}
+			changed[id] = raw
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, raw := range changed {
+		if _, err := tx.ExecContext(ctx, `UPDATE segments SET filters = $1 WHERE id = $2`, raw, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceTagName troca, sem distinção de maiúsculas, oldName por newName na lista
+func replaceTagName(names []string, oldName, newName string) bool {
+	replaced := false
+	for i, name := range names {
+		if strings.EqualFold(name, oldName) {
+			names[i] = newName
+			replaced = true
+		}
+	}
+	return replaced
+}
+
+// scanTag lê as colunas de tagSelectColumns
+func scanTag(row rowScanner) (*entity.Tag, error) {
+	tag := &entity.Tag{}
+	if err := row.Scan(&tag.ID, &tag.OrganizationID, &tag.Name, &tag.CreatedAt, &tag.LeadCount); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// TaskRepository é responsável pelas operações de banco de dados relacionadas às tarefas
+type TaskRepository struct {
+	db *sql.DB
+}
+
+// NewTaskRepository cria uma nova instância do repositório de tarefas
+func NewTaskRepository(db *sql.DB) *TaskRepository {
+	return &TaskRepository{
+		db: db,
+	}
+}
+
+// taskSelectColumns lista as colunas lidas nas consultas de tarefas, que
+// trazem também o nome do lead
+const taskSelectColumns = `
+	t.id, t.organization_id, t.lead_id, l.name, COALESCE(t.created_by, 0), t.assignee_id,
+	t.title, t.description, t.status, t.due_at, t.remind_at, t.reminder_sent_at,
+	t.completed_at, t.created_at, t.updated_at
+`
+
+// Create grava a tarefa, a atividade no histórico do lead e, se informada, a
+// notificação de atribuição ao responsável, que recebe o ID da tarefa em data.task_id
+func (r *TaskRepository) Create(task *entity.Task, notification *entity.Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de criação de tarefa", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO tasks (organization_id, lead_id, created_by, assignee_id, title, description,
+			status, due_at, remind_at, completed_at, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`, task.OrganizationID, task.LeadID, task.CreatedBy, task.AssigneeID, task.Title, task.Description,
+		task.Status, task.DueAt, task.RemindAt, task.CompletedAt, task.CreatedAt, task.UpdatedAt,
+	).Scan(&task.ID); err != nil {
+		logger.Error("Erro ao criar tarefa no banco de dados", err)
+		return err
+	}
+
+	if err := notifyTask(ctx, tx, task, notification); err != nil {
+		return err
+	}
+
+	activity := entity.NewActivity(task.OrganizationID, task.LeadID, task.CreatedBy, entity.ActivityTaskCreated)
+	activity.Data["task_id"] = task.ID
+	activity.Data["title"] = task.Title
+	activity.Data["due_at"] = task.DueAt
+	activity.Data["assignee_id"] = task.AssigneeID
+	if err := insertActivity(ctx, tx, activity); err != nil {
+		logger.Error("Erro ao registrar tarefa no histórico do lead", err)
+		return err
+	}
+	if task.Status == entity.TaskStatusDone {
+		if err := insertActivity(ctx, tx, taskCompletedActivity(task, task.CreatedBy)); err != nil {
+			logger.Error("Erro ao registrar conclusão de tarefa no histórico do lead", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar criação de tarefa", err)
+		return err
+	}
+	return nil
+}
+
+// GetByID busca uma tarefa da organização pelo ID
+func (r *TaskRepository) GetByID(organizationID, id int64) (*entity.Task, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + taskSelectColumns + ` FROM tasks t JOIN leads l ON l.id = t.lead_id
+		WHERE t.id = $1 AND t.organization_id = $2`
+
+	task, err := scanTask(r.db.QueryRowContext(ctx, query, id, organizationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.Error("Erro ao buscar tarefa no banco de dados", err)
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// ListByLead retorna as tarefas do lead: primeiro as abertas, pelo prazo, e
+// depois as concluídas, das mais recentes para as mais antigas
+func (r *TaskRepository) ListByLead(organizationID, leadID int64) ([]*entity.Task, error) {
+	query := `SELECT ` + taskSelectColumns + ` FROM tasks t JOIN leads l ON l.id = t.lead_id
+		WHERE t.lead_id = $1 AND t.organization_id = $2
+		ORDER BY t.status = 'done', CASE WHEN t.status = 'open' THEN t.due_at END, t.completed_at DESC, t.id`
+
+	return r.list(query, leadID, organizationID)
+}
+
+// ListForAssignee retorna as tarefas abertas do responsável com prazo no
+// intervalo [from, to), em ordem de prazo. Limites nulos não restringem.
+func (r *TaskRepository) ListForAssignee(organizationID, assigneeID int64, from, to *time.Time) ([]*entity.Task, error) {
+	query := `SELECT ` + taskSelectColumns + ` FROM tasks t JOIN leads l ON l.id = t.lead_id
+		WHERE t.assignee_id = $1 AND t.organization_id = $2 AND t.status = 'open'
+			AND ($3::timestamp IS NULL OR t.due_at >= $3)
+			AND ($4::timestamp IS NULL OR t.due_at < $4)
+		ORDER BY t.due_at, t.id`
+
+	return r.list(query, assigneeID, organizationID, from, to)
+}
+
+// Update grava as alterações da tarefa e, se informada, a notificação ao novo
+// responsável. Quando o horário do lembrete muda, ele volta a ser enviado, e
+// a conclusão por userID é registrada no histórico do lead.
+func (r *TaskRepository) Update(task *entity.Task, userID int64, notification *entity.Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de atualização de tarefa", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var previousStatus string
+	if err := tx.QueryRowContext(ctx, `
+		SELECT status FROM tasks WHERE id = $1 AND organization_id = $2 FOR UPDATE
+	`, task.ID, task.OrganizationID).Scan(&previousStatus); err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao bloquear tarefa para atualização", err)
+		}
+		return err
+	}
+
+	task.UpdatedAt = time.Now()
+	var reminderSentAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		UPDATE tasks SET
+			assignee_id = $1, title = $2, description = $3, status = $4, due_at = $5,
+			reminder_sent_at = CASE WHEN remind_at = $6 THEN reminder_sent_at END,
+			remind_at = $6, completed_at = $7, updated_at = $8
+		WHERE id = $9 AND organization_id = $10
+		RETURNING reminder_sent_at
+	`, task.AssigneeID, task.Title, task.Description, task.Status, task.DueAt,
+		task.RemindAt, task.CompletedAt, task.UpdatedAt, task.ID, task.OrganizationID,
+	).Scan(&reminderSentAt)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao atualizar tarefa no banco de dados", err)
+		}
+		return err
+	}
+	task.ReminderSentAt = nil
+	if reminderSentAt.Valid {
+		task.ReminderSentAt = &reminderSentAt.Time
+	}
+
+	if err := notifyTask(ctx, tx, task, notification); err != nil {
+		return err
+	}
+
+	if task.Status == entity.TaskStatusDone && previousStatus != entity.TaskStatusDone {
+		if err := insertActivity(ctx, tx, taskCompletedActivity(task, userID)); err != nil {
+			logger.Error("Erro ao registrar conclusão de tarefa no histórico do lead", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar atualização de tarefa", err)
+		return err
+	}
+	return nil
+}
+
+// Delete remove a tarefa
+func (r *TaskRepository) Delete(organizationID, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tasks WHERE id = $1 AND organization_id = $2`, id, organizationID)
+	if err != nil {
+		logger.Error("Erro ao remover tarefa no banco de dados", err)
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// FireDueReminders marca como enviados até limit lembretes vencidos e cria,
+// no mesmo comando, as notificações ao responsável (ou, sem responsável, a
+// quem criou a tarefa). As linhas são travadas com SKIP LOCKED, de modo que
+// várias instâncias podem rodar o agendador sem duplicar lembretes.
+func (r *TaskRepository) FireDueReminders(limit int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		WITH due AS (
+			SELECT id FROM tasks
+			WHERE status = 'open' AND reminder_sent_at IS NULL AND remind_at <= NOW()
+			ORDER BY remind_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), fired AS (
+			UPDATE tasks t SET reminder_sent_at = NOW()
+			FROM due
+			WHERE t.id = due.id
+			RETURNING t.id, t.organization_id, t.lead_id, COALESCE(t.assignee_id, t.created_by) AS user_id,
+				t.title, t.due_at
+		)
+		INSERT INTO notifications (organization_id, user_id, type, title, body, data, created_at)
+		SELECT f.organization_id, f.user_id, $2::text, $3::text, f.title,
+			jsonb_build_object('task_id', f.id, 'lead_id', f.lead_id, 'lead_name', l.name, 'due_at', f.due_at),
+			NOW()
+		FROM fired f JOIN leads l ON l.id = f.lead_id
+		WHERE f.user_id IS NOT NULL
+	`, limit, entity.NotificationTaskDue, "Lembrete de tarefa")
+	if err != nil {
+		logger.Error("Erro ao disparar lembretes de tarefas", err)
+		return 0, err
+	}
+
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// list executa uma consulta de tarefas montada sobre taskSelectColumns
+func (r *TaskRepository) list(query string, args ...interface{}) ([]*entity.Task, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error("Erro ao listar tarefas no banco de dados", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*entity.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			logger.Error("Erro ao ler tarefa", err)
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+// notifyTask grava a notificação de atribuição da tarefa, se houver
+func notifyTask(ctx context.Context, tx *sql.Tx, task *entity.Task, notification *entity.Notification) error {
+	if notification == nil {
+		return nil
+	}
+
+	notification.Data["task_id"] = task.ID
+	if err := insertNotification(ctx, tx, notification); err != nil {
+		logger.Error("Erro ao notificar atribuição de tarefa", err)
+		return err
+	}
+	return nil
+}
+
+// taskCompletedActivity descreve a conclusão da tarefa no histórico do lead
+func taskCompletedActivity(task *entity.Task, userID int64) *entity.Activity {
+	activity := entity.NewActivity(task.OrganizationID, task.LeadID, userID, entity.ActivityTaskCompleted)
+	activity.Data["task_id"] = task.ID
+	activity.Data["title"] = task.Title
+	return activity
+}
+
+// scanTask lê as colunas de taskSelectColumns
+func scanTask(row rowScanner) (*entity.Task, error) {
+	task := &entity.Task{}
+	var assigneeID sql.NullInt64
+	var reminderSentAt, completedAt sql.NullTime
+
+	err := row.Scan(
+		&task.ID,
+		&task.OrganizationID,
+		&task.LeadID,
+		&task.LeadName,
+		&task.CreatedBy,
+		&assigneeID,
+		&task.Title,
+		&task.Description,
+		&task.Status,
+		&task.DueAt,
+		&task.RemindAt,
+		&reminderSentAt,
+		&completedAt,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if assigneeID.Valid {
+		task.AssigneeID = &assigneeID.Int64
+	}
+	if reminderSentAt.Valid {
+		task.ReminderSentAt = &reminderSentAt.Time
+	}
+	if completedAt.Valid {
+		task.CompletedAt = &completedAt.Time
+	}
+	return task, nil
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// ErrTemplateExists indica que a organização já possui um modelo com o mesmo nome e idioma
+var ErrTemplateExists = errors.New("já existe um modelo com este nome e idioma")
+
+// TemplateRepository é responsável pelos modelos de mensagem do WhatsApp
+type TemplateRepository struct {
+	db *sql.DB
+}
+
+// NewTemplateRepository cria uma nova instância do repositório de modelos
+func NewTemplateRepository(db *sql.DB) *TemplateRepository {
+	return &TemplateRepository{
+		db: db,
+	}
+}
+
+const templateSelectColumns = `
+	id, organization_id, name, language, category, header, header_variables, body, body_variables,
+	footer, buttons, status, external_id, rejection_reason, created_by, submitted_at, synced_at,
+	created_at, updated_at`
+
+// Create grava um novo modelo, retornando ErrTemplateExists se o nome e o
+// idioma já existirem na organização
+func (r *TemplateRepository) Create(t *entity.MessageTemplate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	content, err := marshalTemplateContent(t)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO message_templates (organization_id, name, language, category, header, header_variables,
+			body, body_variables, footer, buttons, status, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (organization_id, name, language) DO NOTHING
+		RETURNING id
+	`, t.OrganizationID, t.Name, t.Language, t.Category, t.Header, content.headerVariables,
+		t.Body, content.bodyVariables, t.Footer, content.buttons, t.Status, t.CreatedBy, t.CreatedAt, t.UpdatedAt).Scan(&t.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrTemplateExists
+		}
+		logger.Error("Erro ao criar modelo de mensagem", err)
+		return err
+	}
+	return nil
+}
+
+// GetByID busca um modelo da organização pelo ID
+func (r *TemplateRepository) GetByID(organizationID, id int64) (*entity.MessageTemplate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t, err := scanTemplate(r.db.QueryRowContext(ctx, `SELECT `+templateSelectColumns+`
+		FROM message_templates WHERE id = $1 AND organization_id = $2`, id, organizationID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Erro ao buscar modelo de mensagem", err)
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// List retorna uma página dos modelos da organização em ordem de nome,
+// opcionalmente filtrados pelo status, junto com o total
+func (r *TemplateRepository) List(organizationID int64, status string, limit, offset int) ([]*entity.MessageTemplate, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var args sqlArgs
+	where := `organization_id = ` + args.add(organizationID)
+	if status != "" {
+		where += ` AND status = ` + args.add(status)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM message_templates WHERE `+where, args...).Scan(&total); err != nil {
+		logger.Error("Erro ao contar modelos de mensagem", err)
+		return nil, 0, err
+	}
+
+	templates, err := r.query(ctx, `SELECT `+templateSelectColumns+` FROM message_templates WHERE `+where+`
+		ORDER BY name, language LIMIT `+args.add(limit)+` OFFSET `+args.add(offset), args...)
+	if err != nil {
+		logger.Error("Erro ao listar modelos de mensagem", err)
+		return nil, 0, err
+	}
+	return templates, total, nil
+}
+
+// ListSubmitted retorna os modelos da organização já enviados ao provedor
+func (r *TemplateRepository) ListSubmitted(organizationID int64) ([]*entity.MessageTemplate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	templates, err := r.query(ctx, `SELECT `+templateSelectColumns+` FROM message_templates
+		WHERE organization_id = $1 AND status <> $2
+		ORDER BY id`, organizationID, entity.TemplateStatusDraft)
+	if err != nil {
+		logger.Error("Erro ao listar modelos enviados", err)
+		return nil, err
+	}
+	return templates, nil
+}
+
+// PendingOrganizations retorna as organizações com modelos aguardando aprovação
+func (r *TemplateRepository) PendingOrganizations() ([]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT organization_id FROM message_templates WHERE status = $1`, entity.TemplateStatusPending)
+	if err != nil {
+		logger.Error("Erro ao listar organizações com modelos pendentes", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Update grava o conteúdo e o status do modelo, retornando ErrTemplateExists
+// se o novo nome e idioma já pertencerem a outro modelo da organização
+func (r *TemplateRepository) Update(t *entity.MessageTemplate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	content, err := marshalTemplateContent(t)
+	if err != nil {
+		return err
+	}
+
+	var exists bool
+	err = r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM message_templates
+			WHERE organization_id = $1 AND name = $2 AND language = $3 AND id <> $4)
+	`, t.OrganizationID, t.Name, t.Language, t.ID).Scan(&exists)
+	if err != nil {
+		logger.Error("Erro ao verificar nome do modelo de mensagem", err)
+		return err
+	}
+	if exists {
+		return ErrTemplateExists
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE message_templates
+		SET name = $1, language = $2, category = $3, header = $4, header_variables = $5, body = $6,
+			body_variables = $7, footer = $8, buttons = $9, status = $10, external_id = $11,
+			rejection_reason = $12, submitted_at = $13, synced_at = $14, updated_at = $15
+		WHERE id = $16 AND organization_id = $17
+	`, t.Name, t.Language, t.Category, t.Header, content.headerVariables, t.Body, content.bodyVariables,
+		t.Footer, content.buttons, t.Status, t.ExternalID, t.RejectionReason, t.SubmittedAt, t.SyncedAt,
+		t.UpdatedAt, t.ID, t.OrganizationID)
+	if err != nil {
+		logger.Error("Erro ao atualizar modelo de mensagem", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Delete remove um modelo da organização
+func (r *TemplateRepository) Delete(organizationID, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM message_templates WHERE id = $1 AND organization_id = $2`, id, organizationID)
+	if err != nil {
+		logger.Error("Erro ao remover modelo de mensagem", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *TemplateRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entity.MessageTemplate, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []*entity.MessageTemplate{}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// templateContent guarda as partes do modelo gravadas em JSONB
+type templateContent struct {
+	headerVariables, bodyVariables, buttons []byte
+}
+
+func marshalTemplateContent(t *entity.MessageTemplate) (*templateContent, error) {
+	var c templateContent
+	var err error
+	if c.headerVariables, err = json.Marshal(t.HeaderVariables); err != nil {
+		return nil, err
+	}
+	if c.bodyVariables, err = json.Marshal(t.BodyVariables); err != nil {
+		return nil, err
+	}
+	if c.buttons, err = json.Marshal(t.Buttons); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func scanTemplate(row rowScanner) (*entity.MessageTemplate, error) {
+	t := &entity.MessageTemplate{}
+	var c templateContent
+	var createdBy sql.NullInt64
+	err := row.Scan(&t.ID, &t.OrganizationID, &t.Name, &t.Language, &t.Category, &t.Header, &c.headerVariables,
+		&t.Body, &c.bodyVariables, &t.Footer, &c.buttons, &t.Status, &t.ExternalID, &t.RejectionReason,
+		&createdBy, &t.SubmittedAt, &t.SyncedAt, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if createdBy.Valid {
+		t.CreatedBy = &createdBy.Int64
+	}
+	if err := json.Unmarshal(c.headerVariables, &t.HeaderVariables); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(c.bodyVariables, &t.BodyVariables); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(c.buttons, &t.Buttons); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -3,12 +3,17 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/whatsapp/backend/internal/logger"
 	"github.com/whatsapp/backend/internal/models/entity"
 )
 
+// ErrLastAdmin indica a tentativa de remover o papel do único administrador
+// da organização
+var ErrLastAdmin = errors.New("a organização precisa de ao menos um administrador")
+
 // UserRepository é responsável pelas operações de banco de dados relacionadas aos usuários
 type UserRepository struct {
 	db *sql.DB
@@ -21,23 +26,27 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	}
 }
 
+const userSelectColumns = `id, COALESCE(organization_id, 0), name, email, password, role, created_at, updated_at`
+
 // Create insere um novo usuário no banco de dados
 func (r *UserRepository) Create(user *entity.User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	query := `
-		INSERT INTO users (name, email, password, created_at, updated_at) 
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO users (organization_id, name, email, password, role, created_at, updated_at) 
+		VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6, $7)
 		RETURNING id
 	`
 
 	err := r.db.QueryRowContext(
 		ctx,
 		query,
+		user.OrganizationID,
 		user.Name,
 		user.Email,
 		user.Password,
+		user.Role,
 		user.CreatedAt,
 		user.UpdatedAt,
 	).Scan(&user.ID)
@@ -50,27 +59,61 @@ func (r *UserRepository) Create(user *entity.User) error {
 	return nil
 }
 
+// CreateWithOrganization cria a organização e o usuário, seu administrador,
+// em uma única transação
+func (r *UserRepository) CreateWithOrganization(user *entity.User, org *entity.Organization) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de criação de usuário", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO organizations (name, created_at)
+		VALUES ($1, $2)
+		RETURNING id
+	`, org.Name, org.CreatedAt).Scan(&org.ID)
+	if err != nil {
+		logger.Error("Erro ao criar organização no banco de dados", err)
+		return err
+	}
+
+	user.OrganizationID = org.ID
+	user.Role = entity.UserRoleAdmin
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO users (organization_id, name, email, password, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, user.OrganizationID, user.Name, user.Email, user.Password, user.Role, user.CreatedAt, user.UpdatedAt).Scan(&user.ID)
+	if err != nil {
+		logger.Error("Erro ao criar usuário no banco de dados", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar transação de criação de usuário", err)
+		return err
+	}
+
+	return nil
+}
+
 // GetByID busca um usuário pelo ID
 func (r *UserRepository) GetByID(id int64) (*entity.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	query := `
-		SELECT id, name, email, password, created_at, updated_at
+		SELECT ` + userSelectColumns + `
 		FROM users
 		WHERE id = $1
 	`
 
-	user := &entity.User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID,
-		&user.Name,
-		&user.Email,
-		&user.Password,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			logger.Warning("Usuário não encontrado", map[string]interface{}{"id": id})
@@ -83,27 +126,50 @@ func (r *UserRepository) GetByID(id int64) (*entity.User, error) {
 	return user, nil
 }
 
+// ListByOrganization lista os usuários da organização em ordem alfabética
+func (r *UserRepository) ListByOrganization(organizationID int64) ([]*entity.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + userSelectColumns + `
+		FROM users
+		WHERE organization_id = $1
+		ORDER BY LOWER(name), id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, organizationID)
+	if err != nil {
+		logger.Error("Erro ao listar usuários da organização", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*entity.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			logger.Error("Erro ao ler usuário", err)
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
 // GetByEmail busca um usuário pelo email
 func (r *UserRepository) GetByEmail(email string) (*entity.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	query := `
-		SELECT id, name, email, password, created_at, updated_at
+		SELECT ` + userSelectColumns + `
 		FROM users
 		WHERE email = $1
 	`
 
-	user := &entity.User{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID,
-		&user.Name,
-		&user.Email,
-		&user.Password,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, email))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			logger.Warning("Usuário não encontrado com o email", map[string]interface{}{"email": email})
@@ -165,3 +231,86 @@ func (r *UserRepository) Delete(id int64) error {
 
 	return nil
 }
+
+// UpdateRole altera o papel de um usuário da organização, retornando
+// ErrLastAdmin se ele for o único administrador e sql.ErrNoRows se não
+// pertencer à organização
+func (r *UserRepository) UpdateRole(organizationID, id int64, role string) (*entity.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de alteração de papel", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Bloqueia os administradores para que duas alterações simultâneas não
+	// deixem a organização sem nenhum
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM users WHERE organization_id = $1 AND role = $2 FOR UPDATE
+	`, organizationID, entity.UserRoleAdmin)
+	if err != nil {
+		logger.Error("Erro ao buscar administradores da organização", err)
+		return nil, err
+	}
+	others := 0
+	for rows.Next() {
+		var adminID int64
+		if err := rows.Scan(&adminID); err != nil {
+			rows.Close()
+			logger.Error("Erro ao ler administrador da organização", err)
+			return nil, err
+		}
+		if adminID != id {
+			others++
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro ao ler administradores da organização", err)
+		return nil, err
+	}
+
+	user, err := scanUser(tx.QueryRowContext(ctx, `
+		UPDATE users
+		SET role = $1, updated_at = $2
+		WHERE id = $3 AND organization_id = $4
+		RETURNING `+userSelectColumns,
+		role, time.Now(), id, organizationID,
+	))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Error("Erro ao alterar papel do usuário", err)
+		}
+		return nil, err
+	}
+	if role != entity.UserRoleAdmin && others == 0 {
+		return nil, ErrLastAdmin
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Erro ao confirmar alteração de papel do usuário", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+func scanUser(row rowScanner) (*entity.User, error) {
+	user := &entity.User{}
+	err := row.Scan(
+		&user.ID,
+		&user.OrganizationID,
+		&user.Name,
+		&user.Email,
+		&user.Password,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
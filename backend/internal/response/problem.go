@@ -0,0 +1,115 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/whatsapp/backend/internal/logger"
+)
+
+// ProblemContentType é o media type definido pela RFC 7807
+const ProblemContentType = "application/problem+json"
+
+// problemTypeBase é o prefixo dos URIs que identificam cada tipo de problema
+const problemTypeBase = "/problems/"
+
+// Códigos de erro estáveis, usados pelos clientes para tratar cada situação
+const (
+	CodeInvalidBody        = "invalid_body"
+	CodeBodyTooLarge       = "body_too_large"
+	CodeValidationFailed   = "validation_failed"
+	CodeAuthRequired       = "auth_required"
+	CodeInvalidToken       = "invalid_token"
+	CodeTokenExpired       = "token_expired"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeForbidden          = "forbidden"
+	CodeConflict           = "conflict"
+	CodeTooManyRows        = "too_many_rows"
+	CodeNotFound           = "not_found"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeProviderError      = "provider_error"
+	CodeProviderDisabled   = "provider_not_configured"
+	CodeWindowClosed       = "window_closed"
+	CodeOptedOut           = "opted_out"
+	CodeInternal           = "internal_error"
+)
+
+// FieldError descreve um problema de validação em um campo específico
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Problem representa um documento de erro no formato RFC 7807 (problem details)
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Code      string       `json:"code"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem cria um problema com o status HTTP, o código e a mensagem informados
+func NewProblem(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   problemTypeBase + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+// WithErrors anexa os erros de validação por campo ao problema
+func (p *Problem) WithErrors(errs []FieldError) *Problem {
+	p.Errors = errs
+	return p
+}
+
+// WriteProblem envia o problema preenchendo o caminho e o ID da requisição
+func WriteProblem(w http.ResponseWriter, r *http.Request, p *Problem) {
+	p.Instance = r.URL.Path
+	p.RequestID = middleware.GetReqID(r.Context())
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		logger.Error("Erro ao serializar problem details", err)
+	}
+}
+
+// Error responde com um problema simples, sem detalhes por campo
+func Error(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	WriteProblem(w, r, NewProblem(status, code, detail))
+}
+
+// ValidationError responde 422 com a lista de campos inválidos
+func ValidationError(w http.ResponseWriter, r *http.Request, errs []FieldError) {
+	WriteProblem(w, r, NewProblem(http.StatusUnprocessableEntity, CodeValidationFailed,
+		"Um ou mais campos são inválidos").WithErrors(errs))
+}
+
+// InvalidBody responde 400 quando o corpo da requisição não pode ser interpretado
+func InvalidBody(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, http.StatusBadRequest, CodeInvalidBody, "Formato de requisição inválido")
+}
+
+// Internal responde 500 com uma mensagem genérica
+func Internal(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao processar solicitação")
+}
+
+// NotFound é o handler usado pelo router para rotas inexistentes
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, http.StatusNotFound, CodeNotFound, "Recurso não encontrado")
+}
+
+// MethodNotAllowed é o handler usado pelo router para métodos não suportados
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Método não permitido")
+}
@@ -0,0 +1,156 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestNewProblem(t *testing.T) {
+	tests := []struct {
+		status int
+		code   string
+		title  string
+	}{
+		{http.StatusBadRequest, CodeInvalidBody, "Bad Request"},
+		{http.StatusUnauthorized, CodeTokenExpired, "Unauthorized"},
+		{http.StatusConflict, CodeOptedOut, "Conflict"},
+		{http.StatusUnprocessableEntity, CodeValidationFailed, "Unprocessable Entity"},
+		{http.StatusBadGateway, CodeProviderError, "Bad Gateway"},
+	}
+
+	for _, tt := range tests {
+		p := NewProblem(tt.status, tt.code, "detalhe")
+		if p.Type != "/problems/"+tt.code {
+			t.Errorf("NewProblem(%d, %q).Type = %q, esperado %q", tt.status, tt.code, p.Type, "/problems/"+tt.code)
+		}
+		if p.Title != tt.title || p.Status != tt.status || p.Code != tt.code || p.Detail != "detalhe" {
+			t.Errorf("NewProblem(%d, %q) = %+v", tt.status, tt.code, p)
+		}
+	}
+}
+
+// decodeProblem confere o cabeçalho e o status da resposta e lê o problema
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder, status int) (Problem, map[string]json.RawMessage) {
+	t.Helper()
+	if rec.Code != status {
+		t.Errorf("status %d, esperado %d", rec.Code, status)
+	}
+	if got := rec.Header().Get("Content-Type"); got != ProblemContentType {
+		t.Errorf("Content-Type = %q, esperado %q", got, ProblemContentType)
+	}
+
+	var p Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatalf("corpo %q não é JSON: %v", rec.Body.String(), err)
+	}
+	var raw map[string]json.RawMessage
+	json.Unmarshal(rec.Body.Bytes(), &raw)
+	return p, raw
+}
+
+func TestWriteProblem(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/leads?dry_run=true", nil)
+	r = r.WithContext(context.WithValue(r.Context(), middleware.RequestIDKey, "req-123"))
+	rec := httptest.NewRecorder()
+
+	Error(rec, r, http.StatusConflict, CodeConflict, "Telefone já cadastrado")
+
+	p, raw := decodeProblem(t, rec, http.StatusConflict)
+	if p.Instance != "/api/leads" {
+		t.Errorf("Instance = %q, esperado o caminho sem a query", p.Instance)
+	}
+	if p.RequestID != "req-123" {
+		t.Errorf("RequestID = %q, esperado %q", p.RequestID, "req-123")
+	}
+	if p.Code != CodeConflict || p.Detail != "Telefone já cadastrado" || p.Type != "/problems/conflict" {
+		t.Errorf("problema inesperado: %+v", p)
+	}
+	if _, ok := raw["errors"]; ok {
+		t.Error("errors presente em um problema sem erros de campo")
+	}
+}
+
+func TestWriteProblemWithoutRequestID(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NotFound(rec, httptest.NewRequest(http.MethodGet, "/api/inexistente", nil))
+
+	_, raw := decodeProblem(t, rec, http.StatusNotFound)
+	if _, ok := raw["request_id"]; ok {
+		t.Error("request_id presente sem o middleware de ID da requisição")
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	errs := []FieldError{
+		{Field: "email", Code: "email", Message: "Email inválido"},
+		{Field: "phone", Code: "phone", Message: "Telefone inválido"},
+	}
+	rec := httptest.NewRecorder()
+	ValidationError(rec, httptest.NewRequest(http.MethodPost, "/api/auth/register", nil), errs)
+
+	p, _ := decodeProblem(t, rec, http.StatusUnprocessableEntity)
+	if p.Code != CodeValidationFailed {
+		t.Errorf("Code = %q, esperado %q", p.Code, CodeValidationFailed)
+	}
+	if len(p.Errors) != len(errs) {
+		t.Fatalf("Errors = %+v, esperado %+v", p.Errors, errs)
+	}
+	for i := range errs {
+		if p.Errors[i] != errs[i] {
+			t.Errorf("Errors[%d] = %+v, esperado %+v", i, p.Errors[i], errs[i])
+		}
+	}
+}
+
+func TestProblemHelpers(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(http.ResponseWriter, *http.Request)
+		status  int
+		code    string
+	}{
+		{"InvalidBody", InvalidBody, http.StatusBadRequest, CodeInvalidBody},
+		{"Internal", Internal, http.StatusInternalServerError, CodeInternal},
+		{"NotFound", NotFound, http.StatusNotFound, CodeNotFound},
+		{"MethodNotAllowed", MethodNotAllowed, http.StatusMethodNotAllowed, CodeMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler(rec, httptest.NewRequest(http.MethodGet, "/api/me", nil))
+
+			p, _ := decodeProblem(t, rec, tt.status)
+			if p.Code != tt.code || p.Status != tt.status || p.Detail == "" {
+				t.Errorf("%s: problema inesperado %+v", tt.name, p)
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	JSON(rec, http.StatusCreated, map[string]int{"id": 7})
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status %d, esperado %d", rec.Code, http.StatusCreated)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, esperado application/json", got)
+	}
+	if got := strings.TrimSpace(rec.Body.String()); got != `{"id":7}` {
+		t.Errorf("corpo %q, esperado %q", got, `{"id":7}`)
+	}
+
+	rec = httptest.NewRecorder()
+	NoContent(rec)
+	if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+		t.Errorf("NoContent respondeu %d com %q", rec.Code, rec.Body.String())
+	}
+}
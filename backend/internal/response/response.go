@@ -0,0 +1,22 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/whatsapp/backend/internal/logger"
+)
+
+// JSON serializa o valor informado como resposta JSON com o status indicado
+func JSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("Erro ao serializar resposta JSON", err)
+	}
+}
+
+// NoContent responde 204 sem corpo
+func NoContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,75 @@
+// Package signedurl gera e verifica links com prazo de validade assinados com
+// HMAC-SHA256, usados para downloads que não exigem o token de acesso.
+package signedurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Parâmetros de query acrescentados ao link assinado
+const (
+	ExpiresParam   = "expires"
+	SignatureParam = "signature"
+)
+
+// Erros de verificação de links
+var (
+	ErrInvalidSignature = errors.New("assinatura do link inválida")
+	ErrExpired          = errors.New("link expirado")
+)
+
+// Signer assina caminhos da API com um segredo compartilhado
+type Signer struct {
+	secret []byte
+}
+
+// New cria um assinador com o segredo informado
+func New(secret string) *Signer {
+	return &Signer{secret: []byte(secret)}
+}
+
+// Sign retorna o caminho com os parâmetros de expiração e assinatura
+func (s *Signer) Sign(path string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{}
+	query.Set(ExpiresParam, expires)
+	query.Set(SignatureParam, s.signature(path, expires))
+	return path + "?" + query.Encode()
+}
+
+// Verify confere a assinatura e a validade do caminho acessado
+func (s *Signer) Verify(path string, query url.Values) error {
+	expires := query.Get(ExpiresParam)
+	signature, err := hex.DecodeString(query.Get(SignatureParam))
+	if err != nil || expires == "" {
+		return ErrInvalidSignature
+	}
+
+	expected, _ := hex.DecodeString(s.signature(path, expires))
+	if !hmac.Equal(signature, expected) {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if time.Now().After(time.Unix(unix, 0)) {
+		return ErrExpired
+	}
+	return nil
+}
+
+func (s *Signer) signature(path, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(path))
+	mac.Write([]byte{0})
+	mac.Write([]byte(expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,105 @@
+package signedurl
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parse separa o caminho e os parâmetros de um link assinado
+func parse(t *testing.T, link string) (string, url.Values) {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("url.Parse(%q) retornou erro: %v", link, err)
+	}
+	return u.Path, u.Query()
+}
+
+func TestSignVerify(t *testing.T) {
+	s := New("segredo")
+	link := s.Sign("/api/media/42/download", time.Now().Add(time.Minute))
+
+	path, query := parse(t, link)
+	if path != "/api/media/42/download" {
+		t.Errorf("Sign: caminho %q, esperado %q", path, "/api/media/42/download")
+	}
+	if err := s.Verify(path, query); err != nil {
+		t.Errorf("Verify(%q) = %v, esperado nil", link, err)
+	}
+}
+
+func TestVerifyExpired(t *testing.T) {
+	s := New("segredo")
+	path, query := parse(t, s.Sign("/api/media/42/download", time.Now().Add(-time.Second)))
+
+	if err := s.Verify(path, query); !errors.Is(err, ErrExpired) {
+		t.Errorf("Verify de link vencido = %v, esperado ErrExpired", err)
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	s := New("segredo")
+	expiresAt := time.Now().Add(time.Hour)
+	path, query := parse(t, s.Sign("/api/media/42/download", expiresAt))
+	signature := query.Get(SignatureParam)
+
+	with := func(key, value string) url.Values {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		if value == "" {
+			q.Del(key)
+		} else {
+			q.Set(key, value)
+		}
+		return q
+	}
+	flipped := []byte(signature)
+	if flipped[0] == '0' {
+		flipped[0] = '1'
+	} else {
+		flipped[0] = '0'
+	}
+
+	tests := []struct {
+		name   string
+		signer *Signer
+		path   string
+		query  url.Values
+	}{
+		{"outro caminho", s, "/api/media/43/download", query},
+		{"prazo estendido", s, path, with(ExpiresParam, strconv.FormatInt(expiresAt.Add(time.Hour).Unix(), 10))},
+		{"prazo não numérico", s, path, with(ExpiresParam, "amanhã")},
+		{"sem prazo", s, path, with(ExpiresParam, "")},
+		{"assinatura alterada", s, path, with(SignatureParam, string(flipped))},
+		{"assinatura truncada", s, path, with(SignatureParam, signature[:len(signature)-2])},
+		{"assinatura não hexadecimal", s, path, with(SignatureParam, strings.Repeat("z", len(signature)))},
+		{"sem assinatura", s, path, with(SignatureParam, "")},
+		{"outro segredo", New("outro-segredo"), path, query},
+	}
+
+	for _, tt := range tests {
+		if err := tt.signer.Verify(tt.path, tt.query); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: Verify = %v, esperado ErrInvalidSignature", tt.name, err)
+		}
+	}
+}
+
+// O caminho e o prazo são separados na assinatura, de modo que dígitos não
+// passem de um para o outro
+func TestSignatureSeparatesPathAndExpiry(t *testing.T) {
+	s := New("segredo")
+	expires := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	query := url.Values{}
+	query.Set(ExpiresParam, expires[1:])
+	query.Set(SignatureParam, s.signature("/api/media/4", expires))
+
+	if err := s.Verify("/api/media/4"+expires[:1], query); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify com dígito movido do prazo para o caminho = %v, esperado ErrInvalidSignature", err)
+	}
+}
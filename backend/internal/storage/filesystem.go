@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Filesystem guarda os arquivos em um diretório local. Serve a uma única
+// instância da API ou a um volume compartilhado entre as instâncias.
+type Filesystem struct {
+	dir string
+}
+
+// NewFilesystem cria o armazenamento no diretório, criando-o se necessário
+func NewFilesystem(dir string) (*Filesystem, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de armazenamento %s: %w", dir, err)
+	}
+	return &Filesystem{dir: dir}, nil
+}
+
+// Put grava o arquivo em um temporário e o renomeia ao final, de modo que
+// leituras concorrentes nunca vejam um arquivo incompleto
+func (f *Filesystem) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, readerWithContext(ctx, r)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get abre o arquivo da chave
+func (f *Filesystem) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete remove o arquivo da chave
+func (f *Filesystem) Delete(ctx context.Context, key string) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (f *Filesystem) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("chave de armazenamento inválida: %q", key)
+	}
+	return filepath.Join(f.dir, filepath.FromSlash(key)), nil
+}
+
+// ctxReader interrompe a cópia quando o contexto é cancelado
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func readerWithContext(ctx context.Context, r io.Reader) io.Reader {
+	return &ctxReader{ctx: ctx, r: r}
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
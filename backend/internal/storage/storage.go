@@ -0,0 +1,102 @@
+// Package storage guarda os arquivos das mídias das conversas e das exportações
+// de leads no sistema de arquivos local ou em um serviço compatível com o Amazon S3
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Backends de armazenamento
+const (
+	DriverFilesystem = "filesystem"
+	DriverS3         = "s3"
+)
+
+// ErrNotFound indica um arquivo inexistente no armazenamento
+var ErrNotFound = errors.New("arquivo não encontrado no armazenamento")
+
+// BlobStore grava e lê arquivos por chave. As chaves usam "/" como
+// separador e não começam com "/".
+type BlobStore interface {
+	// Put grava o conteúdo de r, com size bytes, na chave
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get abre o arquivo da chave; retorna ErrNotFound se ele não existir
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete remove o arquivo da chave, sem erro se ele não existir
+	Delete(ctx context.Context, key string) error
+}
+
+// Config representa a configuração do armazenamento de arquivos
+type Config struct {
+	Driver string
+	// Dir é o diretório raiz do backend filesystem
+	Dir string
+	// S3Endpoint é o endereço do serviço, como https://s3.amazonaws.com ou
+	// http://localhost:9000; o bucket é acessado no caminho (path-style)
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+}
+
+// Validate verifica os campos exigidos pelo backend escolhido
+func (c Config) Validate() error {
+	switch c.Driver {
+	case DriverFilesystem:
+		if c.Dir == "" {
+			return errors.New("STORAGE_DIR: obrigatório para o armazenamento filesystem")
+		}
+	case DriverS3:
+		var missing []string
+		for _, field := range []struct{ name, value string }{
+			{"STORAGE_S3_ENDPOINT", c.S3Endpoint},
+			{"STORAGE_S3_REGION", c.S3Region},
+			{"STORAGE_S3_BUCKET", c.S3Bucket},
+			{"STORAGE_S3_ACCESS_KEY", c.S3AccessKey},
+			{"STORAGE_S3_SECRET_KEY", c.S3SecretKey},
+		} {
+			if field.value == "" {
+				missing = append(missing, field.name)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("variáveis ausentes para o armazenamento s3: %s", strings.Join(missing, ", "))
+		}
+		if u, err := url.Parse(c.S3Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("STORAGE_S3_ENDPOINT: endereço %q inválido", c.S3Endpoint)
+		}
+	default:
+		return fmt.Errorf("STORAGE_DRIVER: valor %q inválido, use %q ou %q", c.Driver, DriverFilesystem, DriverS3)
+	}
+	return nil
+}
+
+// New cria o armazenamento do backend configurado
+func New(c Config) (BlobStore, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if c.Driver == DriverS3 {
+		return NewS3(c), nil
+	}
+	return NewFilesystem(c.Dir)
+}
+
+// validKey recusa chaves vazias, absolutas ou com segmentos relativos
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
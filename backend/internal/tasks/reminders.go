@@ -0,0 +1,63 @@
+// Package tasks agenda os lembretes das tarefas de acompanhamento de leads
+package tasks
+
+import (
+	"context"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+)
+
+// Parâmetros do agendador de lembretes
+const (
+	pollInterval = 30 * time.Second
+	// batchSize limita os lembretes disparados por comando no banco
+	batchSize = 200
+)
+
+// ReminderRepository dispara os lembretes vencidos, criando as notificações
+type ReminderRepository interface {
+	FireDueReminders(limit int) (int, error)
+}
+
+// Scheduler notifica os responsáveis quando o horário do lembrete das
+// tarefas abertas chega
+type Scheduler struct {
+	tasks ReminderRepository
+}
+
+// NewScheduler cria o agendador de lembretes de tarefas
+func NewScheduler(tasks ReminderRepository) *Scheduler {
+	return &Scheduler{
+		tasks: tasks,
+	}
+}
+
+// Run dispara os lembretes vencidos até que o contexto seja cancelado. Os
+// lotes são repetidos enquanto vierem completos, para esvaziar acúmulos.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			n, err := s.tasks.FireDueReminders(batchSize)
+			if err != nil {
+				logger.Error("Erro ao disparar lembretes de tarefas", err)
+				break
+			}
+			if n > 0 {
+				logger.Info("Lembretes de tarefas enviados", map[string]interface{}{"count": n})
+			}
+			if n < batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
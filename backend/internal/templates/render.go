@@ -0,0 +1,144 @@
+package templates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// Rendered é o modelo preenchido com os dados de um lead
+type Rendered struct {
+	Header  string           `json:"header"`
+	Body    string           `json:"body"`
+	Footer  string           `json:"footer"`
+	Buttons []RenderedButton `json:"buttons"`
+	// Missing lista os campos vazios no lead, substituídos pelo exemplo
+	Missing []string `json:"missing"`
+}
+
+// RenderedButton é um botão do modelo com a URL preenchida
+type RenderedButton struct {
+	Type        string `json:"type"`
+	Text        string `json:"text"`
+	URL         string `json:"url,omitempty"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+}
+
+// Parameters são os valores das variáveis de um modelo para um lead, na
+// ordem dos marcadores de cada componente
+type Parameters struct {
+	Header []string
+	Body   []string
+	// Buttons associa a posição de cada botão de URL com marcador ao seu valor
+	Buttons map[int]string
+	// Missing lista os campos vazios no lead
+	Missing []string
+}
+
+// Resolve calcula os valores das variáveis para o lead. Campos vazios são
+// preenchidos pelo exemplo da variável e listados em Missing.
+func Resolve(t *entity.MessageTemplate, lead *entity.Lead) Parameters {
+	p := Parameters{Buttons: make(map[int]string), Missing: []string{}}
+	seen := make(map[string]bool)
+	value := func(v entity.TemplateVariable) string {
+		if s := LeadFieldValue(lead, v.Field); s != "" {
+			return s
+		}
+		if !seen[v.Field] {
+			seen[v.Field] = true
+			p.Missing = append(p.Missing, v.Field)
+		}
+		return v.Example
+	}
+
+	for _, v := range t.HeaderVariables {
+		p.Header = append(p.Header, value(v))
+	}
+	for _, v := range t.BodyVariables {
+		p.Body = append(p.Body, value(v))
+	}
+	for i, b := range t.Buttons {
+		if b.Variable != nil {
+			p.Buttons[i] = value(*b.Variable)
+		}
+	}
+	return p
+}
+
+// Render preenche o modelo com os dados do lead, para a prévia
+func Render(t *entity.MessageTemplate, lead *entity.Lead) Rendered {
+	p := Resolve(t, lead)
+
+	rendered := Rendered{
+		Header:  fill(t.Header, p.Header),
+		Body:    fill(t.Body, p.Body),
+		Footer:  t.Footer,
+		Buttons: make([]RenderedButton, 0, len(t.Buttons)),
+		Missing: p.Missing,
+	}
+	for i, b := range t.Buttons {
+		button := RenderedButton{Type: b.Type, Text: b.Text, URL: b.URL, PhoneNumber: b.PhoneNumber}
+		if value, ok := p.Buttons[i]; ok {
+			button.URL = strings.Replace(b.URL, "{{1}}", value, 1)
+		}
+		rendered.Buttons = append(rendered.Buttons, button)
+	}
+	return rendered
+}
+
+// fill substitui cada marcador {{n}} pelo n-ésimo valor
+func fill(text string, values []string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(m string) string {
+		n, err := strconv.Atoi(m[2 : len(m)-2])
+		if err != nil || n < 1 || n > len(values) {
+			return m
+		}
+		return values[n-1]
+	})
+}
+
+// LeadFieldValue formata o valor de um campo do lead como texto
+func LeadFieldValue(lead *entity.Lead, field string) string {
+	switch field {
+	case "name":
+		return lead.Name
+	case "phone":
+		return lead.Phone
+	case "email":
+		return lead.Email
+	case "source":
+		return lead.Source
+	case "status":
+		return lead.Status
+	case "stage":
+		return lead.Stage
+	}
+
+	key, ok := strings.CutPrefix(field, "custom.")
+	if !ok {
+		return ""
+	}
+	switch v := lead.CustomFields[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "sim"
+		}
+		return "não"
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
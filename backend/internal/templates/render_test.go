@@ -0,0 +1,126 @@
+package templates
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+func TestFill(t *testing.T) {
+	tests := []struct {
+		text   string
+		values []string
+		want   string
+	}{
+		{"Olá", nil, "Olá"},
+		{"Olá {{1}}", []string{"Ana"}, "Olá Ana"},
+		{"{{2}} antes de {{1}}", []string{"b", "a"}, "a antes de b"},
+		{"{{1}} e {{1}}", []string{"Ana"}, "Ana e Ana"},
+		{"Olá {{2}}", []string{"Ana"}, "Olá {{2}}"},
+		{"Olá {{0}}", []string{"Ana"}, "Olá {{0}}"},
+		{"Valor {{1}}", []string{"{{2}}"}, "Valor {{2}}"},
+	}
+
+	for _, tt := range tests {
+		if got := fill(tt.text, tt.values); got != tt.want {
+			t.Errorf("fill(%q, %q) = %q, esperado %q", tt.text, tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestLeadFieldValue(t *testing.T) {
+	lead := &entity.Lead{
+		Name:  "Ana",
+		Phone: "+5511987654321",
+		Stage: "proposta",
+		CustomFields: map[string]interface{}{
+			"plano":        "Pro",
+			"valor":        float64(1500.5),
+			"ativo":        true,
+			"inadimplente": false,
+			"interesses":   []interface{}{"a", "b"},
+		},
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"name", "Ana"},
+		{"phone", "+5511987654321"},
+		{"stage", "proposta"},
+		{"email", ""},
+		{"custom.plano", "Pro"},
+		{"custom.valor", "1500.5"},
+		{"custom.ativo", "sim"},
+		{"custom.inadimplente", "não"},
+		{"custom.interesses", "a, b"},
+		{"custom.inexistente", ""},
+		{"cpf", ""},
+	}
+
+	for _, tt := range tests {
+		if got := LeadFieldValue(lead, tt.field); got != tt.want {
+			t.Errorf("LeadFieldValue(%q) = %q, esperado %q", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	template := &entity.MessageTemplate{
+		Header:          "Oi {{1}}",
+		HeaderVariables: []entity.TemplateVariable{{Field: "name", Example: "Cliente"}},
+		Body:            "Seu plano {{1}} vence; fale com {{2}}.",
+		BodyVariables:   []entity.TemplateVariable{{Field: "custom.plano", Example: "Básico"}, {Field: "email", Example: "a@b.com"}},
+		Footer:          "Equipe",
+		Buttons: []entity.TemplateButton{
+			{Type: entity.TemplateButtonURL, Text: "Abrir", URL: "https://loja.com/u/{{1}}", Variable: &entity.TemplateVariable{Field: "email", Example: "a@b.com"}},
+			{Type: entity.TemplateButtonQuickReply, Text: "Sim"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		lead *entity.Lead
+		want Rendered
+	}{
+		{
+			"lead com todos os campos",
+			&entity.Lead{Name: "Ana", Email: "ana@x.com", CustomFields: map[string]interface{}{"plano": "Pro"}},
+			Rendered{
+				Header: "Oi Ana",
+				Body:   "Seu plano Pro vence; fale com ana@x.com.",
+				Footer: "Equipe",
+				Buttons: []RenderedButton{
+					{Type: entity.TemplateButtonURL, Text: "Abrir", URL: "https://loja.com/u/ana@x.com"},
+					{Type: entity.TemplateButtonQuickReply, Text: "Sim"},
+				},
+				Missing: []string{},
+			},
+		},
+		{
+			// Campos vazios usam o exemplo e aparecem uma vez em Missing
+			"lead sem email nem plano",
+			&entity.Lead{Name: "Ana"},
+			Rendered{
+				Header: "Oi Ana",
+				Body:   "Seu plano Básico vence; fale com a@b.com.",
+				Footer: "Equipe",
+				Buttons: []RenderedButton{
+					{Type: entity.TemplateButtonURL, Text: "Abrir", URL: "https://loja.com/u/a@b.com"},
+					{Type: entity.TemplateButtonQuickReply, Text: "Sim"},
+				},
+				Missing: []string{"custom.plano", "email"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(template, tt.lead); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Render = %+v, esperado %+v", got, tt.want)
+			}
+		})
+	}
+}
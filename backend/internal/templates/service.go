@@ -0,0 +1,211 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/whatsapp"
+)
+
+// Parâmetros da sincronização
+const (
+	syncInterval = 5 * time.Minute
+	// providerTimeout limita cada operação no provedor, incluindo a paginação
+	providerTimeout = 60 * time.Second
+)
+
+// Erros do envio e da sincronização de modelos
+var (
+	ErrNoBusinessAccount = errors.New("organização sem conta comercial do WhatsApp vinculada")
+	ErrNotSubmittable    = errors.New("apenas rascunhos e modelos rejeitados podem ser enviados para aprovação")
+)
+
+// Repository grava os modelos e o status de aprovação
+type Repository interface {
+	GetByID(organizationID, id int64) (*entity.MessageTemplate, error)
+	Update(t *entity.MessageTemplate) error
+	ListSubmitted(organizationID int64) ([]*entity.MessageTemplate, error)
+	PendingOrganizations() ([]int64, error)
+}
+
+// OrganizationFinder busca a conta comercial vinculada à organização
+type OrganizationFinder interface {
+	GetByID(id int64) (*entity.Organization, error)
+}
+
+// SyncResult resume uma sincronização com o provedor
+type SyncResult struct {
+	Checked int `json:"checked"`
+	Updated int `json:"updated"`
+}
+
+// Service envia os modelos para aprovação e sincroniza o status com o provedor
+type Service struct {
+	templates     Repository
+	organizations OrganizationFinder
+	provider      whatsapp.TemplateProvider
+}
+
+// NewService cria uma nova instância do serviço de modelos
+func NewService(templates Repository, organizations OrganizationFinder, provider whatsapp.TemplateProvider) *Service {
+	return &Service{
+		templates:     templates,
+		organizations: organizations,
+		provider:      provider,
+	}
+}
+
+// Submit envia o modelo para aprovação. Modelos rejeitados já cadastrados
+// no provedor são editados e voltam para análise.
+func (s *Service) Submit(organizationID, id int64) (*entity.MessageTemplate, error) {
+	t, err := s.templates.GetByID(organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !t.IsEditable() {
+		return nil, ErrNotSubmittable
+	}
+	businessAccountID, err := s.businessAccount(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	remote, err := s.provider.SubmitTemplate(ctx, businessAccountID, t)
+	if err != nil {
+		logger.Error("Erro ao enviar modelo de mensagem para aprovação", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	t.ExternalID = remote.ID
+	t.Status = whatsapp.TemplateStatus(remote.Status)
+	if remote.Category != "" {
+		t.Category = remote.Category
+	}
+	t.RejectionReason = ""
+	t.SubmittedAt = &now
+	t.SyncedAt = &now
+	t.UpdatedAt = now
+	if err := s.templates.Update(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Sync atualiza o status dos modelos enviados da organização com os dados
+// do provedor, associando-os pelo ID externo ou pelo nome e idioma. Modelos
+// que deixaram de existir no provedor são desativados.
+func (s *Service) Sync(organizationID int64) (*SyncResult, error) {
+	businessAccountID, err := s.businessAccount(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	remotes, err := s.provider.ListTemplates(ctx, businessAccountID)
+	if err != nil {
+		logger.Error("Erro ao consultar modelos de mensagem no provedor", err)
+		return nil, err
+	}
+	byID := make(map[string]whatsapp.RemoteTemplate, len(remotes))
+	byName := make(map[string]whatsapp.RemoteTemplate, len(remotes))
+	for _, remote := range remotes {
+		byID[remote.ID] = remote
+		byName[remote.Name+"|"+remote.Language] = remote
+	}
+
+	local, err := s.templates.ListSubmitted(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{Checked: len(local)}
+	now := time.Now()
+	for _, t := range local {
+		remote, ok := byID[t.ExternalID]
+		if !ok {
+			remote, ok = byName[t.Name+"|"+t.Language]
+		}
+
+		status, category, reason, externalID := t.Status, t.Category, t.RejectionReason, t.ExternalID
+		if ok {
+			status = whatsapp.TemplateStatus(remote.Status)
+			if remote.Category != "" {
+				category = remote.Category
+			}
+			reason = ""
+			if status == entity.TemplateStatusRejected && remote.RejectedReason != "NONE" {
+				reason = remote.RejectedReason
+			}
+			externalID = remote.ID
+		} else {
+			status = entity.TemplateStatusDisabled
+			reason = "Modelo não encontrado na conta do WhatsApp"
+		}
+
+		t.SyncedAt = &now
+		if status == t.Status && category == t.Category && reason == t.RejectionReason && externalID == t.ExternalID {
+			continue
+		}
+		t.Status, t.Category, t.RejectionReason, t.ExternalID = status, category, reason, externalID
+		t.UpdatedAt = now
+		if err := s.templates.Update(t); err != nil {
+			return nil, err
+		}
+		result.Updated++
+	}
+	return result, nil
+}
+
+// Run sincroniza periodicamente as organizações com modelos aguardando
+// aprovação até que o contexto seja cancelado
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		organizations, err := s.templates.PendingOrganizations()
+		if err != nil {
+			continue
+		}
+		for _, id := range organizations {
+			if ctx.Err() != nil {
+				return
+			}
+			result, err := s.Sync(id)
+			if err != nil {
+				logger.Error("Erro ao sincronizar modelos de mensagem", map[string]interface{}{"organization_id": id, "error": err.Error()})
+				continue
+			}
+			if result.Updated > 0 {
+				logger.Info("Modelos de mensagem sincronizados", map[string]interface{}{"organization_id": id, "updated": result.Updated})
+			}
+		}
+	}
+}
+
+// businessAccount retorna a conta comercial vinculada à organização
+func (s *Service) businessAccount(organizationID int64) (string, error) {
+	org, err := s.organizations.GetByID(organizationID)
+	if err != nil {
+		return "", err
+	}
+	if org.WhatsAppBusinessAccountID == "" {
+		return "", ErrNoBusinessAccount
+	}
+	return org.WhatsAppBusinessAccountID, nil
+}
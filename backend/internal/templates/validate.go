@@ -0,0 +1,227 @@
+// Package templates valida, renderiza e sincroniza os modelos de mensagem
+// do WhatsApp
+package templates
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+	"github.com/whatsapp/backend/pkg/phone"
+)
+
+// Limites do WhatsApp para os componentes dos modelos
+const (
+	MaxHeaderLength     = 60
+	MaxBodyLength       = 1024
+	MaxFooterLength     = 60
+	MaxButtons          = 10
+	MaxButtonTextLength = 25
+	MaxURLLength        = 2000
+	MaxExampleLength    = 200
+	maxURLButtons       = 2
+	maxPhoneButtons     = 1
+)
+
+var (
+	namePattern        = regexp.MustCompile(`^[a-z0-9_]{1,512}$`)
+	languagePattern    = regexp.MustCompile(`^[a-z]{2,3}(_[A-Z]{2})?$`)
+	placeholderPattern = regexp.MustCompile(`\{\{(\d+)\}\}`)
+)
+
+// leadFields lista os campos fixos do lead aceitos nas variáveis
+var leadFields = map[string]bool{
+	"name": true, "phone": true, "email": true, "source": true, "status": true, "stage": true,
+}
+
+// Placeholders retorna os números dos marcadores {{n}} do texto, em ordem de
+// aparição, ou false se houver chaves fora do formato {{n}}
+func Placeholders(text string) ([]int, bool) {
+	matches := placeholderPattern.FindAllStringSubmatch(text, -1)
+	if strings.Count(text, "{{") != len(matches) || strings.Count(text, "}}") != len(matches) {
+		return nil, false
+	}
+	numbers := make([]int, 0, len(matches))
+	for _, m := range matches {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, false
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, true
+}
+
+// Validate aplica as regras do WhatsApp ao modelo: formato do nome e do
+// idioma, tamanhos, marcadores sequenciais a partir de {{1}} com uma variável
+// para cada um, e botões. As variáveis precisam apontar para campos do lead,
+// incluindo os campos personalizados definidos em schema.
+func Validate(t *entity.MessageTemplate, schema customfields.Schema) []response.FieldError {
+	var errs []response.FieldError
+	add := func(field, code, message string) {
+		errs = append(errs, response.FieldError{Field: field, Code: code, Message: message})
+	}
+
+	if !namePattern.MatchString(t.Name) {
+		add("name", "invalid", "Use apenas letras minúsculas, números e _")
+	}
+	if !languagePattern.MatchString(t.Language) {
+		add("language", "invalid", "Use o código do idioma do WhatsApp, como pt_BR ou en_US")
+	}
+
+	if utf8.RuneCountInString(t.Header) > MaxHeaderLength {
+		add("header", "max", fmt.Sprintf("O cabeçalho deve ter no máximo %d caracteres", MaxHeaderLength))
+	}
+	errs = append(errs, validateText("header", t.Header, t.HeaderVariables, 1, schema)...)
+
+	if strings.TrimSpace(t.Body) == "" {
+		add("body", "required", "Campo obrigatório")
+	} else if utf8.RuneCountInString(t.Body) > MaxBodyLength {
+		add("body", "max", fmt.Sprintf("O corpo deve ter no máximo %d caracteres", MaxBodyLength))
+	} else {
+		trimmed := strings.TrimSpace(t.Body)
+		if strings.HasPrefix(trimmed, "{{") || strings.HasSuffix(trimmed, "}}") {
+			add("body", "invalid", "O corpo não pode começar nem terminar com um marcador")
+		}
+	}
+	errs = append(errs, validateText("body", t.Body, t.BodyVariables, 0, schema)...)
+
+	if utf8.RuneCountInString(t.Footer) > MaxFooterLength {
+		add("footer", "max", fmt.Sprintf("O rodapé deve ter no máximo %d caracteres", MaxFooterLength))
+	}
+	if strings.Contains(t.Footer, "{{") {
+		add("footer", "invalid", "O rodapé não aceita marcadores")
+	}
+
+	errs = append(errs, validateButtons(t.Buttons, schema)...)
+	return errs
+}
+
+// validateText verifica os marcadores do texto e as variáveis correspondentes.
+// maxPlaceholders zero significa sem limite.
+func validateText(field, text string, variables []entity.TemplateVariable, maxPlaceholders int, schema customfields.Schema) []response.FieldError {
+	numbers, ok := Placeholders(text)
+	if !ok {
+		return []response.FieldError{{Field: field, Code: "invalid", Message: "Use marcadores no formato {{1}}"}}
+	}
+
+	distinct := make(map[int]bool)
+	for _, n := range numbers {
+		distinct[n] = true
+	}
+	sorted := make([]int, 0, len(distinct))
+	for n := range distinct {
+		sorted = append(sorted, n)
+	}
+	sort.Ints(sorted)
+
+	var errs []response.FieldError
+	for i, n := range sorted {
+		if n != i+1 {
+			errs = append(errs, response.FieldError{Field: field, Code: "invalid", Message: "Os marcadores devem ser sequenciais a partir de {{1}}"})
+			break
+		}
+	}
+	if maxPlaceholders > 0 && len(sorted) > maxPlaceholders {
+		errs = append(errs, response.FieldError{Field: field, Code: "max", Message: fmt.Sprintf("Use no máximo %d marcador", maxPlaceholders)})
+	}
+	if len(variables) != len(sorted) {
+		errs = append(errs, response.FieldError{
+			Field:   field + "_variables",
+			Code:    "invalid",
+			Message: fmt.Sprintf("Informe uma variável para cada marcador: %d marcador(es), %d variável(is)", len(sorted), len(variables)),
+		})
+	}
+	for i, v := range variables {
+		errs = append(errs, validateVariable(fmt.Sprintf("%s_variables[%d]", field, i), v, schema)...)
+	}
+	return errs
+}
+
+// validateVariable exige um campo do lead conhecido e um exemplo
+func validateVariable(field string, v entity.TemplateVariable, schema customfields.Schema) []response.FieldError {
+	var errs []response.FieldError
+	if !IsLeadField(v.Field, schema) {
+		errs = append(errs, response.FieldError{Field: field + ".field", Code: "invalid", Message: "Use name, phone, email, source, status, stage ou custom.<chave> de um campo definido"})
+	}
+	if example := strings.TrimSpace(v.Example); example == "" || utf8.RuneCountInString(example) > MaxExampleLength {
+		errs = append(errs, response.FieldError{Field: field + ".example", Code: "required", Message: fmt.Sprintf("Informe um exemplo com até %d caracteres para a aprovação", MaxExampleLength)})
+	}
+	return errs
+}
+
+// IsLeadField verifica se o campo pode preencher uma variável
+func IsLeadField(field string, schema customfields.Schema) bool {
+	if key, ok := strings.CutPrefix(field, "custom."); ok {
+		return schema[key] != nil
+	}
+	return leadFields[field]
+}
+
+func validateButtons(buttons []entity.TemplateButton, schema customfields.Schema) []response.FieldError {
+	var errs []response.FieldError
+	if len(buttons) > MaxButtons {
+		errs = append(errs, response.FieldError{Field: "buttons", Code: "max", Message: fmt.Sprintf("Use no máximo %d botões", MaxButtons)})
+	}
+
+	counts := make(map[string]int)
+	for i, b := range buttons {
+		field := fmt.Sprintf("buttons[%d]", i)
+		add := func(suffix, code, message string) {
+			errs = append(errs, response.FieldError{Field: field + suffix, Code: code, Message: message})
+		}
+		counts[b.Type]++
+
+		if text := strings.TrimSpace(b.Text); text == "" || utf8.RuneCountInString(text) > MaxButtonTextLength {
+			add(".text", "required", fmt.Sprintf("Informe o texto do botão com até %d caracteres", MaxButtonTextLength))
+		}
+
+		switch b.Type {
+		case entity.TemplateButtonQuickReply:
+			if b.URL != "" || b.PhoneNumber != "" || b.Variable != nil {
+				add("", "invalid", "Respostas rápidas têm apenas texto")
+			}
+		case entity.TemplateButtonURL:
+			u, err := url.Parse(strings.Replace(b.URL, "{{1}}", "x", 1))
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || len(b.URL) > MaxURLLength {
+				add(".url", "invalid", fmt.Sprintf("Informe uma URL http(s) com até %d caracteres", MaxURLLength))
+			}
+			numbers, ok := Placeholders(b.URL)
+			switch {
+			case !ok || len(numbers) > 1 || (len(numbers) == 1 && (numbers[0] != 1 || !strings.HasSuffix(b.URL, "{{1}}"))):
+				add(".url", "invalid", "A URL aceita apenas um marcador {{1}} no final")
+			case len(numbers) == 1 && b.Variable == nil:
+				add(".variable", "required", "Informe a variável do marcador da URL")
+			case len(numbers) == 0 && b.Variable != nil:
+				add(".variable", "invalid", "A URL não tem marcador para a variável")
+			}
+			if b.Variable != nil {
+				errs = append(errs, validateVariable(field+".variable", *b.Variable, schema)...)
+			}
+		case entity.TemplateButtonPhoneNumber:
+			if _, err := phone.Normalize(b.PhoneNumber); err != nil || len(b.PhoneNumber) > 20 {
+				add(".phone_number", "phone", "Telefone inválido")
+			}
+			if b.Variable != nil {
+				add(".variable", "invalid", "Botões de telefone não aceitam variáveis")
+			}
+		default:
+			add(".type", "oneof", "Use QUICK_REPLY, URL ou PHONE_NUMBER")
+		}
+	}
+
+	if counts[entity.TemplateButtonURL] > maxURLButtons {
+		errs = append(errs, response.FieldError{Field: "buttons", Code: "max", Message: fmt.Sprintf("Use no máximo %d botões de URL", maxURLButtons)})
+	}
+	if counts[entity.TemplateButtonPhoneNumber] > maxPhoneButtons {
+		errs = append(errs, response.FieldError{Field: "buttons", Code: "max", Message: "Use no máximo 1 botão de telefone"})
+	}
+	return errs
+}
@@ -0,0 +1,180 @@
+package templates
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/whatsapp/backend/internal/customfields"
+	"github.com/whatsapp/backend/internal/models/entity"
+	"github.com/whatsapp/backend/internal/response"
+)
+
+var schema = customfields.Schema{"plano": &entity.CustomFieldDefinition{Key: "plano"}}
+
+// codes resume os erros como "campo:código" para comparar nas tabelas
+func codes(errs []response.FieldError) string {
+	parts := make([]string, len(errs))
+	for i, e := range errs {
+		parts[i] = e.Field + ":" + e.Code
+	}
+	return strings.Join(parts, " ")
+}
+
+func variable(field string) entity.TemplateVariable {
+	return entity.TemplateVariable{Field: field, Example: "exemplo"}
+}
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		text string
+		want []int
+		ok   bool
+	}{
+		{"", []int{}, true},
+		{"Olá", []int{}, true},
+		{"Olá {{1}}", []int{1}, true},
+		{"{{2}} e {{1}} e {{2}}", []int{2, 1, 2}, true},
+		{"Olá {{10}}", []int{10}, true},
+		{"Olá {{nome}}", nil, false},
+		{"Olá {{1}", nil, false},
+		{"Olá {1}}", nil, false},
+		{"Olá {{ 1 }}", nil, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Placeholders(tt.text)
+		if ok != tt.ok || (tt.ok && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("Placeholders(%q) = %v, %v; esperado %v, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *entity.MessageTemplate {
+		return &entity.MessageTemplate{
+			Name:          "boas_vindas",
+			Language:      "pt_BR",
+			Body:          "Olá {{1}}, seu plano {{2}} está ativo.",
+			BodyVariables: []entity.TemplateVariable{variable("name"), variable("custom.plano")},
+		}
+	}
+
+	tests := []struct {
+		name string
+		edit func(t *entity.MessageTemplate)
+		want string
+	}{
+		{"válido", func(t *entity.MessageTemplate) {}, ""},
+		{"marcador repetido", func(t *entity.MessageTemplate) {
+			t.Body = "Olá {{1}}, {{1}} e {{2}}."
+		}, ""},
+		{"nome com maiúsculas", func(t *entity.MessageTemplate) { t.Name = "Boas_Vindas" }, "name:invalid"},
+		{"idioma inválido", func(t *entity.MessageTemplate) { t.Language = "portugues" }, "language:invalid"},
+		{"idioma sem região", func(t *entity.MessageTemplate) { t.Language = "pt" }, ""},
+		{"corpo vazio", func(t *entity.MessageTemplate) {
+			t.Body, t.BodyVariables = " ", nil
+		}, "body:required"},
+		{"corpo acima do limite", func(t *entity.MessageTemplate) {
+			t.Body, t.BodyVariables = strings.Repeat("a", MaxBodyLength+1), nil
+		}, "body:max"},
+		{"corpo começando com marcador", func(t *entity.MessageTemplate) {
+			t.Body = "{{1}}, seu plano {{2}} está ativo."
+		}, "body:invalid"},
+		{"corpo terminando com marcador", func(t *entity.MessageTemplate) {
+			t.Body = "Olá {{1}}, seu plano é {{2}}"
+		}, "body:invalid"},
+		{"marcadores fora de sequência", func(t *entity.MessageTemplate) {
+			t.Body = "Olá {{1}}, seu plano {{3}} está ativo."
+		}, "body:invalid"},
+		{"marcadores sem começar em 1", func(t *entity.MessageTemplate) {
+			t.Body = "Olá {{2}}, seu plano {{3}} está ativo."
+		}, "body:invalid"},
+		{"marcador malformado", func(t *entity.MessageTemplate) {
+			t.Body = "Olá {{nome}}, seu plano {{2}} está ativo."
+		}, "body:invalid"},
+		{"variável faltando", func(t *entity.MessageTemplate) {
+			t.BodyVariables = t.BodyVariables[:1]
+		}, "body_variables:invalid"},
+		{"variável sobrando", func(t *entity.MessageTemplate) {
+			t.BodyVariables = append(t.BodyVariables, variable("email"))
+		}, "body_variables:invalid"},
+		{"campo desconhecido", func(t *entity.MessageTemplate) {
+			t.BodyVariables[0].Field = "cpf"
+		}, "body_variables[0].field:invalid"},
+		{"campo personalizado não definido", func(t *entity.MessageTemplate) {
+			t.BodyVariables[1].Field = "custom.cidade"
+		}, "body_variables[1].field:invalid"},
+		{"exemplo vazio", func(t *entity.MessageTemplate) {
+			t.BodyVariables[0].Example = "  "
+		}, "body_variables[0].example:required"},
+		{"exemplo longo", func(t *entity.MessageTemplate) {
+			t.BodyVariables[0].Example = strings.Repeat("a", MaxExampleLength+1)
+		}, "body_variables[0].example:required"},
+		{"cabeçalho com um marcador", func(t *entity.MessageTemplate) {
+			t.Header, t.HeaderVariables = "Oi {{1}}", []entity.TemplateVariable{variable("name")}
+		}, ""},
+		{"cabeçalho com dois marcadores", func(t *entity.MessageTemplate) {
+			t.Header = "Oi {{1}} {{2}}"
+			t.HeaderVariables = []entity.TemplateVariable{variable("name"), variable("email")}
+		}, "header:max"},
+		{"cabeçalho acima do limite", func(t *entity.MessageTemplate) {
+			t.Header = strings.Repeat("á", MaxHeaderLength+1)
+		}, "header:max"},
+		{"rodapé com marcador", func(t *entity.MessageTemplate) { t.Footer = "Até {{1}}" }, "footer:invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := valid()
+			tt.edit(template)
+			if got := codes(Validate(template, schema)); got != tt.want {
+				t.Errorf("Validate(%q) = %q, esperado %q", template.Body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateButtons(t *testing.T) {
+	name := variable("name")
+	url := func(u string, v *entity.TemplateVariable) entity.TemplateButton {
+		return entity.TemplateButton{Type: entity.TemplateButtonURL, Text: "Abrir", URL: u, Variable: v}
+	}
+	call := entity.TemplateButton{Type: entity.TemplateButtonPhoneNumber, Text: "Ligar", PhoneNumber: "+5511987654321"}
+	reply := entity.TemplateButton{Type: entity.TemplateButtonQuickReply, Text: "Sim"}
+
+	tests := []struct {
+		name    string
+		buttons []entity.TemplateButton
+		want    string
+	}{
+		{"sem botões", nil, ""},
+		{"resposta rápida", []entity.TemplateButton{reply}, ""},
+		{"resposta rápida com URL", []entity.TemplateButton{{Type: entity.TemplateButtonQuickReply, Text: "Sim", URL: "https://a.com"}}, "buttons[0]:invalid"},
+		{"texto vazio", []entity.TemplateButton{{Type: entity.TemplateButtonQuickReply}}, "buttons[0].text:required"},
+		{"texto longo", []entity.TemplateButton{{Type: entity.TemplateButtonQuickReply, Text: strings.Repeat("a", MaxButtonTextLength+1)}}, "buttons[0].text:required"},
+		{"URL fixa", []entity.TemplateButton{url("https://loja.com/ofertas", nil)}, ""},
+		{"URL com marcador e variável", []entity.TemplateButton{url("https://loja.com/u/{{1}}", &name)}, ""},
+		{"URL sem esquema", []entity.TemplateButton{url("loja.com", nil)}, "buttons[0].url:invalid"},
+		{"URL ftp", []entity.TemplateButton{url("ftp://loja.com", nil)}, "buttons[0].url:invalid"},
+		{"marcador no meio da URL", []entity.TemplateButton{url("https://loja.com/{{1}}/x", &name)}, "buttons[0].url:invalid"},
+		{"marcador 2 na URL", []entity.TemplateButton{url("https://loja.com/{{2}}", &name)}, "buttons[0].url:invalid"},
+		{"marcador sem variável", []entity.TemplateButton{url("https://loja.com/{{1}}", nil)}, "buttons[0].variable:required"},
+		{"variável sem marcador", []entity.TemplateButton{url("https://loja.com", &name)}, "buttons[0].variable:invalid"},
+		{"telefone", []entity.TemplateButton{call}, ""},
+		{"telefone inválido", []entity.TemplateButton{{Type: entity.TemplateButtonPhoneNumber, Text: "Ligar", PhoneNumber: "123"}}, "buttons[0].phone_number:phone"},
+		{"telefone com variável", []entity.TemplateButton{{Type: entity.TemplateButtonPhoneNumber, Text: "Ligar", PhoneNumber: "+5511987654321", Variable: &name}}, "buttons[0].variable:invalid"},
+		{"tipo desconhecido", []entity.TemplateButton{{Type: "COPY_CODE", Text: "Copiar"}}, "buttons[0].type:oneof"},
+		{"três botões de URL", []entity.TemplateButton{url("https://a.com", nil), url("https://b.com", nil), url("https://c.com", nil)}, "buttons:max"},
+		{"dois botões de telefone", []entity.TemplateButton{call, call}, "buttons:max"},
+		{"botões demais", []entity.TemplateButton{reply, reply, reply, reply, reply, reply, reply, reply, reply, reply, reply}, "buttons:max"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := codes(validateButtons(tt.buttons, schema)); got != tt.want {
+				t.Errorf("validateButtons(%+v) = %q, esperado %q", tt.buttons, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxBodyBytes é o tamanho máximo aceito para corpos JSON
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// Erros de leitura do corpo da requisição
+var (
+	ErrBodyTooLarge = errors.New("corpo da requisição excede o tamanho máximo")
+	ErrInvalidBody  = errors.New("corpo da requisição inválido")
+)
+
+// DecodeJSON lê o corpo JSON da requisição em dst, limitando seu tamanho a
+// maxBytes e recusando campos desconhecidos e conteúdo após o objeto
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}, maxBytes int64) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return ErrBodyTooLarge
+		}
+		return errors.Join(ErrInvalidBody, err)
+	}
+
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return ErrInvalidBody
+	}
+
+	return nil
+}
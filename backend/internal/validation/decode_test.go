@@ -0,0 +1,49 @@
+package validation
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	type body struct {
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		max     int64
+		wantErr error
+	}{
+		{"válido", `{"name":"Ana"}`, DefaultMaxBodyBytes, nil},
+		{"com espaços ao final", "{\"name\":\"Ana\"}\n  ", DefaultMaxBodyBytes, nil},
+		{"vazio", ``, DefaultMaxBodyBytes, ErrInvalidBody},
+		{"malformado", `{"name":`, DefaultMaxBodyBytes, ErrInvalidBody},
+		{"campo desconhecido", `{"name":"Ana","admin":true}`, DefaultMaxBodyBytes, ErrInvalidBody},
+		{"tipo errado", `{"name":1}`, DefaultMaxBodyBytes, ErrInvalidBody},
+		{"segundo objeto", `{"name":"Ana"}{"name":"Bia"}`, DefaultMaxBodyBytes, ErrInvalidBody},
+		{"conteúdo após o objeto", `{"name":"Ana"} x`, DefaultMaxBodyBytes, ErrInvalidBody},
+		{"acima do limite", `{"name":"` + strings.Repeat("a", 64) + `"}`, 32, ErrBodyTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			var dst body
+			err := DecodeJSON(httptest.NewRecorder(), r, &dst, tt.max)
+			if tt.wantErr == nil {
+				if err != nil || dst.Name != "Ana" {
+					t.Errorf("DecodeJSON(%q) = %+v, %v; esperado Ana", tt.body, dst, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodeJSON(%q) = %v, esperado %v", tt.body, err, tt.wantErr)
+			}
+		})
+	}
+}
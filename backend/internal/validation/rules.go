@@ -0,0 +1,153 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/whatsapp/backend/pkg/phone"
+)
+
+// ruleFunc valida um valor e, em caso de falha, retorna o código e a mensagem do erro
+type ruleFunc func(value reflect.Value, param string) (code, message string, ok bool)
+
+// ruleSet reúne as regras disponíveis para a tag `validate`
+var ruleSet = map[string]ruleFunc{
+	"required": required,
+	"email":    email,
+	"min":      minRule,
+	"max":      maxRule,
+	"e164":     e164,
+	"phone":    phoneRule,
+	"oneof":    oneOf,
+	"password": password,
+}
+
+// e164Pattern aceita o sinal de mais seguido de 8 a 15 dígitos, sem zero inicial
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// Limites da política de senha
+const (
+	passwordMinLength = 8
+	// bcrypt ignora tudo além de 72 bytes
+	passwordMaxBytes = 72
+)
+
+func required(value reflect.Value, _ string) (string, string, bool) {
+	if value.Kind() == reflect.String {
+		return "required", "Campo obrigatório", strings.TrimSpace(value.String()) != ""
+	}
+	return "required", "Campo obrigatório", !value.IsZero()
+}
+
+func email(value reflect.Value, _ string) (string, string, bool) {
+	const code, message = "email", "Email inválido"
+
+	s := value.String()
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || addr.Name != "" {
+		return code, message, false
+	}
+
+	_, domain, _ := strings.Cut(s, "@")
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return code, message, false
+	}
+	return code, message, true
+}
+
+func minRule(value reflect.Value, param string) (string, string, bool) {
+	limit := mustInt(param)
+	switch value.Kind() {
+	case reflect.String:
+		return "min_length", fmt.Sprintf("Deve ter ao menos %d caracteres", limit),
+			utf8.RuneCountInString(value.String()) >= limit
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return "min_items", fmt.Sprintf("Deve ter ao menos %d itens", limit), value.Len() >= limit
+	case reflect.Float32, reflect.Float64:
+		return "min", fmt.Sprintf("Deve ser maior ou igual a %d", limit), value.Float() >= float64(limit)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "min", fmt.Sprintf("Deve ser maior ou igual a %d", limit), value.Uint() >= uint64(limit)
+	default:
+		return "min", fmt.Sprintf("Deve ser maior ou igual a %d", limit), value.Int() >= int64(limit)
+	}
+}
+
+func maxRule(value reflect.Value, param string) (string, string, bool) {
+	limit := mustInt(param)
+	switch value.Kind() {
+	case reflect.String:
+		return "max_length", fmt.Sprintf("Deve ter no máximo %d caracteres", limit),
+			utf8.RuneCountInString(value.String()) <= limit
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return "max_items", fmt.Sprintf("Deve ter no máximo %d itens", limit), value.Len() <= limit
+	case reflect.Float32, reflect.Float64:
+		return "max", fmt.Sprintf("Deve ser menor ou igual a %d", limit), value.Float() <= float64(limit)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "max", fmt.Sprintf("Deve ser menor ou igual a %d", limit), value.Uint() <= uint64(limit)
+	default:
+		return "max", fmt.Sprintf("Deve ser menor ou igual a %d", limit), value.Int() <= int64(limit)
+	}
+}
+
+func e164(value reflect.Value, _ string) (string, string, bool) {
+	return "e164", "Telefone deve estar no formato E.164 (ex.: +5511987654321)",
+		e164Pattern.MatchString(value.String())
+}
+
+// phoneRule aceita telefones em qualquer formatação reconhecida por pkg/phone
+func phoneRule(value reflect.Value, _ string) (string, string, bool) {
+	_, err := phone.Parse(value.String())
+	return "phone", "Telefone inválido, informe DDD e número (ex.: (11) 98765-4321)", err == nil
+}
+
+func oneOf(value reflect.Value, param string) (string, string, bool) {
+	options := strings.Fields(param)
+	message := "Valor deve ser um de: " + strings.Join(options, ", ")
+
+	s := fmt.Sprint(value.Interface())
+	for _, option := range options {
+		if s == option {
+			return "oneof", message, true
+		}
+	}
+	return "oneof", message, false
+}
+
+func password(value reflect.Value, _ string) (string, string, bool) {
+	s := value.String()
+	if utf8.RuneCountInString(s) < passwordMinLength {
+		return "password_too_short", fmt.Sprintf("A senha deve ter ao menos %d caracteres", passwordMinLength), false
+	}
+	if len(s) > passwordMaxBytes {
+		return "password_too_long", fmt.Sprintf("A senha deve ter no máximo %d bytes", passwordMaxBytes), false
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return "password_weak", "A senha deve conter letras e números", false
+	}
+	return "password", "", true
+}
+
+// mustInt converte o parâmetro da regra, já verificado em parseRules
+func mustInt(param string) int {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		panic(fmt.Sprintf("validation: parâmetro numérico inválido %q", param))
+	}
+	return n
+}
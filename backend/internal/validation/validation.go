@@ -0,0 +1,152 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/whatsapp/backend/internal/response"
+)
+
+// Validator pode ser implementado pelos DTOs para validações que envolvem mais de um campo.
+// É executado após as regras declaradas nas tags.
+type Validator interface {
+	Validate() []response.FieldError
+}
+
+// rule representa uma regra declarada na tag `validate`, como "max=100"
+type rule struct {
+	name  string
+	param string
+}
+
+// field guarda as regras de um campo já interpretadas
+type field struct {
+	index []int
+	name  string
+	rules []rule
+}
+
+// cache evita reinterpretar as tags a cada requisição
+var cache sync.Map
+
+// Struct valida um struct (ou ponteiro para struct) conforme as tags `validate`
+// e retorna todos os erros encontrados, na ordem em que os campos são declarados.
+//
+// Regras suportadas: required, email, min=N, max=N, e164, phone, oneof=a b c e password.
+// Para textos min e max contam caracteres; para números comparam o valor; para
+// listas contam itens. Campos opcionais vazios só são validados por required.
+func Struct(v interface{}) []response.FieldError {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validation: tipo %s não é um struct", rv.Type()))
+	}
+
+	var errs []response.FieldError
+	for _, f := range fieldsOf(rv.Type()) {
+		value := rv.FieldByIndex(f.index)
+		for _, r := range f.rules {
+			if fe := check(f.name, value, r); fe != nil {
+				errs = append(errs, *fe)
+				break
+			}
+		}
+	}
+
+	if custom, ok := v.(Validator); ok {
+		errs = append(errs, custom.Validate()...)
+	}
+
+	return errs
+}
+
+// fieldsOf interpreta as tags do tipo, consultando o cache
+func fieldsOf(t reflect.Type) []field {
+	if cached, ok := cache.Load(t); ok {
+		return cached.([]field)
+	}
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		fields = append(fields, field{
+			index: sf.Index,
+			name:  jsonName(sf),
+			rules: parseRules(tag),
+		})
+	}
+
+	cache.Store(t, fields)
+	return fields
+}
+
+// parseRules converte "required,max=100" em uma lista de regras
+func parseRules(tag string) []rule {
+	var rules []rule
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, param, _ := strings.Cut(part, "=")
+		if _, ok := ruleSet[name]; !ok {
+			panic(fmt.Sprintf("validation: regra desconhecida %q", name))
+		}
+		if name == "min" || name == "max" {
+			if _, err := strconv.Atoi(param); err != nil {
+				panic(fmt.Sprintf("validation: parâmetro inválido em %q", part))
+			}
+		}
+		rules = append(rules, rule{name: name, param: param})
+	}
+	return rules
+}
+
+// jsonName usa o nome do campo no JSON para que o cliente identifique o erro
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
+
+// check aplica uma regra ao valor, ignorando valores vazios exceto para required
+func check(name string, value reflect.Value, r rule) *response.FieldError {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			if r.name == "required" {
+				return fieldError(name, "required", "Campo obrigatório")
+			}
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if r.name != "required" && value.IsZero() {
+		return nil
+	}
+
+	code, message, ok := ruleSet[r.name](value, r.param)
+	if ok {
+		return nil
+	}
+	return fieldError(name, code, message)
+}
+
+func fieldError(name, code, message string) *response.FieldError {
+	return &response.FieldError{Field: name, Code: code, Message: message}
+}
@@ -0,0 +1,180 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/whatsapp/backend/internal/response"
+)
+
+type registerRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	Email    string `json:"email" validate:"required,email,max=100"`
+	Password string `json:"password" validate:"required,password"`
+	Phone    string `json:"phone,omitempty" validate:"e164"`
+}
+
+type ruleRequest struct {
+	Phone  string   `json:"phone" validate:"phone"`
+	Status string   `json:"status" validate:"oneof=new contacted won"`
+	Tags   []string `json:"tags" validate:"min=1,max=2"`
+	Score  int      `json:"score" validate:"min=1,max=10"`
+	Limit  *int     `json:"limit" validate:"required,max=100"`
+	Note   *string  `json:"note" validate:"max=5"`
+	Hidden string   `validate:"required"`
+	Ignore string   `json:"ignore"`
+}
+
+type passwordConfirmation struct {
+	Password string `json:"password" validate:"required"`
+	Confirm  string `json:"confirm" validate:"required"`
+}
+
+func (p passwordConfirmation) Validate() []response.FieldError {
+	if p.Password != p.Confirm {
+		return []response.FieldError{{Field: "confirm", Code: "mismatch", Message: "As senhas não conferem"}}
+	}
+	return nil
+}
+
+// codes resume os erros como "campo:código" para comparar nas tabelas
+func codes(errs []response.FieldError) string {
+	parts := make([]string, len(errs))
+	for i, e := range errs {
+		parts[i] = e.Field + ":" + e.Code
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestStructRegister(t *testing.T) {
+	valid := registerRequest{Name: "Ana", Email: "ana@example.com", Password: "senha123"}
+
+	tests := []struct {
+		name string
+		edit func(r *registerRequest)
+		want string
+	}{
+		{"válido", func(r *registerRequest) {}, ""},
+		{"telefone E.164", func(r *registerRequest) { r.Phone = "+5511987654321" }, ""},
+		{"vazio", func(r *registerRequest) { *r = registerRequest{} }, "name:required email:required password:required"},
+		{"nome só com espaços", func(r *registerRequest) { r.Name = "   " }, "name:required"},
+		{"nome no limite", func(r *registerRequest) { r.Name = strings.Repeat("á", 100) }, ""},
+		{"nome acima do limite", func(r *registerRequest) { r.Name = strings.Repeat("á", 101) }, "name:max_length"},
+		{"email sem domínio", func(r *registerRequest) { r.Email = "ana@" }, "email:email"},
+		{"email sem ponto no domínio", func(r *registerRequest) { r.Email = "ana@localhost" }, "email:email"},
+		{"email com nome", func(r *registerRequest) { r.Email = "Ana <ana@example.com>" }, "email:email"},
+		{"email com domínio terminado em ponto", func(r *registerRequest) { r.Email = "ana@example." }, "email:email"},
+		{"email longo", func(r *registerRequest) { r.Email = strings.Repeat("a", 90) + "@example.com" }, "email:max_length"},
+		{"senha curta", func(r *registerRequest) { r.Password = "abc12" }, "password:password_too_short"},
+		{"senha acima de 72 bytes", func(r *registerRequest) { r.Password = strings.Repeat("a1", 37) }, "password:password_too_long"},
+		{"senha sem números", func(r *registerRequest) { r.Password = "senhasenha" }, "password:password_weak"},
+		{"senha sem letras", func(r *registerRequest) { r.Password = "12345678" }, "password:password_weak"},
+		{"telefone sem mais", func(r *registerRequest) { r.Phone = "5511987654321" }, "phone:e164"},
+		{"telefone com zero inicial", func(r *registerRequest) { r.Phone = "+0511987654321" }, "phone:e164"},
+		{"telefone longo", func(r *registerRequest) { r.Phone = "+1234567890123456" }, "phone:e164"},
+		{"vários erros de uma vez", func(r *registerRequest) { r.Email = "x"; r.Password = "curta" }, "email:email password:password_too_short"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.edit(&req)
+			if got := codes(Struct(&req)); got != tt.want {
+				t.Errorf("Struct(%+v) = %q, esperado %q", req, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructRules(t *testing.T) {
+	limit, over := 10, 101
+	long, short := "longo demais", "curto"
+
+	tests := []struct {
+		name string
+		req  ruleRequest
+		want string
+	}{
+		{"válido", ruleRequest{Phone: "(11) 98765-4321", Status: "won", Tags: []string{"a"}, Score: 10, Limit: &limit, Note: &short, Hidden: "x"}, ""},
+		{"opcionais vazios", ruleRequest{Limit: &limit, Hidden: "x"}, ""},
+		{"telefone inválido", ruleRequest{Phone: "123", Limit: &limit, Hidden: "x"}, "phone:phone"},
+		{"fora das opções", ruleRequest{Status: "lost", Limit: &limit, Hidden: "x"}, "status:oneof"},
+		{"lista acima do limite", ruleRequest{Tags: []string{"a", "b", "c"}, Limit: &limit, Hidden: "x"}, "tags:max_items"},
+		{"número abaixo do mínimo", ruleRequest{Score: -1, Limit: &limit, Hidden: "x"}, "score:min"},
+		{"número acima do máximo", ruleRequest{Score: 11, Limit: &limit, Hidden: "x"}, "score:max"},
+		{"ponteiro obrigatório nulo", ruleRequest{Hidden: "x"}, "limit:required"},
+		{"ponteiro acima do máximo", ruleRequest{Limit: &over, Hidden: "x"}, "limit:max"},
+		{"ponteiro opcional acima do limite", ruleRequest{Limit: &limit, Note: &long, Hidden: "x"}, "note:max_length"},
+		{"campo sem tag json usa o nome Go", ruleRequest{Limit: &limit}, "Hidden:required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := codes(Struct(tt.req)); got != tt.want {
+				t.Errorf("Struct(%+v) = %q, esperado %q", tt.req, got, tt.want)
+			}
+		})
+	}
+}
+
+// Uma lista ausente no JSON é opcional, mas uma lista vazia enviada
+// explicitamente é validada por min
+func TestStructEmptySlice(t *testing.T) {
+	limit := 1
+	if got := codes(Struct(ruleRequest{Tags: nil, Limit: &limit, Hidden: "x"})); got != "" {
+		t.Errorf("Struct sem lista = %q, esperado sem erros", got)
+	}
+	if got := codes(Struct(ruleRequest{Tags: []string{}, Limit: &limit, Hidden: "x"})); got != "tags:min_items" {
+		t.Errorf("Struct com lista vazia = %q, esperado %q", got, "tags:min_items")
+	}
+}
+
+func TestStructCustomValidator(t *testing.T) {
+	tests := []struct {
+		req  passwordConfirmation
+		want string
+	}{
+		{passwordConfirmation{Password: "a", Confirm: "a"}, ""},
+		{passwordConfirmation{Password: "a", Confirm: "b"}, "confirm:mismatch"},
+		{passwordConfirmation{Password: "a"}, "confirm:required confirm:mismatch"},
+	}
+
+	for _, tt := range tests {
+		if got := codes(Struct(tt.req)); got != tt.want {
+			t.Errorf("Struct(%+v) = %q, esperado %q", tt.req, got, tt.want)
+		}
+	}
+}
+
+func TestStructNilPointer(t *testing.T) {
+	var req *registerRequest
+	if errs := Struct(req); errs != nil {
+		t.Errorf("Struct(nil) = %+v, esperado nil", errs)
+	}
+}
+
+func TestStructPanicsOnInvalidTags(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"regra desconhecida", struct {
+			A string `validate:"cpf"`
+		}{}},
+		{"parâmetro não numérico", struct {
+			A string `validate:"max=dez"`
+		}{}},
+		{"não é struct", "texto"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Struct(%T) não entrou em pânico", tt.v)
+				}
+			}()
+			Struct(tt.v)
+		})
+	}
+}
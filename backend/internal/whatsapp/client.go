@@ -0,0 +1,157 @@
+package whatsapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Prazos das chamadas à WhatsApp Cloud API
+const (
+	// requestTimeout limita cada chamada à API
+	requestTimeout = 15 * time.Second
+	// transferTimeout limita o download e o envio de um arquivo de mídia
+	transferTimeout = 5 * time.Minute
+)
+
+// APIError é um erro retornado pela WhatsApp Cloud API
+type APIError struct {
+	StatusCode int
+	Code       int    `json:"code"`
+	Subcode    int    `json:"error_subcode"`
+	Message    string `json:"message"`
+	UserTitle  string `json:"error_user_title"`
+	UserMsg    string `json:"error_user_msg"`
+	TraceID    string `json:"fbtrace_id"`
+}
+
+func (e *APIError) Error() string {
+	if e.UserMsg != "" {
+		return fmt.Sprintf("WhatsApp Cloud API (%d): %s", e.Code, e.UserMsg)
+	}
+	return fmt.Sprintf("WhatsApp Cloud API (%d): %s", e.Code, e.Message)
+}
+
+// transientCodes são códigos de erro da Cloud API que indicam falhas
+// temporárias ou limites de ritmo, em que a mesma requisição pode ser repetida
+var transientCodes = map[int]bool{
+	1:      true, // erro desconhecido da API
+	2:      true, // serviço temporariamente indisponível
+	4:      true, // limite de chamadas do aplicativo
+	80007:  true, // limite de chamadas da conta comercial
+	130429: true, // limite de vazão do número
+	131000: true, // falha interna
+	131016: true, // serviço sobrecarregado
+	131056: true, // limite de mensagens para o mesmo destinatário
+}
+
+// IsTransient indica erros em que o envio pode ser repetido mais tarde:
+// limites de ritmo, falhas do provedor e falhas de rede
+func IsTransient(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500 || transientCodes[apiErr.Code]
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// Client chama a WhatsApp Cloud API com o token de acesso configurado
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+	// transferClient baixa e envia arquivos de mídia, com prazo maior
+	transferClient *http.Client
+}
+
+// NewClient cria um cliente da WhatsApp Cloud API
+func NewClient(cfg Config) *Client {
+	return &Client{
+		cfg:            cfg,
+		httpClient:     &http.Client{Timeout: requestTimeout},
+		transferClient: &http.Client{Timeout: transferTimeout},
+	}
+}
+
+// do envia a requisição ao caminho relativo a APIURL (ou a uma URL completa,
+// como os links de paginação) e decodifica a resposta em out
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	contentType := ""
+	if body != nil {
+		contentType = "application/json"
+	}
+	resp, err := c.send(ctx, c.httpClient, method, path, contentType, reader)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// send envia a requisição autenticada e retorna a resposta de sucesso com o
+// corpo aberto, ou o *APIError das respostas de erro
+func (c *Client) send(ctx context.Context, client *http.Client, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	if err := c.cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	endpoint := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		endpoint = strings.TrimSuffix(c.cfg.APIURL, "/") + "/" + strings.TrimPrefix(path, "/")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.cfg.AccessToken)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Error *APIError `json:"error"`
+	}
+	if json.Unmarshal(data, &payload) != nil || payload.Error == nil {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	}
+	payload.Error.StatusCode = resp.StatusCode
+	return nil, payload.Error
+}
@@ -0,0 +1,42 @@
+package whatsapp
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// DefaultAPIURL é o endereço padrão da WhatsApp Cloud API
+const DefaultAPIURL = "https://graph.facebook.com/v19.0"
+
+// Erros de configuração do provedor
+var (
+	ErrNotConfigured = errors.New("provedor de mensagens não configurado")
+	ErrInvalidAPIURL = errors.New("WHATSAPP_API_URL inválida")
+)
+
+// Config representa a configuração do provedor de mensagens do WhatsApp
+type Config struct {
+	APIURL string
+	// PhoneNumberID é o número próprio da plataforma, que não pode ser
+	// vinculado a nenhuma organização. As mensagens saem sempre pelo número
+	// vinculado à organização.
+	PhoneNumberID string
+	AccessToken   string
+	VerifyToken   string
+	AppSecret     string
+}
+
+// Validate verifica se a configuração possui os campos necessários para enviar mensagens
+func (c Config) Validate() error {
+	if c.AccessToken == "" {
+		return fmt.Errorf("%w: variável ausente WHATSAPP_ACCESS_TOKEN", ErrNotConfigured)
+	}
+
+	u, err := url.Parse(c.APIURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ErrInvalidAPIURL
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package whatsapp
+
+import (
+	"context"
+)
+
+// Limites das mensagens interativas da Cloud API
+const (
+	MaxInteractiveBody     = 1024
+	MaxReplyButtons        = 3
+	MaxButtonTitle         = 20
+	MaxListRows            = 10
+	MaxListRowTitle        = 24
+	MaxListRowDescription  = 72
+	MaxInteractiveOptionID = 200
+)
+
+// Interactive é uma mensagem com opções de resposta: botões de resposta
+// rápida ou, com ListButton, uma lista aberta pelo botão com esse texto
+type Interactive struct {
+	Body       string
+	ListButton string
+	Options    []InteractiveOption
+}
+
+// InteractiveOption é um botão ou item da lista; o ID volta no webhook
+// quando o lead escolhe a opção
+type InteractiveOption struct {
+	ID          string
+	Title       string
+	Description string
+}
+
+// SendInteractive envia a mensagem interativa ao número informado (wa_id)
+func (c *Client) SendInteractive(ctx context.Context, phoneNumberID, to string, m Interactive) (string, error) {
+	interactive := map[string]interface{}{
+		"body": map[string]string{"text": m.Body},
+	}
+	if m.ListButton != "" {
+		rows := make([]map[string]string, len(m.Options))
+		for i, o := range m.Options {
+			rows[i] = map[string]string{"id": o.ID, "title": o.Title}
+			if o.Description != "" {
+				rows[i]["description"] = o.Description
+			}
+		}
+		interactive["type"] = "list"
+		interactive["action"] = map[string]interface{}{
+			"button":   m.ListButton,
+			"sections": []map[string]interface{}{{"rows": rows}},
+		}
+	} else {
+		buttons := make([]map[string]interface{}, len(m.Options))
+		for i, o := range m.Options {
+			buttons[i] = map[string]interface{}{
+				"type":  "reply",
+				"reply": map[string]string{"id": o.ID, "title": o.Title},
+			}
+		}
+		interactive["type"] = "button"
+		interactive["action"] = map[string]interface{}{"buttons": buttons}
+	}
+
+	return c.sendMessage(ctx, phoneNumberID, map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              "interactive",
+		"interactive":       interactive,
+	})
+}
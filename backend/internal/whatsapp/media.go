@@ -0,0 +1,238 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// MaxMediaSize é o maior arquivo aceito pela Cloud API (documentos)
+const MaxMediaSize = 100 << 20
+
+// mediaRule é o tipo de mensagem e o tamanho máximo de um tipo MIME
+type mediaRule struct {
+	messageType string
+	maxSize     int64
+}
+
+// mediaRules lista os tipos MIME aceitos pela Cloud API em cada tipo de
+// mensagem, com o tamanho máximo do arquivo
+var mediaRules = map[string]mediaRule{
+	"image/jpeg": {entity.MediaImage, 5 << 20},
+	"image/png":  {entity.MediaImage, 5 << 20},
+	"image/webp": {entity.MediaSticker, 500 << 10},
+
+	"audio/aac":  {entity.MediaAudio, 16 << 20},
+	"audio/amr":  {entity.MediaAudio, 16 << 20},
+	"audio/mpeg": {entity.MediaAudio, 16 << 20},
+	"audio/mp4":  {entity.MediaAudio, 16 << 20},
+	"audio/ogg":  {entity.MediaAudio, 16 << 20},
+
+	"video/mp4":  {entity.MediaVideo, 16 << 20},
+	"video/3gpp": {entity.MediaVideo, 16 << 20},
+
+	"text/plain":                    {entity.MediaDocument, MaxMediaSize},
+	"application/pdf":               {entity.MediaDocument, MaxMediaSize},
+	"application/msword":            {entity.MediaDocument, MaxMediaSize},
+	"application/vnd.ms-excel":      {entity.MediaDocument, MaxMediaSize},
+	"application/vnd.ms-powerpoint": {entity.MediaDocument, MaxMediaSize},
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   {entity.MediaDocument, MaxMediaSize},
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         {entity.MediaDocument, MaxMediaSize},
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": {entity.MediaDocument, MaxMediaSize},
+}
+
+// ErrUnsupportedMedia indica um tipo de arquivo não aceito pelo WhatsApp
+var ErrUnsupportedMedia = errors.New("tipo de arquivo não aceito pelo WhatsApp")
+
+// MediaTooLargeError indica um arquivo acima do limite do seu tipo
+type MediaTooLargeError struct {
+	MessageType string
+	Limit       int64
+}
+
+func (e *MediaTooLargeError) Error() string {
+	return fmt.Sprintf("arquivos de %s são limitados a %s", e.MessageType, formatSize(e.Limit))
+}
+
+// MediaType valida o tipo MIME e o tamanho do arquivo pelos limites da
+// Cloud API e retorna o tipo de mensagem que o envia. Parâmetros do tipo
+// MIME, como "; codecs=opus", são ignorados.
+func MediaType(mimeType string, size int64) (string, error) {
+	mimeType = strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
+	rule, ok := mediaRules[mimeType]
+	if !ok {
+		return "", ErrUnsupportedMedia
+	}
+	if size > rule.maxSize {
+		return "", &MediaTooLargeError{MessageType: rule.messageType, Limit: rule.maxSize}
+	}
+	return rule.messageType, nil
+}
+
+// Inline informa se o tipo MIME é uma imagem, um áudio ou um vídeo aceito
+// pelo WhatsApp, que o navegador pode exibir sem executar o conteúdo.
+// Documentos e tipos desconhecidos devem ser entregues para download.
+func Inline(mimeType string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+	rule, ok := mediaRules[mediaType]
+	return ok && rule.messageType != entity.MediaDocument
+}
+
+// SupportedMediaTypes lista os tipos MIME aceitos no envio de mídia
+func SupportedMediaTypes() []string {
+	types := make([]string, 0, len(mediaRules))
+	for t := range mediaRules {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func formatSize(n int64) string {
+	if n >= 1<<20 {
+		return fmt.Sprintf("%d MB", n>>20)
+	}
+	return fmt.Sprintf("%d KB", n>>10)
+}
+
+// MediaDownloader baixa os arquivos das mídias recebidas
+type MediaDownloader interface {
+	DownloadMedia(ctx context.Context, mediaID string) (*MediaFile, error)
+}
+
+// MediaFile é o arquivo de uma mídia baixada do provedor. Body deve ser fechado.
+type MediaFile struct {
+	MIMEType string
+	SHA256   string
+	Size     int64
+	Body     io.ReadCloser
+}
+
+// OutboundMedia é uma mídia já enviada ao provedor por UploadMedia, a
+// enviar em uma mensagem
+type OutboundMedia struct {
+	// Type é o tipo da mensagem: image, audio, video, document ou sticker
+	Type     string
+	MediaID  string
+	Caption  string
+	FileName string
+}
+
+// DownloadMedia consulta o endereço temporário da mídia e abre o arquivo
+func (c *Client) DownloadMedia(ctx context.Context, mediaID string) (*MediaFile, error) {
+	var info struct {
+		URL      string `json:"url"`
+		MIMEType string `json:"mime_type"`
+		SHA256   string `json:"sha256"`
+		FileSize int64  `json:"file_size"`
+	}
+	if err := c.do(ctx, http.MethodGet, url.PathEscape(mediaID), nil, &info); err != nil {
+		return nil, err
+	}
+	if info.URL == "" {
+		return nil, errors.New("WhatsApp Cloud API: mídia sem endereço de download")
+	}
+	if info.FileSize > MaxMediaSize {
+		return nil, &MediaTooLargeError{MessageType: entity.MediaDocument, Limit: MaxMediaSize}
+	}
+
+	resp, err := c.send(ctx, c.transferClient, http.MethodGet, info.URL, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return &MediaFile{
+		MIMEType: info.MIMEType,
+		SHA256:   info.SHA256,
+		Size:     info.FileSize,
+		Body:     resp.Body,
+	}, nil
+}
+
+// UploadMedia envia o arquivo ao número e retorna o ID da mídia, usado em
+// SendMedia
+func (c *Client) UploadMedia(ctx context.Context, phoneNumberID, fileName, mimeType string, r io.Reader) (string, error) {
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		err := form.WriteField("messaging_product", "whatsapp")
+		if err == nil {
+			err = form.WriteField("type", mimeType)
+		}
+		if err == nil {
+			err = writeFormFile(form, fileName, mimeType, r)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	resp, err := c.send(ctx, c.transferClient, http.MethodPost, url.PathEscape(phoneNumberID)+"/media", form.FormDataContentType(), body)
+	if err != nil {
+		body.CloseWithError(err)
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", err
+	}
+	if result.ID == "" {
+		return "", errors.New("WhatsApp Cloud API: resposta sem ID da mídia")
+	}
+	return result.ID, nil
+}
+
+// SendMedia envia a mídia ao número informado (wa_id)
+func (c *Client) SendMedia(ctx context.Context, phoneNumberID, to string, media OutboundMedia) (string, error) {
+	object := map[string]interface{}{"id": media.MediaID}
+	// Áudios e figurinhas não aceitam legenda; apenas documentos têm nome de arquivo
+	if media.Caption != "" && media.Type != entity.MediaAudio && media.Type != entity.MediaSticker {
+		object["caption"] = media.Caption
+	}
+	if media.FileName != "" && media.Type == entity.MediaDocument {
+		object["filename"] = media.FileName
+	}
+	return c.sendMessage(ctx, phoneNumberID, map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              media.Type,
+		media.Type:          object,
+	})
+}
+
+// writeFormFile grava o arquivo no formulário com o tipo MIME informado,
+// que multipart.Writer.CreateFormFile fixaria em application/octet-stream
+func writeFormFile(form *multipart.Writer, fileName, mimeType string, r io.Reader) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, fileName))
+	header.Set("Content-Type", mimeType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, r)
+	return err
+}
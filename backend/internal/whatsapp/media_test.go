@@ -0,0 +1,30 @@
+package whatsapp
+
+import "testing"
+
+func TestInline(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		want     bool
+	}{
+		{"image/jpeg", true},
+		{"image/png", true},
+		{"image/webp", true},
+		{"audio/ogg; codecs=opus", true},
+		{"AUDIO/MPEG", true},
+		{"video/mp4", true},
+		{"application/pdf", false},
+		{"text/plain", false},
+		{"text/html", false},
+		{"image/svg+xml", false},
+		{"application/octet-stream", false},
+		{"", false},
+		{"imagem inválida", false},
+	}
+
+	for _, tt := range tests {
+		if got := Inline(tt.mimeType); got != tt.want {
+			t.Errorf("Inline(%q) = %v, esperado %v", tt.mimeType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// MessageSender envia mensagens pelo número de WhatsApp da organização
+type MessageSender interface {
+	// SendText envia uma mensagem livre e retorna o ID da mensagem no provedor
+	SendText(ctx context.Context, phoneNumberID, to, body string) (string, error)
+	// SendTemplate envia um modelo aprovado com os valores das variáveis
+	SendTemplate(ctx context.Context, phoneNumberID, to string, t *entity.MessageTemplate, params TemplateParameters) (string, error)
+	// UploadMedia envia um arquivo ao número e retorna o ID da mídia
+	UploadMedia(ctx context.Context, phoneNumberID, fileName, mimeType string, r io.Reader) (string, error)
+	// SendMedia envia uma mídia já enviada por UploadMedia
+	SendMedia(ctx context.Context, phoneNumberID, to string, media OutboundMedia) (string, error)
+	// SendInteractive envia uma mensagem com botões ou lista de opções
+	SendInteractive(ctx context.Context, phoneNumberID, to string, m Interactive) (string, error)
+}
+
+// TemplateParameters são os valores das variáveis de um modelo, na ordem dos
+// marcadores de cada componente
+type TemplateParameters struct {
+	Header []string
+	Body   []string
+	// Buttons associa a posição de cada botão de URL ao valor do marcador
+	Buttons map[int]string
+}
+
+type messageParameter struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type messageComponent struct {
+	Type       string             `json:"type"`
+	SubType    string             `json:"sub_type,omitempty"`
+	Index      string             `json:"index,omitempty"`
+	Parameters []messageParameter `json:"parameters"`
+}
+
+// SendText envia uma mensagem de texto livre ao número informado (wa_id)
+func (c *Client) SendText(ctx context.Context, phoneNumberID, to, body string) (string, error) {
+	return c.sendMessage(ctx, phoneNumberID, map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              "text",
+		"text":              map[string]interface{}{"body": body, "preview_url": false},
+	})
+}
+
+// SendTemplate envia o modelo ao número informado (wa_id) com as variáveis
+// preenchidas
+func (c *Client) SendTemplate(ctx context.Context, phoneNumberID, to string, t *entity.MessageTemplate, params TemplateParameters) (string, error) {
+	var components []messageComponent
+	if len(params.Header) > 0 {
+		components = append(components, messageComponent{Type: "header", Parameters: textParameters(params.Header)})
+	}
+	if len(params.Body) > 0 {
+		components = append(components, messageComponent{Type: "body", Parameters: textParameters(params.Body)})
+	}
+	for i, b := range t.Buttons {
+		value, ok := params.Buttons[i]
+		if !ok || b.Type != entity.TemplateButtonURL {
+			continue
+		}
+		components = append(components, messageComponent{
+			Type:       "button",
+			SubType:    "url",
+			Index:      strconv.Itoa(i),
+			Parameters: textParameters([]string{value}),
+		})
+	}
+
+	template := map[string]interface{}{
+		"name":     t.Name,
+		"language": map[string]string{"code": t.Language},
+	}
+	if len(components) > 0 {
+		template["components"] = components
+	}
+	return c.sendMessage(ctx, phoneNumberID, map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              "template",
+		"template":          template,
+	})
+}
+
+func (c *Client) sendMessage(ctx context.Context, phoneNumberID string, body map[string]interface{}) (string, error) {
+	var result struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	if err := c.do(ctx, http.MethodPost, url.PathEscape(phoneNumberID)+"/messages", body, &result); err != nil {
+		return "", err
+	}
+	if len(result.Messages) == 0 || result.Messages[0].ID == "" {
+		return "", errors.New("WhatsApp Cloud API: resposta sem ID da mensagem")
+	}
+	return result.Messages[0].ID, nil
+}
+
+func textParameters(values []string) []messageParameter {
+	params := make([]messageParameter, len(values))
+	for i, v := range values {
+		params[i] = messageParameter{Type: "text", Text: v}
+	}
+	return params
+}
@@ -0,0 +1,57 @@
+package whatsapp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// PhoneNumberProvider consulta os números de uma conta comercial no provedor
+type PhoneNumberProvider interface {
+	// PhoneNumbers retorna os números da conta comercial com o nível de envio
+	// atual de cada um
+	PhoneNumbers(ctx context.Context, businessAccountID string) ([]PhoneNumber, error)
+}
+
+// PhoneNumber é um número como registrado na conta comercial
+type PhoneNumber struct {
+	ID                 string `json:"id"`
+	DisplayPhoneNumber string `json:"display_phone_number"`
+	VerifiedName       string `json:"verified_name"`
+	MessagingLimitTier string `json:"messaging_limit_tier"`
+}
+
+// MessagingTier converte o nível de envio do provedor para o nível local.
+// Números sem nível informado, como os recém-registrados, ficam no menor
+// nível conhecido depois do inicial.
+func MessagingTier(remote string) string {
+	switch remote {
+	case entity.MessagingTier50, entity.MessagingTier250, entity.MessagingTier1K,
+		entity.MessagingTier10K, entity.MessagingTier100K, entity.MessagingTierUnlimited:
+		return remote
+	default:
+		return entity.MessagingTier250
+	}
+}
+
+// PhoneNumbers percorre todas as páginas de números da conta comercial
+func (c *Client) PhoneNumbers(ctx context.Context, businessAccountID string) ([]PhoneNumber, error) {
+	var numbers []PhoneNumber
+	next := url.PathEscape(businessAccountID) + "/phone_numbers?fields=id,display_phone_number,verified_name,messaging_limit_tier&limit=100"
+	for next != "" {
+		var page struct {
+			Data   []PhoneNumber `json:"data"`
+			Paging struct {
+				Next string `json:"next"`
+			} `json:"paging"`
+		}
+		if err := c.do(ctx, http.MethodGet, next, nil, &page); err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, page.Data...)
+		next = page.Paging.Next
+	}
+	return numbers, nil
+}
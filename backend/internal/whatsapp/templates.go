@@ -0,0 +1,163 @@
+package whatsapp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/whatsapp/backend/internal/models/entity"
+)
+
+// TemplateProvider cadastra e consulta modelos de mensagem no provedor
+type TemplateProvider interface {
+	// SubmitTemplate envia o modelo para aprovação na conta comercial. Modelos
+	// com ExternalID já existem no provedor e são editados.
+	SubmitTemplate(ctx context.Context, businessAccountID string, t *entity.MessageTemplate) (*RemoteTemplate, error)
+	// ListTemplates retorna os modelos da conta comercial com o status atual
+	ListTemplates(ctx context.Context, businessAccountID string) ([]RemoteTemplate, error)
+}
+
+// RemoteTemplate é um modelo como registrado no provedor
+type RemoteTemplate struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Language       string `json:"language"`
+	Status         string `json:"status"`
+	Category       string `json:"category"`
+	RejectedReason string `json:"rejected_reason"`
+}
+
+// TemplateStatus converte o status do provedor para o status local
+func TemplateStatus(remote string) string {
+	switch strings.ToUpper(remote) {
+	case "APPROVED":
+		return entity.TemplateStatusApproved
+	case "REJECTED":
+		return entity.TemplateStatusRejected
+	case "PAUSED":
+		return entity.TemplateStatusPaused
+	case "DISABLED", "PENDING_DELETION", "DELETED", "ARCHIVED":
+		return entity.TemplateStatusDisabled
+	default:
+		return entity.TemplateStatusPending
+	}
+}
+
+type templateComponent struct {
+	Type    string                 `json:"type"`
+	Format  string                 `json:"format,omitempty"`
+	Text    string                 `json:"text,omitempty"`
+	Example map[string]interface{} `json:"example,omitempty"`
+	Buttons []templateButton       `json:"buttons,omitempty"`
+}
+
+type templateButton struct {
+	Type        string   `json:"type"`
+	Text        string   `json:"text"`
+	URL         string   `json:"url,omitempty"`
+	PhoneNumber string   `json:"phone_number,omitempty"`
+	Example     []string `json:"example,omitempty"`
+}
+
+// SubmitTemplate cria o modelo na conta comercial ou, se já existir, edita
+// os componentes para nova análise
+func (c *Client) SubmitTemplate(ctx context.Context, businessAccountID string, t *entity.MessageTemplate) (*RemoteTemplate, error) {
+	components := templateComponents(t)
+
+	var result struct {
+		ID       string `json:"id"`
+		Status   string `json:"status"`
+		Category string `json:"category"`
+		Success  bool   `json:"success"`
+	}
+	if t.ExternalID != "" {
+		body := map[string]interface{}{"category": t.Category, "components": components}
+		if err := c.do(ctx, http.MethodPost, url.PathEscape(t.ExternalID), body, &result); err != nil {
+			return nil, err
+		}
+		// A edição responde apenas {"success": true}; o modelo volta à análise
+		return &RemoteTemplate{ID: t.ExternalID, Name: t.Name, Language: t.Language, Status: "PENDING", Category: t.Category}, nil
+	}
+
+	body := map[string]interface{}{
+		"name":       t.Name,
+		"language":   t.Language,
+		"category":   t.Category,
+		"components": components,
+	}
+	if err := c.do(ctx, http.MethodPost, url.PathEscape(businessAccountID)+"/message_templates", body, &result); err != nil {
+		return nil, err
+	}
+	category := result.Category
+	if category == "" {
+		category = t.Category
+	}
+	return &RemoteTemplate{ID: result.ID, Name: t.Name, Language: t.Language, Status: result.Status, Category: category}, nil
+}
+
+// ListTemplates percorre todas as páginas de modelos da conta comercial
+func (c *Client) ListTemplates(ctx context.Context, businessAccountID string) ([]RemoteTemplate, error) {
+	var templates []RemoteTemplate
+	next := url.PathEscape(businessAccountID) + "/message_templates?fields=id,name,language,status,category,rejected_reason&limit=100"
+	for next != "" {
+		var page struct {
+			Data   []RemoteTemplate `json:"data"`
+			Paging struct {
+				Next string `json:"next"`
+			} `json:"paging"`
+		}
+		if err := c.do(ctx, http.MethodGet, next, nil, &page); err != nil {
+			return nil, err
+		}
+		templates = append(templates, page.Data...)
+		next = page.Paging.Next
+	}
+	return templates, nil
+}
+
+// templateComponents monta os componentes do modelo no formato do provedor,
+// com os exemplos das variáveis exigidos na análise
+func templateComponents(t *entity.MessageTemplate) []templateComponent {
+	var components []templateComponent
+
+	if t.Header != "" {
+		header := templateComponent{Type: "HEADER", Format: "TEXT", Text: t.Header}
+		if len(t.HeaderVariables) > 0 {
+			header.Example = map[string]interface{}{"header_text": variableExamples(t.HeaderVariables)}
+		}
+		components = append(components, header)
+	}
+
+	body := templateComponent{Type: "BODY", Text: t.Body}
+	if len(t.BodyVariables) > 0 {
+		body.Example = map[string]interface{}{"body_text": [][]string{variableExamples(t.BodyVariables)}}
+	}
+	components = append(components, body)
+
+	if t.Footer != "" {
+		components = append(components, templateComponent{Type: "FOOTER", Text: t.Footer})
+	}
+
+	if len(t.Buttons) > 0 {
+		buttons := templateComponent{Type: "BUTTONS"}
+		for _, b := range t.Buttons {
+			button := templateButton{Type: b.Type, Text: b.Text, URL: b.URL, PhoneNumber: b.PhoneNumber}
+			if b.Variable != nil {
+				button.Example = []string{strings.Replace(b.URL, "{{1}}", b.Variable.Example, 1)}
+			}
+			buttons.Buttons = append(buttons.Buttons, button)
+		}
+		components = append(components, buttons)
+	}
+
+	return components
+}
+
+func variableExamples(variables []entity.TemplateVariable) []string {
+	examples := make([]string, len(variables))
+	for i, v := range variables {
+		examples[i] = v.Example
+	}
+	return examples
+}
@@ -0,0 +1,253 @@
+package whatsapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader é o cabeçalho com a assinatura HMAC-SHA256 do corpo dos webhooks
+const SignatureHeader = "X-Hub-Signature-256"
+
+// VerifySignature confere a assinatura enviada pela Meta no cabeçalho
+// X-Hub-Signature-256 ("sha256=<hex>") com o App Secret do aplicativo
+func VerifySignature(appSecret string, body []byte, signature string) bool {
+	if appSecret == "" {
+		return false
+	}
+	hexDigest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	received, err := hex.DecodeString(hexDigest)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+	return hmac.Equal(received, mac.Sum(nil))
+}
+
+// InboundMessage é uma mensagem recebida por um número da organização
+type InboundMessage struct {
+	PhoneNumberID string
+	// From é o wa_id do remetente, sem o sinal de mais
+	From        string
+	ProfileName string
+	ID          string
+	Type        string
+	Body        string
+	// ReplyID é o ID da opção escolhida em botões ou listas interativas, ou
+	// o payload do botão de resposta rápida de um modelo
+	ReplyID   string
+	Timestamp time.Time
+	// Media é o arquivo das mensagens de imagem, áudio, vídeo, documento e
+	// figurinha, a baixar pelo ID
+	Media *InboundMedia
+}
+
+// InboundMedia é o arquivo de uma mensagem recebida, ainda no provedor
+type InboundMedia struct {
+	ID       string
+	MIMEType string
+	SHA256   string
+	FileName string
+}
+
+// StatusUpdate é a confirmação de entrega ou leitura de uma mensagem enviada
+type StatusUpdate struct {
+	PhoneNumberID string
+	MessageID     string
+	Status        string
+	Timestamp     time.Time
+}
+
+// WebhookEvents reúne as mensagens e atualizações de status de uma notificação
+type WebhookEvents struct {
+	Messages []InboundMessage
+	Statuses []StatusUpdate
+}
+
+type webhookPayload struct {
+	Object string `json:"object"`
+	Entry  []struct {
+		Changes []struct {
+			Field string `json:"field"`
+			Value struct {
+				Metadata struct {
+					PhoneNumberID string `json:"phone_number_id"`
+				} `json:"metadata"`
+				Contacts []struct {
+					WaID    string `json:"wa_id"`
+					Profile struct {
+						Name string `json:"name"`
+					} `json:"profile"`
+				} `json:"contacts"`
+				Messages []webhookMessage `json:"messages"`
+				Statuses []struct {
+					ID        string `json:"id"`
+					Status    string `json:"status"`
+					Timestamp string `json:"timestamp"`
+				} `json:"statuses"`
+			} `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+type webhookMessage struct {
+	From      string `json:"from"`
+	ID        string `json:"id"`
+	Timestamp string `json:"timestamp"`
+	Type      string `json:"type"`
+	Text      struct {
+		Body string `json:"body"`
+	} `json:"text"`
+	Button struct {
+		Text    string `json:"text"`
+		Payload string `json:"payload"`
+	} `json:"button"`
+	Interactive struct {
+		ButtonReply struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"button_reply"`
+		ListReply struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"list_reply"`
+	} `json:"interactive"`
+	Image    webhookMedia `json:"image"`
+	Audio    webhookMedia `json:"audio"`
+	Video    webhookMedia `json:"video"`
+	Document webhookMedia `json:"document"`
+	Sticker  webhookMedia `json:"sticker"`
+}
+
+type webhookMedia struct {
+	ID       string `json:"id"`
+	MIMEType string `json:"mime_type"`
+	SHA256   string `json:"sha256"`
+	Caption  string `json:"caption"`
+	Filename string `json:"filename"`
+}
+
+// ParseWebhook extrai as mensagens e os status de uma notificação da
+// WhatsApp Cloud API. Campos diferentes de "messages" são ignorados.
+func ParseWebhook(body []byte) (*WebhookEvents, error) {
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	events := &WebhookEvents{}
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "" && change.Field != "messages" {
+				continue
+			}
+			value := change.Value
+			names := make(map[string]string, len(value.Contacts))
+			for _, c := range value.Contacts {
+				names[c.WaID] = c.Profile.Name
+			}
+
+			for _, m := range value.Messages {
+				events.Messages = append(events.Messages, InboundMessage{
+					PhoneNumberID: value.Metadata.PhoneNumberID,
+					From:          m.From,
+					ProfileName:   names[m.From],
+					ID:            m.ID,
+					Type:          m.Type,
+					Body:          m.text(),
+					ReplyID:       m.replyID(),
+					Timestamp:     parseTimestamp(m.Timestamp),
+					Media:         m.media(),
+				})
+			}
+			for _, s := range value.Statuses {
+				events.Statuses = append(events.Statuses, StatusUpdate{
+					PhoneNumberID: value.Metadata.PhoneNumberID,
+					MessageID:     s.ID,
+					Status:        s.Status,
+					Timestamp:     parseTimestamp(s.Timestamp),
+				})
+			}
+		}
+	}
+	return events, nil
+}
+
+// text retorna o conteúdo textual da mensagem conforme o tipo
+func (m webhookMessage) text() string {
+	switch m.Type {
+	case "text":
+		return m.Text.Body
+	case "button":
+		return m.Button.Text
+	case "interactive":
+		if m.Interactive.ButtonReply.Title != "" {
+			return m.Interactive.ButtonReply.Title
+		}
+		return m.Interactive.ListReply.Title
+	case "image":
+		return m.Image.Caption
+	case "video":
+		return m.Video.Caption
+	case "document":
+		return m.Document.Caption
+	}
+	return ""
+}
+
+// replyID retorna o identificador da opção escolhida nas respostas a
+// botões e listas
+func (m webhookMessage) replyID() string {
+	switch m.Type {
+	case "button":
+		return m.Button.Payload
+	case "interactive":
+		if m.Interactive.ButtonReply.ID != "" {
+			return m.Interactive.ButtonReply.ID
+		}
+		return m.Interactive.ListReply.ID
+	}
+	return ""
+}
+
+// media retorna o arquivo das mensagens com mídia
+func (m webhookMessage) media() *InboundMedia {
+	var media webhookMedia
+	switch m.Type {
+	case "image":
+		media = m.Image
+	case "audio":
+		media = m.Audio
+	case "video":
+		media = m.Video
+	case "document":
+		media = m.Document
+	case "sticker":
+		media = m.Sticker
+	default:
+		return nil
+	}
+	if media.ID == "" {
+		return nil
+	}
+	return &InboundMedia{ID: media.ID, MIMEType: media.MIMEType, SHA256: media.SHA256, FileName: media.Filename}
+}
+
+// parseTimestamp converte o horário Unix em segundos enviado pela API,
+// usando o horário atual se ausente ou inválido
+func parseTimestamp(value string) time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Now().UTC()
+	}
+	return time.Unix(seconds, 0).UTC()
+}
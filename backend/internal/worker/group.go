@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/whatsapp/backend/internal/logger"
+)
+
+// Func representa um worker em segundo plano. Ele deve retornar assim que o
+// contexto for cancelado, após concluir o item que estiver processando.
+type Func func(ctx context.Context)
+
+// Group gerencia o ciclo de vida dos workers em segundo plano da aplicação
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewGroup cria um novo grupo de workers
+func NewGroup() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Context retorna o contexto compartilhado pelos workers, cancelado no desligamento
+func (g *Group) Context() context.Context {
+	return g.ctx
+}
+
+// Go inicia um worker identificado pelo nome
+func (g *Group) Go(name string, fn Func) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer func() {
+			if rec := recover(); rec != nil {
+				logger.Error("Worker finalizado com panic", map[string]interface{}{
+					"worker": name,
+					"panic":  fmt.Sprint(rec),
+				})
+			}
+		}()
+
+		logger.Info(fmt.Sprintf("Worker %s iniciado", name))
+		fn(g.ctx)
+		logger.Info(fmt.Sprintf("Worker %s finalizado", name))
+	}()
+}
+
+// Shutdown sinaliza o encerramento aos workers e aguarda até que todos
+// terminem ou até que o contexto informado expire
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
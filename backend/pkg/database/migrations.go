@@ -0,0 +1,954 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/whatsapp/backend/internal/logger"
+	"github.com/whatsapp/backend/pkg/phone"
+)
+
+// Migration representa uma alteração versionada do esquema do banco de dados
+type Migration struct {
+	Version     int
+	Description string
+	SQL         string
+	// Data é uma correção de dados que precisa de código Go, executada depois
+	// do SQL na mesma transação
+	Data func(ctx context.Context, tx *sql.Tx) error
+}
+
+// migrations lista as migrações em ordem crescente de versão.
+// Novas migrações devem ser adicionadas sempre ao final da lista.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "criar tabela de usuários",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS users (
+				id SERIAL PRIMARY KEY,
+				name VARCHAR(100) NOT NULL,
+				email VARCHAR(100) NOT NULL UNIQUE,
+				password VARCHAR(100) NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)
+		`,
+	},
+	{
+		Version:     2,
+		Description: "criar tabela de refresh tokens",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS refresh_tokens (
+				id SERIAL PRIMARY KEY,
+				user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				token VARCHAR(255) NOT NULL UNIQUE,
+				expires_at TIMESTAMP NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				is_valid BOOLEAN NOT NULL DEFAULT TRUE
+			)
+		`,
+	},
+	{
+		Version:     3,
+		Description: "criar organizações e vincular usuários",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS organizations (
+				id SERIAL PRIMARY KEY,
+				name VARCHAR(100) NOT NULL,
+				created_at TIMESTAMP NOT NULL
+			);
+
+			ALTER TABLE users ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE;
+
+			-- Usuários existentes recebem uma organização própria
+			DO $$
+			DECLARE
+				u RECORD;
+				org_id INTEGER;
+			BEGIN
+				FOR u IN SELECT id, name FROM users WHERE organization_id IS NULL LOOP
+					INSERT INTO organizations (name, created_at) VALUES (u.name, NOW()) RETURNING id INTO org_id;
+					UPDATE users SET organization_id = org_id WHERE id = u.id;
+				END LOOP;
+			END $$;
+
+			CREATE INDEX IF NOT EXISTS idx_users_organization ON users(organization_id);
+		`,
+	},
+	{
+		Version:     4,
+		Description: "criar tabela de leads",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS leads (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				owner_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				name VARCHAR(100) NOT NULL DEFAULT '',
+				phone VARCHAR(20) NOT NULL,
+				email VARCHAR(100) NOT NULL DEFAULT '',
+				source VARCHAR(50) NOT NULL DEFAULT '',
+				status VARCHAR(20) NOT NULL DEFAULT 'new',
+				stage VARCHAR(50) NOT NULL DEFAULT '',
+				custom_fields JSONB NOT NULL DEFAULT '{}',
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_leads_organization_created ON leads(organization_id, created_at DESC);
+			CREATE INDEX IF NOT EXISTS idx_leads_organization_phone ON leads(organization_id, phone);
+		`,
+	},
+	{
+		Version:     5,
+		Description: "criar tabela de importações de leads",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS lead_imports (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				file_name VARCHAR(255) NOT NULL,
+				file_type VARCHAR(10) NOT NULL,
+				file_data BYTEA,
+				mapping JSONB NOT NULL,
+				status VARCHAR(20) NOT NULL DEFAULT 'pending',
+				total_rows INTEGER NOT NULL DEFAULT 0,
+				processed_rows INTEGER NOT NULL DEFAULT 0,
+				created_rows INTEGER NOT NULL DEFAULT 0,
+				failed_rows INTEGER NOT NULL DEFAULT 0,
+				errors JSONB NOT NULL DEFAULT '[]',
+				error_message TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL,
+				started_at TIMESTAMP,
+				finished_at TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_lead_imports_organization ON lead_imports(organization_id, created_at DESC);
+			CREATE INDEX IF NOT EXISTS idx_lead_imports_queue ON lead_imports(status, updated_at)
+				WHERE status IN ('pending', 'processing');
+		`,
+	},
+	{
+		Version:     6,
+		Description: "criar etiquetas de leads, última mensagem e registro de exportações",
+		SQL: `
+			ALTER TABLE leads ADD COLUMN IF NOT EXISTS last_message_at TIMESTAMP;
+
+			CREATE TABLE IF NOT EXISTS tags (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				name VARCHAR(50) NOT NULL,
+				created_at TIMESTAMP NOT NULL
+			);
+
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_organization_name ON tags(organization_id, LOWER(name));
+
+			CREATE TABLE IF NOT EXISTS lead_tags (
+				lead_id INTEGER NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+				tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+				created_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (lead_id, tag_id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_lead_tags_tag ON lead_tags(tag_id);
+
+			CREATE TABLE IF NOT EXISTS lead_exports (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				format VARCHAR(10) NOT NULL,
+				columns JSONB NOT NULL,
+				filters JSONB NOT NULL,
+				async BOOLEAN NOT NULL DEFAULT FALSE,
+				status VARCHAR(20) NOT NULL DEFAULT 'pending',
+				row_count INTEGER NOT NULL DEFAULT 0,
+				file_data BYTEA,
+				file_size BIGINT NOT NULL DEFAULT 0,
+				error_message TEXT NOT NULL DEFAULT '',
+				ip_address VARCHAR(45) NOT NULL DEFAULT '',
+				user_agent VARCHAR(255) NOT NULL DEFAULT '',
+				download_count INTEGER NOT NULL DEFAULT 0,
+				last_downloaded_at TIMESTAMP,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL,
+				finished_at TIMESTAMP,
+				expires_at TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_lead_exports_organization ON lead_exports(organization_id, created_at DESC);
+			CREATE INDEX IF NOT EXISTS idx_lead_exports_queue ON lead_exports(status, updated_at)
+				WHERE status IN ('pending', 'processing');
+		`,
+	},
+	{
+		Version:     7,
+		Description: "criar redirecionamentos de leads mesclados e trilha de auditoria",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS lead_redirects (
+				old_lead_id INTEGER PRIMARY KEY,
+				lead_id INTEGER NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				merged_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_lead_redirects_lead ON lead_redirects(lead_id);
+
+			CREATE TABLE IF NOT EXISTS audit_logs (
+				id BIGSERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				action VARCHAR(50) NOT NULL,
+				entity_type VARCHAR(50) NOT NULL,
+				entity_id BIGINT NOT NULL,
+				data JSONB NOT NULL DEFAULT '{}',
+				ip_address VARCHAR(45) NOT NULL DEFAULT '',
+				created_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs(organization_id, entity_type, entity_id, created_at DESC);
+		`,
+	},
+	{
+		Version:     8,
+		Description: "criar definições de campos personalizados e índice GIN dos valores",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS custom_field_definitions (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				key VARCHAR(50) NOT NULL,
+				label VARCHAR(100) NOT NULL,
+				type VARCHAR(20) NOT NULL,
+				options JSONB NOT NULL DEFAULT '[]',
+				required BOOLEAN NOT NULL DEFAULT FALSE,
+				position INTEGER NOT NULL DEFAULT 0,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL,
+				UNIQUE (organization_id, key)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_leads_custom_fields ON leads USING GIN (custom_fields jsonb_path_ops);
+		`,
+	},
+	{
+		Version:     9,
+		Description: "criar segmentos salvos de leads",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS segments (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				name VARCHAR(100) NOT NULL,
+				description VARCHAR(500) NOT NULL DEFAULT '',
+				filters JSONB NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			);
+
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_segments_organization_name ON segments(organization_id, LOWER(name));
+		`,
+	},
+	{
+		Version:     10,
+		Description: "criar conversas, mensagens e notas com busca textual em português",
+		SQL: `
+			CREATE EXTENSION IF NOT EXISTS unaccent;
+
+			-- Português sem acentos: "informação" e "informacao" geram o mesmo lexema
+			DO $$
+			BEGIN
+				IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'pt_unaccent') THEN
+					CREATE TEXT SEARCH CONFIGURATION pt_unaccent (COPY = portuguese);
+					ALTER TEXT SEARCH CONFIGURATION pt_unaccent
+						ALTER MAPPING FOR hword, hword_part, word WITH unaccent, portuguese_stem;
+				END IF;
+			END
+			$$;
+
+			ALTER TABLE leads ADD COLUMN IF NOT EXISTS search tsvector
+				GENERATED ALWAYS AS (to_tsvector('pt_unaccent', name)) STORED;
+			CREATE INDEX IF NOT EXISTS idx_leads_search ON leads USING GIN (search);
+
+			CREATE TABLE IF NOT EXISTS conversations (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				lead_id INTEGER NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+				assigned_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				status VARCHAR(20) NOT NULL DEFAULT 'open',
+				last_message_at TIMESTAMP,
+				last_inbound_at TIMESTAMP,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL,
+				closed_at TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_conversations_lead ON conversations(lead_id);
+			CREATE INDEX IF NOT EXISTS idx_conversations_organization_status ON conversations(organization_id, status, last_message_at DESC);
+
+			CREATE TABLE IF NOT EXISTS messages (
+				id BIGSERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+				lead_id INTEGER NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+				user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				direction VARCHAR(10) NOT NULL,
+				type VARCHAR(20) NOT NULL DEFAULT 'text',
+				body TEXT NOT NULL DEFAULT '',
+				external_id VARCHAR(100) NOT NULL DEFAULT '',
+				status VARCHAR(20) NOT NULL DEFAULT '',
+				created_at TIMESTAMP NOT NULL,
+				search tsvector GENERATED ALWAYS AS (to_tsvector('pt_unaccent', body)) STORED
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages(conversation_id, created_at);
+			CREATE INDEX IF NOT EXISTS idx_messages_lead ON messages(lead_id);
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_external ON messages(organization_id, external_id)
+				WHERE external_id <> '';
+			CREATE INDEX IF NOT EXISTS idx_messages_search ON messages USING GIN (search);
+
+			CREATE TABLE IF NOT EXISTS lead_notes (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				lead_id INTEGER NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+				user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				body TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL,
+				search tsvector GENERATED ALWAYS AS (to_tsvector('pt_unaccent', body)) STORED
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_lead_notes_lead ON lead_notes(lead_id, created_at DESC);
+			CREATE INDEX IF NOT EXISTS idx_lead_notes_search ON lead_notes USING GIN (search);
+		`,
+	},
+	{
+		Version:     11,
+		Description: "criar menções em notas, tarefas e notificações",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS note_mentions (
+				note_id INTEGER NOT NULL REFERENCES lead_notes(id) ON DELETE CASCADE,
+				user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				PRIMARY KEY (note_id, user_id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_note_mentions_user ON note_mentions(user_id);
+
+			CREATE TABLE IF NOT EXISTS tasks (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				lead_id INTEGER NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+				created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				assignee_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				title VARCHAR(200) NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				status VARCHAR(20) NOT NULL DEFAULT 'open',
+				due_at TIMESTAMP NOT NULL,
+				remind_at TIMESTAMP NOT NULL,
+				reminder_sent_at TIMESTAMP,
+				completed_at TIMESTAMP,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_tasks_lead ON tasks(lead_id, due_at);
+			CREATE INDEX IF NOT EXISTS idx_tasks_assignee ON tasks(assignee_id, status, due_at);
+			CREATE INDEX IF NOT EXISTS idx_tasks_reminders ON tasks(remind_at)
+				WHERE status = 'open' AND reminder_sent_at IS NULL;
+
+			CREATE TABLE IF NOT EXISTS notifications (
+				id BIGSERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				type VARCHAR(50) NOT NULL,
+				title VARCHAR(255) NOT NULL,
+				body TEXT NOT NULL DEFAULT '',
+				data JSONB NOT NULL DEFAULT '{}',
+				read_at TIMESTAMP,
+				created_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, created_at DESC);
+			CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id)
+				WHERE read_at IS NULL;
+		`,
+	},
+	{
+		Version:     12,
+		Description: "criar histórico de atividades dos leads",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS lead_activities (
+				id BIGSERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				lead_id INTEGER NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+				user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				type VARCHAR(50) NOT NULL,
+				data JSONB NOT NULL DEFAULT '{}',
+				created_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_lead_activities_lead ON lead_activities(lead_id, created_at DESC, id DESC);
+
+			-- O histórico é somente de inclusão: só a transferência para o
+			-- sobrevivente de uma mesclagem e a remoção do autor são aceitas
+			CREATE OR REPLACE FUNCTION lead_activities_append_only() RETURNS trigger AS $$
+			BEGIN
+				IF NEW.id <> OLD.id
+					OR NEW.organization_id <> OLD.organization_id
+					OR NEW.type <> OLD.type
+					OR NEW.data <> OLD.data
+					OR NEW.created_at <> OLD.created_at
+					OR (NEW.user_id IS DISTINCT FROM OLD.user_id AND NEW.user_id IS NOT NULL) THEN
+					RAISE EXCEPTION 'lead_activities não pode ser alterada';
+				END IF;
+				RETURN NEW;
+			END;
+			$$ LANGUAGE plpgsql;
+
+			DROP TRIGGER IF EXISTS lead_activities_append_only ON lead_activities;
+			CREATE TRIGGER lead_activities_append_only BEFORE UPDATE ON lead_activities
+				FOR EACH ROW EXECUTE FUNCTION lead_activities_append_only();
+
+			-- Histórico inicial a partir dos registros existentes
+			INSERT INTO lead_activities (organization_id, lead_id, type, data, created_at)
+			SELECT organization_id, id, 'lead.created', jsonb_build_object('source', source), created_at
+			FROM leads;
+
+			INSERT INTO lead_activities (organization_id, lead_id, user_id, type, data, created_at)
+			SELECT organization_id, lead_id, user_id,
+				CASE WHEN direction = 'inbound' THEN 'message.inbound' ELSE 'message.outbound' END,
+				jsonb_build_object('message_id', id, 'conversation_id', conversation_id, 'message_type', type, 'body', LEFT(body, 200)),
+				created_at
+			FROM messages;
+
+			INSERT INTO lead_activities (organization_id, lead_id, user_id, type, data, created_at)
+			SELECT organization_id, lead_id, user_id, 'note.created',
+				jsonb_build_object('note_id', id, 'body', LEFT(body, 200)), created_at
+			FROM lead_notes;
+
+			INSERT INTO lead_activities (organization_id, lead_id, user_id, type, data, created_at)
+			SELECT organization_id, lead_id, created_by, 'task.created',
+				jsonb_build_object('task_id', id, 'title', title, 'due_at', due_at, 'assignee_id', assignee_id), created_at
+			FROM tasks;
+
+			INSERT INTO lead_activities (organization_id, lead_id, user_id, type, data, created_at)
+			SELECT organization_id, lead_id, assignee_id, 'task.completed',
+				jsonb_build_object('task_id', id, 'title', title), completed_at
+			FROM tasks
+			WHERE status = 'done' AND completed_at IS NOT NULL;
+		`,
+	},
+	{
+		Version:     13,
+		Description: "criar canal do WhatsApp por organização e distribuição automática de leads",
+		SQL: `
+			ALTER TABLE organizations ADD COLUMN IF NOT EXISTS whatsapp_phone_number_id VARCHAR(50);
+
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_whatsapp_phone_number
+				ON organizations(whatsapp_phone_number_id) WHERE whatsapp_phone_number_id IS NOT NULL;
+
+			CREATE TABLE IF NOT EXISTS assignment_settings (
+				organization_id INTEGER PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+				enabled BOOLEAN NOT NULL DEFAULT FALSE,
+				strategy VARCHAR(20) NOT NULL DEFAULT 'round_robin',
+				rules JSONB NOT NULL DEFAULT '[]',
+				updated_at TIMESTAMP NOT NULL
+			);
+
+			CREATE TABLE IF NOT EXISTS agent_settings (
+				user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				available BOOLEAN NOT NULL DEFAULT TRUE,
+				skills TEXT[] NOT NULL DEFAULT '{}',
+				max_open_conversations INTEGER NOT NULL DEFAULT 0,
+				last_assigned_at TIMESTAMP,
+				updated_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_agent_settings_organization ON agent_settings(organization_id);
+			CREATE INDEX IF NOT EXISTS idx_conversations_assigned_open ON conversations(assigned_user_id)
+				WHERE status = 'open';
+		`,
+	},
+	{
+		Version:     14,
+		Description: "criar modelos de mensagem do WhatsApp",
+		SQL: `
+			ALTER TABLE organizations ADD COLUMN IF NOT EXISTS whatsapp_business_account_id VARCHAR(50);
+
+			CREATE TABLE IF NOT EXISTS message_templates (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				name VARCHAR(512) NOT NULL,
+				language VARCHAR(15) NOT NULL,
+				category VARCHAR(20) NOT NULL,
+				header TEXT NOT NULL DEFAULT '',
+				header_variables JSONB NOT NULL DEFAULT '[]',
+				body TEXT NOT NULL,
+				body_variables JSONB NOT NULL DEFAULT '[]',
+				footer TEXT NOT NULL DEFAULT '',
+				buttons JSONB NOT NULL DEFAULT '[]',
+				status VARCHAR(20) NOT NULL DEFAULT 'draft',
+				external_id VARCHAR(100) NOT NULL DEFAULT '',
+				rejection_reason TEXT NOT NULL DEFAULT '',
+				created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				submitted_at TIMESTAMP,
+				synced_at TIMESTAMP,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			);
+
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_message_templates_name
+				ON message_templates(organization_id, name, language);
+			CREATE INDEX IF NOT EXISTS idx_message_templates_pending
+				ON message_templates(organization_id) WHERE status = 'pending';
+		`,
+	},
+	{
+		Version:     15,
+		Description: "criar campanhas de disparo de modelos com status por destinatário",
+		SQL: `
+			ALTER TABLE organizations ADD COLUMN IF NOT EXISTS whatsapp_messaging_tier VARCHAR(20) NOT NULL DEFAULT 'TIER_250';
+
+			CREATE TABLE IF NOT EXISTS campaigns (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				name VARCHAR(200) NOT NULL,
+				template_id INTEGER NOT NULL REFERENCES message_templates(id) ON DELETE RESTRICT,
+				segment_id INTEGER REFERENCES segments(id) ON DELETE SET NULL,
+				filters JSONB NOT NULL DEFAULT '{}',
+				status VARCHAR(20) NOT NULL DEFAULT 'draft',
+				scheduled_at TIMESTAMP,
+				started_at TIMESTAMP,
+				completed_at TIMESTAMP,
+				error TEXT NOT NULL DEFAULT '',
+				created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_campaigns_organization ON campaigns(organization_id, created_at DESC);
+			CREATE INDEX IF NOT EXISTS idx_campaigns_due ON campaigns(scheduled_at) WHERE status = 'scheduled';
+
+			CREATE TABLE IF NOT EXISTS campaign_recipients (
+				id BIGSERIAL PRIMARY KEY,
+				campaign_id INTEGER NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				lead_id INTEGER NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+				status VARCHAR(20) NOT NULL DEFAULT 'pending',
+				attempts INTEGER NOT NULL DEFAULT 0,
+				next_attempt_at TIMESTAMP NOT NULL,
+				message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+				error TEXT NOT NULL DEFAULT '',
+				sent_at TIMESTAMP,
+				delivered_at TIMESTAMP,
+				read_at TIMESTAMP,
+				replied_at TIMESTAMP,
+				failed_at TIMESTAMP,
+				updated_at TIMESTAMP NOT NULL,
+				UNIQUE (campaign_id, lead_id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_campaign_recipients_pending ON campaign_recipients(campaign_id, next_attempt_at)
+				WHERE status = 'pending';
+			CREATE INDEX IF NOT EXISTS idx_campaign_recipients_message ON campaign_recipients(message_id)
+				WHERE message_id IS NOT NULL;
+			CREATE INDEX IF NOT EXISTS idx_campaign_recipients_lead ON campaign_recipients(lead_id, sent_at);
+		`,
+	},
+	{
+		Version:     16,
+		Description: "criar consentimentos dos leads por canal",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS lead_consents (
+				id BIGSERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				lead_id INTEGER NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+				channel VARCHAR(20) NOT NULL,
+				status VARCHAR(20) NOT NULL,
+				source VARCHAR(30) NOT NULL,
+				evidence TEXT NOT NULL DEFAULT '',
+				message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+				user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				created_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_lead_consents_lead ON lead_consents(lead_id, channel, created_at DESC, id DESC);
+		`,
+	},
+	{
+		Version:     17,
+		Description: "criar mídias das mensagens",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS message_media (
+				id BIGSERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				message_id BIGINT NOT NULL UNIQUE REFERENCES messages(id) ON DELETE CASCADE,
+				status VARCHAR(20) NOT NULL,
+				provider_media_id VARCHAR(100) NOT NULL DEFAULT '',
+				storage_key TEXT NOT NULL DEFAULT '',
+				mime_type VARCHAR(255) NOT NULL DEFAULT '',
+				file_name VARCHAR(255) NOT NULL DEFAULT '',
+				size BIGINT NOT NULL DEFAULT 0,
+				sha256 VARCHAR(64) NOT NULL DEFAULT '',
+				attempts INTEGER NOT NULL DEFAULT 0,
+				next_attempt_at TIMESTAMP NOT NULL,
+				error TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_message_media_pending ON message_media(next_attempt_at)
+				WHERE status = 'pending';
+		`,
+	},
+	{
+		Version:     18,
+		Description: "criar regras de automação e registro das execuções",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS automation_rules (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				name VARCHAR(200) NOT NULL,
+				position INTEGER NOT NULL,
+				enabled BOOLEAN NOT NULL DEFAULT TRUE,
+				conditions JSONB NOT NULL DEFAULT '[]',
+				actions JSONB NOT NULL DEFAULT '[]',
+				stop_processing BOOLEAN NOT NULL DEFAULT FALSE,
+				cooldown_minutes INTEGER NOT NULL DEFAULT 60,
+				created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_automation_rules_organization ON automation_rules(organization_id, position, id);
+
+			CREATE TABLE IF NOT EXISTS automation_runs (
+				id BIGSERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				rule_id INTEGER NOT NULL REFERENCES automation_rules(id) ON DELETE CASCADE,
+				conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+				lead_id INTEGER NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+				message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+				results JSONB NOT NULL DEFAULT '[]',
+				replies INTEGER NOT NULL DEFAULT 0,
+				created_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_automation_runs_conversation ON automation_runs(conversation_id, created_at DESC);
+			CREATE INDEX IF NOT EXISTS idx_automation_runs_rule ON automation_runs(rule_id, created_at DESC);
+		`,
+	},
+	{
+		Version:     19,
+		Description: "criar fluxos do chatbot e sessões por conversa",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS chatbot_flows (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				name VARCHAR(200) NOT NULL,
+				enabled BOOLEAN NOT NULL DEFAULT TRUE,
+				start_node VARCHAR(50) NOT NULL,
+				nodes JSONB NOT NULL DEFAULT '[]',
+				timeout_minutes INTEGER NOT NULL DEFAULT 60,
+				fallback_node VARCHAR(50) NOT NULL DEFAULT '',
+				created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_chatbot_flows_organization ON chatbot_flows(organization_id, name);
+
+			CREATE TABLE IF NOT EXISTS chatbot_sessions (
+				id BIGSERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				flow_id INTEGER NOT NULL REFERENCES chatbot_flows(id) ON DELETE CASCADE,
+				conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+				lead_id INTEGER NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+				status VARCHAR(20) NOT NULL DEFAULT 'active',
+				current_node VARCHAR(50) NOT NULL,
+				attempts INTEGER NOT NULL DEFAULT 0,
+				fallback BOOLEAN NOT NULL DEFAULT FALSE,
+				answers JSONB NOT NULL DEFAULT '{}',
+				expires_at TIMESTAMP,
+				error TEXT NOT NULL DEFAULT '',
+				started_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL,
+				ended_at TIMESTAMP
+			);
+
+			-- No máximo uma sessão ativa por conversa
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_chatbot_sessions_active ON chatbot_sessions(conversation_id) WHERE status = 'active';
+			CREATE INDEX IF NOT EXISTS idx_chatbot_sessions_expires ON chatbot_sessions(expires_at) WHERE status = 'active';
+			CREATE INDEX IF NOT EXISTS idx_chatbot_sessions_flow ON chatbot_sessions(flow_id, started_at DESC);
+		`,
+	},
+	{
+		Version:     20,
+		Description: "criar calendários de atendimento e vincular às campanhas",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS business_calendars (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				name VARCHAR(100) NOT NULL,
+				is_default BOOLEAN NOT NULL DEFAULT FALSE,
+				time_zone VARCHAR(64) NOT NULL,
+				days JSONB NOT NULL DEFAULT '[]',
+				national_holidays BOOLEAN NOT NULL DEFAULT TRUE,
+				optional_holidays BOOLEAN NOT NULL DEFAULT FALSE,
+				holidays JSONB NOT NULL DEFAULT '[]',
+				created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			);
+
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_business_calendars_name ON business_calendars(organization_id, LOWER(name));
+			-- No máximo um calendário padrão por organização
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_business_calendars_default ON business_calendars(organization_id) WHERE is_default;
+
+			ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS calendar_id INTEGER REFERENCES business_calendars(id) ON DELETE SET NULL;
+		`,
+	},
+	{
+		Version:     21,
+		Description: "criar respostas rápidas e seus anexos",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS quick_replies (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+				shortcut VARCHAR(31) NOT NULL,
+				title VARCHAR(100) NOT NULL,
+				body TEXT NOT NULL DEFAULT '',
+				usage_count INTEGER NOT NULL DEFAULT 0,
+				last_used_at TIMESTAMP,
+				created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			);
+
+			-- Atalhos únicos na organização e entre as respostas pessoais de cada atendente
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_quick_replies_shortcut ON quick_replies(organization_id, COALESCE(user_id, 0), shortcut);
+
+			CREATE TABLE IF NOT EXISTS quick_reply_attachments (
+				id BIGSERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				quick_reply_id INTEGER NOT NULL REFERENCES quick_replies(id) ON DELETE CASCADE,
+				storage_key TEXT NOT NULL,
+				mime_type VARCHAR(255) NOT NULL,
+				file_name VARCHAR(255) NOT NULL DEFAULT '',
+				size BIGINT NOT NULL,
+				sha256 VARCHAR(64) NOT NULL,
+				created_at TIMESTAMP NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_quick_reply_attachments_reply ON quick_reply_attachments(quick_reply_id, id);
+		`,
+	},
+	{
+		Version:     22,
+		Description: "adicionar papéis dos usuários",
+		SQL: `
+			-- Os usuários existentes criaram a própria organização e a administram
+			ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'admin';
+			ALTER TABLE users ALTER COLUMN role SET DEFAULT 'agent';
+		`,
+	},
+	{
+		Version:     23,
+		Description: "copiar a última mensagem recebida do lead para as conversas abertas",
+		SQL: `
+			-- A janela de atendimento é do lead: conversas abertas depois do
+			-- encerramento da anterior herdam a última mensagem recebida
+			UPDATE conversations c
+			SET last_inbound_at = (
+				SELECT MAX(p.last_inbound_at) FROM conversations p WHERE p.lead_id = c.lead_id AND p.id < c.id
+			)
+			WHERE c.status = 'open' AND c.last_inbound_at IS NULL;
+		`,
+	},
+	{
+		Version:     24,
+		Description: "normalizar os telefones dos leads gravados antes do formato canônico",
+		Data:        normalizeLeadPhones,
+	},
+	{
+		Version:     25,
+		Description: "criar tabela de convites para a organização",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS organization_invites (
+				id SERIAL PRIMARY KEY,
+				organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				email VARCHAR(100) NOT NULL,
+				role VARCHAR(20) NOT NULL,
+				token_hash VARCHAR(64) NOT NULL UNIQUE,
+				invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				expires_at TIMESTAMP NOT NULL,
+				accepted_at TIMESTAMP,
+				created_at TIMESTAMP NOT NULL
+			);
+
+			-- No máximo um convite pendente por email em cada organização
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_organization_invites_pending ON organization_invites(organization_id, LOWER(email)) WHERE accepted_at IS NULL;
+		`,
+	},
+	{
+		Version:     26,
+		Description: "adicionar versão às sessões do chatbot",
+		SQL: `
+			ALTER TABLE chatbot_sessions ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 0;
+		`,
+	},
+	{
+		Version:     27,
+		Description: "guardar os arquivos das exportações no armazenamento",
+		SQL: `
+			ALTER TABLE lead_exports ADD COLUMN IF NOT EXISTS storage_key VARCHAR(255) NOT NULL DEFAULT '';
+
+			-- Os arquivos guardados no banco não são copiados: as exportações
+			-- disponíveis expiram e podem ser refeitas
+			UPDATE lead_exports SET status = 'expired', updated_at = NOW()
+			WHERE status = 'completed' AND async AND file_data IS NOT NULL;
+
+			ALTER TABLE lead_exports DROP COLUMN IF EXISTS file_data;
+		`,
+	},
+}
+
+// LatestMigrationVersion retorna a versão da última migração conhecida pela aplicação
+func LatestMigrationVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// CurrentMigrationVersion retorna a versão da última migração aplicada no banco de dados
+func CurrentMigrationVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// MigrateTables aplica as migrações pendentes, cada uma em sua própria transação
+func MigrateTables(db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			description VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		logger.Error("Erro ao criar tabela de controle de migrações", err)
+		return err
+	}
+
+	current, err := CurrentMigrationVersion(ctx, db)
+	if err != nil {
+		logger.Error("Erro ao obter versão atual das migrações", err)
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+
+		if err := applyMigration(ctx, db, m); err != nil {
+			logger.Error("Erro ao aplicar migração", map[string]interface{}{
+				"version":     m.Version,
+				"description": m.Description,
+				"error":       err.Error(),
+			})
+			return err
+		}
+
+		logger.Info(fmt.Sprintf("Migração %d aplicada: %s", m.Version, m.Description))
+	}
+
+	logger.Info("Migração de tabelas concluída com sucesso")
+	return nil
+}
+
+// applyMigration executa uma migração e registra sua versão na mesma transação
+func applyMigration(ctx context.Context, db *sql.DB, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if m.SQL != "" {
+		if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+			return err
+		}
+	}
+	if m.Data != nil {
+		if err := m.Data(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, description) VALUES ($1, $2)`,
+		m.Version, m.Description,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// normalizeLeadPhones regrava no E.164 canônico de pkg/phone os telefones
+// gravados pela normalização anterior da importação e pelos cadastros sem
+// normalização, como celulares sem o nono dígito. Telefones que não são
+// reconhecidos ficam como estão. Leads que passam a ter o mesmo telefone
+// aparecem como possíveis duplicados para serem mesclados.
+func normalizeLeadPhones(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, phone FROM leads`)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	var phones []string
+	for rows.Next() {
+		var id int64
+		var raw string
+		if err := rows.Scan(&id, &raw); err != nil {
+			rows.Close()
+			return err
+		}
+		normalized, err := phone.Normalize(raw)
+		if err == nil && normalized != raw {
+			ids = append(ids, id)
+			phones = append(phones, normalized)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE leads l SET phone = n.phone
+		FROM unnest($1::integer[], $2::text[]) AS n(id, phone)
+		WHERE l.id = n.id
+	`, ids, phones)
+	if err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("Telefones de %d leads normalizados", len(ids)))
+	return nil
+}
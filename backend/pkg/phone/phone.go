@@ -0,0 +1,145 @@
+// Package phone normaliza números de telefone para o formato E.164 e para o
+// identificador usado pelo WhatsApp (wa_id), tratando as particularidades dos
+// números brasileiros, em especial o nono dígito dos celulares.
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultCountryCode é aplicado a números informados sem código de país
+const DefaultCountryCode = "55"
+
+// ErrInvalid indica que o texto informado não é um telefone válido
+var ErrInvalid = errors.New("telefone inválido")
+
+// lastNinthDigitDDD é o maior DDD cujos celulares mantêm o nono dígito no
+// wa_id. Contas do WhatsApp de DDDs acima dele foram registradas antes da
+// adoção do nono dígito e continuam identificadas com 8 dígitos.
+const lastNinthDigitDDD = 30
+
+// Number é um telefone já validado, separado em código de país e número nacional
+type Number struct {
+	CountryCode string
+	National    string
+}
+
+// Parse interpreta telefones digitados em formatos variados, como
+// "(11) 9 8765-4321", "+55 11 98765-4321", "0 11 98765-4321" ou o wa_id
+// "551187654321", devolvendo sempre a forma canônica (com o nono dígito para
+// celulares brasileiros).
+func Parse(raw string) (Number, error) {
+	raw = strings.TrimSpace(raw)
+
+	var b strings.Builder
+	for _, r := range raw {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	digits := b.String()
+	if digits == "" {
+		return Number{}, ErrInvalid
+	}
+
+	international := strings.HasPrefix(raw, "+")
+	switch {
+	case !international && strings.HasPrefix(digits, "00"):
+		// Prefixo de discagem internacional
+		digits = digits[2:]
+		international = true
+	case !international && strings.HasPrefix(digits, "0") && (len(digits) == 11 || len(digits) == 12):
+		// Zero de discagem interurbana: 0 11 98765-4321
+		digits = digits[1:]
+	case !international && strings.HasPrefix(digits, DefaultCountryCode) && (len(digits) == 12 || len(digits) == 13):
+		// wa_id ou número com código do país sem o sinal de mais
+		international = true
+	}
+
+	if !international {
+		return parseBrazilian(digits)
+	}
+	if strings.HasPrefix(digits, DefaultCountryCode) {
+		return parseBrazilian(digits[len(DefaultCountryCode):])
+	}
+
+	// Demais países: apenas o formato geral do E.164
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return Number{}, ErrInvalid
+	}
+	return Number{National: digits}, nil
+}
+
+// parseBrazilian valida DDD + número nacional, incluindo o nono dígito ausente em celulares
+func parseBrazilian(national string) (Number, error) {
+	if len(national) != 10 && len(national) != 11 {
+		return Number{}, ErrInvalid
+	}
+
+	ddd, subscriber := national[:2], national[2:]
+	if ddd[0] == '0' || ddd[1] == '0' {
+		return Number{}, ErrInvalid
+	}
+
+	switch len(subscriber) {
+	case 9:
+		if subscriber[0] != '9' {
+			return Number{}, ErrInvalid
+		}
+	case 8:
+		// Celulares (iniciados em 6 a 9) sem o nono dígito ganham o 9;
+		// fixos (2 a 5) permanecem com 8 dígitos
+		switch {
+		case subscriber[0] >= '6':
+			subscriber = "9" + subscriber
+		case subscriber[0] < '2':
+			return Number{}, ErrInvalid
+		}
+	}
+
+	return Number{CountryCode: DefaultCountryCode, National: ddd + subscriber}, nil
+}
+
+// IsBrazilian indica se o número é brasileiro
+func (n Number) IsBrazilian() bool {
+	return n.CountryCode == DefaultCountryCode
+}
+
+// IsMobile indica se o número é um celular brasileiro. Para outros países
+// a informação não está disponível e o retorno é sempre false.
+func (n Number) IsMobile() bool {
+	return n.IsBrazilian() && len(n.National) == 11
+}
+
+// E164 retorna o número no formato E.164, como +5511987654321
+func (n Number) E164() string {
+	return "+" + n.CountryCode + n.National
+}
+
+// WAID retorna o identificador usado pela API do WhatsApp, sem o sinal de
+// mais e, para celulares brasileiros de DDD acima de 30, sem o nono dígito
+func (n Number) WAID() string {
+	if n.IsMobile() && ddd(n.National) > lastNinthDigitDDD {
+		return n.CountryCode + n.National[:2] + n.National[3:]
+	}
+	return n.CountryCode + n.National
+}
+
+func ddd(national string) int {
+	return int(national[0]-'0')*10 + int(national[1]-'0')
+}
+
+// Normalize converte o telefone para o formato E.164 canônico
+func Normalize(raw string) (string, error) {
+	n, err := Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return n.E164(), nil
+}
+
+// FromWAID converte o wa_id recebido do WhatsApp para o formato E.164 canônico
+func FromWAID(waID string) (string, error) {
+	return Normalize("+" + strings.TrimPrefix(waID, "+"))
+}
@@ -0,0 +1,125 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		e164   string
+		waID   string
+		mobile bool
+	}{
+		{"celular formatado", "(11) 9 8765-4321", "+5511987654321", "5511987654321", true},
+		{"celular sem nono dígito", "(11) 8765-4321", "+5511987654321", "5511987654321", true},
+		{"fixo", "(11) 3456-7890", "+551134567890", "551134567890", false},
+		{"fixo de DDD acima de 30", "(31) 3456-7890", "+553134567890", "553134567890", false},
+		{"celular no último DDD com nono dígito no wa_id", "(28) 98765-4321", "+5528987654321", "5528987654321", true},
+		{"celular no primeiro DDD sem nono dígito no wa_id", "(31) 98765-4321", "+5531987654321", "553187654321", true},
+		{"celular de DDD alto sem nono dígito", "(88) 8765-4321", "+5588987654321", "558887654321", true},
+		{"código do país com mais", "+55 21 98765-4321", "+5521987654321", "5521987654321", true},
+		{"código do país sem mais", "55 21 98765-4321", "+5521987654321", "5521987654321", true},
+		{"wa_id de DDD alto", "553187654321", "+5531987654321", "553187654321", true},
+		{"mais com wa_id de DDD alto", "+553187654321", "+5531987654321", "553187654321", true},
+		{"DDD 55 fixo sem código do país", "(55) 3222-1234", "+555532221234", "555532221234", false},
+		{"DDD 55 celular sem código do país", "(55) 99876-5432", "+5555998765432", "555598765432", true},
+		{"DDD 55 celular com código do país", "+55 55 99876-5432", "+5555998765432", "555598765432", true},
+		{"zero de discagem interurbana", "0 11 98765-4321", "+5511987654321", "5511987654321", true},
+		{"prefixo de discagem internacional", "0055 11 98765-4321", "+5511987654321", "5511987654321", true},
+		{"outro país", "+1 415 555 2671", "+14155552671", "14155552671", false},
+		{"espaços nas pontas", "  11987654321 ", "+5511987654321", "5511987654321", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Parse(tt.raw)
+			if err != nil {
+				t.Fatalf("Parse(%q) retornou erro: %v", tt.raw, err)
+			}
+			if got := n.E164(); got != tt.e164 {
+				t.Errorf("Parse(%q).E164() = %q, esperado %q", tt.raw, got, tt.e164)
+			}
+			if got := n.WAID(); got != tt.waID {
+				t.Errorf("Parse(%q).WAID() = %q, esperado %q", tt.raw, got, tt.waID)
+			}
+			if got := n.IsMobile(); got != tt.mobile {
+				t.Errorf("Parse(%q).IsMobile() = %v, esperado %v", tt.raw, got, tt.mobile)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"vazio", ""},
+		{"sem dígitos", "abc"},
+		{"curto demais", "11 8765-432"},
+		{"longo demais", "11 98765-43210"},
+		{"DDD iniciado em zero", "(01) 8765-4321"},
+		{"DDD terminado em zero", "(10) 98765-4321"},
+		{"assinante iniciado em 1", "(11) 1234-5678"},
+		{"nove dígitos sem o 9 inicial", "(11) 88765-4321"},
+		{"código do país com número curto", "+55 11 8765-432"},
+		{"internacional iniciado em zero", "+0123456789"},
+		{"internacional curto", "+1234567"},
+		{"internacional longo", "+1234567890123456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if n, err := Parse(tt.raw); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Parse(%q) = %+v, %v; esperado ErrInvalid", tt.raw, n, err)
+			}
+		})
+	}
+}
+
+func TestFromWAID(t *testing.T) {
+	tests := []struct {
+		waID string
+		want string
+	}{
+		{"5511987654321", "+5511987654321"},
+		{"5528987654321", "+5528987654321"},
+		{"553187654321", "+5531987654321"},
+		{"+553187654321", "+5531987654321"},
+		{"551134567890", "+551134567890"},
+		{"14155552671", "+14155552671"},
+	}
+
+	for _, tt := range tests {
+		got, err := FromWAID(tt.waID)
+		if err != nil {
+			t.Errorf("FromWAID(%q) retornou erro: %v", tt.waID, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("FromWAID(%q) = %q, esperado %q", tt.waID, got, tt.want)
+		}
+	}
+
+	if _, err := FromWAID("55"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("FromWAID(%q) = %v, esperado ErrInvalid", "55", err)
+	}
+}
+
+// O wa_id gerado para o envio precisa voltar ao mesmo telefone quando o
+// WhatsApp o devolve no webhook
+func TestWAIDRoundTrip(t *testing.T) {
+	for _, raw := range []string{"11987654321", "28987654321", "31987654321", "88987654321", "1134567890", "+14155552671"} {
+		n, err := Parse(raw)
+		if err != nil {
+			t.Fatalf("Parse(%q) retornou erro: %v", raw, err)
+		}
+		got, err := FromWAID(n.WAID())
+		if err != nil || got != n.E164() {
+			t.Errorf("FromWAID(%q) = %q, %v; esperado %q", n.WAID(), got, err, n.E164())
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// Package text reúne o tratamento de textos digitados que precisa ser igual
+// em todo o sistema, como a comparação de nomes, palavras-chave e respostas.
+package text
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize remove acentos, pontuação e diferenças de caixa e espaçamento,
+// deixando as palavras em minúsculas separadas por um único espaço
+func Normalize(s string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Marca de acento separada pela decomposição
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+			space = false
+		case !space:
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,30 @@
+package text
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"João", "joao"},
+		{"JOÃO DA SILVA", "joao da silva"},
+		{"  Maria   das  Graças ", "maria das gracas"},
+		{"Ação, orçamento!", "acao orcamento"},
+		{"sim.", "sim"},
+		{"Opção 2", "opcao 2"},
+		{"d'Ávila-Souza", "d avila souza"},
+		{"linha\nnova\ttab", "linha nova tab"},
+		{"São Paulo 🙂 SP", "sao paulo sp"},
+		{"!!!", ""},
+		{"Ñandú", "nandu"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.input); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, esperado %q", tt.input, got, tt.want)
+		}
+	}
+}
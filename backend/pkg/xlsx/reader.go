@@ -0,0 +1,229 @@
+// Package xlsx implementa a leitura e escrita do subconjunto do formato
+// Office Open XML (.xlsx) necessário para planilhas tabulares simples:
+// uma planilha, células de texto e números, sem fórmulas ou estilos.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Erros de leitura de planilhas
+var (
+	ErrInvalidFile = errors.New("arquivo xlsx inválido")
+	ErrNoSheets    = errors.New("planilha sem abas")
+)
+
+// maxUncompressedSize protege contra arquivos compactados maliciosos (zip bombs)
+const maxUncompressedSize = 200 << 20
+
+type workbookXML struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationshipsXML struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type richTextXML struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+// text concatena o texto simples e os trechos com formatação
+func (rt richTextXML) text() string {
+	if len(rt.R) == 0 {
+		return rt.T
+	}
+	var b strings.Builder
+	b.WriteString(rt.T)
+	for _, r := range rt.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type sharedStringsXML struct {
+	Items []richTextXML `xml:"si"`
+}
+
+type worksheetXML struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string      `xml:"r,attr"`
+			Type   string      `xml:"t,attr"`
+			Value  string      `xml:"v"`
+			Inline richTextXML `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadFirstSheet lê a primeira aba da planilha e retorna suas linhas como texto.
+// Células vazias intermediárias são preenchidas com "" para manter o alinhamento das colunas.
+func ReadFirstSheet(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrInvalidFile
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var wb workbookXML
+	if err := decodeXML(files, "xl/workbook.xml", &wb); err != nil {
+		return nil, err
+	}
+	if len(wb.Sheets) == 0 {
+		return nil, ErrNoSheets
+	}
+
+	sheetPath, err := resolveSheetPath(files, wb.Sheets[0].RID)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared sharedStringsXML
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXML(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var ws worksheetXML
+	if err := decodeXML(files, sheetPath, &ws); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(ws.Rows))
+	for _, row := range ws.Rows {
+		var values []string
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				if parsed, err := columnIndex(c.Ref); err == nil {
+					col = parsed
+				}
+			}
+			for len(values) < col {
+				values = append(values, "")
+			}
+
+			var value string
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(c.Value)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("%w: referência de texto inválida em %s", ErrInvalidFile, c.Ref)
+				}
+				value = shared.Items[idx].text()
+			case "inlineStr":
+				value = c.Inline.text()
+			case "b":
+				if c.Value == "1" {
+					value = "TRUE"
+				} else {
+					value = "FALSE"
+				}
+			default:
+				value = c.Value
+			}
+
+			if col < len(values) {
+				values[col] = value
+			} else {
+				values = append(values, value)
+			}
+		}
+		rows = append(rows, values)
+	}
+
+	return rows, nil
+}
+
+// resolveSheetPath encontra o arquivo da aba a partir do relacionamento do workbook
+func resolveSheetPath(files map[string]*zip.File, rid string) (string, error) {
+	var rels relationshipsXML
+	if err := decodeXML(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != rid {
+			continue
+		}
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			return strings.TrimPrefix(target, "/"), nil
+		}
+		return path.Join("xl", target), nil
+	}
+
+	return "", fmt.Errorf("%w: aba %s não encontrada", ErrInvalidFile, rid)
+}
+
+// decodeXML lê e interpreta um arquivo XML de dentro do pacote
+func decodeXML(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%w: %s ausente", ErrInvalidFile, name)
+	}
+	if f.UncompressedSize64 > maxUncompressedSize {
+		return fmt.Errorf("%w: %s excede o tamanho máximo", ErrInvalidFile, name)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, maxUncompressedSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidFile, name, err)
+	}
+	return nil
+}
+
+// columnIndex converte a referência de uma célula (ex.: "AB12") no índice da coluna, começando em 0
+func columnIndex(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			col = col*26 + int(r-'A'+1)
+			n++
+			continue
+		}
+		break
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("referência inválida %q", ref)
+	}
+	return col - 1, nil
+}
+
+// ColumnName converte o índice da coluna (começando em 0) no nome usado pelo Excel, como "A" ou "AB"
+func ColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}